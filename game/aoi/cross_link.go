@@ -0,0 +1,331 @@
+package aoi
+
+import (
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"github.com/kercylan98/minotaur/utils/hash"
+	"sync"
+)
+
+// NewCrossLink 创建一个基于十字链表算法的 AOI 实例(CrossLink)
+//   - 对象的视野范围为以对象坐标为圆心，TwoDimensionalEntity.GetVision 为半径的圆，因此视野可能不是相互的
+//   - 相较于九宫格算法无需预先划分地图，适用于地图较大、对象分布稀疏或对象视野各不相同的场景
+func NewCrossLink[E TwoDimensionalEntity]() *CrossLink[E] {
+	return &CrossLink[E]{
+		event:     new(event[E]),
+		nodes:     make(map[int64]*crossLinkNode[E]),
+		visible:   make(map[int64]map[int64]E),
+		observers: make(map[int64]map[int64]E),
+	}
+}
+
+// CrossLink 基于十字链表算法的 AOI 实现，对象按照 x、y 坐标分别有序地存储在两个双向链表中
+//   - EntityJoinVisionEvent(entity, target) 表示 target 进入了 entity 的视野，EntityLeaveVisionEvent 同理
+type CrossLink[E TwoDimensionalEntity] struct {
+	*event[E]
+	rw        sync.RWMutex
+	nodes     map[int64]*crossLinkNode[E]
+	xHead     *crossLinkNode[E]
+	yHead     *crossLinkNode[E]
+	visible   map[int64]map[int64]E // 对象视野范围内的对象 [guid][target]
+	observers map[int64]map[int64]E // 能够看到对象的对象 [guid][observer]
+	maxVision float64               // 所有对象中最大的视野半径
+}
+
+// crossLinkNode 十字链表节点
+type crossLinkNode[E TwoDimensionalEntity] struct {
+	entity       E
+	guid         int64
+	x, y, vision float64
+	xPrev, xNext *crossLinkNode[E]
+	yPrev, yNext *crossLinkNode[E]
+}
+
+// Add 添加对象，当对象已存在时将视为移动
+func (slf *CrossLink[E]) Add(entity E) {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	if node, exist := slf.nodes[entity.GetGuid()]; exist {
+		slf.move(node, entity)
+		return
+	}
+	node := &crossLinkNode[E]{entity: entity, guid: entity.GetGuid(), vision: entity.GetVision()}
+	node.x, node.y = entity.GetPosition()
+	slf.nodes[node.guid] = node
+	slf.visible[node.guid] = make(map[int64]E)
+	slf.observers[node.guid] = make(map[int64]E)
+	slf.insertX(node, slf.xHead)
+	slf.insertY(node, slf.yHead)
+	if node.vision > slf.maxVision {
+		slf.maxVision = node.vision
+	}
+	slf.refresh(node)
+}
+
+// Remove 移除对象
+func (slf *CrossLink[E]) Remove(guid int64) {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	node, exist := slf.nodes[guid]
+	if !exist {
+		return
+	}
+	slf.unlinkX(node)
+	slf.unlinkY(node)
+	delete(slf.nodes, guid)
+	visible, observers := slf.visible[guid], slf.observers[guid]
+	delete(slf.visible, guid)
+	delete(slf.observers, guid)
+	for tg, target := range visible {
+		delete(slf.observers[tg], guid)
+		slf.OnEntityLeaveVisionEvent(node.entity, target)
+	}
+	for og, observer := range observers {
+		delete(slf.visible[og], guid)
+		slf.OnEntityLeaveVisionEvent(observer, node.entity)
+	}
+}
+
+// Move 在对象位置或视野改变后调用，将根据对象的新位置及视野更新视野，当对象不存在时将不会产生任何效果
+func (slf *CrossLink[E]) Move(entity E) {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	if node, exist := slf.nodes[entity.GetGuid()]; exist {
+		slf.move(node, entity)
+	}
+}
+
+// move 根据对象的新位置及视野更新链表及视野（无锁）
+func (slf *CrossLink[E]) move(node *crossLinkNode[E], entity E) {
+	x, y := entity.GetPosition()
+	node.entity, node.vision = entity, entity.GetVision()
+	if node.vision > slf.maxVision {
+		slf.maxVision = node.vision
+	}
+	if x != node.x {
+		start := node.xPrev
+		if x > node.x {
+			start = node.xNext
+		}
+		slf.unlinkX(node)
+		node.x = x
+		if start == nil {
+			start = slf.xHead
+		}
+		slf.insertX(node, start)
+	}
+	if y != node.y {
+		start := node.yPrev
+		if y > node.y {
+			start = node.yNext
+		}
+		slf.unlinkY(node)
+		node.y = y
+		if start == nil {
+			start = slf.yHead
+		}
+		slf.insertY(node, start)
+	}
+	slf.refresh(node)
+}
+
+// refresh 更新对象的视野及能够看到对象的对象，并触发相应的事件（无锁）
+func (slf *CrossLink[E]) refresh(node *crossLinkNode[E]) {
+	visible := slf.visible[node.guid]
+	var current = make(map[int64]*crossLinkNode[E])
+	for _, target := range slf.around(node, node.vision) {
+		if geometry.CalcDistanceSquared(node.x, node.y, target.x, target.y) <= node.vision*node.vision {
+			current[target.guid] = target
+		}
+	}
+	for tg, target := range visible {
+		if _, exist := current[tg]; !exist {
+			delete(visible, tg)
+			delete(slf.observers[tg], node.guid)
+			slf.OnEntityLeaveVisionEvent(node.entity, target)
+		}
+	}
+	for tg, target := range current {
+		if _, exist := visible[tg]; !exist {
+			visible[tg] = target.entity
+			slf.observers[tg][node.guid] = node.entity
+			slf.OnEntityJoinVisionEvent(node.entity, target.entity)
+		}
+	}
+
+	observers := slf.observers[node.guid]
+	var candidates = make(map[int64]*crossLinkNode[E])
+	for _, observer := range slf.around(node, slf.maxVision) {
+		candidates[observer.guid] = observer
+	}
+	for og := range observers {
+		candidates[og] = slf.nodes[og]
+	}
+	for og, observer := range candidates {
+		_, seen := observers[og]
+		sees := geometry.CalcDistanceSquared(node.x, node.y, observer.x, observer.y) <= observer.vision*observer.vision
+		switch {
+		case sees && !seen:
+			observers[og] = observer.entity
+			slf.visible[og][node.guid] = node.entity
+			slf.OnEntityJoinVisionEvent(observer.entity, node.entity)
+		case !sees && seen:
+			delete(observers, og)
+			delete(slf.visible[og], node.guid)
+			slf.OnEntityLeaveVisionEvent(observer.entity, node.entity)
+		}
+	}
+}
+
+// around 获取 x、y 坐标与对象距离均不超过 radius 的所有其他对象（无锁）
+//   - 将同时沿着 x、y 两个链表向两侧遍历，并以先完成遍历的链表的结果作为候选对象
+func (slf *CrossLink[E]) around(node *crossLinkNode[E], radius float64) []*crossLinkNode[E] {
+	var xs, ys []*crossLinkNode[E]
+	xl, xr, yl, yr := node.xPrev, node.xNext, node.yPrev, node.yNext
+	for {
+		if xl != nil && node.x-xl.x <= radius {
+			xs, xl = append(xs, xl), xl.xPrev
+		} else {
+			xl = nil
+		}
+		if xr != nil && xr.x-node.x <= radius {
+			xs, xr = append(xs, xr), xr.xNext
+		} else {
+			xr = nil
+		}
+		if xl == nil && xr == nil {
+			return slf.filter(node, xs, radius)
+		}
+		if yl != nil && node.y-yl.y <= radius {
+			ys, yl = append(ys, yl), yl.yPrev
+		} else {
+			yl = nil
+		}
+		if yr != nil && yr.y-node.y <= radius {
+			ys, yr = append(ys, yr), yr.yNext
+		} else {
+			yr = nil
+		}
+		if yl == nil && yr == nil {
+			return slf.filter(node, ys, radius)
+		}
+	}
+}
+
+// filter 过滤出 x、y 坐标与对象距离均不超过 radius 的对象
+func (slf *CrossLink[E]) filter(node *crossLinkNode[E], nodes []*crossLinkNode[E], radius float64) []*crossLinkNode[E] {
+	var result = nodes[:0]
+	for _, n := range nodes {
+		if n.x-node.x <= radius && node.x-n.x <= radius && n.y-node.y <= radius && node.y-n.y <= radius {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// GetVisible 获取对象视野范围内的所有对象
+func (slf *CrossLink[E]) GetVisible(guid int64) map[int64]E {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	return hash.Copy(slf.visible[guid])
+}
+
+// GetObservers 获取能够看到对象的所有对象，通常用于向这些对象广播该对象的位置变化
+func (slf *CrossLink[E]) GetObservers(guid int64) map[int64]E {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	return hash.Copy(slf.observers[guid])
+}
+
+// QueryRange 获取以 x、y 为圆心，radius 为半径的范围内的所有对象
+func (slf *CrossLink[E]) QueryRange(x, y, radius float64) map[int64]E {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	var result = make(map[int64]E)
+	for node := slf.xHead; node != nil && node.x <= x+radius; node = node.xNext {
+		if node.x >= x-radius && geometry.CalcDistanceSquared(x, y, node.x, node.y) <= radius*radius {
+			result[node.guid] = node.entity
+		}
+	}
+	return result
+}
+
+// GetEntityCount 获取对象数量
+func (slf *CrossLink[E]) GetEntityCount() int {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	return len(slf.nodes)
+}
+
+// insertX 从 start 开始查找位置并将节点插入 x 链表（无锁）
+func (slf *CrossLink[E]) insertX(node, start *crossLinkNode[E]) {
+	if start == nil {
+		slf.xHead = node
+		return
+	}
+	prev, next := start.xPrev, start
+	for next != nil && next.x < node.x {
+		prev, next = next, next.xNext
+	}
+	for prev != nil && prev.x > node.x {
+		prev, next = prev.xPrev, prev
+	}
+	node.xPrev, node.xNext = prev, next
+	if prev != nil {
+		prev.xNext = node
+	} else {
+		slf.xHead = node
+	}
+	if next != nil {
+		next.xPrev = node
+	}
+}
+
+// insertY 从 start 开始查找位置并将节点插入 y 链表（无锁）
+func (slf *CrossLink[E]) insertY(node, start *crossLinkNode[E]) {
+	if start == nil {
+		slf.yHead = node
+		return
+	}
+	prev, next := start.yPrev, start
+	for next != nil && next.y < node.y {
+		prev, next = next, next.yNext
+	}
+	for prev != nil && prev.y > node.y {
+		prev, next = prev.yPrev, prev
+	}
+	node.yPrev, node.yNext = prev, next
+	if prev != nil {
+		prev.yNext = node
+	} else {
+		slf.yHead = node
+	}
+	if next != nil {
+		next.yPrev = node
+	}
+}
+
+// unlinkX 将节点从 x 链表中移除（无锁）
+func (slf *CrossLink[E]) unlinkX(node *crossLinkNode[E]) {
+	if node.xPrev != nil {
+		node.xPrev.xNext = node.xNext
+	} else {
+		slf.xHead = node.xNext
+	}
+	if node.xNext != nil {
+		node.xNext.xPrev = node.xPrev
+	}
+	node.xPrev, node.xNext = nil, nil
+}
+
+// unlinkY 将节点从 y 链表中移除（无锁）
+func (slf *CrossLink[E]) unlinkY(node *crossLinkNode[E]) {
+	if node.yPrev != nil {
+		node.yPrev.yNext = node.yNext
+	} else {
+		slf.yHead = node.yNext
+	}
+	if node.yNext != nil {
+		node.yNext.yPrev = node.yPrev
+	}
+	node.yPrev, node.yNext = nil, nil
+}
@@ -0,0 +1,75 @@
+package aoi_test
+
+import (
+	"github.com/kercylan98/minotaur/game/aoi"
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"github.com/kercylan98/minotaur/utils/random"
+	"testing"
+)
+
+func TestCrossLink(t *testing.T) {
+	link := aoi.NewCrossLink[*Ent]()
+	var visible = make(map[[2]int64]bool)
+	link.RegEntityJoinVisionEvent(func(entity, target *Ent) {
+		visible[[2]int64{entity.guid, target.guid}] = true
+	})
+	link.RegEntityLeaveVisionEvent(func(entity, target *Ent) {
+		delete(visible, [2]int64{entity.guid, target.guid})
+	})
+
+	var entities []*Ent
+	for i := 0; i < 300; i++ {
+		entity := &Ent{
+			guid:   int64(i),
+			x:      float64(random.Int(0, 1000)),
+			y:      float64(random.Int(0, 1000)),
+			vision: float64(random.Int(10, 150)),
+		}
+		entities = append(entities, entity)
+		link.Add(entity)
+	}
+	for round := 0; round < 5; round++ {
+		for _, entity := range entities {
+			entity.x += float64(random.Int(-50, 50))
+			entity.y += float64(random.Int(-50, 50))
+			link.Move(entity)
+		}
+	}
+	for _, entity := range entities[:100] {
+		link.Remove(entity.guid)
+	}
+	entities = entities[100:]
+
+	var expected = make(map[[2]int64]bool)
+	for _, entity := range entities {
+		for _, target := range entities {
+			if entity != target && geometry.CalcDistanceWithCoordinate(entity.x, entity.y, target.x, target.y) <= entity.vision {
+				expected[[2]int64{entity.guid, target.guid}] = true
+			}
+		}
+	}
+	if len(expected) != len(visible) {
+		t.Fatalf("expected %d visible pairs, got %d", len(expected), len(visible))
+	}
+	for pair := range expected {
+		if !visible[pair] {
+			t.Fatalf("pair %v should be visible", pair)
+		}
+	}
+
+	entity := entities[0]
+	for guid := range link.GetObservers(entity.guid) {
+		if !expected[[2]int64{guid, entity.guid}] {
+			t.Fatalf("entity %d should not observe entity %d", guid, entity.guid)
+		}
+	}
+	var count int
+	for _, target := range entities {
+		if geometry.CalcDistanceWithCoordinate(entity.x, entity.y, target.x, target.y) <= 200 {
+			count++
+		}
+	}
+	if r := link.QueryRange(entity.x, entity.y, 200); len(r) != count {
+		t.Fatalf("expected %d entities in range, got %d", count, len(r))
+	}
+}
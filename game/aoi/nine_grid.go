@@ -0,0 +1,224 @@
+package aoi
+
+import (
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"github.com/kercylan98/minotaur/utils/hash"
+	"math"
+	"sync"
+)
+
+// NewNineGrid 创建一个基于九宫格算法的 AOI 实例(NineGrid)
+//   - width、height 为地图的宽高，gridSize 为每个格子的边长，超出地图范围的坐标将被视为位于地图边缘的格子中
+//   - 对象的视野范围为所在格子及其周围的 8 个格子，与 TwoDimensionalEntity.GetVision 无关，因此视野总是相互的
+//   - gridSize 通常设置为对象视野半径，格子越小视野越精确，但移动时跨越格子的频率也越高
+func NewNineGrid[E TwoDimensionalEntity](width, height, gridSize float64) *NineGrid[E] {
+	if gridSize <= 0 {
+		gridSize = 1
+	}
+	cols, rows := int(math.Ceil(width/gridSize)), int(math.Ceil(height/gridSize))
+	if cols < 1 {
+		cols = 1
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	return &NineGrid[E]{
+		event:    new(event[E]),
+		gridSize: gridSize,
+		cols:     cols,
+		rows:     rows,
+		grids:    make([]map[int64]E, cols*rows),
+		entities: make(map[int64]int),
+	}
+}
+
+// NineGrid 基于九宫格算法的 AOI 实现
+//   - 对象进入或离开视野时将触发 EntityJoinVisionEvent 及 EntityLeaveVisionEvent，由于视野是相互的，每次进入或离开都将以双方的视角分别触发一次
+type NineGrid[E TwoDimensionalEntity] struct {
+	*event[E]
+	rw       sync.RWMutex
+	gridSize float64
+	cols     int
+	rows     int
+	grids    []map[int64]E // 格子中的对象，下标为格子索引
+	entities map[int64]int // 对象所在的格子索引
+}
+
+// Add 添加对象，当对象已存在时将视为移动
+func (slf *NineGrid[E]) Add(entity E) {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	if _, exist := slf.entities[entity.GetGuid()]; exist {
+		slf.move(entity)
+		return
+	}
+	index := slf.gridIndex(entity.GetPosition())
+	slf.rangeNine(index, func(guid int64, e E) {
+		slf.OnEntityJoinVisionEvent(entity, e)
+		slf.OnEntityJoinVisionEvent(e, entity)
+	})
+	slf.put(index, entity)
+}
+
+// Remove 移除对象
+func (slf *NineGrid[E]) Remove(guid int64) {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	index, exist := slf.entities[guid]
+	if !exist {
+		return
+	}
+	entity := slf.grids[index][guid]
+	delete(slf.grids[index], guid)
+	delete(slf.entities, guid)
+	slf.rangeNine(index, func(guid int64, e E) {
+		slf.OnEntityLeaveVisionEvent(entity, e)
+		slf.OnEntityLeaveVisionEvent(e, entity)
+	})
+}
+
+// Move 在对象位置改变后调用，将根据对象的新位置更新视野，当对象不存在时将不会产生任何效果
+func (slf *NineGrid[E]) Move(entity E) {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	if _, exist := slf.entities[entity.GetGuid()]; exist {
+		slf.move(entity)
+	}
+}
+
+// move 根据对象的新位置更新视野（无锁）
+func (slf *NineGrid[E]) move(entity E) {
+	guid := entity.GetGuid()
+	from, to := slf.entities[guid], slf.gridIndex(entity.GetPosition())
+	if from == to {
+		slf.grids[to][guid] = entity
+		return
+	}
+	delete(slf.grids[from], guid)
+	fromNine, toNine := slf.nine(from), slf.nine(to)
+	for index := range fromNine {
+		if _, exist := toNine[index]; exist {
+			continue
+		}
+		for _, e := range slf.grids[index] {
+			slf.OnEntityLeaveVisionEvent(entity, e)
+			slf.OnEntityLeaveVisionEvent(e, entity)
+		}
+	}
+	for index := range toNine {
+		if _, exist := fromNine[index]; exist {
+			continue
+		}
+		for _, e := range slf.grids[index] {
+			slf.OnEntityJoinVisionEvent(entity, e)
+			slf.OnEntityJoinVisionEvent(e, entity)
+		}
+	}
+	slf.put(to, entity)
+}
+
+// GetVisible 获取对象视野范围内的所有对象
+func (slf *NineGrid[E]) GetVisible(guid int64) map[int64]E {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	var result = make(map[int64]E)
+	index, exist := slf.entities[guid]
+	if !exist {
+		return result
+	}
+	slf.rangeNine(index, func(g int64, e E) {
+		if g != guid {
+			result[g] = e
+		}
+	})
+	return result
+}
+
+// QueryRange 获取以 x、y 为圆心，radius 为半径的范围内的所有对象
+func (slf *NineGrid[E]) QueryRange(x, y, radius float64) map[int64]E {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	var result = make(map[int64]E)
+	sc, sr := slf.gridCoordinate(x-radius, y-radius)
+	ec, er := slf.gridCoordinate(x+radius, y+radius)
+	for c := sc; c <= ec; c++ {
+		for r := sr; r <= er; r++ {
+			for guid, e := range slf.grids[r*slf.cols+c] {
+				if ex, ey := e.GetPosition(); geometry.CalcDistanceSquared(x, y, ex, ey) <= radius*radius {
+					result[guid] = e
+				}
+			}
+		}
+	}
+	return result
+}
+
+// GetEntityCount 获取对象数量
+func (slf *NineGrid[E]) GetEntityCount() int {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	return len(slf.entities)
+}
+
+// GetGridEntities 获取坐标所在格子中的所有对象
+func (slf *NineGrid[E]) GetGridEntities(x, y float64) map[int64]E {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	return hash.Copy(slf.grids[slf.gridIndex(x, y)])
+}
+
+// put 将对象放入格子（无锁）
+func (slf *NineGrid[E]) put(index int, entity E) {
+	grid := slf.grids[index]
+	if grid == nil {
+		grid = make(map[int64]E)
+		slf.grids[index] = grid
+	}
+	grid[entity.GetGuid()] = entity
+	slf.entities[entity.GetGuid()] = index
+}
+
+// rangeNine 遍历格子及其周围 8 个格子中的所有对象（无锁）
+func (slf *NineGrid[E]) rangeNine(index int, handle func(guid int64, e E)) {
+	for i := range slf.nine(index) {
+		for guid, e := range slf.grids[i] {
+			handle(guid, e)
+		}
+	}
+}
+
+// nine 获取格子及其周围 8 个格子的索引
+func (slf *NineGrid[E]) nine(index int) map[int]struct{} {
+	col, row := index%slf.cols, index/slf.cols
+	var result = make(map[int]struct{}, 9)
+	for c := col - 1; c <= col+1; c++ {
+		for r := row - 1; r <= row+1; r++ {
+			if c >= 0 && c < slf.cols && r >= 0 && r < slf.rows {
+				result[r*slf.cols+c] = struct{}{}
+			}
+		}
+	}
+	return result
+}
+
+// gridIndex 获取坐标所在格子的索引
+func (slf *NineGrid[E]) gridIndex(x, y float64) int {
+	col, row := slf.gridCoordinate(x, y)
+	return row*slf.cols + col
+}
+
+// gridCoordinate 获取坐标所在格子的列与行，超出地图范围的坐标将被限制在地图边缘
+func (slf *NineGrid[E]) gridCoordinate(x, y float64) (col, row int) {
+	col, row = int(x/slf.gridSize), int(y/slf.gridSize)
+	if col < 0 || x < 0 {
+		col = 0
+	} else if col >= slf.cols {
+		col = slf.cols - 1
+	}
+	if row < 0 || y < 0 {
+		row = 0
+	} else if row >= slf.rows {
+		row = slf.rows - 1
+	}
+	return
+}
@@ -0,0 +1,42 @@
+package aoi_test
+
+import (
+	"github.com/kercylan98/minotaur/game/aoi"
+	"testing"
+)
+
+func TestNineGrid(t *testing.T) {
+	grid := aoi.NewNineGrid[*Ent](1000, 1000, 100)
+	var visible = make(map[[2]int64]bool)
+	grid.RegEntityJoinVisionEvent(func(entity, target *Ent) {
+		visible[[2]int64{entity.guid, target.guid}] = true
+	})
+	grid.RegEntityLeaveVisionEvent(func(entity, target *Ent) {
+		delete(visible, [2]int64{entity.guid, target.guid})
+	})
+
+	a, b, c := &Ent{guid: 1, x: 50, y: 50}, &Ent{guid: 2, x: 150, y: 150}, &Ent{guid: 3, x: 550, y: 550}
+	grid.Add(a)
+	grid.Add(b)
+	grid.Add(c)
+	if !visible[[2]int64{1, 2}] || !visible[[2]int64{2, 1}] || len(visible) != 2 {
+		t.Fatalf("unexpected visible: %v", visible)
+	}
+
+	b.x, b.y = 450, 450
+	grid.Move(b)
+	if !visible[[2]int64{2, 3}] || !visible[[2]int64{3, 2}] || len(visible) != 2 {
+		t.Fatalf("unexpected visible after move: %v", visible)
+	}
+	if v := grid.GetVisible(3); len(v) != 1 || v[2] != b {
+		t.Fatalf("unexpected visible entities: %v", v)
+	}
+	if r := grid.QueryRange(500, 500, 80); len(r) != 2 {
+		t.Fatalf("unexpected range query result: %v", r)
+	}
+
+	grid.Remove(3)
+	if len(visible) != 0 || grid.GetEntityCount() != 2 {
+		t.Fatalf("unexpected visible after remove: %v", visible)
+	}
+}
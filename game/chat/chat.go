@@ -0,0 +1,255 @@
+package chat
+
+import (
+	"github.com/kercylan98/minotaur/utils/hash"
+	"sync"
+	"time"
+)
+
+const (
+	ChannelRoom    Channel = iota + 1 // 房间频道，所有成员均可接收
+	ChannelTeam                       // 队伍频道，仅同一队伍的成员可接收
+	ChannelPrivate                    // 私聊频道，仅接收者可接收
+)
+
+var channelNames = map[Channel]string{
+	ChannelRoom:    "Room",
+	ChannelTeam:    "Team",
+	ChannelPrivate: "Private",
+}
+
+// Channel 聊天频道
+type Channel byte
+
+// String 返回聊天频道的字符串表示
+func (slf Channel) String() string {
+	return channelNames[slf]
+}
+
+// Message 聊天消息
+type Message[ID comparable] struct {
+	Channel   Channel   // 频道
+	Team      string    // 队伍频道的队伍
+	Sender    ID        // 发送者
+	Receivers []ID      // 接收者，包含发送者本身
+	Content   string    // 经过过滤后的消息内容
+	Time      time.Time // 发送时间
+}
+
+// NewChat 创建聊天，聊天不会直接发送数据包，而是通过 RegMessageEvent 等事件交由使用者处理
+func NewChat[ID comparable](options ...Option[ID]) *Chat[ID] {
+	chat := &Chat[ID]{
+		events:  new(events[ID]),
+		members: make(map[ID]string),
+		mutes:   make(map[ID]time.Time),
+		sends:   make(map[ID][]time.Time),
+	}
+	for _, option := range options {
+		option(chat)
+	}
+	return chat
+}
+
+// Chat 支持房间、队伍及私聊频道的聊天
+type Chat[ID comparable] struct {
+	*events[ID]
+	rw        sync.RWMutex
+	members   map[ID]string      // 成员及其所属的队伍
+	mutes     map[ID]time.Time   // 被禁言的成员及解除禁言的时间，零值表示永久禁言
+	sends     map[ID][]time.Time // 成员在频率限制时间窗口内发送消息的时间
+	rateCount int
+	ratePer   time.Duration
+	maxLength int
+	filters   []func(sender ID, channel Channel, content string) (string, error)
+}
+
+// Join 添加聊天成员，team 为成员所属的队伍，为空时表示不属于任何队伍
+//   - 重复添加将更新成员所属的队伍
+func (slf *Chat[ID]) Join(id ID, team string) {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	slf.members[id] = team
+}
+
+// Leave 移除聊天成员，成员的禁言状态将被保留
+func (slf *Chat[ID]) Leave(id ID) {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	delete(slf.members, id)
+	delete(slf.sends, id)
+}
+
+// SetTeam 设置成员所属的队伍，当成员不存在时将不会产生任何效果
+func (slf *Chat[ID]) SetTeam(id ID, team string) {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	if _, exist := slf.members[id]; exist {
+		slf.members[id] = team
+	}
+}
+
+// GetTeam 获取成员所属的队伍
+func (slf *Chat[ID]) GetTeam(id ID) string {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	return slf.members[id]
+}
+
+// IsMember 检查是否是聊天成员
+func (slf *Chat[ID]) IsMember(id ID) bool {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	_, exist := slf.members[id]
+	return exist
+}
+
+// GetMembers 获取所有聊天成员
+func (slf *Chat[ID]) GetMembers() []ID {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	return hash.KeyToSlice(slf.members)
+}
+
+// Mute 对成员禁言 duration 时长，当 duration 小于等于 0 时将永久禁言
+func (slf *Chat[ID]) Mute(id ID, duration time.Duration) {
+	var expire time.Time
+	if duration > 0 {
+		expire = time.Now().Add(duration)
+	}
+	slf.rw.Lock()
+	slf.mutes[id] = expire
+	slf.rw.Unlock()
+	slf.OnMuteEvent(slf, id, true)
+}
+
+// Unmute 解除成员的禁言
+func (slf *Chat[ID]) Unmute(id ID) {
+	slf.rw.Lock()
+	_, exist := slf.mutes[id]
+	delete(slf.mutes, id)
+	slf.rw.Unlock()
+	if exist {
+		slf.OnMuteEvent(slf, id, false)
+	}
+}
+
+// IsMuted 检查成员是否被禁言
+func (slf *Chat[ID]) IsMuted(id ID) bool {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	return slf.isMuted(id, time.Now())
+}
+
+// isMuted 检查成员是否被禁言，已过期的禁言将被清除
+func (slf *Chat[ID]) isMuted(id ID, now time.Time) bool {
+	expire, exist := slf.mutes[id]
+	if !exist {
+		return false
+	}
+	if !expire.IsZero() && !now.Before(expire) {
+		delete(slf.mutes, id)
+		return false
+	}
+	return true
+}
+
+// SendRoom 向所有成员发送消息
+func (slf *Chat[ID]) SendRoom(sender ID, content string) error {
+	return slf.send(sender, ChannelRoom, content, nil)
+}
+
+// SendTeam 向发送者所属队伍的所有成员发送消息，当发送者不属于任何队伍时将返回 ErrNoTeam
+func (slf *Chat[ID]) SendTeam(sender ID, content string) error {
+	return slf.send(sender, ChannelTeam, content, nil)
+}
+
+// SendPrivate 向特定成员发送私聊消息
+func (slf *Chat[ID]) SendPrivate(sender, receiver ID, content string) error {
+	return slf.send(sender, ChannelPrivate, content, &receiver)
+}
+
+// send 对消息进行检查及过滤后触发消息事件，被拒绝的消息将触发消息拒绝事件
+func (slf *Chat[ID]) send(sender ID, channel Channel, content string, receiver *ID) error {
+	message, err := slf.prepare(sender, channel, content, receiver)
+	if err != nil {
+		slf.OnMessageRejectedEvent(slf, sender, channel, content, err)
+		return err
+	}
+	slf.OnMessageEvent(slf, message)
+	return nil
+}
+
+// prepare 检查发送者状态、频率限制并执行内容过滤，返回包含接收者的消息
+func (slf *Chat[ID]) prepare(sender ID, channel Channel, content string, receiver *ID) (*Message[ID], error) {
+	if len(content) == 0 {
+		return nil, ErrEmptyContent
+	}
+	if slf.maxLength > 0 {
+		if runes := []rune(content); len(runes) > slf.maxLength {
+			content = string(runes[:slf.maxLength])
+		}
+	}
+	for _, filter := range slf.filters {
+		var err error
+		if content, err = filter(sender, channel, content); err != nil {
+			return nil, err
+		}
+	}
+
+	var now = time.Now()
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	team, exist := slf.members[sender]
+	if !exist {
+		return nil, ErrNotMember
+	}
+	if slf.isMuted(sender, now) {
+		return nil, ErrMuted
+	}
+	message := &Message[ID]{Channel: channel, Sender: sender, Content: content, Time: now}
+	switch channel {
+	case ChannelRoom:
+		message.Receivers = hash.KeyToSlice(slf.members)
+	case ChannelTeam:
+		if len(team) == 0 {
+			return nil, ErrNoTeam
+		}
+		message.Team = team
+		for id, t := range slf.members {
+			if t == team {
+				message.Receivers = append(message.Receivers, id)
+			}
+		}
+	case ChannelPrivate:
+		if _, exist = slf.members[*receiver]; !exist {
+			return nil, ErrReceiverNotMember
+		}
+		message.Receivers = []ID{sender}
+		if *receiver != sender {
+			message.Receivers = append(message.Receivers, *receiver)
+		}
+	}
+	if !slf.allow(sender, now) {
+		return nil, ErrRateLimited
+	}
+	return message, nil
+}
+
+// allow 检查成员是否超出频率限制，未超出时将记录本次发送
+func (slf *Chat[ID]) allow(sender ID, now time.Time) bool {
+	if slf.rateCount <= 0 {
+		return true
+	}
+	sends := slf.sends[sender]
+	var start = 0
+	for start < len(sends) && now.Sub(sends[start]) >= slf.ratePer {
+		start++
+	}
+	sends = sends[start:]
+	if len(sends) >= slf.rateCount {
+		slf.sends[sender] = sends
+		return false
+	}
+	slf.sends[sender] = append(sends, now)
+	return true
+}
@@ -0,0 +1,78 @@
+package chat_test
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/game/chat"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChat_Send(t *testing.T) {
+	var messages []*chat.Message[string]
+	c := chat.NewChat[string]()
+	c.RegMessageEvent(func(c *chat.Chat[string], message *chat.Message[string]) {
+		messages = append(messages, message)
+	})
+	c.Join("a", "red")
+	c.Join("b", "red")
+	c.Join("c", "blue")
+
+	if err := c.SendRoom("a", "hello"); err != nil || len(messages[0].Receivers) != 3 {
+		t.Fatalf("expected the room message to reach all members, got %v", err)
+	}
+	if err := c.SendTeam("a", "hello"); err != nil || len(messages[1].Receivers) != 2 || messages[1].Team != "red" {
+		t.Fatalf("expected the team message to reach the red team only, got %v", err)
+	}
+	if err := c.SendPrivate("a", "c", "hello"); err != nil || len(messages[2].Receivers) != 2 {
+		t.Fatalf("expected the private message to reach the sender and receiver, got %v", err)
+	}
+	if err := c.SendPrivate("a", "d", "hello"); !errors.Is(err, chat.ErrReceiverNotMember) {
+		t.Fatalf("expected ErrReceiverNotMember, got %v", err)
+	}
+	if err := c.SendRoom("d", "hello"); !errors.Is(err, chat.ErrNotMember) {
+		t.Fatalf("expected ErrNotMember, got %v", err)
+	}
+}
+
+func TestChat_Mute(t *testing.T) {
+	var rejected int
+	c := chat.NewChat[string]()
+	c.RegMessageRejectedEvent(func(c *chat.Chat[string], sender string, channel chat.Channel, content string, err error) {
+		rejected++
+	})
+	c.Join("a", "")
+	c.Mute("a", 20*time.Millisecond)
+	if err := c.SendRoom("a", "hello"); !errors.Is(err, chat.ErrMuted) || rejected != 1 {
+		t.Fatalf("expected ErrMuted, got %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if err := c.SendRoom("a", "hello"); err != nil {
+		t.Fatalf("expected the mute to expire, got %v", err)
+	}
+	if err := c.SendTeam("a", "hello"); !errors.Is(err, chat.ErrNoTeam) {
+		t.Fatalf("expected ErrNoTeam, got %v", err)
+	}
+}
+
+func TestChat_RateLimitAndFilter(t *testing.T) {
+	var content string
+	c := chat.NewChat[string](
+		chat.WithRateLimit[string](2, time.Minute),
+		chat.WithFilter(func(sender string, channel chat.Channel, content string) (string, error) {
+			return strings.ReplaceAll(content, "bad", "***"), nil
+		}),
+	)
+	c.RegMessageEvent(func(c *chat.Chat[string], message *chat.Message[string]) {
+		content = message.Content
+	})
+	c.Join("a", "")
+	_ = c.SendRoom("a", "bad word")
+	if content != "*** word" {
+		t.Fatalf("expected the content to be filtered, got %s", content)
+	}
+	_ = c.SendRoom("a", "hello")
+	if err := c.SendRoom("a", "hello"); !errors.Is(err, chat.ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+}
@@ -0,0 +1,18 @@
+package chat
+
+import "errors"
+
+var (
+	// ErrNotMember 发送者不是聊天成员
+	ErrNotMember = errors.New("chat: sender is not a member")
+	// ErrReceiverNotMember 私聊的接收者不是聊天成员
+	ErrReceiverNotMember = errors.New("chat: receiver is not a member")
+	// ErrMuted 发送者已被禁言
+	ErrMuted = errors.New("chat: sender is muted")
+	// ErrRateLimited 发送者发送消息过于频繁
+	ErrRateLimited = errors.New("chat: rate limited")
+	// ErrEmptyContent 消息内容为空
+	ErrEmptyContent = errors.New("chat: empty content")
+	// ErrNoTeam 发送者没有所属的队伍
+	ErrNoTeam = errors.New("chat: sender has no team")
+)
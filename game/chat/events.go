@@ -0,0 +1,49 @@
+package chat
+
+type (
+	MessageEventHandle[ID comparable]         func(chat *Chat[ID], message *Message[ID])
+	MessageRejectedEventHandle[ID comparable] func(chat *Chat[ID], sender ID, channel Channel, content string, err error)
+	MuteEventHandle[ID comparable]            func(chat *Chat[ID], id ID, muted bool)
+)
+
+type events[ID comparable] struct {
+	messageEventHandles         []MessageEventHandle[ID]
+	messageRejectedEventHandles []MessageRejectedEventHandle[ID]
+	muteEventHandles            []MuteEventHandle[ID]
+}
+
+// RegMessageEvent 在消息发送成功时将立即执行被注册的事件处理函数，通常在该事件中将消息写入 message.Receivers 的连接中
+func (slf *events[ID]) RegMessageEvent(handle MessageEventHandle[ID]) {
+	slf.messageEventHandles = append(slf.messageEventHandles, handle)
+}
+
+// OnMessageEvent 在消息发送成功时将立即执行被注册的事件处理函数
+func (slf *events[ID]) OnMessageEvent(chat *Chat[ID], message *Message[ID]) {
+	for _, handle := range slf.messageEventHandles {
+		handle(chat, message)
+	}
+}
+
+// RegMessageRejectedEvent 在消息因禁言、频率限制或内容过滤等原因被拒绝时将立即执行被注册的事件处理函数
+func (slf *events[ID]) RegMessageRejectedEvent(handle MessageRejectedEventHandle[ID]) {
+	slf.messageRejectedEventHandles = append(slf.messageRejectedEventHandles, handle)
+}
+
+// OnMessageRejectedEvent 在消息被拒绝时将立即执行被注册的事件处理函数
+func (slf *events[ID]) OnMessageRejectedEvent(chat *Chat[ID], sender ID, channel Channel, content string, err error) {
+	for _, handle := range slf.messageRejectedEventHandles {
+		handle(chat, sender, channel, content, err)
+	}
+}
+
+// RegMuteEvent 在成员被禁言或解除禁言时将立即执行被注册的事件处理函数
+func (slf *events[ID]) RegMuteEvent(handle MuteEventHandle[ID]) {
+	slf.muteEventHandles = append(slf.muteEventHandles, handle)
+}
+
+// OnMuteEvent 在成员被禁言或解除禁言时将立即执行被注册的事件处理函数
+func (slf *events[ID]) OnMuteEvent(chat *Chat[ID], id ID, muted bool) {
+	for _, handle := range slf.muteEventHandles {
+		handle(chat, id, muted)
+	}
+}
@@ -0,0 +1,30 @@
+package chat
+
+import "time"
+
+type Option[ID comparable] func(chat *Chat[ID])
+
+// WithRateLimit 通过限制每个成员在 per 时间内最多发送 count 条消息的方式创建聊天
+//   - 超出限制的消息将被拒绝并返回 ErrRateLimited，默认情况下不限制
+func WithRateLimit[ID comparable](count int, per time.Duration) Option[ID] {
+	return func(chat *Chat[ID]) {
+		if count > 0 && per > 0 {
+			chat.rateCount, chat.ratePer = count, per
+		}
+	}
+}
+
+// WithFilter 通过内容过滤器创建聊天，过滤器可对消息内容进行替换（例如屏蔽敏感词），返回错误时消息将被拒绝
+//   - 存在多个过滤器时将按照顺序执行，后一个过滤器接收到的内容为前一个过滤器的处理结果
+func WithFilter[ID comparable](filter func(sender ID, channel Channel, content string) (string, error)) Option[ID] {
+	return func(chat *Chat[ID]) {
+		chat.filters = append(chat.filters, filter)
+	}
+}
+
+// WithMaxLength 通过限制消息内容最大长度（按字符计算）的方式创建聊天，超出部分将被截断，默认情况下不限制
+func WithMaxLength[ID comparable](length int) Option[ID] {
+	return func(chat *Chat[ID]) {
+		chat.maxLength = length
+	}
+}
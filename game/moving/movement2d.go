@@ -0,0 +1,271 @@
+package moving
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"github.com/kercylan98/minotaur/utils/random"
+	"github.com/kercylan98/minotaur/utils/timer"
+	"sync"
+	"time"
+)
+
+// NewMovement2D 创建一个基于逐帧更新的2D对象移动管理器(Movement2D)
+//   - 默认情况下需要通过 Update 手动驱动更新，适用于帧同步等需要确定性结果的场景
+//   - 可通过 WithMovement2DTicker 使用 timer.Ticker 定时驱动更新
+func NewMovement2D(options ...Movement2DOption) *Movement2D {
+	movement := &Movement2D{
+		entities:   make(map[int64]*movement2DState),
+		tickerName: fmt.Sprintf("movement2d_%s", random.HostName()),
+	}
+	for _, option := range options {
+		option(movement)
+	}
+	if movement.ticker != nil {
+		movement.last = time.Now()
+		movement.ticker.Loop(movement.tickerName, movement.interval, movement.interval, timer.Forever, movement.tick)
+	}
+	return movement
+}
+
+// Movement2D 2D对象移动管理器，支持目标点移动、路径跟随、速度移动及瞬移
+//   - 对象的移动速度通过 TwoDimensionalEntity.GetSpeed 获取，单位为每秒移动的距离
+//   - 对象的位置通过 TwoDimensionalEntity.SetPosition 进行更新
+//   - 事件将在释放锁之后触发，因此可以在事件处理函数中继续对对象发起移动
+type Movement2D struct {
+	rw         sync.Mutex
+	entities   map[int64]*movement2DState
+	ticker     *timer.Ticker
+	tickerName string
+	interval   time.Duration
+	last       time.Time // 上一次定时更新的时间
+
+	positionChangeEventHandles []Movement2DPositionChangeEventHandle
+	waypointEventHandles       []Movement2DWaypointEventHandle
+	arrivalEventHandles        []Movement2DArrivalEventHandle
+	stopEventHandles           []Movement2DStopEventHandle
+	teleportEventHandles       []Movement2DTeleportEventHandle
+}
+
+// movement2DState 对象的移动状态
+type movement2DState struct {
+	entity   TwoDimensionalEntity
+	path     []geometry.Point[float64] // 剩余的路径点
+	velocity *geometry.Point[float64]  // 速度移动时每秒在 x、y 轴上移动的距离
+}
+
+// MoveTo 设置对象移动到特定位置，将覆盖对象当前的移动
+func (slf *Movement2D) MoveTo(entity TwoDimensionalEntity, x, y float64) {
+	slf.MoveAlong(entity, geometry.NewPoint(x, y))
+}
+
+// MoveAlong 设置对象沿着特定路径移动，将覆盖对象当前的移动
+//   - 对象每到达一个中间路径点时将触发 WaypointEvent，到达最后一个路径点时将触发 ArrivalEvent
+//   - 当路径为空时将停止对象的移动
+func (slf *Movement2D) MoveAlong(entity TwoDimensionalEntity, path ...geometry.Point[float64]) {
+	if len(path) == 0 {
+		slf.StopMove(entity.GetGuid())
+		return
+	}
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	slf.entities[entity.GetGuid()] = &movement2DState{
+		entity: entity,
+		path:   append([]geometry.Point[float64](nil), path...),
+	}
+}
+
+// MoveWithVelocity 设置对象以特定速度持续移动，直到调用 StopMove 或发起其他移动，将覆盖对象当前的移动
+//   - vx、vy 为每秒在 x、y 轴上移动的距离，该方式不使用 TwoDimensionalEntity.GetSpeed
+//   - 当 vx、vy 均为 0 时将停止对象的移动
+func (slf *Movement2D) MoveWithVelocity(entity TwoDimensionalEntity, vx, vy float64) {
+	if vx == 0 && vy == 0 {
+		slf.StopMove(entity.GetGuid())
+		return
+	}
+	velocity := geometry.NewPoint(vx, vy)
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	slf.entities[entity.GetGuid()] = &movement2DState{
+		entity:   entity,
+		velocity: &velocity,
+	}
+}
+
+// MoveTowards 设置对象以 TwoDimensionalEntity.GetSpeed 的速度朝着特定角度持续移动，直到调用 StopMove 或发起其他移动
+func (slf *Movement2D) MoveTowards(entity TwoDimensionalEntity, angle float64) {
+	vx, vy := geometry.CalcNewCoordinate(0, 0, angle, entity.GetSpeed())
+	slf.MoveWithVelocity(entity, vx, vy)
+}
+
+// Teleport 将对象瞬移到特定位置，对象当前的移动将被停止，并触发 TeleportEvent
+func (slf *Movement2D) Teleport(entity TwoDimensionalEntity, x, y float64) {
+	slf.rw.Lock()
+	delete(slf.entities, entity.GetGuid())
+	oldX, oldY := entity.GetPosition()
+	entity.SetPosition(x, y)
+	slf.rw.Unlock()
+	slf.OnTeleportEvent(entity, oldX, oldY)
+}
+
+// StopMove 停止特定对象的移动，当对象正在移动时将触发 StopEvent
+func (slf *Movement2D) StopMove(guid int64) {
+	slf.rw.Lock()
+	state, exist := slf.entities[guid]
+	delete(slf.entities, guid)
+	slf.rw.Unlock()
+	if exist {
+		slf.OnStopEvent(state.entity)
+	}
+}
+
+// IsMoving 检查对象是否正在移动
+func (slf *Movement2D) IsMoving(guid int64) bool {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	_, exist := slf.entities[guid]
+	return exist
+}
+
+// GetPath 获取对象剩余的移动路径，当对象未在沿路径移动时将返回 nil
+func (slf *Movement2D) GetPath(guid int64) []geometry.Point[float64] {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	state, exist := slf.entities[guid]
+	if !exist || len(state.path) == 0 {
+		return nil
+	}
+	return append([]geometry.Point[float64](nil), state.path...)
+}
+
+// GetMovingCount 获取正在移动的对象数量
+func (slf *Movement2D) GetMovingCount() int {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	return len(slf.entities)
+}
+
+// Update 将所有正在移动的对象推进 delta 时长
+//   - 使用 WithMovement2DTicker 时将自动调用，否则需要手动调用，例如在帧同步的每一帧中以固定的 delta 调用
+func (slf *Movement2D) Update(delta time.Duration) {
+	if delta <= 0 {
+		return
+	}
+	var events []func()
+	seconds := delta.Seconds()
+
+	slf.rw.Lock()
+	for guid, state := range slf.entities {
+		entity := state.entity
+		oldX, oldY := entity.GetPosition()
+		if state.velocity != nil {
+			entity.SetPosition(oldX+state.velocity.GetX()*seconds, oldY+state.velocity.GetY()*seconds)
+			events = append(events, func() { slf.OnPositionChangeEvent(entity, oldX, oldY) })
+			continue
+		}
+
+		x, y := oldX, oldY
+		remaining := entity.GetSpeed() * seconds
+		for len(state.path) > 0 && remaining > 0 {
+			tx, ty := state.path[0].GetXY()
+			distance := geometry.CalcDistanceWithCoordinate(x, y, tx, ty)
+			if distance > remaining {
+				x, y = geometry.CalcNewCoordinate(x, y, geometry.CalcAngle(x, y, tx, ty), remaining)
+				break
+			}
+			x, y = tx, ty
+			remaining -= distance
+			state.path = state.path[1:]
+			if len(state.path) > 0 {
+				events = append(events, func() { slf.OnWaypointEvent(entity, tx, ty) })
+			}
+		}
+		if x != oldX || y != oldY {
+			entity.SetPosition(x, y)
+			events = append(events, func() { slf.OnPositionChangeEvent(entity, oldX, oldY) })
+		}
+		if len(state.path) == 0 {
+			delete(slf.entities, guid)
+			events = append(events, func() { slf.OnArrivalEvent(entity) })
+		}
+	}
+	slf.rw.Unlock()
+
+	for _, event := range events {
+		event()
+	}
+}
+
+// tick 以距离上一次定时更新实际经过的时长进行更新
+func (slf *Movement2D) tick() {
+	now := time.Now()
+	slf.rw.Lock()
+	delta := now.Sub(slf.last)
+	slf.last = now
+	slf.rw.Unlock()
+	slf.Update(delta)
+}
+
+// Release 释放移动管理器，停止定时驱动的更新
+func (slf *Movement2D) Release() {
+	if slf.ticker != nil {
+		slf.ticker.StopTimer(slf.tickerName)
+	}
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	slf.entities = make(map[int64]*movement2DState)
+}
+
+// RegPositionChangeEvent 在对象位置因移动而改变时将执行注册的事件处理函数
+func (slf *Movement2D) RegPositionChangeEvent(handle Movement2DPositionChangeEventHandle) {
+	slf.positionChangeEventHandles = append(slf.positionChangeEventHandles, handle)
+}
+
+func (slf *Movement2D) OnPositionChangeEvent(entity TwoDimensionalEntity, oldX, oldY float64) {
+	for _, handle := range slf.positionChangeEventHandles {
+		handle(slf, entity, oldX, oldY)
+	}
+}
+
+// RegWaypointEvent 在对象到达路径中的中间路径点时将执行注册的事件处理函数
+func (slf *Movement2D) RegWaypointEvent(handle Movement2DWaypointEventHandle) {
+	slf.waypointEventHandles = append(slf.waypointEventHandles, handle)
+}
+
+func (slf *Movement2D) OnWaypointEvent(entity TwoDimensionalEntity, x, y float64) {
+	for _, handle := range slf.waypointEventHandles {
+		handle(slf, entity, x, y)
+	}
+}
+
+// RegArrivalEvent 在对象到达目标点或路径终点时将执行注册的事件处理函数
+func (slf *Movement2D) RegArrivalEvent(handle Movement2DArrivalEventHandle) {
+	slf.arrivalEventHandles = append(slf.arrivalEventHandles, handle)
+}
+
+func (slf *Movement2D) OnArrivalEvent(entity TwoDimensionalEntity) {
+	for _, handle := range slf.arrivalEventHandles {
+		handle(slf, entity)
+	}
+}
+
+// RegStopEvent 在对象通过 StopMove 停止移动时将执行注册的事件处理函数
+func (slf *Movement2D) RegStopEvent(handle Movement2DStopEventHandle) {
+	slf.stopEventHandles = append(slf.stopEventHandles, handle)
+}
+
+func (slf *Movement2D) OnStopEvent(entity TwoDimensionalEntity) {
+	for _, handle := range slf.stopEventHandles {
+		handle(slf, entity)
+	}
+}
+
+// RegTeleportEvent 在对象通过 Teleport 瞬移时将执行注册的事件处理函数
+func (slf *Movement2D) RegTeleportEvent(handle Movement2DTeleportEventHandle) {
+	slf.teleportEventHandles = append(slf.teleportEventHandles, handle)
+}
+
+func (slf *Movement2D) OnTeleportEvent(entity TwoDimensionalEntity, oldX, oldY float64) {
+	for _, handle := range slf.teleportEventHandles {
+		handle(slf, entity, oldX, oldY)
+	}
+}
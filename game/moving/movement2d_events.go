@@ -0,0 +1,9 @@
+package moving
+
+type (
+	Movement2DPositionChangeEventHandle func(movement *Movement2D, entity TwoDimensionalEntity, oldX, oldY float64)
+	Movement2DWaypointEventHandle       func(movement *Movement2D, entity TwoDimensionalEntity, x, y float64)
+	Movement2DArrivalEventHandle        func(movement *Movement2D, entity TwoDimensionalEntity)
+	Movement2DStopEventHandle           func(movement *Movement2D, entity TwoDimensionalEntity)
+	Movement2DTeleportEventHandle       func(movement *Movement2D, entity TwoDimensionalEntity, oldX, oldY float64)
+)
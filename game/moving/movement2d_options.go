@@ -0,0 +1,21 @@
+package moving
+
+import (
+	"github.com/kercylan98/minotaur/utils/timer"
+	"time"
+)
+
+type Movement2DOption func(movement *Movement2D)
+
+// WithMovement2DTicker 通过特定的定时器驱动更新，每隔 interval 将以实际经过的时长调用一次 Movement2D.Update
+//   - 例如通过 server.Server.GetTicker 获取的定时器，使移动在服务器的消息循环中执行
+//   - interval 小于等于 0 时将默认为 100 毫秒
+func WithMovement2DTicker(ticker *timer.Ticker, interval time.Duration) Movement2DOption {
+	return func(movement *Movement2D) {
+		if interval <= 0 {
+			interval = time.Millisecond * 100
+		}
+		movement.ticker = ticker
+		movement.interval = interval
+	}
+}
@@ -0,0 +1,84 @@
+package moving_test
+
+import (
+	"github.com/kercylan98/minotaur/game/moving"
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"github.com/kercylan98/minotaur/utils/timer"
+	"testing"
+	"time"
+)
+
+func TestMovement2D_MoveAlong(t *testing.T) {
+	m := moving.NewMovement2D()
+	defer m.Release()
+
+	var waypoints, arrivals int
+	m.RegWaypointEvent(func(movement *moving.Movement2D, entity moving.TwoDimensionalEntity, x, y float64) {
+		waypoints++
+	})
+	m.RegArrivalEvent(func(movement *moving.Movement2D, entity moving.TwoDimensionalEntity) {
+		arrivals++
+	})
+
+	entity := NewEntity(1, 10)
+	m.MoveAlong(entity, geometry.NewPoint(10.0, 0), geometry.NewPoint(10.0, 10))
+	m.Update(time.Millisecond * 500)
+	if x, y := entity.GetPosition(); x != 5 || y != 0 {
+		t.Fatalf("unexpected position: %f, %f", x, y)
+	}
+	m.Update(time.Second)
+	if x, y := entity.GetPosition(); x != 10 || y < 4.99 || y > 5.01 || waypoints != 1 {
+		t.Fatalf("unexpected position: %f, %f, waypoints: %d", x, y, waypoints)
+	}
+	m.Update(time.Second)
+	if x, y := entity.GetPosition(); x != 10 || y != 10 || arrivals != 1 || m.IsMoving(1) {
+		t.Fatalf("unexpected position: %f, %f, arrivals: %d", x, y, arrivals)
+	}
+}
+
+func TestMovement2D_VelocityAndTeleport(t *testing.T) {
+	m := moving.NewMovement2D()
+	defer m.Release()
+
+	var stopped, teleported bool
+	m.RegStopEvent(func(movement *moving.Movement2D, entity moving.TwoDimensionalEntity) {
+		stopped = true
+	})
+	m.RegTeleportEvent(func(movement *moving.Movement2D, entity moving.TwoDimensionalEntity, oldX, oldY float64) {
+		teleported = oldX == 2 && oldY == -4
+	})
+
+	entity := NewEntity(1, 10)
+	m.MoveWithVelocity(entity, 1, -2)
+	m.Update(time.Second * 2)
+	if x, y := entity.GetPosition(); x != 2 || y != -4 {
+		t.Fatalf("unexpected position: %f, %f", x, y)
+	}
+	m.Teleport(entity, 100, 100)
+	if x, y := entity.GetPosition(); x != 100 || y != 100 || !teleported || m.IsMoving(1) {
+		t.Fatalf("unexpected position: %f, %f", x, y)
+	}
+	m.MoveTo(entity, 0, 0)
+	m.StopMove(1)
+	if !stopped {
+		t.Fatal("stop event not fired")
+	}
+}
+
+func TestMovement2D_Ticker(t *testing.T) {
+	ticker := timer.GetTicker(10)
+	defer ticker.Release()
+	m := moving.NewMovement2D(moving.WithMovement2DTicker(ticker, time.Millisecond*10))
+	defer m.Release()
+
+	var arrived = make(chan struct{}, 1)
+	m.RegArrivalEvent(func(movement *moving.Movement2D, entity moving.TwoDimensionalEntity) {
+		arrived <- struct{}{}
+	})
+	m.MoveTo(NewEntity(1, 100), 10, 10)
+	select {
+	case <-arrived:
+	case <-time.After(time.Second):
+		t.Fatal("arrival event not fired")
+	}
+}
@@ -0,0 +1,51 @@
+package space
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+)
+
+// roomAffinityKey 连接数据中记录连接所在房间的键
+type roomAffinityKey struct{}
+
+// RoomShardStrategy 以连接所在房间作为分片键的分片策略，通过 server.WithShard 使用后，同一房间内所有实体的数据包将在同一个分片分发器中串行处理，房间内的游戏逻辑无需加锁
+//   - 连接所在的房间通过 RoomManager.BindConn 绑定的实体进行记录，实体加入房间后其连接将记录该房间，离开房间后将恢复以连接 ID 作为分片键
+//   - 当实体同时存在于多个房间时，以最后加入的房间为准
+//   - 不同房间的数据包仍可能并行处理，跨房间访问共享数据时需要自行保证线程安全
+//   - 分片键在实体加入或离开房间时立即切换，切换前已进入原分片队列但尚未处理的数据包仍将在原分片中处理，可能与房间分片中的数据包并行且乱序执行，
+//     因此房间内无需加锁的保证仅适用于切换之后收到的数据包。建议在连接的数据包处理中完成加入房间，并由客户端在收到加入结果后再发送房间内的数据包
+//
+// 例如：
+//
+//	srv := server.New(server.NetworkWebsocket, server.WithShard(runtime.NumCPU(), space.RoomShardStrategy()))
+func RoomShardStrategy() server.ShardStrategy {
+	return server.ShardByData(roomAffinityKey{})
+}
+
+// GetConnRoomAffinity 获取连接当前用于分片的房间，当连接不在任何房间中时将返回空字符串
+func GetConnRoomAffinity(conn *server.Conn) string {
+	if value, ok := conn.GetData(roomAffinityKey{}).(string); ok {
+		return value
+	}
+	return ""
+}
+
+// roomAffinity 获取房间用于分片的键
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) roomAffinity() string {
+	return fmt.Sprintf("space.room.%p.%v", slf.manager, slf.room.GetId())
+}
+
+// bindAffinity 将实体绑定的连接与房间关联
+//   - 已进入原分片队列的数据包不会被迁移，参见 RoomShardStrategy
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) bindAffinity(id EntityID) {
+	if conn := slf.manager.GetConn(id); conn != nil {
+		conn.SetData(roomAffinityKey{}, slf.roomAffinity())
+	}
+}
+
+// unbindAffinity 当实体绑定的连接与房间关联时解除关联
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) unbindAffinity(id EntityID) {
+	if conn := slf.manager.GetConn(id); conn != nil && GetConnRoomAffinity(conn) == slf.roomAffinity() {
+		conn.SetData(roomAffinityKey{}, nil)
+	}
+}
@@ -0,0 +1,56 @@
+package space_test
+
+import (
+	"github.com/kercylan98/minotaur/game/space"
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+)
+
+func TestRoomShardStrategy(t *testing.T) {
+	srv := server.New(server.NetworkNone)
+	strategy := space.RoomShardStrategy()
+	manager := newTestRoomManager()
+	room := manager.AssumeControl(&testRoom{id: "room"})
+	other := manager.AssumeControl(&testRoom{id: "other"})
+	var conns = map[string]*server.Conn{}
+	for _, id := range []string{"a", "b", "c"} {
+		conns[id] = server.NewGatewayConn(srv, "127.0.0.1", func(packet []byte) {})
+		manager.BindConn(id, conns[id])
+	}
+
+	// 加入房间前以连接 ID 作为分片键
+	for _, conn := range conns {
+		if key := strategy(conn); key != conn.GetID() || space.GetConnRoomAffinity(conn) != "" {
+			t.Fatalf("expected shard key %s, got %s", conn.GetID(), key)
+		}
+	}
+
+	// 加入房间后同一房间内的连接具有相同的分片键，此后收到的数据包将在同一个分片中串行处理
+	_ = room.AddEntity(&testEntity{id: "a"})
+	_ = room.AddEntity(&testEntity{id: "b"})
+	_ = other.AddEntity(&testEntity{id: "c"})
+	if strategy(conns["a"]) != strategy(conns["b"]) || strategy(conns["a"]) == conns["a"].GetID() {
+		t.Fatal("expected entities in the same room to share the room shard key")
+	}
+	if strategy(conns["a"]) == strategy(conns["c"]) {
+		t.Fatal("expected entities in different rooms to use different shard keys")
+	}
+
+	// 离开非当前关联的房间不会影响分片键，离开当前关联的房间后恢复以连接 ID 作为分片键
+	_ = other.AddEntity(&testEntity{id: "a"})
+	room.RemoveEntity("a")
+	if strategy(conns["a"]) != strategy(conns["c"]) {
+		t.Fatal("expected the last joined room to be kept as the shard key")
+	}
+	other.RemoveEntity("a")
+	if key := strategy(conns["a"]); key != conns["a"].GetID() {
+		t.Fatalf("expected shard key %s, got %s", conns["a"].GetID(), key)
+	}
+
+	// 已在房间中的实体绑定连接时将立即关联房间
+	conn := server.NewGatewayConn(srv, "127.0.0.1", func(packet []byte) {})
+	manager.BindConn("b", conn)
+	if strategy(conn) != strategy(conns["b"]) {
+		t.Fatal("expected BindConn to associate the connection with the room")
+	}
+}
@@ -0,0 +1,70 @@
+package space
+
+import (
+	"github.com/kercylan98/minotaur/utils/generic"
+	"github.com/kercylan98/minotaur/utils/hash"
+)
+
+// SetAttr 设置房间属性，例如房间名称、地图、模式等，设置后将触发房间属性改变事件
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) SetAttr(key string, value any) {
+	slf.attrsRWMutex.Lock()
+	old := slf.attrs[key]
+	slf.attrs[key] = value
+	slf.attrsRWMutex.Unlock()
+	slf.manager.OnRoomAttrChangeEvent(slf, key, old, value)
+}
+
+// DelAttr 删除房间属性，删除后将触发房间属性改变事件，新的属性值为 nil
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) DelAttr(key string) {
+	slf.attrsRWMutex.Lock()
+	old, exist := slf.attrs[key]
+	delete(slf.attrs, key)
+	slf.attrsRWMutex.Unlock()
+	if exist {
+		slf.manager.OnRoomAttrChangeEvent(slf, key, old, nil)
+	}
+}
+
+// GetAttr 获取房间属性，当属性不存在时将返回 nil，如需获取特定类型的属性可使用 GetRoomAttr
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) GetAttr(key string) any {
+	slf.attrsRWMutex.RLock()
+	defer slf.attrsRWMutex.RUnlock()
+	return slf.attrs[key]
+}
+
+// HasAttr 检查房间是否存在特定属性
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) HasAttr(key string) bool {
+	slf.attrsRWMutex.RLock()
+	defer slf.attrsRWMutex.RUnlock()
+	_, exist := slf.attrs[key]
+	return exist
+}
+
+// GetAttrs 获取房间的所有属性
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) GetAttrs() map[string]any {
+	slf.attrsRWMutex.RLock()
+	defer slf.attrsRWMutex.RUnlock()
+	return hash.Copy(slf.attrs)
+}
+
+// GetRoomAttr 获取房间特定类型的属性，当属性不存在或类型不匹配时 ok 将返回 false
+func GetRoomAttr[T any, EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]](controller *RoomController[EntityID, RoomID, Entity, Room], key string) (value T, ok bool) {
+	value, ok = controller.GetAttr(key).(T)
+	return
+}
+
+// GetRoomAttrOrDefault 获取房间特定类型的属性，当属性不存在或类型不匹配时将返回 defaultValue
+func GetRoomAttrOrDefault[T any, EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]](controller *RoomController[EntityID, RoomID, Entity, Room], key string, defaultValue T) T {
+	if value, ok := GetRoomAttr[T](controller, key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+// GetRoomsByAttr 获取特定属性等于 value 的所有房间
+func GetRoomsByAttr[T comparable, EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]](manager *RoomManager[EntityID, RoomID, Entity, Room], key string, value T) map[RoomID]*RoomController[EntityID, RoomID, Entity, Room] {
+	return manager.FilterRooms(func(controller *RoomController[EntityID, RoomID, Entity, Room]) bool {
+		attr, ok := GetRoomAttr[T](controller, key)
+		return ok && attr == value
+	})
+}
@@ -0,0 +1,87 @@
+package space_test
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/game/space"
+	"testing"
+)
+
+func TestRoomController_SetAttr(t *testing.T) {
+	manager := newTestRoomManager()
+	var events []string
+	manager.RegRoomAttrChangeEvent(func(controller *testRoomController, key string, old, value any) {
+		events = append(events, fmt.Sprintf("%s:%v->%v", key, old, value))
+	})
+	room := manager.AssumeControl(&testRoom{id: "room"}, space.NewRoomControllerOptions().WithAttr("map", "desert"))
+
+	// 初始属性不会触发房间属性改变事件
+	if len(events) != 0 || room.GetAttr("map") != "desert" {
+		t.Fatalf("unexpected initial attrs %v, events %v", room.GetAttrs(), events)
+	}
+
+	room.SetAttr("mode", "rank")
+	room.SetAttr("map", "forest")
+	room.DelAttr("map")
+	room.DelAttr("map")
+	if room.HasAttr("map") || !room.HasAttr("mode") {
+		t.Fatalf("unexpected attrs %v", room.GetAttrs())
+	}
+	var expected = []string{"mode:<nil>->rank", "map:desert->forest", "map:forest-><nil>"}
+	if len(events) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, events)
+	}
+	for i, event := range expected {
+		if events[i] != event {
+			t.Fatalf("expected events %v, got %v", expected, events)
+		}
+	}
+
+	// GetAttrs 返回的是属性的副本
+	attrs := room.GetAttrs()
+	attrs["mode"] = "casual"
+	if room.GetAttr("mode") != "rank" {
+		t.Fatal("expected GetAttrs to return a copy")
+	}
+}
+
+func TestGetRoomAttr(t *testing.T) {
+	manager := newTestRoomManager()
+	room := manager.AssumeControl(&testRoom{id: "room"})
+	room.SetAttr("level", 3)
+	room.SetAttr("name", "lobby")
+
+	var cases = []struct {
+		name     string
+		key      string
+		expected int
+		ok       bool
+	}{
+		{"matched", "level", 3, true},
+		{"mismatched type", "name", 10, false},
+		{"missing", "unknown", 10, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			value, ok := space.GetRoomAttr[int](room, c.key)
+			if ok != c.ok || (ok && value != c.expected) || (!ok && value != 0) {
+				t.Fatalf("expected %v %v, got %v %v", c.expected, c.ok, value, ok)
+			}
+			if value = space.GetRoomAttrOrDefault(room, c.key, 10); value != c.expected {
+				t.Fatalf("expected %v, got %v", c.expected, value)
+			}
+		})
+	}
+}
+
+func TestGetRoomsByAttr(t *testing.T) {
+	manager := newTestRoomManager()
+	manager.AssumeControl(&testRoom{id: "r0"}, space.NewRoomControllerOptions().WithAttr("mode", "rank"))
+	manager.AssumeControl(&testRoom{id: "r1"}, space.NewRoomControllerOptions().WithAttr("mode", "casual"))
+	manager.AssumeControl(&testRoom{id: "r2"}).SetAttr("mode", "rank")
+	manager.AssumeControl(&testRoom{id: "r3"}).SetAttr("mode", 1)
+
+	rooms := space.GetRoomsByAttr(manager, "mode", "rank")
+	if len(rooms) != 2 || rooms["r0"] == nil || rooms["r2"] == nil {
+		t.Fatalf("expected rooms r0 and r2, got %v", rooms)
+	}
+}
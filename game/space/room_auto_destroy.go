@@ -0,0 +1,81 @@
+package space
+
+import (
+	"fmt"
+)
+
+const (
+	RoomAutoDestroyReasonEmpty    RoomAutoDestroyReason = iota + 1 // 房间为空的时长达到上限
+	RoomAutoDestroyReasonLifetime                                  // 房间的存活时长达到上限
+)
+
+var roomAutoDestroyReasonNames = map[RoomAutoDestroyReason]string{
+	RoomAutoDestroyReasonEmpty:    "Empty",
+	RoomAutoDestroyReasonLifetime: "Lifetime",
+}
+
+// RoomAutoDestroyReason 房间自动销毁的原因
+type RoomAutoDestroyReason byte
+
+// String 返回房间自动销毁原因的字符串表示
+func (slf RoomAutoDestroyReason) String() string {
+	return roomAutoDestroyReasonNames[slf]
+}
+
+// autoDestroyTimerName 获取房间自动销毁定时器的名称
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) autoDestroyTimerName(reason RoomAutoDestroyReason) string {
+	return fmt.Sprintf("space.room.autoDestroy.%p.%v.%s", slf.manager, slf.room.GetId(), reason)
+}
+
+// armLifetimeDestroy 在房间被接管后开始最大存活时长的计时
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) armLifetimeDestroy() {
+	if slf.manager.options.maxLifetime <= 0 {
+		return
+	}
+	slf.manager.ticker.After(slf.autoDestroyTimerName(RoomAutoDestroyReasonLifetime), slf.manager.options.maxLifetime, func() {
+		slf.autoDestroy(RoomAutoDestroyReasonLifetime)
+	})
+}
+
+// armEmptyDestroy 在房间为空时开始计时，房间不为空时停止计时（无锁）
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) armEmptyDestroy() {
+	if slf.manager.options.emptyDestroy <= 0 {
+		return
+	}
+	name := slf.autoDestroyTimerName(RoomAutoDestroyReasonEmpty)
+	if len(slf.entities) > 0 {
+		slf.manager.ticker.StopTimer(name)
+		return
+	}
+	if !slf.manager.ticker.IsStopped(name) {
+		return
+	}
+	slf.manager.ticker.After(name, slf.manager.options.emptyDestroy, func() {
+		slf.entitiesRWMutex.RLock()
+		empty := len(slf.entities) == 0
+		slf.entitiesRWMutex.RUnlock()
+		if empty {
+			slf.autoDestroy(RoomAutoDestroyReasonEmpty)
+		}
+	})
+}
+
+// disarmAutoDestroy 停止房间所有自动销毁的计时
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) disarmAutoDestroy() {
+	if slf.manager.ticker == nil {
+		return
+	}
+	slf.manager.ticker.StopTimer(slf.autoDestroyTimerName(RoomAutoDestroyReasonEmpty))
+	slf.manager.ticker.StopTimer(slf.autoDestroyTimerName(RoomAutoDestroyReasonLifetime))
+}
+
+// autoDestroy 触发房间自动销毁前事件，当事件未取消销毁且房间仍由管理器接管时销毁房间
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) autoDestroy(reason RoomAutoDestroyReason) {
+	if slf.manager.GetRoom(slf.room.GetId()) != slf {
+		return
+	}
+	if slf.manager.OnRoomAutoDestroyEvent(slf, reason) {
+		return
+	}
+	slf.Destroy()
+}
@@ -0,0 +1,81 @@
+package space_test
+
+import (
+	"github.com/kercylan98/minotaur/game/space"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRoomController_EmptyDestroy(t *testing.T) {
+	manager := newTestRoomManager(space.NewRoomManagerOptions().WithEmptyDestroy(100 * time.Millisecond))
+	var destroyed = make(chan space.RoomAutoDestroyReason, 1)
+	manager.RegRoomAutoDestroyEvent(func(controller *testRoomController, reason space.RoomAutoDestroyReason) (cancel bool) {
+		destroyed <- reason
+		return false
+	})
+	room := manager.AssumeControl(&testRoom{id: "room"})
+
+	// 房间不为空时停止计时
+	_ = room.AddEntity(&testEntity{id: "a"})
+	select {
+	case reason := <-destroyed:
+		t.Fatalf("expected the room not to be destroyed while occupied, got %v", reason)
+	case <-time.After(250 * time.Millisecond):
+	}
+
+	// 房间再次为空时重新开始计时，定时器存在一定的精度误差
+	start := time.Now()
+	room.RemoveEntity("a")
+	select {
+	case reason := <-destroyed:
+		if reason != space.RoomAutoDestroyReasonEmpty {
+			t.Fatalf("expected %v, got %v", space.RoomAutoDestroyReasonEmpty, reason)
+		}
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Fatalf("expected the room to be destroyed after about 100ms, got %v", elapsed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the room to be destroyed")
+	}
+	if manager.GetRoom("room") != nil {
+		t.Fatal("expected the room to be removed from the manager")
+	}
+}
+
+func TestRoomController_LifetimeDestroy(t *testing.T) {
+	manager := newTestRoomManager(space.NewRoomManagerOptions().WithMaxLifetime(100 * time.Millisecond))
+	var events atomic.Int32
+	manager.RegRoomAutoDestroyEvent(func(controller *testRoomController, reason space.RoomAutoDestroyReason) (cancel bool) {
+		events.Add(1)
+		return controller.GetRoom().GetId() == "cancel"
+	})
+	var destroyed = make(chan string, 2)
+	manager.RegRoomDestroyEvent(func(controller *testRoomController) {
+		destroyed <- controller.GetRoom().GetId()
+	})
+	manager.AssumeControl(&testRoom{id: "room"})
+	manager.AssumeControl(&testRoom{id: "cancel"})
+	_ = manager.GetRoom("room").AddEntity(&testEntity{id: "a"})
+
+	// 存活时长达到上限时即使房间不为空也将销毁，事件取消销毁的房间将被保留
+	select {
+	case id := <-destroyed:
+		if id != "room" {
+			t.Fatalf("expected room to be destroyed, got %s", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the room to be destroyed")
+	}
+	select {
+	case id := <-destroyed:
+		t.Fatalf("expected the cancelled room to be kept, got %s destroyed", id)
+	case <-time.After(250 * time.Millisecond):
+	}
+	if manager.GetRoom("cancel") == nil {
+		t.Fatal("expected the cancelled room to be kept")
+	}
+	if count := events.Load(); count != 2 {
+		t.Fatalf("expected 2 auto destroy events, got %d", count)
+	}
+}
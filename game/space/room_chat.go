@@ -0,0 +1,44 @@
+package space
+
+import "github.com/kercylan98/minotaur/game/chat"
+
+// EnableChat 启用房间聊天，房间内的实体及观战者将自动成为聊天成员，加入或离开房间时将自动加入或离开聊天
+//   - 重复调用将返回已启用的聊天，options 将被忽略
+//   - 成员默认不属于任何队伍，可通过 chat.Chat.SetTeam 设置成员所属的队伍以使用队伍频道
+//   - 聊天消息将通过 chat.Chat.RegMessageEvent 等事件交由使用者处理，例如通过 RoomManager.GetConn 获取接收者的连接并写入数据包
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) EnableChat(options ...chat.Option[EntityID]) *chat.Chat[EntityID] {
+	slf.entitiesRWMutex.Lock()
+	defer slf.entitiesRWMutex.Unlock()
+	if slf.chat != nil {
+		return slf.chat
+	}
+	slf.chat = chat.NewChat[EntityID](options...)
+	for id := range slf.entities {
+		slf.chat.Join(id, "")
+	}
+	for id := range slf.spectators {
+		slf.chat.Join(id, "")
+	}
+	return slf.chat
+}
+
+// GetChat 获取房间聊天，当未通过 EnableChat 启用聊天时将返回 nil
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) GetChat() *chat.Chat[EntityID] {
+	slf.entitiesRWMutex.RLock()
+	defer slf.entitiesRWMutex.RUnlock()
+	return slf.chat
+}
+
+// joinChat 当启用了房间聊天时使对象加入聊天（无锁）
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) joinChat(id EntityID) {
+	if slf.chat != nil && !slf.chat.IsMember(id) {
+		slf.chat.Join(id, "")
+	}
+}
+
+// leaveChat 当启用了房间聊天时使对象离开聊天（无锁）
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) leaveChat(id EntityID) {
+	if slf.chat != nil {
+		slf.chat.Leave(id)
+	}
+}
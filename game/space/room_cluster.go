@@ -0,0 +1,334 @@
+package space
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/cluster"
+	"github.com/kercylan98/minotaur/utils/generic"
+	"github.com/kercylan98/minotaur/utils/log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultRoomClusterJoinTimeout = time.Second * 5 // 默认的跨服加入房间超时时间
+	roomClusterIdSeparator        = "#"             // 全局房间 ID 中服务器 ID 与本地 ID 的分隔符
+)
+
+const (
+	roomClusterPacketJoin   byte = iota + 1 // 加入远程房间请求：| magic | kind | roomId | connId | ip | payload |
+	roomClusterPacketIn                     // 转发至房间所在服务器的数据包：| magic | kind | connId | packet |
+	roomClusterPacketOut                    // 转发至客户端所在服务器的数据包：| magic | kind | connId | packet |
+	roomClusterPacketLeave                  // 客户端离开远程房间：| magic | kind | connId |
+	roomClusterPacketDetach                 // 房间所在服务器关闭了远程连接：| magic | kind | connId |
+)
+
+// roomClusterMagic 房间集群数据包的头部标识，用于与其他跨服数据包进行区分
+var roomClusterMagic = []byte{0xff, 'M', 'R', 'C'}
+
+// RoomClusterJoinHandler 房间加入处理函数，conn 为客户端连接，当客户端位于其他服务器时 conn 为通过 server.NewGatewayConn 创建的虚拟连接
+//   - 处理函数通常需要创建实体并通过 RoomController.AddEntity 加入房间，通过 RoomManager.BindConn 绑定连接后即可向远程客户端广播数据包
+//   - 返回错误时将拒绝加入，远程客户端的虚拟连接将被关闭
+type RoomClusterJoinHandler[EntityID comparable, RoomID ~string, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]] func(controller *RoomController[EntityID, RoomID, Entity, Room], conn *server.Conn, payload []byte) error
+
+// NewRoomCluster 创建基于跨服及集群的分布式房间目录，使同一个集群中的服务器能够加入彼此的房间
+//   - 房间 ID 需要通过 RoomCluster.NewRoomID 生成，全局房间 ID 中包含了房间所在的服务器 ID
+//   - 房间所在的服务器通过 cls 进行解析，加入远程房间的请求及后续的数据包将通过 crossName 对应的跨服进行转发
+//   - 客户端加入远程房间后，其数据包将被转发至房间所在的服务器，不再由本服处理，直到离开房间
+//   - 房间集群数据包同样会触发 ReceiveCrossPacketEvent 及 CrossRequestEvent，可通过 IsRoomClusterPacket 进行区分
+func NewRoomCluster[EntityID comparable, RoomID ~string, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]](srv *server.Server, crossName string, cls *cluster.Cluster, manager *RoomManager[EntityID, RoomID, Entity, Room], joinHandler RoomClusterJoinHandler[EntityID, RoomID, Entity, Room]) *RoomCluster[EntityID, RoomID, Entity, Room] {
+	rc := &RoomCluster[EntityID, RoomID, Entity, Room]{
+		srv:         srv,
+		crossName:   crossName,
+		cluster:     cls,
+		manager:     manager,
+		joinHandler: joinHandler,
+		joinTimeout: DefaultRoomClusterJoinTimeout,
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		routes:      make(map[string]string),
+		remotes:     make(map[string]*server.Conn),
+		virtuals:    make(map[string]roomClusterVirtual),
+	}
+	srv.RegConnectionPacketPreprocessEvent(rc.onPacketPreprocess, math.MinInt)
+	srv.RegConnectionClosedEvent(rc.onConnectionClosed, math.MinInt)
+	srv.RegReceiveCrossPacketEvent(rc.onReceiveCrossPacket, math.MinInt)
+	srv.RegCrossRequestEvent(rc.onCrossRequest, math.MinInt)
+	return rc
+}
+
+// RoomCluster 分布式房间目录
+type RoomCluster[EntityID comparable, RoomID ~string, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]] struct {
+	srv         *server.Server
+	crossName   string
+	cluster     *cluster.Cluster
+	manager     *RoomManager[EntityID, RoomID, Entity, Room]
+	joinHandler RoomClusterJoinHandler[EntityID, RoomID, Entity, Room]
+	joinTimeout time.Duration
+	epoch       string        // 服务器启动时的时间戳，用于确保重启后生成的房间 ID 不会重复
+	seq         atomic.Uint64 // 房间 ID 序列
+	rw          sync.RWMutex
+
+	routes   map[string]string             // 加入了远程房间的本服连接 [connId]serverId
+	remotes  map[string]*server.Conn       // 加入了本服房间的远程客户端虚拟连接 [serverId#connId]
+	virtuals map[string]roomClusterVirtual // 虚拟连接信息 [virtual.ID]
+}
+
+// roomClusterVirtual 远程客户端虚拟连接信息
+type roomClusterVirtual struct {
+	serverId string // 客户端所在的服务器 ID
+	connId   string // 客户端在其所在服务器中的连接 ID
+}
+
+// SetJoinTimeout 设置加入远程房间的超时时间，默认为 DefaultRoomClusterJoinTimeout
+func (slf *RoomCluster[EntityID, RoomID, Entity, Room]) SetJoinTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		slf.joinTimeout = timeout
+	}
+}
+
+// NewRoomID 生成包含本服 ID 的全局唯一房间 ID
+func (slf *RoomCluster[EntityID, RoomID, Entity, Room]) NewRoomID() RoomID {
+	return RoomID(fmt.Sprintf("%s%s%s.%d", slf.srv.GetID(), roomClusterIdSeparator, slf.epoch, slf.seq.Add(1)))
+}
+
+// Resolve 解析房间所在的服务器 ID
+//   - 当房间 ID 不是通过 NewRoomID 生成时将返回 ErrRoomIDIllegal
+//   - 当房间所在的服务器不是本服且不存在于集群中时将返回 ErrRoomHostNotFound
+func (slf *RoomCluster[EntityID, RoomID, Entity, Room]) Resolve(roomId RoomID) (serverId string, err error) {
+	index := strings.LastIndex(string(roomId), roomClusterIdSeparator)
+	if index <= 0 {
+		return "", ErrRoomIDIllegal
+	}
+	serverId = string(roomId)[:index]
+	if serverId == slf.srv.GetID() {
+		return serverId, nil
+	}
+	if _, exist := slf.cluster.Get(serverId); !exist {
+		return "", ErrRoomHostNotFound
+	}
+	return serverId, nil
+}
+
+// IsLocal 检查房间是否位于本服
+func (slf *RoomCluster[EntityID, RoomID, Entity, Room]) IsLocal(roomId RoomID) bool {
+	serverId, err := slf.Resolve(roomId)
+	return err == nil && serverId == slf.srv.GetID()
+}
+
+// Join 使连接加入特定房间，当房间位于其他服务器时将通过跨服请求房间所在的服务器，加入成功后该连接的数据包将被转发至房间所在的服务器
+//   - payload 将原样传递给房间所在服务器的 RoomClusterJoinHandler
+//   - 当房间位于本服且不存在时将返回 ErrRoomNotFound
+//   - 由于加入远程房间时将阻塞当前协程等待响应，应避免在消息处理过程中调用，可通过 server.Server.PushAsyncMessage 在异步消息中调用
+func (slf *RoomCluster[EntityID, RoomID, Entity, Room]) Join(conn *server.Conn, roomId RoomID, payload []byte) error {
+	serverId, err := slf.Resolve(roomId)
+	if err != nil {
+		return err
+	}
+	if serverId == slf.srv.GetID() {
+		controller := slf.manager.GetRoom(roomId)
+		if controller == nil {
+			return ErrRoomNotFound
+		}
+		return slf.joinHandler(controller, conn, payload)
+	}
+
+	slf.rw.RLock()
+	_, routed := slf.routes[conn.GetID()]
+	slf.rw.RUnlock()
+	if routed {
+		return ErrAlreadyInRemoteRoom
+	}
+	response, err := slf.srv.CallCross(slf.crossName, serverId, marshalRoomClusterPacket(roomClusterPacketJoin, string(roomId), conn.GetID(), conn.GetIP(), string(payload)), slf.joinTimeout)
+	if err != nil {
+		return err
+	}
+	if len(response) > 0 {
+		return fmt.Errorf("%w: %s", ErrRemoteRoomJoinRejected, response)
+	}
+	slf.rw.Lock()
+	slf.routes[conn.GetID()] = serverId
+	slf.rw.Unlock()
+	return nil
+}
+
+// Leave 使已加入远程房间的连接离开房间，离开后该连接的数据包将重新由本服处理，返回连接是否加入了远程房间
+func (slf *RoomCluster[EntityID, RoomID, Entity, Room]) Leave(conn *server.Conn) bool {
+	slf.rw.Lock()
+	serverId, exist := slf.routes[conn.GetID()]
+	delete(slf.routes, conn.GetID())
+	slf.rw.Unlock()
+	if exist {
+		slf.push(serverId, marshalRoomClusterPacket(roomClusterPacketLeave, conn.GetID()))
+	}
+	return exist
+}
+
+// GetRemoteServerID 获取连接所加入的远程房间所在的服务器 ID
+func (slf *RoomCluster[EntityID, RoomID, Entity, Room]) GetRemoteServerID(conn *server.Conn) (serverId string, exist bool) {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	serverId, exist = slf.routes[conn.GetID()]
+	return
+}
+
+// IsRemote 检查连接是否为加入了本服房间的远程客户端虚拟连接
+func (slf *RoomCluster[EntityID, RoomID, Entity, Room]) IsRemote(conn *server.Conn) bool {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	_, exist := slf.virtuals[conn.GetID()]
+	return exist
+}
+
+// push 推送房间集群数据包到特定服务器
+func (slf *RoomCluster[EntityID, RoomID, Entity, Room]) push(serverId string, packet []byte) {
+	if err := slf.srv.PushCrossMessage(slf.crossName, serverId, packet); err != nil {
+		slf.srv.Logger().Error("RoomCluster", log.String("ServerID", serverId), log.Err(err))
+	}
+}
+
+// onPacketPreprocess 将已加入远程房间的连接的数据包转发至房间所在的服务器
+func (slf *RoomCluster[EntityID, RoomID, Entity, Room]) onPacketPreprocess(srv *server.Server, conn *server.Conn, packet []byte, abort func(), usePacket func(newPacket []byte)) {
+	slf.rw.RLock()
+	serverId, exist := slf.routes[conn.GetID()]
+	slf.rw.RUnlock()
+	if !exist {
+		return
+	}
+	abort()
+	slf.push(serverId, marshalRoomClusterPacket(roomClusterPacketIn, conn.GetID(), string(packet)))
+}
+
+// onConnectionClosed 清理已关闭的连接，本服连接关闭时将通知远程房间，远程客户端虚拟连接被本服关闭时将通知客户端所在的服务器
+func (slf *RoomCluster[EntityID, RoomID, Entity, Room]) onConnectionClosed(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
+	if slf.Leave(conn) {
+		return
+	}
+	slf.rw.Lock()
+	virtual, exist := slf.virtuals[conn.GetID()]
+	if exist {
+		delete(slf.virtuals, conn.GetID())
+		delete(slf.remotes, virtual.serverId+roomClusterIdSeparator+virtual.connId)
+	}
+	slf.rw.Unlock()
+	if exist && reason != server.CloseReasonClientClose {
+		slf.push(virtual.serverId, marshalRoomClusterPacket(roomClusterPacketDetach, virtual.connId))
+	}
+}
+
+// onReceiveCrossPacket 处理房间集群的跨服数据包
+func (slf *RoomCluster[EntityID, RoomID, Entity, Room]) onReceiveCrossPacket(srv *server.Server, crossName, senderServerId string, packet []byte) {
+	if crossName != slf.crossName {
+		return
+	}
+	kind, fields, err := unmarshalRoomClusterPacket(packet)
+	if err != nil || len(fields) == 0 {
+		return
+	}
+	connId := fields[0]
+	switch kind {
+	case roomClusterPacketIn, roomClusterPacketLeave:
+		slf.rw.RLock()
+		virtual, exist := slf.remotes[senderServerId+roomClusterIdSeparator+connId]
+		slf.rw.RUnlock()
+		if !exist {
+			return
+		}
+		if kind == roomClusterPacketLeave {
+			virtual.CloseWithReason(server.CloseReasonClientClose)
+		} else if len(fields) > 1 {
+			srv.PushPacketMessage(virtual, 0, []byte(fields[1]))
+		}
+	case roomClusterPacketOut:
+		if conn := srv.GetOnline(connId); conn != nil && len(fields) > 1 {
+			conn.Write([]byte(fields[1]))
+		}
+	case roomClusterPacketDetach:
+		slf.rw.Lock()
+		if slf.routes[connId] == senderServerId {
+			delete(slf.routes, connId)
+		}
+		slf.rw.Unlock()
+	}
+}
+
+// onCrossRequest 处理加入本服房间的跨服请求，为远程客户端创建虚拟连接并交由 RoomClusterJoinHandler 处理
+func (slf *RoomCluster[EntityID, RoomID, Entity, Room]) onCrossRequest(srv *server.Server, crossName, senderServerId string, packet []byte, reply func(packet []byte) error) {
+	if crossName != slf.crossName {
+		return
+	}
+	kind, fields, err := unmarshalRoomClusterPacket(packet)
+	if err != nil || kind != roomClusterPacketJoin || len(fields) != 4 {
+		return
+	}
+	roomId, connId, ip, payload := RoomID(fields[0]), fields[1], fields[2], []byte(fields[3])
+	controller := slf.manager.GetRoom(roomId)
+	if controller == nil {
+		_ = reply([]byte(ErrRoomNotFound.Error()))
+		return
+	}
+
+	key := senderServerId + roomClusterIdSeparator + connId
+	slf.rw.Lock()
+	virtual, exist := slf.remotes[key]
+	if !exist {
+		virtual = server.NewGatewayConn(srv, ip, func(packet []byte) {
+			slf.push(senderServerId, marshalRoomClusterPacket(roomClusterPacketOut, connId, string(packet)))
+		})
+		slf.remotes[key] = virtual
+		slf.virtuals[virtual.GetID()] = roomClusterVirtual{serverId: senderServerId, connId: connId}
+	}
+	slf.rw.Unlock()
+	if !exist {
+		srv.OnConnectionOpenedEvent(virtual)
+	}
+
+	if err = slf.joinHandler(controller, virtual, payload); err != nil {
+		virtual.CloseWithReason(server.CloseReasonClientClose)
+		_ = reply([]byte(err.Error()))
+		return
+	}
+	_ = reply(nil)
+}
+
+// IsRoomClusterPacket 检查跨服数据包是否为房间集群所使用的数据包
+func IsRoomClusterPacket(packet []byte) bool {
+	return bytes.HasPrefix(packet, roomClusterMagic)
+}
+
+// marshalRoomClusterPacket 编码房间集群数据包，每个字段均以 uvarint 长度作为前缀
+func marshalRoomClusterPacket(kind byte, fields ...string) []byte {
+	var size = len(roomClusterMagic) + 1
+	for _, field := range fields {
+		size += binary.MaxVarintLen64 + len(field)
+	}
+	result := append(make([]byte, 0, size), roomClusterMagic...)
+	result = append(result, kind)
+	for _, field := range fields {
+		result = binary.AppendUvarint(result, uint64(len(field)))
+		result = append(result, field...)
+	}
+	return result
+}
+
+// unmarshalRoomClusterPacket 解码房间集群数据包
+func unmarshalRoomClusterPacket(packet []byte) (kind byte, fields []string, err error) {
+	if !IsRoomClusterPacket(packet) || len(packet) < len(roomClusterMagic)+1 {
+		return 0, nil, ErrRoomClusterInvalidPacket
+	}
+	kind, packet = packet[len(roomClusterMagic)], packet[len(roomClusterMagic)+1:]
+	for len(packet) > 0 {
+		size, n := binary.Uvarint(packet)
+		if n <= 0 || uint64(len(packet)-n) < size {
+			return 0, nil, ErrRoomClusterInvalidPacket
+		}
+		fields = append(fields, string(packet[n:n+int(size)]))
+		packet = packet[n+int(size):]
+	}
+	return kind, fields, nil
+}
@@ -0,0 +1,54 @@
+package space
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRoomClusterPacket(t *testing.T) {
+	var cases = []struct {
+		kind   byte
+		fields []string
+	}{
+		{kind: roomClusterPacketJoin, fields: []string{"game-1#a.1", "conn", "127.0.0.1", "payload"}},
+		{kind: roomClusterPacketIn, fields: []string{"conn", string(make([]byte, 300))}},
+		{kind: roomClusterPacketOut, fields: []string{"conn", ""}},
+		{kind: roomClusterPacketLeave, fields: []string{"conn"}},
+		{kind: roomClusterPacketDetach},
+	}
+	for _, c := range cases {
+		packet := marshalRoomClusterPacket(c.kind, c.fields...)
+		if !IsRoomClusterPacket(packet) {
+			t.Fatalf("expected kind %d to be a room cluster packet", c.kind)
+		}
+		kind, fields, err := unmarshalRoomClusterPacket(packet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if kind != c.kind || len(fields) != len(c.fields) {
+			t.Fatalf("expected kind %d with %d fields, got kind %d with %d fields", c.kind, len(c.fields), kind, len(fields))
+		}
+		for i, field := range fields {
+			if field != c.fields[i] {
+				t.Fatalf("expected field %d to be %q, got %q", i, c.fields[i], field)
+			}
+		}
+	}
+}
+
+func TestRoomClusterPacket_Invalid(t *testing.T) {
+	var packet = marshalRoomClusterPacket(roomClusterPacketIn, "conn", "hello")
+	var cases = map[string][]byte{
+		"empty":           nil,
+		"foreign":         []byte("hello"),
+		"magic only":      roomClusterMagic,
+		"truncated field": packet[:len(packet)-1],
+		"truncated size":  append(append([]byte(nil), roomClusterMagic...), roomClusterPacketIn, 0x80),
+		"oversize field":  append(append([]byte(nil), roomClusterMagic...), roomClusterPacketIn, 0xff, 0xff, 0xff, 0xff, 0x0f, 'a'),
+	}
+	for name, packet := range cases {
+		if _, _, err := unmarshalRoomClusterPacket(packet); !errors.Is(err, ErrRoomClusterInvalidPacket) {
+			t.Fatalf("%s: expected %v, got %v", name, ErrRoomClusterInvalidPacket, err)
+		}
+	}
+}
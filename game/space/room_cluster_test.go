@@ -0,0 +1,161 @@
+package space_test
+
+import (
+	"context"
+	"github.com/kercylan98/minotaur/game/space"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/cluster"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryCrossNetwork 内存中的跨服网络，推送的消息将直接交由目标服务器处理
+type memoryCrossNetwork struct {
+	handles map[string]func(serverId string, packet []byte)
+	rw      sync.RWMutex
+}
+
+type memoryCross struct {
+	network *memoryCrossNetwork
+	id      string
+}
+
+func (slf *memoryCross) Init(srv *server.Server, packetHandle func(serverId string, packet []byte)) error {
+	slf.id = srv.GetID()
+	slf.network.rw.Lock()
+	slf.network.handles[slf.id] = packetHandle
+	slf.network.rw.Unlock()
+	return nil
+}
+
+func (slf *memoryCross) PushMessage(serverId string, packet []byte) error {
+	slf.network.rw.RLock()
+	handle := slf.network.handles[serverId]
+	slf.network.rw.RUnlock()
+	handle(slf.id, packet)
+	return nil
+}
+
+func (slf *memoryCross) Release() {}
+
+// staticRegistry 固定实例的注册中心
+type staticRegistry []cluster.Instance
+
+func (slf staticRegistry) Register(ctx context.Context, instance cluster.Instance) error { return nil }
+
+func (slf staticRegistry) Deregister(ctx context.Context, id string) error { return nil }
+
+func (slf staticRegistry) Instances(ctx context.Context) ([]cluster.Instance, error) {
+	return slf, nil
+}
+
+func (slf staticRegistry) Watch(ctx context.Context, handler func(instances []cluster.Instance)) error {
+	handler(slf)
+	return nil
+}
+
+func TestRoomCluster_Join(t *testing.T) {
+	network := &memoryCrossNetwork{handles: map[string]func(serverId string, packet []byte){}}
+	cls := cluster.NewCluster(staticRegistry{{ID: "game-1"}, {ID: "game-2"}})
+	defer cls.Close()
+	if err := cls.Watch(); err != nil {
+		t.Fatal(err)
+	}
+
+	var servers []*server.Server
+	var ready sync.WaitGroup
+	for _, id := range []string{"game-1", "game-2"} {
+		srv := server.New(server.NetworkNone, server.WithCross("room", id, &memoryCross{network: network}))
+		ready.Add(1)
+		srv.RegStartFinishEvent(func(srv *server.Server) {
+			ready.Done()
+		})
+		servers = append(servers, srv)
+	}
+	host, guest := servers[0], servers[1]
+
+	// 房间所在的服务器将远程客户端的数据包原样返回
+	manager := newTestRoomManager()
+	var roomId string
+	var left = make(chan string, 1)
+	manager.RegRoomRemoveEntityEvent(func(controller *testRoomController, entity *testEntity) {
+		left <- entity.GetId()
+	})
+	hostCluster := space.NewRoomCluster(host, "room", cls, manager, func(controller *testRoomController, conn *server.Conn, payload []byte) error {
+		conn.SetData("entity", string(payload))
+		return controller.AddEntity(&testEntity{id: string(payload)})
+	})
+	host.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		conn.Write(append([]byte("echo:"), packet...))
+	})
+	host.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
+		if id, ok := conn.GetData("entity").(string); ok {
+			manager.GetRoom(roomId).RemoveEntity(id)
+		}
+	})
+	guestCluster := space.NewRoomCluster(guest, "room", cls, newTestRoomManager(), nil)
+
+	for _, srv := range servers {
+		go func(srv *server.Server) {
+			_ = srv.RunNone()
+		}(srv)
+	}
+	ready.Wait()
+	defer func() {
+		for _, srv := range servers {
+			srv.Shutdown()
+		}
+	}()
+
+	roomId = hostCluster.NewRoomID()
+	manager.AssumeControl(&testRoom{id: roomId})
+	if serverId, err := guestCluster.Resolve(roomId); err != nil || serverId != "game-1" {
+		t.Fatalf("expected game-1, got %s %v", serverId, err)
+	}
+
+	var received = make(chan string, 1)
+	client := server.NewGatewayConn(guest, "127.0.0.1", func(packet []byte) {
+		received <- string(packet)
+	})
+	guest.OnConnectionOpenedEvent(client)
+	for deadline := time.Now().Add(time.Second); guest.GetOnline(client.GetID()) == nil; {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the client to be online")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 加入远程房间后客户端的数据包将被转发至房间所在的服务器，响应将被转发回客户端
+	if err := guestCluster.Join(client, roomId, []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	if serverId, exist := guestCluster.GetRemoteServerID(client); !exist || serverId != "game-1" {
+		t.Fatalf("expected the client to be routed to game-1, got %s %v", serverId, exist)
+	}
+	if !manager.GetRoom(roomId).HasEntity("a") {
+		t.Fatal("expected the remote client to join the room")
+	}
+	guest.PushPacketMessage(client, 0, []byte("hello"))
+	select {
+	case packet := <-received:
+		if packet != "echo:hello" {
+			t.Fatalf("expected echo:hello, got %s", packet)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the packet to be forwarded")
+	}
+
+	// 离开后房间所在服务器的虚拟连接将被关闭
+	if !guestCluster.Leave(client) || guestCluster.Leave(client) {
+		t.Fatal("expected the client to leave the remote room exactly once")
+	}
+	select {
+	case id := <-left:
+		if id != "a" {
+			t.Fatalf("expected a to leave the room, got %s", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the remote client to leave the room")
+	}
+}
@@ -1,18 +1,31 @@
 package space
 
 import (
+	"github.com/kercylan98/minotaur/game/chat"
 	"github.com/kercylan98/minotaur/utils/generic"
 	"github.com/kercylan98/minotaur/utils/hash"
 	"github.com/kercylan98/minotaur/utils/slice"
 	"sync"
+	"time"
 )
 
 func newRoomController[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]](manager *RoomManager[EntityID, RoomID, Entity, Room], room Room, options *RoomControllerOptions) *RoomController[EntityID, RoomID, Entity, Room] {
 	controller := &RoomController[EntityID, RoomID, Entity, Room]{
-		manager:  manager,
-		options:  options,
-		entities: make(map[EntityID]Entity),
-		room:     room,
+		manager:    manager,
+		index:      manager.index.Add(1),
+		options:    options,
+		entities:   make(map[EntityID]Entity),
+		spectators: make(map[EntityID]Entity),
+		bans:       make(map[EntityID]time.Time),
+		state:      RoomStateWaiting,
+		ready:      make(map[EntityID]struct{}),
+		attrs:      hash.Copy(options.attrs),
+		room:       room,
+	}
+	if options.seed != nil {
+		controller.rand = newRoomRand(*options.seed, 0)
+	} else {
+		controller.rand = newRoomRand(defaultRoomSeed(), 0)
 	}
 
 	manager.roomsRWMutex.Lock()
@@ -25,13 +38,30 @@ func newRoomController[EntityID comparable, RoomID comparable, Entity generic.Id
 // RoomController 对房间进行操作的控制器，由 RoomManager 接管后返回
 type RoomController[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]] struct {
 	manager         *RoomManager[EntityID, RoomID, Entity, Room]
+	index           uint64 // 房间被接管的顺序
 	options         *RoomControllerOptions
 	room            Room
 	entities        map[EntityID]Entity
+	spectators      map[EntityID]Entity    // 观战者，不占用房间容量且不参与实体相关的操作
+	waitlist        []Entity               // 房间已满时等待加入房间的实体
+	bans            map[EntityID]time.Time // 被禁止加入房间的实体及解除禁止的时间，零值表示永久禁止
 	entitiesRWMutex sync.RWMutex
 
 	vacancy []int       // 空缺的座位
 	seat    []*EntityID // 座位上的玩家
+
+	state        RoomState             // 房间当前的状态
+	ready        map[EntityID]struct{} // 已准备的实体
+	stateRWMutex sync.RWMutex
+
+	attrs        map[string]any // 房间属性
+	attrsRWMutex sync.RWMutex
+
+	chat *chat.Chat[EntityID] // 房间聊天
+
+	code string // 房间加入码，由 RoomManager.invitesRWMutex 保护
+
+	rand *RoomRand // 房间的确定性随机数生成器
 }
 
 // JoinSeat 设置特定对象加入座位，当具体的座位不存在的时候，将会自动分配座位
@@ -250,12 +280,13 @@ func (slf *RoomController[EntityID, RoomID, Entity, Room]) AddEntity(entity Enti
 	slf.entitiesRWMutex.Lock()
 	defer slf.entitiesRWMutex.Unlock()
 
-	if slf.options.maxEntityCount != nil && len(slf.entities) > *slf.options.maxEntityCount {
+	if slf.isBanned(entity.GetId()) {
+		return ErrBanned
+	}
+	if slf.isFull() {
 		return ErrRoomFull
 	}
-	slf.entities[entity.GetId()] = entity
-
-	slf.manager.OnRoomAddEntityEvent(slf, entity)
+	slf.addEntity(entity)
 	return nil
 }
 
@@ -267,31 +298,56 @@ func (slf *RoomController[EntityID, RoomID, Entity, Room]) AddEntityByPassword(e
 	slf.entitiesRWMutex.Lock()
 	defer slf.entitiesRWMutex.Unlock()
 
-	if slf.options.maxEntityCount != nil && len(slf.entities) > *slf.options.maxEntityCount {
+	if slf.isBanned(entity.GetId()) {
+		return ErrBanned
+	}
+	if slf.isFull() {
 		return ErrRoomFull
 	}
+	slf.addEntity(entity)
+	return nil
+}
+
+// addEntity 添加实体（无锁）
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) addEntity(entity Entity) {
+	slf.removeSpectator(entity.GetId())
+	slf.leaveWaitlist(entity.GetId())
 	slf.entities[entity.GetId()] = entity
+	slf.bindAffinity(entity.GetId())
+	slf.joinChat(entity.GetId())
+	slf.armEmptyDestroy()
 
 	slf.manager.OnRoomAddEntityEvent(slf, entity)
-	return nil
+}
+
+// isFull 检查房间是否已满（无锁）
+//   - 当实体数量达到最大实体数量时即视为已满
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) isFull() bool {
+	return slf.options.maxEntityCount != nil && len(slf.entities) >= *slf.options.maxEntityCount
 }
 
 // RemoveEntity 移除实体
 //   - 当实体被移除时如果实体在座位上，将会自动离开座位
+//   - 当房间存在等待队列时，等待队列中的实体将按照顺序加入房间
 func (slf *RoomController[EntityID, RoomID, Entity, Room]) RemoveEntity(id EntityID) {
-	slf.entitiesRWMutex.RLock()
-	defer slf.entitiesRWMutex.RUnlock()
+	slf.entitiesRWMutex.Lock()
+	defer slf.entitiesRWMutex.Unlock()
 	slf.removeEntity(id)
+	slf.admitWaitlist()
+	slf.armEmptyDestroy()
 }
 
 // removeEntity 移除实体（无锁）
 func (slf *RoomController[EntityID, RoomID, Entity, Room]) removeEntity(id EntityID) {
 	slf.leaveSeat(id)
+	slf.clearReady(id)
 	entity, exist := slf.entities[id]
 	delete(slf.entities, id)
 	if !exist {
 		return
 	}
+	slf.unbindAffinity(id)
+	slf.leaveChat(id)
 	slf.manager.OnRoomRemoveEntityEvent(slf, entity)
 }
 
@@ -303,10 +359,14 @@ func (slf *RoomController[EntityID, RoomID, Entity, Room]) RemoveAllEntities() {
 		slf.removeEntity(id)
 		delete(slf.entities, id)
 	}
+	slf.admitWaitlist()
+	slf.armEmptyDestroy()
 }
 
 // Destroy 销毁房间
 func (slf *RoomController[EntityID, RoomID, Entity, Room]) Destroy() {
+	slf.disarmAutoDestroy()
+	slf.RevokeJoinCode()
 	slf.manager.roomsRWMutex.Lock()
 	defer slf.manager.roomsRWMutex.Unlock()
 
@@ -321,7 +381,12 @@ func (slf *RoomController[EntityID, RoomID, Entity, Room]) Destroy() {
 		delete(slf.entities, eid)
 	}
 
+	for sid := range slf.spectators {
+		slf.removeSpectator(sid)
+	}
+
 	slf.entities = make(map[EntityID]Entity)
+	slf.waitlist = nil
 	slf.seat = slf.seat[:]
 	slf.vacancy = slf.vacancy[:]
 }
@@ -350,3 +415,178 @@ func (slf *RoomController[EntityID, RoomID, Entity, Room]) Broadcast(handler fun
 		handler(entity)
 	}
 }
+
+// BroadcastPacket 向房间内所有绑定了连接的实体及观战者发送数据包
+//   - 未绑定连接或连接已关闭的实体将被忽略，实体与连接的绑定可通过 RoomManager.BindConn 进行
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) BroadcastPacket(packet []byte) {
+	slf.BroadcastPacketExcept(packet)
+}
+
+// BroadcastPacketExcept 向房间内除特定实体外所有绑定了连接的实体及观战者发送数据包
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) BroadcastPacketExcept(packet []byte, entityIds ...EntityID) {
+	slf.entitiesRWMutex.RLock()
+	ids := append(hash.KeyToSlice(slf.entities), hash.KeyToSlice(slf.spectators)...)
+	slf.entitiesRWMutex.RUnlock()
+	except := hash.ToIterator(entityIds)
+	slf.manager.connsRWMutex.RLock()
+	defer slf.manager.connsRWMutex.RUnlock()
+	for _, id := range ids {
+		if _, skip := except[id]; skip {
+			continue
+		}
+		if conn := slf.manager.conns[id]; conn != nil && !conn.IsClosed() {
+			conn.Write(packet)
+		}
+	}
+}
+
+// AddSpectator 添加观战者，观战者不占用房间容量，能够收到 BroadcastPacket 等广播的数据包，但不会出现在 GetEntities 等实体相关的操作中
+//   - 当对象已经作为实体存在于房间中时将返回 ErrAlreadyInRoom
+//   - 观战者作为实体加入房间时将自动移除观战者身份
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) AddSpectator(spectator Entity) error {
+	if slf.options.password != nil {
+		return ErrRoomPasswordNotMatch
+	}
+	return slf.addSpectator(spectator)
+}
+
+// AddSpectatorByPassword 通过房间密码添加观战者
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) AddSpectatorByPassword(spectator Entity, password string) error {
+	if slf.options.password == nil || *slf.options.password != password {
+		return ErrRoomPasswordNotMatch
+	}
+	return slf.addSpectator(spectator)
+}
+
+// addSpectator 添加观战者
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) addSpectator(spectator Entity) error {
+	slf.entitiesRWMutex.Lock()
+	defer slf.entitiesRWMutex.Unlock()
+	if _, exist := slf.entities[spectator.GetId()]; exist {
+		return ErrAlreadyInRoom
+	}
+	if slf.isBanned(spectator.GetId()) {
+		return ErrBanned
+	}
+	slf.spectators[spectator.GetId()] = spectator
+	slf.joinChat(spectator.GetId())
+
+	slf.manager.OnRoomAddSpectatorEvent(slf, spectator)
+	return nil
+}
+
+// RemoveSpectator 移除观战者
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) RemoveSpectator(id EntityID) {
+	slf.entitiesRWMutex.Lock()
+	defer slf.entitiesRWMutex.Unlock()
+	slf.removeSpectator(id)
+}
+
+// removeSpectator 移除观战者（无锁）
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) removeSpectator(id EntityID) {
+	spectator, exist := slf.spectators[id]
+	if !exist {
+		return
+	}
+	delete(slf.spectators, id)
+	if _, entity := slf.entities[id]; !entity {
+		slf.leaveChat(id)
+	}
+	slf.manager.OnRoomRemoveSpectatorEvent(slf, spectator)
+}
+
+// HasSpectator 判断特定对象是否是房间的观战者
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) HasSpectator(id EntityID) bool {
+	slf.entitiesRWMutex.RLock()
+	defer slf.entitiesRWMutex.RUnlock()
+	_, exist := slf.spectators[id]
+	return exist
+}
+
+// GetSpectator 获取观战者
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) GetSpectator(id EntityID) Entity {
+	slf.entitiesRWMutex.RLock()
+	defer slf.entitiesRWMutex.RUnlock()
+	return slf.spectators[id]
+}
+
+// GetSpectators 获取所有观战者
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) GetSpectators() map[EntityID]Entity {
+	slf.entitiesRWMutex.RLock()
+	defer slf.entitiesRWMutex.RUnlock()
+	return hash.Copy(slf.spectators)
+}
+
+// GetSpectatorCount 获取观战者数量
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) GetSpectatorCount() int {
+	slf.entitiesRWMutex.RLock()
+	defer slf.entitiesRWMutex.RUnlock()
+	return len(slf.spectators)
+}
+
+// Kick 将实体或观战者踢出房间，踢出后将触发移除事件及踢出事件
+//   - 当通过 RoomControllerOptions.WithKickBanDuration 设置了禁止时长时，被踢出的对象在禁止时长内将无法重新加入房间
+//   - 当对象不在房间中时将返回 ErrNotInRoom
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) Kick(id EntityID, reason string) error {
+	slf.entitiesRWMutex.Lock()
+	defer slf.entitiesRWMutex.Unlock()
+	entity, exist := slf.entities[id]
+	if exist {
+		slf.removeEntity(id)
+	} else if entity, exist = slf.spectators[id]; exist {
+		slf.removeSpectator(id)
+	} else {
+		return ErrNotInRoom
+	}
+	if slf.options.kickBanDuration != nil {
+		slf.ban(id, *slf.options.kickBanDuration)
+	}
+	slf.manager.OnRoomEntityKickedEvent(slf, entity, reason)
+	slf.admitWaitlist()
+	slf.armEmptyDestroy()
+	return nil
+}
+
+// Ban 禁止特定对象在 duration 时长内加入房间，当 duration 小于等于 0 时将永久禁止
+//   - 该函数不会将已在房间中的对象移除，如需移除请使用 Kick
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) Ban(id EntityID, duration time.Duration) {
+	slf.entitiesRWMutex.Lock()
+	defer slf.entitiesRWMutex.Unlock()
+	slf.ban(id, duration)
+}
+
+// ban 禁止特定对象加入房间（无锁）
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) ban(id EntityID, duration time.Duration) {
+	var expire time.Time
+	if duration > 0 {
+		expire = time.Now().Add(duration)
+	}
+	slf.bans[id] = expire
+}
+
+// Unban 解除对特定对象的禁止
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) Unban(id EntityID) {
+	slf.entitiesRWMutex.Lock()
+	defer slf.entitiesRWMutex.Unlock()
+	delete(slf.bans, id)
+}
+
+// IsBanned 判断特定对象是否被禁止加入房间
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) IsBanned(id EntityID) bool {
+	slf.entitiesRWMutex.Lock()
+	defer slf.entitiesRWMutex.Unlock()
+	return slf.isBanned(id)
+}
+
+// isBanned 判断特定对象是否被禁止加入房间（无锁），已过期的禁止将被清除
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) isBanned(id EntityID) bool {
+	expire, exist := slf.bans[id]
+	if !exist {
+		return false
+	}
+	if !expire.IsZero() && !time.Now().Before(expire) {
+		delete(slf.bans, id)
+		return false
+	}
+	return true
+}
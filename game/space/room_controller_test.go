@@ -0,0 +1,500 @@
+package space_test
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/game/space"
+	"github.com/kercylan98/minotaur/server"
+	"sort"
+	"testing"
+	"time"
+)
+
+type testRoomController = space.RoomController[string, string, *testEntity, *testRoom]
+
+func TestRoomController_Kick(t *testing.T) {
+	manager := newTestRoomManager()
+	var events []string
+	manager.RegRoomRemoveEntityEvent(func(controller *testRoomController, entity *testEntity) {
+		events = append(events, "remove:"+entity.GetId())
+	})
+	manager.RegRoomRemoveSpectatorEvent(func(controller *testRoomController, spectator *testEntity) {
+		events = append(events, "removeSpectator:"+spectator.GetId())
+	})
+	manager.RegRoomEntityKickedEvent(func(controller *testRoomController, entity *testEntity, reason string) {
+		events = append(events, "kicked:"+entity.GetId()+":"+reason)
+	})
+	room := manager.AssumeControl(&testRoom{id: "room"}, space.NewRoomControllerOptions().WithKickBanDuration(50*time.Millisecond))
+	_ = room.AddEntity(&testEntity{id: "a"})
+	_ = room.AddSpectator(&testEntity{id: "s"})
+
+	// 踢出实体将依次且仅触发一次移除事件及踢出事件
+	if err := room.Kick("a", "afk"); err != nil {
+		t.Fatal(err)
+	}
+	if err := room.Kick("s", "spam"); err != nil {
+		t.Fatal(err)
+	}
+	if err := room.Kick("a", "afk"); !errors.Is(err, space.ErrNotInRoom) {
+		t.Fatalf("expected %v, got %v", space.ErrNotInRoom, err)
+	}
+	var expected = []string{"remove:a", "kicked:a:afk", "removeSpectator:s", "kicked:s:spam"}
+	if len(events) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, events)
+	}
+	for i, event := range expected {
+		if events[i] != event {
+			t.Fatalf("expected events %v, got %v", expected, events)
+		}
+	}
+
+	// 被踢出的对象在禁止时长内无法重新加入房间
+	if err := room.AddEntity(&testEntity{id: "a"}); !errors.Is(err, space.ErrBanned) {
+		t.Fatalf("expected %v, got %v", space.ErrBanned, err)
+	}
+	if err := room.AddSpectator(&testEntity{id: "s"}); !errors.Is(err, space.ErrBanned) {
+		t.Fatalf("expected %v, got %v", space.ErrBanned, err)
+	}
+	time.Sleep(60 * time.Millisecond)
+	if room.IsBanned("a") {
+		t.Fatal("expected the ban to expire")
+	}
+	if err := room.AddEntity(&testEntity{id: "a"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRoomController_Ban(t *testing.T) {
+	manager := newTestRoomManager()
+	room := manager.AssumeControl(&testRoom{id: "room"})
+	_ = room.AddEntity(&testEntity{id: "a"})
+
+	// 禁止不会移除已在房间中的实体，小于等于 0 的时长表示永久禁止
+	room.Ban("a", 0)
+	room.Ban("b", 0)
+	if !room.HasEntity("a") {
+		t.Fatal("expected Ban not to remove the entity")
+	}
+	if err := room.AddEntity(&testEntity{id: "b"}); !errors.Is(err, space.ErrBanned) {
+		t.Fatalf("expected %v, got %v", space.ErrBanned, err)
+	}
+	room.Unban("b")
+	if room.IsBanned("b") {
+		t.Fatal("expected b to be unbanned")
+	}
+	if err := room.AddEntity(&testEntity{id: "b"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRoomController_BroadcastPacket(t *testing.T) {
+	srv := server.New(server.NetworkNone)
+	var ready = make(chan struct{})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	go func() {
+		_ = srv.RunNone()
+	}()
+	<-ready
+	defer srv.Shutdown()
+
+	manager := newTestRoomManager()
+	var received []string
+	var conns = make(map[string]*server.Conn)
+	for _, id := range []string{"a", "b", "c", "s", "x"} {
+		id := id
+		conns[id] = server.NewGatewayConn(srv, "127.0.0.1", func(packet []byte) {
+			received = append(received, id+":"+string(packet))
+		})
+		manager.BindConn(id, conns[id])
+	}
+	room := manager.AssumeControl(&testRoom{id: "room"})
+	for _, id := range []string{"a", "b", "c", "d"} {
+		_ = room.AddEntity(&testEntity{id: id})
+	}
+	_ = room.AddSpectator(&testEntity{id: "s"})
+	if manager.GetConn("a") != conns["a"] || manager.GetConn("d") != nil {
+		t.Fatal("unexpected bound connections")
+	}
+
+	// 仅房间内绑定了连接且连接未关闭的实体及观战者会收到数据包，房间外的实体不会收到数据包
+	manager.UnbindConn("b")
+	conns["c"].Close()
+	var cases = []struct {
+		name     string
+		except   []string
+		expected []string
+	}{
+		{"all", nil, []string{"a:packet", "s:packet"}},
+		{"except", []string{"a"}, []string{"s:packet"}},
+		{"except spectator", []string{"a", "s"}, nil},
+		{"except unknown", []string{"x"}, []string{"a:packet", "s:packet"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			received = nil
+			if c.except == nil {
+				room.BroadcastPacket([]byte("packet"))
+			} else {
+				room.BroadcastPacketExcept([]byte("packet"), c.except...)
+			}
+			sort.Strings(received)
+			if len(received) != len(c.expected) {
+				t.Fatalf("expected %v, got %v", c.expected, received)
+			}
+			for i, packet := range c.expected {
+				if received[i] != packet {
+					t.Fatalf("expected %v, got %v", c.expected, received)
+				}
+			}
+		})
+	}
+
+	// 重复绑定将覆盖之前绑定的连接
+	manager.BindConn("b", conns["x"])
+	received = nil
+	room.BroadcastPacketExcept([]byte("packet"), "a", "s")
+	if len(received) != 1 || received[0] != "x:packet" {
+		t.Fatalf("expected [x:packet], got %v", received)
+	}
+}
+
+func TestRoomController_Spectator(t *testing.T) {
+	manager := newTestRoomManager()
+	var events []string
+	manager.RegRoomAddSpectatorEvent(func(controller *testRoomController, spectator *testEntity) {
+		events = append(events, "addSpectator:"+spectator.GetId())
+	})
+	manager.RegRoomRemoveSpectatorEvent(func(controller *testRoomController, spectator *testEntity) {
+		events = append(events, "removeSpectator:"+spectator.GetId())
+	})
+	room := manager.AssumeControl(&testRoom{id: "room"}, space.NewRoomControllerOptions().WithMaxEntityCount(1))
+	for _, id := range []string{"s1", "s2", "s3"} {
+		if err := room.AddSpectator(&testEntity{id: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 观战者不占用房间容量，也不会出现在实体相关的操作中
+	if err := room.AddEntity(&testEntity{id: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := room.AddEntity(&testEntity{id: "b"}); !errors.Is(err, space.ErrRoomFull) {
+		t.Fatalf("expected %v, got %v", space.ErrRoomFull, err)
+	}
+	if count := room.GetEntityCount(); count != 1 || len(room.GetEntities()) != 1 || room.HasEntity("s1") {
+		t.Fatalf("expected spectators to be excluded from entities, got %v", room.GetEntities())
+	}
+	if count := room.GetSpectatorCount(); count != 3 || len(room.GetSpectators()) != 3 {
+		t.Fatalf("expected 3 spectators, got %d", count)
+	}
+	if !room.HasSpectator("s1") || room.GetSpectator("s1").GetId() != "s1" || room.HasSpectator("a") {
+		t.Fatal("unexpected spectators")
+	}
+	if err := room.AddSpectator(&testEntity{id: "a"}); !errors.Is(err, space.ErrAlreadyInRoom) {
+		t.Fatalf("expected %v, got %v", space.ErrAlreadyInRoom, err)
+	}
+
+	// 观战者不参与准备状态的检查
+	if err := room.SetReady("a", true); err != nil {
+		t.Fatal(err)
+	}
+	if !room.IsAllReady() || room.GetState() != space.RoomStateReady {
+		t.Fatalf("expected all entities to be ready, got state %v", room.GetState())
+	}
+
+	// 观战者作为实体加入房间时将自动移除观战者身份
+	room.RemoveEntity("a")
+	if err := room.AddEntity(&testEntity{id: "s1"}); err != nil {
+		t.Fatal(err)
+	}
+	if room.HasSpectator("s1") || !room.HasEntity("s1") {
+		t.Fatal("expected s1 to become an entity")
+	}
+	room.RemoveSpectator("s2")
+	room.RemoveSpectator("s2")
+	room.Destroy()
+	if count := room.GetSpectatorCount(); count != 0 {
+		t.Fatalf("expected no spectators after destroy, got %d", count)
+	}
+
+	var expected = []string{
+		"addSpectator:s1", "addSpectator:s2", "addSpectator:s3",
+		"removeSpectator:s1", "removeSpectator:s2", "removeSpectator:s3",
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, events)
+	}
+	for i, event := range expected {
+		if events[i] != event {
+			t.Fatalf("expected events %v, got %v", expected, events)
+		}
+	}
+}
+
+func TestRoomController_SpectatorPassword(t *testing.T) {
+	manager := newTestRoomManager()
+	room := manager.AssumeControl(&testRoom{id: "room"}, space.NewRoomControllerOptions().WithPassword("secret"))
+	if err := room.AddSpectator(&testEntity{id: "s"}); !errors.Is(err, space.ErrRoomPasswordNotMatch) {
+		t.Fatalf("expected %v, got %v", space.ErrRoomPasswordNotMatch, err)
+	}
+	if err := room.AddSpectatorByPassword(&testEntity{id: "s"}, "wrong"); !errors.Is(err, space.ErrRoomPasswordNotMatch) {
+		t.Fatalf("expected %v, got %v", space.ErrRoomPasswordNotMatch, err)
+	}
+	if err := room.AddSpectatorByPassword(&testEntity{id: "s"}, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if !room.HasSpectator("s") {
+		t.Fatal("expected s to be a spectator")
+	}
+}
+
+func TestRoomController_AddEntityFull(t *testing.T) {
+	manager := newTestRoomManager()
+	room := manager.AssumeControl(&testRoom{id: "room"}, space.NewRoomControllerOptions().WithMaxEntityCount(2))
+	if err := room.AddEntity(&testEntity{id: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := room.AddEntity(&testEntity{id: "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 房间实体数量恰好达到最大实体数量时不允许再加入实体
+	if err := room.AddEntity(&testEntity{id: "c"}); !errors.Is(err, space.ErrRoomFull) {
+		t.Fatalf("expected %v, got %v", space.ErrRoomFull, err)
+	}
+	if count := room.GetEntityCount(); count != 2 {
+		t.Fatalf("expected 2 entities, got %d", count)
+	}
+}
+
+func TestRoomController_ChangeState(t *testing.T) {
+	// 通过默认的状态转换到达各个状态的路径
+	var paths = map[space.RoomState][]space.RoomState{
+		space.RoomStateWaiting:  nil,
+		space.RoomStateReady:    {space.RoomStateReady},
+		space.RoomStatePlaying:  {space.RoomStateReady, space.RoomStatePlaying},
+		space.RoomStateSettling: {space.RoomStateReady, space.RoomStatePlaying, space.RoomStateSettling},
+	}
+	var cases = []struct {
+		from, to space.RoomState
+		allow    bool
+	}{
+		{space.RoomStateWaiting, space.RoomStateWaiting, false},
+		{space.RoomStateWaiting, space.RoomStateReady, true},
+		{space.RoomStateWaiting, space.RoomStatePlaying, false},
+		{space.RoomStateWaiting, space.RoomStateSettling, false},
+		{space.RoomStateReady, space.RoomStateWaiting, true},
+		{space.RoomStateReady, space.RoomStateReady, false},
+		{space.RoomStateReady, space.RoomStatePlaying, true},
+		{space.RoomStateReady, space.RoomStateSettling, false},
+		{space.RoomStatePlaying, space.RoomStateWaiting, false},
+		{space.RoomStatePlaying, space.RoomStateReady, false},
+		{space.RoomStatePlaying, space.RoomStatePlaying, false},
+		{space.RoomStatePlaying, space.RoomStateSettling, true},
+		{space.RoomStateSettling, space.RoomStateWaiting, true},
+		{space.RoomStateSettling, space.RoomStateReady, false},
+		{space.RoomStateSettling, space.RoomStatePlaying, false},
+		{space.RoomStateSettling, space.RoomStateSettling, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.from.String()+"->"+c.to.String(), func(t *testing.T) {
+			manager := newTestRoomManager()
+			var changes int
+			manager.RegRoomStateChangeEvent(func(controller *testRoomController, from, to space.RoomState) {
+				changes++
+			})
+			room := manager.AssumeControl(&testRoom{id: "room"})
+			if state := room.GetState(); state != space.RoomStateWaiting {
+				t.Fatalf("expected initial state %v, got %v", space.RoomStateWaiting, state)
+			}
+			for _, state := range paths[c.from] {
+				if err := room.ChangeState(state); err != nil {
+					t.Fatal(err)
+				}
+			}
+			changes = 0
+
+			err := room.ChangeState(c.to)
+			switch {
+			case c.allow && err != nil:
+				t.Fatalf("expected %v -> %v to be allowed, got %v", c.from, c.to, err)
+			case !c.allow && !errors.Is(err, space.ErrIllegalRoomStateTransition):
+				t.Fatalf("expected %v, got %v", space.ErrIllegalRoomStateTransition, err)
+			}
+			var expected, expectedChanges = c.from, 0
+			if c.allow {
+				expected, expectedChanges = c.to, 1
+			}
+			if state := room.GetState(); state != expected {
+				t.Fatalf("expected state %v, got %v", expected, state)
+			}
+			if changes != expectedChanges {
+				t.Fatalf("expected %d state change events, got %d", expectedChanges, changes)
+			}
+		})
+	}
+}
+
+func TestRoomController_ChangeStateCustomTransitions(t *testing.T) {
+	const custom space.RoomState = 100
+	manager := newTestRoomManager()
+	room := manager.AssumeControl(&testRoom{id: "room"}, space.NewRoomControllerOptions().WithStateTransitions(map[space.RoomState][]space.RoomState{
+		space.RoomStateWaiting: {custom},
+		custom:                 {space.RoomStateWaiting},
+	}))
+
+	// 自定义状态转换将完全取代默认的状态转换
+	if err := room.ChangeState(space.RoomStateReady); !errors.Is(err, space.ErrIllegalRoomStateTransition) {
+		t.Fatalf("expected %v, got %v", space.ErrIllegalRoomStateTransition, err)
+	}
+	if err := room.ChangeState(custom); err != nil {
+		t.Fatal(err)
+	}
+	if err := room.ChangeState(space.RoomStateWaiting); err != nil {
+		t.Fatal(err)
+	}
+	if state := room.GetState(); state != space.RoomStateWaiting {
+		t.Fatalf("expected state %v, got %v", space.RoomStateWaiting, state)
+	}
+}
+
+func TestRoomController_ChangeStateGuard(t *testing.T) {
+	var errGuard = errors.New("not enough entities")
+	manager := newTestRoomManager()
+	var changes []string
+	manager.RegRoomStateGuard(func(controller *testRoomController, from, to space.RoomState) error {
+		if to == space.RoomStatePlaying && controller.GetEntityCount() < 2 {
+			return errGuard
+		}
+		return nil
+	})
+	manager.RegRoomStateChangeEvent(func(controller *testRoomController, from, to space.RoomState) {
+		changes = append(changes, from.String()+"->"+to.String())
+	})
+	room := manager.AssumeControl(&testRoom{id: "room"})
+	_ = room.AddEntity(&testEntity{id: "a"})
+	if err := room.ChangeState(space.RoomStateReady); err != nil {
+		t.Fatal(err)
+	}
+
+	// 守卫返回错误时不会切换状态，也不会触发状态改变事件
+	if err := room.ChangeState(space.RoomStatePlaying); !errors.Is(err, errGuard) {
+		t.Fatalf("expected %v, got %v", errGuard, err)
+	}
+	if state := room.GetState(); state != space.RoomStateReady {
+		t.Fatalf("expected state %v, got %v", space.RoomStateReady, state)
+	}
+
+	// 非法的状态转换将在调用守卫前被拒绝
+	if err := room.ChangeState(space.RoomStateSettling); !errors.Is(err, space.ErrIllegalRoomStateTransition) {
+		t.Fatalf("expected %v, got %v", space.ErrIllegalRoomStateTransition, err)
+	}
+
+	_ = room.AddEntity(&testEntity{id: "b"})
+	if err := room.ChangeState(space.RoomStatePlaying); err != nil {
+		t.Fatal(err)
+	}
+	var expected = []string{"Waiting->Ready", "Ready->Playing"}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, changes)
+	}
+	for i, change := range expected {
+		if changes[i] != change {
+			t.Fatalf("expected events %v, got %v", expected, changes)
+		}
+	}
+}
+
+func TestRoomController_SetReady(t *testing.T) {
+	manager := newTestRoomManager()
+	var events []string
+	manager.RegRoomEntityReadyChangeEvent(func(controller *testRoomController, entity *testEntity, ready bool) {
+		if ready {
+			events = append(events, "ready:"+entity.GetId())
+		} else {
+			events = append(events, "unready:"+entity.GetId())
+		}
+	})
+	manager.RegRoomStateChangeEvent(func(controller *testRoomController, from, to space.RoomState) {
+		events = append(events, from.String()+"->"+to.String())
+	})
+	room := manager.AssumeControl(&testRoom{id: "room"})
+	_ = room.AddEntity(&testEntity{id: "a"})
+	_ = room.AddEntity(&testEntity{id: "b"})
+	_ = room.AddSpectator(&testEntity{id: "s"})
+
+	if err := room.SetReady("c", true); !errors.Is(err, space.ErrNotInRoom) {
+		t.Fatalf("expected %v, got %v", space.ErrNotInRoom, err)
+	}
+	if err := room.SetReady("s", true); !errors.Is(err, space.ErrNotInRoom) {
+		t.Fatalf("expected %v, got %v", space.ErrNotInRoom, err)
+	}
+
+	var steps = []struct {
+		id         string
+		ready      bool
+		state      space.RoomState
+		readyCount int
+	}{
+		{"a", true, space.RoomStateWaiting, 1},
+		{"a", true, space.RoomStateWaiting, 1},  // 重复设置不会触发事件
+		{"b", true, space.RoomStateReady, 2},    // 所有实体均已准备时自动切换到 Ready
+		{"b", false, space.RoomStateWaiting, 1}, // 有实体取消准备时自动切换回 Waiting
+		{"a", false, space.RoomStateWaiting, 0},
+		{"a", true, space.RoomStateWaiting, 1},
+		{"b", true, space.RoomStateReady, 2},
+	}
+	for i, step := range steps {
+		if err := room.SetReady(step.id, step.ready); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if ready := room.IsReady(step.id); ready != step.ready {
+			t.Fatalf("step %d: expected IsReady(%s) %v, got %v", i, step.id, step.ready, ready)
+		}
+		if state := room.GetState(); state != step.state {
+			t.Fatalf("step %d: expected state %v, got %v", i, step.state, state)
+		}
+		if count := room.GetReadyEntityCount(); count != step.readyCount {
+			t.Fatalf("step %d: expected %d ready entities, got %d", i, step.readyCount, count)
+		}
+		if all := room.IsAllReady(); all != (step.readyCount == 2) {
+			t.Fatalf("step %d: expected IsAllReady %v, got %v", i, step.readyCount == 2, all)
+		}
+	}
+	var expected = []string{
+		"ready:a",
+		"ready:b", "Waiting->Ready",
+		"unready:b", "Ready->Waiting",
+		"unready:a",
+		"ready:a",
+		"ready:b", "Waiting->Ready",
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expected events %v, got %v", expected, events)
+	}
+	for i, event := range expected {
+		if events[i] != event {
+			t.Fatalf("expected events %v, got %v", expected, events)
+		}
+	}
+
+	// 进入 Playing 状态时将清除所有实体的准备状态
+	if err := room.ChangeState(space.RoomStatePlaying); err != nil {
+		t.Fatal(err)
+	}
+	if room.IsReady("a") || room.IsReady("b") || room.IsAllReady() {
+		t.Fatal("expected ready flags to be cleared on Playing")
+	}
+	if count := room.GetReadyEntityCount(); count != 0 {
+		t.Fatalf("expected 0 ready entities, got %d", count)
+	}
+
+	// 游戏中准备不会自动切换状态
+	_ = room.SetReady("a", true)
+	_ = room.SetReady("b", true)
+	if state := room.GetState(); state != space.RoomStatePlaying {
+		t.Fatalf("expected state %v, got %v", space.RoomStatePlaying, state)
+	}
+}
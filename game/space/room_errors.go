@@ -13,4 +13,32 @@ var (
 	ErrRoomPasswordNotMatch = errors.New("room password not match")
 	// ErrPermissionDenied 权限不足
 	ErrPermissionDenied = errors.New("permission denied")
+	// ErrAlreadyInRoom 实体已经在房间中
+	ErrAlreadyInRoom = errors.New("already in room")
+	// ErrBanned 实体被禁止加入房间
+	ErrBanned = errors.New("banned from room")
+	// ErrIllegalRoomStateTransition 房间当前状态不允许切换到目标状态
+	ErrIllegalRoomStateTransition = errors.New("illegal room state transition")
+	// ErrAlreadyInWaitlist 实体已经在房间的等待队列中
+	ErrAlreadyInWaitlist = errors.New("already in waitlist")
+	// ErrRoomNotFound 房间不存在
+	ErrRoomNotFound = errors.New("room not found")
+	// ErrRoomIDIllegal 房间 ID 不是由 RoomCluster.NewRoomID 生成的全局房间 ID
+	ErrRoomIDIllegal = errors.New("room id is not a global room id")
+	// ErrRoomHostNotFound 房间所在的服务器不存在于集群中
+	ErrRoomHostNotFound = errors.New("room host not found")
+	// ErrAlreadyInRemoteRoom 连接已经加入了远程房间
+	ErrAlreadyInRemoteRoom = errors.New("already in remote room")
+	// ErrRemoteRoomJoinRejected 远程房间拒绝加入
+	ErrRemoteRoomJoinRejected = errors.New("remote room join rejected")
+	// ErrRoomClusterInvalidPacket 无效的房间集群数据包
+	ErrRoomClusterInvalidPacket = errors.New("invalid room cluster packet")
+	// ErrAlreadyInMatchQueue 实体已经在匹配队列中
+	ErrAlreadyInMatchQueue = errors.New("already in match queue")
+	// ErrJoinCodeNotFound 房间加入码不存在或已失效
+	ErrJoinCodeNotFound = errors.New("join code not found")
+	// ErrInvitationNotFound 邀请不存在或已失效
+	ErrInvitationNotFound = errors.New("invitation not found")
+	// ErrInvitationExpired 邀请已过期
+	ErrInvitationExpired = errors.New("invitation expired")
 )
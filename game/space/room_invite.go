@@ -0,0 +1,239 @@
+package space
+
+import (
+	"github.com/kercylan98/minotaur/utils/random"
+	"strings"
+	"time"
+)
+
+// roomJoinCodeCharset 房间加入码使用的字符集，排除了 0、O、1、I 等容易混淆的字符
+const roomJoinCodeCharset = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+// RoomInvitation 房间邀请
+type RoomInvitation[EntityID comparable, RoomID comparable] struct {
+	Code     string    // 邀请对应的房间加入码
+	RoomID   RoomID    // 被邀请加入的房间 ID
+	Inviter  EntityID  // 邀请者
+	Invitee  EntityID  // 被邀请者
+	ExpireAt time.Time // 邀请过期的时间，零值表示永不过期
+}
+
+// IsExpired 检查邀请是否已经过期
+func (slf *RoomInvitation[EntityID, RoomID]) IsExpired() bool {
+	return !slf.ExpireAt.IsZero() && !time.Now().Before(slf.ExpireAt)
+}
+
+// GenerateJoinCode 为房间生成新的加入码，加入码长度可通过 RoomManagerOptions.WithJoinCodeLength 进行设置
+//   - 房间原有的加入码将失效，通过原有加入码发出的邀请也将随之失效
+//   - 通过加入码加入房间时无需提供房间密码，适用于在不暴露房间 ID 及密码的情况下分享私人房间
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) GenerateJoinCode() string {
+	manager := slf.manager
+	manager.invitesRWMutex.Lock()
+	defer manager.invitesRWMutex.Unlock()
+	slf.revokeJoinCode()
+	var code string
+	for {
+		var builder strings.Builder
+		for i := 0; i < manager.options.joinCodeLength; i++ {
+			builder.WriteByte(roomJoinCodeCharset[random.IntN(len(roomJoinCodeCharset))])
+		}
+		if code = builder.String(); !manager.hasJoinCode(code) {
+			break
+		}
+	}
+	slf.code = code
+	manager.codes[code] = slf.GetRoomID()
+	return code
+}
+
+// GetJoinCode 获取房间当前的加入码，当房间没有加入码时将返回空字符串
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) GetJoinCode() string {
+	slf.manager.invitesRWMutex.RLock()
+	defer slf.manager.invitesRWMutex.RUnlock()
+	return slf.code
+}
+
+// RevokeJoinCode 使房间当前的加入码及通过该加入码发出的邀请失效
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) RevokeJoinCode() {
+	slf.manager.invitesRWMutex.Lock()
+	defer slf.manager.invitesRWMutex.Unlock()
+	slf.revokeJoinCode()
+}
+
+// revokeJoinCode 使房间当前的加入码及通过该加入码发出的邀请失效（无锁）
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) revokeJoinCode() {
+	if slf.code == "" {
+		return
+	}
+	delete(slf.manager.codes, slf.code)
+	for invitee, invitations := range slf.manager.invitations {
+		for code := range invitations {
+			if code == slf.code {
+				delete(invitations, code)
+			}
+		}
+		if len(invitations) == 0 {
+			delete(slf.manager.invitations, invitee)
+		}
+	}
+	slf.code = ""
+}
+
+// Invite 由房间内的实体邀请其他实体加入房间，并触发房间邀请事件
+//   - 当房间没有加入码时将自动生成加入码
+//   - expire 为邀请的有效时长，小于等于 0 时表示永不过期
+//   - 重复邀请同一实体时将覆盖之前的邀请
+//   - 被邀请者可通过 RoomManager.AcceptInvitation 接受邀请或通过 RoomManager.DeclineInvitation 拒绝邀请
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) Invite(inviter, invitee EntityID, expire time.Duration) (*RoomInvitation[EntityID, RoomID], error) {
+	if !slf.HasEntity(inviter) {
+		return nil, ErrNotInRoom
+	}
+	if slf.HasEntity(invitee) {
+		return nil, ErrAlreadyInRoom
+	}
+	code := slf.GetJoinCode()
+	if code == "" {
+		code = slf.GenerateJoinCode()
+	}
+	invitation := &RoomInvitation[EntityID, RoomID]{
+		Code:    code,
+		RoomID:  slf.GetRoomID(),
+		Inviter: inviter,
+		Invitee: invitee,
+	}
+	if expire > 0 {
+		invitation.ExpireAt = time.Now().Add(expire)
+	}
+
+	manager := slf.manager
+	manager.invitesRWMutex.Lock()
+	if slf.code != code {
+		manager.invitesRWMutex.Unlock()
+		return nil, ErrInvitationNotFound
+	}
+	invitations, exist := manager.invitations[invitee]
+	if !exist {
+		invitations = make(map[string]*RoomInvitation[EntityID, RoomID])
+		manager.invitations[invitee] = invitations
+	}
+	invitations[code] = invitation
+	manager.invitesRWMutex.Unlock()
+
+	manager.OnRoomInviteEvent(slf, invitation)
+	return invitation, nil
+}
+
+// JoinByCode 通过房间加入码使实体加入房间，并触发通过加入码加入房间事件
+//   - 通过加入码加入房间时无需提供房间密码
+func (slf *RoomManager[EntityID, RoomID, Entity, Room]) JoinByCode(code string, entity Entity) (*RoomController[EntityID, RoomID, Entity, Room], error) {
+	controller := slf.GetRoomByCode(code)
+	if controller == nil {
+		return nil, ErrJoinCodeNotFound
+	}
+	if err := controller.admit(entity); err != nil {
+		return nil, err
+	}
+	slf.OnRoomJoinByCodeEvent(controller, entity, code)
+	return controller, nil
+}
+
+// GetRoomByCode 获取加入码对应的房间，当加入码不存在或已失效时将返回 nil
+func (slf *RoomManager[EntityID, RoomID, Entity, Room]) GetRoomByCode(code string) *RoomController[EntityID, RoomID, Entity, Room] {
+	slf.invitesRWMutex.RLock()
+	id, exist := slf.codes[strings.ToUpper(code)]
+	slf.invitesRWMutex.RUnlock()
+	if !exist {
+		return nil
+	}
+	return slf.GetRoom(id)
+}
+
+// GetInvitations 获取实体收到的所有未过期的邀请
+func (slf *RoomManager[EntityID, RoomID, Entity, Room]) GetInvitations(invitee EntityID) []*RoomInvitation[EntityID, RoomID] {
+	slf.invitesRWMutex.Lock()
+	defer slf.invitesRWMutex.Unlock()
+	var result []*RoomInvitation[EntityID, RoomID]
+	for code, invitation := range slf.invitations[invitee] {
+		if invitation.IsExpired() {
+			delete(slf.invitations[invitee], code)
+			continue
+		}
+		result = append(result, invitation)
+	}
+	if len(slf.invitations[invitee]) == 0 {
+		delete(slf.invitations, invitee)
+	}
+	return result
+}
+
+// AcceptInvitation 接受邀请并使实体加入房间，成功加入房间后将触发房间邀请答复事件
+//   - code 为邀请对应的房间加入码，即 RoomInvitation.Code
+func (slf *RoomManager[EntityID, RoomID, Entity, Room]) AcceptInvitation(code string, entity Entity) (*RoomController[EntityID, RoomID, Entity, Room], error) {
+	invitation, err := slf.takeInvitation(code, entity.GetId())
+	if err != nil {
+		return nil, err
+	}
+	controller := slf.GetRoom(invitation.RoomID)
+	if controller == nil {
+		return nil, ErrRoomNotFound
+	}
+	if err = controller.admit(entity); err != nil {
+		return nil, err
+	}
+	slf.OnRoomInvitationReplyEvent(controller, invitation, true)
+	return controller, nil
+}
+
+// DeclineInvitation 拒绝邀请，并触发房间邀请答复事件
+func (slf *RoomManager[EntityID, RoomID, Entity, Room]) DeclineInvitation(code string, invitee EntityID) error {
+	invitation, err := slf.takeInvitation(code, invitee)
+	if err != nil {
+		return err
+	}
+	if controller := slf.GetRoom(invitation.RoomID); controller != nil {
+		slf.OnRoomInvitationReplyEvent(controller, invitation, false)
+	}
+	return nil
+}
+
+// takeInvitation 取出实体收到的特定邀请，取出后邀请将被移除
+func (slf *RoomManager[EntityID, RoomID, Entity, Room]) takeInvitation(code string, invitee EntityID) (*RoomInvitation[EntityID, RoomID], error) {
+	code = strings.ToUpper(code)
+	slf.invitesRWMutex.Lock()
+	defer slf.invitesRWMutex.Unlock()
+	invitation, exist := slf.invitations[invitee][code]
+	if !exist {
+		return nil, ErrInvitationNotFound
+	}
+	delete(slf.invitations[invitee], code)
+	if len(slf.invitations[invitee]) == 0 {
+		delete(slf.invitations, invitee)
+	}
+	if invitation.IsExpired() {
+		return nil, ErrInvitationExpired
+	}
+	return invitation, nil
+}
+
+// hasJoinCode 检查加入码是否已被使用（无锁）
+func (slf *RoomManager[EntityID, RoomID, Entity, Room]) hasJoinCode(code string) bool {
+	_, exist := slf.codes[code]
+	return exist
+}
+
+// admit 在不校验房间密码的情况下使实体加入房间
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) admit(entity Entity) error {
+	slf.entitiesRWMutex.Lock()
+	defer slf.entitiesRWMutex.Unlock()
+	if _, exist := slf.entities[entity.GetId()]; exist {
+		return ErrAlreadyInRoom
+	}
+	if slf.isBanned(entity.GetId()) {
+		return ErrBanned
+	}
+	if slf.isFull() {
+		return ErrRoomFull
+	}
+	slf.addEntity(entity)
+	return nil
+}
@@ -0,0 +1,264 @@
+package space_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/game/space"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoomController_GenerateJoinCode(t *testing.T) {
+	// 加入码长度为 1 时字符集恰好可容纳 32 个房间，每个房间的加入码均不应重复
+	manager := newTestRoomManager(space.NewRoomManagerOptions().WithJoinCodeLength(1))
+	var codes = make(map[string]string)
+	for i := 0; i < 32; i++ {
+		room := manager.AssumeControl(&testRoom{id: fmt.Sprintf("room-%d", i)})
+		code := room.GenerateJoinCode()
+		if len(code) != 1 {
+			t.Fatalf("expected code length 1, got %q", code)
+		}
+		if id, exist := codes[code]; exist {
+			t.Fatalf("code %q of %s duplicates %s", code, room.GetRoomID(), id)
+		}
+		codes[code] = room.GetRoomID()
+	}
+	for code, id := range codes {
+		if room := manager.GetRoomByCode(code); room == nil || room.GetRoomID() != id {
+			t.Fatalf("expected code %q to resolve to %s, got %v", code, id, room)
+		}
+	}
+}
+
+func TestRoomController_RevokeJoinCode(t *testing.T) {
+	manager := newTestRoomManager()
+	room := manager.AssumeControl(&testRoom{id: "room"})
+	_ = room.AddEntity(&testEntity{id: "a"})
+	old := room.GenerateJoinCode()
+	if room.GetJoinCode() != old {
+		t.Fatalf("expected join code %q, got %q", old, room.GetJoinCode())
+	}
+	if _, err := room.Invite("a", "b", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重新生成加入码后原有加入码及通过其发出的邀请均将失效
+	code := room.GenerateJoinCode()
+	if code == old {
+		t.Fatal("expected a new join code")
+	}
+	if manager.GetRoomByCode(old) != nil {
+		t.Fatalf("expected code %q to be revoked", old)
+	}
+	if _, err := manager.JoinByCode(old, &testEntity{id: "c"}); !errors.Is(err, space.ErrJoinCodeNotFound) {
+		t.Fatalf("expected %v, got %v", space.ErrJoinCodeNotFound, err)
+	}
+	if _, err := manager.AcceptInvitation(old, &testEntity{id: "b"}); !errors.Is(err, space.ErrInvitationNotFound) {
+		t.Fatalf("expected %v, got %v", space.ErrInvitationNotFound, err)
+	}
+
+	// 加入码不区分大小写
+	if r := manager.GetRoomByCode(strings.ToLower(code)); r != room {
+		t.Fatalf("expected code %q to resolve to the room", strings.ToLower(code))
+	}
+
+	room.RevokeJoinCode()
+	if room.GetJoinCode() != "" || manager.GetRoomByCode(code) != nil {
+		t.Fatalf("expected code %q to be revoked", code)
+	}
+}
+
+func TestRoomManager_JoinByCode(t *testing.T) {
+	manager := newTestRoomManager()
+	var joined []string
+	manager.RegRoomJoinByCodeEvent(func(controller *testRoomController, entity *testEntity, code string) {
+		joined = append(joined, entity.GetId()+":"+code)
+	})
+	room := manager.AssumeControl(&testRoom{id: "room"}, space.NewRoomControllerOptions().WithMaxEntityCount(2).WithPassword("secret"))
+	code := room.GenerateJoinCode()
+
+	// 通过加入码加入设置了密码的房间时无需提供密码
+	if err := room.AddEntity(&testEntity{id: "a"}); !errors.Is(err, space.ErrRoomPasswordNotMatch) {
+		t.Fatalf("expected %v, got %v", space.ErrRoomPasswordNotMatch, err)
+	}
+	if r, err := manager.JoinByCode(code, &testEntity{id: "a"}); err != nil || r != room {
+		t.Fatalf("expected to join the room, got %v", err)
+	}
+	if _, err := manager.JoinByCode(code, &testEntity{id: "a"}); !errors.Is(err, space.ErrAlreadyInRoom) {
+		t.Fatalf("expected %v, got %v", space.ErrAlreadyInRoom, err)
+	}
+	if _, err := manager.JoinByCode(code, &testEntity{id: "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 房间已满时无法通过加入码加入
+	if _, err := manager.JoinByCode(code, &testEntity{id: "c"}); !errors.Is(err, space.ErrRoomFull) {
+		t.Fatalf("expected %v, got %v", space.ErrRoomFull, err)
+	}
+	if room.HasEntity("c") {
+		t.Fatal("expected c not to join the full room")
+	}
+	if _, err := manager.JoinByCode("unknown", &testEntity{id: "d"}); !errors.Is(err, space.ErrJoinCodeNotFound) {
+		t.Fatalf("expected %v, got %v", space.ErrJoinCodeNotFound, err)
+	}
+
+	var expected = []string{"a:" + code, "b:" + code}
+	if len(joined) != len(expected) || joined[0] != expected[0] || joined[1] != expected[1] {
+		t.Fatalf("expected events %v, got %v", expected, joined)
+	}
+}
+
+func TestRoomController_Invite(t *testing.T) {
+	manager := newTestRoomManager()
+	var events []string
+	manager.RegRoomInviteEvent(func(controller *testRoomController, invitation *space.RoomInvitation[string, string]) {
+		events = append(events, "invite:"+invitation.Inviter+":"+invitation.Invitee)
+	})
+	room := manager.AssumeControl(&testRoom{id: "room"})
+	_ = room.AddEntity(&testEntity{id: "a"})
+
+	if _, err := room.Invite("x", "b", 0); !errors.Is(err, space.ErrNotInRoom) {
+		t.Fatalf("expected %v, got %v", space.ErrNotInRoom, err)
+	}
+	if _, err := room.Invite("a", "a", 0); !errors.Is(err, space.ErrAlreadyInRoom) {
+		t.Fatalf("expected %v, got %v", space.ErrAlreadyInRoom, err)
+	}
+
+	// 房间没有加入码时将自动生成加入码
+	invitation, err := room.Invite("a", "b", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if invitation.Code == "" || invitation.Code != room.GetJoinCode() {
+		t.Fatalf("expected invitation code %q, got %q", room.GetJoinCode(), invitation.Code)
+	}
+	if invitation.RoomID != "room" || invitation.ExpireAt.IsZero() || invitation.IsExpired() {
+		t.Fatalf("unexpected invitation %+v", invitation)
+	}
+
+	// 重复邀请同一实体将覆盖之前的邀请
+	if _, err = room.Invite("a", "b", 0); err != nil {
+		t.Fatal(err)
+	}
+	invitations := manager.GetInvitations("b")
+	if len(invitations) != 1 || !invitations[0].ExpireAt.IsZero() {
+		t.Fatalf("expected 1 invitation without expiry, got %v", invitations)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 invite events, got %v", events)
+	}
+}
+
+func TestRoomManager_AcceptInvitation(t *testing.T) {
+	manager := newTestRoomManager()
+	var replies []string
+	manager.RegRoomInvitationReplyEvent(func(controller *testRoomController, invitation *space.RoomInvitation[string, string], accept bool) {
+		replies = append(replies, fmt.Sprintf("%s:%v", invitation.Invitee, accept))
+	})
+	room := manager.AssumeControl(&testRoom{id: "room"}, space.NewRoomControllerOptions().WithPassword("secret"))
+	_ = room.AddEntityByPassword(&testEntity{id: "a"}, "secret")
+	invitation, err := room.Invite("a", "b", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 非被邀请者无法接受或拒绝邀请，且不会影响被邀请者的邀请
+	if _, err = manager.AcceptInvitation(invitation.Code, &testEntity{id: "c"}); !errors.Is(err, space.ErrInvitationNotFound) {
+		t.Fatalf("expected %v, got %v", space.ErrInvitationNotFound, err)
+	}
+	if err = manager.DeclineInvitation(invitation.Code, "c"); !errors.Is(err, space.ErrInvitationNotFound) {
+		t.Fatalf("expected %v, got %v", space.ErrInvitationNotFound, err)
+	}
+	if room.HasEntity("c") {
+		t.Fatal("expected c not to join the room")
+	}
+
+	// 被邀请者接受邀请时无需提供房间密码，邀请被接受后即被移除
+	if r, err := manager.AcceptInvitation(strings.ToLower(invitation.Code), &testEntity{id: "b"}); err != nil || r != room {
+		t.Fatalf("expected to join the room, got %v", err)
+	}
+	if !room.HasEntity("b") {
+		t.Fatal("expected b to join the room")
+	}
+	if len(manager.GetInvitations("b")) != 0 {
+		t.Fatal("expected the accepted invitation to be removed")
+	}
+	if _, err = manager.AcceptInvitation(invitation.Code, &testEntity{id: "b"}); !errors.Is(err, space.ErrInvitationNotFound) {
+		t.Fatalf("expected %v, got %v", space.ErrInvitationNotFound, err)
+	}
+	if len(replies) != 1 || replies[0] != "b:true" {
+		t.Fatalf("expected replies [b:true], got %v", replies)
+	}
+}
+
+func TestRoomManager_DeclineInvitation(t *testing.T) {
+	manager := newTestRoomManager()
+	var replies []string
+	manager.RegRoomInvitationReplyEvent(func(controller *testRoomController, invitation *space.RoomInvitation[string, string], accept bool) {
+		replies = append(replies, fmt.Sprintf("%s:%v", invitation.Invitee, accept))
+	})
+	room := manager.AssumeControl(&testRoom{id: "room"})
+	_ = room.AddEntity(&testEntity{id: "a"})
+	invitation, err := room.Invite("a", "b", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = manager.DeclineInvitation(invitation.Code, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = manager.AcceptInvitation(invitation.Code, &testEntity{id: "b"}); !errors.Is(err, space.ErrInvitationNotFound) {
+		t.Fatalf("expected %v, got %v", space.ErrInvitationNotFound, err)
+	}
+	if room.HasEntity("b") {
+		t.Fatal("expected b not to join the room")
+	}
+	if len(replies) != 1 || replies[0] != "b:false" {
+		t.Fatalf("expected replies [b:false], got %v", replies)
+	}
+}
+
+func TestRoomManager_InvitationExpired(t *testing.T) {
+	manager := newTestRoomManager()
+	var replies int
+	manager.RegRoomInvitationReplyEvent(func(controller *testRoomController, invitation *space.RoomInvitation[string, string], accept bool) {
+		replies++
+	})
+	room := manager.AssumeControl(&testRoom{id: "room"})
+	_ = room.AddEntity(&testEntity{id: "a"})
+	accepted, err := room.Invite("a", "b", 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	declined, err := room.Invite("a", "c", 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+
+	// 过期的邀请无法被接受或拒绝，也不会再被获取到
+	if !accepted.IsExpired() {
+		t.Fatal("expected the invitation to expire")
+	}
+	if _, err = manager.AcceptInvitation(accepted.Code, &testEntity{id: "b"}); !errors.Is(err, space.ErrInvitationExpired) {
+		t.Fatalf("expected %v, got %v", space.ErrInvitationExpired, err)
+	}
+	if room.HasEntity("b") {
+		t.Fatal("expected b not to join the room")
+	}
+	if err = manager.DeclineInvitation(declined.Code, "c"); !errors.Is(err, space.ErrInvitationExpired) {
+		t.Fatalf("expected %v, got %v", space.ErrInvitationExpired, err)
+	}
+	if replies != 0 {
+		t.Fatalf("expected no reply events, got %d", replies)
+	}
+
+	if _, err = room.Invite("a", "d", 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if invitations := manager.GetInvitations("d"); len(invitations) != 0 {
+		t.Fatalf("expected expired invitations to be dropped, got %v", invitations)
+	}
+}
@@ -0,0 +1,167 @@
+package space
+
+import (
+	"reflect"
+	"sort"
+)
+
+// NewRoomListFilter 创建房间列表过滤器，同一个过滤器中的所有条件需要同时满足
+func NewRoomListFilter() *RoomListFilter {
+	return &RoomListFilter{}
+}
+
+// RoomListFilter 房间列表过滤器
+type RoomListFilter struct {
+	notFull  bool
+	password *bool
+	states   []RoomState
+	attrs    map[string]func(value any) bool
+}
+
+// WithNotFull 仅保留未满员的房间
+func (slf *RoomListFilter) WithNotFull() *RoomListFilter {
+	slf.notFull = true
+	return slf
+}
+
+// WithPassword 仅保留设置了密码或未设置密码的房间
+func (slf *RoomListFilter) WithPassword(hasPassword bool) *RoomListFilter {
+	slf.password = &hasPassword
+	return slf
+}
+
+// WithState 仅保留处于特定状态之一的房间
+func (slf *RoomListFilter) WithState(states ...RoomState) *RoomListFilter {
+	slf.states = append(slf.states, states...)
+	return slf
+}
+
+// WithAttr 仅保留特定属性等于 value 的房间
+func (slf *RoomListFilter) WithAttr(key string, value any) *RoomListFilter {
+	return slf.WithAttrFunc(key, func(attr any) bool {
+		return reflect.DeepEqual(attr, value)
+	})
+}
+
+// WithAttrFunc 仅保留特定属性满足 match 的房间，当房间不存在该属性时 match 将接收到 nil
+func (slf *RoomListFilter) WithAttrFunc(key string, match func(value any) bool) *RoomListFilter {
+	if slf.attrs == nil {
+		slf.attrs = make(map[string]func(value any) bool)
+	}
+	slf.attrs[key] = match
+	return slf
+}
+
+// match 检查房间概要是否满足过滤器的所有条件
+func (slf *RoomListFilter) match(summary *roomListCandidate) bool {
+	if slf.notFull && summary.maxEntityCount > 0 && summary.entityCount >= summary.maxEntityCount {
+		return false
+	}
+	if slf.password != nil && *slf.password != summary.hasPassword {
+		return false
+	}
+	if len(slf.states) > 0 {
+		var exist bool
+		for _, state := range slf.states {
+			if state == summary.state {
+				exist = true
+				break
+			}
+		}
+		if !exist {
+			return false
+		}
+	}
+	for key, match := range slf.attrs {
+		if !match(summary.attrs[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// roomListCandidate 用于过滤的房间信息
+type roomListCandidate struct {
+	entityCount    int
+	maxEntityCount int
+	hasPassword    bool
+	state          RoomState
+	attrs          map[string]any
+}
+
+// RoomSummary 房间概要，用于大厅等场景的房间列表展示
+type RoomSummary[RoomID comparable] struct {
+	ID             RoomID         // 房间 ID
+	State          RoomState      // 房间状态
+	EntityCount    int            // 实体数量
+	SpectatorCount int            // 观战者数量
+	MaxEntityCount int            // 最大实体数量，为 0 时表示不限制
+	HasPassword    bool           // 是否设置了密码
+	Attrs          map[string]any // 房间属性
+}
+
+// List 按照房间被接管的顺序分页获取满足任一过滤器的房间概要，当未指定过滤器时将返回所有房间
+//   - page 从 1 开始，当 page 或 size 小于等于 0 时将返回所有满足条件的房间
+//   - total 为满足条件的房间总数
+func (slf *RoomManager[EntityID, RoomID, Entity, Room]) List(page, size int, filters ...*RoomListFilter) (summaries []*RoomSummary[RoomID], total int) {
+	slf.roomsRWMutex.RLock()
+	rooms := make([]*RoomController[EntityID, RoomID, Entity, Room], 0, len(slf.rooms))
+	for _, room := range slf.rooms {
+		rooms = append(rooms, room)
+	}
+	slf.roomsRWMutex.RUnlock()
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].index < rooms[j].index
+	})
+
+	for _, room := range rooms {
+		summary := room.summary()
+		if len(filters) > 0 {
+			candidate := &roomListCandidate{
+				entityCount:    summary.EntityCount,
+				maxEntityCount: summary.MaxEntityCount,
+				hasPassword:    summary.HasPassword,
+				state:          summary.State,
+				attrs:          summary.Attrs,
+			}
+			var matched bool
+			for _, filter := range filters {
+				if matched = filter.match(candidate); matched {
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		}
+		total++
+		if page > 0 && size > 0 && (total <= (page-1)*size || total > page*size) {
+			continue
+		}
+		summaries = append(summaries, summary)
+	}
+	return
+}
+
+// summary 获取房间概要
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) summary() *RoomSummary[RoomID] {
+	slf.entitiesRWMutex.RLock()
+	summary := &RoomSummary[RoomID]{
+		ID:             slf.room.GetId(),
+		EntityCount:    len(slf.entities),
+		SpectatorCount: len(slf.spectators),
+		HasPassword:    slf.options.password != nil,
+	}
+	if slf.options.maxEntityCount != nil {
+		summary.MaxEntityCount = *slf.options.maxEntityCount
+	}
+	slf.entitiesRWMutex.RUnlock()
+	summary.State = slf.GetState()
+	summary.Attrs = slf.GetAttrs()
+	return summary
+}
+
+// GetSummary 获取房间概要
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) GetSummary() *RoomSummary[RoomID] {
+	return slf.summary()
+}
@@ -0,0 +1,133 @@
+package space_test
+
+import (
+	"github.com/kercylan98/minotaur/game/space"
+	"strings"
+	"testing"
+)
+
+func newTestRoomListManager(t *testing.T) *testRoomManager {
+	t.Helper()
+	manager := newTestRoomManager()
+	var rooms = []struct {
+		id       string
+		password string
+		options  *space.RoomControllerOptions
+		entities []string
+	}{
+		{"r0", "", space.NewRoomControllerOptions().WithMaxEntityCount(2).WithAttr("mode", "rank"), []string{"a", "b"}},
+		{"r1", "secret", space.NewRoomControllerOptions().WithMaxEntityCount(2).WithPassword("secret").WithAttr("mode", "casual"), []string{"c"}},
+		{"r2", "secret", space.NewRoomControllerOptions().WithPassword("secret").WithAttr("mode", "rank"), []string{"d", "e", "f"}},
+		{"r3", "", space.NewRoomControllerOptions().WithMaxEntityCount(4).WithAttr("mode", "casual"), nil},
+		{"r4", "secret", space.NewRoomControllerOptions().WithMaxEntityCount(1).WithPassword("secret"), []string{"g"}},
+	}
+	for _, r := range rooms {
+		room := manager.AssumeControl(&testRoom{id: r.id}, r.options)
+		for _, id := range r.entities {
+			var err error
+			if r.password == "" {
+				err = room.AddEntity(&testEntity{id: id})
+			} else {
+				err = room.AddEntityByPassword(&testEntity{id: id}, r.password)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return manager
+}
+
+func roomSummaryIds(summaries []*space.RoomSummary[string]) string {
+	var ids = make([]string, 0, len(summaries))
+	for _, summary := range summaries {
+		ids = append(ids, summary.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestRoomManager_ListPage(t *testing.T) {
+	manager := newTestRoomListManager(t)
+	var cases = []struct {
+		name       string
+		page, size int
+		expected   string
+	}{
+		{"first page", 1, 2, "r0,r1"},
+		{"middle page", 2, 2, "r2,r3"},
+		{"last page", 3, 2, "r4"},
+		{"out of range", 4, 2, ""},
+		{"exact size", 1, 5, "r0,r1,r2,r3,r4"},
+		{"zero size", 1, 0, "r0,r1,r2,r3,r4"},
+		{"negative size", 2, -1, "r0,r1,r2,r3,r4"},
+		{"zero page", 0, 2, "r0,r1,r2,r3,r4"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			summaries, total := manager.List(c.page, c.size)
+			if ids := roomSummaryIds(summaries); ids != c.expected {
+				t.Fatalf("expected rooms %q, got %q", c.expected, ids)
+			}
+			if total != 5 {
+				t.Fatalf("expected total 5, got %d", total)
+			}
+		})
+	}
+}
+
+func TestRoomManager_ListFilter(t *testing.T) {
+	manager := newTestRoomListManager(t)
+	_ = manager.GetRoom("r3").ChangeState(space.RoomStateReady)
+
+	var cases = []struct {
+		name       string
+		page, size int
+		filters    []*space.RoomListFilter
+		expected   string
+		total      int
+	}{
+		{"not full", 0, 0, []*space.RoomListFilter{space.NewRoomListFilter().WithNotFull()}, "r1,r2,r3", 3},
+		{"with password", 0, 0, []*space.RoomListFilter{space.NewRoomListFilter().WithPassword(true)}, "r1,r2,r4", 3},
+		{"without password", 0, 0, []*space.RoomListFilter{space.NewRoomListFilter().WithPassword(false)}, "r0,r3", 2},
+		{"attr", 0, 0, []*space.RoomListFilter{space.NewRoomListFilter().WithAttr("mode", "rank")}, "r0,r2", 2},
+		{"missing attr", 0, 0, []*space.RoomListFilter{space.NewRoomListFilter().WithAttrFunc("mode", func(value any) bool {
+			return value == nil
+		})}, "r4", 1},
+		{"state", 0, 0, []*space.RoomListFilter{space.NewRoomListFilter().WithState(space.RoomStateReady, space.RoomStatePlaying)}, "r3", 1},
+		{"and within a filter", 0, 0, []*space.RoomListFilter{space.NewRoomListFilter().WithNotFull().WithPassword(true)}, "r1,r2", 2},
+		{"and with attr", 0, 0, []*space.RoomListFilter{space.NewRoomListFilter().WithNotFull().WithAttr("mode", "rank")}, "r2", 1},
+		{"or across filters", 0, 0, []*space.RoomListFilter{
+			space.NewRoomListFilter().WithAttr("mode", "rank").WithPassword(false),
+			space.NewRoomListFilter().WithNotFull().WithPassword(true),
+		}, "r0,r1,r2", 3},
+		{"no match", 0, 0, []*space.RoomListFilter{space.NewRoomListFilter().WithAttr("mode", "unknown")}, "", 0},
+		{"filtered page", 2, 2, []*space.RoomListFilter{space.NewRoomListFilter().WithNotFull()}, "r3", 3},
+		{"filtered out of range", 3, 2, []*space.RoomListFilter{space.NewRoomListFilter().WithNotFull()}, "", 3},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			summaries, total := manager.List(c.page, c.size, c.filters...)
+			if ids := roomSummaryIds(summaries); ids != c.expected {
+				t.Fatalf("expected rooms %q, got %q", c.expected, ids)
+			}
+			if total != c.total {
+				t.Fatalf("expected total %d, got %d", c.total, total)
+			}
+		})
+	}
+}
+
+func TestRoomManager_ListSummary(t *testing.T) {
+	manager := newTestRoomListManager(t)
+	_ = manager.GetRoom("r1").AddSpectatorByPassword(&testEntity{id: "s"}, "secret")
+
+	summaries, _ := manager.List(2, 1)
+	if len(summaries) != 1 {
+		t.Fatalf("expected 1 room, got %d", len(summaries))
+	}
+	summary := summaries[0]
+	if summary.ID != "r1" || summary.State != space.RoomStateWaiting || summary.EntityCount != 1 || summary.SpectatorCount != 1 ||
+		summary.MaxEntityCount != 2 || !summary.HasPassword || summary.Attrs["mode"] != "casual" {
+		t.Fatalf("unexpected summary %+v", summary)
+	}
+}
@@ -1,33 +1,62 @@
 package space
 
 import (
+	"github.com/kercylan98/minotaur/server"
 	"github.com/kercylan98/minotaur/utils/generic"
 	"github.com/kercylan98/minotaur/utils/hash"
+	"github.com/kercylan98/minotaur/utils/timer"
 	"sync"
+	"sync/atomic"
 )
 
 // NewRoomManager 创建房间管理器
-func NewRoomManager[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]() *RoomManager[EntityID, RoomID, Entity, Room] {
-	return &RoomManager[EntityID, RoomID, Entity, Room]{
+func NewRoomManager[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]](options ...*RoomManagerOptions) *RoomManager[EntityID, RoomID, Entity, Room] {
+	manager := &RoomManager[EntityID, RoomID, Entity, Room]{
 		roomManagerEvents: new(roomManagerEvents[EntityID, RoomID, Entity, Room]),
+		options:           mergeRoomManagerOptions(options...),
 		rooms:             make(map[RoomID]*RoomController[EntityID, RoomID, Entity, Room]),
+		conns:             make(map[EntityID]*server.Conn),
+		codes:             make(map[string]RoomID),
+		invitations:       make(map[EntityID]map[string]*RoomInvitation[EntityID, RoomID]),
 	}
+	if manager.ticker = manager.options.ticker; manager.ticker == nil && (manager.options.emptyDestroy > 0 || manager.options.maxLifetime > 0) {
+		manager.ticker = timer.GetTicker(10)
+	}
+	return manager
 }
 
 // RoomManager 房间管理器
 type RoomManager[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]] struct {
 	*roomManagerEvents[EntityID, RoomID, Entity, Room]
+	options      *RoomManagerOptions
+	ticker       *timer.Ticker
 	roomsRWMutex sync.RWMutex
 	rooms        map[RoomID]*RoomController[EntityID, RoomID, Entity, Room]
+	connsRWMutex sync.RWMutex
+	conns        map[EntityID]*server.Conn // 实体绑定的连接
+	index        atomic.Uint64             // 房间接管顺序的计数器
+
+	invitesRWMutex sync.RWMutex
+	codes          map[string]RoomID                                         // 房间加入码对应的房间
+	invitations    map[EntityID]map[string]*RoomInvitation[EntityID, RoomID] // 实体收到的邀请 [invitee][code]
 }
 
 // AssumeControl 将房间控制权交由 RoomManager 接管
 func (slf *RoomManager[EntityID, RoomID, Entity, Room]) AssumeControl(room Room, options ...*RoomControllerOptions) *RoomController[EntityID, RoomID, Entity, Room] {
 	controller := newRoomController(slf, room, mergeRoomControllerOptions(options...))
-	slf.OnRoomAssumeControlEvent(controller)
+	slf.assumeControl(controller)
 	return controller
 }
 
+// assumeControl 触发房间接管事件并开始房间自动销毁的计时
+func (slf *RoomManager[EntityID, RoomID, Entity, Room]) assumeControl(controller *RoomController[EntityID, RoomID, Entity, Room]) {
+	slf.OnRoomAssumeControlEvent(controller)
+	controller.armLifetimeDestroy()
+	controller.entitiesRWMutex.Lock()
+	controller.armEmptyDestroy()
+	controller.entitiesRWMutex.Unlock()
+}
+
 // DestroyRoom 销毁房间
 func (slf *RoomManager[EntityID, RoomID, Entity, Room]) DestroyRoom(id RoomID) {
 	slf.roomsRWMutex.Lock()
@@ -103,3 +132,47 @@ func (slf *RoomManager[EntityID, RoomID, Entity, Room]) Broadcast(handler func(E
 		room.Broadcast(handler, conditions...)
 	}
 }
+
+// BindConn 将实体与连接进行绑定，绑定后可通过 RoomController.BroadcastPacket 等函数向房间内的实体发送数据包
+//   - 重复绑定将覆盖之前绑定的连接
+//   - 当实体已经在房间中时，连接将与该房间关联，可配合 RoomShardStrategy 使用
+func (slf *RoomManager[EntityID, RoomID, Entity, Room]) BindConn(entityId EntityID, conn *server.Conn) {
+	slf.connsRWMutex.Lock()
+	slf.conns[entityId] = conn
+	slf.connsRWMutex.Unlock()
+	for _, room := range slf.GetEntityRooms(entityId) {
+		room.bindAffinity(entityId)
+		break
+	}
+}
+
+// UnbindConn 解除实体与连接的绑定
+func (slf *RoomManager[EntityID, RoomID, Entity, Room]) UnbindConn(entityId EntityID) {
+	slf.connsRWMutex.Lock()
+	conn, exist := slf.conns[entityId]
+	delete(slf.conns, entityId)
+	slf.connsRWMutex.Unlock()
+	if exist && conn != nil {
+		conn.SetData(roomAffinityKey{}, nil)
+	}
+}
+
+// GetConn 获取实体绑定的连接，当实体未绑定连接时将返回 nil
+func (slf *RoomManager[EntityID, RoomID, Entity, Room]) GetConn(entityId EntityID) *server.Conn {
+	slf.connsRWMutex.RLock()
+	defer slf.connsRWMutex.RUnlock()
+	return slf.conns[entityId]
+}
+
+// FilterRooms 获取满足特定条件的所有房间
+func (slf *RoomManager[EntityID, RoomID, Entity, Room]) FilterRooms(filter func(controller *RoomController[EntityID, RoomID, Entity, Room]) bool) map[RoomID]*RoomController[EntityID, RoomID, Entity, Room] {
+	slf.roomsRWMutex.RLock()
+	rooms := hash.Copy(slf.rooms)
+	slf.roomsRWMutex.RUnlock()
+	for id, room := range rooms {
+		if !filter(room) {
+			delete(rooms, id)
+		}
+	}
+	return rooms
+}
@@ -3,19 +3,43 @@ package space
 import "github.com/kercylan98/minotaur/utils/generic"
 
 type (
-	RoomAssumeControlEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]  func(controller *RoomController[EntityID, RoomID, Entity, Room])
-	RoomDestroyEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]        func(controller *RoomController[EntityID, RoomID, Entity, Room])
-	RoomAddEntityEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]      func(controller *RoomController[EntityID, RoomID, Entity, Room], entity Entity)
-	RoomRemoveEntityEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]   func(controller *RoomController[EntityID, RoomID, Entity, Room], entity Entity)
-	RoomChangePasswordEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]] func(controller *RoomController[EntityID, RoomID, Entity, Room], oldPassword, password *string)
+	RoomAssumeControlEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]     func(controller *RoomController[EntityID, RoomID, Entity, Room])
+	RoomDestroyEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]           func(controller *RoomController[EntityID, RoomID, Entity, Room])
+	RoomAddEntityEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]         func(controller *RoomController[EntityID, RoomID, Entity, Room], entity Entity)
+	RoomRemoveEntityEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]      func(controller *RoomController[EntityID, RoomID, Entity, Room], entity Entity)
+	RoomChangePasswordEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]    func(controller *RoomController[EntityID, RoomID, Entity, Room], oldPassword, password *string)
+	RoomAddSpectatorEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]      func(controller *RoomController[EntityID, RoomID, Entity, Room], spectator Entity)
+	RoomRemoveSpectatorEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]   func(controller *RoomController[EntityID, RoomID, Entity, Room], spectator Entity)
+	RoomEntityKickedEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]      func(controller *RoomController[EntityID, RoomID, Entity, Room], entity Entity, reason string)
+	RoomStateGuardHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]             func(controller *RoomController[EntityID, RoomID, Entity, Room], from, to RoomState) error
+	RoomStateChangeEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]       func(controller *RoomController[EntityID, RoomID, Entity, Room], from, to RoomState)
+	RoomEntityReadyChangeEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]] func(controller *RoomController[EntityID, RoomID, Entity, Room], entity Entity, ready bool)
+	RoomAttrChangeEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]        func(controller *RoomController[EntityID, RoomID, Entity, Room], key string, old, value any)
+	RoomWaitlistAdmittedEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]  func(controller *RoomController[EntityID, RoomID, Entity, Room], entity Entity)
+	RoomAutoDestroyEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]       func(controller *RoomController[EntityID, RoomID, Entity, Room], reason RoomAutoDestroyReason) (cancel bool)
+	RoomInviteEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]            func(controller *RoomController[EntityID, RoomID, Entity, Room], invitation *RoomInvitation[EntityID, RoomID])
+	RoomInvitationReplyEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]   func(controller *RoomController[EntityID, RoomID, Entity, Room], invitation *RoomInvitation[EntityID, RoomID], accept bool)
+	RoomJoinByCodeEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]        func(controller *RoomController[EntityID, RoomID, Entity, Room], entity Entity, code string)
 )
 
 type roomManagerEvents[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]] struct {
-	roomAssumeControlEventHandles  []RoomAssumeControlEventHandle[EntityID, RoomID, Entity, Room]
-	roomDestroyEventHandles        []RoomDestroyEventHandle[EntityID, RoomID, Entity, Room]
-	roomAddEntityEventHandles      []RoomAddEntityEventHandle[EntityID, RoomID, Entity, Room]
-	roomRemoveEntityEventHandles   []RoomRemoveEntityEventHandle[EntityID, RoomID, Entity, Room]
-	roomChangePasswordEventHandles []RoomChangePasswordEventHandle[EntityID, RoomID, Entity, Room]
+	roomAssumeControlEventHandles     []RoomAssumeControlEventHandle[EntityID, RoomID, Entity, Room]
+	roomDestroyEventHandles           []RoomDestroyEventHandle[EntityID, RoomID, Entity, Room]
+	roomAddEntityEventHandles         []RoomAddEntityEventHandle[EntityID, RoomID, Entity, Room]
+	roomRemoveEntityEventHandles      []RoomRemoveEntityEventHandle[EntityID, RoomID, Entity, Room]
+	roomChangePasswordEventHandles    []RoomChangePasswordEventHandle[EntityID, RoomID, Entity, Room]
+	roomAddSpectatorEventHandles      []RoomAddSpectatorEventHandle[EntityID, RoomID, Entity, Room]
+	roomRemoveSpectatorEventHandles   []RoomRemoveSpectatorEventHandle[EntityID, RoomID, Entity, Room]
+	roomEntityKickedEventHandles      []RoomEntityKickedEventHandle[EntityID, RoomID, Entity, Room]
+	roomStateGuardHandles             []RoomStateGuardHandle[EntityID, RoomID, Entity, Room]
+	roomStateChangeEventHandles       []RoomStateChangeEventHandle[EntityID, RoomID, Entity, Room]
+	roomEntityReadyChangeEventHandles []RoomEntityReadyChangeEventHandle[EntityID, RoomID, Entity, Room]
+	roomAttrChangeEventHandles        []RoomAttrChangeEventHandle[EntityID, RoomID, Entity, Room]
+	roomWaitlistAdmittedEventHandles  []RoomWaitlistAdmittedEventHandle[EntityID, RoomID, Entity, Room]
+	roomAutoDestroyEventHandles       []RoomAutoDestroyEventHandle[EntityID, RoomID, Entity, Room]
+	roomInviteEventHandles            []RoomInviteEventHandle[EntityID, RoomID, Entity, Room]
+	roomInvitationReplyEventHandles   []RoomInvitationReplyEventHandle[EntityID, RoomID, Entity, Room]
+	roomJoinByCodeEventHandles        []RoomJoinByCodeEventHandle[EntityID, RoomID, Entity, Room]
 }
 
 // RegRoomAssumeControlEvent 注册房间接管事件
@@ -77,3 +101,155 @@ func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) OnRoomChangePasswo
 		handle(controller, oldPassword, password)
 	}
 }
+
+// RegRoomAddSpectatorEvent 注册房间添加观战者事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) RegRoomAddSpectatorEvent(handle RoomAddSpectatorEventHandle[EntityID, RoomID, Entity, Room]) {
+	slf.roomAddSpectatorEventHandles = append(slf.roomAddSpectatorEventHandles, handle)
+}
+
+// OnRoomAddSpectatorEvent 房间添加观战者事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) OnRoomAddSpectatorEvent(controller *RoomController[EntityID, RoomID, Entity, Room], spectator Entity) {
+	for _, handle := range slf.roomAddSpectatorEventHandles {
+		handle(controller, spectator)
+	}
+}
+
+// RegRoomRemoveSpectatorEvent 注册房间移除观战者事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) RegRoomRemoveSpectatorEvent(handle RoomRemoveSpectatorEventHandle[EntityID, RoomID, Entity, Room]) {
+	slf.roomRemoveSpectatorEventHandles = append(slf.roomRemoveSpectatorEventHandles, handle)
+}
+
+// OnRoomRemoveSpectatorEvent 房间移除观战者事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) OnRoomRemoveSpectatorEvent(controller *RoomController[EntityID, RoomID, Entity, Room], spectator Entity) {
+	for _, handle := range slf.roomRemoveSpectatorEventHandles {
+		handle(controller, spectator)
+	}
+}
+
+// RegRoomEntityKickedEvent 注册房间踢出对象事件，该事件将在对象被移除后触发
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) RegRoomEntityKickedEvent(handle RoomEntityKickedEventHandle[EntityID, RoomID, Entity, Room]) {
+	slf.roomEntityKickedEventHandles = append(slf.roomEntityKickedEventHandles, handle)
+}
+
+// OnRoomEntityKickedEvent 房间踢出对象事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) OnRoomEntityKickedEvent(controller *RoomController[EntityID, RoomID, Entity, Room], entity Entity, reason string) {
+	for _, handle := range slf.roomEntityKickedEventHandles {
+		handle(controller, entity, reason)
+	}
+}
+
+// RegRoomStateGuard 注册房间状态转换守卫，当守卫返回错误时房间状态将不会改变
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) RegRoomStateGuard(handle RoomStateGuardHandle[EntityID, RoomID, Entity, Room]) {
+	slf.roomStateGuardHandles = append(slf.roomStateGuardHandles, handle)
+}
+
+// OnRoomStateGuard 房间状态转换守卫，返回首个守卫返回的错误
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) OnRoomStateGuard(controller *RoomController[EntityID, RoomID, Entity, Room], from, to RoomState) error {
+	for _, handle := range slf.roomStateGuardHandles {
+		if err := handle(controller, from, to); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegRoomStateChangeEvent 注册房间状态改变事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) RegRoomStateChangeEvent(handle RoomStateChangeEventHandle[EntityID, RoomID, Entity, Room]) {
+	slf.roomStateChangeEventHandles = append(slf.roomStateChangeEventHandles, handle)
+}
+
+// OnRoomStateChangeEvent 房间状态改变事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) OnRoomStateChangeEvent(controller *RoomController[EntityID, RoomID, Entity, Room], from, to RoomState) {
+	for _, handle := range slf.roomStateChangeEventHandles {
+		handle(controller, from, to)
+	}
+}
+
+// RegRoomEntityReadyChangeEvent 注册房间对象准备状态改变事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) RegRoomEntityReadyChangeEvent(handle RoomEntityReadyChangeEventHandle[EntityID, RoomID, Entity, Room]) {
+	slf.roomEntityReadyChangeEventHandles = append(slf.roomEntityReadyChangeEventHandles, handle)
+}
+
+// OnRoomEntityReadyChangeEvent 房间对象准备状态改变事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) OnRoomEntityReadyChangeEvent(controller *RoomController[EntityID, RoomID, Entity, Room], entity Entity, ready bool) {
+	for _, handle := range slf.roomEntityReadyChangeEventHandles {
+		handle(controller, entity, ready)
+	}
+}
+
+// RegRoomAttrChangeEvent 注册房间属性改变事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) RegRoomAttrChangeEvent(handle RoomAttrChangeEventHandle[EntityID, RoomID, Entity, Room]) {
+	slf.roomAttrChangeEventHandles = append(slf.roomAttrChangeEventHandles, handle)
+}
+
+// OnRoomAttrChangeEvent 房间属性改变事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) OnRoomAttrChangeEvent(controller *RoomController[EntityID, RoomID, Entity, Room], key string, old, value any) {
+	for _, handle := range slf.roomAttrChangeEventHandles {
+		handle(controller, key, old, value)
+	}
+}
+
+// RegRoomWaitlistAdmittedEvent 注册房间等待队列准入事件，该事件将在等待队列中的实体加入房间后触发
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) RegRoomWaitlistAdmittedEvent(handle RoomWaitlistAdmittedEventHandle[EntityID, RoomID, Entity, Room]) {
+	slf.roomWaitlistAdmittedEventHandles = append(slf.roomWaitlistAdmittedEventHandles, handle)
+}
+
+// OnRoomWaitlistAdmittedEvent 房间等待队列准入事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) OnRoomWaitlistAdmittedEvent(controller *RoomController[EntityID, RoomID, Entity, Room], entity Entity) {
+	for _, handle := range slf.roomWaitlistAdmittedEventHandles {
+		handle(controller, entity)
+	}
+}
+
+// RegRoomAutoDestroyEvent 注册房间自动销毁前事件，当处理函数返回 true 时将取消本次自动销毁
+//   - 因房间为空而取消销毁时，需要房间再次从非空变为空才会重新开始计时
+//   - 因存活时长而取消销毁时，将不会再次触发
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) RegRoomAutoDestroyEvent(handle RoomAutoDestroyEventHandle[EntityID, RoomID, Entity, Room]) {
+	slf.roomAutoDestroyEventHandles = append(slf.roomAutoDestroyEventHandles, handle)
+}
+
+// OnRoomAutoDestroyEvent 房间自动销毁前事件，返回是否取消销毁
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) OnRoomAutoDestroyEvent(controller *RoomController[EntityID, RoomID, Entity, Room], reason RoomAutoDestroyReason) (cancel bool) {
+	for _, handle := range slf.roomAutoDestroyEventHandles {
+		if handle(controller, reason) {
+			cancel = true
+		}
+	}
+	return
+}
+
+// RegRoomInviteEvent 注册房间邀请事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) RegRoomInviteEvent(handle RoomInviteEventHandle[EntityID, RoomID, Entity, Room]) {
+	slf.roomInviteEventHandles = append(slf.roomInviteEventHandles, handle)
+}
+
+// OnRoomInviteEvent 房间邀请事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) OnRoomInviteEvent(controller *RoomController[EntityID, RoomID, Entity, Room], invitation *RoomInvitation[EntityID, RoomID]) {
+	for _, handle := range slf.roomInviteEventHandles {
+		handle(controller, invitation)
+	}
+}
+
+// RegRoomInvitationReplyEvent 注册房间邀请答复事件，当被邀请者接受并成功加入房间或拒绝邀请时触发
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) RegRoomInvitationReplyEvent(handle RoomInvitationReplyEventHandle[EntityID, RoomID, Entity, Room]) {
+	slf.roomInvitationReplyEventHandles = append(slf.roomInvitationReplyEventHandles, handle)
+}
+
+// OnRoomInvitationReplyEvent 房间邀请答复事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) OnRoomInvitationReplyEvent(controller *RoomController[EntityID, RoomID, Entity, Room], invitation *RoomInvitation[EntityID, RoomID], accept bool) {
+	for _, handle := range slf.roomInvitationReplyEventHandles {
+		handle(controller, invitation, accept)
+	}
+}
+
+// RegRoomJoinByCodeEvent 注册通过加入码加入房间事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) RegRoomJoinByCodeEvent(handle RoomJoinByCodeEventHandle[EntityID, RoomID, Entity, Room]) {
+	slf.roomJoinByCodeEventHandles = append(slf.roomJoinByCodeEventHandles, handle)
+}
+
+// OnRoomJoinByCodeEvent 通过加入码加入房间事件
+func (slf *roomManagerEvents[EntityID, RoomID, Entity, Room]) OnRoomJoinByCodeEvent(controller *RoomController[EntityID, RoomID, Entity, Room], entity Entity, code string) {
+	for _, handle := range slf.roomJoinByCodeEventHandles {
+		handle(controller, entity, code)
+	}
+}
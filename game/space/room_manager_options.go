@@ -0,0 +1,66 @@
+package space
+
+import (
+	"github.com/kercylan98/minotaur/utils/timer"
+	"time"
+)
+
+// NewRoomManagerOptions 创建房间管理器选项
+func NewRoomManagerOptions() *RoomManagerOptions {
+	return &RoomManagerOptions{
+		joinCodeLength: 6,
+	}
+}
+
+// mergeRoomManagerOptions 合并房间管理器选项
+func mergeRoomManagerOptions(options ...*RoomManagerOptions) *RoomManagerOptions {
+	result := NewRoomManagerOptions()
+	for _, option := range options {
+		if option.ticker != nil {
+			result.ticker = option.ticker
+		}
+		if option.emptyDestroy > 0 {
+			result.emptyDestroy = option.emptyDestroy
+		}
+		if option.maxLifetime > 0 {
+			result.maxLifetime = option.maxLifetime
+		}
+		if option.joinCodeLength > 0 {
+			result.joinCodeLength = option.joinCodeLength
+		}
+	}
+	return result
+}
+
+type RoomManagerOptions struct {
+	ticker       *timer.Ticker // 驱动自动销毁策略的定时器
+	emptyDestroy time.Duration // 房间为空多久后自动销毁
+	maxLifetime  time.Duration // 房间的最大存活时长
+
+	joinCodeLength int // 房间加入码的长度
+}
+
+// WithTicker 设置驱动自动销毁策略的定时器，例如通过 server.Server.GetTicker 获取的定时器，使自动销毁在服务器的消息循环中执行
+//   - 默认情况下将在启用自动销毁策略时通过 timer.GetTicker 获取定时器
+func (slf *RoomManagerOptions) WithTicker(ticker *timer.Ticker) *RoomManagerOptions {
+	slf.ticker = ticker
+	return slf
+}
+
+// WithEmptyDestroy 设置房间内没有实体持续 duration 时长后自动销毁房间，房间被接管时如果为空也将开始计时
+func (slf *RoomManagerOptions) WithEmptyDestroy(duration time.Duration) *RoomManagerOptions {
+	slf.emptyDestroy = duration
+	return slf
+}
+
+// WithMaxLifetime 设置房间被接管后的最大存活时长，超过该时长后将自动销毁房间
+func (slf *RoomManagerOptions) WithMaxLifetime(duration time.Duration) *RoomManagerOptions {
+	slf.maxLifetime = duration
+	return slf
+}
+
+// WithJoinCodeLength 设置房间加入码的长度，默认为 6
+func (slf *RoomManagerOptions) WithJoinCodeLength(length int) *RoomManagerOptions {
+	slf.joinCodeLength = length
+	return slf
+}
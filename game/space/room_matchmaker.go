@@ -0,0 +1,275 @@
+package space
+
+import (
+	"github.com/kercylan98/minotaur/utils/generic"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	RoomMatchedEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]]         func(controller *RoomController[EntityID, RoomID, Entity, Room], match *RoomMatch[EntityID, Entity])
+	RoomMatchTimeoutEventHandle[EntityID comparable, Entity generic.IdR[EntityID]]                                                 func(entity Entity, waited time.Duration)
+	RoomMatchJoinFailedEventHandle[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]] func(controller *RoomController[EntityID, RoomID, Entity, Room], entity Entity, err error)
+)
+
+// NewRoomMatchmaker 创建基于队列的房间匹配器，匹配成功后将通过 roomFactory 创建房间并交由 manager 接管，匹配的实体将按照队伍顺序加入房间座位
+//   - 第 n 支队伍的第 m 个实体将加入 n*teamSize+m 号座位
+//   - 匹配器不会主动进行匹配，需要定期调用 Match 函数，例如通过服务器的定时器或 Cron 在消息循环中调用
+func NewRoomMatchmaker[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]](manager *RoomManager[EntityID, RoomID, Entity, Room], roomFactory func(match *RoomMatch[EntityID, Entity]) Room, options ...*RoomMatchmakerOptions) *RoomMatchmaker[EntityID, RoomID, Entity, Room] {
+	return &RoomMatchmaker[EntityID, RoomID, Entity, Room]{
+		manager:     manager,
+		roomFactory: roomFactory,
+		options:     mergeRoomMatchmakerOptions(options...),
+		tickets:     make(map[EntityID]*roomMatchTicket[EntityID, Entity]),
+	}
+}
+
+// RoomMatch 一次成功的匹配结果
+type RoomMatch[EntityID comparable, Entity generic.IdR[EntityID]] struct {
+	Teams   [][]Entity    // 按队伍划分的实体，队伍之间的平均分数尽可能接近
+	Ratings [][]float64   // 与 Teams 一一对应的实体分数
+	Waited  time.Duration // 匹配成员中最长的等待时间
+}
+
+// GetEntities 获取匹配结果中的所有实体
+func (slf *RoomMatch[EntityID, Entity]) GetEntities() []Entity {
+	var entities []Entity
+	for _, team := range slf.Teams {
+		entities = append(entities, team...)
+	}
+	return entities
+}
+
+// GetTeamRating 获取特定队伍的平均分数
+func (slf *RoomMatch[EntityID, Entity]) GetTeamRating(team int) float64 {
+	var total float64
+	for _, rating := range slf.Ratings[team] {
+		total += rating
+	}
+	return total / float64(len(slf.Ratings[team]))
+}
+
+// roomMatchTicket 匹配队列中的实体
+type roomMatchTicket[EntityID comparable, Entity generic.IdR[EntityID]] struct {
+	entity    Entity
+	rating    float64
+	deviation float64
+	enqueued  time.Time
+}
+
+// RoomMatchmaker 房间匹配器
+type RoomMatchmaker[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]] struct {
+	manager     *RoomManager[EntityID, RoomID, Entity, Room]
+	roomFactory func(match *RoomMatch[EntityID, Entity]) Room
+	options     *RoomMatchmakerOptions
+	lock        sync.Mutex
+	tickets     map[EntityID]*roomMatchTicket[EntityID, Entity]
+
+	roomMatchedEventHandles         []RoomMatchedEventHandle[EntityID, RoomID, Entity, Room]
+	roomMatchTimeoutEventHandles    []RoomMatchTimeoutEventHandle[EntityID, Entity]
+	roomMatchJoinFailedEventHandles []RoomMatchJoinFailedEventHandle[EntityID, RoomID, Entity, Room]
+}
+
+// Enqueue 将实体加入匹配队列
+//   - rating 为实体的分数，例如 Elo 或 Glicko 的评分
+//   - deviation 为 Glicko 的评分偏差（RD），偏差越大可接受的分数差越大，使用 Elo 等不包含偏差的评分时应传入 0
+func (slf *RoomMatchmaker[EntityID, RoomID, Entity, Room]) Enqueue(entity Entity, rating, deviation float64) error {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	if _, exist := slf.tickets[entity.GetId()]; exist {
+		return ErrAlreadyInMatchQueue
+	}
+	slf.tickets[entity.GetId()] = &roomMatchTicket[EntityID, Entity]{
+		entity:    entity,
+		rating:    rating,
+		deviation: deviation,
+		enqueued:  time.Now(),
+	}
+	return nil
+}
+
+// Dequeue 将实体移出匹配队列，返回实体是否在匹配队列中
+func (slf *RoomMatchmaker[EntityID, RoomID, Entity, Room]) Dequeue(entityId EntityID) bool {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	_, exist := slf.tickets[entityId]
+	delete(slf.tickets, entityId)
+	return exist
+}
+
+// InQueue 判断实体是否在匹配队列中
+func (slf *RoomMatchmaker[EntityID, RoomID, Entity, Room]) InQueue(entityId EntityID) bool {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	_, exist := slf.tickets[entityId]
+	return exist
+}
+
+// GetQueueLength 获取匹配队列中的实体数量
+func (slf *RoomMatchmaker[EntityID, RoomID, Entity, Room]) GetQueueLength() int {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	return len(slf.tickets)
+}
+
+// Match 进行一轮匹配，返回本轮匹配成功后创建的房间控制器
+//   - 超时的实体将被移出匹配队列并触发匹配超时事件
+//   - 匹配成功的实体将被移出匹配队列，创建房间后触发匹配成功事件
+//   - 加入房间失败的实体将保留原有的入队时间重新放回匹配队列，并触发匹配加入房间失败事件，可在事件中通过 Dequeue 将其移出匹配队列
+func (slf *RoomMatchmaker[EntityID, RoomID, Entity, Room]) Match() []*RoomController[EntityID, RoomID, Entity, Room] {
+	now := time.Now()
+	size := slf.options.teams * slf.options.teamSize
+
+	slf.lock.Lock()
+	var timeout []*roomMatchTicket[EntityID, Entity]
+	var queue = make([]*roomMatchTicket[EntityID, Entity], 0, len(slf.tickets))
+	for id, ticket := range slf.tickets {
+		if slf.options.timeout > 0 && now.Sub(ticket.enqueued) >= slf.options.timeout {
+			timeout = append(timeout, ticket)
+			delete(slf.tickets, id)
+			continue
+		}
+		queue = append(queue, ticket)
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		if queue[i].rating == queue[j].rating {
+			return queue[i].enqueued.Before(queue[j].enqueued)
+		}
+		return queue[i].rating < queue[j].rating
+	})
+	var groups [][]*roomMatchTicket[EntityID, Entity]
+	for i := 0; i+size <= len(queue); {
+		group := queue[i : i+size]
+		if !slf.acceptable(group, now) {
+			i++
+			continue
+		}
+		for _, ticket := range group {
+			delete(slf.tickets, ticket.entity.GetId())
+		}
+		groups = append(groups, group)
+		i += size
+	}
+	slf.lock.Unlock()
+
+	for _, ticket := range timeout {
+		slf.OnRoomMatchTimeoutEvent(ticket.entity, now.Sub(ticket.enqueued))
+	}
+	var controllers = make([]*RoomController[EntityID, RoomID, Entity, Room], 0, len(groups))
+	for _, group := range groups {
+		match := slf.compose(group, now)
+		controller := slf.manager.AssumeControl(slf.roomFactory(match), slf.options.roomOptions...)
+		for t, team := range match.Teams {
+			for i, entity := range team {
+				if err := controller.AddEntity(entity); err != nil {
+					slf.requeue(group, entity.GetId())
+					slf.OnRoomMatchJoinFailedEvent(controller, entity, err)
+					continue
+				}
+				_ = controller.JoinSeat(entity.GetId(), t*slf.options.teamSize+i)
+			}
+		}
+		slf.OnRoomMatchedEvent(controller, match)
+		controllers = append(controllers, controller)
+	}
+	return controllers
+}
+
+// requeue 将匹配成功但加入房间失败的实体以原有的入队时间重新放回匹配队列，当实体已重新加入匹配队列时将保持不变
+func (slf *RoomMatchmaker[EntityID, RoomID, Entity, Room]) requeue(group []*roomMatchTicket[EntityID, Entity], entityId EntityID) {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	if _, exist := slf.tickets[entityId]; exist {
+		return
+	}
+	for _, ticket := range group {
+		if ticket.entity.GetId() == entityId {
+			slf.tickets[entityId] = ticket
+			return
+		}
+	}
+}
+
+// rangeOf 获取实体当前可接受的分数差
+func (slf *RoomMatchmaker[EntityID, RoomID, Entity, Room]) rangeOf(ticket *roomMatchTicket[EntityID, Entity], now time.Time) float64 {
+	r := slf.options.initialRange + 2*ticket.deviation
+	if slf.options.relaxInterval > 0 {
+		r += float64(now.Sub(ticket.enqueued)/slf.options.relaxInterval) * slf.options.relaxStep
+	}
+	if slf.options.maxRange > 0 {
+		r = math.Min(r, math.Max(slf.options.maxRange, slf.options.initialRange))
+	}
+	return r
+}
+
+// acceptable 判断按分数升序排列的实体是否均能接受彼此的分数差
+func (slf *RoomMatchmaker[EntityID, RoomID, Entity, Room]) acceptable(group []*roomMatchTicket[EntityID, Entity], now time.Time) bool {
+	low, high := group[0].rating, group[len(group)-1].rating
+	for _, ticket := range group {
+		if math.Max(ticket.rating-low, high-ticket.rating) > slf.rangeOf(ticket, now) {
+			return false
+		}
+	}
+	return true
+}
+
+// compose 以蛇形顺序将按分数升序排列的实体分配到各个队伍中，使队伍之间的平均分数尽可能接近
+func (slf *RoomMatchmaker[EntityID, RoomID, Entity, Room]) compose(group []*roomMatchTicket[EntityID, Entity], now time.Time) *RoomMatch[EntityID, Entity] {
+	teams := slf.options.teams
+	match := &RoomMatch[EntityID, Entity]{
+		Teams:   make([][]Entity, teams),
+		Ratings: make([][]float64, teams),
+	}
+	for i := len(group) - 1; i >= 0; i-- {
+		ticket := group[i]
+		round, offset := (len(group)-1-i)/teams, (len(group)-1-i)%teams
+		team := offset
+		if round%2 == 1 {
+			team = teams - 1 - offset
+		}
+		match.Teams[team] = append(match.Teams[team], ticket.entity)
+		match.Ratings[team] = append(match.Ratings[team], ticket.rating)
+		if waited := now.Sub(ticket.enqueued); waited > match.Waited {
+			match.Waited = waited
+		}
+	}
+	return match
+}
+
+// RegRoomMatchedEvent 注册匹配成功事件，该事件将在房间创建且匹配的实体加入房间后触发
+func (slf *RoomMatchmaker[EntityID, RoomID, Entity, Room]) RegRoomMatchedEvent(handle RoomMatchedEventHandle[EntityID, RoomID, Entity, Room]) {
+	slf.roomMatchedEventHandles = append(slf.roomMatchedEventHandles, handle)
+}
+
+// OnRoomMatchedEvent 匹配成功事件
+func (slf *RoomMatchmaker[EntityID, RoomID, Entity, Room]) OnRoomMatchedEvent(controller *RoomController[EntityID, RoomID, Entity, Room], match *RoomMatch[EntityID, Entity]) {
+	for _, handle := range slf.roomMatchedEventHandles {
+		handle(controller, match)
+	}
+}
+
+// RegRoomMatchTimeoutEvent 注册匹配超时事件
+func (slf *RoomMatchmaker[EntityID, RoomID, Entity, Room]) RegRoomMatchTimeoutEvent(handle RoomMatchTimeoutEventHandle[EntityID, Entity]) {
+	slf.roomMatchTimeoutEventHandles = append(slf.roomMatchTimeoutEventHandles, handle)
+}
+
+// OnRoomMatchTimeoutEvent 匹配超时事件
+func (slf *RoomMatchmaker[EntityID, RoomID, Entity, Room]) OnRoomMatchTimeoutEvent(entity Entity, waited time.Duration) {
+	for _, handle := range slf.roomMatchTimeoutEventHandles {
+		handle(entity, waited)
+	}
+}
+
+// RegRoomMatchJoinFailedEvent 注册匹配加入房间失败事件，该事件将在匹配成功的实体无法加入房间时触发，此时实体已被重新放回匹配队列
+func (slf *RoomMatchmaker[EntityID, RoomID, Entity, Room]) RegRoomMatchJoinFailedEvent(handle RoomMatchJoinFailedEventHandle[EntityID, RoomID, Entity, Room]) {
+	slf.roomMatchJoinFailedEventHandles = append(slf.roomMatchJoinFailedEventHandles, handle)
+}
+
+// OnRoomMatchJoinFailedEvent 匹配加入房间失败事件
+func (slf *RoomMatchmaker[EntityID, RoomID, Entity, Room]) OnRoomMatchJoinFailedEvent(controller *RoomController[EntityID, RoomID, Entity, Room], entity Entity, err error) {
+	for _, handle := range slf.roomMatchJoinFailedEventHandles {
+		handle(controller, entity, err)
+	}
+}
@@ -0,0 +1,87 @@
+package space
+
+import "time"
+
+// NewRoomMatchmakerOptions 创建房间匹配器选项
+func NewRoomMatchmakerOptions() *RoomMatchmakerOptions {
+	return &RoomMatchmakerOptions{}
+}
+
+// mergeRoomMatchmakerOptions 合并房间匹配器选项
+func mergeRoomMatchmakerOptions(options ...*RoomMatchmakerOptions) *RoomMatchmakerOptions {
+	result := &RoomMatchmakerOptions{
+		teams:        1,
+		teamSize:     1,
+		initialRange: 100,
+	}
+	for _, option := range options {
+		if option.teams > 0 {
+			result.teams = option.teams
+		}
+		if option.teamSize > 0 {
+			result.teamSize = option.teamSize
+		}
+		if option.initialRange > 0 {
+			result.initialRange = option.initialRange
+		}
+		if option.maxRange > 0 {
+			result.maxRange = option.maxRange
+		}
+		if option.relaxInterval > 0 {
+			result.relaxInterval = option.relaxInterval
+			result.relaxStep = option.relaxStep
+		}
+		if option.timeout > 0 {
+			result.timeout = option.timeout
+		}
+		if len(option.roomOptions) > 0 {
+			result.roomOptions = append(result.roomOptions, option.roomOptions...)
+		}
+	}
+	return result
+}
+
+type RoomMatchmakerOptions struct {
+	teams         int                      // 每场匹配的队伍数量
+	teamSize      int                      // 每支队伍的实体数量
+	initialRange  float64                  // 初始可接受的分数差
+	maxRange      float64                  // 放宽后可接受的最大分数差
+	relaxInterval time.Duration            // 放宽分数差的时间间隔
+	relaxStep     float64                  // 每次放宽的分数差
+	timeout       time.Duration            // 匹配超时时间
+	roomOptions   []*RoomControllerOptions // 匹配成功后创建房间所使用的选项
+}
+
+// WithTeams 设置每场匹配的队伍数量及每支队伍的实体数量，默认为 1 支队伍 1 个实体
+//   - 例如 5v5 的匹配应设置为 WithTeams(2, 5)
+func (slf *RoomMatchmakerOptions) WithTeams(teams, teamSize int) *RoomMatchmakerOptions {
+	slf.teams = teams
+	slf.teamSize = teamSize
+	return slf
+}
+
+// WithRatingRange 设置可接受的分数差，默认初始分数差为 100，maxRange 小于等于 0 时不限制放宽后的最大分数差
+func (slf *RoomMatchmakerOptions) WithRatingRange(initialRange, maxRange float64) *RoomMatchmakerOptions {
+	slf.initialRange = initialRange
+	slf.maxRange = maxRange
+	return slf
+}
+
+// WithRelaxation 设置匹配等待时的放宽策略，每等待 interval 时间可接受的分数差将增加 step
+func (slf *RoomMatchmakerOptions) WithRelaxation(interval time.Duration, step float64) *RoomMatchmakerOptions {
+	slf.relaxInterval = interval
+	slf.relaxStep = step
+	return slf
+}
+
+// WithTimeout 设置匹配超时时间，超时的实体将被移出匹配队列并触发匹配超时事件
+func (slf *RoomMatchmakerOptions) WithTimeout(timeout time.Duration) *RoomMatchmakerOptions {
+	slf.timeout = timeout
+	return slf
+}
+
+// WithRoomOptions 设置匹配成功后创建房间所使用的选项
+func (slf *RoomMatchmakerOptions) WithRoomOptions(options ...*RoomControllerOptions) *RoomMatchmakerOptions {
+	slf.roomOptions = append(slf.roomOptions, options...)
+	return slf
+}
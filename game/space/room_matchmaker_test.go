@@ -0,0 +1,138 @@
+package space_test
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/game/space"
+	"testing"
+	"time"
+)
+
+type testEntity struct {
+	id string
+}
+
+func (slf *testEntity) GetId() string {
+	return slf.id
+}
+
+type testRoom struct {
+	id string
+}
+
+func (slf *testRoom) GetId() string {
+	return slf.id
+}
+
+type testRoomManager = space.RoomManager[string, string, *testEntity, *testRoom]
+
+func newTestRoomManager(options ...*space.RoomManagerOptions) *testRoomManager {
+	return space.NewRoomManager[string, string, *testEntity, *testRoom](options...)
+}
+
+func TestRoomMatchmaker_Match(t *testing.T) {
+	manager := newTestRoomManager()
+	var rooms int
+	matchmaker := space.NewRoomMatchmaker(manager, func(match *space.RoomMatch[string, *testEntity]) *testRoom {
+		rooms++
+		return &testRoom{id: "room"}
+	}, space.NewRoomMatchmakerOptions().WithTeams(2, 2).WithRatingRange(100, 0))
+	var matched *space.RoomMatch[string, *testEntity]
+	matchmaker.RegRoomMatchedEvent(func(controller *space.RoomController[string, string, *testEntity, *testRoom], match *space.RoomMatch[string, *testEntity]) {
+		matched = match
+	})
+
+	for id, rating := range map[string]float64{"a": 1000, "b": 1010, "c": 1020, "d": 1030, "e": 2000, "f": 2500} {
+		if err := matchmaker.Enqueue(&testEntity{id: id}, rating, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := matchmaker.Enqueue(&testEntity{id: "a"}, 1000, 0); !errors.Is(err, space.ErrAlreadyInMatchQueue) {
+		t.Fatalf("expected %v, got %v", space.ErrAlreadyInMatchQueue, err)
+	}
+
+	// 分数相近的 4 个实体将被蛇形分配到两支队伍中，分数相差过大的实体将继续等待
+	controllers := matchmaker.Match()
+	if len(controllers) != 1 || rooms != 1 || matched == nil {
+		t.Fatalf("expected 1 match, got %d", len(controllers))
+	}
+	if matched.GetTeamRating(0) != 1015 || matched.GetTeamRating(1) != 1015 {
+		t.Fatalf("expected balanced teams, got %v", matched.Ratings)
+	}
+	for seat, id := range []string{"d", "a", "c", "b"} {
+		if entity := controllers[0].GetSeatEntity(seat); entity == nil || entity.GetId() != id {
+			t.Fatalf("expected %s on seat %d, got %v", id, seat, entity)
+		}
+	}
+	if matchmaker.GetQueueLength() != 2 || !matchmaker.InQueue("e") || !matchmaker.InQueue("f") {
+		t.Fatalf("expected e and f to keep waiting, got %d in queue", matchmaker.GetQueueLength())
+	}
+	if len(matchmaker.Match()) != 0 {
+		t.Fatal("expected entities out of the rating range not to be matched")
+	}
+	if !matchmaker.Dequeue("e") || matchmaker.Dequeue("e") {
+		t.Fatal("expected Dequeue to report whether the entity was in queue")
+	}
+}
+
+func TestRoomMatchmaker_Relaxation(t *testing.T) {
+	manager := newTestRoomManager()
+	matchmaker := space.NewRoomMatchmaker(manager, func(match *space.RoomMatch[string, *testEntity]) *testRoom {
+		return &testRoom{id: "room"}
+	}, space.NewRoomMatchmakerOptions().WithTeams(1, 2).WithRatingRange(100, 300).WithRelaxation(10*time.Millisecond, 1000))
+	_ = matchmaker.Enqueue(&testEntity{id: "a"}, 1000, 0)
+	_ = matchmaker.Enqueue(&testEntity{id: "b"}, 1250, 0)
+	if len(matchmaker.Match()) != 0 {
+		t.Fatal("expected the rating difference to exceed the initial range")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if len(matchmaker.Match()) != 1 {
+		t.Fatal("expected the range to be relaxed up to the max range")
+	}
+}
+
+func TestRoomMatchmaker_Timeout(t *testing.T) {
+	manager := newTestRoomManager()
+	matchmaker := space.NewRoomMatchmaker(manager, func(match *space.RoomMatch[string, *testEntity]) *testRoom {
+		return &testRoom{id: "room"}
+	}, space.NewRoomMatchmakerOptions().WithTeams(1, 2).WithTimeout(10*time.Millisecond))
+	var timeout []string
+	matchmaker.RegRoomMatchTimeoutEvent(func(entity *testEntity, waited time.Duration) {
+		timeout = append(timeout, entity.GetId())
+	})
+	_ = matchmaker.Enqueue(&testEntity{id: "a"}, 1000, 0)
+	time.Sleep(20 * time.Millisecond)
+	matchmaker.Match()
+	if len(timeout) != 1 || timeout[0] != "a" || matchmaker.InQueue("a") {
+		t.Fatalf("expected a to be removed from the queue after timeout, got %v", timeout)
+	}
+}
+
+func TestRoomMatchmaker_JoinFailed(t *testing.T) {
+	manager := newTestRoomManager()
+	var index int
+	matchmaker := space.NewRoomMatchmaker(manager, func(match *space.RoomMatch[string, *testEntity]) *testRoom {
+		index++
+		return &testRoom{id: string(rune('0' + index))}
+	}, space.NewRoomMatchmakerOptions().WithTeams(1, 2).WithRoomOptions(space.NewRoomControllerOptions().WithMaxEntityCount(1)))
+	var failed = make(map[string]error)
+	matchmaker.RegRoomMatchJoinFailedEvent(func(controller *space.RoomController[string, string, *testEntity, *testRoom], entity *testEntity, err error) {
+		if !matchmaker.InQueue(entity.GetId()) {
+			t.Errorf("expected %s to be back in queue when the event is triggered", entity.GetId())
+		}
+		failed[entity.GetId()] = err
+	})
+	_ = matchmaker.Enqueue(&testEntity{id: "a"}, 1000, 0)
+	_ = matchmaker.Enqueue(&testEntity{id: "b"}, 1010, 0)
+
+	// 房间仅能容纳 1 个实体，无法加入房间的实体不应被丢弃，而是重新放回匹配队列
+	controllers := matchmaker.Match()
+	if len(controllers) != 1 || controllers[0].GetEntityCount() != 1 || !controllers[0].HasEntity("b") {
+		t.Fatalf("expected b to join the room")
+	}
+	if err := failed["a"]; !errors.Is(err, space.ErrRoomFull) || len(failed) != 1 {
+		t.Fatalf("expected a to fail with %v, got %v", space.ErrRoomFull, failed)
+	}
+	if !matchmaker.InQueue("a") || matchmaker.GetQueueLength() != 1 {
+		t.Fatal("expected a to be requeued")
+	}
+}
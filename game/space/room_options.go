@@ -1,5 +1,7 @@
 package space
 
+import "time"
+
 // NewRoomControllerOptions 创建房间控制器选项
 func NewRoomControllerOptions() *RoomControllerOptions {
 	return &RoomControllerOptions{}
@@ -12,13 +14,36 @@ func mergeRoomControllerOptions(options ...*RoomControllerOptions) *RoomControll
 		if option.maxEntityCount != nil {
 			result.maxEntityCount = option.maxEntityCount
 		}
+		if option.password != nil {
+			result.password = option.password
+		}
+		if option.kickBanDuration != nil {
+			result.kickBanDuration = option.kickBanDuration
+		}
+		if option.seed != nil {
+			result.seed = option.seed
+		}
+		if option.stateTransitions != nil {
+			result.stateTransitions = option.stateTransitions
+		}
+		for key, value := range option.attrs {
+			if result.attrs == nil {
+				result.attrs = make(map[string]any)
+			}
+			result.attrs[key] = value
+		}
 	}
 	return result
 }
 
 type RoomControllerOptions struct {
-	maxEntityCount *int    // 房间最大实体数量
-	password       *string // 房间密码
+	maxEntityCount  *int           // 房间最大实体数量
+	password        *string        // 房间密码
+	kickBanDuration *time.Duration // 实体被踢出后禁止重新加入房间的时长
+	seed            *int64         // 房间随机数生成器的种子
+
+	stateTransitions map[RoomState][]RoomState // 房间状态允许的转换
+	attrs            map[string]any            // 房间的初始属性
 }
 
 // WithMaxEntityCount 设置房间最大实体数量
@@ -36,3 +61,36 @@ func (slf *RoomControllerOptions) WithPassword(password string) *RoomControllerO
 	}
 	return slf
 }
+
+// WithKickBanDuration 设置实体被踢出房间后禁止重新加入的时长，默认情况下被踢出的实体可以立即重新加入房间
+//   - 当 duration 小于 0 时，被踢出的实体将永久无法加入房间，直到调用 Unban 解除
+func (slf *RoomControllerOptions) WithKickBanDuration(duration time.Duration) *RoomControllerOptions {
+	if duration != 0 {
+		slf.kickBanDuration = &duration
+	}
+	return slf
+}
+
+// WithSeed 设置房间随机数生成器的种子，默认情况下将使用房间被创建时的纳秒时间戳作为种子
+//   - 在帧同步或回放等需要确定性的场景中，可将相同的种子下发给客户端或保存在回放中
+func (slf *RoomControllerOptions) WithSeed(seed int64) *RoomControllerOptions {
+	slf.seed = &seed
+	return slf
+}
+
+// WithStateTransitions 设置房间状态允许的转换，key 为当前状态，value 为允许切换到的状态
+//   - 默认允许的转换为 Waiting -> Ready、Ready -> Waiting、Ready -> Playing、Playing -> Settling、Settling -> Waiting
+//   - 房间的初始状态始终为 RoomStateWaiting
+func (slf *RoomControllerOptions) WithStateTransitions(transitions map[RoomState][]RoomState) *RoomControllerOptions {
+	slf.stateTransitions = transitions
+	return slf
+}
+
+// WithAttr 设置房间的初始属性，初始属性不会触发房间属性改变事件
+func (slf *RoomControllerOptions) WithAttr(key string, value any) *RoomControllerOptions {
+	if slf.attrs == nil {
+		slf.attrs = make(map[string]any)
+	}
+	slf.attrs[key] = value
+	return slf
+}
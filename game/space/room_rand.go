@@ -0,0 +1,142 @@
+package space
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// newRoomRand 创建基于特定种子的房间随机数生成器，并跳过 draws 次抽取
+func newRoomRand(seed int64, draws uint64) *RoomRand {
+	source := &roomRandSource{source: rand.NewSource(seed).(rand.Source64)}
+	for i := uint64(0); i < draws; i++ {
+		source.Int63()
+	}
+	return &RoomRand{seed: seed, source: source, rand: rand.New(source)}
+}
+
+// roomRandSource 记录抽取次数的随机源
+type roomRandSource struct {
+	source rand.Source64
+	draws  uint64 // 从随机源中抽取的次数
+}
+
+func (slf *roomRandSource) Int63() int64 {
+	slf.draws++
+	return slf.source.Int63()
+}
+
+func (slf *roomRandSource) Uint64() uint64 {
+	slf.draws++
+	return slf.source.Uint64()
+}
+
+func (slf *roomRandSource) Seed(int64) {}
+
+// RoomRand 房间的确定性随机数生成器，相同的种子及相同的调用顺序将产生相同的随机数序列
+//   - 房间逻辑中的随机数应当全部通过该生成器获取，以便在帧同步或回放时获得一致的结果
+//   - 种子及已抽取的次数将被保存在房间快照中，通过快照恢复的房间将从相同的位置继续产生随机数
+type RoomRand struct {
+	seed   int64
+	source *roomRandSource
+	rand   *rand.Rand
+	mutex  sync.Mutex
+}
+
+// GetSeed 获取随机数生成器的种子
+func (slf *RoomRand) GetSeed() int64 {
+	return slf.seed
+}
+
+// GetDraws 获取随机数生成器从随机源中抽取的次数
+func (slf *RoomRand) GetDraws() uint64 {
+	slf.mutex.Lock()
+	defer slf.mutex.Unlock()
+	return slf.source.draws
+}
+
+// Int 返回一个介于 min 和 max 之间的 int 类型的随机数
+func (slf *RoomRand) Int(min int, max int) int {
+	return int(slf.Int64(int64(min), int64(max)))
+}
+
+// Int64 返回一个介于 min 和 max 之间的 int64 类型的随机数
+func (slf *RoomRand) Int64(min int64, max int64) int64 {
+	slf.mutex.Lock()
+	defer slf.mutex.Unlock()
+	return min + slf.rand.Int63n(max+1-min)
+}
+
+// IntN 返回一个 0~n 的整数，不包含 n
+func (slf *RoomRand) IntN(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	slf.mutex.Lock()
+	defer slf.mutex.Unlock()
+	return slf.rand.Intn(n)
+}
+
+// Float64 返回一个 0~1 的浮点数
+func (slf *RoomRand) Float64() float64 {
+	slf.mutex.Lock()
+	defer slf.mutex.Unlock()
+	return slf.rand.Float64()
+}
+
+// Bool 返回一个随机的布尔值
+func (slf *RoomRand) Bool() bool {
+	return slf.IntN(2) == 1
+}
+
+// Probability 输入一个概率，返回是否命中
+//   - 当 full 不为空时，将以 full 为基数，p 为分子，计算命中概率，默认 full 为 100
+func (slf *RoomRand) Probability(p int, full ...int) bool {
+	var total = 100
+	if len(full) > 0 && full[0] > 0 {
+		total = full[0]
+	}
+	if p <= 0 {
+		return false
+	} else if p >= total {
+		return true
+	}
+	return slf.Int(1, total) <= p
+}
+
+// Perm 返回 [0, n) 的一个随机排列
+func (slf *RoomRand) Perm(n int) []int {
+	slf.mutex.Lock()
+	defer slf.mutex.Unlock()
+	return slf.rand.Perm(n)
+}
+
+// Shuffle 随机打乱 n 个元素的顺序，swap 用于交换下标为 i 和 j 的元素
+func (slf *RoomRand) Shuffle(n int, swap func(i, j int)) {
+	slf.mutex.Lock()
+	defer slf.mutex.Unlock()
+	slf.rand.Shuffle(n, swap)
+}
+
+// RoomRandChoice 通过房间随机数生成器从切片中随机选择一个元素，当切片为空时将返回零值
+func RoomRandChoice[T any](r *RoomRand, s []T) (result T) {
+	if len(s) == 0 {
+		return
+	}
+	return s[r.IntN(len(s))]
+}
+
+// GetRand 获取房间的确定性随机数生成器
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) GetRand() *RoomRand {
+	return slf.rand
+}
+
+// GetSeed 获取房间随机数生成器的种子
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) GetSeed() int64 {
+	return slf.rand.GetSeed()
+}
+
+// defaultRoomSeed 当未指定房间种子时使用的种子
+func defaultRoomSeed() int64 {
+	return time.Now().UnixNano()
+}
@@ -0,0 +1,72 @@
+package space_test
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/game/space"
+	"testing"
+)
+
+// roomRandSequence 通过房间随机数生成器的各个函数产生一段随机数序列
+func roomRandSequence(r *space.RoomRand) []string {
+	var sequence []string
+	for i := 0; i < 20; i++ {
+		sequence = append(sequence, fmt.Sprint(r.Int(1, 100), r.Int64(-5, 5), r.IntN(7), r.Float64(), r.Bool(), r.Probability(30)))
+	}
+	var items = []int{1, 2, 3, 4, 5, 6, 7, 8}
+	r.Shuffle(len(items), func(i, j int) {
+		items[i], items[j] = items[j], items[i]
+	})
+	sequence = append(sequence, fmt.Sprint(r.Perm(8), items, space.RoomRandChoice(r, items)))
+	return sequence
+}
+
+func TestRoomRand_Seed(t *testing.T) {
+	manager := newTestRoomManager()
+	a := manager.AssumeControl(&testRoom{id: "a"}, space.NewRoomControllerOptions().WithSeed(42))
+	b := manager.AssumeControl(&testRoom{id: "b"}, space.NewRoomControllerOptions().WithSeed(42))
+	c := manager.AssumeControl(&testRoom{id: "c"}, space.NewRoomControllerOptions().WithSeed(7))
+	if a.GetSeed() != 42 || a.GetRand().GetSeed() != 42 {
+		t.Fatalf("expected seed 42, got %d", a.GetSeed())
+	}
+
+	// 相同种子的房间将产生相同的随机数序列，且互不影响
+	sa, sb, sc := roomRandSequence(a.GetRand()), roomRandSequence(b.GetRand()), roomRandSequence(c.GetRand())
+	for i := range sa {
+		if sa[i] != sb[i] {
+			t.Fatalf("expected draw %d to be %s, got %s", i, sa[i], sb[i])
+		}
+	}
+	if fmt.Sprint(sa) == fmt.Sprint(sc) {
+		t.Fatal("expected different seeds to produce different sequences")
+	}
+	if a.GetRand().GetDraws() == 0 || a.GetRand().GetDraws() != b.GetRand().GetDraws() {
+		t.Fatalf("expected equal draws, got %d and %d", a.GetRand().GetDraws(), b.GetRand().GetDraws())
+	}
+}
+
+func TestRoomRand_Range(t *testing.T) {
+	r := newTestRoomManager().AssumeControl(&testRoom{id: "room"}, space.NewRoomControllerOptions().WithSeed(1)).GetRand()
+	for i := 0; i < 1000; i++ {
+		if v := r.Int(3, 5); v < 3 || v > 5 {
+			t.Fatalf("expected Int in [3, 5], got %d", v)
+		}
+		if v := r.IntN(3); v < 0 || v >= 3 {
+			t.Fatalf("expected IntN in [0, 3), got %d", v)
+		}
+		if v := r.Float64(); v < 0 || v >= 1 {
+			t.Fatalf("expected Float64 in [0, 1), got %v", v)
+		}
+	}
+
+	// 边界情况不会从随机源中抽取
+	draws := r.GetDraws()
+	if r.IntN(0) != 0 || r.Probability(0) || !r.Probability(100) || !r.Probability(5, 5) {
+		t.Fatal("unexpected boundary result")
+	}
+	if space.RoomRandChoice[int](r, nil) != 0 {
+		t.Fatal("expected zero value for an empty slice")
+	}
+	if r.GetDraws() != draws {
+		t.Fatalf("expected no draws, got %d", r.GetDraws()-draws)
+	}
+}
@@ -0,0 +1,119 @@
+package space
+
+import (
+	"github.com/kercylan98/minotaur/utils/generic"
+	"github.com/kercylan98/minotaur/utils/hash"
+	"time"
+)
+
+// RoomSnapshot 房间快照，包含房间、实体、观战者、座位、状态及属性等信息，可通过 RoomManager.Restore 恢复房间
+//   - 快照中的房间、实体及属性值需要能够被序列化，快照才能够被持久化或在服务器之间迁移
+type RoomSnapshot[EntityID comparable, RoomID comparable, Entity generic.IdR[EntityID], Room generic.IdR[RoomID]] struct {
+	Room            Room                   `json:"room"`                      // 房间
+	Entities        []Entity               `json:"entities"`                  // 实体
+	Spectators      []Entity               `json:"spectators,omitempty"`      // 观战者
+	Waitlist        []Entity               `json:"waitlist,omitempty"`        // 等待队列中的实体
+	Seats           []*EntityID            `json:"seats,omitempty"`           // 座位上的实体，下标为座位号
+	Vacancy         []int                  `json:"vacancy,omitempty"`         // 空缺的座位
+	State           RoomState              `json:"state"`                     // 房间状态
+	Ready           []EntityID             `json:"ready,omitempty"`           // 已准备的实体
+	Attrs           map[string]any         `json:"attrs,omitempty"`           // 房间属性
+	Bans            map[EntityID]time.Time `json:"bans,omitempty"`            // 被禁止加入房间的实体及解除禁止的时间
+	MaxEntityCount  int                    `json:"maxEntityCount,omitempty"`  // 房间最大实体数量
+	Password        *string                `json:"password,omitempty"`        // 房间密码
+	KickBanDuration time.Duration          `json:"kickBanDuration,omitempty"` // 实体被踢出后禁止重新加入房间的时长
+	Seed            int64                  `json:"seed"`                      // 房间随机数生成器的种子
+	RandDraws       uint64                 `json:"randDraws,omitempty"`       // 房间随机数生成器已抽取的次数
+}
+
+// Snapshot 获取房间的快照
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) Snapshot() *RoomSnapshot[EntityID, RoomID, Entity, Room] {
+	slf.entitiesRWMutex.RLock()
+	snapshot := &RoomSnapshot[EntityID, RoomID, Entity, Room]{
+		Room:       slf.room,
+		Entities:   hash.ToSlice(slf.entities),
+		Spectators: hash.ToSlice(slf.spectators),
+		Waitlist:   append([]Entity(nil), slf.waitlist...),
+		Vacancy:    append([]int(nil), slf.vacancy...),
+		Bans:       hash.Copy(slf.bans),
+	}
+	for _, seat := range slf.seat {
+		if seat != nil {
+			id := *seat
+			seat = &id
+		}
+		snapshot.Seats = append(snapshot.Seats, seat)
+	}
+	if slf.options.maxEntityCount != nil {
+		snapshot.MaxEntityCount = *slf.options.maxEntityCount
+	}
+	if slf.options.password != nil {
+		password := *slf.options.password
+		snapshot.Password = &password
+	}
+	if slf.options.kickBanDuration != nil {
+		snapshot.KickBanDuration = *slf.options.kickBanDuration
+	}
+	slf.entitiesRWMutex.RUnlock()
+
+	slf.stateRWMutex.RLock()
+	snapshot.State = slf.state
+	snapshot.Ready = hash.KeyToSlice(slf.ready)
+	slf.stateRWMutex.RUnlock()
+
+	snapshot.Attrs = slf.GetAttrs()
+	snapshot.Seed, snapshot.RandDraws = slf.rand.GetSeed(), slf.rand.GetDraws()
+	return snapshot
+}
+
+// Restore 通过快照恢复房间并交由 RoomManager 接管，恢复时仅会触发房间接管事件，不会触发实体添加等事件
+//   - options 将在快照中的房间选项之后合并，可用于设置无法通过快照保存的选项，例如 RoomControllerOptions.WithStateTransitions
+//   - 房间随机数生成器将从快照时的位置继续产生随机数，当通过 options 指定了不同的种子时将从新种子的起始位置开始
+//   - 实体与连接的绑定不会被保存在快照中，恢复后需要重新通过 BindConn 进行绑定
+func (slf *RoomManager[EntityID, RoomID, Entity, Room]) Restore(snapshot *RoomSnapshot[EntityID, RoomID, Entity, Room], options ...*RoomControllerOptions) *RoomController[EntityID, RoomID, Entity, Room] {
+	base := NewRoomControllerOptions().WithMaxEntityCount(snapshot.MaxEntityCount).WithKickBanDuration(snapshot.KickBanDuration)
+	if snapshot.Password != nil {
+		base.WithPassword(*snapshot.Password)
+	}
+	for key, value := range snapshot.Attrs {
+		base.WithAttr(key, value)
+	}
+	base.WithSeed(snapshot.Seed)
+	controller := newRoomController(slf, snapshot.Room, mergeRoomControllerOptions(append([]*RoomControllerOptions{base}, options...)...))
+	if controller.rand.GetSeed() == snapshot.Seed {
+		controller.rand = newRoomRand(snapshot.Seed, snapshot.RandDraws)
+	}
+
+	controller.entitiesRWMutex.Lock()
+	for _, entity := range snapshot.Entities {
+		controller.entities[entity.GetId()] = entity
+	}
+	for _, spectator := range snapshot.Spectators {
+		controller.spectators[spectator.GetId()] = spectator
+	}
+	controller.waitlist = append(controller.waitlist, snapshot.Waitlist...)
+	for _, seat := range snapshot.Seats {
+		if seat != nil {
+			id := *seat
+			seat = &id
+		}
+		controller.seat = append(controller.seat, seat)
+	}
+	controller.vacancy = append(controller.vacancy, snapshot.Vacancy...)
+	for id, expire := range snapshot.Bans {
+		controller.bans[id] = expire
+	}
+	controller.entitiesRWMutex.Unlock()
+
+	controller.stateRWMutex.Lock()
+	if snapshot.State != 0 {
+		controller.state = snapshot.State
+	}
+	for _, id := range snapshot.Ready {
+		controller.ready[id] = struct{}{}
+	}
+	controller.stateRWMutex.Unlock()
+
+	slf.assumeControl(controller)
+	return controller
+}
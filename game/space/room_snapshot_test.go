@@ -0,0 +1,89 @@
+package space_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/kercylan98/minotaur/game/space"
+	"testing"
+)
+
+type testRoomSnapshot = space.RoomSnapshot[string, string, *testEntity, *testRoom]
+
+func (slf *testEntity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(slf.id)
+}
+
+func (slf *testEntity) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &slf.id)
+}
+
+func (slf *testRoom) MarshalJSON() ([]byte, error) {
+	return json.Marshal(slf.id)
+}
+
+func (slf *testRoom) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &slf.id)
+}
+
+func TestRoomManager_Restore(t *testing.T) {
+	manager := newTestRoomManager()
+	room := manager.AssumeControl(&testRoom{id: "room"}, space.NewRoomControllerOptions().
+		WithMaxEntityCount(2).
+		WithPassword("secret").
+		WithSeed(42).
+		WithAttr("mode", "ranked"),
+	)
+	_ = room.AddEntityByPassword(&testEntity{id: "a"}, "secret")
+	_ = room.AddEntityByPassword(&testEntity{id: "b"}, "secret")
+	_ = room.AddSpectatorByPassword(&testEntity{id: "s"}, "secret")
+	_, _ = room.JoinWaitlist(&testEntity{id: "w"}, "secret")
+	room.Ban("x", 0)
+	for i := 0; i < 10; i++ {
+		room.GetRand().Int(1, 100)
+	}
+
+	data, err := json.Marshal(room.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snapshot testRoomSnapshot
+	if err = json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	restored := newTestRoomManager().Restore(&snapshot)
+
+	if restored.GetRoom().GetId() != "room" || !restored.HasEntity("a") || !restored.HasEntity("b") || !restored.HasSpectator("s") {
+		t.Fatal("expected the room, entities and spectators to be restored")
+	}
+	if restored.GetWaitlistPosition("w") != 1 || !restored.IsBanned("x") || restored.GetAttr("mode") != "ranked" {
+		t.Fatal("expected the waitlist, bans and attrs to be restored")
+	}
+	if err = restored.AddEntityByPassword(&testEntity{id: "c"}, "secret"); !errors.Is(err, space.ErrRoomFull) {
+		t.Fatalf("expected %v, got %v", space.ErrRoomFull, err)
+	}
+
+	// 恢复后的随机数生成器将从快照时的位置继续产生随机数
+	if restored.GetSeed() != 42 {
+		t.Fatalf("expected seed 42, got %d", restored.GetSeed())
+	}
+	for i := 0; i < 10; i++ {
+		if expected, actual := room.GetRand().Int(1, 100), restored.GetRand().Int(1, 100); expected != actual {
+			t.Fatalf("expected draw %d to be %d, got %d", i, expected, actual)
+		}
+	}
+}
+
+func TestRoomManager_RestoreWithSeed(t *testing.T) {
+	manager := newTestRoomManager()
+	room := manager.AssumeControl(&testRoom{id: "room"}, space.NewRoomControllerOptions().WithSeed(42))
+	room.GetRand().Int(1, 100)
+
+	// 通过选项指定不同的种子时将从新种子的起始位置开始
+	restored := newTestRoomManager().Restore(room.Snapshot(), space.NewRoomControllerOptions().WithSeed(7))
+	expected := newTestRoomManager().AssumeControl(&testRoom{id: "expected"}, space.NewRoomControllerOptions().WithSeed(7))
+	for i := 0; i < 10; i++ {
+		if e, a := expected.GetRand().Int(1, 100), restored.GetRand().Int(1, 100); e != a {
+			t.Fatalf("expected draw %d to be %d, got %d", i, e, a)
+		}
+	}
+}
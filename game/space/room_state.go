@@ -0,0 +1,167 @@
+package space
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/hash"
+	"github.com/kercylan98/minotaur/utils/slice"
+)
+
+const (
+	RoomStateWaiting  RoomState = iota + 1 // 等待中，房间创建后的初始状态
+	RoomStateReady                         // 准备就绪，房间内所有实体均已准备
+	RoomStatePlaying                       // 游戏中
+	RoomStateSettling                      // 结算中
+)
+
+var roomStateNames = map[RoomState]string{
+	RoomStateWaiting:  "Waiting",
+	RoomStateReady:    "Ready",
+	RoomStatePlaying:  "Playing",
+	RoomStateSettling: "Settling",
+}
+
+// defaultRoomStateTransitions 默认允许的房间状态转换
+var defaultRoomStateTransitions = map[RoomState][]RoomState{
+	RoomStateWaiting:  {RoomStateReady},
+	RoomStateReady:    {RoomStateWaiting, RoomStatePlaying},
+	RoomStatePlaying:  {RoomStateSettling},
+	RoomStateSettling: {RoomStateWaiting},
+}
+
+// RoomState 房间生命周期状态，除内置状态外可自定义状态，并通过 RoomControllerOptions.WithStateTransitions 配置状态之间的转换
+type RoomState int
+
+// String 返回房间状态的字符串表示
+func (slf RoomState) String() string {
+	if name, exist := roomStateNames[slf]; exist {
+		return name
+	}
+	return fmt.Sprintf("RoomState(%d)", int(slf))
+}
+
+// GetState 获取房间当前的状态
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) GetState() RoomState {
+	slf.stateRWMutex.RLock()
+	defer slf.stateRWMutex.RUnlock()
+	return slf.state
+}
+
+// ChangeState 将房间切换到新的状态，切换成功后将触发房间状态改变事件
+//   - 当前状态不允许切换到目标状态时将返回 ErrIllegalRoomStateTransition
+//   - 当通过 RoomManager.RegRoomStateGuard 注册的守卫返回错误时，将不会切换状态并返回该错误
+//   - 进入 RoomStatePlaying 状态时将清除所有实体的准备状态
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) ChangeState(state RoomState) error {
+	slf.stateRWMutex.RLock()
+	from := slf.state
+	slf.stateRWMutex.RUnlock()
+	if !slf.allowState(from, state) {
+		return ErrIllegalRoomStateTransition
+	}
+	if err := slf.manager.OnRoomStateGuard(slf, from, state); err != nil {
+		return err
+	}
+
+	slf.stateRWMutex.Lock()
+	if slf.state != from {
+		slf.stateRWMutex.Unlock()
+		return ErrIllegalRoomStateTransition
+	}
+	slf.state = state
+	if state == RoomStatePlaying {
+		slf.ready = make(map[EntityID]struct{})
+	}
+	slf.stateRWMutex.Unlock()
+
+	slf.manager.OnRoomStateChangeEvent(slf, from, state)
+	return nil
+}
+
+// allowState 检查房间状态是否允许从 from 切换到 to
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) allowState(from, to RoomState) bool {
+	transitions := slf.options.stateTransitions
+	if transitions == nil {
+		transitions = defaultRoomStateTransitions
+	}
+	return slice.Contains(transitions[from], to)
+}
+
+// SetReady 设置实体的准备状态，设置成功后将触发实体准备状态改变事件
+//   - 当实体不在房间中时将返回 ErrNotInRoom
+//   - 当房间处于 RoomStateWaiting 状态且所有实体均已准备时，将自动切换到 RoomStateReady 状态
+//   - 当房间处于 RoomStateReady 状态且有实体取消准备时，将自动切换到 RoomStateWaiting 状态
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) SetReady(entityId EntityID, ready bool) error {
+	slf.entitiesRWMutex.RLock()
+	entity, exist := slf.entities[entityId]
+	ids := hash.KeyToSlice(slf.entities)
+	slf.entitiesRWMutex.RUnlock()
+	if !exist {
+		return ErrNotInRoom
+	}
+
+	slf.stateRWMutex.Lock()
+	_, before := slf.ready[entityId]
+	if ready {
+		slf.ready[entityId] = struct{}{}
+	} else {
+		delete(slf.ready, entityId)
+	}
+	allReady, state := slf.isAllReady(ids), slf.state
+	slf.stateRWMutex.Unlock()
+
+	if before != ready {
+		slf.manager.OnRoomEntityReadyChangeEvent(slf, entity, ready)
+	}
+	switch {
+	case allReady && state == RoomStateWaiting:
+		_ = slf.ChangeState(RoomStateReady)
+	case !allReady && state == RoomStateReady:
+		_ = slf.ChangeState(RoomStateWaiting)
+	}
+	return nil
+}
+
+// IsReady 检查实体是否已准备
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) IsReady(entityId EntityID) bool {
+	slf.stateRWMutex.RLock()
+	defer slf.stateRWMutex.RUnlock()
+	_, exist := slf.ready[entityId]
+	return exist
+}
+
+// IsAllReady 检查房间内的所有实体是否均已准备，当房间内没有实体时将返回 false
+//   - 观战者不参与准备状态的检查
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) IsAllReady() bool {
+	slf.entitiesRWMutex.RLock()
+	ids := hash.KeyToSlice(slf.entities)
+	slf.entitiesRWMutex.RUnlock()
+	slf.stateRWMutex.RLock()
+	defer slf.stateRWMutex.RUnlock()
+	return slf.isAllReady(ids)
+}
+
+// isAllReady 检查特定实体是否均已准备（无锁）
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) isAllReady(ids []EntityID) bool {
+	if len(ids) == 0 {
+		return false
+	}
+	for _, id := range ids {
+		if _, exist := slf.ready[id]; !exist {
+			return false
+		}
+	}
+	return true
+}
+
+// GetReadyEntityCount 获取已准备的实体数量
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) GetReadyEntityCount() int {
+	slf.stateRWMutex.RLock()
+	defer slf.stateRWMutex.RUnlock()
+	return len(slf.ready)
+}
+
+// clearReady 清除实体的准备状态
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) clearReady(entityId EntityID) {
+	slf.stateRWMutex.Lock()
+	defer slf.stateRWMutex.Unlock()
+	delete(slf.ready, entityId)
+}
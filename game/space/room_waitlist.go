@@ -0,0 +1,92 @@
+package space
+
+// JoinWaitlist 当房间已满时将实体加入等待队列，当房间有空位时等待队列中的实体将按照顺序自动加入房间，并触发房间等待队列准入事件
+//   - 当房间未满时实体将直接加入房间，此时 position 为 0
+//   - position 为实体在等待队列中的位置，从 1 开始
+//   - 当房间设置了密码时需要提供正确的密码
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) JoinWaitlist(entity Entity, password ...string) (position int, err error) {
+	if slf.options.password != nil && (len(password) == 0 || *slf.options.password != password[0]) {
+		return 0, ErrRoomPasswordNotMatch
+	}
+	slf.entitiesRWMutex.Lock()
+	defer slf.entitiesRWMutex.Unlock()
+
+	id := entity.GetId()
+	if _, exist := slf.entities[id]; exist {
+		return 0, ErrAlreadyInRoom
+	}
+	if slf.isBanned(id) {
+		return 0, ErrBanned
+	}
+	if position = slf.waitlistPosition(id); position > 0 {
+		return position, ErrAlreadyInWaitlist
+	}
+	if !slf.isFull() {
+		slf.addEntity(entity)
+		return 0, nil
+	}
+	slf.waitlist = append(slf.waitlist, entity)
+	return len(slf.waitlist), nil
+}
+
+// LeaveWaitlist 将实体移出等待队列，返回实体是否在等待队列中
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) LeaveWaitlist(id EntityID) bool {
+	slf.entitiesRWMutex.Lock()
+	defer slf.entitiesRWMutex.Unlock()
+	return slf.leaveWaitlist(id)
+}
+
+// leaveWaitlist 将实体移出等待队列（无锁）
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) leaveWaitlist(id EntityID) bool {
+	position := slf.waitlistPosition(id)
+	if position == 0 {
+		return false
+	}
+	slf.waitlist = append(slf.waitlist[:position-1], slf.waitlist[position:]...)
+	return true
+}
+
+// GetWaitlistPosition 获取实体在等待队列中的位置，从 1 开始，当实体不在等待队列中时将返回 0
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) GetWaitlistPosition(id EntityID) int {
+	slf.entitiesRWMutex.RLock()
+	defer slf.entitiesRWMutex.RUnlock()
+	return slf.waitlistPosition(id)
+}
+
+// waitlistPosition 获取实体在等待队列中的位置（无锁）
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) waitlistPosition(id EntityID) int {
+	for i, entity := range slf.waitlist {
+		if entity.GetId() == id {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// GetWaitlist 获取等待队列中的所有实体
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) GetWaitlist() []Entity {
+	slf.entitiesRWMutex.RLock()
+	defer slf.entitiesRWMutex.RUnlock()
+	return append([]Entity(nil), slf.waitlist...)
+}
+
+// GetWaitlistLength 获取等待队列的长度
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) GetWaitlistLength() int {
+	slf.entitiesRWMutex.RLock()
+	defer slf.entitiesRWMutex.RUnlock()
+	return len(slf.waitlist)
+}
+
+// admitWaitlist 在房间有空位时按照顺序将等待队列中的实体加入房间（无锁）
+//   - 在等待期间被禁止加入房间的实体将被移出等待队列
+func (slf *RoomController[EntityID, RoomID, Entity, Room]) admitWaitlist() {
+	for len(slf.waitlist) > 0 && !slf.isFull() {
+		entity := slf.waitlist[0]
+		slf.waitlist = slf.waitlist[1:]
+		if slf.isBanned(entity.GetId()) {
+			continue
+		}
+		slf.addEntity(entity)
+		slf.manager.OnRoomWaitlistAdmittedEvent(slf, entity)
+	}
+}
@@ -0,0 +1,67 @@
+package space_test
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/game/space"
+	"testing"
+)
+
+func TestRoomController_JoinWaitlist(t *testing.T) {
+	manager := newTestRoomManager()
+	var admitted []string
+	manager.RegRoomWaitlistAdmittedEvent(func(controller *testRoomController, entity *testEntity) {
+		admitted = append(admitted, entity.GetId())
+	})
+	room := manager.AssumeControl(&testRoom{id: "room"}, space.NewRoomControllerOptions().WithMaxEntityCount(1))
+
+	// 房间未满时直接加入房间，已满时按照顺序进入等待队列
+	for i, id := range []string{"a", "b", "c", "d"} {
+		position, err := room.JoinWaitlist(&testEntity{id: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if position != i {
+			t.Fatalf("expected %s at position %d, got %d", id, i, position)
+		}
+	}
+	if position, err := room.JoinWaitlist(&testEntity{id: "c"}); !errors.Is(err, space.ErrAlreadyInWaitlist) || position != 2 {
+		t.Fatalf("expected %v at position 2, got %v at position %d", space.ErrAlreadyInWaitlist, err, position)
+	}
+
+	// 移除实体后等待队列中的第一个实体将加入房间
+	room.RemoveEntity("a")
+	if !room.HasEntity("b") || room.GetWaitlistPosition("c") != 1 || room.GetWaitlistPosition("d") != 2 {
+		t.Fatalf("expected b to be admitted, got waitlist %v", room.GetWaitlist())
+	}
+
+	// 在等待期间被禁止的实体将被跳过
+	room.Ban("c", 0)
+	if err := room.Kick("b", "afk"); err != nil {
+		t.Fatal(err)
+	}
+	if !room.HasEntity("d") || room.HasEntity("c") || room.GetWaitlistLength() != 0 {
+		t.Fatalf("expected d to be admitted and c to be skipped, got waitlist %v", room.GetWaitlist())
+	}
+	if len(admitted) != 2 || admitted[0] != "b" || admitted[1] != "d" {
+		t.Fatalf("expected admitted [b d], got %v", admitted)
+	}
+}
+
+func TestRoomController_LeaveWaitlist(t *testing.T) {
+	manager := newTestRoomManager()
+	room := manager.AssumeControl(&testRoom{id: "room"}, space.NewRoomControllerOptions().WithMaxEntityCount(1))
+	_, _ = room.JoinWaitlist(&testEntity{id: "a"})
+	_, _ = room.JoinWaitlist(&testEntity{id: "b"})
+	_, _ = room.JoinWaitlist(&testEntity{id: "c"})
+
+	if !room.LeaveWaitlist("b") || room.LeaveWaitlist("b") {
+		t.Fatal("expected b to leave the waitlist exactly once")
+	}
+	if position := room.GetWaitlistPosition("c"); position != 1 {
+		t.Fatalf("expected c at position 1, got %d", position)
+	}
+	room.RemoveEntity("a")
+	if !room.HasEntity("c") || room.HasEntity("b") {
+		t.Fatal("expected c to be admitted")
+	}
+}
@@ -0,0 +1,394 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"github.com/kercylan98/minotaur/utils/log"
+	"io"
+	"net/http"
+	"net/http/pprof"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RuntimeStats 服务器运行时状态
+type RuntimeStats struct {
+	Goroutines    int            `json:"goroutines"`     // 协程数量
+	NumCPU        int            `json:"num_cpu"`        // 逻辑 CPU 数量
+	HeapAlloc     uint64         `json:"heap_alloc"`     // 堆上已分配对象的字节数
+	HeapSys       uint64         `json:"heap_sys"`       // 从操作系统获取的堆内存字节数
+	HeapObjects   uint64         `json:"heap_objects"`   // 堆上已分配的对象数量
+	NumGC         uint32         `json:"num_gc"`         // 已完成的 GC 次数
+	PauseTotal    time.Duration  `json:"pause_total_ns"` // GC 累计暂停时长
+	LastGC        time.Time      `json:"last_gc"`        // 最近一次 GC 完成的时间
+	OnlineCount   int            `json:"online_count"`   // 在线连接数量
+	MessageCount  int64          `json:"message_count"`  // 等待处理及正在处理的消息数量
+	DispatchQueue map[string]int `json:"dispatch_queue"` // 各消息分发器中等待处理的消息数量
+}
+
+// GetRuntimeStats 获取服务器当前的运行时状态
+//   - 该函数将触发 runtime.ReadMemStats，短暂地暂停所有协程，不宜频繁调用
+func (slf *Server) GetRuntimeStats() RuntimeStats {
+	var mem goruntime.MemStats
+	goruntime.ReadMemStats(&mem)
+	stats := RuntimeStats{
+		Goroutines:    goruntime.NumGoroutine(),
+		NumCPU:        goruntime.NumCPU(),
+		HeapAlloc:     mem.HeapAlloc,
+		HeapSys:       mem.HeapSys,
+		HeapObjects:   mem.HeapObjects,
+		NumGC:         mem.NumGC,
+		PauseTotal:    time.Duration(mem.PauseTotalNs),
+		OnlineCount:   slf.GetOnlineCount(),
+		MessageCount:  slf.GetMessageCount(),
+		DispatchQueue: map[string]int{},
+	}
+	if mem.LastGC > 0 {
+		stats.LastGC = time.Unix(0, int64(mem.LastGC))
+	}
+	if slf.systemDispatcher != nil {
+		stats.DispatchQueue[slf.systemDispatcher.name] = slf.systemDispatcher.len()
+	}
+	if slf.workerDispatcher != nil {
+		stats.DispatchQueue[slf.workerDispatcher.name] = slf.workerDispatcher.len()
+	}
+	for _, d := range slf.shardDispatchers {
+		stats.DispatchQueue[d.name] = d.len()
+	}
+	slf.dispatcherLock.RLock()
+	for name, d := range slf.dispatchers {
+		stats.DispatchQueue[name] = d.len()
+	}
+	slf.dispatcherLock.RUnlock()
+	return stats
+}
+
+// newAdminMux 创建管理服务的路由
+func (slf *Server) newAdminMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/stats", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(writer).Encode(slf.GetRuntimeStats()); err != nil {
+			slf.Logger().Error("Server", log.String("Admin", request.URL.Path), log.Err(err))
+		}
+	})
+	if slf.metrics != nil {
+		mux.Handle(DefaultMetricsPattern, slf.MetricsHandler())
+	}
+	if slf.messageExecStats != nil {
+		mux.HandleFunc("/debug/messages", func(writer http.ResponseWriter, request *http.Request) {
+			adminResponse(writer, http.StatusOK, slf.Stats(request.Method == http.MethodDelete))
+		})
+	}
+	if len(slf.adminToken) == 0 {
+		slf.Logger().Warn("Server", log.String("Admin", "management api is disabled, use WithAdminToken to enable it"))
+		return mux
+	}
+	mux.HandleFunc("/admin/online", slf.adminOnline)
+	mux.HandleFunc("/admin/conns", slf.adminConns)
+	mux.HandleFunc("/admin/kick", slf.adminKick)
+	mux.HandleFunc("/admin/broadcast", slf.adminBroadcast)
+	mux.HandleFunc("/admin/log/level", slf.adminLogLevel)
+	mux.HandleFunc("/admin/log/packet", slf.adminPacketLog)
+	mux.HandleFunc("/admin/drain", slf.adminDrain)
+	mux.HandleFunc("/admin/maintenance", slf.adminMaintenance)
+	mux.HandleFunc("/admin/shutdown", slf.adminShutdown)
+	mux.HandleFunc("/admin/restart", slf.adminRestart)
+	return mux
+}
+
+// adminAuth 对管理服务的请求进行鉴权，支持 "Authorization: Bearer {token}" 请求头或 token 查询参数
+func (slf *Server) adminAuth(handler http.Handler) http.Handler {
+	if len(slf.adminToken) == 0 {
+		return handler
+	}
+	var expected = []byte(slf.adminToken)
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		token := strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ")
+		if len(token) == 0 {
+			token = request.URL.Query().Get("token")
+		}
+		if subtle.ConstantTimeCompare([]byte(token), expected) != 1 {
+			adminResponse(writer, http.StatusUnauthorized, adminError("unauthorized"))
+			return
+		}
+		handler.ServeHTTP(writer, request)
+	})
+}
+
+// adminConn 管理服务中的连接信息
+type adminConn struct {
+	ID         string        `json:"id"`
+	IP         string        `json:"ip"`
+	Network    Network       `json:"network"`
+	OpenTime   time.Time     `json:"open_time"`
+	OnlineTime time.Duration `json:"online_time_ns"`
+	Bot        bool          `json:"bot"`
+}
+
+// adminError 管理服务中的错误信息
+type adminError string
+
+func (slf adminError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"error": string(slf)})
+}
+
+// adminResponse 以 JSON 格式写入管理服务的响应
+func adminResponse(writer http.ResponseWriter, status int, value any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	if err := json.NewEncoder(writer).Encode(value); err != nil {
+		log.Error("Server", log.String("Admin", "response"), log.Err(err))
+	}
+}
+
+// adminMethod 检查请求方法，不匹配时将写入 405 响应并返回 false
+func adminMethod(writer http.ResponseWriter, request *http.Request, method string) bool {
+	if request.Method != method {
+		adminResponse(writer, http.StatusMethodNotAllowed, adminError("method not allowed"))
+		return false
+	}
+	return true
+}
+
+// adminOnline GET /admin/online 获取在线连接数量
+func (slf *Server) adminOnline(writer http.ResponseWriter, request *http.Request) {
+	if !adminMethod(writer, request, http.MethodGet) {
+		return
+	}
+	adminResponse(writer, http.StatusOK, map[string]int{
+		"online": slf.GetOnlineCount(),
+		"bot":    slf.GetOnlineBotCount(),
+	})
+}
+
+// adminConns GET /admin/conns[?id=] 获取所有在线连接或特定连接的信息
+func (slf *Server) adminConns(writer http.ResponseWriter, request *http.Request) {
+	if !adminMethod(writer, request, http.MethodGet) {
+		return
+	}
+	var info = func(conn *Conn) adminConn {
+		return adminConn{
+			ID:         conn.GetID(),
+			IP:         conn.GetIP(),
+			Network:    conn.GetNetwork(),
+			OpenTime:   conn.GetOpenTime(),
+			OnlineTime: conn.GetOnlineTime(),
+			Bot:        conn.IsBot(),
+		}
+	}
+	if id := request.URL.Query().Get("id"); len(id) > 0 {
+		conn, exist := slf.GetConn(id)
+		if !exist {
+			adminResponse(writer, http.StatusNotFound, adminError("connection not found"))
+			return
+		}
+		adminResponse(writer, http.StatusOK, info(conn))
+		return
+	}
+	var conns = make([]adminConn, 0, slf.GetOnlineCount())
+	slf.RangeConn(func(conn *Conn) bool {
+		conns = append(conns, info(conn))
+		return true
+	})
+	adminResponse(writer, http.StatusOK, conns)
+}
+
+// adminKick POST /admin/kick?id=&code= 踢出特定连接，请求体将作为最后一个数据包发送
+func (slf *Server) adminKick(writer http.ResponseWriter, request *http.Request) {
+	if !adminMethod(writer, request, http.MethodPost) {
+		return
+	}
+	conn, exist := slf.GetConn(request.URL.Query().Get("id"))
+	if !exist {
+		adminResponse(writer, http.StatusNotFound, adminError("connection not found"))
+		return
+	}
+	code, _ := strconv.Atoi(request.URL.Query().Get("code"))
+	payload, err := io.ReadAll(request.Body)
+	if err != nil {
+		adminResponse(writer, http.StatusBadRequest, adminError(err.Error()))
+		return
+	}
+	slf.PushSystemMessage(func() {
+		conn.Kick(code, payload)
+	}, log.String("Admin", "kick"), log.String("conn", conn.GetID()))
+	adminResponse(writer, http.StatusOK, map[string]string{"id": conn.GetID()})
+}
+
+// adminBroadcast POST /admin/broadcast 将请求体作为数据包广播给所有在线连接
+func (slf *Server) adminBroadcast(writer http.ResponseWriter, request *http.Request) {
+	if !adminMethod(writer, request, http.MethodPost) {
+		return
+	}
+	packet, err := io.ReadAll(request.Body)
+	if err != nil || len(packet) == 0 {
+		adminResponse(writer, http.StatusBadRequest, adminError("empty packet"))
+		return
+	}
+	slf.Broadcast(packet)
+	adminResponse(writer, http.StatusOK, map[string]int{"online": slf.GetOnlineCount()})
+}
+
+// adminLogLevel GET /admin/log/level 获取日志级别，POST /admin/log/level?level= 调整日志级别
+func (slf *Server) adminLogLevel(writer http.ResponseWriter, request *http.Request) {
+	switch request.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		level, err := log.ParseLevel(request.URL.Query().Get("level"))
+		if err != nil {
+			adminResponse(writer, http.StatusBadRequest, adminError(err.Error()))
+			return
+		}
+		if !log.SetLevel(level) {
+			adminResponse(writer, http.StatusNotImplemented, adminError("the logger does not support changing level"))
+			return
+		}
+		slf.Logger().Info("Server", log.String("Admin", "log level"), log.String("level", level.String()))
+	default:
+		adminMethod(writer, request, http.MethodPost)
+		return
+	}
+	level, ok := log.GetLevel()
+	if !ok {
+		adminResponse(writer, http.StatusNotImplemented, adminError("the logger does not support changing level"))
+		return
+	}
+	adminResponse(writer, http.StatusOK, map[string]string{"level": level.String()})
+}
+
+// adminPacketLog GET /admin/log/packet 获取数据包日志的开启状态
+//   - POST /admin/log/packet?enable= 开启或关闭数据包日志，enable 默认为 true
+//   - POST /admin/log/packet?id=&enable= 设置是否记录指定连接的数据包日志
+func (slf *Server) adminPacketLog(writer http.ResponseWriter, request *http.Request) {
+	if slf.packetLogging == nil {
+		adminResponse(writer, http.StatusNotImplemented, adminError("packet logging is disabled, use WithPacketLogging to enable it"))
+		return
+	}
+	switch request.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		var enable = true
+		if value := request.URL.Query().Get("enable"); len(value) > 0 {
+			var err error
+			if enable, err = strconv.ParseBool(value); err != nil {
+				adminResponse(writer, http.StatusBadRequest, adminError(err.Error()))
+				return
+			}
+		}
+		if id := request.URL.Query().Get("id"); len(id) > 0 {
+			conn, exist := slf.GetConn(id)
+			if !exist {
+				adminResponse(writer, http.StatusNotFound, adminError("connection not found"))
+				return
+			}
+			conn.SetPacketLogging(enable)
+			slf.Logger().Info("Server", log.String("Admin", "packet log"), log.String("ConnID", id), log.Bool("enable", enable))
+			adminResponse(writer, http.StatusOK, map[string]any{"id": id, "enable": enable})
+			return
+		}
+		slf.SetPacketLogging(enable)
+		slf.Logger().Info("Server", log.String("Admin", "packet log"), log.Bool("enable", enable))
+	default:
+		adminMethod(writer, request, http.MethodPost)
+		return
+	}
+	adminResponse(writer, http.StatusOK, map[string]bool{"enable": slf.IsPacketLogging()})
+}
+
+// adminDrain POST /admin/drain?enable= 设置服务器是否处于排空状态，enable 默认为 true
+func (slf *Server) adminDrain(writer http.ResponseWriter, request *http.Request) {
+	if !adminMethod(writer, request, http.MethodPost) {
+		return
+	}
+	var draining = true
+	if enable := request.URL.Query().Get("enable"); len(enable) > 0 {
+		var err error
+		if draining, err = strconv.ParseBool(enable); err != nil {
+			adminResponse(writer, http.StatusBadRequest, adminError(err.Error()))
+			return
+		}
+	}
+	slf.SetDraining(draining)
+	slf.Logger().Info("Server", log.String("Admin", "drain"), log.Bool("draining", draining))
+	adminResponse(writer, http.StatusOK, map[string]any{"draining": draining, "online": slf.GetOnlineCount()})
+}
+
+// adminMaintenance POST /admin/maintenance[?enable=false] 进入或退出维护模式，进入时将使用 WithMaintenance 设置的默认白名单
+func (slf *Server) adminMaintenance(writer http.ResponseWriter, request *http.Request) {
+	if !adminMethod(writer, request, http.MethodPost) {
+		return
+	}
+	var enable = true
+	if value := request.URL.Query().Get("enable"); len(value) > 0 {
+		var err error
+		if enable, err = strconv.ParseBool(value); err != nil {
+			adminResponse(writer, http.StatusBadRequest, adminError(err.Error()))
+			return
+		}
+	}
+	if enable {
+		slf.EnterMaintenance(nil)
+	} else {
+		slf.ExitMaintenance()
+	}
+	slf.Logger().Info("Server", log.String("Admin", "maintenance"), log.Bool("maintenance", enable))
+	adminResponse(writer, http.StatusOK, map[string]any{"maintenance": enable, "online": slf.GetOnlineCount()})
+}
+
+// adminShutdown POST /admin/shutdown 在响应后关闭服务器
+func (slf *Server) adminShutdown(writer http.ResponseWriter, request *http.Request) {
+	if !adminMethod(writer, request, http.MethodPost) {
+		return
+	}
+	adminResponse(writer, http.StatusOK, map[string]bool{"shutdown": true})
+	slf.Logger().Info("Server", log.String("Admin", "shutdown"))
+	go slf.Shutdown()
+}
+
+// adminRestart POST /admin/restart 热重启服务器，返回新进程的 pid
+func (slf *Server) adminRestart(writer http.ResponseWriter, request *http.Request) {
+	if !adminMethod(writer, request, http.MethodPost) {
+		return
+	}
+	pid, err := slf.Restart()
+	if err != nil {
+		adminResponse(writer, http.StatusBadRequest, adminError(err.Error()))
+		return
+	}
+	slf.Logger().Info("Server", log.String("Admin", "restart"), log.Int("pid", pid))
+	adminResponse(writer, http.StatusOK, map[string]int{"pid": pid})
+}
+
+// startAdmin 在通过 WithAdmin 设置的地址上启动管理服务
+func (slf *Server) startAdmin() error {
+	if len(slf.adminAddr) == 0 {
+		return nil
+	}
+	listener, err := slf.listenTCP("admin", slf.adminAddr)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: slf.adminAuth(slf.newAdminMux())}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slf.Logger().Error("Server", log.String("Admin", slf.adminAddr), log.Err(err))
+		}
+	}()
+	slf.listenerClosers = append(slf.listenerClosers, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		return server.Shutdown(ctx)
+	})
+	slf.Logger().Info("Server", log.String("Admin", listener.Addr().String()))
+	return nil
+}
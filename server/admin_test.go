@@ -0,0 +1,81 @@
+package server_test
+
+import (
+	"encoding/json"
+	"github.com/kercylan98/minotaur/server"
+	"net/http"
+	"testing"
+)
+
+func TestServer_WithAdmin(t *testing.T) {
+	var addr = freeAddr(t, "tcp")
+	srv := server.New(server.NetworkNone, server.WithAdmin(addr), server.WithMetrics())
+	runServer(t, srv, "")
+	defer srv.Shutdown()
+
+	response, err := http.Get("http://" + addr + "/debug/stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stats server.RuntimeStats
+	err = json.NewDecoder(response.Body).Decode(&stats)
+	_ = response.Body.Close()
+	if err != nil || stats.Goroutines == 0 {
+		t.Fatalf("unexpected stats: %+v %v", stats, err)
+	}
+	if _, exist := stats.DispatchQueue["system"]; !exist {
+		t.Fatalf("system dispatcher not found: %v", stats.DispatchQueue)
+	}
+
+	for _, path := range []string{"/debug/pprof/", "/metrics"} {
+		response, err = http.Get("http://" + addr + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("%s responded %d", path, response.StatusCode)
+		}
+	}
+}
+
+func TestServer_WithAdminToken(t *testing.T) {
+	var addr = freeAddr(t, "tcp")
+	srv := server.New(server.NetworkNone, server.WithAdmin(addr), server.WithAdminToken("secret"))
+	runServer(t, srv, "")
+	defer srv.Shutdown()
+
+	var do = func(method, path, token string) int {
+		request, _ := http.NewRequest(method, "http://"+addr+path, nil)
+		if len(token) > 0 {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = response.Body.Close()
+		return response.StatusCode
+	}
+	if status := do(http.MethodGet, "/admin/online", ""); status != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", status)
+	}
+	if status := do(http.MethodGet, "/admin/online", "secret"); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if status := do(http.MethodPost, "/admin/kick?id=unknown", "secret"); status != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", status)
+	}
+	if status := do(http.MethodPost, "/admin/drain", "secret"); status != http.StatusOK || !srv.IsDraining() {
+		t.Fatalf("expected draining, got %d %v", status, srv.IsDraining())
+	}
+	if status := do(http.MethodPost, "/admin/log/level?level=info", "secret"); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if status := do(http.MethodPost, "/admin/log/level?level=debug", "secret"); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if status := do(http.MethodPost, "/admin/log/packet", "secret"); status != http.StatusNotImplemented {
+		t.Fatalf("expected 501, got %d", status)
+	}
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"time"
+)
+
+const (
+	connAuthPending int32 = iota // 等待鉴权
+	connAuthPassed               // 鉴权通过
+	connAuthFailed               // 鉴权失败
+)
+
+// AuthHandler 连接鉴权函数，firstPacket 为连接接收到的首个数据包，返回 nil 时表示鉴权通过
+type AuthHandler func(conn *Conn, firstPacket []byte) error
+
+// IsAuthenticated 连接是否已经通过 WithAuthHandler 的鉴权
+//   - 未设置鉴权函数时、机器人连接及网关转发的连接始终返回 true
+func (slf *Conn) IsAuthenticated() bool {
+	return slf.server.authHandler == nil || slf.IsBot() || slf.gw != nil || slf.authState.Load() == connAuthPassed
+}
+
+// awaitAuth 检查连接是否需要等待鉴权，需要时将开始鉴权超时计时并返回 true
+func (slf *Server) awaitAuth(conn *Conn) bool {
+	if conn.IsAuthenticated() {
+		return false
+	}
+	conn.authTimer = time.AfterFunc(slf.authTimeout, func() {
+		if conn.authState.CompareAndSwap(connAuthPending, connAuthFailed) {
+			conn.CloseWithReason(CloseReasonUnauthorized, ErrConnectionAuthTimeout)
+		}
+	})
+	return true
+}
+
+// authenticate 对连接的数据包进行鉴权，返回 true 时表示该数据包应当继续处理
+//   - 等待鉴权的连接接收到的首个数据包将用于鉴权，鉴权通过后触发 ConnectionOpenedEvent
+func (slf *Server) authenticate(conn *Conn, packet []byte) bool {
+	if conn.IsAuthenticated() {
+		return true
+	}
+	if conn.authState.Load() != connAuthPending {
+		return false
+	}
+	if err := slf.authHandler(conn, packet); err != nil {
+		if conn.authState.CompareAndSwap(connAuthPending, connAuthFailed) {
+			conn.CloseWithReason(CloseReasonUnauthorized, err)
+		}
+		return false
+	}
+	if conn.authState.CompareAndSwap(connAuthPending, connAuthPassed) {
+		conn.authTimer.Stop()
+		slf.OnConnectionOpenedEvent(conn)
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package server_test
+
+import (
+	"errors"
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/server"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithAuthHandler(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket, server.WithAuthHandler(func(conn *server.Conn, firstPacket []byte) error {
+		if string(firstPacket) != "token" {
+			return errors.New("invalid token")
+		}
+		conn.Write([]byte("ok"))
+		return nil
+	}, 200*time.Millisecond))
+	var opened atomic.Int32
+	var closed = make(chan server.CloseReason, 3)
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		opened.Add(1)
+	})
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		if !conn.IsAuthenticated() {
+			t.Error("received packet from unauthenticated connection")
+		}
+		conn.Write(packet)
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
+		closed <- reason
+	})
+	var addr = freeAddr(t, "tcp")
+	runServer(t, srv, addr+"/auth")
+	defer srv.Shutdown()
+
+	dial := func() *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial("ws://"+addr+"/auth", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		return conn
+	}
+	roundTrip := func(conn *websocket.Conn, packet string) string {
+		if err := conn.WriteMessage(websocket.BinaryMessage, []byte(packet)); err != nil {
+			t.Fatal(err)
+		}
+		_, reply, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(reply)
+	}
+
+	authorized := dial()
+	defer authorized.Close()
+	if reply := roundTrip(authorized, "token"); reply != "ok" {
+		t.Fatalf("expected ok, got %s", reply)
+	}
+	if reply := roundTrip(authorized, "hello"); reply != "hello" {
+		t.Fatalf("expected hello, got %s", reply)
+	}
+
+	invalid := dial()
+	defer invalid.Close()
+	if err := invalid.WriteMessage(websocket.BinaryMessage, []byte("bad")); err != nil {
+		t.Fatal(err)
+	}
+	silent := dial()
+	defer silent.Close()
+	for _, conn := range []*websocket.Conn{invalid, silent} {
+		if _, _, err := conn.ReadMessage(); err == nil || errors.Is(err, websocket.ErrReadLimit) {
+			t.Fatalf("expected connection to be closed, got %v", err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case reason := <-closed:
+			if reason != server.CloseReasonUnauthorized {
+				t.Fatalf("expected %s, got %s", server.CloseReasonUnauthorized, reason)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("unauthorized connection was not closed")
+		}
+	}
+	if count := opened.Load(); count != 1 {
+		t.Fatalf("expected 1 opened connection, got %d", count)
+	}
+}
@@ -29,7 +29,7 @@ type Bot struct {
 // JoinServer 加入服务器
 func (slf *Bot) JoinServer() {
 	if slf.joined.Swap(true) {
-		slf.conn.server.OnConnectionClosedEvent(slf.conn, nil)
+		slf.conn.server.OnConnectionClosedEvent(slf.conn, CloseReasonClientClose, nil)
 	}
 	slf.conn.server.OnConnectionOpenedEvent(slf.conn)
 }
@@ -37,7 +37,7 @@ func (slf *Bot) JoinServer() {
 // LeaveServer 离开服务器
 func (slf *Bot) LeaveServer() {
 	if slf.joined.Swap(false) {
-		slf.conn.server.OnConnectionClosedEvent(slf.conn, nil)
+		slf.conn.server.OnConnectionClosedEvent(slf.conn, CloseReasonClientClose, nil)
 	}
 }
 
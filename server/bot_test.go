@@ -3,6 +3,7 @@ package server_test
 import (
 	"github.com/kercylan98/minotaur/server"
 	"io"
+	"os"
 	"testing"
 	"time"
 )
@@ -22,6 +23,9 @@ func (slf *Writer) Write(p []byte) (n int, err error) {
 }
 
 func TestNewBot(t *testing.T) {
+	if len(os.Getenv("MINOTAUR_TEST_MANUAL")) == 0 {
+		t.Skip("manual test, set MINOTAUR_TEST_MANUAL to run it")
+	}
 	srv := server.New(server.NetworkWebsocket)
 
 	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
@@ -29,7 +33,7 @@ func TestNewBot(t *testing.T) {
 		conn.Close()
 		conn.Write([]byte("hello"))
 	})
-	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
 		t.Logf("connection closed: %s", conn.GetID())
 	})
 	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
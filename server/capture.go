@@ -0,0 +1,205 @@
+package server
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"github.com/kercylan98/minotaur/utils/log"
+	"io"
+	"sync"
+	"time"
+)
+
+// CaptureMagic 数据包捕获文件的文件头
+//
+// 捕获文件由文件头及若干条记录组成，所有整数均以大端序编码：
+//   - 文件头：4 字节 CaptureMagic + 1 字节 CaptureVersion
+//   - 记录：1 字节 CaptureDirection + 8 字节 Unix 纳秒时间戳 + 2 字节连接 ID 长度 + 连接 ID + 4 字节数据包长度 + 数据包
+//
+// 捕获的数据包为解密、解压及解码后的逻辑数据包，出站数据包为经过 ConnectionWritePacketBeforeEvent 处理后的数据包
+const CaptureMagic = "MNTC"
+
+// CaptureVersion 数据包捕获文件的格式版本
+const CaptureVersion byte = 1
+
+const (
+	CaptureDirectionInbound  CaptureDirection = iota + 1 // 入站数据包
+	CaptureDirectionOutbound                             // 出站数据包
+)
+
+var captureDirectionNames = map[CaptureDirection]string{
+	CaptureDirectionInbound:  "Inbound",
+	CaptureDirectionOutbound: "Outbound",
+}
+
+// CaptureDirection 捕获的数据包方向
+type CaptureDirection byte
+
+// String 返回数据包方向的字符串表示
+func (slf CaptureDirection) String() string {
+	return captureDirectionNames[slf]
+}
+
+// CaptureRecord 一条数据包捕获记录
+type CaptureRecord struct {
+	Direction CaptureDirection // 数据包方向
+	Time      time.Time        // 捕获时间
+	ConnID    string           // 连接 ID
+	Packet    []byte           // 数据包
+}
+
+// packetCapture 数据包捕获器
+type packetCapture struct {
+	writer *bufio.Writer
+	closer io.Closer
+	lock   sync.Mutex
+	err    error
+}
+
+// newPacketCapture 创建数据包捕获器并写入文件头
+func newPacketCapture(w io.Writer) *packetCapture {
+	c := &packetCapture{writer: bufio.NewWriter(w)}
+	if closer, ok := w.(io.Closer); ok {
+		c.closer = closer
+	}
+	_, c.err = c.writer.WriteString(CaptureMagic)
+	if c.err == nil {
+		c.err = c.writer.WriteByte(CaptureVersion)
+	}
+	return c
+}
+
+// record 记录一个数据包，写入失败后将停止捕获
+func (slf *packetCapture) record(direction CaptureDirection, conn *Conn, packet []byte) {
+	if slf == nil {
+		return
+	}
+	var head [11]byte
+	head[0] = byte(direction)
+	binary.BigEndian.PutUint64(head[1:9], uint64(time.Now().UnixNano()))
+	id := conn.GetID()
+	binary.BigEndian.PutUint16(head[9:11], uint16(len(id)))
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(packet)))
+
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	if slf.err != nil {
+		return
+	}
+	for _, b := range [][]byte{head[:], []byte(id), size[:], packet} {
+		if _, slf.err = slf.writer.Write(b); slf.err != nil {
+			conn.server.Logger().Error("Server", log.String("PacketCapture", "stopped"), log.String("ConnID", id), log.Err(slf.err))
+			return
+		}
+	}
+}
+
+// close 将缓冲区中的数据写入并关闭捕获器
+func (slf *packetCapture) close(logger log.Logger) {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	if err := slf.writer.Flush(); err != nil && slf.err == nil {
+		logger.Error("Server", log.String("PacketCapture", "flush"), log.Err(err))
+	}
+	slf.err = io.ErrClosedPipe
+	if slf.closer != nil {
+		_ = slf.closer.Close()
+	}
+}
+
+// CaptureReader 数据包捕获文件的读取器
+type CaptureReader struct {
+	reader *bufio.Reader
+}
+
+// NewCaptureReader 创建数据包捕获文件的读取器，将会校验文件头
+func NewCaptureReader(r io.Reader) (*CaptureReader, error) {
+	reader := bufio.NewReader(r)
+	var head [5]byte
+	if _, err := io.ReadFull(reader, head[:]); err != nil {
+		return nil, ErrCaptureInvalidFormat
+	}
+	if string(head[:4]) != CaptureMagic || head[4] != CaptureVersion {
+		return nil, ErrCaptureInvalidFormat
+	}
+	return &CaptureReader{reader: reader}, nil
+}
+
+// Next 读取下一条捕获记录，当读取完毕时将返回 io.EOF
+func (slf *CaptureReader) Next() (*CaptureRecord, error) {
+	var head [11]byte
+	if _, err := io.ReadFull(slf.reader, head[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, ErrCaptureInvalidFormat
+	}
+	id := make([]byte, binary.BigEndian.Uint16(head[9:11]))
+	if _, err := io.ReadFull(slf.reader, id); err != nil {
+		return nil, ErrCaptureInvalidFormat
+	}
+	var size [4]byte
+	if _, err := io.ReadFull(slf.reader, size[:]); err != nil {
+		return nil, ErrCaptureInvalidFormat
+	}
+	packet := make([]byte, binary.BigEndian.Uint32(size[:]))
+	if _, err := io.ReadFull(slf.reader, packet); err != nil {
+		return nil, ErrCaptureInvalidFormat
+	}
+	return &CaptureRecord{
+		Direction: CaptureDirection(head[0]),
+		Time:      time.Unix(0, int64(binary.BigEndian.Uint64(head[1:9]))),
+		ConnID:    string(id),
+		Packet:    packet,
+	}, nil
+}
+
+// Replay 将捕获文件中的入站数据包通过机器人回放到服务器中，适用于压力测试及问题复现
+//   - 捕获文件中的每个连接 ID 将对应一个机器人，机器人将在该连接的首个数据包回放前加入服务器，并在回放结束后离开服务器
+//   - speed 为回放速度倍率，例如 2 表示以两倍速回放，当 speed <= 0 时将不等待数据包之间的时间间隔
+//   - 服务器需要处于运行状态，出站数据包将被忽略
+func Replay(srv *Server, r io.Reader, speed float64) error {
+	reader, err := NewCaptureReader(r)
+	if err != nil {
+		return err
+	}
+	var bots = make(map[string]*Bot)
+	defer func() {
+		for _, bot := range bots {
+			bot.LeaveServer()
+		}
+	}()
+	var start, first time.Time
+	for {
+		record, err := reader.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if record.Direction != CaptureDirectionInbound {
+			continue
+		}
+		if speed > 0 {
+			if first.IsZero() {
+				start, first = time.Now(), record.Time
+			} else if wait := time.Duration(float64(record.Time.Sub(first))/speed) - time.Since(start); wait > 0 {
+				time.Sleep(wait)
+			}
+		}
+		bot, exist := bots[record.ConnID]
+		if !exist {
+			bot = NewBot(srv)
+			bot.SetWriter(io.Discard)
+			bot.JoinServer()
+			bots[record.ConnID] = bot
+			// 加入服务器的事件将通过系统消息处理，等待连接上线后再回放数据包
+			for i := 0; i < 100 && !srv.IsOnline(bot.conn.GetID()); i++ {
+				time.Sleep(time.Millisecond)
+			}
+		}
+		bot.SendPacket(record.Packet)
+	}
+}
@@ -0,0 +1,82 @@
+package server_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/kercylan98/minotaur/server"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestWithPacketCapture(t *testing.T) {
+	var capture = new(bytes.Buffer)
+	srv := server.New(server.NetworkWebsocket, server.WithPacketCapture(capture))
+	var received = make(chan struct{}, 2)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		conn.Write(append([]byte("re:"), packet...))
+		received <- struct{}{}
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			bot := server.NewBot(srv)
+			bot.SetWriter(io.Discard)
+			bot.JoinServer()
+			time.Sleep(100 * time.Millisecond)
+			bot.SendPacket([]byte("a"))
+			bot.SendPacket([]byte("b"))
+			for i := 0; i < 2; i++ {
+				<-received
+			}
+			srv.Shutdown()
+		}()
+	})
+	if err := srv.Run(freeAddr(t, "tcp") + "/capture"); err != nil {
+		t.Fatal(err)
+	}
+	var data = bytes.Clone(capture.Bytes())
+
+	reader, err := server.NewCaptureReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var records = map[server.CaptureDirection][]string{}
+	for {
+		record, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		records[record.Direction] = append(records[record.Direction], string(record.Packet))
+	}
+	for direction, expected := range map[server.CaptureDirection][]string{
+		server.CaptureDirectionInbound:  {"a", "b"},
+		server.CaptureDirectionOutbound: {"re:a", "re:b"},
+	} {
+		if got := records[direction]; len(got) != len(expected) || got[0] != expected[0] || got[1] != expected[1] {
+			t.Fatalf("%s: expected %v, got %v", direction, expected, got)
+		}
+	}
+
+	replay := server.New(server.NetworkWebsocket)
+	var replayed = make(chan string, 2)
+	replay.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		replayed <- string(packet)
+	})
+	runServer(t, replay, freeAddr(t, "tcp")+"/replay")
+	defer replay.Shutdown()
+	if err = server.Replay(replay, bytes.NewReader(data), 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, packet := range []string{"a", "b"} {
+		select {
+		case p := <-replayed:
+			if p != packet {
+				t.Fatalf("expected %s, got %s", packet, p)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("packet was not replayed")
+		}
+	}
+}
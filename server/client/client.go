@@ -3,6 +3,7 @@ package client
 import (
 	"errors"
 	"fmt"
+	"github.com/kercylan98/minotaur/server"
 	"github.com/kercylan98/minotaur/server/writeloop"
 	"github.com/kercylan98/minotaur/utils/concurrent"
 	"sync"
@@ -21,6 +22,7 @@ func NewClient(core Core) *Client {
 // CloneClient 克隆客户端
 func CloneClient(client *Client) *Client {
 	cli := NewClient(client.core.Clone())
+	cli.codec = client.codec
 	return cli
 }
 
@@ -33,6 +35,16 @@ type Client struct {
 	pool   *concurrent.Pool[*Packet]     // 数据包缓冲池
 	loop   *writeloop.WriteLoop[*Packet] // 写入循环
 	block  chan struct{}                 // 以阻塞方式运行
+	codec  server.Codec                  // 数据包编解码器
+	buf    []byte                        // 等待解码的数据
+}
+
+// SetCodec 设置数据包编解码器，应与服务器通过 server.WithCodec 设置的编解码器保持一致
+//   - 设置后写入的数据包将先经过编码，OnConnectionReceivePacketEvent 接收到的始终为完整的逻辑数据包
+//   - 适用于 TCP、Unix、KCP 等基于流的客户端，应在 Run 之前设置
+func (slf *Client) SetCodec(codec server.Codec) *Client {
+	slf.codec = codec
+	return slf
 }
 
 // Run 运行客户端，当客户端已运行时，会先关闭客户端再重新运行
@@ -47,6 +59,7 @@ func (slf *Client) Run(block ...bool) error {
 	if len(block) > 0 && block[0] {
 		slf.block = make(chan struct{})
 	}
+	slf.buf = nil
 	var runState = make(chan error)
 	go func(runState chan<- error) {
 		defer func() {
@@ -131,6 +144,16 @@ func (slf *Client) write(wst int, packet []byte, callback ...func(err error)) {
 	if slf.closed {
 		return
 	}
+	if slf.codec != nil {
+		encoded, err := slf.codec.Encode(packet)
+		if err != nil {
+			if len(callback) > 0 {
+				callback[0](err)
+			}
+			return
+		}
+		packet = encoded
+	}
 
 	cp := slf.pool.Get()
 	cp.wst = wst
@@ -142,7 +165,27 @@ func (slf *Client) write(wst int, packet []byte, callback ...func(err error)) {
 }
 
 func (slf *Client) onReceive(wst int, packet []byte) {
-	slf.OnConnectionReceivePacketEvent(slf, wst, packet)
+	if slf.codec == nil {
+		slf.OnConnectionReceivePacketEvent(slf, wst, packet)
+		return
+	}
+	slf.buf = append(slf.buf, packet...)
+	for len(slf.buf) > 0 {
+		decoded, n, err := slf.codec.Decode(slf.buf)
+		if err != nil {
+			panic(err)
+		}
+		if n == 0 {
+			break
+		}
+		// 解码结果可能引用缓冲区，复制后再交由事件处理
+		decoded = append([]byte(nil), decoded...)
+		slf.buf = slf.buf[n:]
+		slf.OnConnectionReceivePacketEvent(slf, wst, decoded)
+	}
+	if len(slf.buf) == 0 {
+		slf.buf = nil
+	}
 }
 
 // GetServerAddr 获取服务器地址
@@ -3,11 +3,15 @@ package client_test
 import (
 	"github.com/kercylan98/minotaur/server"
 	"github.com/kercylan98/minotaur/server/client"
+	"os"
 	"sync"
 	"testing"
 )
 
 func TestClient_WriteWS(t *testing.T) {
+	if len(os.Getenv("MINOTAUR_TEST_MANUAL")) == 0 {
+		t.Skip("manual test, set MINOTAUR_TEST_MANUAL to run it")
+	}
 	var wait sync.WaitGroup
 	wait.Add(1)
 	srv := server.New(server.NetworkWebsocket)
@@ -0,0 +1,290 @@
+package client
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	ConnConnectedEventHandle       func(conn *Conn)
+	ConnDisconnectedEventHandle    func(conn *Conn, err any)
+	ConnReceivePacketEventHandle   func(conn *Conn, packet []byte)
+	ConnReconnectFailedEventHandle func(conn *Conn, err error)
+)
+
+// NewConn 创建一个具有自动重连、心跳及发送队列的客户端连接，适用于编写机器人及服务器之间的客户端
+//   - client 为底层客户端，重连时将通过 CloneClient 创建新的客户端，因此应通过 Conn 注册事件而不是 client
+//   - 对于基于流的客户端，应在传入前通过 Client.SetCodec 设置编解码器
+func NewConn(client *Client, options ...ConnOption) *Conn {
+	conn := &Conn{
+		client:    client,
+		queueSize: DefaultSendQueueSize,
+		done:      make(chan struct{}),
+	}
+	for _, option := range options {
+		option(conn)
+	}
+	return conn
+}
+
+// Conn 具有自动重连、心跳及发送队列的客户端连接
+type Conn struct {
+	mutex             sync.Mutex
+	client            *Client       // 当前使用的底层客户端
+	connected         bool          // 是否已连接
+	closed            bool          // 是否已通过 Close 关闭
+	queue             [][]byte      // 断开期间的发送队列
+	queueCallbacks    []func(error) // 发送队列中数据包的回调
+	queueSize         int           // 发送队列最大长度
+	reconnect         bool          // 是否自动重连
+	reconnectMin      time.Duration // 最小重连间隔
+	reconnectMax      time.Duration // 最大重连间隔
+	reconnectAttempts int           // 最大重连次数
+	heartbeatInterval time.Duration // 心跳间隔
+	heartbeatTimeout  time.Duration // 心跳超时时间
+	heartbeatPacket   []byte        // 心跳数据包
+	lastReceive       atomic.Int64  // 最后接收到数据包的时间
+	done              chan struct{} // 连接关闭信号
+
+	connectedEventHandles       []ConnConnectedEventHandle
+	disconnectedEventHandles    []ConnDisconnectedEventHandle
+	receivePacketEventHandles   []ConnReceivePacketEventHandle
+	reconnectFailedEventHandles []ConnReconnectFailedEventHandle
+}
+
+// Run 建立连接，首次连接失败时将直接返回错误而不进行重连
+//   - 连接建立后将发送断开期间暂存的数据包并触发 ConnectedEvent
+func (slf *Conn) Run() error {
+	slf.mutex.Lock()
+	if slf.closed {
+		slf.mutex.Unlock()
+		return ErrConnClosed
+	}
+	client := slf.client
+	slf.mutex.Unlock()
+	if err := slf.connect(client); err != nil {
+		return err
+	}
+	if slf.heartbeatInterval > 0 {
+		go slf.heartbeat()
+	}
+	return nil
+}
+
+// Close 关闭连接，关闭后将不再重连，发送队列中的数据包将通过回调返回 ErrConnClosed
+func (slf *Conn) Close() {
+	slf.mutex.Lock()
+	if slf.closed {
+		slf.mutex.Unlock()
+		return
+	}
+	slf.closed = true
+	close(slf.done)
+	client, connected := slf.client, slf.connected
+	callbacks := slf.queueCallbacks
+	slf.queue, slf.queueCallbacks = nil, nil
+	slf.mutex.Unlock()
+	for _, callback := range callbacks {
+		if callback != nil {
+			callback(ErrConnClosed)
+		}
+	}
+	if connected {
+		client.Close()
+	}
+}
+
+// IsConnected 是否已连接
+func (slf *Conn) IsConnected() bool {
+	slf.mutex.Lock()
+	defer slf.mutex.Unlock()
+	return slf.connected
+}
+
+// GetServerAddr 获取服务器地址
+func (slf *Conn) GetServerAddr() string {
+	slf.mutex.Lock()
+	defer slf.mutex.Unlock()
+	return slf.client.GetServerAddr()
+}
+
+// Write 向连接中写入数据，连接断开期间数据包将被暂存到发送队列中
+func (slf *Conn) Write(packet []byte, callback ...func(err error)) {
+	var cb func(error)
+	if len(callback) > 0 {
+		cb = callback[0]
+	}
+	slf.mutex.Lock()
+	switch {
+	case slf.connected:
+		client := slf.client
+		slf.mutex.Unlock()
+		client.Write(packet, callback...)
+		return
+	case slf.closed || slf.queueSize <= 0:
+		slf.mutex.Unlock()
+		if cb != nil {
+			cb(ErrConnClosed)
+		}
+		return
+	case len(slf.queue) >= slf.queueSize:
+		slf.mutex.Unlock()
+		if cb != nil {
+			cb(ErrSendQueueFull)
+		}
+		return
+	}
+	slf.queue = append(slf.queue, packet)
+	slf.queueCallbacks = append(slf.queueCallbacks, cb)
+	slf.mutex.Unlock()
+}
+
+// RegConnectedEvent 注册连接建立事件，首次连接及每次重连成功后都将被触发
+func (slf *Conn) RegConnectedEvent(handle ConnConnectedEventHandle) {
+	slf.connectedEventHandles = append(slf.connectedEventHandles, handle)
+}
+
+func (slf *Conn) OnConnectedEvent() {
+	for _, handle := range slf.connectedEventHandles {
+		handle(slf)
+	}
+}
+
+// RegDisconnectedEvent 注册连接断开事件，err 为断开的原因，通过 Close 主动关闭时也将被触发
+func (slf *Conn) RegDisconnectedEvent(handle ConnDisconnectedEventHandle) {
+	slf.disconnectedEventHandles = append(slf.disconnectedEventHandles, handle)
+}
+
+func (slf *Conn) OnDisconnectedEvent(err any) {
+	for _, handle := range slf.disconnectedEventHandles {
+		handle(slf, err)
+	}
+}
+
+// RegReceivePacketEvent 注册接收数据包事件
+func (slf *Conn) RegReceivePacketEvent(handle ConnReceivePacketEventHandle) {
+	slf.receivePacketEventHandles = append(slf.receivePacketEventHandles, handle)
+}
+
+func (slf *Conn) OnReceivePacketEvent(packet []byte) {
+	for _, handle := range slf.receivePacketEventHandles {
+		handle(slf, packet)
+	}
+}
+
+// RegReconnectFailedEvent 注册重连失败事件，当重连次数达到 WithReconnect 设置的最大次数时将被触发，此后连接将被关闭
+func (slf *Conn) RegReconnectFailedEvent(handle ConnReconnectFailedEventHandle) {
+	slf.reconnectFailedEventHandles = append(slf.reconnectFailedEventHandles, handle)
+}
+
+func (slf *Conn) OnReconnectFailedEvent(err error) {
+	for _, handle := range slf.reconnectFailedEventHandles {
+		handle(slf, err)
+	}
+}
+
+// connect 通过特定的底层客户端建立连接，成功后将发送队列中的数据包
+func (slf *Conn) connect(client *Client) error {
+	client.RegConnectionReceivePacketEvent(func(cli *Client, wst int, packet []byte) {
+		slf.lastReceive.Store(time.Now().UnixNano())
+		slf.OnReceivePacketEvent(packet)
+	})
+	client.RegConnectionClosedEvent(slf.onClientClosed)
+	if err := client.Run(); err != nil {
+		return err
+	}
+
+	slf.mutex.Lock()
+	if slf.closed || slf.client != client {
+		slf.mutex.Unlock()
+		client.Close()
+		return ErrConnClosed
+	}
+	slf.connected = true
+	slf.lastReceive.Store(time.Now().UnixNano())
+	queue, callbacks := slf.queue, slf.queueCallbacks
+	slf.queue, slf.queueCallbacks = nil, nil
+	for i, packet := range queue {
+		if callbacks[i] != nil {
+			client.Write(packet, callbacks[i])
+		} else {
+			client.Write(packet)
+		}
+	}
+	slf.mutex.Unlock()
+	slf.OnConnectedEvent()
+	return nil
+}
+
+// onClientClosed 底层客户端断开时的处理，当未通过 Close 关闭时将进行重连
+func (slf *Conn) onClientClosed(client *Client, err any) {
+	slf.mutex.Lock()
+	if slf.client != client || !slf.connected {
+		slf.mutex.Unlock()
+		return
+	}
+	slf.connected = false
+	closed := slf.closed
+	slf.mutex.Unlock()
+	slf.OnDisconnectedEvent(err)
+	if !closed && slf.reconnect {
+		go slf.reconnectLoop(client)
+	}
+}
+
+// reconnectLoop 以指数退避的间隔进行重连
+func (slf *Conn) reconnectLoop(client *Client) {
+	var interval = slf.reconnectMin
+	var err error
+	for attempt := 0; slf.reconnectAttempts <= 0 || attempt < slf.reconnectAttempts; attempt++ {
+		select {
+		case <-slf.done:
+			return
+		case <-time.After(interval):
+		}
+		if interval *= 2; interval > slf.reconnectMax {
+			interval = slf.reconnectMax
+		}
+
+		client = CloneClient(client)
+		slf.mutex.Lock()
+		if slf.closed {
+			slf.mutex.Unlock()
+			return
+		}
+		slf.client = client
+		slf.mutex.Unlock()
+		if err = slf.connect(client); err == nil {
+			return
+		}
+	}
+	slf.OnReconnectFailedEvent(err)
+	slf.Close()
+}
+
+// heartbeat 定时发送心跳并检测超时，直到连接被关闭
+func (slf *Conn) heartbeat() {
+	ticker := time.NewTicker(slf.heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-slf.done:
+			return
+		case <-ticker.C:
+		}
+		slf.mutex.Lock()
+		client, connected := slf.client, slf.connected
+		slf.mutex.Unlock()
+		if !connected {
+			continue
+		}
+		if slf.heartbeatTimeout > 0 && time.Since(time.Unix(0, slf.lastReceive.Load())) >= slf.heartbeatTimeout {
+			client.Close(ErrHeartbeatTimeout)
+			continue
+		}
+		if len(slf.heartbeatPacket) > 0 {
+			client.Write(slf.heartbeatPacket)
+		}
+	}
+}
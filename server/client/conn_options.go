@@ -0,0 +1,45 @@
+package client
+
+import "time"
+
+const (
+	DefaultReconnectMinInterval = 500 * time.Millisecond // 默认的最小重连间隔
+	DefaultReconnectMaxInterval = 30 * time.Second       // 默认的最大重连间隔
+	DefaultSendQueueSize        = 1024                   // 默认的发送队列长度
+)
+
+// ConnOption 连接选项
+type ConnOption func(conn *Conn)
+
+// WithReconnect 通过自动重连的方式创建连接，连接意外断开后将以指数退避的间隔进行重连
+//   - 重连间隔从 min 开始，每次失败后翻倍，最大不超过 max
+//   - maxAttempts 为单次断线的最大重连次数，超出后将触发 ReconnectFailedEvent，当 maxAttempts <= 0 时表示不限制
+func WithReconnect(min, max time.Duration, maxAttempts int) ConnOption {
+	return func(conn *Conn) {
+		if min <= 0 {
+			min = DefaultReconnectMinInterval
+		}
+		if max < min {
+			max = min
+		}
+		conn.reconnect = true
+		conn.reconnectMin, conn.reconnectMax, conn.reconnectAttempts = min, max, maxAttempts
+	}
+}
+
+// WithHeartbeat 通过心跳的方式创建连接，连接建立后将以 interval 的间隔发送 packet
+//   - 当超过 timeout 未接收到任何数据包时将断开连接，此时如果启用了自动重连将进行重连，当 timeout <= 0 时表示不检测
+func WithHeartbeat(interval, timeout time.Duration, packet []byte) ConnOption {
+	return func(conn *Conn) {
+		conn.heartbeatInterval, conn.heartbeatTimeout, conn.heartbeatPacket = interval, timeout, packet
+	}
+}
+
+// WithSendQueueSize 设置连接断开期间发送队列的最大长度，默认为 DefaultSendQueueSize
+//   - 连接断开期间写入的数据包将被暂存，并在连接建立后按序发送，队列已满时将通过回调返回 ErrSendQueueFull
+//   - 当 size <= 0 时表示不暂存，连接断开期间写入的数据包将通过回调返回 ErrConnClosed
+func WithSendQueueSize(size int) ConnOption {
+	return func(conn *Conn) {
+		conn.queueSize = size
+	}
+}
@@ -0,0 +1,110 @@
+package client_test
+
+import (
+	"encoding/json"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/client"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type echoMessage struct {
+	Text string `json:"text"`
+}
+
+func TestConn_Reconnect(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket)
+	var serverRouter = server.NewRouter[uint16](server.RouterDecoderUint16).SetEncoder(server.RouterEncoderUint16).Bind(srv)
+	server.RouteJSON[uint16, echoMessage](serverRouter, 1, func(conn *server.Conn, message *echoMessage) {
+		if message.Text == "kick" {
+			conn.Close()
+			return
+		}
+		data, _ := json.Marshal(message)
+		serverRouter.Write(conn, 1, data)
+	})
+	var addr = freeAddr(t)
+	runServer(t, srv, addr)
+	defer srv.Shutdown()
+
+	conn := client.NewConn(client.NewWebsocket("ws://"+addr), client.WithReconnect(50*time.Millisecond, 200*time.Millisecond, 0))
+	defer conn.Close()
+	var connected atomic.Int32
+	conn.RegConnectedEvent(func(conn *client.Conn) {
+		connected.Add(1)
+	})
+	var received = make(chan string, 8)
+	router := client.NewRouter[uint16](server.RouterDecoderUint16).SetEncoder(server.RouterEncoderUint16).Bind(conn)
+	client.RouteJSON[uint16, echoMessage](router, 1, func(conn *client.Conn, message *echoMessage) {
+		received <- message.Text
+	})
+
+	// 连接建立前写入的数据包将在连接建立后发送
+	if err := router.WriteJSON(conn, 1, echoMessage{Text: "queued"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Run(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-received:
+		if got != "queued" {
+			t.Fatalf("expected queued, got %s", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued message was not received")
+	}
+
+	// 服务器断开连接后将自动重连
+	_ = router.WriteJSON(conn, 1, echoMessage{Text: "kick"})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for connected.Load() < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if count := connected.Load(); count != 2 {
+		t.Fatalf("expected to reconnect once, got %d connected events", count)
+	}
+	_ = router.WriteJSON(conn, 1, echoMessage{Text: "again"})
+	select {
+	case got := <-received:
+		if got != "again" {
+			t.Fatalf("expected again, got %s", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("message was not received after reconnecting")
+	}
+}
+
+// freeAddr 获取本地空闲 TCP 端口的地址
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+// runServer 在新的协程中运行服务器并等待启动完成，运行失败或启动超时时将终止测试
+func runServer(t *testing.T, srv *server.Server, addr string) {
+	t.Helper()
+	var ready = make(chan struct{})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	var result = make(chan error, 1)
+	go func() {
+		result <- srv.Run(addr)
+	}()
+	select {
+	case <-ready:
+	case err := <-result:
+		t.Fatalf("server run failed: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server was not started")
+	}
+}
@@ -0,0 +1,9 @@
+package client
+
+import "errors"
+
+var (
+	ErrConnClosed       = errors.New("client: connection has been closed")
+	ErrSendQueueFull    = errors.New("client: send queue is full")
+	ErrHeartbeatTimeout = errors.New("client: heartbeat timeout")
+)
@@ -8,12 +8,20 @@ func dial(network string, addr string, runState chan<- error, receive func(wst i
 		runState <- err
 		return
 	}
+	serve(c, runState, receive, setConn, isClosed)
+}
+
+// serve 在连接建立后持续读取数据包，直到连接关闭
+func serve(c net.Conn, runState chan<- error, receive func(wst int, packet []byte), setConn func(conn net.Conn), isClosed func() bool) {
 	setConn(c)
 	runState <- nil
 	packet := make([]byte, 1024)
 	for !isClosed() {
 		n, readErr := c.Read(packet)
 		if readErr != nil {
+			if isClosed() {
+				return
+			}
 			panic(readErr)
 		}
 		receive(0, packet[:n])
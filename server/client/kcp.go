@@ -0,0 +1,71 @@
+package client
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/xtaci/kcp-go/v5"
+	"net"
+	"sync/atomic"
+)
+
+// NewKCP 创建 KCP 客户端
+//   - config 为 KCP 调优配置，应与服务器通过 server.WithKcpConfig 设置的配置保持一致
+func NewKCP(addr string, config ...*server.KcpConfig) *Client {
+	k := &KCP{addr: addr}
+	if len(config) > 0 {
+		k.config = config[0]
+	}
+	return NewClient(k)
+}
+
+// KCP KCP 客户端
+type KCP struct {
+	conn   net.Conn
+	addr   string
+	config *server.KcpConfig
+	closed atomic.Bool
+}
+
+func (slf *KCP) Run(runState chan<- error, receive func(wst int, packet []byte)) {
+	var session *kcp.UDPSession
+	var err error
+	if slf.config == nil {
+		session, err = kcp.DialWithOptions(slf.addr, nil, 0, 0)
+	} else {
+		session, err = kcp.DialWithOptions(slf.addr, slf.config.BlockCrypt, slf.config.DataShards, slf.config.ParityShards)
+	}
+	if err != nil {
+		runState <- err
+		return
+	}
+	if slf.config != nil {
+		slf.config.Apply(session)
+	}
+	serve(session, runState, receive, func(conn net.Conn) {
+		slf.conn = conn
+	}, func() bool {
+		return slf.closed.Load()
+	})
+}
+
+func (slf *KCP) Write(packet *Packet) error {
+	_, err := slf.conn.Write(packet.data)
+	return err
+}
+
+func (slf *KCP) Close() {
+	slf.closed.Store(true)
+	if slf.conn != nil {
+		_ = slf.conn.Close()
+	}
+}
+
+func (slf *KCP) GetServerAddr() string {
+	return slf.addr
+}
+
+func (slf *KCP) Clone() Core {
+	return &KCP{
+		addr:   slf.addr,
+		config: slf.config,
+	}
+}
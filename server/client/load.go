@@ -0,0 +1,188 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LoadConfig 压力测试配置
+type LoadConfig struct {
+	Connections int                               // 并发连接数量
+	Rate        float64                           // 每个连接每秒发送的消息数量
+	Duration    time.Duration                     // 发送消息的持续时间
+	Drain       time.Duration                     // 发送结束后等待剩余响应的最长时间，为 0 时默认为 1 秒
+	Packet      func(cli *Client, seq int) []byte // 生成第 seq 条消息，为 nil 时发送固定的 "ping"
+	OnConnected func(cli *Client)                 // 连接建立后的回调，可用于发送登录等前置消息
+}
+
+// LoadReport 压力测试报告
+type LoadReport struct {
+	Connections int           // 成功建立的连接数量
+	Failed      int           // 建立失败的连接数量
+	Sent        int64         // 发送的消息数量
+	Received    int64         // 接收的响应数量
+	Errors      int64         // 写入失败的消息数量
+	Elapsed     time.Duration // 压力测试总耗时
+	Min         time.Duration // 最小延迟
+	Avg         time.Duration // 平均延迟
+	P50         time.Duration // 50 分位延迟
+	P90         time.Duration // 90 分位延迟
+	P99         time.Duration // 99 分位延迟
+	Max         time.Duration // 最大延迟
+}
+
+// String 返回压力测试报告的文本表示
+func (slf *LoadReport) String() string {
+	return fmt.Sprintf("connections=%d failed=%d sent=%d received=%d errors=%d elapsed=%s min=%s avg=%s p50=%s p90=%s p99=%s max=%s",
+		slf.Connections, slf.Failed, slf.Sent, slf.Received, slf.Errors, slf.Elapsed,
+		slf.Min, slf.Avg, slf.P50, slf.P90, slf.P99, slf.Max,
+	)
+}
+
+// RunLoad 通过 factory 创建 config.Connections 个客户端并以 config.Rate 的速率持续发送消息，返回压力测试报告
+//   - 延迟为消息写入到收到响应的时间，按照发送顺序与接收顺序一一对应计算，因此要求服务器对每条消息有且仅有一条按序的响应（例如回显服务器）
+//   - 对于基于流的客户端，应通过 Client.SetCodec 设置编解码器，避免粘包导致响应数量统计错误
+//   - 压力测试结束后将关闭所有客户端
+func RunLoad(factory func() *Client, config LoadConfig) (*LoadReport, error) {
+	if config.Connections <= 0 || config.Rate <= 0 || config.Duration <= 0 {
+		return nil, errors.New("client: connections, rate and duration must be positive")
+	}
+	if config.Drain <= 0 {
+		config.Drain = time.Second
+	}
+	if config.Packet == nil {
+		config.Packet = func(cli *Client, seq int) []byte {
+			return []byte("ping")
+		}
+	}
+
+	var report = new(LoadReport)
+	var sent, received, failed atomic.Int64
+	var latencyLock sync.Mutex
+	var latencies = make([]time.Duration, 0, int(float64(config.Connections)*config.Rate*config.Duration.Seconds()))
+	var clients = make([]*Client, 0, config.Connections)
+	var pending = make([]*loadPending, 0, config.Connections)
+	for i := 0; i < config.Connections; i++ {
+		cli := factory()
+		p := new(loadPending)
+		cli.RegConnectionReceivePacketEvent(func(conn *Client, wst int, packet []byte) {
+			start, ok := p.pop()
+			if !ok {
+				return
+			}
+			received.Add(1)
+			latency := time.Since(start)
+			latencyLock.Lock()
+			latencies = append(latencies, latency)
+			latencyLock.Unlock()
+		})
+		if err := cli.Run(); err != nil {
+			report.Failed++
+			continue
+		}
+		if config.OnConnected != nil {
+			config.OnConnected(cli)
+		}
+		clients = append(clients, cli)
+		pending = append(pending, p)
+	}
+	report.Connections = len(clients)
+	if report.Connections == 0 {
+		return report, errors.New("client: no connection established")
+	}
+
+	var start = time.Now()
+	var wait sync.WaitGroup
+	var interval = time.Duration(float64(time.Second) / config.Rate)
+	for i, cli := range clients {
+		wait.Add(1)
+		go func(cli *Client, p *loadPending) {
+			defer wait.Done()
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			deadline := time.After(config.Duration)
+			for seq := 0; ; seq++ {
+				select {
+				case <-deadline:
+					return
+				case <-ticker.C:
+					p.push(time.Now())
+					sent.Add(1)
+					cli.Write(config.Packet(cli, seq), func(err error) {
+						if err != nil {
+							failed.Add(1)
+						}
+					})
+				}
+			}
+		}(cli, pending[i])
+	}
+	wait.Wait()
+
+	var drain = time.Now().Add(config.Drain)
+	for received.Load()+failed.Load() < sent.Load() && time.Now().Before(drain) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	report.Elapsed = time.Since(start)
+	for _, cli := range clients {
+		cli.Close()
+	}
+
+	report.Sent, report.Received, report.Errors = sent.Load(), received.Load(), failed.Load()
+	latencyLock.Lock()
+	defer latencyLock.Unlock()
+	if len(latencies) == 0 {
+		return report, nil
+	}
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i] < latencies[j]
+	})
+	var total time.Duration
+	for _, latency := range latencies {
+		total += latency
+	}
+	report.Min, report.Max = latencies[0], latencies[len(latencies)-1]
+	report.Avg = total / time.Duration(len(latencies))
+	report.P50 = percentile(latencies, 0.5)
+	report.P90 = percentile(latencies, 0.9)
+	report.P99 = percentile(latencies, 0.99)
+	return report, nil
+}
+
+// percentile 获取已排序延迟中的 p 分位值
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	index := int(float64(len(sorted))*p+0.5) - 1
+	if index < 0 {
+		index = 0
+	} else if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index]
+}
+
+// loadPending 单个连接中等待响应的消息发送时间
+type loadPending struct {
+	lock  sync.Mutex
+	times []time.Time
+}
+
+func (slf *loadPending) push(t time.Time) {
+	slf.lock.Lock()
+	slf.times = append(slf.times, t)
+	slf.lock.Unlock()
+}
+
+func (slf *loadPending) pop() (time.Time, bool) {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	if len(slf.times) == 0 {
+		return time.Time{}, false
+	}
+	t := slf.times[0]
+	slf.times = slf.times[1:]
+	return t, true
+}
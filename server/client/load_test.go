@@ -0,0 +1,37 @@
+package client_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/client"
+	"testing"
+	"time"
+)
+
+func TestRunLoad(t *testing.T) {
+	codec := server.NewLengthFieldCodec(2, 0)
+	srv := server.New(server.NetworkTcp, server.WithCodec(codec))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		conn.Write(packet)
+	})
+	var addr = freeAddr(t)
+	runServer(t, srv, addr)
+	defer srv.Shutdown()
+
+	report, err := client.RunLoad(func() *client.Client {
+		return client.NewTCP(addr).SetCodec(codec)
+	}, client.LoadConfig{
+		Connections: 5,
+		Rate:        50,
+		Duration:    500 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(report)
+	if report.Connections != 5 || report.Sent == 0 || report.Received != report.Sent {
+		t.Fatalf("unexpected report: %s", report)
+	}
+	if report.P50 <= 0 || report.P50 > report.P99 || report.P99 > report.Max {
+		t.Fatalf("unexpected latency percentiles: %s", report)
+	}
+}
@@ -1,6 +1,9 @@
 package client
 
-import "net"
+import (
+	"net"
+	"sync/atomic"
+)
 
 func NewTCP(addr string) *Client {
 	return NewClient(&TCP{
@@ -11,14 +14,14 @@ func NewTCP(addr string) *Client {
 type TCP struct {
 	conn   net.Conn
 	addr   string
-	closed bool
+	closed atomic.Bool
 }
 
 func (slf *TCP) Run(runState chan<- error, receive func(wst int, packet []byte)) {
 	dial("tcp", slf.addr, runState, receive, func(conn net.Conn) {
 		slf.conn = conn
 	}, func() bool {
-		return slf.closed
+		return slf.closed.Load()
 	})
 }
 
@@ -28,7 +31,10 @@ func (slf *TCP) Write(packet *Packet) error {
 }
 
 func (slf *TCP) Close() {
-	slf.closed = true
+	slf.closed.Store(true)
+	if slf.conn != nil {
+		_ = slf.conn.Close()
+	}
 }
 
 func (slf *TCP) GetServerAddr() string {
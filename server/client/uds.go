@@ -2,6 +2,7 @@ package client
 
 import (
 	"net"
+	"sync/atomic"
 )
 
 func NewUnixDomainSocket(addr string) *Client {
@@ -13,14 +14,14 @@ func NewUnixDomainSocket(addr string) *Client {
 type UnixDomainSocket struct {
 	conn   net.Conn
 	addr   string
-	closed bool
+	closed atomic.Bool
 }
 
 func (slf *UnixDomainSocket) Run(runState chan<- error, receive func(wst int, packet []byte)) {
 	dial("unix", slf.addr, runState, receive, func(conn net.Conn) {
 		slf.conn = conn
 	}, func() bool {
-		return slf.closed
+		return slf.closed.Load()
 	})
 }
 
@@ -30,7 +31,10 @@ func (slf *UnixDomainSocket) Write(packet *Packet) error {
 }
 
 func (slf *UnixDomainSocket) Close() {
-	slf.closed = true
+	slf.closed.Store(true)
+	if slf.conn != nil {
+		_ = slf.conn.Close()
+	}
 }
 
 func (slf *UnixDomainSocket) GetServerAddr() string {
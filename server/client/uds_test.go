@@ -3,11 +3,15 @@ package client_test
 import (
 	"github.com/kercylan98/minotaur/server"
 	"github.com/kercylan98/minotaur/server/client"
+	"os"
 	"testing"
 	"time"
 )
 
 func TestUnixDomainSocket_Write(t *testing.T) {
+	if len(os.Getenv("MINOTAUR_TEST_MANUAL")) == 0 {
+		t.Skip("manual test, set MINOTAUR_TEST_MANUAL to run it")
+	}
 	var closed = make(chan struct{})
 	srv := server.New(server.NetworkUnix)
 	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
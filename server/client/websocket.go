@@ -39,6 +39,12 @@ func (slf *Websocket) Run(runState chan<- error, receive func(wst int, packet []
 		slf.mu.Unlock()
 		messageType, packet, readErr := ws.ReadMessage()
 		if readErr != nil {
+			slf.mu.Lock()
+			closed := slf.closed
+			slf.mu.Unlock()
+			if closed {
+				return
+			}
 			panic(readErr)
 		}
 		receive(messageType, packet)
@@ -56,6 +62,9 @@ func (slf *Websocket) Close() {
 	slf.mu.Lock()
 	defer slf.mu.Unlock()
 	slf.closed = true
+	if slf.conn != nil {
+		_ = slf.conn.Close()
+	}
 }
 
 func (slf *Websocket) GetServerAddr() string {
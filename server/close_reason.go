@@ -0,0 +1,64 @@
+package server
+
+import (
+	"errors"
+	"github.com/gorilla/websocket"
+	"io"
+	"net"
+)
+
+const (
+	CloseReasonUnknown          CloseReason = iota // 未知原因
+	CloseReasonClientClose                         // 客户端主动断开连接
+	CloseReasonServerClose                         // 服务器通过 Conn.Close 或 Server.CloseConn 主动关闭连接
+	CloseReasonReadTimeout                         // 读取数据超时
+	CloseReasonReadError                           // 读取数据时发生错误
+	CloseReasonWriteError                          // 写入数据时发生错误
+	CloseReasonProtocolError                       // 数据包不符合协议，例如编解码失败或不支持的消息类型
+	CloseReasonHeartbeatTimeout                    // 心跳超时
+	CloseReasonKicked                              // 被服务器踢出
+	CloseReasonRateLimited                         // 接收数据包超出速率限制
+	CloseReasonPanic                               // 处理连接数据时发生 panic，且 PanicPolicy 要求关闭连接
+	CloseReasonMaintenance                         // 服务器处于维护模式且连接不在白名单中
+	CloseReasonUnauthorized                        // 连接未能在限定时间内通过 WithAuthHandler 的鉴权
+)
+
+var closeReasonNames = map[CloseReason]string{
+	CloseReasonUnknown:          "Unknown",
+	CloseReasonClientClose:      "ClientClose",
+	CloseReasonServerClose:      "ServerClose",
+	CloseReasonReadTimeout:      "ReadTimeout",
+	CloseReasonReadError:        "ReadError",
+	CloseReasonWriteError:       "WriteError",
+	CloseReasonProtocolError:    "ProtocolError",
+	CloseReasonHeartbeatTimeout: "HeartbeatTimeout",
+	CloseReasonKicked:           "Kicked",
+	CloseReasonRateLimited:      "RateLimited",
+	CloseReasonPanic:            "Panic",
+	CloseReasonMaintenance:      "Maintenance",
+	CloseReasonUnauthorized:     "Unauthorized",
+}
+
+// CloseReason 连接关闭原因
+type CloseReason byte
+
+// String 返回连接关闭原因的字符串表示
+func (slf CloseReason) String() string {
+	return closeReasonNames[slf]
+}
+
+// readCloseReason 根据读取数据时发生的错误推断连接关闭原因
+func readCloseReason(err error) CloseReason {
+	var netErr net.Error
+	switch {
+	case err == nil, errors.Is(err, io.EOF), errors.Is(err, net.ErrClosed), websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived):
+		return CloseReasonClientClose
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return CloseReasonReadTimeout
+	case errors.Is(err, ErrWebsocketIllegalMessageType), errors.Is(err, ErrCodecPacketTooLarge), errors.Is(err, ErrPacketTooLarge), errors.Is(err, websocket.ErrReadLimit), errors.Is(err, ErrCompressionInvalidPacket), errors.Is(err, ErrCompressionPacketTooLarge),
+		errors.Is(err, ErrCryptoHandshakeFailed), errors.Is(err, ErrCryptoInvalidPacket), errors.Is(err, ErrPacketSequenceMissing):
+		return CloseReasonProtocolError
+	default:
+		return CloseReasonReadError
+	}
+}
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"errors"
+	"github.com/gorilla/websocket"
+	"io"
+	"os"
+	"testing"
+)
+
+func TestReadCloseReason(t *testing.T) {
+	var cases = []struct {
+		err    error
+		expect CloseReason
+	}{
+		{nil, CloseReasonClientClose},
+		{io.EOF, CloseReasonClientClose},
+		{&websocket.CloseError{Code: websocket.CloseGoingAway}, CloseReasonClientClose},
+		{os.ErrDeadlineExceeded, CloseReasonReadTimeout},
+		{ErrCodecPacketTooLarge, CloseReasonProtocolError},
+		{errors.New("unknown"), CloseReasonReadError},
+	}
+	for _, c := range cases {
+		if reason := readCloseReason(c.err); reason != c.expect {
+			t.Fatalf("%v: expected %s, got %s", c.err, c.expect, reason)
+		}
+	}
+}
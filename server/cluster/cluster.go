@@ -0,0 +1,196 @@
+package cluster
+
+import (
+	"context"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/log"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultReportInterval = time.Second * 10 // 默认的负载上报间隔
+	registryTimeout       = time.Second * 5  // 注册中心操作的超时时间
+)
+
+// NewCluster 创建通过 registry 进行服务器实例注册及发现的集群
+func NewCluster(registry Registry, options ...Option) *Cluster {
+	c := &Cluster{
+		registry:       registry,
+		picker:         PickLeastLoad,
+		reportInterval: DefaultReportInterval,
+		load: func(srv *server.Server) float64 {
+			return float64(srv.GetOnlineCount())
+		},
+		instances: map[string]Instance{},
+	}
+	for _, option := range options {
+		option(c)
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	return c
+}
+
+var _ server.CrossDiscovery = (*Cluster)(nil)
+
+// Cluster 集群，维护注册中心中的服务器实例并提供服务器 ID 到实例的解析
+type Cluster struct {
+	registry       Registry
+	picker         Picker
+	reportInterval time.Duration
+	load           func(srv *server.Server) float64
+	changeHandlers []func(instances []Instance)
+	instances      map[string]Instance
+	watchOnce      sync.Once
+	rw             sync.RWMutex
+	ctx            context.Context
+	cancel         context.CancelFunc
+}
+
+// Join 将服务器加入集群，服务器启动完成后将注册实例并开始监听集群变化，服务器停止时将注销实例
+//   - instance.ID 为空时将使用 server.Server.GetID，instance.Network 为空时将使用服务器的网络类型
+//   - instance.Addr 应当为其他服务器或客户端可访问的地址，而非侦听地址
+//   - 负载将按照 WithReportInterval 设置的间隔通过 WithLoad 设置的函数计算并上报
+//   - 应当在服务器运行前调用
+func (slf *Cluster) Join(srv *server.Server, instance Instance) {
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		if len(instance.ID) == 0 {
+			instance.ID = srv.GetID()
+		}
+		if len(instance.Network) == 0 {
+			instance.Network = string(srv.GetNetwork())
+		}
+		if len(instance.ID) == 0 {
+			srv.PushErrorMessage(ErrInstanceIdEmpty, server.MessageErrorActionShutdown)
+			return
+		}
+		if err := slf.report(srv, instance); err != nil {
+			srv.PushErrorMessage(err, server.MessageErrorActionShutdown)
+			return
+		}
+		if err := slf.Watch(); err != nil {
+			srv.PushErrorMessage(err, server.MessageErrorActionShutdown)
+			return
+		}
+		go func() {
+			ticker := time.NewTicker(slf.reportInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-slf.ctx.Done():
+					return
+				case <-ticker.C:
+					if err := slf.report(srv, instance); err != nil {
+						log.Error("Cluster", log.String("ID", instance.ID), log.String("Action", "Report"), log.Err(err))
+					}
+				}
+			}
+		}()
+		log.Info("Cluster", log.String("ID", instance.ID), log.String("Network", instance.Network), log.String("Addr", instance.Addr))
+	})
+	srv.RegStopEvent(func(srv *server.Server) {
+		slf.cancel()
+		ctx, cancel := context.WithTimeout(context.Background(), registryTimeout)
+		defer cancel()
+		if err := slf.registry.Deregister(ctx, instance.ID); err != nil {
+			log.Error("Cluster", log.String("ID", instance.ID), log.String("Action", "Deregister"), log.Err(err))
+		}
+	})
+}
+
+// Watch 开始监听集群变化，重复调用将不会产生任何效果
+//   - 通过 Join 加入集群时将自动开始监听，对于仅需要发现其他服务器的情况（例如网关），可直接调用该函数
+func (slf *Cluster) Watch() (err error) {
+	slf.watchOnce.Do(func() {
+		err = slf.registry.Watch(slf.ctx, func(instances []Instance) {
+			var m = make(map[string]Instance, len(instances))
+			for _, instance := range instances {
+				m[instance.ID] = instance
+			}
+			slf.rw.Lock()
+			slf.instances = m
+			slf.rw.Unlock()
+			for _, handler := range slf.changeHandlers {
+				handler(instances)
+			}
+		})
+	})
+	return
+}
+
+// Close 停止监听集群变化及负载上报，不会注销已注册的实例
+func (slf *Cluster) Close() {
+	slf.cancel()
+}
+
+// Get 获取特定 ID 的服务器实例
+func (slf *Cluster) Get(id string) (instance Instance, exist bool) {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	instance, exist = slf.instances[id]
+	return
+}
+
+// Instances 获取集群中的所有服务器实例，结果将按照 ID 排序
+func (slf *Cluster) Instances(filter ...func(instance Instance) bool) []Instance {
+	slf.rw.RLock()
+	var instances = make([]Instance, 0, len(slf.instances))
+	for _, instance := range slf.instances {
+		var pass = true
+		for _, f := range filter {
+			if !f(instance) {
+				pass = false
+				break
+			}
+		}
+		if pass {
+			instances = append(instances, instance)
+		}
+	}
+	slf.rw.RUnlock()
+	sort.Slice(instances, func(i, j int) bool {
+		return instances[i].ID < instances[j].ID
+	})
+	return instances
+}
+
+// GetServerIds 获取特定分组的全部服务器 ID，当 group 为空时将返回全部服务器 ID，结果将按照 ID 排序
+//   - 实现了 server.CrossDiscovery 接口，可通过 server.WithCrossDiscovery 用于跨服广播及分组消息
+func (slf *Cluster) GetServerIds(group string) []string {
+	var instances []Instance
+	if len(group) == 0 {
+		instances = slf.Instances()
+	} else {
+		instances = slf.Instances(InGroup(group))
+	}
+	var ids = make([]string, len(instances))
+	for i, instance := range instances {
+		ids[i] = instance.ID
+	}
+	return ids
+}
+
+// Pick 通过 WithPicker 设置的选择器从满足 filter 的服务器实例中选择一个，当不存在满足条件的实例时将返回 ErrNoInstance
+func (slf *Cluster) Pick(filter ...func(instance Instance) bool) (Instance, error) {
+	instances := slf.Instances(filter...)
+	if len(instances) == 0 {
+		return Instance{}, ErrNoInstance
+	}
+	return slf.picker(instances), nil
+}
+
+// report 计算负载并注册实例
+func (slf *Cluster) report(srv *server.Server, instance Instance) error {
+	instance.Load = slf.load(srv)
+	ctx, cancel := context.WithTimeout(slf.ctx, registryTimeout)
+	defer cancel()
+	return slf.registry.Register(ctx, instance)
+}
+
+// InGroup 返回筛选特定分组服务器实例的过滤函数，可用于 Cluster.Instances 及 Cluster.Pick
+func InGroup(group string) func(instance Instance) bool {
+	return func(instance Instance) bool {
+		return instance.Group == group
+	}
+}
@@ -0,0 +1,86 @@
+package cluster_test
+
+import (
+	"context"
+	"github.com/kercylan98/minotaur/server/cluster"
+	"sync"
+	"testing"
+)
+
+type memoryRegistry struct {
+	instances map[string]cluster.Instance
+	handlers  []func(instances []cluster.Instance)
+	rw        sync.Mutex
+}
+
+func (slf *memoryRegistry) Register(ctx context.Context, instance cluster.Instance) error {
+	slf.rw.Lock()
+	slf.instances[instance.ID] = instance
+	slf.rw.Unlock()
+	slf.notify()
+	return nil
+}
+
+func (slf *memoryRegistry) Deregister(ctx context.Context, id string) error {
+	slf.rw.Lock()
+	delete(slf.instances, id)
+	slf.rw.Unlock()
+	slf.notify()
+	return nil
+}
+
+func (slf *memoryRegistry) Instances(ctx context.Context) ([]cluster.Instance, error) {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	var instances []cluster.Instance
+	for _, instance := range slf.instances {
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+func (slf *memoryRegistry) Watch(ctx context.Context, handler func(instances []cluster.Instance)) error {
+	slf.rw.Lock()
+	slf.handlers = append(slf.handlers, handler)
+	slf.rw.Unlock()
+	slf.notify()
+	return nil
+}
+
+func (slf *memoryRegistry) notify() {
+	instances, _ := slf.Instances(context.Background())
+	for _, handler := range slf.handlers {
+		handler(instances)
+	}
+}
+
+func TestCluster_Pick(t *testing.T) {
+	registry := &memoryRegistry{instances: map[string]cluster.Instance{}}
+	c := cluster.NewCluster(registry)
+	defer c.Close()
+	if err := c.Watch(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Pick(); err != cluster.ErrNoInstance {
+		t.Fatalf("expected ErrNoInstance, got %v", err)
+	}
+
+	_ = registry.Register(context.Background(), cluster.Instance{ID: "game-1", Group: "game", Addr: "10.0.0.1:8888", Load: 10})
+	_ = registry.Register(context.Background(), cluster.Instance{ID: "game-2", Group: "game", Addr: "10.0.0.2:8888", Load: 5})
+	_ = registry.Register(context.Background(), cluster.Instance{ID: "battle-1", Group: "battle", Addr: "10.0.0.3:8888", Load: 1})
+	if instance, exist := c.Get("game-1"); !exist || instance.Addr != "10.0.0.1:8888" {
+		t.Fatalf("unexpected instance: %v %v", instance, exist)
+	}
+	instance, err := c.Pick(cluster.InGroup("game"))
+	if err != nil || instance.ID != "game-2" {
+		t.Fatalf("expected game-2, got %v %v", instance, err)
+	}
+	if ids := c.GetServerIds("game"); len(ids) != 2 || ids[0] != "game-1" || ids[1] != "game-2" {
+		t.Fatalf("unexpected server ids: %v", ids)
+	}
+
+	_ = registry.Deregister(context.Background(), "game-2")
+	if instances := c.Instances(); len(instances) != 2 || instances[0].ID != "battle-1" || instances[1].ID != "game-1" {
+		t.Fatalf("unexpected instances: %v", instances)
+	}
+}
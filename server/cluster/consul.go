@@ -0,0 +1,219 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-resty/resty/v2"
+	"github.com/kercylan98/minotaur/utils/log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultConsulService = "minotaur"       // 默认的 consul 服务名称
+	DefaultConsulTTL     = time.Second * 10 // 默认的 consul 健康检查 TTL
+	consulMetaInstance   = "instance"       // 服务器实例在服务元数据中的键
+	consulWatchWait      = time.Second * 30 // 阻塞查询的最长等待时间
+	consulRetryInterval  = time.Second      // 保活或监听失败后的重试间隔
+)
+
+// NewConsul 创建基于 consul 的注册中心
+//   - addr 为 consul agent 的 HTTP 地址，例如 "http://127.0.0.1:8500"
+//   - 服务器实例将注册为名称为 service 的服务，并通过 TTL 健康检查保活，检查失败超过一分钟后将被 consul 自动注销
+func NewConsul(addr string, options ...ConsulOption) *Consul {
+	c := &Consul{
+		client:  resty.New().SetBaseURL(addr),
+		service: DefaultConsulService,
+		ttl:     DefaultConsulTTL,
+		checks:  map[string]*consulCheck{},
+	}
+	for _, option := range options {
+		option(c)
+	}
+	return c
+}
+
+// Consul 基于 consul 的注册中心
+type Consul struct {
+	client  *resty.Client
+	service string
+	ttl     time.Duration
+	checks  map[string]*consulCheck
+	rw      sync.Mutex
+}
+
+// consulCheck 服务器实例的健康检查
+type consulCheck struct {
+	instance Instance
+	cancel   context.CancelFunc
+}
+
+// consulService consul 健康服务查询结果
+type consulService struct {
+	Service struct {
+		ID   string            `json:"ID"`
+		Meta map[string]string `json:"Meta"`
+	} `json:"Service"`
+}
+
+// Register 注册或更新服务器实例，首次注册时将持续进行健康检查保活，健康检查失败时将自动重新注册
+func (slf *Consul) Register(ctx context.Context, instance Instance) error {
+	if len(instance.ID) == 0 {
+		return ErrInstanceIdEmpty
+	}
+	if err := slf.register(ctx, instance); err != nil {
+		return err
+	}
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	if check, exist := slf.checks[instance.ID]; exist {
+		check.instance = instance
+		return nil
+	}
+	passCtx, cancel := context.WithCancel(context.Background())
+	check := &consulCheck{instance: instance, cancel: cancel}
+	slf.checks[instance.ID] = check
+	go slf.pass(passCtx, check)
+	return nil
+}
+
+// Deregister 注销服务器实例并停止健康检查
+func (slf *Consul) Deregister(ctx context.Context, id string) error {
+	slf.rw.Lock()
+	if check, exist := slf.checks[id]; exist {
+		check.cancel()
+		delete(slf.checks, id)
+	}
+	slf.rw.Unlock()
+	return slf.do(slf.client.R().SetContext(ctx), resty.MethodPut, "/v1/agent/service/deregister/"+id)
+}
+
+// Instances 获取当前注册中心中健康的服务器实例
+func (slf *Consul) Instances(ctx context.Context) ([]Instance, error) {
+	instances, _, err := slf.query(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+	return sortInstances(instances), nil
+}
+
+// Watch 通过阻塞查询监听注册中心中服务器实例的变化
+func (slf *Consul) Watch(ctx context.Context, handler func(instances []Instance)) error {
+	instances, index, err := slf.query(ctx, 0)
+	if err != nil {
+		return err
+	}
+	handler(sortInstances(instances))
+	go func() {
+		for ctx.Err() == nil {
+			result, next, err := slf.query(ctx, index)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Error("Cluster", log.String("Registry", "Consul"), log.String("Action", "Watch"), log.Err(err))
+				time.Sleep(consulRetryInterval)
+				continue
+			}
+			if next < index {
+				// 索引回退时需要重新开始
+				next = 0
+			}
+			if next != index {
+				index = next
+				handler(sortInstances(result))
+			}
+		}
+	}()
+	return nil
+}
+
+// register 向 consul agent 注册服务
+func (slf *Consul) register(ctx context.Context, instance Instance) error {
+	data, err := json.Marshal(instance)
+	if err != nil {
+		return err
+	}
+	return slf.do(slf.client.R().SetContext(ctx).SetBody(map[string]any{
+		"ID":      instance.ID,
+		"Name":    slf.service,
+		"Address": instance.Addr,
+		"Meta":    map[string]string{consulMetaInstance: string(data)},
+		"Check": map[string]any{
+			"CheckID":                        slf.checkId(instance.ID),
+			"TTL":                            slf.ttl.String(),
+			"Status":                         "passing",
+			"DeregisterCriticalServiceAfter": "1m",
+		},
+	}), resty.MethodPut, "/v1/agent/service/register")
+}
+
+// pass 持续更新健康检查状态，当更新失败时将重新注册
+func (slf *Consul) pass(ctx context.Context, check *consulCheck) {
+	ticker := time.NewTicker(slf.ttl / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			slf.rw.Lock()
+			var instance = check.instance
+			slf.rw.Unlock()
+			err := slf.do(slf.client.R().SetContext(ctx), resty.MethodPut, "/v1/agent/check/pass/"+slf.checkId(instance.ID))
+			if err == nil || ctx.Err() != nil {
+				continue
+			}
+			log.Warn("Cluster", log.String("Registry", "Consul"), log.String("ID", instance.ID), log.String("State", "CheckFailed"), log.Err(err))
+			if err = slf.register(ctx, instance); err != nil && ctx.Err() == nil {
+				log.Error("Cluster", log.String("Registry", "Consul"), log.String("ID", instance.ID), log.String("Action", "Register"), log.Err(err))
+			}
+		}
+	}
+}
+
+// query 查询健康的服务器实例，当 index 大于 0 时将进行阻塞查询
+func (slf *Consul) query(ctx context.Context, index uint64) (map[string]Instance, uint64, error) {
+	var services []consulService
+	request := slf.client.R().SetContext(ctx).SetResult(&services).SetQueryParam("passing", "true")
+	if index > 0 {
+		request.SetQueryParam("index", strconv.FormatUint(index, 10)).SetQueryParam("wait", consulWatchWait.String())
+	}
+	response, err := request.Get("/v1/health/service/" + slf.service)
+	if err != nil {
+		return nil, 0, err
+	}
+	if response.IsError() {
+		return nil, 0, fmt.Errorf("cluster: consul responded %s: %s", response.Status(), response.String())
+	}
+	next, _ := strconv.ParseUint(response.Header().Get("X-Consul-Index"), 10, 64)
+	var instances = make(map[string]Instance, len(services))
+	for _, service := range services {
+		var instance Instance
+		if err := json.Unmarshal([]byte(service.Service.Meta[consulMetaInstance]), &instance); err != nil {
+			log.Error("Cluster", log.String("Registry", "Consul"), log.String("Service", service.Service.ID), log.Err(err))
+			continue
+		}
+		instances[instance.ID] = instance
+	}
+	return instances, next, nil
+}
+
+// do 执行请求并检查响应状态
+func (slf *Consul) do(request *resty.Request, method, url string) error {
+	response, err := request.Execute(method, url)
+	if err != nil {
+		return err
+	}
+	if response.IsError() {
+		return fmt.Errorf("cluster: consul responded %s: %s", response.Status(), response.String())
+	}
+	return nil
+}
+
+// checkId 获取服务器实例的健康检查 ID
+func (slf *Consul) checkId(id string) string {
+	return "service:" + id
+}
@@ -0,0 +1,29 @@
+package cluster
+
+import "time"
+
+// ConsulOption consul 注册中心选项
+type ConsulOption func(c *Consul)
+
+// WithConsulService 设置服务名称，默认为 DefaultConsulService
+func WithConsulService(service string) ConsulOption {
+	return func(c *Consul) {
+		c.service = service
+	}
+}
+
+// WithConsulTTL 设置健康检查 TTL，默认为 DefaultConsulTTL
+func WithConsulTTL(ttl time.Duration) ConsulOption {
+	return func(c *Consul) {
+		if ttl > 0 {
+			c.ttl = ttl
+		}
+	}
+}
+
+// WithConsulToken 设置访问 consul 所使用的 ACL Token
+func WithConsulToken(token string) ConsulOption {
+	return func(c *Consul) {
+		c.client.SetHeader("X-Consul-Token", token)
+	}
+}
@@ -0,0 +1,3 @@
+// Package cluster 提供了基于 etcd、consul 等注册中心的服务发现能力，服务器将自身的 ID、网络类型、地址及负载注册到注册中心中，
+// 以便跨服消息等场景能够根据服务器 ID 动态解析服务器地址，而无需进行静态配置。
+package cluster
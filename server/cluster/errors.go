@@ -0,0 +1,10 @@
+package cluster
+
+import "errors"
+
+var (
+	// ErrInstanceIdEmpty 服务器实例未设置 ID
+	ErrInstanceIdEmpty = errors.New("cluster: instance id is empty")
+	// ErrNoInstance 不存在可用的服务器实例
+	ErrNoInstance = errors.New("cluster: no instance available")
+)
@@ -0,0 +1,213 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/kercylan98/minotaur/utils/log"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultEtcdPrefix = "/minotaur/cluster/" // 默认的 etcd 键前缀
+	DefaultEtcdTTL    = 10                   // 默认的 etcd 租约时长（秒）
+	etcdRetryInterval = time.Second          // 保活或监听中断后的重试间隔
+)
+
+// NewEtcd 创建基于 etcd 的注册中心
+//   - 服务器实例将以 JSON 格式写入 "{prefix}{id}"，并绑定时长为 ttl 的租约
+//   - client 的生命周期由调用方管理
+func NewEtcd(client *clientv3.Client, options ...EtcdOption) *Etcd {
+	e := &Etcd{
+		client: client,
+		prefix: DefaultEtcdPrefix,
+		ttl:    DefaultEtcdTTL,
+		leases: map[string]*etcdLease{},
+	}
+	for _, option := range options {
+		option(e)
+	}
+	return e
+}
+
+// Etcd 基于 etcd 的注册中心
+type Etcd struct {
+	client *clientv3.Client
+	prefix string
+	ttl    int64
+	leases map[string]*etcdLease
+	rw     sync.Mutex
+}
+
+// etcdLease 服务器实例的租约
+type etcdLease struct {
+	id       clientv3.LeaseID
+	instance Instance
+	cancel   context.CancelFunc
+}
+
+// Register 注册或更新服务器实例，首次注册时将创建租约并持续保活，租约丢失时将自动重新注册
+func (slf *Etcd) Register(ctx context.Context, instance Instance) error {
+	if len(instance.ID) == 0 {
+		return ErrInstanceIdEmpty
+	}
+	data, err := json.Marshal(instance)
+	if err != nil {
+		return err
+	}
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	if lease, exist := slf.leases[instance.ID]; exist {
+		lease.instance = instance
+		_, err = slf.client.Put(ctx, slf.prefix+instance.ID, string(data), clientv3.WithLease(lease.id))
+		return err
+	}
+
+	grant, err := slf.client.Grant(ctx, slf.ttl)
+	if err != nil {
+		return err
+	}
+	if _, err = slf.client.Put(ctx, slf.prefix+instance.ID, string(data), clientv3.WithLease(grant.ID)); err != nil {
+		return err
+	}
+	keepAliveCtx, cancel := context.WithCancel(context.Background())
+	lease := &etcdLease{id: grant.ID, instance: instance, cancel: cancel}
+	slf.leases[instance.ID] = lease
+	go slf.keepAlive(keepAliveCtx, lease)
+	return nil
+}
+
+// Deregister 注销服务器实例并撤销租约
+func (slf *Etcd) Deregister(ctx context.Context, id string) error {
+	slf.rw.Lock()
+	lease, exist := slf.leases[id]
+	var leaseId clientv3.LeaseID
+	if exist {
+		delete(slf.leases, id)
+		lease.cancel()
+		leaseId = lease.id
+	}
+	slf.rw.Unlock()
+	if !exist {
+		_, err := slf.client.Delete(ctx, slf.prefix+id)
+		return err
+	}
+	_, err := slf.client.Revoke(ctx, leaseId)
+	return err
+}
+
+// Instances 获取当前注册中心中的所有服务器实例
+func (slf *Etcd) Instances(ctx context.Context) ([]Instance, error) {
+	instances, _, err := slf.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return sortInstances(instances), nil
+}
+
+// Watch 监听注册中心中服务器实例的变化，监听中断时将重新读取全部实例并继续监听
+func (slf *Etcd) Watch(ctx context.Context, handler func(instances []Instance)) error {
+	instances, revision, err := slf.load(ctx)
+	if err != nil {
+		return err
+	}
+	handler(sortInstances(instances))
+	go func() {
+		for {
+			for response := range slf.client.Watch(ctx, slf.prefix, clientv3.WithPrefix(), clientv3.WithRev(revision+1)) {
+				if err := response.Err(); err != nil {
+					log.Error("Cluster", log.String("Registry", "Etcd"), log.String("Action", "Watch"), log.Err(err))
+					break
+				}
+				for _, event := range response.Events {
+					var id = strings.TrimPrefix(string(event.Kv.Key), slf.prefix)
+					switch event.Type {
+					case clientv3.EventTypePut:
+						var instance Instance
+						if err := json.Unmarshal(event.Kv.Value, &instance); err != nil {
+							log.Error("Cluster", log.String("Registry", "Etcd"), log.String("Key", string(event.Kv.Key)), log.Err(err))
+							continue
+						}
+						instances[id] = instance
+					case clientv3.EventTypeDelete:
+						delete(instances, id)
+					}
+				}
+				revision = response.Header.Revision
+				handler(sortInstances(instances))
+			}
+			for ctx.Err() == nil {
+				time.Sleep(etcdRetryInterval)
+				if instances, revision, err = slf.load(ctx); err == nil {
+					handler(sortInstances(instances))
+					break
+				}
+				log.Error("Cluster", log.String("Registry", "Etcd"), log.String("Action", "Watch"), log.Err(err))
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// keepAlive 持续为租约保活，当租约丢失时将重新注册
+//   - 租约已被注销时将不再重新注册
+func (slf *Etcd) keepAlive(ctx context.Context, lease *etcdLease) {
+	for ctx.Err() == nil {
+		slf.rw.Lock()
+		leaseId, id := lease.id, lease.instance.ID
+		slf.rw.Unlock()
+
+		ch, err := slf.client.KeepAlive(ctx, leaseId)
+		if err == nil {
+			for range ch {
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warn("Cluster", log.String("Registry", "Etcd"), log.String("ID", id), log.String("State", "LeaseLost"), log.Err(err))
+		time.Sleep(etcdRetryInterval)
+
+		slf.rw.Lock()
+		if ctx.Err() != nil || slf.leases[id] != lease {
+			slf.rw.Unlock()
+			return
+		}
+		var data []byte
+		var grant *clientv3.LeaseGrantResponse
+		if data, err = json.Marshal(lease.instance); err == nil {
+			if grant, err = slf.client.Grant(ctx, slf.ttl); err == nil {
+				if _, err = slf.client.Put(ctx, slf.prefix+id, string(data), clientv3.WithLease(grant.ID)); err == nil {
+					lease.id = grant.ID
+				}
+			}
+		}
+		if err != nil {
+			log.Error("Cluster", log.String("Registry", "Etcd"), log.String("ID", id), log.String("Action", "Register"), log.Err(err))
+		}
+		slf.rw.Unlock()
+	}
+}
+
+// load 读取全部服务器实例及当前版本
+func (slf *Etcd) load(ctx context.Context) (map[string]Instance, int64, error) {
+	response, err := slf.client.Get(ctx, slf.prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, 0, err
+	}
+	var instances = make(map[string]Instance, len(response.Kvs))
+	for _, kv := range response.Kvs {
+		var instance Instance
+		if err := json.Unmarshal(kv.Value, &instance); err != nil {
+			log.Error("Cluster", log.String("Registry", "Etcd"), log.String("Key", string(kv.Key)), log.Err(err))
+			continue
+		}
+		instances[strings.TrimPrefix(string(kv.Key), slf.prefix)] = instance
+	}
+	return instances, response.Header.Revision, nil
+}
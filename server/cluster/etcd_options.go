@@ -0,0 +1,20 @@
+package cluster
+
+// EtcdOption etcd 注册中心选项
+type EtcdOption func(e *Etcd)
+
+// WithEtcdPrefix 设置键前缀，默认为 DefaultEtcdPrefix
+func WithEtcdPrefix(prefix string) EtcdOption {
+	return func(e *Etcd) {
+		e.prefix = prefix
+	}
+}
+
+// WithEtcdTTL 设置租约时长（秒），默认为 DefaultEtcdTTL
+func WithEtcdTTL(ttl int64) EtcdOption {
+	return func(e *Etcd) {
+		if ttl > 0 {
+			e.ttl = ttl
+		}
+	}
+}
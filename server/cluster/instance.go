@@ -0,0 +1,25 @@
+package cluster
+
+import "sort"
+
+// Instance 注册到注册中心的服务器实例
+type Instance struct {
+	ID       string            `json:"id"`                 // 服务器 ID
+	Group    string            `json:"group,omitempty"`    // 服务器分组，例如 "battle"、"lobby"
+	Network  string            `json:"network"`            // 网络类型
+	Addr     string            `json:"addr"`               // 对外公布的地址
+	Load     float64           `json:"load"`               // 负载，数值越小表示越空闲
+	Metadata map[string]string `json:"metadata,omitempty"` // 元数据
+}
+
+// sortInstances 将服务器实例转换为按照 ID 排序的切片
+func sortInstances(instances map[string]Instance) []Instance {
+	var result = make([]Instance, 0, len(instances))
+	for _, instance := range instances {
+		result = append(result, instance)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
@@ -0,0 +1,39 @@
+package cluster
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"time"
+)
+
+// Option 集群选项
+type Option func(c *Cluster)
+
+// WithPicker 设置 Cluster.Pick 所使用的选择器，默认为 PickLeastLoad
+func WithPicker(picker Picker) Option {
+	return func(c *Cluster) {
+		c.picker = picker
+	}
+}
+
+// WithLoad 设置负载计算函数，默认为服务器的在线连接数量
+func WithLoad(load func(srv *server.Server) float64) Option {
+	return func(c *Cluster) {
+		c.load = load
+	}
+}
+
+// WithReportInterval 设置负载上报间隔，默认为 DefaultReportInterval
+func WithReportInterval(interval time.Duration) Option {
+	return func(c *Cluster) {
+		if interval > 0 {
+			c.reportInterval = interval
+		}
+	}
+}
+
+// WithChangeHandler 设置集群发生变化时的处理函数，将在监听开始及每次发生变化时传入全部的服务器实例
+func WithChangeHandler(handler func(instances []Instance)) Option {
+	return func(c *Cluster) {
+		c.changeHandlers = append(c.changeHandlers, handler)
+	}
+}
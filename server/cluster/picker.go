@@ -0,0 +1,22 @@
+package cluster
+
+import "math/rand"
+
+// Picker 服务器实例选择器，instances 至少包含一个实例
+type Picker func(instances []Instance) Instance
+
+// PickLeastLoad 选择负载最低的服务器实例
+func PickLeastLoad(instances []Instance) Instance {
+	var result = instances[0]
+	for _, instance := range instances[1:] {
+		if instance.Load < result.Load {
+			result = instance
+		}
+	}
+	return result
+}
+
+// PickRandom 随机选择一个服务器实例
+func PickRandom(instances []Instance) Instance {
+	return instances[rand.Intn(len(instances))]
+}
@@ -0,0 +1,17 @@
+package cluster
+
+import "context"
+
+// Registry 注册中心接口
+//   - 官方实现可参考 NewEtcd 及 NewConsul
+type Registry interface {
+	// Register 注册或更新服务器实例，实例将通过租约保活，当服务器异常退出且超过租约时长后将自动从注册中心移除
+	Register(ctx context.Context, instance Instance) error
+	// Deregister 注销服务器实例并停止保活
+	Deregister(ctx context.Context, id string) error
+	// Instances 获取当前注册中心中的所有服务器实例
+	Instances(ctx context.Context) ([]Instance, error)
+	// Watch 监听注册中心中服务器实例的变化，在开始监听及每次发生变化时将通过 handler 传入全部的服务器实例
+	//   - 该函数不会阻塞，监听将持续到 ctx 被取消
+	Watch(ctx context.Context, handler func(instances []Instance)) error
+}
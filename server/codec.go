@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Codec 数据包编解码器，用于处理基于流的网络类型（TCP、Unix、KCP）中数据包的分包与粘包问题
+//   - 通过 WithCodec 设置后，OnConnectionReceivePacketEvent 接收到的始终为完整的逻辑数据包
+//   - 对于同一连接，Decode 不会被并发调用
+type Codec interface {
+	// Encode 对需要发送的数据包进行编码
+	Encode(packet []byte) ([]byte, error)
+	// Decode 从缓冲区中解码出一个完整的数据包，并返回该数据包在缓冲区中所占用的字节数
+	//   - 当缓冲区中的数据不足以构成一个完整的数据包时，应返回 nil, 0, nil
+	//   - 当返回错误时，连接将被关闭
+	Decode(buf []byte) (packet []byte, n int, err error)
+}
+
+// NewLengthFieldCodec 创建一个基于长度字段的编解码器
+//   - 数据包格式为 | length(fieldSize) | packet |，长度字段采用大端序，仅表示 packet 部分的长度
+//   - fieldSize 支持 1、2、4、8，其他值将引发 panic
+//   - maxPacketSize 为允许的最大数据包长度，超出时将返回 ErrCodecPacketTooLarge，当 maxPacketSize <= 0 时表示不限制
+func NewLengthFieldCodec(fieldSize int, maxPacketSize int) *LengthFieldCodec {
+	switch fieldSize {
+	case 1, 2, 4, 8:
+	default:
+		panic(fmt.Errorf("codec: invalid length field size %d", fieldSize))
+	}
+	return &LengthFieldCodec{fieldSize: fieldSize, maxPacketSize: maxPacketSize}
+}
+
+// LengthFieldCodec 基于长度字段的编解码器
+type LengthFieldCodec struct {
+	fieldSize     int
+	maxPacketSize int
+}
+
+// Encode 对数据包进行编码
+func (slf *LengthFieldCodec) Encode(packet []byte) ([]byte, error) {
+	var size = uint64(len(packet))
+	if (slf.maxPacketSize > 0 && len(packet) > slf.maxPacketSize) || (slf.fieldSize < 8 && size >= 1<<(slf.fieldSize*8)) {
+		return nil, ErrCodecPacketTooLarge
+	}
+	var result = make([]byte, slf.fieldSize, slf.fieldSize+len(packet))
+	switch slf.fieldSize {
+	case 1:
+		result[0] = byte(size)
+	case 2:
+		binary.BigEndian.PutUint16(result, uint16(size))
+	case 4:
+		binary.BigEndian.PutUint32(result, uint32(size))
+	case 8:
+		binary.BigEndian.PutUint64(result, size)
+	}
+	return append(result, packet...), nil
+}
+
+// Decode 对数据包进行解码
+func (slf *LengthFieldCodec) Decode(buf []byte) (packet []byte, n int, err error) {
+	if len(buf) < slf.fieldSize {
+		return nil, 0, nil
+	}
+	var size uint64
+	switch slf.fieldSize {
+	case 1:
+		size = uint64(buf[0])
+	case 2:
+		size = uint64(binary.BigEndian.Uint16(buf))
+	case 4:
+		size = uint64(binary.BigEndian.Uint32(buf))
+	case 8:
+		size = binary.BigEndian.Uint64(buf)
+	}
+	if slf.maxPacketSize > 0 && size > uint64(slf.maxPacketSize) {
+		return nil, 0, ErrCodecPacketTooLarge
+	}
+	if uint64(len(buf)-slf.fieldSize) < size {
+		return nil, 0, nil
+	}
+	n = slf.fieldSize + int(size)
+	return buf[slf.fieldSize:n], n, nil
+}
+
+// NewDelimiterCodec 创建一个基于分隔符的编解码器
+//   - 编码时将在数据包末尾追加分隔符，解码时将以分隔符切分数据包，返回的数据包不包含分隔符
+//   - maxPacketSize 为允许的最大数据包长度，当缓冲区中超过该长度仍未找到分隔符时将返回 ErrCodecPacketTooLarge，当 maxPacketSize <= 0 时表示不限制
+func NewDelimiterCodec(delimiter []byte, maxPacketSize int) *DelimiterCodec {
+	if len(delimiter) == 0 {
+		panic(errors.New("codec: delimiter must not be empty"))
+	}
+	return &DelimiterCodec{delimiter: bytes.Clone(delimiter), maxPacketSize: maxPacketSize}
+}
+
+// NewLineCodec 创建一个以换行符 "\n" 作为分隔符的编解码器
+//   - 解码时将同时去除行尾的 "\r"
+func NewLineCodec(maxPacketSize int) *DelimiterCodec {
+	codec := NewDelimiterCodec([]byte{'\n'}, maxPacketSize)
+	codec.trimCR = true
+	return codec
+}
+
+// DelimiterCodec 基于分隔符的编解码器
+type DelimiterCodec struct {
+	delimiter     []byte
+	maxPacketSize int
+	trimCR        bool
+}
+
+// Encode 对数据包进行编码
+func (slf *DelimiterCodec) Encode(packet []byte) ([]byte, error) {
+	if slf.maxPacketSize > 0 && len(packet) > slf.maxPacketSize {
+		return nil, ErrCodecPacketTooLarge
+	}
+	var result = make([]byte, 0, len(packet)+len(slf.delimiter))
+	result = append(result, packet...)
+	return append(result, slf.delimiter...), nil
+}
+
+// Decode 对数据包进行解码
+func (slf *DelimiterCodec) Decode(buf []byte) (packet []byte, n int, err error) {
+	index := bytes.Index(buf, slf.delimiter)
+	if index == -1 {
+		if slf.maxPacketSize > 0 && len(buf) > slf.maxPacketSize {
+			return nil, 0, ErrCodecPacketTooLarge
+		}
+		return nil, 0, nil
+	}
+	if slf.maxPacketSize > 0 && index > slf.maxPacketSize {
+		return nil, 0, ErrCodecPacketTooLarge
+	}
+	packet = buf[:index]
+	if slf.trimCR && len(packet) > 0 && packet[len(packet)-1] == '\r' {
+		packet = packet[:len(packet)-1]
+	}
+	return packet, index + len(slf.delimiter), nil
+}
@@ -0,0 +1,56 @@
+package server_test
+
+import (
+	"bytes"
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+)
+
+func TestLengthFieldCodec(t *testing.T) {
+	codec := server.NewLengthFieldCodec(2, 0)
+	var stream []byte
+	for _, packet := range []string{"hello", "", "minotaur"} {
+		encoded, err := codec.Encode([]byte(packet))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream = append(stream, encoded...)
+	}
+
+	var packets []string
+	for i := 1; i <= len(stream); i++ {
+		// 模拟数据逐字节到达的情况
+		buf := stream[:i]
+		for {
+			packet, n, err := codec.Decode(buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n == 0 {
+				break
+			}
+			if i == len(stream) {
+				packets = append(packets, string(packet))
+			}
+			buf = buf[n:]
+		}
+	}
+	if len(packets) != 3 || packets[0] != "hello" || packets[1] != "" || packets[2] != "minotaur" {
+		t.Fatalf("unexpected packets: %q", packets)
+	}
+
+	if _, _, err := server.NewLengthFieldCodec(1, 4).Decode([]byte{5, 1, 2, 3, 4, 5}); err != server.ErrCodecPacketTooLarge {
+		t.Fatalf("expected ErrCodecPacketTooLarge, got %v", err)
+	}
+}
+
+func TestLineCodec(t *testing.T) {
+	codec := server.NewLineCodec(0)
+	packet, n, err := codec.Decode([]byte("ping\r\npong"))
+	if err != nil || n != 6 || !bytes.Equal(packet, []byte("ping")) {
+		t.Fatalf("unexpected decode result: %q %d %v", packet, n, err)
+	}
+	if packet, n, err = codec.Decode([]byte("pong")); packet != nil || n != 0 || err != nil {
+		t.Fatalf("unexpected decode result for partial line: %q %d %v", packet, n, err)
+	}
+}
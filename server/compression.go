@@ -0,0 +1,133 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"io"
+)
+
+const (
+	CompressionGzip   CompressionAlgorithm = iota + 1 // gzip 压缩算法
+	CompressionSnappy                                 // snappy 压缩算法，压缩率较低但速度极快
+	CompressionZstd                                   // zstd 压缩算法，兼顾压缩率与速度
+)
+
+const (
+	compressionHeaderRaw      byte = 0        // 数据包头：未压缩
+	maxDecompressedPacketSize      = 64 << 20 // 解压后数据包的最大长度，用于防止解压炸弹
+)
+
+var compressionAlgorithmNames = map[CompressionAlgorithm]string{
+	CompressionGzip:   "Gzip",
+	CompressionSnappy: "Snappy",
+	CompressionZstd:   "Zstd",
+}
+
+// CompressionAlgorithm 数据包压缩算法
+type CompressionAlgorithm byte
+
+// String 返回压缩算法的字符串表示
+func (slf CompressionAlgorithm) String() string {
+	return compressionAlgorithmNames[slf]
+}
+
+// newPacketCompression 创建数据包压缩器，当压缩算法不受支持时将引发 panic
+func newPacketCompression(threshold int, algorithm CompressionAlgorithm) *packetCompression {
+	c := &packetCompression{threshold: threshold, algorithm: algorithm}
+	switch algorithm {
+	case CompressionGzip, CompressionSnappy:
+	case CompressionZstd:
+		var err error
+		if c.zstdEncoder, err = zstd.NewWriter(nil); err != nil {
+			panic(err)
+		}
+		if c.zstdDecoder, err = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedPacketSize)); err != nil {
+			panic(err)
+		}
+	default:
+		panic(fmt.Errorf("compression: unsupported algorithm %d", algorithm))
+	}
+	return c
+}
+
+// packetCompression 数据包压缩器
+//   - 数据包格式为 | header(1) | packet |，header 为 0 时表示未压缩，否则为压缩所使用的 CompressionAlgorithm
+type packetCompression struct {
+	threshold   int
+	algorithm   CompressionAlgorithm
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+}
+
+// compress 对数据包进行压缩，长度未超过阈值或压缩后长度未减少的数据包将不会被压缩
+func (slf *packetCompression) compress(packet []byte) ([]byte, error) {
+	if len(packet) > slf.threshold {
+		var buf = bytes.NewBuffer(make([]byte, 0, len(packet)/2+1))
+		buf.WriteByte(byte(slf.algorithm))
+		switch slf.algorithm {
+		case CompressionGzip:
+			w := gzip.NewWriter(buf)
+			if _, err := w.Write(packet); err != nil {
+				return nil, err
+			}
+			if err := w.Close(); err != nil {
+				return nil, err
+			}
+		case CompressionSnappy:
+			buf.Write(snappy.Encode(nil, packet))
+		case CompressionZstd:
+			buf.Write(slf.zstdEncoder.EncodeAll(packet, nil))
+		}
+		if buf.Len() < len(packet)+1 {
+			return buf.Bytes(), nil
+		}
+	}
+	var result = make([]byte, 0, len(packet)+1)
+	result = append(result, compressionHeaderRaw)
+	return append(result, packet...), nil
+}
+
+// decompress 根据数据包头对数据包进行解压
+func (slf *packetCompression) decompress(packet []byte) ([]byte, error) {
+	if len(packet) == 0 {
+		return nil, ErrCompressionInvalidPacket
+	}
+	var header, data = packet[0], packet[1:]
+	switch CompressionAlgorithm(header) {
+	case CompressionAlgorithm(compressionHeaderRaw):
+		return data, nil
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		result, err := io.ReadAll(io.LimitReader(r, maxDecompressedPacketSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(result) > maxDecompressedPacketSize {
+			return nil, ErrCompressionPacketTooLarge
+		}
+		return result, nil
+	case CompressionSnappy:
+		size, err := snappy.DecodedLen(data)
+		if err != nil {
+			return nil, err
+		}
+		if size > maxDecompressedPacketSize {
+			return nil, ErrCompressionPacketTooLarge
+		}
+		return snappy.Decode(nil, data)
+	case CompressionZstd:
+		if slf.zstdDecoder == nil {
+			return nil, ErrCompressionInvalidPacket
+		}
+		return slf.zstdDecoder.DecodeAll(data, nil)
+	default:
+		return nil, ErrCompressionInvalidPacket
+	}
+}
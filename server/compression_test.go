@@ -0,0 +1,36 @@
+package server
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPacketCompression(t *testing.T) {
+	var large = bytes.Repeat([]byte("minotaur"), 128)
+	for _, algorithm := range []CompressionAlgorithm{CompressionGzip, CompressionSnappy, CompressionZstd} {
+		c := newPacketCompression(64, algorithm)
+		for _, packet := range [][]byte{[]byte("small"), large} {
+			compressed, err := c.compress(packet)
+			if err != nil {
+				t.Fatalf("%s: %v", algorithm, err)
+			}
+			if len(packet) > 64 && (compressed[0] != byte(algorithm) || len(compressed) >= len(packet)) {
+				t.Fatalf("%s: expected packet to be compressed", algorithm)
+			}
+			if len(packet) <= 64 && compressed[0] != compressionHeaderRaw {
+				t.Fatalf("%s: expected small packet to stay raw", algorithm)
+			}
+			decompressed, err := c.decompress(compressed)
+			if err != nil {
+				t.Fatalf("%s: %v", algorithm, err)
+			}
+			if !bytes.Equal(decompressed, packet) {
+				t.Fatalf("%s: round trip mismatch", algorithm)
+			}
+		}
+	}
+
+	if _, err := newPacketCompression(0, CompressionGzip).decompress([]byte{0xFF, 1}); err != ErrCompressionInvalidPacket {
+		t.Fatalf("expected ErrCompressionInvalidPacket, got %v", err)
+	}
+}
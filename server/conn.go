@@ -7,17 +7,19 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/kercylan98/minotaur/server/writeloop"
 	"github.com/kercylan98/minotaur/utils/concurrent"
-	"github.com/kercylan98/minotaur/utils/hash"
 	"github.com/kercylan98/minotaur/utils/log"
 	"github.com/kercylan98/minotaur/utils/random"
 	"github.com/kercylan98/minotaur/utils/timer"
 	"github.com/panjf2000/gnet"
+	"github.com/quic-go/webtransport-go"
 	"github.com/xtaci/kcp-go/v5"
+	"golang.org/x/time/rate"
 	"io"
 	"net"
 	"net/http"
 	"os"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -32,10 +34,32 @@ func newKcpConn(server *Server, session *kcp.UDPSession) *Conn {
 		ctx: server.ctx,
 		connection: &connection{
 			server:     server,
+			network:    NetworkKcp,
 			remoteAddr: session.RemoteAddr(),
 			ip:         session.RemoteAddr().String(),
 			kcp:        session,
-			data:       map[any]any{},
+			data:       concurrent.NewBalanceMap[any, any](),
+			openTime:   time.Now(),
+		},
+	}
+	if index := strings.LastIndex(c.ip, ":"); index != -1 {
+		c.ip = c.ip[0:index]
+	}
+	c.init()
+	return c
+}
+
+// newNetConn 创建一个处理标准库连接的连接，用于启用热重启时的 TCP 及 Unix 连接
+func newNetConn(server *Server, network Network, conn net.Conn) *Conn {
+	c := &Conn{
+		ctx: server.ctx,
+		connection: &connection{
+			server:     server,
+			network:    network,
+			remoteAddr: conn.RemoteAddr(),
+			ip:         conn.RemoteAddr().String(),
+			nc:         conn,
+			data:       concurrent.NewBalanceMap[any, any](),
 			openTime:   time.Now(),
 		},
 	}
@@ -47,15 +71,16 @@ func newKcpConn(server *Server, session *kcp.UDPSession) *Conn {
 }
 
 // newKcpConn 创建一个处理GNet的连接
-func newGNetConn(server *Server, conn gnet.Conn) *Conn {
+func newGNetConn(server *Server, network Network, conn gnet.Conn) *Conn {
 	c := &Conn{
 		ctx: server.ctx,
 		connection: &connection{
 			server:     server,
+			network:    network,
 			remoteAddr: conn.RemoteAddr(),
 			ip:         conn.RemoteAddr().String(),
 			gn:         conn,
-			data:       map[any]any{},
+			data:       concurrent.NewBalanceMap[any, any](),
 			openTime:   time.Now(),
 		},
 	}
@@ -72,10 +97,48 @@ func newWebsocketConn(server *Server, ws *websocket.Conn, ip string) *Conn {
 		ctx: server.ctx,
 		connection: &connection{
 			server:     server,
+			network:    NetworkWebsocket,
 			remoteAddr: ws.RemoteAddr(),
 			ip:         ip,
 			ws:         ws,
-			data:       map[any]any{},
+			data:       concurrent.NewBalanceMap[any, any](),
+			openTime:   time.Now(),
+		},
+	}
+	c.init()
+	return c
+}
+
+// newWebTransportConn 创建一个处理WebTransport的连接
+func newWebTransportConn(server *Server, session *webtransport.Session, ip string) *Conn {
+	c := &Conn{
+		ctx: server.ctx,
+		connection: &connection{
+			server:     server,
+			network:    NetworkWebTransport,
+			remoteAddr: session.RemoteAddr(),
+			ip:         ip,
+			wt:         session,
+			data:       concurrent.NewBalanceMap[any, any](),
+			openTime:   time.Now(),
+		},
+	}
+	c.init()
+	return c
+}
+
+// NewGatewayConn 创建一个由网关转发的虚拟连接，对该连接的写入将直接交由 writer 处理，而不会经过编解码、压缩及加密
+//   - 虚拟连接不具备实际的网络连接，接收到的数据包需要通过 Server.PushPacketMessage 进行推送
+//   - 创建后需要通过 Server.OnConnectionOpenedEvent 使其上线，关闭时将正常触发 ConnectionClosedEvent
+func NewGatewayConn(server *Server, ip string, writer func(packet []byte)) *Conn {
+	c := &Conn{
+		ctx: server.ctx,
+		connection: &connection{
+			server:     server,
+			remoteAddr: &net.TCPAddr{IP: net.ParseIP(ip)},
+			ip:         ip,
+			gw:         writer,
+			data:       concurrent.NewBalanceMap[any, any](),
 			openTime:   time.Now(),
 		},
 	}
@@ -90,14 +153,15 @@ func newBotConn(server *Server) *Conn {
 	c := &Conn{
 		ctx: server.ctx,
 		connection: &connection{
-			server: server,
+			server:  server,
+			network: server.network,
 			remoteAddr: &net.TCPAddr{
 				IP:   ip,
 				Port: port,
 				Zone: "",
 			},
 			ip:       fmt.Sprintf("BOT:%s:%d", ip.String(), port),
-			data:     map[any]any{},
+			data:     concurrent.NewBalanceMap[any, any](),
 			openTime: time.Now(),
 		},
 	}
@@ -109,29 +173,50 @@ func newBotConn(server *Server) *Conn {
 // Conn 服务器连接单次消息的包装
 type Conn struct {
 	*connection
-	wst int
-	ctx context.Context
+	wst    int
+	ctx    context.Context
+	route  string
+	buffer *packetBuffer // 当前数据包所在的缓冲区
 }
 
 // connection 长久保持的连接
 type connection struct {
-	server      *Server
-	ticker      *timer.Ticker
-	remoteAddr  net.Addr
-	ip          string
-	ws          *websocket.Conn
-	gn          gnet.Conn
-	kcp         *kcp.UDPSession
-	gw          func(packet []byte)
-	data        map[any]any
-	closed      bool
-	pool        *concurrent.Pool[*connPacket]
-	loop        *writeloop.WriteLoop[*connPacket]
-	mu          sync.Mutex
-	openTime    time.Time
-	delay       time.Duration
-	fluctuation time.Duration
-	botWriter   atomic.Pointer[io.Writer]
+	server        *Server
+	network       Network
+	id            string
+	ticker        *timer.Ticker
+	remoteAddr    net.Addr
+	ip            string
+	ws            *websocket.Conn
+	gn            gnet.Conn
+	nc            net.Conn
+	kcp           *kcp.UDPSession
+	wt            *webtransport.Session
+	wtStream      atomic.Pointer[webtransport.Stream]
+	gw            func(packet []byte)
+	data          *concurrent.BalanceMap[any, any]
+	closed        bool
+	pool          *concurrent.Pool[*connPacket]
+	loop          *writeloop.WriteLoop[*connPacket]
+	mu            sync.Mutex
+	openTime      time.Time
+	delay         time.Duration
+	fluctuation   time.Duration
+	botWriter     atomic.Pointer[io.Writer]
+	codecBuffer   []byte
+	lastActive    atomic.Int64
+	filtered      bool
+	packetLimiter *rate.Limiter
+	byteLimiter   *rate.Limiter
+	rateLimitedAt atomic.Int64
+	crypto        *packetCryptoSession
+	wsPattern     string
+	authState     atomic.Int32
+	authTimer     *time.Timer
+	player        atomic.Pointer[any]
+	coalescer     *writeCoalescer
+	packetLogged  atomic.Bool
+	sequence      atomic.Pointer[packetSequence]
 }
 
 // Ticker 获取定时器
@@ -161,7 +246,7 @@ func (slf *Conn) GetWebsocketRequest() *http.Request {
 
 // IsBot 是否是机器人连接
 func (slf *Conn) IsBot() bool {
-	return slf != nil && slf.ws == nil && slf.gn == nil && slf.kcp == nil && slf.gw == nil
+	return slf != nil && slf.ws == nil && slf.gn == nil && slf.nc == nil && slf.kcp == nil && slf.wt == nil && slf.gw == nil
 }
 
 // RemoteAddr 获取远程地址
@@ -170,12 +255,10 @@ func (slf *Conn) RemoteAddr() net.Addr {
 }
 
 // GetID 获取连接ID
-//   - 为远程地址的字符串形式
+//   - 由服务器在连接建立时生成的单调递增的唯一标识，同一服务器内不会重复，即便多个客户端处于同一 NAT 之后
+//   - 如需获取远程地址或 IP，请使用 RemoteAddr 或 GetIP
 func (slf *Conn) GetID() string {
-	if slf.IsBot() {
-		return slf.ip
-	}
-	return slf.remoteAddr.String()
+	return slf.id
 }
 
 // GetIP 获取连接IP
@@ -191,19 +274,21 @@ func (slf *Conn) IsClosed() bool {
 }
 
 // SetData 设置连接数据，该数据将在连接关闭前始终存在
+//   - 连接数据是并发安全的，并将在 ConnectionClosedEvent 执行完毕后自动释放
+//   - 需要类型安全的存取时可使用 Store 及 Load 函数
 func (slf *Conn) SetData(key, value any) *Conn {
-	slf.data[key] = value
+	slf.data.Set(key, value)
 	return slf
 }
 
 // GetData 获取连接数据
 func (slf *Conn) GetData(key any) any {
-	return slf.data[key]
+	return slf.data.Get(key)
 }
 
 // ViewData 查看只读的连接数据
 func (slf *Conn) ViewData() map[any]any {
-	return hash.Copy(slf.data)
+	return slf.data.Map()
 }
 
 // SetMessageData 设置消息数据，该数据将在消息处理完成后释放
@@ -212,6 +297,22 @@ func (slf *Conn) SetMessageData(key, value any) *Conn {
 	return slf
 }
 
+// Context 获取当前消息的上下文，当通过 WithMessageExecTimeout 设置执行超时时，上下文将在消息执行超时后被取消
+//   - 仅在 ConnectionReceivePacketEvent 等处理 MessageTypePacket 消息的过程中有效，耗时较长的处理函数可通过检查 Context().Done() 主动中断执行
+func (slf *Conn) Context() context.Context {
+	if slf.ctx == nil {
+		return slf.server.ctx
+	}
+	return slf.ctx
+}
+
+// SetMessageRoute 设置当前消息的路由名称，用于慢消息日志及消息执行耗时统计，通过 Router 分发时将自动设置
+//   - 仅在处理 MessageTypePacket 消息的过程中有效
+func (slf *Conn) SetMessageRoute(route string) *Conn {
+	slf.route = route
+	return slf
+}
+
 // GetMessageData 获取消息数据
 func (slf *Conn) GetMessageData(key any) any {
 	return slf.ctx.Value(key)
@@ -219,15 +320,23 @@ func (slf *Conn) GetMessageData(key any) any {
 
 // ReleaseData 释放数据
 func (slf *Conn) ReleaseData() *Conn {
-	for k := range slf.data {
-		delete(slf.data, k)
-	}
+	slf.data.Clear()
 	return slf
 }
 
 // IsWebsocket 是否是websocket连接
 func (slf *Conn) IsWebsocket() bool {
-	return slf.server.network == NetworkWebsocket
+	return slf.network == NetworkWebsocket
+}
+
+// GetWebsocketPattern 获取 Websocket 连接所属的路由，例如 "/ws"，非 Websocket 连接将返回空字符串
+func (slf *Conn) GetWebsocketPattern() string {
+	return slf.wsPattern
+}
+
+// GetNetwork 获取连接所属的网络类型，当服务器存在多个侦听器时可用于区分连接的来源
+func (slf *Conn) GetNetwork() Network {
+	return slf.network
 }
 
 // GetWST 获取本次 websocket 消息类型
@@ -256,12 +365,67 @@ func (slf *Conn) PushUniqueAsyncMessage(name string, caller func() error, callba
 }
 
 // Write 向连接中写入数据
+//   - 数据包在发送前将依次经过 ConnectionWritePacketBeforeEvent、压缩、加密及编码的处理
+//   - 当 ConnectionWritePacketBeforeEvent 丢弃数据包时，callback 将接收到 ErrPacketDropped
 func (slf *Conn) Write(packet []byte, callback ...func(err error)) {
+	var drop bool
+	if packet, drop = slf.server.OnConnectionWritePacketBeforeEvent(slf, packet); drop {
+		if len(callback) > 0 {
+			callback[0](ErrPacketDropped)
+		}
+		return
+	}
+	slf.server.metrics.send(packet)
+	slf.server.capture.record(CaptureDirectionOutbound, slf, packet)
+	slf.server.packetLogging.record(CaptureDirectionOutbound, slf, packet)
 	if slf.gw != nil {
 		slf.gw(packet)
 		return
 	}
-	packet = slf.server.OnConnectionWritePacketBeforeEvent(slf, packet)
+	if slf.server.compression != nil && !slf.IsBot() {
+		var err error
+		if packet, err = slf.server.compression.compress(packet); err != nil {
+			if len(callback) > 0 {
+				callback[0](err)
+			}
+			return
+		}
+	}
+	var cb func(err error)
+	if len(callback) > 0 {
+		cb = callback[0]
+	}
+	if slf.crypto != nil {
+		slf.crypto.write(slf, packet, cb)
+		return
+	}
+	slf.write(packet, cb)
+}
+
+// WriteWithType 以特定的 Websocket 消息类型向连接中写入数据，仅支持 WebsocketMessageTypeText 及 WebsocketMessageTypeBinary
+//   - 当消息类型不被支持时，callback 将接收到 ErrWebsocketIllegalMessageType
+//   - 对于非 Websocket 连接，消息类型将被忽略，与 Write 一致
+func (slf *Conn) WriteWithType(messageType int, packet []byte, callback ...func(err error)) {
+	if messageType != WebsocketMessageTypeText && messageType != WebsocketMessageTypeBinary {
+		if len(callback) > 0 {
+			callback[0](ErrWebsocketIllegalMessageType)
+		}
+		return
+	}
+	(&Conn{wst: messageType, connection: slf.connection}).Write(packet, callback...)
+}
+
+// write 对数据包进行编码后放入写入队列
+func (slf *Conn) write(packet []byte, callback func(err error)) {
+	if slf.server.codec != nil && !slf.IsBot() {
+		var err error
+		if packet, err = slf.server.codec.Encode(packet); err != nil {
+			if callback != nil {
+				callback(err)
+			}
+			return
+		}
+	}
 	slf.mu.Lock()
 	defer slf.mu.Unlock()
 	if slf.closed {
@@ -270,13 +434,25 @@ func (slf *Conn) Write(packet []byte, callback ...func(err error)) {
 	cp := slf.pool.Get()
 	cp.wst = slf.GetWST()
 	cp.packet = packet
-	if len(callback) > 0 {
-		cp.callback = callback[0]
-	}
+	cp.callback = callback
 	slf.loop.Put(cp)
 }
 
 func (slf *Conn) init() {
+	slf.id = strconv.FormatInt(slf.server.connIdGenerator.Add(1), 10)
+	slf.packetLimiter = slf.server.packetRateLimit.newLimiter()
+	slf.byteLimiter = slf.server.packetByteRateLimit.newLimiter()
+	slf.packetLogged.Store(slf.server.packetLogging.sample())
+	if slf.server.packetSequenceWindow > 0 && !slf.IsBot() && slf.gw == nil {
+		slf.sequence.Store(newPacketSequence(slf.server.packetSequenceWindow))
+	}
+	if slf.server.packetCrypto != 0 && !slf.IsBot() && slf.gw == nil {
+		var err error
+		if slf.crypto, err = newPacketCryptoSession(slf.server.packetCrypto); err != nil {
+			panic(err)
+		}
+	}
+	slf.refreshActive()
 	if slf.server.ticker != nil {
 		if slf.server.tickerAutonomy {
 			slf.ticker = timer.GetTicker(slf.server.connTickerSize)
@@ -293,15 +469,27 @@ func (slf *Conn) init() {
 			data.wst = 0
 			data.packet = nil
 			data.callback = nil
+			data.flush = false
 		},
 	)
 	slf.loop = writeloop.NewWriteLoop[*connPacket](slf.pool, func(data *connPacket) error {
 		if slf.server.runtime.packetWarnSize > 0 && len(data.packet) > slf.server.runtime.packetWarnSize {
-			log.Warn("Conn.Write", log.String("State", "PacketWarn"), log.String("Reason", "PacketSize"), log.String("ID", slf.GetID()), log.Int("PacketSize", len(data.packet)))
+			slf.server.Logger().Warn("Conn.Write", log.String("State", "PacketWarn"), log.String("Reason", "PacketSize"), log.String("ID", slf.GetID()), log.Int("PacketSize", len(data.packet)))
+		}
+		if data.flush {
+			if slf.coalescer != nil {
+				if err := slf.coalescer.flush(); err != nil {
+					return err
+				}
+			}
+			data.callback(nil)
+			return nil
 		}
 		var err error
-		if slf.delay > 0 || slf.fluctuation > 0 {
-			time.Sleep(random.Duration(int64(slf.delay-slf.fluctuation), int64(slf.delay+slf.fluctuation)))
+		if slf.IsBot() {
+			if slf.delay > 0 || slf.fluctuation > 0 {
+				time.Sleep(random.Duration(int64(slf.delay-slf.fluctuation), int64(slf.delay+slf.fluctuation)))
+			}
 			_, err = (*slf.botWriter.Load()).Write(data.packet)
 			if data.callback != nil {
 				data.callback(err)
@@ -309,30 +497,103 @@ func (slf *Conn) init() {
 			return err
 		}
 		if slf.IsWebsocket() {
-			err = slf.ws.WriteMessage(data.wst, data.packet)
-		} else {
-			if slf.gn != nil {
-				switch slf.server.network {
-				case NetworkUdp, NetworkUdp4, NetworkUdp6:
-					err = slf.gn.SendTo(data.packet)
-				default:
-					err = slf.gn.AsyncWrite(data.packet)
+			var wst = data.wst
+			if wst == 0 {
+				// 非消息处理过程中获取的连接不具备消息类型，以 WithWebsocketWriteMessageType 设置的消息类型发送，默认为二进制消息
+				if wst = slf.server.websocketWriteMessageType; wst == 0 {
+					wst = WebsocketMessageTypeBinary
 				}
-			} else if slf.kcp != nil {
-				_, err = slf.kcp.Write(data.packet)
 			}
+			err = slf.ws.WriteMessage(wst, data.packet)
+		} else if slf.coalescer != nil {
+			return slf.coalescer.add(data.packet, data.callback)
+		} else {
+			err = slf.writeConn(data.packet)
 		}
 		if data.callback != nil {
 			data.callback(err)
 		}
 		return err
 	}, func(err any) {
-		slf.Close(errors.New(fmt.Sprint(err)))
+		slf.CloseWithReason(CloseReasonWriteError, errors.New(fmt.Sprint(err)))
 	})
+	if slf.server.writeCoalesce != nil && ((slf.gn != nil && !isUDPNetwork(slf.network)) || slf.nc != nil || slf.kcp != nil || slf.wt != nil) {
+		slf.coalescer = newWriteCoalescer(slf.server.writeCoalesce, slf.writeConn, func(err error) {
+			slf.CloseWithReason(CloseReasonWriteError, err)
+		})
+	}
+}
+
+// writeConn 将数据包写入除 Websocket 外的底层连接
+func (slf *Conn) writeConn(packet []byte) (err error) {
+	if slf.gn != nil {
+		if isUDPNetwork(slf.network) {
+			err = slf.gn.SendTo(packet)
+		} else {
+			err = slf.gn.AsyncWrite(packet)
+		}
+	} else if slf.nc != nil {
+		_, err = slf.nc.Write(packet)
+	} else if slf.kcp != nil {
+		_, err = slf.kcp.Write(packet)
+	} else if slf.wt != nil {
+		if stream := slf.wtStream.Load(); stream != nil {
+			_, err = (*stream).Write(packet)
+		} else {
+			err = ErrWebTransportStreamNotReady
+		}
+	}
+	return err
+}
+
+// Kick 将连接踢出服务器，将触发 ConnectionKickedEvent
+//   - code 为踢出原因码，payload 为可选的最后一个数据包，将在连接关闭前发送
+//   - 连接将在写入队列中的数据发送完成后以 CloseReasonKicked 关闭，最长等待 DefaultKickFlushTimeout
+func (slf *Conn) Kick(code int, payload []byte) {
+	slf.server.OnConnectionKickedEvent(slf, code, payload)
+}
+
+// flushAndClose 写入最后一个数据包，并在写入队列中的数据发送完成后关闭连接
+func (slf *Conn) flushAndClose(packet []byte, reason CloseReason, err error) {
+	var once sync.Once
+	var closer = func() {
+		once.Do(func() {
+			slf.CloseWithReason(reason, err)
+		})
+	}
+	if slf.gw != nil {
+		if len(packet) > 0 {
+			slf.Write(packet)
+		}
+		closer()
+		return
+	}
+	time.AfterFunc(DefaultKickFlushTimeout, closer)
+	if len(packet) > 0 {
+		slf.Write(packet)
+	}
+	slf.mu.Lock()
+	defer slf.mu.Unlock()
+	if slf.closed {
+		return
+	}
+	// 写入队列按顺序发送，刷新标记的回调触发时意味着此前的数据均已发送完成
+	cp := slf.pool.Get()
+	cp.flush = true
+	cp.callback = func(error) {
+		go closer()
+	}
+	slf.loop.Put(cp)
 }
 
-// Close 关闭连接
+// Close 关闭连接，关闭原因为 CloseReasonServerClose
 func (slf *Conn) Close(err ...error) {
+	slf.CloseWithReason(CloseReasonServerClose, err...)
+}
+
+// CloseWithReason 以特定的原因关闭连接，关闭原因将通过 ConnectionClosedEvent 传递
+//   - 当连接已关闭时将不会产生任何效果，关闭原因以首次关闭时为准
+func (slf *Conn) CloseWithReason(reason CloseReason, err ...error) {
 	slf.mu.Lock()
 	if slf.closed {
 		slf.mu.Unlock()
@@ -340,18 +601,37 @@ func (slf *Conn) Close(err ...error) {
 	}
 	defer func() {
 		if err := recover(); err != nil {
-			log.Error("Conn.Close", log.String("State", "Panic"), log.Any("Error", err))
+			slf.server.Logger().Error("Conn.Close", log.String("State", "Panic"), log.Any("Error", err))
 			debug.PrintStack()
 			slf.mu.Unlock()
 		}
 	}()
 	slf.closed = true
+	if slf.filtered {
+		slf.server.connFilter.release(slf.ip)
+	}
+	var notify = func() {}
+	if slf.coalescer != nil {
+		// 在关闭底层连接前写入尚未合并写入的数据，避免关闭前写入的最后一个数据包丢失
+		notify = slf.coalescer.close()
+	}
 	if slf.ws != nil {
-		_ = slf.ws.Close()
+		var e error
+		if len(err) > 0 {
+			e = err[0]
+		}
+		slf.closeWebsocket(reason, e)
 	} else if slf.gn != nil {
-		_ = slf.gn.Close()
+		// UDP 虚拟会话共享侦听器的套接字，不能关闭
+		if !isUDPNetwork(slf.network) {
+			_ = slf.gn.Close()
+		}
+	} else if slf.nc != nil {
+		_ = slf.nc.Close()
 	} else if slf.kcp != nil {
 		_ = slf.kcp.Close()
+	} else if slf.wt != nil {
+		_ = slf.wt.CloseWithError(0, "")
 	}
 	if slf.ticker != nil {
 		slf.ticker.Release()
@@ -360,9 +640,10 @@ func (slf *Conn) Close(err ...error) {
 	slf.pool.Close()
 	slf.loop.Close()
 	slf.mu.Unlock()
+	notify()
 	if len(err) > 0 {
-		slf.server.OnConnectionClosedEvent(slf, err[0])
+		slf.server.OnConnectionClosedEvent(slf, reason, err[0])
 		return
 	}
-	slf.server.OnConnectionClosedEvent(slf, nil)
+	slf.server.OnConnectionClosedEvent(slf, reason, nil)
 }
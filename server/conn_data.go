@@ -0,0 +1,38 @@
+package server
+
+// Store 以类型安全的方式设置连接数据，与 Conn.SetData 共享同一份数据
+//   - 例如：server.Store(conn, "level", 10)
+func Store[T any](conn *Conn, key any, value T) {
+	conn.data.Set(key, value)
+}
+
+// Load 以类型安全的方式获取连接数据，当数据不存在或类型不匹配时 ok 将为 false
+//   - 例如：level, ok := server.Load[int](conn, "level")
+func Load[T any](conn *Conn, key any) (value T, ok bool) {
+	v, exist := conn.data.GetExist(key)
+	if !exist {
+		return value, false
+	}
+	value, ok = v.(T)
+	return value, ok
+}
+
+// BindPlayer 将玩家绑定到连接上，并触发 ConnectionBoundEvent
+//   - 重复绑定将替换此前绑定的玩家，绑定关系将在 ConnectionClosedEvent 执行完毕后自动解除
+//   - 例如：server.BindPlayer(conn, &Player{ID: uid})
+func BindPlayer[P any](conn *Conn, player P) {
+	var p any = player
+	conn.player.Store(&p)
+	conn.server.OnConnectionBoundEvent(conn, player)
+}
+
+// GetPlayer 获取连接绑定的玩家，当未绑定或类型不匹配时 ok 将为 false
+//   - 例如：player, ok := server.GetPlayer[*Player](conn)
+func GetPlayer[P any](conn *Conn) (player P, ok bool) {
+	p := conn.player.Load()
+	if p == nil {
+		return player, false
+	}
+	player, ok = (*p).(P)
+	return player, ok
+}
@@ -0,0 +1,100 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket)
+	var released = make(chan int, 1)
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		server.Store(conn, "level", 10)
+		if level, ok := server.Load[int](conn, "level"); !ok || level != 10 {
+			t.Errorf("expected level 10, got %d, %v", level, ok)
+		}
+		if _, ok := server.Load[string](conn, "level"); ok {
+			t.Error("expected type mismatch")
+		}
+		if _, ok := server.Load[int](conn, "none"); ok {
+			t.Error("expected missing key")
+		}
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
+		if _, ok := server.Load[int](conn, "level"); !ok {
+			t.Error("expected data to be available in closed event")
+		}
+		srv.PushSystemMessage(func() {
+			released <- len(conn.ViewData())
+		})
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		bot := server.NewBot(srv)
+		bot.JoinServer()
+		bot.LeaveServer()
+	})
+	runServer(t, srv, freeAddr(t, "tcp")+"/data")
+	defer srv.Shutdown()
+	select {
+	case n := <-released:
+		if n != 0 {
+			t.Fatalf("expected data to be released after closed event, got %d entries", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection closed event was not triggered")
+	}
+}
+
+func TestBindPlayer(t *testing.T) {
+	type Player struct {
+		ID string
+	}
+	srv := server.New(server.NetworkWebsocket)
+	var bound = make(chan any, 1)
+	var released = make(chan bool, 1)
+	srv.RegConnectionBoundEvent(func(srv *server.Server, conn *server.Conn, player any) {
+		bound <- player
+	})
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		if _, ok := server.GetPlayer[*Player](conn); ok {
+			t.Error("expected no player before binding")
+		}
+		server.BindPlayer(conn, &Player{ID: "10001"})
+		if player, ok := server.GetPlayer[*Player](conn); !ok || player.ID != "10001" {
+			t.Errorf("expected player 10001, got %v, %v", player, ok)
+		}
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
+		if _, ok := server.GetPlayer[*Player](conn); !ok {
+			t.Error("expected player to be available in closed event")
+		}
+		srv.PushSystemMessage(func() {
+			_, ok := server.GetPlayer[*Player](conn)
+			released <- !ok
+		})
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		bot := server.NewBot(srv)
+		bot.JoinServer()
+		bot.LeaveServer()
+	})
+	runServer(t, srv, freeAddr(t, "tcp")+"/player")
+	defer srv.Shutdown()
+	select {
+	case player := <-bound:
+		if p, ok := player.(*Player); !ok || p.ID != "10001" {
+			t.Fatalf("expected bound player 10001, got %v", player)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection bound event was not triggered")
+	}
+	select {
+	case ok := <-released:
+		if !ok {
+			t.Fatal("expected player to be unbound after closed event")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection closed event was not triggered")
+	}
+}
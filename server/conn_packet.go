@@ -5,4 +5,5 @@ type connPacket struct {
 	wst      int             // websocket消息类型
 	packet   []byte          // 数据包
 	callback func(err error) // 回调函数
+	flush    bool            // 是否为刷新标记，刷新标记不会写入连接，仅在此前的数据包发送完成后触发回调
 }
@@ -0,0 +1,45 @@
+package server_test
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+func TestConn_WriteWithType(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket, server.WithWebsocketWriteMessageType(server.WebsocketMessageTypeText))
+	var illegal = make(chan error, 1)
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		conn.Write([]byte("text"))
+		conn.WriteWithType(server.WebsocketMessageTypeBinary, []byte("binary"))
+		conn.WriteWithType(server.WebsocketMessageTypePing, []byte("ping"), func(err error) {
+			illegal <- err
+		})
+	})
+	var addr = freeAddr(t, "tcp")
+	runServer(t, srv, addr)
+	defer srv.Shutdown()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws://"+addr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ws.Close() }()
+	_ = ws.SetReadDeadline(time.Now().Add(3 * time.Second))
+	for _, expected := range []struct {
+		messageType int
+		data        string
+	}{{websocket.TextMessage, "text"}, {websocket.BinaryMessage, "binary"}} {
+		messageType, data, err := ws.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if messageType != expected.messageType || string(data) != expected.data {
+			t.Fatalf("expected %d %s, got %d %s", expected.messageType, expected.data, messageType, data)
+		}
+	}
+	if err = <-illegal; err != server.ErrWebsocketIllegalMessageType {
+		t.Fatalf("expected ErrWebsocketIllegalMessageType, got %v", err)
+	}
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+const (
+	ConnectionRejectReasonDenied     ConnectionRejectReason = iota + 1 // IP 位于拒绝列表中
+	ConnectionRejectReasonNotAllowed                                   // IP 不在允许列表中
+	ConnectionRejectReasonIPLimit                                      // 该 IP 的连接数量已达上限
+	ConnectionRejectReasonServerFull                                   // 服务器连接数量已达上限
+	ConnectionRejectReasonDraining                                     // 服务器处于排空状态，不再接受新的连接
+)
+
+var connectionRejectReasonNames = map[ConnectionRejectReason]string{
+	ConnectionRejectReasonDenied:     "Denied",
+	ConnectionRejectReasonNotAllowed: "NotAllowed",
+	ConnectionRejectReasonIPLimit:    "IPLimit",
+	ConnectionRejectReasonServerFull: "ServerFull",
+	ConnectionRejectReasonDraining:   "Draining",
+}
+
+// ConnectionRejectReason 连接被拒绝的原因
+type ConnectionRejectReason byte
+
+// String 返回连接被拒绝原因的字符串表示
+func (slf ConnectionRejectReason) String() string {
+	return connectionRejectReasonNames[slf]
+}
+
+// ConnectionFilter 连接过滤器配置，通过 WithConnectionFilter 进行设置
+type ConnectionFilter struct {
+	Allow        []string // 允许连接的 IP 或 CIDR，为空时表示允许所有 IP
+	Deny         []string // 拒绝连接的 IP 或 CIDR，优先级高于 Allow
+	MaxConnPerIP int      // 单个 IP 允许的最大连接数量，<= 0 时表示不限制
+	MaxConn      int      // 服务器允许的最大连接数量，<= 0 时表示不限制
+}
+
+// newConnectionFilter 根据配置创建连接过滤器，当 IP 或 CIDR 不合法时将引发 panic
+func newConnectionFilter(config ConnectionFilter) *connectionFilter {
+	return &connectionFilter{
+		allow:    parseIPNets(config.Allow),
+		deny:     parseIPNets(config.Deny),
+		maxPerIP: config.MaxConnPerIP,
+		max:      config.MaxConn,
+		counter:  map[string]int{},
+	}
+}
+
+// parseIPNets 将 IP 或 CIDR 解析为 *net.IPNet
+func parseIPNets(cidrs []string) []*net.IPNet {
+	var result = make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				panic(fmt.Errorf("connection filter: invalid ip %s", cidr))
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			result = append(result, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(fmt.Errorf("connection filter: %w", err))
+		}
+		result = append(result, ipNet)
+	}
+	return result
+}
+
+type connectionFilter struct {
+	allow    []*net.IPNet
+	deny     []*net.IPNet
+	maxPerIP int
+	max      int
+	mu       sync.Mutex
+	counter  map[string]int
+	total    int
+}
+
+// acquire 检查 IP 是否允许建立连接，允许时将占用一个连接名额
+func (slf *connectionFilter) acquire(ip string) (ConnectionRejectReason, bool) {
+	if parsed := net.ParseIP(strings.Trim(ip, "[]")); parsed != nil {
+		for _, ipNet := range slf.deny {
+			if ipNet.Contains(parsed) {
+				return ConnectionRejectReasonDenied, false
+			}
+		}
+		if len(slf.allow) > 0 {
+			var allowed bool
+			for _, ipNet := range slf.allow {
+				if allowed = ipNet.Contains(parsed); allowed {
+					break
+				}
+			}
+			if !allowed {
+				return ConnectionRejectReasonNotAllowed, false
+			}
+		}
+	}
+	slf.mu.Lock()
+	defer slf.mu.Unlock()
+	if slf.max > 0 && slf.total >= slf.max {
+		return ConnectionRejectReasonServerFull, false
+	}
+	if slf.maxPerIP > 0 && slf.counter[ip] >= slf.maxPerIP {
+		return ConnectionRejectReasonIPLimit, false
+	}
+	slf.counter[ip]++
+	slf.total++
+	return 0, true
+}
+
+// release 释放 IP 占用的连接名额
+func (slf *connectionFilter) release(ip string) {
+	slf.mu.Lock()
+	defer slf.mu.Unlock()
+	if count := slf.counter[ip]; count <= 1 {
+		delete(slf.counter, ip)
+	} else {
+		slf.counter[ip] = count - 1
+	}
+	slf.total--
+}
+
+// filterConnection 通过连接过滤器检查 IP 是否允许建立连接，当被拒绝时将触发 ConnectionRejectedEvent
+//   - 允许建立连接时，需要在连接创建后将 connection.filtered 设置为 true，以便在连接关闭时释放名额
+func (slf *Server) filterConnection(ip string) bool {
+	if slf.draining.Load() {
+		slf.OnConnectionRejectedEvent(ip, ConnectionRejectReasonDraining)
+		return false
+	}
+	if slf.connFilter == nil {
+		return true
+	}
+	reason, ok := slf.connFilter.acquire(ip)
+	if !ok {
+		slf.OnConnectionRejectedEvent(ip, reason)
+	}
+	return ok
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConnectionFilter(t *testing.T) {
+	filter := newConnectionFilter(ConnectionFilter{
+		Allow:        []string{"10.0.0.0/8", "127.0.0.1"},
+		Deny:         []string{"10.0.0.1"},
+		MaxConnPerIP: 2,
+		MaxConn:      3,
+	})
+
+	var cases = []struct {
+		ip     string
+		reason ConnectionRejectReason
+		ok     bool
+	}{
+		{"10.0.0.1", ConnectionRejectReasonDenied, false},
+		{"192.168.0.1", ConnectionRejectReasonNotAllowed, false},
+		{"127.0.0.1", 0, true},
+		{"127.0.0.1", 0, true},
+		{"127.0.0.1", ConnectionRejectReasonIPLimit, false},
+		{"10.0.0.2", 0, true},
+		{"10.0.0.3", ConnectionRejectReasonServerFull, false},
+	}
+	for i, c := range cases {
+		reason, ok := filter.acquire(c.ip)
+		if reason != c.reason || ok != c.ok {
+			t.Fatalf("case %d: expected (%s, %v), got (%s, %v)", i, c.reason, c.ok, reason, ok)
+		}
+	}
+
+	filter.release("127.0.0.1")
+	if _, ok := filter.acquire("10.0.0.3"); !ok {
+		t.Fatalf("expected connection to be accepted after release")
+	}
+}
+
+func TestServer_RequestIP(t *testing.T) {
+	request := func(remoteAddr string, headers ...string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remoteAddr
+		for i := 0; i+1 < len(headers); i += 2 {
+			r.Header.Set(headers[i], headers[i+1])
+		}
+		return r
+	}
+
+	untrusted := New(NetworkWebsocket)
+	if ip := untrusted.requestIP(request("1.1.1.1:1000", "X-Real-IP", "10.0.0.1", "X-Forwarded-For", "10.0.0.1")); ip != "1.1.1.1" {
+		t.Fatalf("expected spoofed headers to be ignored without trusted proxies, got %s", ip)
+	}
+	if ip := untrusted.requestIP(request("[::1]:1000")); ip != "::1" {
+		t.Fatalf("expected ipv6 remote address, got %s", ip)
+	}
+
+	trusted := New(NetworkWebsocket, WithTrustedProxies("127.0.0.1", "10.0.0.0/8"))
+	var cases = []struct {
+		remoteAddr string
+		headers    []string
+		ip         string
+	}{
+		{"127.0.0.1:1000", []string{"X-Real-IP", "2.2.2.2"}, "2.2.2.2"},
+		{"127.0.0.1:1000", []string{"X-Forwarded-For", "3.3.3.3, 2.2.2.2, 10.0.0.5"}, "2.2.2.2"},
+		{"127.0.0.1:1000", []string{"X-Forwarded-For", "10.0.0.6, 10.0.0.5"}, "10.0.0.6"},
+		{"127.0.0.1:1000", []string{"X-Real-IP", "invalid"}, "127.0.0.1"},
+		{"1.1.1.1:1000", []string{"X-Real-IP", "2.2.2.2"}, "1.1.1.1"},
+	}
+	for i, c := range cases {
+		if ip := trusted.requestIP(request(c.remoteAddr, c.headers...)); ip != c.ip {
+			t.Fatalf("case %d: expected %s, got %s", i, c.ip, ip)
+		}
+	}
+}
@@ -5,14 +5,26 @@ import (
 )
 
 const (
-	serverMultipleMark     = "Minotaur Multiple Server"
-	serverMark             = "Minotaur Server"
-	serverSystemDispatcher = "system" // 系统消息分发器
+	serverMultipleMark      = "Minotaur Multiple Server"
+	serverMark              = "Minotaur Server"
+	serverSystemDispatcher  = "system"  // 系统消息分发器
+	serverShardDispatcher   = "shard"   // 分片消息分发器
+	serverMailboxDispatcher = "mailbox" // 连接邮箱消息分发器
+	serverWorkerDispatcher  = "worker"  // 工作池消息分发器
 )
 
 const (
-	DefaultMessageBufferSize     = 1024
-	DefaultAsyncPoolSize         = 256
-	DefaultWebsocketReadDeadline = 30 * time.Second
-	DefaultPacketWarnSize        = 1024 * 1024 * 1 // 1MB
+	DefaultMessageBufferSize       = 1024
+	DefaultAsyncPoolSize           = 256
+	DefaultWebsocketReadDeadline   = 30 * time.Second
+	DefaultPacketWarnSize          = 1024 * 1024 * 1        // 1MB
+	DefaultKickFlushTimeout        = 3 * time.Second        // 踢出连接时等待写入队列发送完成的最长时间
+	DefaultLowMessageDuration      = 100 * time.Millisecond // 默认的慢消息阈值
+	DefaultAsyncLowMessageDuration = time.Second            // 默认的异步慢消息阈值
+	DefaultUDPSessionTimeout       = 30 * time.Second       // 默认的 UDP 虚拟会话空闲超时时间
+	DefaultAuthTimeout             = 10 * time.Second       // 默认的连接鉴权超时时间
+	DefaultWriteCoalesceInterval   = 5 * time.Millisecond   // 默认的出站数据包合并等待时间
+	DefaultWriteCoalesceSize       = 16 * 1024              // 默认的出站数据包合并字节数阈值
+	DefaultWebsocketCloseTimeout   = time.Second            // 关闭 Websocket 连接时等待客户端回复关闭帧的最长时间
+	DefaultHttpSignatureBodyLimit  = 4 * 1024 * 1024        // WithHttpSignature 默认允许的请求体最大长度
 )
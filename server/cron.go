@@ -0,0 +1,110 @@
+package server
+
+import (
+	"github.com/gorhill/cronexpr"
+	"github.com/kercylan98/minotaur/utils/log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// parseCron 解析 cron 表达式，返回表达式及其所使用的时区
+//   - 支持 5 个字段的标准语法（分 时 日 月 周）及 6 个字段的秒级语法（秒 分 时 日 月 周），以及 @daily、@every 等预定义表达式
+//   - 表达式可以 CRON_TZ= 或 TZ= 开头指定时区，例如 "CRON_TZ=Asia/Shanghai 0 0 4 * * *"，否则将使用 loc
+func parseCron(expression string, loc *time.Location) (*cronexpr.Expression, *time.Location, error) {
+	fields := strings.Fields(expression)
+	if len(fields) > 0 {
+		var tz string
+		if strings.HasPrefix(fields[0], "CRON_TZ=") {
+			tz = strings.TrimPrefix(fields[0], "CRON_TZ=")
+		} else if strings.HasPrefix(fields[0], "TZ=") {
+			tz = strings.TrimPrefix(fields[0], "TZ=")
+		}
+		if len(tz) > 0 {
+			var err error
+			if loc, err = time.LoadLocation(tz); err != nil {
+				return nil, nil, err
+			}
+			fields = fields[1:]
+		}
+	}
+	// cronexpr 将 6 个字段的表达式视为 分 时 日 月 周 年，此处补充年份字段以支持秒级语法
+	if len(fields) == 6 {
+		fields = append(fields, "*")
+	}
+	expr, err := cronexpr.Parse(strings.Join(fields, " "))
+	if err != nil {
+		return nil, nil, err
+	}
+	return expr, loc, nil
+}
+
+// cronSchedule 通过 Server.Cron 设置的 cron 调度
+type cronSchedule struct {
+	srv        *Server
+	expression string
+	expr       *cronexpr.Expression
+	loc        *time.Location
+	caller     func()
+	marks      []log.Field
+	timer      *time.Timer
+	lock       sync.Mutex
+	stopped    bool
+}
+
+// schedule 计算下一次执行的时间并设置定时器，不存在下一次执行的时间或服务器已关闭时将停止调度
+func (slf *cronSchedule) schedule() {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	if slf.stopped || slf.srv.ctx.Err() != nil {
+		return
+	}
+	now := time.Now().In(slf.loc)
+	next := slf.expr.Next(now)
+	if next.IsZero() {
+		slf.stopped = true
+		return
+	}
+	slf.timer = time.AfterFunc(next.Sub(now), func() {
+		slf.srv.PushTickerMessage(slf.expression, slf.caller, slf.marks...)
+		slf.schedule()
+	})
+}
+
+// stop 停止调度
+func (slf *cronSchedule) stop() {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	slf.stopped = true
+	if slf.timer != nil {
+		slf.timer.Stop()
+	}
+}
+
+// Cron 通过 cron 表达式设置一个调度，caller 将以 MessageTypeTicker 消息在系统分发器中执行，避免与其他消息产生并发问题
+//   - 支持 5 个字段的标准语法（分 时 日 月 周）及 6 个字段的秒级语法（秒 分 时 日 月 周），例如 "0 0 4 * * *" 表示每天 4 点执行
+//   - 支持 @yearly、@monthly、@weekly、@daily、@hourly 等预定义表达式
+//   - 默认使用 WithCronLocation 设置的时区，也可以在表达式前通过 CRON_TZ= 指定时区，例如 "CRON_TZ=Asia/Shanghai 0 0 4 * * *"
+//   - 与 Ticker 不同的是，该函数无需通过 WithTicker 启用定时器，调度将在服务器关闭后停止
+//   - 返回的 stop 函数可用于停止调度，当表达式错误时将返回错误
+//   - mark 为可选的日志标记，当发生异常时，将会在日志中进行体现
+func (slf *Server) Cron(expression string, caller func(), mark ...log.Field) (stop func(), err error) {
+	var loc = slf.cronLocation
+	if loc == nil {
+		loc = time.Local
+	}
+	expr, loc, err := parseCron(expression, loc)
+	if err != nil {
+		return nil, err
+	}
+	schedule := &cronSchedule{
+		srv:        slf,
+		expression: expression,
+		expr:       expr,
+		loc:        loc,
+		caller:     caller,
+		marks:      mark,
+	}
+	schedule.schedule()
+	return schedule.stop, nil
+}
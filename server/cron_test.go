@@ -0,0 +1,65 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skip(err)
+	}
+	var from = time.Date(2024, 1, 1, 5, 0, 0, 0, shanghai)
+	var cases = []struct {
+		expression string
+		next       time.Time
+	}{
+		{"0 4 * * *", time.Date(2024, 1, 2, 4, 0, 0, 0, shanghai)},
+		{"30 0 4 * * *", time.Date(2024, 1, 2, 4, 0, 30, 0, shanghai)},
+		{"@hourly", time.Date(2024, 1, 1, 6, 0, 0, 0, shanghai)},
+		{"CRON_TZ=UTC 0 0 4 * * *", time.Date(2024, 1, 1, 4, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		expr, loc, err := parseCron(c.expression, shanghai)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next := expr.Next(from.In(loc)); !next.Equal(c.next) {
+			t.Fatalf("%s: expected %s, got %s", c.expression, c.next, next)
+		}
+	}
+	if _, _, err = parseCron("0 0 25 * * *", shanghai); err == nil {
+		t.Fatal("expected an invalid expression error")
+	}
+}
+
+func TestServer_Cron(t *testing.T) {
+	srv := New(NetworkNone)
+	var ready = make(chan struct{})
+	srv.RegStartFinishEvent(func(srv *Server) {
+		close(ready)
+	})
+	go func() {
+		_ = srv.RunNone()
+	}()
+	<-ready
+	defer srv.Shutdown()
+
+	var executed = make(chan struct{}, 1)
+	stop, err := srv.Cron("* * * * * *", func() {
+		select {
+		case executed <- struct{}{}:
+		default:
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	select {
+	case <-executed:
+	case <-time.After(3 * time.Second):
+		t.Fatal("cron was not executed")
+	}
+}
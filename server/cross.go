@@ -0,0 +1,167 @@
+package server
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/log"
+	"sync/atomic"
+	"time"
+)
+
+// Cross 跨服接口，用于在不同服务器之间传递数据包
+//   - 官方实现可参考 server/cross 包
+//   - 数据包中包含了服务器用于区分普通消息及请求、响应的头部，跨服实现应当原样传递
+type Cross interface {
+	// Init 初始化跨服
+	//   - server: 本服服务器，可通过 Server.GetID 获取本服 ID
+	//   - packetHandle.serverId: 发送跨服消息的服务器 ID
+	//   - packetHandle.packet: 数据包
+	Init(server *Server, packetHandle func(serverId string, packet []byte)) error
+	// PushMessage 推送跨服消息
+	//   - serverId: 目标服务器 ID
+	PushMessage(serverId string, packet []byte) error
+	// Release 释放资源
+	Release()
+}
+
+// CrossDiscovery 跨服服务发现接口，用于解析跨服广播及分组消息的目标服务器
+type CrossDiscovery interface {
+	// GetServerIds 获取特定分组的全部服务器 ID，当 group 为空时将返回全部服务器 ID
+	GetServerIds(group string) []string
+}
+
+const (
+	crossPacketMessage  byte = iota // 普通跨服消息：| kind(1) | packet |
+	crossPacketRequest              // 跨服请求：| kind(1) | callId(8) | packet |
+	crossPacketResponse             // 跨服响应：| kind(1) | callId(8) | packet |
+)
+
+// marshalCrossPacket 将数据包编码为特定类型的跨服数据包
+func marshalCrossPacket(kind byte, callId uint64, packet []byte) []byte {
+	if kind == crossPacketMessage {
+		return append([]byte{kind}, packet...)
+	}
+	result := make([]byte, 0, 9+len(packet))
+	result = append(result, kind)
+	result = binary.BigEndian.AppendUint64(result, callId)
+	return append(result, packet...)
+}
+
+// unmarshalCrossPacket 从跨服数据包中解析出类型、调用 ID 及数据包
+func unmarshalCrossPacket(data []byte) (kind byte, callId uint64, packet []byte, err error) {
+	if len(data) < 1 {
+		return 0, 0, nil, ErrCrossInvalidPacket
+	}
+	switch kind = data[0]; kind {
+	case crossPacketMessage:
+		return kind, 0, data[1:], nil
+	case crossPacketRequest, crossPacketResponse:
+		if len(data) < 9 {
+			return 0, 0, nil, ErrCrossInvalidPacket
+		}
+		return kind, binary.BigEndian.Uint64(data[1:9]), data[9:], nil
+	default:
+		return 0, 0, nil, ErrCrossInvalidPacket
+	}
+}
+
+// handleCrossPacket 处理跨服中间件接收到的跨服数据包
+func (slf *Server) handleCrossPacket(crossName, serverId string, data []byte) {
+	kind, callId, packet, err := unmarshalCrossPacket(data)
+	if err != nil {
+		slf.Logger().Error("Server", log.String("Cross", crossName), log.String("ServerID", serverId), log.Err(err))
+		return
+	}
+	switch kind {
+	case crossPacketMessage:
+		slf.pushMessage(slf.messagePool.Get().castToCrossMessage(crossName, serverId, packet))
+	case crossPacketRequest:
+		slf.PushSystemMessage(func() {
+			var replied atomic.Bool
+			slf.OnCrossRequestEvent(crossName, serverId, packet, func(response []byte) error {
+				if !replied.CompareAndSwap(false, true) {
+					return ErrCrossRepliedAlready
+				}
+				return slf.cross[crossName].PushMessage(serverId, marshalCrossPacket(crossPacketResponse, callId, response))
+			})
+		}, log.String("Cross", crossName), log.String("Request", serverId))
+	case crossPacketResponse:
+		slf.crossCallLock.Lock()
+		call, exist := slf.crossCalls[callId]
+		delete(slf.crossCalls, callId)
+		slf.crossCallLock.Unlock()
+		if exist {
+			call <- packet
+		}
+	}
+}
+
+// BroadcastCross 推送跨服消息到特定跨服的所有服务器中，不包含本服
+//   - 目标服务器将通过 WithCrossDiscovery 设置的服务发现进行解析，当未设置时将返回 ErrNoSupportCrossDiscovery
+//   - 当部分服务器推送失败时，将继续推送其余服务器，并返回所有失败的错误
+func (slf *Server) BroadcastCross(crossName string, packet []byte) error {
+	return slf.PushCrossGroupMessage(crossName, "", packet)
+}
+
+// PushCrossGroupMessage 推送跨服消息到特定跨服中属于特定分组的所有服务器中，不包含本服
+//   - 目标服务器将通过 WithCrossDiscovery 设置的服务发现进行解析，当未设置时将返回 ErrNoSupportCrossDiscovery
+//   - 当 group 为空时等同于 BroadcastCross
+//   - 当部分服务器推送失败时，将继续推送其余服务器，并返回所有失败的错误
+func (slf *Server) PushCrossGroupMessage(crossName string, group string, packet []byte) error {
+	cross, exist := slf.cross[crossName]
+	if !exist {
+		return ErrNoSupportCross
+	}
+	if slf.crossDiscovery == nil {
+		return ErrNoSupportCrossDiscovery
+	}
+	var data = marshalCrossPacket(crossPacketMessage, 0, packet)
+	var errs []error
+	for _, serverId := range slf.crossDiscovery.GetServerIds(group) {
+		if serverId == slf.id {
+			continue
+		}
+		if err := cross.PushMessage(serverId, data); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", serverId, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CallCross 向特定跨服的特定服务器发起请求并阻塞等待响应
+//   - 目标服务器将通过 RegCrossRequestEvent 注册的事件处理函数对请求进行响应
+//   - 当超过 timeout 仍未收到响应时将返回 ErrCrossCallTimeout
+//   - 当服务器未通过 WithCross 设置 crossName 对应的跨服时，将返回 ErrNoSupportCross
+//   - 由于该函数将阻塞当前协程，应避免在消息处理过程中调用，可通过 PushAsyncMessage 在异步消息中调用
+func (slf *Server) CallCross(crossName string, serverId string, packet []byte, timeout time.Duration) ([]byte, error) {
+	cross, exist := slf.cross[crossName]
+	if !exist {
+		return nil, ErrNoSupportCross
+	}
+	var callId = slf.crossCallId.Add(1)
+	var call = make(chan []byte, 1)
+	slf.crossCallLock.Lock()
+	if slf.crossCalls == nil {
+		slf.crossCalls = map[uint64]chan []byte{}
+	}
+	slf.crossCalls[callId] = call
+	slf.crossCallLock.Unlock()
+	defer func() {
+		slf.crossCallLock.Lock()
+		delete(slf.crossCalls, callId)
+		slf.crossCallLock.Unlock()
+	}()
+
+	if err := cross.PushMessage(serverId, marshalCrossPacket(crossPacketRequest, callId, packet)); err != nil {
+		return nil, err
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case response := <-call:
+		return response, nil
+	case <-timer.C:
+		return nil, ErrCrossCallTimeout
+	}
+}
@@ -0,0 +1,2 @@
+// Package cross 提供了 server.Cross 的官方实现，适用于通过消息中间件在不同服务器之间传递跨服数据包的情况。
+package cross
@@ -0,0 +1,12 @@
+package cross
+
+import "errors"
+
+var (
+	// ErrServerIdEmpty 服务器未设置 ID，需要通过 server.WithCross 设置
+	ErrServerIdEmpty = errors.New("cross: server id is empty, use server.WithCross to set it")
+	// ErrInvalidMessage 跨服消息格式错误
+	ErrInvalidMessage = errors.New("cross: invalid message")
+	// ErrInvalidIdempotencyKey 幂等键为空或长度超过 255
+	ErrInvalidIdempotencyKey = errors.New("cross: invalid idempotency key")
+)
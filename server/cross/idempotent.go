@@ -0,0 +1,90 @@
+package cross
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// NewIdempotencyKey 生成随机的幂等键
+func NewIdempotencyKey() string {
+	var key = make([]byte, 16)
+	_, _ = rand.Read(key)
+	return hex.EncodeToString(key)
+}
+
+// MarshalIdempotentPacket 将幂等键及数据包编码为带幂等键的数据包
+//   - | keyLen(1) | key(n) | packet |
+//   - 当同一业务请求需要重试时，应当使用相同的幂等键
+func MarshalIdempotentPacket(key string, packet []byte) ([]byte, error) {
+	if len(key) == 0 || len(key) > 0xFF {
+		return nil, ErrInvalidIdempotencyKey
+	}
+	result := make([]byte, 0, 1+len(key)+len(packet))
+	result = append(result, byte(len(key)))
+	result = append(result, key...)
+	return append(result, packet...), nil
+}
+
+// UnmarshalIdempotentPacket 从带幂等键的数据包中解析出幂等键及数据包
+func UnmarshalIdempotentPacket(data []byte) (key string, packet []byte, err error) {
+	if len(data) < 1 {
+		return "", nil, ErrInvalidMessage
+	}
+	size := int(data[0])
+	if size == 0 || len(data) < 1+size {
+		return "", nil, ErrInvalidMessage
+	}
+	return string(data[1 : 1+size]), data[1+size:], nil
+}
+
+// NewIdempotent 创建幂等检查器，幂等键将在首次出现 ttl 后过期
+//   - ttl 应当大于消息可能被重复投递的最长时间
+func NewIdempotent(ttl time.Duration) *Idempotent {
+	return &Idempotent{
+		ttl:  ttl,
+		keys: make(map[string]time.Time),
+	}
+}
+
+// Idempotent 幂等检查器，用于对至少一次投递的跨服消息进行去重
+type Idempotent struct {
+	ttl   time.Duration
+	keys  map[string]time.Time
+	check uint64
+	rw    sync.Mutex
+}
+
+// Check 检查幂等键是否首次出现，首次出现时返回 true，否则返回 false
+//   - 仅在当前进程内生效，相同服务器 ID 多实例部署时需要基于数据库等共享存储自行检查
+func (slf *Idempotent) Check(key string) bool {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	now := time.Now()
+	slf.check++
+	if slf.check%1024 == 0 {
+		slf.evict(now)
+	}
+	if expire, exist := slf.keys[key]; exist && now.Before(expire) {
+		return false
+	}
+	slf.keys[key] = now.Add(slf.ttl)
+	return true
+}
+
+// Len 获取当前记录的幂等键数量
+func (slf *Idempotent) Len() int {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	return len(slf.keys)
+}
+
+// evict 清理已过期的幂等键
+func (slf *Idempotent) evict(now time.Time) {
+	for key, expire := range slf.keys {
+		if !now.Before(expire) {
+			delete(slf.keys, key)
+		}
+	}
+}
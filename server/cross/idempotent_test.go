@@ -0,0 +1,35 @@
+package cross
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdempotentPacket(t *testing.T) {
+	key := NewIdempotencyKey()
+	data, err := MarshalIdempotentPacket(key, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, packet, err := UnmarshalIdempotentPacket(data)
+	if err != nil || k != key || string(packet) != "hello" {
+		t.Fatalf("unexpected packet: %s %q %v", k, packet, err)
+	}
+	if _, err = MarshalIdempotentPacket("", nil); err != ErrInvalidIdempotencyKey {
+		t.Fatalf("expected ErrInvalidIdempotencyKey, got %v", err)
+	}
+}
+
+func TestIdempotent_Check(t *testing.T) {
+	idempotent := NewIdempotent(50 * time.Millisecond)
+	if !idempotent.Check("a") {
+		t.Fatal("expected first check to pass")
+	}
+	if idempotent.Check("a") {
+		t.Fatal("expected duplicate check to fail")
+	}
+	time.Sleep(60 * time.Millisecond)
+	if !idempotent.Check("a") {
+		t.Fatal("expected expired key to pass")
+	}
+}
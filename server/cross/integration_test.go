@@ -0,0 +1,152 @@
+package cross_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/cross"
+	"github.com/redis/go-redis/v9"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 集成测试需要真实的中间件，通过以下环境变量指定地址后才会执行：
+//   - MINOTAUR_TEST_REDIS：Redis 地址，例如 127.0.0.1:6379
+//   - MINOTAUR_TEST_NATS：NATS 地址，例如 nats://127.0.0.1:4222
+//   - MINOTAUR_TEST_KAFKA：Kafka 地址，多个地址使用逗号分隔，例如 127.0.0.1:9092
+
+func integrationAddr(t *testing.T, env string) string {
+	addr := os.Getenv(env)
+	if len(addr) == 0 {
+		t.Skipf("%s is not set", env)
+	}
+	return addr
+}
+
+// runCrossServer 运行使用特定跨服实现的服务器，handler 将在收到跨服消息时被执行
+func runCrossServer(t *testing.T, serverId string, impl server.Cross, handler func(packet []byte)) *server.Server {
+	srv := server.New(server.NetworkNone, server.WithCross("test", serverId, impl))
+	srv.RegReceiveCrossPacketEvent(func(srv *server.Server, crossName, senderServerId string, packet []byte) {
+		if senderServerId != serverId {
+			t.Errorf("expected sender %s, got %s", serverId, senderServerId)
+		}
+		handler(packet)
+	})
+	var ready = make(chan struct{})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	go func() {
+		_ = srv.RunNone()
+	}()
+	select {
+	case <-ready:
+	case <-time.After(10 * time.Second):
+		t.Fatal("server was not started")
+	}
+	return srv
+}
+
+func expectCrossPacket(t *testing.T, received <-chan string, expected string, timeout time.Duration) {
+	select {
+	case packet := <-received:
+		if packet != expected {
+			t.Fatalf("expected %q, got %q", expected, packet)
+		}
+	case <-time.After(timeout):
+		t.Fatalf("cross packet %q was not received", expected)
+	}
+}
+
+func TestRedis_PubSub(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: integrationAddr(t, "MINOTAUR_TEST_REDIS")})
+	defer client.Close()
+	var received = make(chan string, 1)
+	srv := runCrossServer(t, "redis-pubsub", cross.NewRedis(client, cross.WithRedisPrefix(fmt.Sprintf("minotaur:test:%d", time.Now().UnixNano()))), func(packet []byte) {
+		received <- string(packet)
+	})
+	defer srv.Shutdown()
+
+	if err := srv.PushCrossMessage("test", "redis-pubsub", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	expectCrossPacket(t, received, "hello", 5*time.Second)
+}
+
+func TestRedis_StreamAckAfterProcessed(t *testing.T) {
+	client := redis.NewClient(&redis.Options{Addr: integrationAddr(t, "MINOTAUR_TEST_REDIS")})
+	defer client.Close()
+	var prefix = fmt.Sprintf("minotaur:test:%d", time.Now().UnixNano())
+	var stream = prefix + ":stream:redis-stream"
+	defer client.Del(context.Background(), stream)
+	pending := func() int64 {
+		result, err := client.XPending(context.Background(), stream, cross.DefaultRedisStreamGroup).Result()
+		if err != nil {
+			t.Error(err)
+			return -1
+		}
+		return result.Count
+	}
+
+	// 消息处理期间不应被确认，处理完成后才会被确认
+	var received = make(chan string, 1)
+	srv := runCrossServer(t, "redis-stream", cross.NewRedis(client, cross.WithRedisPrefix(prefix), cross.WithRedisStream(0)), func(packet []byte) {
+		if count := pending(); count != 1 {
+			t.Errorf("expected the message to stay pending while processing, got %d pending", count)
+		}
+		received <- string(packet)
+	})
+	defer srv.Shutdown()
+
+	if err := srv.PushCrossMessage("test", "redis-stream", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	expectCrossPacket(t, received, "hello", 5*time.Second)
+	var deadline = time.Now().Add(5 * time.Second)
+	for pending() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("processed message was not acknowledged")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestNats(t *testing.T) {
+	var received = make(chan string, 1)
+	subject := fmt.Sprintf("minotaur.test.%d", time.Now().UnixNano())
+	srv := runCrossServer(t, "nats", cross.NewNats(integrationAddr(t, "MINOTAUR_TEST_NATS"), cross.WithNatsSubject(subject)), func(packet []byte) {
+		received <- string(packet)
+	})
+	defer srv.Shutdown()
+
+	if err := srv.PushCrossMessage("test", "nats", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	expectCrossPacket(t, received, "hello", 5*time.Second)
+}
+
+func TestKafka(t *testing.T) {
+	brokers := strings.Split(integrationAddr(t, "MINOTAUR_TEST_KAFKA"), ",")
+	var received = make(chan string, 1)
+	topic := fmt.Sprintf("minotaur-test-%d", time.Now().UnixNano())
+	srv := runCrossServer(t, "kafka", cross.NewKafka(brokers, cross.WithKafkaTopic(topic)), func(packet []byte) {
+		received <- string(packet)
+	})
+	defer srv.Shutdown()
+
+	// 主题将在首次写入时自动创建，创建完成前的写入可能失败
+	var deadline = time.Now().Add(30 * time.Second)
+	for {
+		err := srv.PushCrossMessage("test", "kafka", []byte("hello"))
+		if err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Second)
+	}
+	expectCrossPacket(t, received, "hello", 30*time.Second)
+}
@@ -0,0 +1,189 @@
+package cross
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/segmentio/kafka-go"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultKafkaTopic          = "minotaur.cross" // 默认的 Kafka 主题前缀
+	DefaultKafkaCommitInterval = time.Second      // 默认的位移提交间隔
+)
+
+// NewKafka 创建基于 Kafka 的跨服实现，适用于邮件、交易等需要在服务器重启后依然能够送达的跨服消息
+//   - 每个服务器消费 "{topic}.{serverId}" 主题，并以相同的名称作为消费者组，相同 ID 的多个服务器实例将以消费者组的方式分摊消息
+//   - 投递语义为至少一次：位移将在跨服消息被服务器处理完成后才会提交，服务器异常退出时未提交的消息将被重新投递
+//   - 由于消息可能被重复投递，对于不具备幂等性的业务，可通过 MarshalIdempotentPacket 及 Idempotent 进行去重
+func NewKafka(brokers []string, options ...KafkaOption) *Kafka {
+	k := &Kafka{
+		brokers:        brokers,
+		topic:          DefaultKafkaTopic,
+		commitInterval: DefaultKafkaCommitInterval,
+		processed:      make(map[int]kafka.Message),
+	}
+	for _, option := range options {
+		option(k)
+	}
+	return k
+}
+
+// Kafka 基于 Kafka 的跨服实现
+type Kafka struct {
+	brokers        []string
+	topic          string
+	group          string
+	commitInterval time.Duration
+	dialer         *kafka.Dialer
+	transport      kafka.RoundTripper
+	server         *server.Server
+	serverId       string
+	writer         *kafka.Writer
+	reader         *kafka.Reader
+	processed      map[int]kafka.Message // 各分区已处理完成但未提交的最新消息
+	processedLock  sync.Mutex
+	ctx            context.Context
+	cancel         context.CancelFunc
+	wait           sync.WaitGroup
+}
+
+// Init 初始化跨服，将开始消费本服的主题
+func (slf *Kafka) Init(server *server.Server, packetHandle func(serverId string, packet []byte)) error {
+	slf.server = server
+	slf.serverId = server.GetID()
+	if len(slf.serverId) == 0 {
+		return ErrServerIdEmpty
+	}
+	var group = slf.group
+	if len(group) == 0 {
+		group = slf.topicOf(slf.serverId)
+	}
+	slf.ctx, slf.cancel = context.WithCancel(context.Background())
+	slf.writer = &kafka.Writer{
+		Addr:                   kafka.TCP(slf.brokers...),
+		Balancer:               &kafka.Hash{},
+		RequiredAcks:           kafka.RequireAll,
+		AllowAutoTopicCreation: true,
+		Transport:              slf.transport,
+	}
+	slf.reader = kafka.NewReader(kafka.ReaderConfig{
+		Brokers: slf.brokers,
+		GroupID: group,
+		Topic:   slf.topicOf(slf.serverId),
+		Dialer:  slf.dialer,
+	})
+
+	slf.wait.Add(2)
+	go slf.consume(packetHandle)
+	go slf.commit()
+	return nil
+}
+
+// PushMessage 推送跨服消息到特定服务器，将等待 Kafka 确认写入后返回
+func (slf *Kafka) PushMessage(serverId string, packet []byte) error {
+	data, err := marshalMessage(slf.serverId, packet)
+	if err != nil {
+		return err
+	}
+	return slf.writer.WriteMessages(slf.ctx, kafka.Message{
+		Topic: slf.topicOf(serverId),
+		Key:   []byte(slf.serverId),
+		Value: data,
+	})
+}
+
+// Release 释放资源，将停止消费并提交已处理完成的消息位移
+func (slf *Kafka) Release() {
+	if slf.cancel == nil {
+		return
+	}
+	slf.cancel()
+	slf.wait.Wait()
+	slf.flush(context.Background())
+	if err := slf.reader.Close(); err != nil {
+		log.Error("Cross", log.String("Name", "Kafka"), log.String("Action", "Release"), log.Err(err))
+	}
+	if err := slf.writer.Close(); err != nil {
+		log.Error("Cross", log.String("Name", "Kafka"), log.String("Action", "Release"), log.Err(err))
+	}
+}
+
+// consume 持续拉取消息并交由服务器处理
+//   - 跨服消息与系统消息处于同一消息分发器中，因此在跨服消息之后推送的系统消息执行时，意味着该跨服消息已经处理完成
+func (slf *Kafka) consume(packetHandle func(serverId string, packet []byte)) {
+	defer slf.wait.Done()
+	for {
+		message, err := slf.reader.FetchMessage(slf.ctx)
+		if err != nil {
+			if slf.ctx.Err() != nil || errors.Is(err, context.Canceled) {
+				return
+			}
+			log.Error("Cross", log.String("Name", "Kafka"), log.String("Action", "Fetch"), log.Err(err))
+			time.Sleep(time.Second)
+			continue
+		}
+		serverId, packet, err := unmarshalMessage(message.Value)
+		if err != nil {
+			log.Error("Cross", log.String("Name", "Kafka"), log.Err(err))
+		} else {
+			packetHandle(serverId, packet)
+		}
+		slf.server.PushSystemMessage(func() {
+			slf.processedLock.Lock()
+			slf.processed[message.Partition] = message
+			slf.processedLock.Unlock()
+		}, log.String("Cross", "Kafka"))
+	}
+}
+
+// commit 定期提交已处理完成的消息位移
+func (slf *Kafka) commit() {
+	defer slf.wait.Done()
+	ticker := time.NewTicker(slf.commitInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-slf.ctx.Done():
+			return
+		case <-ticker.C:
+			slf.flush(slf.ctx)
+		}
+	}
+}
+
+// flush 提交各分区已处理完成的最新消息位移
+func (slf *Kafka) flush(ctx context.Context) {
+	slf.processedLock.Lock()
+	if len(slf.processed) == 0 {
+		slf.processedLock.Unlock()
+		return
+	}
+	var messages = make([]kafka.Message, 0, len(slf.processed))
+	for partition, message := range slf.processed {
+		messages = append(messages, message)
+		delete(slf.processed, partition)
+	}
+	slf.processedLock.Unlock()
+
+	if err := slf.reader.CommitMessages(ctx, messages...); err != nil {
+		log.Error("Cross", log.String("Name", "Kafka"), log.String("Action", "Commit"), log.Err(err))
+		// 提交失败时保留位移以便下次重试，若期间已有更新的位移则以更新的为准
+		slf.processedLock.Lock()
+		for _, message := range messages {
+			if _, exist := slf.processed[message.Partition]; !exist {
+				slf.processed[message.Partition] = message
+			}
+		}
+		slf.processedLock.Unlock()
+	}
+}
+
+// topicOf 获取服务器的主题
+func (slf *Kafka) topicOf(serverId string) string {
+	return fmt.Sprintf("%s.%s", slf.topic, serverId)
+}
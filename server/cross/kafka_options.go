@@ -0,0 +1,47 @@
+package cross
+
+import (
+	"github.com/segmentio/kafka-go"
+	"time"
+)
+
+// KafkaOption Kafka 跨服选项
+type KafkaOption func(k *Kafka)
+
+// WithKafkaTopic 设置主题前缀，默认为 DefaultKafkaTopic
+func WithKafkaTopic(topic string) KafkaOption {
+	return func(k *Kafka) {
+		k.topic = topic
+	}
+}
+
+// WithKafkaGroup 设置消费者组名称，默认为本服所消费的主题名称
+func WithKafkaGroup(group string) KafkaOption {
+	return func(k *Kafka) {
+		k.group = group
+	}
+}
+
+// WithKafkaCommitInterval 设置位移提交间隔，默认为 DefaultKafkaCommitInterval
+//   - 间隔越大，服务器异常退出时被重复投递的消息越多
+func WithKafkaCommitInterval(interval time.Duration) KafkaOption {
+	return func(k *Kafka) {
+		if interval > 0 {
+			k.commitInterval = interval
+		}
+	}
+}
+
+// WithKafkaDialer 设置消费者所使用的 kafka.Dialer，可用于配置 TLS 及 SASL 等
+func WithKafkaDialer(dialer *kafka.Dialer) KafkaOption {
+	return func(k *Kafka) {
+		k.dialer = dialer
+	}
+}
+
+// WithKafkaTransport 设置生产者所使用的 kafka.RoundTripper，可用于配置 TLS 及 SASL 等
+func WithKafkaTransport(transport kafka.RoundTripper) KafkaOption {
+	return func(k *Kafka) {
+		k.transport = transport
+	}
+}
@@ -0,0 +1,31 @@
+package cross
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// marshalMessage 将发送方服务器 ID 及数据包编码为跨服消息
+//   - | serverIdLen(2) | serverId(n) | packet |
+func marshalMessage(serverId string, packet []byte) ([]byte, error) {
+	if len(serverId) > math.MaxUint16 {
+		return nil, ErrInvalidMessage
+	}
+	result := make([]byte, 0, 2+len(serverId)+len(packet))
+	result = binary.BigEndian.AppendUint16(result, uint16(len(serverId)))
+	result = append(result, serverId...)
+	return append(result, packet...), nil
+}
+
+// unmarshalMessage 从跨服消息中解析出发送方服务器 ID 及数据包
+//   - | serverIdLen(2) | serverId(n) | packet |
+func unmarshalMessage(data []byte) (serverId string, packet []byte, err error) {
+	if len(data) < 2 {
+		return "", nil, ErrInvalidMessage
+	}
+	size := int(binary.BigEndian.Uint16(data))
+	if len(data) < 2+size {
+		return "", nil, ErrInvalidMessage
+	}
+	return string(data[2 : 2+size]), data[2+size:], nil
+}
@@ -0,0 +1,17 @@
+package cross
+
+import "testing"
+
+func TestMessage(t *testing.T) {
+	data, err := marshalMessage("server-1", []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverId, packet, err := unmarshalMessage(data)
+	if err != nil || serverId != "server-1" || string(packet) != "hello" {
+		t.Fatalf("unexpected message: %s %q %v", serverId, packet, err)
+	}
+	if _, _, err = unmarshalMessage([]byte{0, 9, 'a'}); err != ErrInvalidMessage {
+		t.Fatalf("expected ErrInvalidMessage, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package cross
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/nats-io/nats.go"
+	"time"
+)
+
+const (
+	DefaultNatsSubject       = "minotaur.cross" // 默认的 NATS 主题前缀
+	DefaultNatsReconnectWait = time.Second * 5  // 默认的 NATS 重连间隔
+)
+
+// NewNats 创建基于 NATS 的跨服实现
+//   - 每个服务器订阅 "{subject}.{serverId}" 主题，当未通过 WithNatsConn 指定连接时，将在 Init 时通过 url 建立连接
+//   - 默认将无限次自动重连，重连间隔为 DefaultNatsReconnectWait，可通过 WithNatsOptions 覆盖
+//   - 支持通过 Request 发起请求并等待目标服务器的响应，目标服务器需要通过 WithNatsRequestHandler 处理请求
+func NewNats(url string, options ...NatsOption) *Nats {
+	n := &Nats{
+		url:     url,
+		subject: DefaultNatsSubject,
+	}
+	for _, option := range options {
+		option(n)
+	}
+	return n
+}
+
+// Nats 基于 NATS 的跨服实现
+type Nats struct {
+	url            string
+	subject        string
+	conn           *nats.Conn
+	ownConn        bool
+	options        []nats.Option
+	server         *server.Server
+	serverId       string
+	sub            *nats.Subscription
+	requestHandler NatsRequestHandler
+}
+
+// Init 初始化跨服，将建立连接并订阅本服的主题
+func (slf *Nats) Init(server *server.Server, packetHandle func(serverId string, packet []byte)) (err error) {
+	slf.server = server
+	slf.serverId = server.GetID()
+	if len(slf.serverId) == 0 {
+		return ErrServerIdEmpty
+	}
+	if slf.conn == nil {
+		var options = []nats.Option{
+			nats.ReconnectWait(DefaultNatsReconnectWait),
+			nats.MaxReconnects(-1),
+			nats.DisconnectErrHandler(func(conn *nats.Conn, err error) {
+				log.Error("Cross", log.String("Name", "Nats"), log.String("State", "Disconnect"), log.Err(err))
+			}),
+			nats.ReconnectHandler(func(conn *nats.Conn) {
+				log.Info("Cross", log.String("Name", "Nats"), log.String("State", "Reconnect"), log.String("URL", conn.ConnectedUrl()))
+			}),
+		}
+		if slf.conn, err = nats.Connect(slf.url, append(options, slf.options...)...); err != nil {
+			return err
+		}
+		slf.ownConn = true
+	}
+	slf.sub, err = slf.conn.Subscribe(slf.subjectOf(slf.serverId), func(msg *nats.Msg) {
+		serverId, packet, err := unmarshalMessage(msg.Data)
+		if err != nil {
+			log.Error("Cross", log.String("Name", "Nats"), log.Err(err))
+			return
+		}
+		if len(msg.Reply) > 0 {
+			if slf.requestHandler == nil {
+				log.Warn("Cross", log.String("Name", "Nats"), log.String("Request", serverId), log.String("Reason", "no request handler, use WithNatsRequestHandler to set it"))
+				return
+			}
+			slf.server.PushSystemMessage(func() {
+				if err := msg.Respond(slf.requestHandler(slf.server, serverId, packet)); err != nil {
+					log.Error("Cross", log.String("Name", "Nats"), log.String("Action", "Respond"), log.Err(err))
+				}
+			}, log.String("Cross", "Nats"), log.String("Request", serverId))
+			return
+		}
+		packetHandle(serverId, packet)
+	})
+	return err
+}
+
+// PushMessage 推送跨服消息到特定服务器
+func (slf *Nats) PushMessage(serverId string, packet []byte) error {
+	data, err := marshalMessage(slf.serverId, packet)
+	if err != nil {
+		return err
+	}
+	return slf.conn.Publish(slf.subjectOf(serverId), data)
+}
+
+// Request 向特定服务器发起请求并等待响应，当超过 timeout 仍未收到响应时将返回 nats.ErrTimeout
+//   - 目标服务器需要通过 WithNatsRequestHandler 处理请求，否则请求将被忽略且不会得到响应
+//   - 与中间件无关的跨服请求可使用 server.Server.CallCross
+func (slf *Nats) Request(serverId string, packet []byte, timeout time.Duration) ([]byte, error) {
+	data, err := marshalMessage(slf.serverId, packet)
+	if err != nil {
+		return nil, err
+	}
+	msg, err := slf.conn.Request(slf.subjectOf(serverId), data, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return msg.Data, nil
+}
+
+// Release 释放资源，将取消订阅，当连接由 Init 建立时将排空并关闭连接
+func (slf *Nats) Release() {
+	if slf.sub != nil {
+		_ = slf.sub.Unsubscribe()
+	}
+	if slf.ownConn && slf.conn != nil {
+		_ = slf.conn.Drain()
+	}
+}
+
+// subjectOf 获取服务器的主题
+func (slf *Nats) subjectOf(serverId string) string {
+	return fmt.Sprintf("%s.%s", slf.subject, serverId)
+}
@@ -0,0 +1,42 @@
+package cross
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/nats-io/nats.go"
+)
+
+type (
+	// NatsOption NATS 跨服选项
+	NatsOption func(n *Nats)
+	// NatsRequestHandler NATS 请求处理函数，返回值将作为响应发送给请求方
+	//   - 处理函数将作为系统消息在服务器中执行
+	NatsRequestHandler func(srv *server.Server, serverId string, packet []byte) []byte
+)
+
+// WithNatsSubject 设置主题前缀，默认为 DefaultNatsSubject
+func WithNatsSubject(subject string) NatsOption {
+	return func(n *Nats) {
+		n.subject = subject
+	}
+}
+
+// WithNatsOptions 设置建立连接时的 nats.Option，将覆盖默认的重连配置
+func WithNatsOptions(options ...nats.Option) NatsOption {
+	return func(n *Nats) {
+		n.options = append(n.options, options...)
+	}
+}
+
+// WithNatsConn 使用已建立的 NATS 连接，此时 url 及 WithNatsOptions 将被忽略，连接的生命周期由调用方管理
+func WithNatsConn(conn *nats.Conn) NatsOption {
+	return func(n *Nats) {
+		n.conn = conn
+	}
+}
+
+// WithNatsRequestHandler 设置请求处理函数，用于响应其他服务器通过 Nats.Request 发起的请求
+func WithNatsRequestHandler(handler NatsRequestHandler) NatsOption {
+	return func(n *Nats) {
+		n.requestHandler = handler
+	}
+}
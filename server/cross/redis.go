@@ -0,0 +1,225 @@
+package cross
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/redis/go-redis/v9"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultRedisPrefix      = "minotaur:cross" // 默认的 Redis 键前缀
+	DefaultRedisStreamGroup = "minotaur"       // Stream 模式下默认的消费者组名称
+	redisStreamField        = "m"              // Stream 模式下消息所在的字段
+	redisStreamBatch        = 64               // Stream 模式下单次读取的最大消息数量
+	redisStreamBlock        = time.Second      // Stream 模式下单次读取的最长阻塞时间
+	redisRetryInterval      = time.Second      // 发生错误后的重试间隔
+)
+
+// NewRedis 创建基于 Redis 的跨服实现
+//   - 默认采用 Pub/Sub 模式，每个服务器订阅 "{prefix}:{serverId}" 频道，消息仅会投递给当前在线的服务器
+//   - 通过 WithRedisStream 可采用 Stream 模式，消息将被写入 "{prefix}:stream:{serverId}"，服务器重启后将继续消费未确认的消息
+//   - Stream 模式下消息将在服务器处理完成后才被确认，因此提供至少一次的投递保证，消息处理应当是幂等的
+//   - client 可以是 *redis.Client、*redis.ClusterClient 等任意 redis.UniversalClient 实现，其生命周期由调用方管理
+func NewRedis(client redis.UniversalClient, options ...RedisOption) *Redis {
+	r := &Redis{
+		client: client,
+		prefix: DefaultRedisPrefix,
+		group:  DefaultRedisStreamGroup,
+	}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// Redis 基于 Redis 的跨服实现
+type Redis struct {
+	client    redis.UniversalClient
+	prefix    string
+	stream    bool
+	maxLen    int64
+	group     string
+	server    *server.Server
+	serverId  string
+	handler   func(serverId string, packet []byte)
+	pubsub    *redis.PubSub
+	processed []string // Stream 模式下已处理完成但未确认的消息 ID
+	lock      sync.Mutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wait      sync.WaitGroup
+}
+
+// Init 初始化跨服，将开始订阅本服的频道或消费本服的 Stream
+func (slf *Redis) Init(server *server.Server, packetHandle func(serverId string, packet []byte)) error {
+	slf.server = server
+	slf.serverId = server.GetID()
+	if len(slf.serverId) == 0 {
+		return ErrServerIdEmpty
+	}
+	slf.handler = packetHandle
+	slf.ctx, slf.cancel = context.WithCancel(context.Background())
+
+	if slf.stream {
+		var stream = slf.streamKey(slf.serverId)
+		err := slf.client.XGroupCreateMkStream(slf.ctx, stream, slf.group, "0").Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			slf.cancel()
+			return err
+		}
+		slf.wait.Add(1)
+		go slf.consume(stream)
+		return nil
+	}
+
+	slf.pubsub = slf.client.Subscribe(slf.ctx, slf.channel(slf.serverId))
+	if _, err := slf.pubsub.Receive(slf.ctx); err != nil {
+		slf.cancel()
+		_ = slf.pubsub.Close()
+		return err
+	}
+	slf.wait.Add(1)
+	go func(ch <-chan *redis.Message) {
+		defer slf.wait.Done()
+		for message := range ch {
+			slf.handle(message.Payload)
+		}
+	}(slf.pubsub.Channel())
+	return nil
+}
+
+// PushMessage 推送跨服消息到特定服务器
+func (slf *Redis) PushMessage(serverId string, packet []byte) error {
+	data, err := marshalMessage(slf.serverId, packet)
+	if err != nil {
+		return err
+	}
+	if slf.stream {
+		return slf.client.XAdd(slf.ctx, &redis.XAddArgs{
+			Stream: slf.streamKey(serverId),
+			MaxLen: slf.maxLen,
+			Approx: slf.maxLen > 0,
+			Values: map[string]any{redisStreamField: data},
+		}).Err()
+	}
+	return slf.client.Publish(slf.ctx, slf.channel(serverId), data).Err()
+}
+
+// Release 释放资源，将停止订阅及消费并确认已处理完成的消息，但不会关闭 Redis 客户端
+func (slf *Redis) Release() {
+	if slf.cancel == nil {
+		return
+	}
+	slf.cancel()
+	if slf.pubsub != nil {
+		_ = slf.pubsub.Close()
+	}
+	slf.wait.Wait()
+	if slf.stream {
+		slf.ack(context.Background(), slf.streamKey(slf.serverId))
+	}
+}
+
+// consume 消费 Stream 中的消息，将首先处理此前已读取但未确认的消息
+//   - 跨服消息与系统消息处于同一消息分发器中，因此在跨服消息之后推送的系统消息执行时，意味着该跨服消息已经处理完成，此时才会确认该消息
+func (slf *Redis) consume(stream string) {
+	defer slf.wait.Done()
+	var id = "0"
+	for slf.ctx.Err() == nil {
+		slf.ack(slf.ctx, stream)
+		var block = redisStreamBlock
+		if id == "0" {
+			block = -1
+		}
+		streams, err := slf.client.XReadGroup(slf.ctx, &redis.XReadGroupArgs{
+			Group:    slf.group,
+			Consumer: slf.serverId,
+			Streams:  []string{stream, id},
+			Count:    redisStreamBatch,
+			Block:    block,
+		}).Result()
+		if err != nil {
+			if slf.ctx.Err() != nil {
+				return
+			}
+			if errors.Is(err, redis.Nil) {
+				id = ">"
+				continue
+			}
+			log.Error("Cross", log.String("Name", "Redis"), log.String("Stream", stream), log.Err(err))
+			time.Sleep(redisRetryInterval)
+			continue
+		}
+		var count int
+		var last string
+		for _, s := range streams {
+			for _, message := range s.Messages {
+				count++
+				last = message.ID
+				if data, ok := message.Values[redisStreamField].(string); ok {
+					slf.handle(data)
+				}
+				var messageId = message.ID
+				slf.server.PushSystemMessage(func() {
+					slf.lock.Lock()
+					slf.processed = append(slf.processed, messageId)
+					slf.lock.Unlock()
+				}, log.String("Cross", "Redis"))
+			}
+		}
+		if id != ">" {
+			if count == 0 {
+				// 未确认的消息已处理完毕，开始消费新消息
+				id = ">"
+			} else {
+				// 此前的消息将在处理完成后才被确认，因此需要从最后读取的位置继续读取未确认的消息
+				id = last
+			}
+		}
+	}
+}
+
+// ack 确认已处理完成的消息，确认失败时将保留以便下次重试
+func (slf *Redis) ack(ctx context.Context, stream string) {
+	slf.lock.Lock()
+	var ids = slf.processed
+	slf.processed = nil
+	slf.lock.Unlock()
+	if len(ids) == 0 {
+		return
+	}
+	if err := slf.client.XAck(ctx, stream, slf.group, ids...).Err(); err != nil {
+		if ctx.Err() == nil {
+			log.Error("Cross", log.String("Name", "Redis"), log.String("Stream", stream), log.Int("Count", len(ids)), log.Err(err))
+		}
+		slf.lock.Lock()
+		slf.processed = append(ids, slf.processed...)
+		slf.lock.Unlock()
+	}
+}
+
+// handle 解析跨服消息并交由服务器处理
+func (slf *Redis) handle(data string) {
+	serverId, packet, err := unmarshalMessage([]byte(data))
+	if err != nil {
+		log.Error("Cross", log.String("Name", "Redis"), log.Err(err))
+		return
+	}
+	slf.handler(serverId, packet)
+}
+
+// channel 获取服务器的 Pub/Sub 频道名称
+func (slf *Redis) channel(serverId string) string {
+	return fmt.Sprintf("%s:%s", slf.prefix, serverId)
+}
+
+// streamKey 获取服务器的 Stream 键名
+func (slf *Redis) streamKey(serverId string) string {
+	return fmt.Sprintf("%s:stream:%s", slf.prefix, serverId)
+}
@@ -0,0 +1,29 @@
+package cross
+
+// RedisOption Redis 跨服选项
+type RedisOption func(r *Redis)
+
+// WithRedisPrefix 设置频道及 Stream 键名的前缀，默认为 DefaultRedisPrefix
+//   - 可用于在同一 Redis 中隔离不同的集群
+func WithRedisPrefix(prefix string) RedisOption {
+	return func(r *Redis) {
+		r.prefix = prefix
+	}
+}
+
+// WithRedisStream 采用 Stream 模式进行可靠投递
+//   - 消息将持久化在 Stream 中，服务器离线期间的消息将在重新上线后被消费，处理后将通过 XACK 进行确认
+//   - maxLen 为每个 Stream 保留的最大消息数量（近似值），<= 0 时不限制
+func WithRedisStream(maxLen int64) RedisOption {
+	return func(r *Redis) {
+		r.stream = true
+		r.maxLen = maxLen
+	}
+}
+
+// WithRedisStreamGroup 设置 Stream 模式下的消费者组名称，默认为 DefaultRedisStreamGroup
+func WithRedisStreamGroup(group string) RedisOption {
+	return func(r *Redis) {
+		r.group = group
+	}
+}
@@ -0,0 +1,140 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+type loopbackCross struct {
+	handle func(serverId string, packet []byte)
+	id     string
+}
+
+func (slf *loopbackCross) Init(srv *server.Server, packetHandle func(serverId string, packet []byte)) error {
+	slf.id, slf.handle = srv.GetID(), packetHandle
+	return nil
+}
+
+func (slf *loopbackCross) PushMessage(serverId string, packet []byte) error {
+	slf.handle(slf.id, packet)
+	return nil
+}
+
+func (slf *loopbackCross) Release() {}
+
+func TestServer_PushCrossMessage(t *testing.T) {
+	srv := server.New(server.NetworkNone, server.WithCross("loopback", "server-1", new(loopbackCross)))
+	var received = make(chan string, 1)
+	srv.RegReceiveCrossPacketEvent(func(srv *server.Server, crossName, senderServerId string, packet []byte) {
+		received <- crossName + ":" + senderServerId + ":" + string(packet)
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		if err := srv.PushCrossMessage("unknown", "server-1", nil); err != server.ErrNoSupportCross {
+			t.Errorf("expected ErrNoSupportCross, got %v", err)
+		}
+		if err := srv.PushCrossMessage("loopback", "server-1", []byte("hello")); err != nil {
+			t.Error(err)
+		}
+	})
+	go func() {
+		_ = srv.RunNone()
+	}()
+	select {
+	case message := <-received:
+		if message != "loopback:server-1:hello" {
+			t.Fatalf("unexpected cross message %s", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cross message was not received")
+	}
+	srv.Shutdown()
+}
+
+func TestServer_CallCross(t *testing.T) {
+	srv := server.New(server.NetworkNone, server.WithCross("loopback", "server-1", new(loopbackCross)))
+	srv.RegCrossRequestEvent(func(srv *server.Server, crossName, senderServerId string, packet []byte, reply func(packet []byte) error) {
+		if string(packet) == "ignore" {
+			return
+		}
+		if err := reply(append([]byte("echo:"), packet...)); err != nil {
+			t.Error(err)
+		}
+		if err := reply(nil); err != server.ErrCrossRepliedAlready {
+			t.Errorf("expected ErrCrossRepliedAlready, got %v", err)
+		}
+	})
+	var done = make(chan struct{})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			defer close(done)
+			response, err := srv.CallCross("loopback", "server-1", []byte("hello"), time.Second)
+			if err != nil || string(response) != "echo:hello" {
+				t.Errorf("unexpected response: %q %v", response, err)
+			}
+			if _, err = srv.CallCross("loopback", "server-1", []byte("ignore"), 100*time.Millisecond); err != server.ErrCrossCallTimeout {
+				t.Errorf("expected ErrCrossCallTimeout, got %v", err)
+			}
+		}()
+	})
+	go func() {
+		_ = srv.RunNone()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("cross call was not finished")
+	}
+	srv.Shutdown()
+}
+
+type recordCross struct {
+	targets []string
+}
+
+func (slf *recordCross) Init(srv *server.Server, packetHandle func(serverId string, packet []byte)) error {
+	return nil
+}
+
+func (slf *recordCross) PushMessage(serverId string, packet []byte) error {
+	slf.targets = append(slf.targets, serverId)
+	return nil
+}
+
+func (slf *recordCross) Release() {}
+
+type staticDiscovery map[string][]string
+
+func (slf staticDiscovery) GetServerIds(group string) []string {
+	if len(group) == 0 {
+		var ids []string
+		for _, group := range []string{"game", "battle"} {
+			ids = append(ids, slf[group]...)
+		}
+		return ids
+	}
+	return slf[group]
+}
+
+func TestServer_BroadcastCross(t *testing.T) {
+	cross := new(recordCross)
+	srv := server.New(server.NetworkNone, server.WithCross("record", "game-1", cross), server.WithCrossDiscovery(staticDiscovery{
+		"game":   {"game-1", "game-2"},
+		"battle": {"battle-1", "battle-2"},
+	}))
+	if err := srv.BroadcastCross("record", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.PushCrossGroupMessage("record", "battle", []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	var expected = []string{"game-2", "battle-1", "battle-2", "battle-1", "battle-2"}
+	if len(cross.targets) != len(expected) {
+		t.Fatalf("unexpected targets: %v", cross.targets)
+	}
+	for i, target := range cross.targets {
+		if target != expected[i] {
+			t.Fatalf("unexpected targets: %v", cross.targets)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/utils/log"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	delayTaskPending  int32 = iota // 等待执行
+	delayTaskExecuted              // 已执行
+	delayTaskCanceled              // 已取消
+)
+
+// delayTask 延迟消息的执行状态
+type delayTask struct {
+	timer *time.Timer
+	state atomic.Int32
+}
+
+// execute 标记为已执行，当已被取消时将返回 false
+func (slf *delayTask) execute() bool {
+	return slf.state.CompareAndSwap(delayTaskPending, delayTaskExecuted)
+}
+
+// cancel 取消执行，当已执行或已被取消时将返回 false
+func (slf *delayTask) cancel() bool {
+	slf.timer.Stop()
+	return slf.state.CompareAndSwap(delayTaskPending, delayTaskCanceled)
+}
+
+// PushDelayMessage 向服务器中推送 MessageTypeDelay 消息，caller 将在 d 之后于系统分发器中执行
+//   - 与 MessageTypeTicker 不同的是，延迟消息无需通过 WithTicker 启用定时器，适用于与处理逻辑相关的一次性超时等场景
+//   - 返回的 cancel 函数可在 caller 执行前取消执行，当成功取消时将返回 true，已执行或已取消时将返回 false
+//   - 延迟期间的消息不会阻止服务器关闭，服务器关闭后到期的消息将被丢弃
+//   - mark 为可选的日志标记，当发生异常时，将会在日志中进行体现
+func (slf *Server) PushDelayMessage(d time.Duration, caller func(), mark ...log.Field) (cancel func() bool) {
+	task := new(delayTask)
+	task.timer = time.AfterFunc(d, func() {
+		slf.pushMessage(slf.messagePool.Get().castToDelayMessage(task, caller, mark...))
+	})
+	return task.cancel
+}
+
+// PushShuntDelayMessage 向特定分发器中推送 MessageTypeShuntDelay 消息，消息执行与 MessageTypeDelay 一致，不同的是将会在连接所在的分发器中执行
+//   - 当延迟时间到达时连接已关闭，消息将被丢弃
+//   - mark 为可选的日志标记，当发生异常时，将会在日志中进行体现
+func (slf *Server) PushShuntDelayMessage(conn *Conn, d time.Duration, caller func(), mark ...log.Field) (cancel func() bool) {
+	task := new(delayTask)
+	task.timer = time.AfterFunc(d, func() {
+		if conn.IsClosed() {
+			task.state.CompareAndSwap(delayTaskPending, delayTaskCanceled)
+			return
+		}
+		slf.pushMessage(slf.messagePool.Get().castToShuntDelayMessage(conn, task, caller, mark...))
+	})
+	return task.cancel
+}
+
+// PushDelayMessage 推送延迟消息，caller 将在 d 之后于连接所在的分发器中执行，该消息将通过 Server.PushShuntDelayMessage 函数推送
+//   - mark 为可选的日志标记，当发生异常时，将会在日志中进行体现
+func (slf *Conn) PushDelayMessage(d time.Duration, caller func(), mark ...log.Field) (cancel func() bool) {
+	return slf.server.PushShuntDelayMessage(slf, d, caller, mark...)
+}
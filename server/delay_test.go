@@ -0,0 +1,41 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+func TestServer_PushDelayMessage(t *testing.T) {
+	srv := server.New(server.NetworkNone)
+	runServer(t, srv, "")
+	defer srv.Shutdown()
+
+	var executed = make(chan string, 2)
+	var start = time.Now()
+	srv.PushDelayMessage(50*time.Millisecond, func() {
+		executed <- "delay"
+	})
+	cancel := srv.PushDelayMessage(20*time.Millisecond, func() {
+		executed <- "canceled"
+	})
+	if !cancel() {
+		t.Fatal("expected the pending message to be canceled")
+	}
+	if cancel() {
+		t.Fatal("expected the second cancel to fail")
+	}
+	select {
+	case name := <-executed:
+		if name != "delay" || time.Since(start) < 50*time.Millisecond {
+			t.Fatalf("unexpected execution: %s after %s", name, time.Since(start))
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("delay message was not executed")
+	}
+	select {
+	case name := <-executed:
+		t.Fatalf("unexpected execution: %s", name)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"github.com/alphadose/haxmap"
 	"github.com/kercylan98/minotaur/utils/buffer"
+	"sync"
 )
 
 var dispatcherUnique = struct{}{}
@@ -23,6 +24,10 @@ type dispatcher struct {
 	buffer  *buffer.Unbounded[*Message]
 	uniques *haxmap.Map[string, struct{}]
 	handler func(dispatcher *dispatcher, message *Message)
+	pool    *workerPool
+	closed  bool
+	drain   bool
+	rw      sync.RWMutex
 }
 
 func (slf *dispatcher) unique(name string) bool {
@@ -34,7 +39,10 @@ func (slf *dispatcher) antiUnique(name string) {
 	slf.uniques.Del(name)
 }
 
-func (slf *dispatcher) start() {
+// start 开始处理消息，直到分发器被关闭
+//   - wait 将在分发器退出时被标记为完成，用于服务器关闭时等待所有分发器退出
+func (slf *dispatcher) start(wait *sync.WaitGroup) {
+	defer wait.Done()
 	for {
 		select {
 		case message, ok := <-slf.buffer.Get():
@@ -43,14 +51,76 @@ func (slf *dispatcher) start() {
 			}
 			slf.buffer.Load()
 			slf.handler(slf, message)
+			slf.closeIfDrained()
 		}
 	}
 }
 
-func (slf *dispatcher) put(message *Message) {
+// put 向分发器中写入消息，当分发器已关闭时将返回 false
+func (slf *dispatcher) put(message *Message) bool {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	if slf.closed {
+		return false
+	}
+	if slf.pool != nil {
+		return slf.pool.put(message)
+	}
 	slf.buffer.Put(message)
+	return true
+}
+
+// len 获取分发器中等待处理的消息数量
+func (slf *dispatcher) len() int {
+	if slf.pool != nil {
+		return slf.pool.stats().Queued
+	}
+	return slf.buffer.Len()
 }
 
 func (slf *dispatcher) close() {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	slf.closed = true
+	if slf.pool != nil {
+		slf.pool.close()
+		return
+	}
+	slf.buffer.Close()
+}
+
+// closeWhenDrained 在分发器中已写入的消息全部处理完毕后关闭分发器，期间仍允许写入消息
+//   - 用于连接切换分流渠道后释放旧的分发器，避免仍在排队的消息被丢弃
+func (slf *dispatcher) closeWhenDrained() {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	if slf.pool != nil {
+		slf.closed = true
+		slf.pool.close()
+		return
+	}
+	slf.drain = true
+	slf.closeIfDrainedWithoutLock()
+}
+
+// closeIfDrained 当分发器处于排空状态且没有等待处理的消息时关闭分发器
+func (slf *dispatcher) closeIfDrained() {
+	slf.rw.RLock()
+	draining := slf.drain
+	slf.rw.RUnlock()
+	if !draining {
+		return
+	}
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	slf.closeIfDrainedWithoutLock()
+}
+
+// closeIfDrainedWithoutLock 当分发器处于排空状态且没有等待处理的消息时关闭分发器（无锁）
+func (slf *dispatcher) closeIfDrainedWithoutLock() {
+	if !slf.drain || slf.closed || slf.buffer.Len() > 0 {
+		return
+	}
+	slf.closed = true
 	slf.buffer.Close()
 }
@@ -5,9 +5,47 @@ import "errors"
 var (
 	ErrConstructed                 = errors.New("the Server must be constructed using the server.New function")
 	ErrCanNotSupportNetwork        = errors.New("can not support network")
-	ErrNetworkOnlySupportHttp      = errors.New("the current network mode is not compatible with HttpRouter, only NetworkHttp is supported")
+	ErrNetworkOnlySupportHttp      = errors.New("the current network mode is not compatible with HttpRouter, only NetworkHttp and NetworkWebsocket are supported")
 	ErrNetworkOnlySupportGRPC      = errors.New("the current network mode is not compatible with RegGrpcServer, only NetworkGRPC is supported")
 	ErrNetworkIncompatibleHttp     = errors.New("the current network mode is not compatible with NetworkHttp")
+	ErrNetworkOnlySupportWebsocket = errors.New("the current network mode is not compatible with WebsocketRoute, only NetworkWebsocket is supported")
 	ErrWebsocketIllegalMessageType = errors.New("illegal message type")
+	ErrNoSupportCross              = errors.New("the server does not support Cross, please use the WithCross option to create the server")
+	ErrNoSupportCrossDiscovery     = errors.New("the server does not support CrossDiscovery, please use the WithCrossDiscovery option to create the server")
+	ErrCrossInvalidPacket          = errors.New("cross: invalid cross packet")
+	ErrCrossCallTimeout            = errors.New("cross: call timeout")
+	ErrCrossRepliedAlready         = errors.New("cross: the request has already been replied")
 	ErrNoSupportTicker             = errors.New("the server does not support Ticker, please use the WithTicker option to create the server")
+	ErrNoSupportMetrics            = errors.New("the server does not support Metrics, please use the WithMetrics option to create the server")
+	ErrNoSupportHotRestart         = errors.New("the server does not support hot restart, please use the WithHotRestart option to create the server")
+	ErrHotRestartUnsupportedNet    = errors.New("hot restart: kcp and webtransport listeners can not be inherited")
+	ErrHotRestarting               = errors.New("hot restart: the server is already restarting")
+	ErrJWTMalformed                = errors.New("jwt: malformed token")
+	ErrJWTUnsupportedAlg           = errors.New("jwt: unsupported signing algorithm")
+	ErrJWTInvalidSignature         = errors.New("jwt: invalid signature")
+	ErrJWTExpired                  = errors.New("jwt: token is expired")
+	ErrJWTNotValidYet              = errors.New("jwt: token is not valid yet")
+	ErrCodecPacketTooLarge         = errors.New("codec: packet too large")
+	ErrPacketTooLarge              = errors.New("packet exceeds the maximum packet size")
+	ErrConnectionHeartbeatTimeout  = errors.New("connection heartbeat timeout")
+	ErrConnectionKicked            = errors.New("connection kicked by server")
+	ErrUDPSessionTimeout           = errors.New("udp session idle timeout")
+	ErrConnectionAuthTimeout       = errors.New("connection authentication timeout")
+	ErrPacketDropped               = errors.New("packet dropped by ConnectionWritePacketBeforeEvent")
+	ErrServerMaintenance           = errors.New("server is under maintenance")
+	ErrConnectionRateLimited       = errors.New("connection exceeded the packet rate limit")
+	ErrCompressionInvalidPacket    = errors.New("compression: invalid packet header")
+	ErrCompressionPacketTooLarge   = errors.New("compression: decompressed packet is too large")
+	ErrCryptoHandshakeFailed       = errors.New("crypto: invalid public key received during handshake")
+	ErrCryptoInvalidPacket         = errors.New("crypto: packet decryption failed")
+	ErrWebTransportRequireTLS      = errors.New("webtransport: tls cert file and key file must be specified, use WithTLS to set them")
+	ErrWebTransportStreamNotReady  = errors.New("webtransport: no bidirectional stream has been opened by the client")
+	ErrUnixSocketInUse             = errors.New("unix: the socket file is in use by another process")
+	ErrUnixSocketNotSocket         = errors.New("unix: the path already exists and is not a socket file")
+	ErrCaptureInvalidFormat        = errors.New("capture: invalid capture file format")
+	ErrListenerUnsupportedNetwork  = errors.New("listener: only connection based networks are supported")
+	ErrRouterPacketTooShort        = errors.New("router: packet is too short to contain a message id")
+	ErrRouterNotFound              = errors.New("router: route not found")
+	ErrPacketSequenceMissing       = errors.New("packet is too short to contain a sequence number")
+	ErrConnectionClosed            = errors.New("connection has been closed")
 )
@@ -20,7 +20,7 @@ type StartFinishEventHandler func(srv *Server)
 type StopEventHandler func(srv *Server)
 type ConnectionReceivePacketEventHandler func(srv *Server, conn *Conn, packet []byte)
 type ConnectionOpenedEventHandler func(srv *Server, conn *Conn)
-type ConnectionClosedEventHandler func(srv *Server, conn *Conn, err any)
+type ConnectionClosedEventHandler func(srv *Server, conn *Conn, reason CloseReason, err any)
 type MessageErrorEventHandler func(srv *Server, message *Message, err error)
 type MessageLowExecEventHandler func(srv *Server, message *Message, cost time.Duration)
 type ConsoleCommandEventHandler func(srv *Server, command string, params ConsoleParams)
@@ -31,6 +31,19 @@ type ShuntChannelClosedEventHandler func(srv *Server, guid int64)
 type ConnectionPacketPreprocessEventHandler func(srv *Server, conn *Conn, packet []byte, abort func(), usePacket func(newPacket []byte))
 type MessageExecBeforeEventHandler func(srv *Server, message *Message) bool
 type MessageReadyEventHandler func(srv *Server)
+type ConnectionIdleEventHandler func(srv *Server, conn *Conn, idle time.Duration)
+type ConnectionKickedEventHandler func(srv *Server, conn *Conn, code int, payload []byte)
+type ConnectionRejectedEventHandler func(srv *Server, ip string, reason ConnectionRejectReason)
+type ConnectionRateLimitedEventHandler func(srv *Server, conn *Conn, action PacketRateLimitAction)
+type ReceiveCrossPacketEventHandler func(srv *Server, crossName, senderServerId string, packet []byte)
+type MessageTimeoutEventHandler func(srv *Server, message *Message, timeout time.Duration)
+type ConnectionPanicEventHandler func(srv *Server, conn *Conn, err any, stack []byte)
+type ShutdownBeforeEventHandler func(srv *Server, err error) bool
+type ListenErrorEventHandler func(srv *Server, network Network, addr string, err error)
+type ConnectionBoundEventHandler func(srv *Server, conn *Conn, player any)
+type ConnectionPacketOversizeEventHandler func(srv *Server, conn *Conn, size int)
+type ConnectionPacketReplayEventHandler func(srv *Server, conn *Conn, seq uint64)
+type CrossRequestEventHandler func(srv *Server, crossName, senderServerId string, packet []byte, reply func(packet []byte) error)
 
 func newEvent(srv *Server) *event {
 	return &event{
@@ -50,6 +63,19 @@ func newEvent(srv *Server) *event {
 		connectionPacketPreprocessEventHandlers: slice.NewPriority[ConnectionPacketPreprocessEventHandler](),
 		messageExecBeforeEventHandlers:          slice.NewPriority[MessageExecBeforeEventHandler](),
 		messageReadyEventHandlers:               slice.NewPriority[MessageReadyEventHandler](),
+		connectionIdleEventHandlers:             slice.NewPriority[ConnectionIdleEventHandler](),
+		connectionKickedEventHandlers:           slice.NewPriority[ConnectionKickedEventHandler](),
+		connectionRejectedEventHandlers:         slice.NewPriority[ConnectionRejectedEventHandler](),
+		connectionRateLimitedEventHandlers:      slice.NewPriority[ConnectionRateLimitedEventHandler](),
+		receiveCrossPacketEventHandlers:         slice.NewPriority[ReceiveCrossPacketEventHandler](),
+		crossRequestEventHandlers:               slice.NewPriority[CrossRequestEventHandler](),
+		messageTimeoutEventHandlers:             slice.NewPriority[MessageTimeoutEventHandler](),
+		connectionPanicEventHandlers:            slice.NewPriority[ConnectionPanicEventHandler](),
+		shutdownBeforeEventHandlers:             slice.NewPriority[ShutdownBeforeEventHandler](),
+		listenErrorEventHandlers:                slice.NewPriority[ListenErrorEventHandler](),
+		connectionBoundEventHandlers:            slice.NewPriority[ConnectionBoundEventHandler](),
+		connectionPacketOversizeEventHandlers:   slice.NewPriority[ConnectionPacketOversizeEventHandler](),
+		connectionPacketReplayEventHandlers:     slice.NewPriority[ConnectionPacketReplayEventHandler](),
 	}
 }
 
@@ -70,6 +96,19 @@ type event struct {
 	connectionPacketPreprocessEventHandlers *slice.Priority[ConnectionPacketPreprocessEventHandler]
 	messageExecBeforeEventHandlers          *slice.Priority[MessageExecBeforeEventHandler]
 	messageReadyEventHandlers               *slice.Priority[MessageReadyEventHandler]
+	connectionIdleEventHandlers             *slice.Priority[ConnectionIdleEventHandler]
+	connectionKickedEventHandlers           *slice.Priority[ConnectionKickedEventHandler]
+	connectionRejectedEventHandlers         *slice.Priority[ConnectionRejectedEventHandler]
+	connectionRateLimitedEventHandlers      *slice.Priority[ConnectionRateLimitedEventHandler]
+	receiveCrossPacketEventHandlers         *slice.Priority[ReceiveCrossPacketEventHandler]
+	crossRequestEventHandlers               *slice.Priority[CrossRequestEventHandler]
+	messageTimeoutEventHandlers             *slice.Priority[MessageTimeoutEventHandler]
+	connectionPanicEventHandlers            *slice.Priority[ConnectionPanicEventHandler]
+	shutdownBeforeEventHandlers             *slice.Priority[ShutdownBeforeEventHandler]
+	listenErrorEventHandlers                *slice.Priority[ListenErrorEventHandler]
+	connectionBoundEventHandlers            *slice.Priority[ConnectionBoundEventHandler]
+	connectionPacketOversizeEventHandlers   *slice.Priority[ConnectionPacketOversizeEventHandler]
+	connectionPacketReplayEventHandlers     *slice.Priority[ConnectionPacketReplayEventHandler]
 
 	consoleCommandEventHandlers        map[string]*slice.Priority[ConsoleCommandEventHandler]
 	consoleCommandEventHandlerInitOnce sync.Once
@@ -78,7 +117,7 @@ type event struct {
 // RegStopEvent 服务器停止时将立即执行被注册的事件处理函数
 func (slf *event) RegStopEvent(handler StopEventHandler, priority ...int) {
 	slf.stopEventHandlers.Append(handler, slice.GetValue(priority, 0))
-	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
 }
 
 func (slf *event) OnStopEvent() {
@@ -94,7 +133,7 @@ func (slf *event) OnStopEvent() {
 func (slf *event) RegConsoleCommandEvent(command string, handler ConsoleCommandEventHandler, priority ...int) {
 	fd := int(os.Stdin.Fd())
 	if !terminal.IsTerminal(fd) {
-		log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("ignore", "system not terminal"))
+		slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("ignore", "system not terminal"))
 		return
 	}
 
@@ -118,7 +157,7 @@ func (slf *event) RegConsoleCommandEvent(command string, handler ConsoleCommandE
 		slf.consoleCommandEventHandlers[command] = list
 	}
 	list.Append(handler, slice.GetValue(priority, 0))
-	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
 }
 
 func (slf *event) OnConsoleCommandEvent(command string, paramsStr string) {
@@ -127,15 +166,15 @@ func (slf *event) OnConsoleCommandEvent(command string, paramsStr string) {
 		if !exist {
 			switch command {
 			case "exit", "quit", "close", "shutdown", "EXIT", "QUIT", "CLOSE", "SHUTDOWN":
-				log.Info("Console", log.String("Receive", command), log.String("Action", "Shutdown"))
-				slf.Server.shutdown(nil)
+				slf.Logger().Info("Console", log.String("Receive", command), log.String("Action", "Shutdown"))
+				go slf.Server.shutdown(nil)
 				return
 			}
-			log.Warn("Server", log.String("Command", "unregistered"))
+			slf.Logger().Warn("Server", log.String("Command", "unregistered"))
 		} else {
 			v, err := url.ParseQuery(paramsStr)
 			if err != nil {
-				log.Error("ConsoleCommandEvent", log.String("command", command), log.String("params", paramsStr), log.Err(err))
+				slf.Logger().Error("ConsoleCommandEvent", log.String("command", command), log.String("params", paramsStr), log.Err(err))
 				return
 			}
 			var params = make(ConsoleParams)
@@ -153,13 +192,13 @@ func (slf *event) OnConsoleCommandEvent(command string, paramsStr string) {
 // RegStartBeforeEvent 在服务器初始化完成启动前立刻执行被注册的事件处理函数
 func (slf *event) RegStartBeforeEvent(handler StartBeforeEventHandler, priority ...int) {
 	slf.startBeforeEventHandlers.Append(handler, slice.GetValue(priority, 0))
-	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
 }
 
 func (slf *event) OnStartBeforeEvent() {
 	defer func() {
 		if err := recover(); err != nil {
-			log.Error("Server", log.String("OnStartBeforeEvent", fmt.Sprintf("%v", err)))
+			slf.Logger().Error("Server", log.String("OnStartBeforeEvent", fmt.Sprintf("%v", err)))
 			debug.PrintStack()
 		}
 	}()
@@ -173,7 +212,7 @@ func (slf *event) OnStartBeforeEvent() {
 //   - 需要注意该时刻服务器已经启动完成，但是还有可能未开始处理消息，客户端有可能无法连接，如果需要在消息处理器准备就绪后执行，请使用 RegMessageReadyEvent 函数
 func (slf *event) RegStartFinishEvent(handler StartFinishEventHandler, priority ...int) {
 	slf.startFinishEventHandlers.Append(handler, slice.GetValue(priority, 0))
-	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
 }
 
 func (slf *event) OnStartFinishEvent() {
@@ -192,21 +231,24 @@ func (slf *event) OnStartFinishEvent() {
 }
 
 // RegConnectionClosedEvent 在连接关闭后将立刻执行被注册的事件处理函数
+//   - reason 为连接关闭的原因，err 为导致连接关闭的错误，当连接正常关闭时 err 可能为 nil
 func (slf *event) RegConnectionClosedEvent(handler ConnectionClosedEventHandler, priority ...int) {
 	if slf.network == NetworkHttp {
 		panic(ErrNetworkIncompatibleHttp)
 	}
 	slf.connectionClosedEventHandlers.Append(handler, slice.GetValue(priority, 0))
-	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
 }
 
-func (slf *event) OnConnectionClosedEvent(conn *Conn, err any) {
+func (slf *event) OnConnectionClosedEvent(conn *Conn, reason CloseReason, err any) {
 	slf.PushSystemMessage(func() {
 		slf.Server.online.Delete(conn.GetID())
 		slf.connectionClosedEventHandlers.RangeValue(func(index int, value ConnectionClosedEventHandler) bool {
-			value(slf.Server, conn, err)
+			value(slf.Server, conn, reason, err)
 			return true
 		})
+		conn.ReleaseData()
+		conn.player.Store(nil)
 	}, log.String("Event", "OnConnectionClosedEvent"))
 }
 
@@ -217,16 +259,22 @@ func (slf *event) RegConnectionOpenedEvent(handler ConnectionOpenedEventHandler,
 		panic(ErrNetworkIncompatibleHttp)
 	}
 	slf.connectionOpenedEventHandlers.Append(handler, slice.GetValue(priority, 0))
-	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
 }
 
 func (slf *event) OnConnectionOpenedEvent(conn *Conn) {
+	if slf.Server.awaitAuth(conn) {
+		return
+	}
 	slf.PushSystemMessage(func() {
 		slf.Server.online.Set(conn.GetID(), conn)
 		slf.connectionOpenedEventHandlers.RangeValue(func(index int, value ConnectionOpenedEventHandler) bool {
 			value(slf.Server, conn)
 			return true
 		})
+		if !slf.Server.checkMaintenance(conn) {
+			return
+		}
 		slf.OnConnectionOpenedAfterEvent(conn)
 	}, log.String("Event", "OnConnectionOpenedEvent"))
 }
@@ -237,12 +285,12 @@ func (slf *event) RegConnectionReceivePacketEvent(handler ConnectionReceivePacke
 		panic(ErrNetworkIncompatibleHttp)
 	}
 	slf.connectionReceivePacketEventHandlers.Append(handler, slice.GetValue(priority, 0))
-	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
 }
 
 func (slf *event) OnConnectionReceivePacketEvent(conn *Conn, packet []byte) {
 	if slf.Server.runtime.packetWarnSize > 0 && len(packet) > slf.Server.runtime.packetWarnSize {
-		log.Warn("Server", log.String("OnConnectionReceivePacketEvent", fmt.Sprintf("packet size %d > %d", len(packet), slf.Server.runtime.packetWarnSize)))
+		slf.Logger().Warn("Server", log.String("OnConnectionReceivePacketEvent", fmt.Sprintf("packet size %d > %d", len(packet), slf.Server.runtime.packetWarnSize)), log.String("ConnID", conn.GetID()))
 	}
 	slf.connectionReceivePacketEventHandlers.RangeValue(func(index int, value ConnectionReceivePacketEventHandler) bool {
 		value(slf.Server, conn, packet)
@@ -253,7 +301,7 @@ func (slf *event) OnConnectionReceivePacketEvent(conn *Conn, packet []byte) {
 // RegMessageErrorEvent 在处理消息发生错误时将立即执行被注册的事件处理函数
 func (slf *event) RegMessageErrorEvent(handler MessageErrorEventHandler, priority ...int) {
 	slf.messageErrorEventHandlers.Append(handler, slice.GetValue(priority, 0))
-	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
 }
 
 func (slf *event) OnMessageErrorEvent(message *Message, err error) {
@@ -262,7 +310,7 @@ func (slf *event) OnMessageErrorEvent(message *Message, err error) {
 	}
 	defer func() {
 		if err := recover(); err != nil {
-			log.Error("Server", log.String("OnMessageErrorEvent", messageNames[message.t]), log.Any("Error", err))
+			slf.Logger().Error("Server", log.String("OnMessageErrorEvent", messageNames[message.t]), log.Any("Error", err))
 			debug.PrintStack()
 		}
 	}()
@@ -275,7 +323,7 @@ func (slf *event) OnMessageErrorEvent(message *Message, err error) {
 // RegMessageLowExecEvent 在处理消息缓慢时将立即执行被注册的事件处理函数
 func (slf *event) RegMessageLowExecEvent(handler MessageLowExecEventHandler, priority ...int) {
 	slf.messageLowExecEventHandlers.Append(handler, slice.GetValue(priority, 0))
-	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
 }
 
 func (slf *event) OnMessageLowExecEvent(message *Message, cost time.Duration) {
@@ -296,7 +344,7 @@ func (slf *event) RegConnectionOpenedAfterEvent(handler ConnectionOpenedAfterEve
 		panic(ErrNetworkIncompatibleHttp)
 	}
 	slf.connectionOpenedAfterEventHandlers.Append(handler, slice.GetValue(priority, 0))
-	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
 }
 
 func (slf *event) OnConnectionOpenedAfterEvent(conn *Conn) {
@@ -308,31 +356,35 @@ func (slf *event) OnConnectionOpenedAfterEvent(conn *Conn) {
 	}, log.String("Event", "OnConnectionOpenedAfterEvent"))
 }
 
-// RegConnectionWritePacketBeforeEvent 在发送数据包前将立刻执行被注册的事件处理函数
+// RegConnectionWritePacketBeforeEvent 在发送数据包前将立刻执行被注册的事件处理函数，与 ConnectionReceivePacketEvent 相对应
+//   - 所有通过 Conn.Write 发送的数据包均会经过该事件，包括广播、心跳、踢出及网关转发的连接，适用于协议版本转换、数据包日志及统计等场景
+//   - 事件处理函数的返回值将作为新的数据包传递给下一个事件处理函数，并最终在压缩、加密及编码后发送
+//   - 当任一事件处理函数返回 nil 时，数据包将被丢弃，后续的事件处理函数将不会执行
 func (slf *event) RegConnectionWritePacketBeforeEvent(handler ConnectionWritePacketBeforeEventHandler, priority ...int) {
 	if slf.network == NetworkHttp {
 		panic(ErrNetworkIncompatibleHttp)
 	}
 	slf.connectionWritePacketBeforeHandlers.Append(handler, slice.GetValue(priority, 0))
-	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
 }
 
-func (slf *event) OnConnectionWritePacketBeforeEvent(conn *Conn, packet []byte) (newPacket []byte) {
+func (slf *event) OnConnectionWritePacketBeforeEvent(conn *Conn, packet []byte) (newPacket []byte, drop bool) {
 	if slf.connectionWritePacketBeforeHandlers.Len() == 0 {
-		return packet
+		return packet, false
 	}
 	newPacket = packet
 	slf.connectionWritePacketBeforeHandlers.RangeValue(func(index int, value ConnectionWritePacketBeforeEventHandler) bool {
 		newPacket = value(slf.Server, conn, newPacket)
-		return true
+		drop = newPacket == nil
+		return !drop
 	})
-	return newPacket
+	return newPacket, drop
 }
 
 // RegShuntChannelCreatedEvent 在分流通道创建时将立刻执行被注册的事件处理函数
 func (slf *event) RegShuntChannelCreatedEvent(handler ShuntChannelCreatedEventHandler, priority ...int) {
 	slf.shuntChannelCreatedEventHandlers.Append(handler, slice.GetValue(priority, 0))
-	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
 }
 
 func (slf *event) OnShuntChannelCreatedEvent(guid int64) {
@@ -347,7 +399,7 @@ func (slf *event) OnShuntChannelCreatedEvent(guid int64) {
 // RegShuntChannelCloseEvent 在分流通道关闭时将立刻执行被注册的事件处理函数
 func (slf *event) RegShuntChannelCloseEvent(handler ShuntChannelClosedEventHandler, priority ...int) {
 	slf.shuntChannelClosedEventHandlers.Append(handler, slice.GetValue(priority, 0))
-	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
 }
 
 func (slf *event) OnShuntChannelClosedEvent(guid int64) {
@@ -369,7 +421,7 @@ func (slf *event) OnShuntChannelClosedEvent(guid int64) {
 //   - 数据包分包等情况处理
 func (slf *event) RegConnectionPacketPreprocessEvent(handler ConnectionPacketPreprocessEventHandler, priority ...int) {
 	slf.connectionPacketPreprocessEventHandlers.Append(handler, slice.GetValue(priority, 0))
-	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
 }
 
 func (slf *event) OnConnectionPacketPreprocessEvent(conn *Conn, packet []byte, usePacket func(newPacket []byte)) bool {
@@ -393,7 +445,7 @@ func (slf *event) OnConnectionPacketPreprocessEvent(conn *Conn, packet []byte, u
 // 适用于限流等场景
 func (slf *event) RegMessageExecBeforeEvent(handler MessageExecBeforeEventHandler, priority ...int) {
 	slf.messageExecBeforeEventHandlers.Append(handler, slice.GetValue(priority, 0))
-	log.Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
 }
 
 func (slf *event) OnMessageExecBeforeEvent(message *Message) bool {
@@ -403,7 +455,7 @@ func (slf *event) OnMessageExecBeforeEvent(message *Message) bool {
 	var result = true
 	defer func() {
 		if err := recover(); err != nil {
-			log.Error("Server", log.String("OnMessageExecBeforeEvent", fmt.Sprintf("%v", err)))
+			slf.Logger().Error("Server", log.String("OnMessageExecBeforeEvent", fmt.Sprintf("%v", err)))
 			debug.PrintStack()
 		}
 	}()
@@ -425,7 +477,7 @@ func (slf *event) OnMessageReadyEvent() {
 	}
 	defer func() {
 		if err := recover(); err != nil {
-			log.Error("Server", log.String("OnMessageReadyEvent", fmt.Sprintf("%v", err)))
+			slf.Logger().Error("Server", log.String("OnMessageReadyEvent", fmt.Sprintf("%v", err)))
 			debug.PrintStack()
 		}
 	}()
@@ -435,12 +487,231 @@ func (slf *event) OnMessageReadyEvent() {
 	})
 }
 
+// RegConnectionIdleEvent 在连接空闲时间超过 WithHeartbeat 设定的超时时间时将立即执行被注册的事件处理函数
+//   - 事件处理完成后连接将被关闭
+func (slf *event) RegConnectionIdleEvent(handler ConnectionIdleEventHandler, priority ...int) {
+	slf.connectionIdleEventHandlers.Append(handler, slice.GetValue(priority, 0))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnConnectionIdleEvent(conn *Conn, idle time.Duration) {
+	slf.PushSystemMessage(func() {
+		if conn.IsClosed() {
+			return
+		}
+		slf.connectionIdleEventHandlers.RangeValue(func(index int, value ConnectionIdleEventHandler) bool {
+			value(slf.Server, conn, idle)
+			return true
+		})
+		conn.CloseWithReason(CloseReasonHeartbeatTimeout, ErrConnectionHeartbeatTimeout)
+	}, log.String("Event", "OnConnectionIdleEvent"))
+}
+
+// RegConnectionKickedEvent 在连接被服务器通过 Kick 踢出时将立即执行被注册的事件处理函数
+//   - 事件处理完成后将向连接发送 payload，并在写入队列中的数据发送完成后以 CloseReasonKicked 关闭连接
+//   - 可用于区分连接是被踢出还是异常断开
+func (slf *event) RegConnectionKickedEvent(handler ConnectionKickedEventHandler, priority ...int) {
+	slf.connectionKickedEventHandlers.Append(handler, slice.GetValue(priority, 0))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnConnectionKickedEvent(conn *Conn, code int, payload []byte) {
+	slf.PushSystemMessage(func() {
+		if conn.IsClosed() {
+			return
+		}
+		slf.connectionKickedEventHandlers.RangeValue(func(index int, value ConnectionKickedEventHandler) bool {
+			value(slf.Server, conn, code, payload)
+			return true
+		})
+		conn.flushAndClose(payload, CloseReasonKicked, ErrConnectionKicked)
+	}, log.String("Event", "OnConnectionKickedEvent"))
+}
+
+// RegConnectionRejectedEvent 在连接被 WithConnectionFilter 设置的连接过滤器拒绝时将立即执行被注册的事件处理函数
+func (slf *event) RegConnectionRejectedEvent(handler ConnectionRejectedEventHandler, priority ...int) {
+	slf.connectionRejectedEventHandlers.Append(handler, slice.GetValue(priority, 0))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnConnectionRejectedEvent(ip string, reason ConnectionRejectReason) {
+	slf.PushSystemMessage(func() {
+		slf.connectionRejectedEventHandlers.RangeValue(func(index int, value ConnectionRejectedEventHandler) bool {
+			value(slf.Server, ip, reason)
+			return true
+		})
+	}, log.String("Event", "OnConnectionRejectedEvent"))
+}
+
+// RegConnectionRateLimitedEvent 在连接接收数据包超出 WithPacketRateLimit 或 WithPacketByteRateLimit 设置的速率限制时将立即执行被注册的事件处理函数
+//   - 同一连接每秒最多触发一次
+//   - 当处理方式为 PacketRateLimitActionDisconnect 时，事件触发时连接已经关闭
+func (slf *event) RegConnectionRateLimitedEvent(handler ConnectionRateLimitedEventHandler, priority ...int) {
+	slf.connectionRateLimitedEventHandlers.Append(handler, slice.GetValue(priority, 0))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnConnectionRateLimitedEvent(conn *Conn, action PacketRateLimitAction) {
+	slf.PushSystemMessage(func() {
+		slf.connectionRateLimitedEventHandlers.RangeValue(func(index int, value ConnectionRateLimitedEventHandler) bool {
+			value(slf.Server, conn, action)
+			return true
+		})
+	}, log.String("Event", "OnConnectionRateLimitedEvent"))
+}
+
+// RegConnectionPacketOversizeEvent 在连接接收的数据包超出 WithMaxPacketSize 设置的最大长度时将立即执行被注册的事件处理函数
+//   - size 为超出限制的数据包长度，Websocket 无法获知实际长度，此时 size 为 -1
+//   - 事件触发时连接已经关闭
+func (slf *event) RegConnectionPacketOversizeEvent(handler ConnectionPacketOversizeEventHandler, priority ...int) {
+	slf.connectionPacketOversizeEventHandlers.Append(handler, slice.GetValue(priority, 0))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnConnectionPacketOversizeEvent(conn *Conn, size int) {
+	slf.PushSystemMessage(func() {
+		slf.connectionPacketOversizeEventHandlers.RangeValue(func(index int, value ConnectionPacketOversizeEventHandler) bool {
+			value(slf.Server, conn, size)
+			return true
+		})
+	}, log.String("Event", "OnConnectionPacketOversizeEvent"))
+}
+
+// RegConnectionPacketReplayEvent 在连接接收到重复或重放的数据包时将立即执行被注册的事件处理函数
+//   - 仅在通过 WithPacketSequence 启用序列号检查时生效，seq 为被拒绝的序列号
+//   - 被拒绝的数据包将被丢弃，连接不会被关闭
+func (slf *event) RegConnectionPacketReplayEvent(handler ConnectionPacketReplayEventHandler, priority ...int) {
+	slf.connectionPacketReplayEventHandlers.Append(handler, slice.GetValue(priority, 0))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnConnectionPacketReplayEvent(conn *Conn, seq uint64) {
+	slf.PushSystemMessage(func() {
+		slf.connectionPacketReplayEventHandlers.RangeValue(func(index int, value ConnectionPacketReplayEventHandler) bool {
+			value(slf.Server, conn, seq)
+			return true
+		})
+	}, log.String("Event", "OnConnectionPacketReplayEvent"))
+}
+
 func (slf *event) check() {
 	switch slf.network {
 	case NetworkHttp, NetworkGRPC, NetworkNone:
 	default:
 		if slf.connectionReceivePacketEventHandlers.Len() == 0 {
-			log.Warn("Server", log.String("ConnectionReceivePacketEvent", "invalid server, no packets processed"))
+			slf.Logger().Warn("Server", log.String("ConnectionReceivePacketEvent", "invalid server, no packets processed"))
 		}
 	}
 }
+
+// RegReceiveCrossPacketEvent 在接收到跨服数据包时将立即执行被注册的事件处理函数
+//   - crossName 为接收到数据包的跨服名称，senderServerId 为发送方服务器 ID
+func (slf *event) RegReceiveCrossPacketEvent(handler ReceiveCrossPacketEventHandler, priority ...int) {
+	slf.receiveCrossPacketEventHandlers.Append(handler, slice.GetValue(priority, 0))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnReceiveCrossPacketEvent(crossName, senderServerId string, packet []byte) {
+	slf.receiveCrossPacketEventHandlers.RangeValue(func(index int, value ReceiveCrossPacketEventHandler) bool {
+		value(slf.Server, crossName, senderServerId, packet)
+		return true
+	})
+}
+
+// RegCrossRequestEvent 在接收到其他服务器通过 Server.CallCross 发起的跨服请求时将立即执行被注册的事件处理函数
+//   - 通过 reply 对请求进行响应，reply 可在异步消息中调用，仅首次调用有效，重复调用将返回 ErrCrossRepliedAlready
+//   - 当未调用 reply 时，请求方将在等待超时后返回 ErrCrossCallTimeout
+func (slf *event) RegCrossRequestEvent(handler CrossRequestEventHandler, priority ...int) {
+	slf.crossRequestEventHandlers.Append(handler, slice.GetValue(priority, 0))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnCrossRequestEvent(crossName, senderServerId string, packet []byte, reply func(packet []byte) error) {
+	if slf.crossRequestEventHandlers.Len() == 0 {
+		slf.Logger().Warn("Server", log.String("CrossRequestEvent", "no handler registered, the request will not be replied"), log.String("Cross", crossName), log.String("ServerID", senderServerId))
+		return
+	}
+	slf.crossRequestEventHandlers.RangeValue(func(index int, value CrossRequestEventHandler) bool {
+		value(slf.Server, crossName, senderServerId, packet, reply)
+		return true
+	})
+}
+
+// RegMessageTimeoutEvent 在消息执行超过 WithMessageExecTimeout 设置的超时时间时将立即执行被注册的事件处理函数
+//   - 事件处理函数将在独立的协程中执行，此时超时的消息可能仍在执行中，需要注意并发安全
+//   - message 为消息执行前的快照，可用于获取消息类型及日志标记等信息
+func (slf *event) RegMessageTimeoutEvent(handler MessageTimeoutEventHandler, priority ...int) {
+	slf.messageTimeoutEventHandlers.Append(handler, slice.GetValue(priority, 0))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnMessageTimeoutEvent(message *Message, timeout time.Duration) {
+	slf.messageTimeoutEventHandlers.RangeValue(func(index int, value MessageTimeoutEventHandler) bool {
+		value(slf.Server, message, timeout)
+		return true
+	})
+}
+
+// RegConnectionPanicEvent 在处理连接数据包或连接相关消息的过程中发生 panic 时将立即执行被注册的事件处理函数
+//   - 事件处理函数执行后将根据 WithPanicPolicy 设置的策略决定继续处理、关闭连接或关闭服务器
+//   - stack 为发生 panic 时的堆栈信息
+func (slf *event) RegConnectionPanicEvent(handler ConnectionPanicEventHandler, priority ...int) {
+	slf.connectionPanicEventHandlers.Append(handler, slice.GetValue(priority, 0))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnConnectionPanicEvent(conn *Conn, err any, stack []byte) {
+	slf.connectionPanicEventHandlers.RangeValue(func(index int, value ConnectionPanicEventHandler) bool {
+		value(slf.Server, conn, err, stack)
+		return true
+	})
+}
+
+// RegShutdownBeforeEvent 在服务器开始关闭前将立即执行被注册的事件处理函数
+//   - 当任一事件处理函数返回 false 时，将取消本次关闭，服务器将继续运行
+//   - 事件处理函数执行期间服务器仍将正常处理消息，可在其中阻塞等待以延迟关闭，例如等待玩家数据落地
+//   - 由于错误或上下文取消导致的关闭无法被取消，此时返回值将被忽略，其中由于错误导致的关闭 err 不为空
+//   - 不应在消息分发器中调用 Server.Shutdown 后同步等待该事件
+func (slf *event) RegShutdownBeforeEvent(handler ShutdownBeforeEventHandler, priority ...int) {
+	slf.shutdownBeforeEventHandlers.Append(handler, slice.GetValue(priority, 0))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnShutdownBeforeEvent(err error) bool {
+	var allow = true
+	slf.shutdownBeforeEventHandlers.RangeValue(func(index int, value ShutdownBeforeEventHandler) bool {
+		if !value(slf.Server, err) {
+			allow = false
+		}
+		return true
+	})
+	return allow
+}
+
+// RegListenErrorEvent 在服务器的侦听器运行失败时将立即执行被注册的事件处理函数，随后服务器将由于该错误而关闭
+//   - 包括通过 Server.AddListener 添加的侦听器
+func (slf *event) RegListenErrorEvent(handler ListenErrorEventHandler, priority ...int) {
+	slf.listenErrorEventHandlers.Append(handler, slice.GetValue(priority, 0))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnListenErrorEvent(network Network, addr string, err error) {
+	slf.listenErrorEventHandlers.RangeValue(func(index int, value ListenErrorEventHandler) bool {
+		value(slf.Server, network, addr, err)
+		return true
+	})
+}
+
+// RegConnectionBoundEvent 在通过 BindPlayer 将玩家绑定到连接时将立即执行被注册的事件处理函数
+//   - 事件处理函数将在调用 BindPlayer 的协程中执行
+func (slf *event) RegConnectionBoundEvent(handler ConnectionBoundEventHandler, priority ...int) {
+	slf.connectionBoundEventHandlers.Append(handler, slice.GetValue(priority, 0))
+	slf.Logger().Info("Server", log.String("RegEvent", runtimes.CurrentRunningFuncName()), log.String("handler", reflect.TypeOf(handler).String()))
+}
+
+func (slf *event) OnConnectionBoundEvent(conn *Conn, player any) {
+	slf.connectionBoundEventHandlers.RangeValue(func(index int, value ConnectionBoundEventHandler) bool {
+		value(slf.Server, conn, player)
+		return true
+	})
+}
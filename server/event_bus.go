@@ -0,0 +1,106 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/kercylan98/minotaur/utils/slice"
+	"sync"
+)
+
+// Topic 事件总线中的强类型主题，主题的名称及事件类型共同确定一个主题
+//   - 例如：var LevelUpTopic = server.Topic[LevelUpEvent]("level-up")
+type Topic[T any] string
+
+// NewEventBus 创建一个未关联服务器的事件总线，此时 PublishQueued 将退化为 Publish
+//   - 通常应使用通过 Server.EventBus 获取的事件总线
+func NewEventBus() *EventBus {
+	return newEventBus(nil)
+}
+
+func newEventBus(srv *Server) *EventBus {
+	return &EventBus{
+		srv:    srv,
+		topics: map[any]*slice.Priority[*eventBusSubscriber]{},
+	}
+}
+
+// EventBus 进程内的事件总线，用于游戏系统之间（例如升级、获得物品）的解耦通讯，与网络事件相互独立
+//   - 通过 Subscribe 订阅主题，通过 Publish 或 PublishQueued 发布事件
+type EventBus struct {
+	srv    *Server
+	lock   sync.RWMutex
+	topics map[any]*slice.Priority[*eventBusSubscriber]
+}
+
+// eventBusSubscriber 事件总线的订阅者，handler 的类型为 func(event T)
+type eventBusSubscriber struct {
+	handler any
+}
+
+// subscribers 获取主题的订阅者快照
+func (slf *EventBus) subscribers(topic any) []*eventBusSubscriber {
+	slf.lock.RLock()
+	defer slf.lock.RUnlock()
+	subscribers, exist := slf.topics[topic]
+	if !exist {
+		return nil
+	}
+	return subscribers.Slice()
+}
+
+// EventBus 获取服务器的事件总线，通过 PublishQueued 发布的事件将在系统分发器中处理
+func (slf *Server) EventBus() *EventBus {
+	return slf.eventBus
+}
+
+// Subscribe 订阅事件总线中的主题，返回的 unsubscribe 函数可用于取消订阅
+//   - priority 为订阅者的优先级，值越小越先执行，默认为 0
+func Subscribe[T any](bus *EventBus, topic Topic[T], handler func(event T), priority ...int) (unsubscribe func()) {
+	subscriber := &eventBusSubscriber{handler: handler}
+	bus.lock.Lock()
+	subscribers, exist := bus.topics[topic]
+	if !exist {
+		subscribers = slice.NewPriority[*eventBusSubscriber]()
+		bus.topics[topic] = subscribers
+	}
+	subscribers.Append(subscriber, slice.GetValue(priority, 0))
+	bus.lock.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			bus.lock.Lock()
+			defer bus.lock.Unlock()
+			subscribers.Action(func(items []*slice.PriorityItem[*eventBusSubscriber]) []*slice.PriorityItem[*eventBusSubscriber] {
+				for i, item := range items {
+					if item.Value() == subscriber {
+						return append(items[:i], items[i+1:]...)
+					}
+				}
+				return items
+			})
+			if subscribers.Len() == 0 {
+				delete(bus.topics, topic)
+			}
+		})
+	}
+}
+
+// Publish 向事件总线中的主题发布事件，订阅者将在当前协程中按优先级依次同步执行
+func Publish[T any](bus *EventBus, topic Topic[T], event T) {
+	for _, subscriber := range bus.subscribers(topic) {
+		subscriber.handler.(func(event T))(event)
+	}
+}
+
+// PublishQueued 向事件总线中的主题发布事件，订阅者将以 MessageTypeSystem 消息在系统分发器中执行，避免与其他消息产生并发问题
+//   - 适用于在异步任务等消息循环以外的协程中发布事件的场景
+//   - 订阅者将在消息执行时确定，当事件总线未关联服务器时将同步执行
+func PublishQueued[T any](bus *EventBus, topic Topic[T], event T) {
+	if bus.srv == nil {
+		Publish(bus, topic, event)
+		return
+	}
+	bus.srv.PushSystemMessage(func() {
+		Publish(bus, topic, event)
+	}, log.String("EventBus", string(topic)))
+}
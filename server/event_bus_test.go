@@ -0,0 +1,57 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+type levelUpEvent struct {
+	Level int
+}
+
+func TestEventBus(t *testing.T) {
+	var levelUp = server.Topic[levelUpEvent]("level-up")
+	var levelUpCount = server.Topic[int]("level-up")
+	bus := server.NewEventBus()
+
+	var received []int
+	server.Subscribe(bus, levelUp, func(event levelUpEvent) {
+		received = append(received, event.Level)
+	})
+	unsubscribe := server.Subscribe(bus, levelUp, func(event levelUpEvent) {
+		received = append(received, -event.Level)
+	}, -1)
+	server.Subscribe(bus, levelUpCount, func(event int) {
+		t.Fatal("topics with the same name but different event types should be isolated")
+	})
+
+	server.Publish(bus, levelUp, levelUpEvent{Level: 2})
+	unsubscribe()
+	unsubscribe()
+	server.PublishQueued(bus, levelUp, levelUpEvent{Level: 3})
+	if len(received) != 3 || received[0] != -2 || received[1] != 2 || received[2] != 3 {
+		t.Fatalf("unexpected events: %v", received)
+	}
+}
+
+func TestServer_EventBus(t *testing.T) {
+	srv := server.New(server.NetworkNone)
+	runServer(t, srv, "")
+	defer srv.Shutdown()
+
+	var levelUp = server.Topic[levelUpEvent]("level-up")
+	var received = make(chan int, 1)
+	server.Subscribe(srv.EventBus(), levelUp, func(event levelUpEvent) {
+		received <- event.Level
+	})
+	go server.PublishQueued(srv.EventBus(), levelUp, levelUpEvent{Level: 5})
+	select {
+	case level := <-received:
+		if level != 5 {
+			t.Fatalf("expected level 5, got %d", level)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("queued event was not delivered")
+	}
+}
@@ -52,6 +52,7 @@ type Endpoint struct {
 	connections *haxmap.Map[string, *server.Conn]  // 被该端点转发的连接列表
 	rci         time.Duration                      // 端点重连间隔
 	cps         int                                // 端点连接池大小
+	linked      sync.Map                           // 已发送鉴权数据包的端点客户端
 }
 
 // start 开始与目标服务端点建立连接
@@ -80,22 +81,32 @@ func (slf *Endpoint) connect(gateway *Gateway) {
 	for _, cli := range slf.client {
 		go func(cli *client.Client) {
 			cli.RegConnectionOpenedEvent(func(conn *client.Client) {
+				// 鉴权数据包需先于任何转发数据包写入，因此在写入后才允许该客户端被用于转发
+				conn.Write(MarshalGatewayAuthPacket(slf.gateway.token))
+				slf.linked.Store(conn, struct{}{})
 				slf.gateway.OnEndpointConnectOpenedEvent(slf.gateway, slf)
 			})
 			cli.RegConnectionClosedEvent(func(conn *client.Client, err any) {
+				slf.linked.Delete(conn)
 				slf.gateway.OnEndpointConnectClosedEvent(slf.gateway, slf)
 				slf.start(cli)
 			})
 			cli.RegConnectionReceivePacketEvent(func(conn *client.Client, wst int, packet []byte) {
-				addr, sendTime, packet, err := UnmarshalGatewayInPacket(packet)
+				if connId, err := UnmarshalGatewayInClosePacket(packet); err == nil {
+					if c, ok := slf.connections.Get(connId); ok {
+						c.Close()
+					}
+					return
+				}
+				connId, sendTime, packet, err := UnmarshalGatewayInPacket(packet)
 				if err != nil {
 					log.Error("Endpoint", log.String("Action", "ReceivePacket"), log.String("Name", slf.name), log.String("Addr", slf.address), log.Err(err))
 					return
 				}
 				slf.state.Swap(slf.evaluator(float64(time.Now().UnixNano() - sendTime)))
-				c, ok := slf.connections.Get(addr)
+				c, ok := slf.connections.Get(connId)
 				if !ok {
-					log.Error("Endpoint", log.String("Action", "ReceivePacket"), log.String("Name", slf.name), log.String("Addr", slf.address), log.String("ConnAddr", addr), log.Err(ErrConnectionNotFount))
+					log.Error("Endpoint", log.String("Action", "ReceivePacket"), log.String("Name", slf.name), log.String("Addr", slf.address), log.String("ConnID", connId), log.Err(ErrConnectionNotFount))
 					return
 				}
 				c.SetWST(wst)
@@ -135,12 +146,13 @@ func (slf *Endpoint) Forward(conn *server.Conn, packet []byte, callback ...func(
 		return
 	}
 
-	var superior *client.Client
-	for _, cli := range slf.client {
-		if cli.IsConnected() {
-			superior = cli
-			break
+	var superior = slf.superior()
+	if superior == nil {
+		slf.connections.Del(conn.GetID())
+		if len(callback) > 0 {
+			callback[0](ErrEndpointUnavailable)
 		}
+		return
 	}
 
 	var cb = func(err error) {
@@ -163,3 +175,25 @@ func (slf *Endpoint) Forward(conn *server.Conn, packet []byte, callback ...func(
 		superior.Write(packet, cb)
 	}
 }
+
+// superior 获取一个已连接且已发送鉴权数据包的端点客户端，当不存在可用的客户端时将返回 nil
+func (slf *Endpoint) superior() *client.Client {
+	for _, cli := range slf.client {
+		if _, linked := slf.linked.Load(cli); linked && cli.IsConnected() {
+			return cli
+		}
+	}
+	return nil
+}
+
+// release 移除被转发的连接，并通知端点该连接已断开
+func (slf *Endpoint) release(connId string) {
+	slf.connections.Del(connId)
+	packet, err := MarshalGatewayOutClosePacket(connId)
+	if err != nil {
+		return
+	}
+	if superior := slf.superior(); superior != nil {
+		superior.Write(packet)
+	}
+}
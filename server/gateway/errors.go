@@ -9,6 +9,12 @@ var (
 	ErrGatewayClosed = errors.New("gateway: gateway closed")
 	// ErrGatewayRunning 网关正在运行
 	ErrGatewayRunning = errors.New("gateway: gateway running")
+	// ErrEndpointUnavailable 端点不存在可用的连接
+	ErrEndpointUnavailable = errors.New("gateway: endpoint has no available connection")
+	// ErrGatewayLinkClosed 承载虚拟连接的网关连接已断开
+	ErrGatewayLinkClosed = errors.New("gateway: gateway link closed")
+	// ErrGatewayLinkUnauthorized 连接未通过网关鉴权却发送了网关数据包，或鉴权令牌不匹配
+	ErrGatewayLinkUnauthorized = errors.New("gateway: gateway link unauthorized")
 	// ErrConnectionNotFount 该端点下不存在该连接
 	ErrConnectionNotFount = errors.New("gateway: connection not found")
 )
@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/log"
 	"github.com/kercylan98/minotaur/utils/random"
 	"math"
 	"sync"
@@ -41,6 +42,8 @@ func NewGateway(srv *server.Server, scanner Scanner, options ...Option) *Gateway
 //   - 支持将客户端网络类型进行不同的转换，例如：客户端使用 Websocket 连接，但是网关服务器可以将其转换为 TCP 端点的连接
 //   - 支持客户端消息绑定，在客户端未断开连接的情况下，可以将客户端的连接绑定到某个端点，这样该客户端的所有消息都会转发到该端点
 //   - 根据端点延迟实时调整端点状态评分，根据评分选择最优的端点，默认评分算法为：1 / (1 + 1.5 * ${DelaySeconds})
+//   - 通过 WithForward 可将客户端数据包自动转发至端点，端点服务器可通过 NewNode 以虚拟连接的方式处理转发的数据包并随时推送数据
+//   - 客户端断开连接时将通知曾转发过该连接的端点，端点也可以通过关闭虚拟连接要求网关断开客户端连接
 type Gateway struct {
 	*events
 	srv     *server.Server                  // 网关服务器核心
@@ -52,6 +55,8 @@ type Gateway struct {
 	running bool                            // 网关是否正在运行
 	cce     map[string]*Endpoint            // 连接当前连接的端点 [conn.ID]
 	cceLock sync.RWMutex                    // 连接当前连接的端点锁
+	forward string                          // 自动转发的端点名称
+	token   string                          // 网关连接鉴权令牌
 }
 
 // Run 运行网关
@@ -94,12 +99,26 @@ func (slf *Gateway) Run(addr string) error {
 	slf.srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
 		slf.OnConnectionOpenedEvent(slf, conn)
 	}, math.MinInt)
-	slf.srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+	slf.srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
 		slf.OnConnectionClosedEvent(slf, conn)
+		slf.release(conn)
 	}, math.MinInt)
 	slf.srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
 		slf.OnConnectionReceivePacketEvent(slf, conn, packet)
 	}, math.MinInt)
+	if len(slf.forward) > 0 {
+		slf.RegConnectionReceivePacketEventHandle(func(gateway *Gateway, conn *server.Conn, packet []byte) {
+			endpoint, err := gateway.GetConnEndpoint(gateway.forward, conn)
+			if err != nil {
+				log.Error("Gateway", log.String("Action", "Forward"), log.String("Name", gateway.forward), log.String("ConnID", conn.GetID()), log.Err(err))
+				return
+			}
+			endpoint.Forward(conn, packet)
+		}, math.MinInt)
+		slf.RegEndpointConnectReceivePacketEventHandle(func(gateway *Gateway, endpoint *Endpoint, conn *server.Conn, packet []byte) {
+			conn.Write(packet)
+		}, math.MinInt)
+	}
 	slf.running = true
 	if err := slf.srv.Run(addr); err != nil {
 		return err
@@ -177,3 +196,25 @@ func (slf *Gateway) SwitchEndpoint(source, dest *Endpoint) {
 	}
 	slf.cceLock.Unlock()
 }
+
+// release 清理客户端连接的转发记录，并通知曾转发过该连接的端点连接已断开
+func (slf *Gateway) release(conn *server.Conn) {
+	var id = conn.GetID()
+	slf.cceLock.Lock()
+	delete(slf.cce, id)
+	slf.cceLock.Unlock()
+
+	var endpoints []*Endpoint
+	slf.esm.Lock()
+	for _, es := range slf.es {
+		for _, endpoint := range es {
+			if _, exist := endpoint.connections.Get(id); exist {
+				endpoints = append(endpoints, endpoint)
+			}
+		}
+	}
+	slf.esm.Unlock()
+	for _, endpoint := range endpoints {
+		endpoint.release(id)
+	}
+}
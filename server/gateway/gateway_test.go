@@ -6,6 +6,7 @@ import (
 	"github.com/kercylan98/minotaur/server/client"
 	"github.com/kercylan98/minotaur/server/gateway"
 	"github.com/kercylan98/minotaur/utils/super"
+	"os"
 	"testing"
 	"time"
 )
@@ -25,22 +26,25 @@ func (slf *Scanner) GetInterval() time.Duration {
 }
 
 func TestGateway_RunEndpointServerA(t *testing.T) {
+	if len(os.Getenv("MINOTAUR_TEST_MANUAL")) == 0 {
+		t.Skip("manual test, set MINOTAUR_TEST_MANUAL to run it")
+	}
 	srv := server.New(server.NetworkWebsocket, server.WithDeadlockDetect(time.Second*3))
 	srv.RegConnectionPacketPreprocessEvent(func(srv *server.Server, conn *server.Conn, packet []byte, abort func(), usePacket func(newPacket []byte)) {
-		addr, packet, err := gateway.UnmarshalGatewayOutPacket(packet)
+		connId, packet, err := gateway.UnmarshalGatewayOutPacket(packet)
 		if err != nil {
 			// 非网关的普通数据包
 			return
 		}
 		usePacket(packet)
-		conn.SetMessageData("gw-addr", addr)
+		conn.SetMessageData("gw-conn", connId)
 	})
 	srv.RegConnectionWritePacketBeforeEvent(func(srv *server.Server, conn *server.Conn, packet []byte) []byte {
-		addr, ok := conn.GetMessageData("gw-addr").(string)
+		connId, ok := conn.GetMessageData("gw-conn").(string)
 		if !ok {
 			return packet
 		}
-		packet, err := gateway.MarshalGatewayInPacket(addr, time.Now().Unix(), packet)
+		packet, err := gateway.MarshalGatewayInPacket(connId, time.Now().Unix(), packet)
 		if err != nil {
 			panic(err)
 		}
@@ -55,22 +59,25 @@ func TestGateway_RunEndpointServerA(t *testing.T) {
 }
 
 func TestGateway_RunEndpointServerB(t *testing.T) {
+	if len(os.Getenv("MINOTAUR_TEST_MANUAL")) == 0 {
+		t.Skip("manual test, set MINOTAUR_TEST_MANUAL to run it")
+	}
 	srv := server.New(server.NetworkWebsocket, server.WithDeadlockDetect(time.Second*3))
 	srv.RegConnectionPacketPreprocessEvent(func(srv *server.Server, conn *server.Conn, packet []byte, abort func(), usePacket func(newPacket []byte)) {
-		addr, packet, err := gateway.UnmarshalGatewayOutPacket(packet)
+		connId, packet, err := gateway.UnmarshalGatewayOutPacket(packet)
 		if err != nil {
 			// 非网关的普通数据包
 			return
 		}
 		usePacket(packet)
-		conn.SetMessageData("gw-addr", addr)
+		conn.SetMessageData("gw-conn", connId)
 	})
 	srv.RegConnectionWritePacketBeforeEvent(func(srv *server.Server, conn *server.Conn, packet []byte) []byte {
-		addr, ok := conn.GetMessageData("gw-addr").(string)
+		connId, ok := conn.GetMessageData("gw-conn").(string)
 		if !ok {
 			return packet
 		}
-		packet, err := gateway.MarshalGatewayInPacket(addr, time.Now().Unix(), packet)
+		packet, err := gateway.MarshalGatewayInPacket(connId, time.Now().Unix(), packet)
 		if err != nil {
 			panic(err)
 		}
@@ -85,6 +92,9 @@ func TestGateway_RunEndpointServerB(t *testing.T) {
 }
 
 func TestGateway_Run(t *testing.T) {
+	if len(os.Getenv("MINOTAUR_TEST_MANUAL")) == 0 {
+		t.Skip("manual test, set MINOTAUR_TEST_MANUAL to run it")
+	}
 	gw := gateway.NewGateway(server.New(server.NetworkWebsocket, server.WithDeadlockDetect(time.Second*3)), new(Scanner))
 	gw.RegConnectionReceivePacketEventHandle(func(gateway *gateway.Gateway, conn *server.Conn, packet []byte) {
 		endpoint, err := gateway.GetConnEndpoint("test", conn)
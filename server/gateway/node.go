@@ -0,0 +1,177 @@
+package gateway
+
+import (
+	"crypto/subtle"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/log"
+	"math"
+	"sync"
+	"time"
+)
+
+// NewNode 基于 server.Server 创建网关后端节点，用于处理由 Gateway 转发而来的数据包
+//   - 节点会为网关转发的每一个客户端连接创建一个虚拟连接，虚拟连接与普通连接一样会触发 ConnectionOpenedEvent、ConnectionReceivePacketEvent 及 ConnectionClosedEvent
+//   - 对虚拟连接的写入将被封装为网关入网数据包并通过网关连接写回网关，由网关转发给客户端，因此可以在任意时刻向虚拟连接推送数据
+//   - 当客户端与网关断开连接时，对应的虚拟连接将以 server.CloseReasonClientClose 关闭；当节点主动关闭虚拟连接时，网关也将断开对应的客户端连接
+//   - 当网关连接断开时，经由该网关连接转发的所有虚拟连接都将被关闭
+//   - 网关连接需要首先发送携带 token 的鉴权数据包，鉴权令牌需与网关通过 WithLinkToken 设置的令牌一致
+//   - 未通过鉴权的连接发送网关数据包或鉴权失败时，该连接将以 ErrGatewayLinkUnauthorized 被关闭，避免普通客户端伪造虚拟连接
+//   - 非网关数据包将被视为普通直连数据包，不受影响
+//   - 当 token 为空时将会发生 panic
+func NewNode(srv *server.Server, token string) *Node {
+	if len(token) == 0 {
+		panic("gateway: node token must not be empty")
+	}
+	node := &Node{
+		srv:      srv,
+		token:    []byte(token),
+		links:    make(map[string]map[string]*server.Conn),
+		virtuals: make(map[string]nodeVirtual),
+	}
+	srv.RegConnectionPacketPreprocessEvent(node.onPacketPreprocess, math.MinInt)
+	srv.RegConnectionClosedEvent(node.onConnectionClosed, math.MinInt)
+	return node
+}
+
+// Node 网关后端节点
+type Node struct {
+	srv      *server.Server
+	token    []byte                             // 网关连接鉴权令牌
+	links    map[string]map[string]*server.Conn // 已通过鉴权的网关连接转发的虚拟连接 [link.ID][connId]
+	virtuals map[string]nodeVirtual             // 虚拟连接信息 [virtual.ID]
+	rw       sync.RWMutex
+}
+
+// nodeVirtual 虚拟连接信息
+type nodeVirtual struct {
+	link   *server.Conn // 承载该虚拟连接的网关连接
+	connId string       // 客户端在网关中的连接 ID
+}
+
+// Server 获取节点服务器核心
+func (slf *Node) Server() *server.Server {
+	return slf.srv
+}
+
+// IsVirtual 检查连接是否为由网关转发的虚拟连接
+func (slf *Node) IsVirtual(conn *server.Conn) bool {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	_, exist := slf.virtuals[conn.GetID()]
+	return exist
+}
+
+// GetGatewayConnID 获取虚拟连接所对应的客户端在网关中的连接 ID
+func (slf *Node) GetGatewayConnID(conn *server.Conn) (connId string, exist bool) {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	virtual, exist := slf.virtuals[conn.GetID()]
+	return virtual.connId, exist
+}
+
+// GetVirtualCount 获取当前虚拟连接的数量
+func (slf *Node) GetVirtualCount() int {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	return len(slf.virtuals)
+}
+
+// IsLink 检查连接是否为已通过鉴权的网关连接
+func (slf *Node) IsLink(conn *server.Conn) bool {
+	slf.rw.RLock()
+	defer slf.rw.RUnlock()
+	_, exist := slf.links[conn.GetID()]
+	return exist
+}
+
+// onPacketPreprocess 对网关数据包进行解析并推送至对应的虚拟连接
+func (slf *Node) onPacketPreprocess(srv *server.Server, conn *server.Conn, packet []byte, abort func(), usePacket func(newPacket []byte)) {
+	if token, err := UnmarshalGatewayAuthPacket(packet); err == nil {
+		abort()
+		if subtle.ConstantTimeCompare([]byte(token), slf.token) != 1 {
+			conn.CloseWithReason(server.CloseReasonUnauthorized, ErrGatewayLinkUnauthorized)
+			return
+		}
+		slf.rw.Lock()
+		if _, exist := slf.links[conn.GetID()]; !exist {
+			slf.links[conn.GetID()] = make(map[string]*server.Conn)
+		}
+		slf.rw.Unlock()
+		return
+	}
+	closeConnId, closeErr := UnmarshalGatewayOutClosePacket(packet)
+	connId, packet, err := UnmarshalGatewayOutPacket(packet)
+	if closeErr != nil && err != nil {
+		// 非网关的普通数据包
+		return
+	}
+	abort()
+	if !slf.IsLink(conn) {
+		conn.CloseWithReason(server.CloseReasonUnauthorized, ErrGatewayLinkUnauthorized)
+		return
+	}
+	if closeErr == nil {
+		slf.rw.RLock()
+		virtual, exist := slf.links[conn.GetID()][closeConnId]
+		slf.rw.RUnlock()
+		if exist {
+			virtual.CloseWithReason(server.CloseReasonClientClose)
+		}
+		return
+	}
+	if virtual := slf.open(conn, connId); virtual != nil {
+		srv.PushPacketMessage(virtual, conn.GetWST(), packet)
+	}
+}
+
+// open 获取网关连接中特定客户端的虚拟连接，当虚拟连接不存在时将创建并使其上线
+//   - 当网关连接已断开时将返回 nil
+func (slf *Node) open(link *server.Conn, connId string) *server.Conn {
+	slf.rw.Lock()
+	conns, exist := slf.links[link.GetID()]
+	if !exist {
+		slf.rw.Unlock()
+		return nil
+	}
+	virtual, exist := conns[connId]
+	if exist {
+		slf.rw.Unlock()
+		return virtual
+	}
+	virtual = server.NewGatewayConn(slf.srv, link.GetIP(), func(packet []byte) {
+		packet, err := MarshalGatewayInPacket(connId, time.Now().UnixNano(), packet)
+		if err != nil {
+			log.Error("Node", log.String("Action", "Write"), log.String("ConnID", connId), log.Err(err))
+			return
+		}
+		link.Write(packet)
+	})
+	conns[connId] = virtual
+	slf.virtuals[virtual.GetID()] = nodeVirtual{link: link, connId: connId}
+	slf.rw.Unlock()
+	slf.srv.OnConnectionOpenedEvent(virtual)
+	return virtual
+}
+
+// onConnectionClosed 清理已关闭的网关连接及虚拟连接
+func (slf *Node) onConnectionClosed(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
+	slf.rw.Lock()
+	if virtual, exist := slf.virtuals[conn.GetID()]; exist {
+		delete(slf.virtuals, conn.GetID())
+		delete(slf.links[virtual.link.GetID()], virtual.connId)
+		slf.rw.Unlock()
+		if reason != server.CloseReasonClientClose {
+			// 由节点主动关闭的虚拟连接需要通知网关断开客户端连接
+			if packet, err := MarshalGatewayInClosePacket(virtual.connId); err == nil {
+				virtual.link.Write(packet)
+			}
+		}
+		return
+	}
+	conns := slf.links[conn.GetID()]
+	delete(slf.links, conn.GetID())
+	slf.rw.Unlock()
+	for _, virtual := range conns {
+		virtual.CloseWithReason(reason, ErrGatewayLinkClosed)
+	}
+}
@@ -0,0 +1,153 @@
+package gateway_test
+
+import (
+	"bytes"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/server/gateway"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+type nodeTestWriter chan []byte
+
+func (slf nodeTestWriter) Write(p []byte) (int, error) {
+	slf <- bytes.Clone(p)
+	return len(p), nil
+}
+
+func TestNode(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket)
+	node := gateway.NewNode(srv, "secret")
+	var closed = make(chan server.CloseReason, 1)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		if !node.IsVirtual(conn) {
+			t.Error("expected a virtual connection")
+		}
+		conn.Write(append([]byte("echo:"), packet...))
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
+		if node.IsVirtual(conn) {
+			t.Error("virtual connection should be released before the closed event")
+		}
+		closed <- reason
+	})
+
+	var writer = make(nodeTestWriter, 8)
+	var link *server.Bot
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		link = server.NewBot(srv, server.WithBotNetworkDelay(1, 0), server.WithBotWriter(func(bot *server.Bot) io.Writer {
+			return writer
+		}))
+		link.JoinServer()
+	})
+	runServer(t, srv, freeAddr(t))
+	time.Sleep(100 * time.Millisecond)
+
+	link.SendPacket(gateway.MarshalGatewayAuthPacket("secret"))
+	packet, _ := gateway.MarshalGatewayOutPacket("client-1", []byte("hello"))
+	link.SendPacket(packet)
+	select {
+	case data := <-writer:
+		connId, _, packet, err := gateway.UnmarshalGatewayInPacket(data)
+		if err != nil || connId != "client-1" || string(packet) != "echo:hello" {
+			t.Fatalf("unexpected packet: %s %q %v", connId, packet, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("packet was not written back to the gateway")
+	}
+	if count := node.GetVirtualCount(); count != 1 {
+		t.Fatalf("expected 1 virtual connection, got %d", count)
+	}
+
+	packet, _ = gateway.MarshalGatewayOutClosePacket("client-1")
+	link.SendPacket(packet)
+	select {
+	case reason := <-closed:
+		if reason != server.CloseReasonClientClose {
+			t.Fatalf("expected %s, got %s", server.CloseReasonClientClose, reason)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("virtual connection was not closed")
+	}
+	if count := node.GetVirtualCount(); count != 0 {
+		t.Fatalf("expected 0 virtual connection, got %d", count)
+	}
+	srv.Shutdown()
+}
+
+func TestNode_Unauthorized(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket)
+	node := gateway.NewNode(srv, "secret")
+	var closed = make(chan server.CloseReason, 2)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		t.Errorf("unexpected packet from an unauthorized link: %q", packet)
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
+		if err != gateway.ErrGatewayLinkUnauthorized {
+			t.Errorf("expected %v, got %v", gateway.ErrGatewayLinkUnauthorized, err)
+		}
+		closed <- reason
+	})
+
+	var spoof, wrong *server.Bot
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		spoof = server.NewBot(srv, server.WithBotNetworkDelay(1, 0))
+		wrong = server.NewBot(srv, server.WithBotNetworkDelay(1, 0))
+		spoof.JoinServer()
+		wrong.JoinServer()
+	})
+	runServer(t, srv, freeAddr(t))
+	defer srv.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	// 未鉴权的连接伪造网关数据包，以及鉴权令牌错误的连接均应被关闭
+	packet, _ := gateway.MarshalGatewayOutPacket("client-1", []byte("hello"))
+	spoof.SendPacket(packet)
+	wrong.SendPacket(gateway.MarshalGatewayAuthPacket("guess"))
+	for i := 0; i < 2; i++ {
+		select {
+		case reason := <-closed:
+			if reason != server.CloseReasonUnauthorized {
+				t.Fatalf("expected %s, got %s", server.CloseReasonUnauthorized, reason)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("unauthorized link was not closed")
+		}
+	}
+	if count := node.GetVirtualCount(); count != 0 {
+		t.Fatalf("expected 0 virtual connection, got %d", count)
+	}
+}
+
+// freeAddr 获取本地空闲 TCP 端口的地址
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+// runServer 在新的协程中运行服务器并等待启动完成，运行失败或启动超时时将终止测试
+func runServer(t *testing.T, srv *server.Server, addr string) {
+	t.Helper()
+	var ready = make(chan struct{})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	var result = make(chan error, 1)
+	go func() {
+		result <- srv.Run(addr)
+	}()
+	select {
+	case <-ready:
+	case err := <-result:
+		t.Fatalf("server run failed: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server was not started")
+	}
+}
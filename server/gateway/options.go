@@ -10,3 +10,21 @@ func WithEndpointSelector(selector EndpointSelector) Option {
 		gateway.ess = selector
 	}
 }
+
+// WithForward 设置网关自动转发的端点名称
+//   - 设置后，客户端的数据包将通过 GetConnEndpoint 自动转发到该名称下的端点，端点返回的数据包也将自动写回客户端
+//   - 端点服务器可通过 NewNode 处理网关转发的数据包，无需手动解析网关数据包
+func WithForward(name string) Option {
+	return func(gateway *Gateway) {
+		gateway.forward = name
+	}
+}
+
+// WithLinkToken 设置网关连接的鉴权令牌
+//   - 网关与端点建立连接后将首先发送携带该令牌的鉴权数据包，端点通过 NewNode 指定的令牌进行校验
+//   - 鉴权完成前端点连接不会被用于转发数据包
+func WithLinkToken(token string) Option {
+	return func(gateway *Gateway) {
+		gateway.token = token
+	}
+}
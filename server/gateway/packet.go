@@ -3,96 +3,159 @@ package gateway
 import (
 	"encoding/binary"
 	"errors"
-	"fmt"
-	"net"
-	"strconv"
+	"math"
 )
 
 var packetIdentifier = []byte{0xDE, 0xAD, 0xBE, 0xEF}
+var closePacketIdentifier = []byte{0xDE, 0xAD, 0xC0, 0xDE}
+var authPacketIdentifier = []byte{0xDE, 0xAD, 0x5E, 0xC5}
 
 // MarshalGatewayOutPacket 将数据包转换为网关出网数据包
-//   - | identifier(4) | ipv4(4) | port(2) | packet |
-func MarshalGatewayOutPacket(addr string, packet []byte) ([]byte, error) {
-	host, portStr, err := net.SplitHostPort(addr)
-	if err != nil {
-		return nil, err
+//   - | identifier(4) | connIdLen(1) | connId(n) | packet |
+func MarshalGatewayOutPacket(connId string, packet []byte) ([]byte, error) {
+	if len(connId) == 0 || len(connId) > math.MaxUint8 {
+		return nil, errors.New("invalid connection id length")
 	}
-	ipBytes := net.ParseIP(host).To4()
-	if ipBytes == nil {
-		return nil, errors.New("invalid IPv4 address")
-	}
-	port, err := strconv.Atoi(portStr)
-	if err != nil || port < 0 || port > 65535 {
-		return nil, errors.New("invalid port number")
-	}
-	portBytes := []byte{byte(port >> 8), byte(port & 0xFF)}
-
-	result := append(packetIdentifier, ipBytes...)
-	result = append(result, portBytes...)
+	result := make([]byte, 0, len(packetIdentifier)+1+len(connId)+len(packet))
+	result = append(result, packetIdentifier...)
+	result = append(result, byte(len(connId)))
+	result = append(result, connId...)
 	result = append(result, packet...)
 
 	return result, nil
 }
 
 // UnmarshalGatewayOutPacket 将网关出网数据包转换为数据包
-//   - | identifier(4) | ipv4(4) | port(2) | packet |
-func UnmarshalGatewayOutPacket(data []byte) (addr string, packet []byte, err error) {
-	if len(data) < 10 {
-		err = errors.New("data is too short to contain an IPv4 address and a port")
+//   - | identifier(4) | connIdLen(1) | connId(n) | packet |
+func UnmarshalGatewayOutPacket(data []byte) (connId string, packet []byte, err error) {
+	if len(data) < 6 {
+		err = errors.New("data is too short to contain a connection id")
 		return
 	}
 	if !compareBytes(data[:4], packetIdentifier) {
 		err = errors.New("invalid identifier")
 		return
 	}
-	ipAddr := net.IP(data[4:8]).String()
-	port := uint16(data[8])<<8 | uint16(data[9])
-	addr = fmt.Sprintf("%s:%d", ipAddr, port)
-	packet = data[10:]
+	idLen := int(data[4])
+	if idLen == 0 || len(data) < 5+idLen {
+		err = errors.New("data is too short to contain a connection id")
+		return
+	}
+	connId = string(data[5 : 5+idLen])
+	packet = data[5+idLen:]
 
-	return addr, packet, nil
+	return connId, packet, nil
 }
 
 // MarshalGatewayInPacket 将数据包转换为网关入网数据包
-//   - | ipv4(4) | port(2) | cost(4) | packet |
-func MarshalGatewayInPacket(addr string, currentTime int64, packet []byte) ([]byte, error) {
-	host, portStr, err := net.SplitHostPort(addr)
-	if err != nil {
-		return nil, err
-	}
-	ipBytes := net.ParseIP(host).To4()
-	if ipBytes == nil {
-		return nil, errors.New("invalid IPv4 address")
-	}
-	port, err := strconv.Atoi(portStr)
-	if err != nil || port < 0 || port > 65535 {
-		return nil, errors.New("invalid port number")
-	}
-	portBytes := []byte{byte(port >> 8), byte(port & 0xFF)}
-	costBytes := make([]byte, 4)
-	binary.BigEndian.PutUint32(costBytes, uint32(currentTime))
-
-	result := append(ipBytes, portBytes...)
-	result = append(result, costBytes...)
+//   - | connIdLen(1) | connId(n) | cost(4) | packet |
+func MarshalGatewayInPacket(connId string, currentTime int64, packet []byte) ([]byte, error) {
+	if len(connId) == 0 || len(connId) > math.MaxUint8 {
+		return nil, errors.New("invalid connection id length")
+	}
+	result := make([]byte, 0, 1+len(connId)+4+len(packet))
+	result = append(result, byte(len(connId)))
+	result = append(result, connId...)
+	result = binary.BigEndian.AppendUint32(result, uint32(currentTime))
 	result = append(result, packet...)
 
 	return result, nil
 }
 
 // UnmarshalGatewayInPacket 将网关入网数据包转换为数据包
-//   - | ipv4(4) | port(2) | cost(4) | packet |
-func UnmarshalGatewayInPacket(data []byte) (addr string, sendTime int64, packet []byte, err error) {
-	if len(data) < 10 {
+//   - | connIdLen(1) | connId(n) | cost(4) | packet |
+func UnmarshalGatewayInPacket(data []byte) (connId string, sendTime int64, packet []byte, err error) {
+	if len(data) < 6 {
 		err = errors.New("data is too short")
 		return
 	}
-	ipAddr := net.IP(data[:4]).String()
-	port := uint16(data[4])<<8 | uint16(data[5])
-	addr = fmt.Sprintf("%s:%d", ipAddr, port)
-	sendTime = int64(binary.BigEndian.Uint32(data[6:10]))
-	packet = data[10:]
+	idLen := int(data[0])
+	if idLen == 0 || len(data) < 1+idLen+4 {
+		err = errors.New("data is too short")
+		return
+	}
+	connId = string(data[1 : 1+idLen])
+	sendTime = int64(binary.BigEndian.Uint32(data[1+idLen : 5+idLen]))
+	packet = data[5+idLen:]
 
-	return addr, sendTime, packet, nil
+	return connId, sendTime, packet, nil
+}
+
+// MarshalGatewayOutClosePacket 创建网关出网连接关闭数据包，用于通知端点客户端连接已断开
+//   - | identifier(4) | connIdLen(1) | connId(n) |
+func MarshalGatewayOutClosePacket(connId string) ([]byte, error) {
+	if len(connId) == 0 || len(connId) > math.MaxUint8 {
+		return nil, errors.New("invalid connection id length")
+	}
+	result := make([]byte, 0, len(closePacketIdentifier)+1+len(connId))
+	result = append(result, closePacketIdentifier...)
+	result = append(result, byte(len(connId)))
+	result = append(result, connId...)
+
+	return result, nil
+}
+
+// UnmarshalGatewayOutClosePacket 解析网关出网连接关闭数据包
+//   - | identifier(4) | connIdLen(1) | connId(n) |
+func UnmarshalGatewayOutClosePacket(data []byte) (connId string, err error) {
+	if len(data) < 6 || !compareBytes(data[:4], closePacketIdentifier) {
+		err = errors.New("not a close packet")
+		return
+	}
+	idLen := int(data[4])
+	if idLen == 0 || len(data) != 5+idLen {
+		err = errors.New("invalid connection id length")
+		return
+	}
+	return string(data[5:]), nil
+}
+
+// MarshalGatewayInClosePacket 创建网关入网连接关闭数据包，用于由端点要求网关断开客户端连接
+//   - | 0(1) | connIdLen(1) | connId(n) |
+//   - 首字节为 0 的数据包不会被 UnmarshalGatewayInPacket 成功解析，以此与普通入网数据包进行区分
+func MarshalGatewayInClosePacket(connId string) ([]byte, error) {
+	if len(connId) == 0 || len(connId) > math.MaxUint8 {
+		return nil, errors.New("invalid connection id length")
+	}
+	result := make([]byte, 0, 2+len(connId))
+	result = append(result, 0, byte(len(connId)))
+	result = append(result, connId...)
+
+	return result, nil
+}
+
+// UnmarshalGatewayInClosePacket 解析网关入网连接关闭数据包
+//   - | 0(1) | connIdLen(1) | connId(n) |
+func UnmarshalGatewayInClosePacket(data []byte) (connId string, err error) {
+	if len(data) < 3 || data[0] != 0 {
+		err = errors.New("not a close packet")
+		return
+	}
+	idLen := int(data[1])
+	if idLen == 0 || len(data) != 2+idLen {
+		err = errors.New("invalid connection id length")
+		return
+	}
+	return string(data[2:]), nil
+}
+
+// MarshalGatewayAuthPacket 创建网关连接鉴权数据包，网关在与端点建立连接后将首先发送该数据包
+//   - | identifier(4) | token |
+func MarshalGatewayAuthPacket(token string) []byte {
+	result := make([]byte, 0, len(authPacketIdentifier)+len(token))
+	result = append(result, authPacketIdentifier...)
+	result = append(result, token...)
+	return result
+}
+
+// UnmarshalGatewayAuthPacket 解析网关连接鉴权数据包
+//   - | identifier(4) | token |
+func UnmarshalGatewayAuthPacket(data []byte) (token string, err error) {
+	if len(data) < len(authPacketIdentifier) || !compareBytes(data[:4], authPacketIdentifier) {
+		err = errors.New("not an auth packet")
+		return
+	}
+	return string(data[4:]), nil
 }
 
 func compareBytes(a, b []byte) bool {
@@ -1,16 +1,29 @@
 package server
 
 import (
-	"bytes"
 	"github.com/panjf2000/gnet"
+	"strings"
 	"time"
 )
 
 type gNet struct {
 	*Server
+	network Network
+	ready   func()
+	init    func() error // 侦听器创建完成后、就绪前的初始化函数
+	initErr error
+	udp     *udpSessions // UDP 虚拟会话表，仅 UDP 网络类型有效
 }
 
 func (slf *gNet) OnInitComplete(server gnet.Server) (action gnet.Action) {
+	if slf.init != nil {
+		if slf.initErr = slf.init(); slf.initErr != nil {
+			return gnet.Shutdown
+		}
+	}
+	if slf.ready != nil {
+		slf.ready()
+	}
 	return
 }
 
@@ -19,15 +32,26 @@ func (slf *gNet) OnShutdown(server gnet.Server) {
 }
 
 func (slf *gNet) OnOpened(c gnet.Conn) (out []byte, action gnet.Action) {
-	conn := newGNetConn(slf.Server, c)
+	ip := c.RemoteAddr().String()
+	if index := strings.LastIndex(ip, ":"); index != -1 {
+		ip = ip[0:index]
+	}
+	if !slf.filterConnection(ip) {
+		return nil, gnet.Close
+	}
+	conn := newGNetConn(slf.Server, slf.network, c)
+	conn.filtered = slf.connFilter != nil
 	c.SetContext(conn)
-	slf.OnConnectionOpenedEvent(conn)
+	slf.protectConn(conn, func() { slf.OnConnectionOpenedEvent(conn) })
 	return
 }
 
 func (slf *gNet) OnClosed(c gnet.Conn, err error) (action gnet.Action) {
-	conn := c.Context().(*Conn)
-	conn.Close(err)
+	conn, ok := c.Context().(*Conn)
+	if !ok {
+		return
+	}
+	conn.CloseWithReason(readCloseReason(err), err)
 	return
 }
 
@@ -40,10 +64,21 @@ func (slf *gNet) AfterWrite(c gnet.Conn, b []byte) {
 }
 
 func (slf *gNet) React(packet []byte, c gnet.Conn) (out []byte, action gnet.Action) {
-	slf.Server.PushPacketMessage(c.Context().(*Conn), 0, bytes.Clone(packet))
+	var conn *Conn
+	if slf.udp != nil {
+		if conn = slf.session(c); conn == nil {
+			return nil, gnet.None
+		}
+	} else {
+		conn = c.Context().(*Conn)
+	}
+	slf.protectConn(conn, func() { slf.Server.receivePacket(conn, 0, packet) })
 	return nil, gnet.None
 }
 
 func (slf *gNet) Tick() (delay time.Duration, action gnet.Action) {
-	return
+	if slf.udp != nil {
+		slf.expire()
+	}
+	return time.Second, gnet.None
 }
@@ -0,0 +1,25 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// grpcGateway 通过 WithGRPCGateway 挂载的 grpc-gateway 处理器
+type grpcGateway struct {
+	handler http.Handler
+	prefix  string
+}
+
+// registerGRPCGateway 挂载通过 WithGRPCGateway 设置的 grpc-gateway 处理器，需要在 HTTP 服务器开始服务前调用
+//   - gin 的路由在注册时便确定了中间件，因此延迟至运行时注册，使此前通过 Server.HttpRouter 注册的中间件同样作用于 gateway
+func (slf *Server) registerGRPCGateway() {
+	if slf.grpcGateway == nil {
+		return
+	}
+	if len(slf.grpcGateway.prefix) == 0 {
+		slf.ginServer.NoRoute(gin.WrapH(slf.grpcGateway.handler))
+		return
+	}
+	slf.ginServer.Any(slf.grpcGateway.prefix+"/*path", gin.WrapH(slf.grpcGateway.handler))
+}
@@ -0,0 +1,60 @@
+package server_test
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kercylan98/minotaur/server"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestWithGRPCGateway(t *testing.T) {
+	gateway := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("gateway:" + request.URL.Path))
+	})
+	type request struct {
+		path string
+		body string
+	}
+	var cases = []struct {
+		prefix   string
+		requests []request
+	}{
+		{"", []request{
+			{"/v1/users/1", "gateway:/v1/users/1"},
+			{"/gin", "gin"},
+		}},
+		{"/v1/", []request{
+			{"/v1/users/1", "gateway:/v1/users/1"},
+			{"/v2/users/1", "404 page not found"},
+		}},
+	}
+	for _, c := range cases {
+		srv := server.New(server.NetworkHttp, server.WithGRPCGateway(gateway, c.prefix))
+		// 在 WithGRPCGateway 之后注册的中间件同样应作用于 gateway
+		srv.HttpRouter().Use(func(ctx *gin.Context) {
+			ctx.Header("X-Middleware", "ok")
+		})
+		srv.HttpServer().GET("/gin", func(ctx *server.HttpContext) {
+			ctx.Gin().String(http.StatusOK, "gin")
+		})
+		var addr = freeAddr(t, "tcp")
+		runServer(t, srv, addr)
+
+		for _, r := range c.requests {
+			response, err := http.Get("http://" + addr + r.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(response.Body)
+			_ = response.Body.Close()
+			if string(body) != r.body {
+				t.Fatalf("prefix %q path %s: expected %q, got %q", c.prefix, r.path, r.body, body)
+			}
+			if response.Header.Get("X-Middleware") != "ok" {
+				t.Fatalf("prefix %q path %s: expected the HttpRouter middleware to run", c.prefix, r.path)
+			}
+		}
+		srv.Shutdown()
+	}
+}
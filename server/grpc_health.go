@@ -0,0 +1,43 @@
+package server
+
+import (
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// registerGRPCServices 注册通过 WithGRPCHealth 及 WithGRPCReflection 启用的服务，需要在 grpc 服务器开始服务前调用
+func (slf *Server) registerGRPCServices() {
+	if slf.grpcHealthEnabled {
+		slf.grpcHealth = health.NewServer()
+		healthpb.RegisterHealthServer(slf.grpcServer, slf.grpcHealth)
+		slf.refreshGRPCHealth()
+	}
+	if slf.grpcReflection {
+		reflection.Register(slf.grpcServer)
+	}
+}
+
+// refreshGRPCHealth 根据服务器的生命周期刷新 grpc 健康检查状态
+//   - 仅在服务器启动完成、处于运行中且未处于排空状态时为 SERVING，否则为 NOT_SERVING
+//   - 整体状态（空服务名）及所有已注册服务的状态将被同时设置
+func (slf *Server) refreshGRPCHealth() {
+	if slf.grpcHealth == nil {
+		return
+	}
+	var status = healthpb.HealthCheckResponse_NOT_SERVING
+	if slf.startFinished.Load() && slf.state.Load() == serverStateRunning && !slf.IsDraining() {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	slf.grpcHealth.SetServingStatus("", status)
+	for service := range slf.grpcServer.GetServiceInfo() {
+		slf.grpcHealth.SetServingStatus(service, status)
+	}
+}
+
+// GRPCHealthServer 获取通过 WithGRPCHealth 注册的健康检查服务，可用于手动设置特定服务的状态
+//   - 当未启用健康检查或服务器尚未运行时将返回 nil
+//   - 服务器生命周期变化时将覆盖所有已注册服务的状态
+func (slf *Server) GRPCHealthServer() *health.Server {
+	return slf.grpcHealth
+}
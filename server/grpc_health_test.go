@@ -0,0 +1,43 @@
+package server_test
+
+import (
+	"context"
+	"github.com/kercylan98/minotaur/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"testing"
+	"time"
+)
+
+func TestServer_WithGRPCHealth(t *testing.T) {
+	srv := server.New(server.NetworkGRPC, server.WithGRPCHealth(), server.WithGRPCReflection())
+	var addr = freeAddr(t, "tcp")
+	runServer(t, srv, addr)
+	defer srv.Shutdown()
+
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	client := healthpb.NewHealthClient(conn)
+	var wait = func(expected healthpb.HealthCheckResponse_ServingStatus) {
+		deadline := time.Now().Add(3 * time.Second)
+		for {
+			response, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+			if err == nil && response.Status == expected {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("expected %s, got %v, %v", expected, response, err)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	wait(healthpb.HealthCheckResponse_SERVING)
+	srv.SetDraining(true)
+	wait(healthpb.HealthCheckResponse_NOT_SERVING)
+	srv.SetDraining(false)
+	wait(healthpb.HealthCheckResponse_SERVING)
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"github.com/gorilla/websocket"
+	"time"
+)
+
+// refreshActive 刷新连接的最后活跃时间
+func (slf *Conn) refreshActive() {
+	slf.lastActive.Store(time.Now().UnixNano())
+}
+
+// GetLastActiveTime 获取连接最后活跃的时间
+//   - 连接建立、接收到数据包以及 Websocket 接收到 Pong 时将会刷新活跃时间
+func (slf *Conn) GetLastActiveTime() time.Time {
+	return time.Unix(0, slf.lastActive.Load())
+}
+
+// startHeartbeat 开始心跳检测，检测将在服务器关闭后停止
+func (slf *Server) startHeartbeat() {
+	if slf.heartbeatInterval <= 0 || slf.heartbeatTimeout <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(slf.heartbeatInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if slf.state.Load() != serverStateRunning {
+				return
+			}
+			now := time.Now()
+			slf.RangeConn(func(conn *Conn) bool {
+				if conn.IsBot() {
+					return true
+				}
+				idle := now.Sub(conn.GetLastActiveTime())
+				switch {
+				case idle >= slf.heartbeatTimeout:
+					slf.OnConnectionIdleEvent(conn, idle)
+				case idle >= slf.heartbeatInterval:
+					slf.ping(conn)
+				}
+				return true
+			})
+		}
+	}()
+}
+
+// ping 向连接发送心跳
+func (slf *Server) ping(conn *Conn) {
+	if conn.ws != nil {
+		_ = conn.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(slf.heartbeatInterval))
+		return
+	}
+	if len(slf.heartbeatPacket) > 0 {
+		conn.Write(slf.heartbeatPacket)
+	}
+}
@@ -0,0 +1,97 @@
+package server_test
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/server"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestWithHeartbeat(t *testing.T) {
+	srv := server.New(server.NetworkTcp, server.WithHeartbeat(time.Millisecond*100, time.Millisecond*500, []byte("ping")))
+	var idle = make(chan time.Duration, 1)
+	srv.RegConnectionIdleEvent(func(srv *server.Server, conn *server.Conn, duration time.Duration) {
+		idle <- duration
+	})
+	var closed = make(chan server.CloseReason, 1)
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
+		closed <- reason
+	})
+	var addr = freeAddr(t, "tcp")
+	runServer(t, srv, addr)
+	defer srv.Shutdown()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	var buf = make([]byte, 4)
+	if _, err = conn.Read(buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("expected heartbeat packet, got %q, %v", buf, err)
+	}
+
+	select {
+	case duration := <-idle:
+		if duration < time.Millisecond*500 {
+			t.Fatalf("idle event fired after %s, before timeout", duration)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("connection idle event was not triggered")
+	}
+	select {
+	case reason := <-closed:
+		if reason != server.CloseReasonHeartbeatTimeout {
+			t.Fatalf("expected close reason %v, got %v", server.CloseReasonHeartbeatTimeout, reason)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("idle connection was not closed")
+	}
+}
+
+func TestWithHeartbeat_Active(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket, server.WithHeartbeat(time.Millisecond*100, time.Millisecond*400, nil))
+	var idle = make(chan struct{}, 1)
+	srv.RegConnectionIdleEvent(func(srv *server.Server, conn *server.Conn, duration time.Duration) {
+		idle <- struct{}{}
+	})
+	var addr = freeAddr(t, "tcp")
+	runServer(t, srv, addr)
+	defer srv.Shutdown()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws://"+addr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ws.Close() }()
+	var pinged = make(chan struct{}, 1)
+	ws.SetPingHandler(func(appData string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return ws.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(time.Second))
+	})
+	go func() {
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-pinged:
+	case <-time.After(time.Second):
+		t.Fatal("websocket ping was not sent")
+	}
+	// 客户端持续响应 Pong，连接不应被判定为空闲
+	select {
+	case <-idle:
+		t.Fatal("active connection was treated as idle")
+	case <-time.After(time.Second):
+	}
+}
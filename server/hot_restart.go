@@ -0,0 +1,243 @@
+package server
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/log"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// HotRestartEnv 热重启时用于向新进程传递继承的侦听器的环境变量，格式为 "{name}|{addr}={fd};..."
+	HotRestartEnv = "MINOTAUR_HOT_RESTART_FDS"
+	// DefaultHotRestartDrainTimeout 热重启时旧进程等待连接下线的默认超时时间
+	DefaultHotRestartDrainTimeout = time.Minute
+)
+
+var inheritedListeners struct {
+	once  sync.Once
+	lock  sync.Mutex
+	files map[string]*os.File
+}
+
+// hotRestartListener 可被热重启继承的侦听器
+type hotRestartListener struct {
+	key      string
+	listener inheritableListener
+}
+
+// inheritableListener 可通过文件描述符传递给新进程的侦听器，例如 *net.TCPListener 及 *net.UnixListener
+type inheritableListener interface {
+	net.Listener
+	File() (*os.File, error)
+}
+
+// hotRestartKey 获取侦听器在热重启时的标识
+func hotRestartKey(name, addr string) string {
+	return fmt.Sprintf("%s|%s", name, addr)
+}
+
+// takeInheritedListener 获取从父进程继承的侦听器文件，每个侦听器仅能被获取一次
+func takeInheritedListener(key string) *os.File {
+	inheritedListeners.once.Do(func() {
+		inheritedListeners.files = make(map[string]*os.File)
+		for _, item := range strings.Split(os.Getenv(HotRestartEnv), ";") {
+			index := strings.LastIndex(item, "=")
+			if index == -1 {
+				continue
+			}
+			fd, err := strconv.Atoi(item[index+1:])
+			if err != nil {
+				continue
+			}
+			inheritedListeners.files[item[:index]] = os.NewFile(uintptr(fd), item[:index])
+		}
+	})
+	inheritedListeners.lock.Lock()
+	defer inheritedListeners.lock.Unlock()
+	file := inheritedListeners.files[key]
+	delete(inheritedListeners.files, key)
+	return file
+}
+
+// listenTCP 创建 TCP 侦听器，当父进程通过热重启传递了相同地址的侦听器时将直接继承
+//   - name 为侦听器的名称，通常为网络类型，与 addr 共同作为热重启时侦听器的标识
+//   - 启用 WithHotRestart 时，侦听器将被记录以便在 Server.Restart 时传递给新进程
+func (slf *Server) listenTCP(name, addr string) (net.Listener, error) {
+	var key = hotRestartKey(name, addr)
+	listener, err := slf.inheritListener(key)
+	if err != nil {
+		return nil, err
+	}
+	if listener == nil {
+		var network = NetworkTcp
+		if name == string(NetworkTcp4) || name == string(NetworkTcp6) {
+			network = Network(name)
+		}
+		if listener, err = net.Listen(string(network), addr); err != nil {
+			return nil, err
+		}
+	}
+	slf.recordListener(key, listener)
+	return listener, nil
+}
+
+// listenUnix 创建 Unix 侦听器，当父进程通过热重启传递了相同路径的侦听器时将直接继承
+//   - 侦听器关闭时不会删除 socket 文件，socket 文件由 Server 在关闭时清理，避免旧进程删除新进程仍在使用的 socket 文件
+func (slf *Server) listenUnix(path string) (net.Listener, error) {
+	var key = hotRestartKey(string(NetworkUnix), path)
+	listener, err := slf.inheritListener(key)
+	if err != nil {
+		return nil, err
+	}
+	if listener == nil {
+		if err = removeStaleUnixSocket(path); err != nil {
+			return nil, err
+		}
+		if listener, err = net.Listen(string(NetworkUnix), path); err != nil {
+			return nil, err
+		}
+		if err = slf.setupUnixSocket(path); err != nil {
+			_ = listener.Close()
+			return nil, err
+		}
+	}
+	if unix, ok := listener.(*net.UnixListener); ok {
+		unix.SetUnlinkOnClose(false)
+	}
+	slf.recordListener(key, listener)
+	return listener, nil
+}
+
+// inheritListener 获取父进程通过热重启传递的侦听器，不存在时将返回 nil
+func (slf *Server) inheritListener(key string) (net.Listener, error) {
+	file := takeInheritedListener(key)
+	if file == nil {
+		return nil, nil
+	}
+	listener, err := net.FileListener(file)
+	_ = file.Close()
+	if err != nil {
+		return nil, err
+	}
+	slf.Logger().Info("Server", log.String("HotRestart", "inherit"), log.String("listener", key))
+	return listener, nil
+}
+
+// recordListener 启用 WithHotRestart 时记录侦听器，以便在 Server.Restart 时传递给新进程
+func (slf *Server) recordListener(key string, listener net.Listener) {
+	if l, ok := listener.(inheritableListener); ok && slf.hotRestart != nil {
+		slf.hotRestart.lock.Lock()
+		slf.hotRestart.listeners = append(slf.hotRestart.listeners, hotRestartListener{key: key, listener: l})
+		slf.hotRestart.lock.Unlock()
+	}
+}
+
+// hotRestart 热重启状态
+type hotRestart struct {
+	drainTimeout time.Duration
+	restarting   bool
+	listeners    []hotRestartListener
+	accepting    sync.WaitGroup // 正在接收连接的侦听器
+	lock         sync.Mutex
+}
+
+// IsRestarting 服务器是否正在进行热重启
+func (slf *Server) IsRestarting() bool {
+	if slf.hotRestart == nil {
+		return false
+	}
+	slf.hotRestart.lock.Lock()
+	defer slf.hotRestart.lock.Unlock()
+	return slf.hotRestart.restarting
+}
+
+// Restart 热重启服务器，将以相同的参数启动新进程并将侦听器传递给新进程，随后旧进程将在连接全部下线或超过排空超时时间后关闭
+//   - 需要通过 WithHotRestart 启用，否则将返回 ErrNoSupportHotRestart
+//   - TCP、Unix、Websocket、HTTP、gRPC 及管理服务侦听器将通过文件描述符继承，新旧进程不会出现无法连接的间隙
+//   - 启用 WithHotRestart 时 TCP 及 Unix 侦听器将不再由 gnet 驱动，以便旧进程在排空开始时单独关闭侦听器而不影响已建立的连接
+//   - gnet 驱动的 UDP 侦听器同时承载已建立的会话而无法关闭，新进程将通过 SO_REUSEPORT 绑定相同端口，旧进程在排空期间将拒绝新连接
+//   - KCP 及 WebTransport 侦听器无法被继承，此时将返回 ErrHotRestartUnsupportedNet
+//   - 不支持 Windows 平台
+func (slf *Server) Restart() (pid int, err error) {
+	if slf.hotRestart == nil {
+		return 0, ErrNoSupportHotRestart
+	}
+	if slf.network == NetworkKcp || slf.network == NetworkWebTransport {
+		return 0, ErrHotRestartUnsupportedNet
+	}
+	for _, l := range slf.listeners {
+		if l.network == NetworkKcp || l.network == NetworkWebTransport {
+			return 0, ErrHotRestartUnsupportedNet
+		}
+	}
+
+	slf.hotRestart.lock.Lock()
+	defer slf.hotRestart.lock.Unlock()
+	if slf.hotRestart.restarting {
+		return 0, ErrHotRestarting
+	}
+
+	var files = make([]*os.File, 0, len(slf.hotRestart.listeners))
+	var env = make([]string, 0, len(slf.hotRestart.listeners))
+	defer func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}()
+	for i, l := range slf.hotRestart.listeners {
+		file, err := l.listener.File()
+		if err != nil {
+			return 0, err
+		}
+		files = append(files, file)
+		env = append(env, fmt.Sprintf("%s=%d", l.key, 3+i))
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%s", HotRestartEnv, strings.Join(env, ";")))
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = files
+	if err = cmd.Start(); err != nil {
+		return 0, err
+	}
+	slf.hotRestart.restarting = true
+	slf.Logger().Info("Server", log.String("HotRestart", "start"), log.Int("pid", cmd.Process.Pid), log.Int("listeners", len(files)))
+
+	go slf.hotRestartDrain(cmd)
+	return cmd.Process.Pid, nil
+}
+
+// hotRestartDrain 停止旧进程接收新连接，并在连接全部下线或超过排空超时时间后关闭服务器
+func (slf *Server) hotRestartDrain(cmd *exec.Cmd) {
+	_ = cmd.Process.Release()
+	slf.hotRestartStopAccept()
+
+	var deadline = time.Now().Add(slf.hotRestart.drainTimeout)
+	for slf.GetOnlineCount() > 0 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+	slf.Logger().Info("Server", log.String("HotRestart", "drained"), log.Int("online", slf.GetOnlineCount()))
+	slf.Shutdown()
+}
+
+// hotRestartStopAccept 关闭旧进程的侦听器并进入排空状态，使新连接仅由新进程接收，已建立的连接不受影响
+//   - 侦听器关闭前已被旧进程接收的连接将正常建立，因此需要等待侦听器停止接收后再进入排空状态
+func (slf *Server) hotRestartStopAccept() {
+	slf.hotRestart.lock.Lock()
+	for _, l := range slf.hotRestart.listeners {
+		_ = l.listener.Close()
+	}
+	slf.hotRestart.lock.Unlock()
+	slf.hotRestart.accepting.Wait()
+	slf.SetDraining(true)
+}
@@ -0,0 +1,15 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServer_Restart(t *testing.T) {
+	if _, err := New(NetworkWebsocket).Restart(); err != ErrNoSupportHotRestart {
+		t.Fatalf("expected %v, got %v", ErrNoSupportHotRestart, err)
+	}
+	if _, err := New(NetworkKcp, WithHotRestart(time.Second)).Restart(); err != ErrHotRestartUnsupportedNet {
+		t.Fatalf("expected %v, got %v", ErrHotRestartUnsupportedNet, err)
+	}
+}
@@ -0,0 +1,375 @@
+//go:build !windows
+
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const (
+	hotRestartChildEnv     = "MINOTAUR_TEST_HOT_RESTART_CHILD"
+	hotRestartUnixChildEnv = "MINOTAUR_TEST_HOT_RESTART_UNIX_CHILD"
+)
+
+// TestHotRestartChild 在子进程中模拟热重启的新进程，通过继承的文件描述符侦听并向每个连接写入 "new"
+func TestHotRestartChild(t *testing.T) {
+	if len(os.Getenv(hotRestartChildEnv)) == 0 {
+		t.Skip("only runs as the child process of TestServer_hotRestartStopAccept")
+	}
+	file := os.NewFile(3, "listener")
+	listener, err := net.FileListener(file)
+	_ = file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("new"))
+			_ = conn.Close()
+		}
+	}()
+	os.Stdout.WriteString("ready\n")
+	_, _ = io.Copy(io.Discard, os.Stdin)
+}
+
+func TestServer_listenTCP(t *testing.T) {
+	origin, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := origin.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 继承的文件描述符将由 listenTCP 关闭，因此传递一个不被 file 持有的副本
+	fd, err := syscall.Dup(int(file.Fd()))
+	_ = file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var addr = origin.Addr().String()
+	_ = origin.Close()
+
+	t.Setenv(HotRestartEnv, fmt.Sprintf("%s=%d", hotRestartKey(string(NetworkWebsocket), addr), fd))
+	inheritedListeners.once = sync.Once{}
+	srv := New(NetworkWebsocket, WithHotRestart(time.Second))
+	listener, err := srv.listenTCP(string(NetworkWebsocket), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	if listener.Addr().String() != addr {
+		t.Fatalf("expected inherited listener on %s, got %s", addr, listener.Addr())
+	}
+	if len(srv.hotRestart.listeners) != 1 {
+		t.Fatalf("expected 1 recorded listener, got %d", len(srv.hotRestart.listeners))
+	}
+
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			_ = conn.Close()
+		}
+	}()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.Close()
+
+	if takeInheritedListener(hotRestartKey(string(NetworkWebsocket), addr)) != nil {
+		t.Fatal("inherited listener should only be taken once")
+	}
+}
+
+func TestServer_hotRestartStopAccept(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	_ = l.Close()
+
+	srv := New(NetworkTcp, WithHotRestart(time.Minute))
+	srv.RegConnectionOpenedEvent(func(srv *Server, conn *Conn) {
+		conn.Write([]byte("old"))
+	})
+	srv.RegConnectionReceivePacketEvent(func(srv *Server, conn *Conn, packet []byte) {
+		conn.Write(packet)
+	})
+	var ready = make(chan struct{})
+	srv.RegStartFinishEvent(func(srv *Server) {
+		close(ready)
+	})
+	var result = make(chan error, 1)
+	go func() {
+		result <- srv.Run(addr)
+	}()
+	select {
+	case <-ready:
+	case err := <-result:
+		t.Fatalf("server run failed: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server was not started")
+	}
+	defer srv.Shutdown()
+
+	online, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer online.Close()
+	expectRead(t, online, "old")
+
+	// 启动模拟新进程的子进程，其与 Server.Restart 相同地通过文件描述符继承旧进程的侦听器
+	srv.hotRestart.lock.Lock()
+	file, err := srv.hotRestart.listeners[0].listener.File()
+	srv.hotRestart.lock.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := exec.Command(os.Args[0], "-test.run=^TestHotRestartChild$")
+	child.Env = append(os.Environ(), hotRestartChildEnv+"=1")
+	child.ExtraFiles = []*os.File{file}
+	stdin, err := child.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := child.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = child.Start()
+	_ = file.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = stdin.Close()
+		_ = child.Wait()
+	}()
+	if line, err := bufio.NewReader(stdout).ReadString('\n'); err != nil || line != "ready\n" {
+		t.Fatalf("child process was not ready: %q %v", line, err)
+	}
+
+	// 排空期间持续建立新连接，每个连接都应被旧进程或新进程之一接收，而不会被拒绝
+	var stop atomic.Bool
+	var wait sync.WaitGroup
+	var failed = make(chan string, 8)
+	for i := 0; i < 8; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for !stop.Load() {
+				conn, err := net.Dial("tcp", addr)
+				if err != nil {
+					failed <- err.Error()
+					return
+				}
+				_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+				var buf = make([]byte, 3)
+				_, err = io.ReadFull(conn, buf)
+				_ = conn.Close()
+				if err != nil || (string(buf) != "old" && string(buf) != "new") {
+					failed <- fmt.Sprintf("%q %v", buf, err)
+					return
+				}
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	srv.hotRestartStopAccept()
+	time.Sleep(50 * time.Millisecond)
+	stop.Store(true)
+	wait.Wait()
+	close(failed)
+	for message := range failed {
+		t.Fatalf("dial failed during drain: %s", message)
+	}
+
+	// 排空开始后，新连接应全部由新进程接收
+	for i := 0; i < 20; i++ {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatalf("dial %d failed after drain: %v", i, err)
+		}
+		expectRead(t, conn, "new")
+		_ = conn.Close()
+	}
+
+	// 已建立的连接不受影响
+	if _, err = online.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	expectRead(t, online, "ping")
+}
+
+// TestHotRestartUnixChild 在子进程中模拟热重启的新进程，通过 HotRestartEnv 继承 Unix 侦听器并向每个连接写入 "new"
+func TestHotRestartUnixChild(t *testing.T) {
+	path := os.Getenv(hotRestartUnixChildEnv)
+	if len(path) == 0 {
+		t.Skip("only runs as the child process of TestServer_RestartUnix")
+	}
+	srv := New(NetworkUnix, WithHotRestart(time.Minute))
+	srv.RegConnectionOpenedEvent(func(srv *Server, conn *Conn) {
+		conn.Write([]byte("new"))
+	})
+	var ready = make(chan struct{})
+	srv.RegStartFinishEvent(func(srv *Server) {
+		close(ready)
+	})
+	var result = make(chan error, 1)
+	go func() {
+		result <- srv.Run(path)
+	}()
+	select {
+	case <-ready:
+	case err := <-result:
+		t.Fatalf("server run failed: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server was not started")
+	}
+	os.Stdout.WriteString("ready\n")
+	_, _ = io.Copy(io.Discard, os.Stdin)
+	srv.Shutdown()
+}
+
+func TestServer_RestartUnix(t *testing.T) {
+	dir, err := os.MkdirTemp("", "minotaur")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "minotaur.sock")
+
+	srv := New(NetworkUnix, WithHotRestart(time.Minute))
+	srv.RegConnectionOpenedEvent(func(srv *Server, conn *Conn) {
+		conn.Write([]byte("old"))
+	})
+	srv.RegConnectionReceivePacketEvent(func(srv *Server, conn *Conn, packet []byte) {
+		conn.Write(packet)
+	})
+	var ready = make(chan struct{})
+	srv.RegStartFinishEvent(func(srv *Server) {
+		close(ready)
+	})
+	var result = make(chan error, 1)
+	go func() {
+		result <- srv.Run(path)
+	}()
+	select {
+	case <-ready:
+	case err := <-result:
+		t.Fatalf("server run failed: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server was not started")
+	}
+
+	online, err := net.Dial("unix", path)
+	if err != nil {
+		srv.Shutdown()
+		t.Fatal(err)
+	}
+	defer online.Close()
+	expectRead(t, online, "old")
+
+	// 与 Server.Restart 相同地通过 HotRestartEnv 及 ExtraFiles 将侦听器传递给新进程
+	srv.hotRestart.lock.Lock()
+	l := srv.hotRestart.listeners[0]
+	file, err := l.listener.File()
+	srv.hotRestart.restarting = true
+	srv.hotRestart.lock.Unlock()
+	if err != nil {
+		srv.Shutdown()
+		t.Fatal(err)
+	}
+	child := exec.Command(os.Args[0], "-test.run=^TestHotRestartUnixChild$")
+	child.Env = append(os.Environ(), hotRestartUnixChildEnv+"="+path, fmt.Sprintf("%s=%s=3", HotRestartEnv, l.key))
+	child.ExtraFiles = []*os.File{file}
+	stdin, err := child.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := child.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = child.Start()
+	_ = file.Close()
+	if err != nil {
+		srv.Shutdown()
+		t.Fatal(err)
+	}
+	var exited bool
+	defer func() {
+		if !exited {
+			_ = stdin.Close()
+			_ = child.Wait()
+		}
+	}()
+	reader := bufio.NewReader(stdout)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			srv.Shutdown()
+			t.Fatalf("child process was not ready: %v", err)
+		}
+		if line == "ready\n" {
+			break
+		}
+	}
+	go func() {
+		_, _ = io.Copy(io.Discard, reader)
+	}()
+
+	// 旧进程排空并退出后，socket 文件应保留给新进程使用，且已建立的连接在排空期间不受影响
+	srv.hotRestartStopAccept()
+	if _, err = online.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	expectRead(t, online, "ping")
+	_ = online.Close()
+	srv.Shutdown()
+
+	for i := 0; i < 10; i++ {
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			t.Fatalf("dial %d failed after the old process exited: %v", i, err)
+		}
+		expectRead(t, conn, "new")
+		_ = conn.Close()
+	}
+
+	// 新进程正常关闭时将删除 socket 文件
+	_ = stdin.Close()
+	exited = true
+	if err = child.Wait(); err != nil {
+		t.Fatalf("child process failed: %v", err)
+	}
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected socket file to be removed by the new process, got %v", err)
+	}
+}
+
+func expectRead(t *testing.T, conn net.Conn, expected string) {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	var buf = make([]byte, len(expected))
+	if _, err := io.ReadFull(conn, buf); err != nil || string(buf) != expected {
+		t.Fatalf("expected %q, got %q %v", expected, buf, err)
+	}
+}
@@ -0,0 +1,554 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/kercylan98/minotaur/utils/log"
+	"golang.org/x/time/rate"
+	"hash"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// HttpJWTClaimsKey 通过 JWT 认证后，声明将以该键存储在 gin.Context 中
+	HttpJWTClaimsKey = "minotaur:jwt:claims"
+	// WebsocketJWTClaimsKey 通过 WithWebsocketJWTAuth 认证后，声明将以该键存储在连接数据中
+	WebsocketJWTClaimsKey = "minotaur:websocket:jwt:claims"
+	// DefaultWebsocketJWTQuery WithWebsocketJWTAuth 默认从该 url 参数中获取令牌
+	DefaultWebsocketJWTQuery = "token"
+	// HttpSignatureHeader 请求签名所在的请求头
+	HttpSignatureHeader = "X-Signature"
+	// HttpTimestampHeader 请求签名时间戳所在的请求头，值为秒级 Unix 时间戳
+	HttpTimestampHeader = "X-Timestamp"
+	// HttpRequestIDHeader WithHttpRequestID 默认读取及写入请求 ID 的请求头
+	HttpRequestIDHeader = "X-Request-ID"
+	// HttpRequestIDKey 通过 WithHttpRequestID 注入后，请求 ID 将以该键存储在 gin.Context 中
+	HttpRequestIDKey = "minotaur:request:id"
+)
+
+// HttpCORSConfig WithHttpCORS 的跨域配置
+type HttpCORSConfig struct {
+	AllowOrigins     []string      // 允许的来源，为空或包含 "*" 时允许所有来源
+	AllowMethods     []string      // 允许的请求方法，为空时允许 GET、POST、PUT、PATCH、DELETE、HEAD、OPTIONS
+	AllowHeaders     []string      // 允许的请求头，为空时将使用预检请求中的 Access-Control-Request-Headers
+	ExposeHeaders    []string      // 允许客户端访问的响应头
+	AllowCredentials bool          // 是否允许携带凭证，开启后响应的 Access-Control-Allow-Origin 将为请求的来源而非 "*"
+	MaxAge           time.Duration // 预检请求结果的缓存时间
+}
+
+// HttpRateLimitKey 根据 HTTP 请求生成限流键的函数
+type HttpRateLimitKey func(ctx *gin.Context) string
+
+// HttpRateLimitByIP 以客户端 IP 作为限流键
+//   - 客户端 IP 默认为请求的远程地址，仅当请求来自通过 WithTrustedProxies 设置的可信代理时才会从请求头中获取
+func HttpRateLimitByIP() HttpRateLimitKey {
+	return func(ctx *gin.Context) string {
+		return ctx.ClientIP()
+	}
+}
+
+// HttpRateLimitByHeader 以特定请求头的值作为限流键，适用于按 Token 限流的场景
+//   - 当请求头不存在时将退化为以客户端 IP 作为限流键
+func HttpRateLimitByHeader(header string) HttpRateLimitKey {
+	return func(ctx *gin.Context) string {
+		if v := ctx.GetHeader(header); len(v) > 0 {
+			return v
+		}
+		return ctx.ClientIP()
+	}
+}
+
+// WithHttpRateLimit 通过令牌桶限流的方式创建 HTTP 服务器
+//   - limit 为每秒产生的令牌数量，burst 为令牌桶容量
+//   - key 为限流键生成函数，默认为 HttpRateLimitByIP
+//   - 超出限制的请求将返回 http.StatusTooManyRequests
+func WithHttpRateLimit(limit rate.Limit, burst int, key ...HttpRateLimitKey) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkHttp {
+			return
+		}
+		var keyFunc = HttpRateLimitByIP()
+		if len(key) > 0 && key[0] != nil {
+			keyFunc = key[0]
+		}
+		limiters := newHttpLimiterStore(limit, burst)
+		srv.ginServer.Use(func(ctx *gin.Context) {
+			if !limiters.allow(keyFunc(ctx)) {
+				ctx.AbortWithStatus(http.StatusTooManyRequests)
+				return
+			}
+			ctx.Next()
+		})
+	}
+}
+
+// WithHttpJWTAuth 通过 JWT 认证的方式创建 HTTP 服务器
+//   - 支持 HS256、HS384、HS512 签名算法，令牌从请求头 Authorization: Bearer <token> 中获取
+//   - 认证通过后可通过 ctx.Get(HttpJWTClaimsKey) 获取 map[string]any 类型的声明
+//   - skipPaths 中的路径将不进行认证，例如登录接口
+//   - 认证失败的请求将返回 http.StatusUnauthorized
+func WithHttpJWTAuth(secret []byte, skipPaths ...string) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkHttp {
+			return
+		}
+		skips := httpSkipPaths(skipPaths)
+		srv.ginServer.Use(func(ctx *gin.Context) {
+			if skips[ctx.Request.URL.Path] {
+				ctx.Next()
+				return
+			}
+			token := strings.TrimSpace(ctx.GetHeader("Authorization"))
+			if len(token) < 7 || !strings.EqualFold(token[:7], "Bearer ") {
+				ctx.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			claims, err := parseJWT(strings.TrimSpace(token[7:]), secret)
+			if err != nil {
+				ctx.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			ctx.Set(HttpJWTClaimsKey, claims)
+			ctx.Next()
+		})
+	}
+}
+
+// websocketJWTAuth 在 Websocket 升级前对请求进行 JWT 认证，返回令牌的声明
+type websocketJWTAuth func(request *http.Request) (map[string]any, error)
+
+// WithWebsocketJWTAuth 通过 JWT 认证的方式创建 Websocket 服务器，认证将在 Websocket 升级前完成
+//   - 支持 HS256、HS384、HS512 签名算法，令牌优先从请求头 Authorization: Bearer <token> 中获取，其次从 url 参数 query 中获取，默认为 DefaultWebsocketJWTQuery
+//   - 认证通过后可通过 conn.GetData(WebsocketJWTClaimsKey) 获取 map[string]any 类型的声明
+//   - 认证失败的请求将不会升级为 Websocket 连接，并返回 http.StatusUnauthorized
+func WithWebsocketJWTAuth(secret []byte, query ...string) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkWebsocket {
+			return
+		}
+		var q = DefaultWebsocketJWTQuery
+		if len(query) > 0 && len(query[0]) > 0 {
+			q = query[0]
+		}
+		srv.websocketJWT = func(request *http.Request) (map[string]any, error) {
+			token := strings.TrimSpace(request.Header.Get("Authorization"))
+			if len(token) >= 7 && strings.EqualFold(token[:7], "Bearer ") {
+				token = strings.TrimSpace(token[7:])
+			} else {
+				token = request.URL.Query().Get(q)
+			}
+			return parseJWT(token, secret)
+		}
+	}
+}
+
+// WithHttpSignature 通过 HMAC-SHA256 请求签名校验的方式创建 HTTP 服务器，适用于支付回调等场景
+//   - 签名内容为 "{method}\n{path}\n{query}\n{timestamp}\n{body}"，签名结果以十六进制编码后放置于 HttpSignatureHeader 请求头中
+//   - 时间戳放置于 HttpTimestampHeader 请求头中，与服务器时间相差超过 expire 的请求将被拒绝
+//   - 当 expire <= 0 时不校验时间戳，此时被截获的请求可以被无限次重放，应当仅在业务自身能够保证幂等或去重时使用
+//   - 请求体需要在校验签名前读取，长度超过 WithHttpSignatureBodyLimit 设置的上限（默认为 DefaultHttpSignatureBodyLimit）的请求将返回 http.StatusRequestEntityTooLarge
+//   - skipPaths 中的路径将不进行校验
+//   - 校验失败的请求将返回 http.StatusUnauthorized
+func WithHttpSignature(secret []byte, expire time.Duration, skipPaths ...string) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkHttp {
+			return
+		}
+		skips := httpSkipPaths(skipPaths)
+		srv.ginServer.Use(func(ctx *gin.Context) {
+			if skips[ctx.Request.URL.Path] {
+				ctx.Next()
+				return
+			}
+			timestamp := ctx.GetHeader(HttpTimestampHeader)
+			if expire > 0 {
+				ts, err := strconv.ParseInt(timestamp, 10, 64)
+				if err != nil || time.Since(time.Unix(ts, 0)).Abs() > expire {
+					ctx.AbortWithStatus(http.StatusUnauthorized)
+					return
+				}
+			}
+			var body []byte
+			if ctx.Request.Body != nil {
+				var limit = srv.httpSignatureBodyLimit
+				if limit <= 0 {
+					limit = DefaultHttpSignatureBodyLimit
+				}
+				var err error
+				if body, err = io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, limit)); err != nil {
+					var tooLarge *http.MaxBytesError
+					if errors.As(err, &tooLarge) {
+						ctx.AbortWithStatus(http.StatusRequestEntityTooLarge)
+					} else {
+						ctx.AbortWithStatus(http.StatusBadRequest)
+					}
+					return
+				}
+				ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+			}
+			expected := HttpSign(secret, ctx.Request.Method, ctx.Request.URL.Path, ctx.Request.URL.RawQuery, timestamp, body)
+			if !hmac.Equal([]byte(expected), []byte(strings.ToLower(ctx.GetHeader(HttpSignatureHeader)))) {
+				ctx.AbortWithStatus(http.StatusUnauthorized)
+				return
+			}
+			ctx.Next()
+		})
+	}
+}
+
+// WithHttpSignatureBodyLimit 设置 WithHttpSignature 在校验签名前允许读取的请求体最大长度，默认为 DefaultHttpSignatureBodyLimit
+//   - 由于签名覆盖请求体，请求体需要在校验前完整读取，该上限用于避免未经认证的请求占用过多内存
+func WithHttpSignatureBodyLimit(limit int64) Option {
+	return func(srv *Server) {
+		srv.httpSignatureBodyLimit = limit
+	}
+}
+
+// HttpSign 生成与 WithHttpSignature 校验方式一致的请求签名，可用于客户端或测试
+func HttpSign(secret []byte, method, path, query, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(fmt.Sprintf("%s\n%s\n%s\n%s\n", method, path, query, timestamp)))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// WithHttpMiddlewares 通过附加中间件的方式创建 HTTP 服务器，中间件将按照可选项及参数的顺序执行
+//   - 中间件仅对之后注册的路由生效，因此应在注册路由前完成服务器的创建
+func WithHttpMiddlewares(middlewares ...gin.HandlerFunc) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkHttp {
+			return
+		}
+		srv.ginServer.Use(middlewares...)
+	}
+}
+
+// WithHttpRecovery 通过捕获处理函数 panic 的方式创建 HTTP 服务器
+//   - 发生 panic 时将记录日志，并以 MessageTypeError 类型的消息触发 MessageErrorEvent，消息中将包含请求的方法及路径标记
+//   - 未写入响应的请求将返回 http.StatusInternalServerError
+//   - 连接被客户端中断导致的 panic 将不会触发事件
+func WithHttpRecovery() Option {
+	return func(srv *Server) {
+		if srv.network != NetworkHttp {
+			return
+		}
+		srv.ginServer.Use(func(ctx *gin.Context) {
+			defer func() {
+				err := recover()
+				if err == nil {
+					return
+				}
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				e, ok := err.(error)
+				if !ok {
+					e = fmt.Errorf("%v", err)
+				}
+				marks := []log.Field{log.String("method", ctx.Request.Method), log.String("path", ctx.Request.URL.Path)}
+				if id := ctx.GetString(HttpRequestIDKey); len(id) > 0 {
+					marks = append(marks, log.String("request_id", id))
+				}
+				srv.Logger().Error("Server", append(marks, log.Err(e), log.String("stack", string(debug.Stack())))...)
+				srv.OnMessageErrorEvent(new(Message).castToErrorMessage(e, MessageErrorActionNone, marks...), e)
+				if ctx.Writer.Written() {
+					ctx.Abort()
+				} else {
+					ctx.AbortWithStatus(http.StatusInternalServerError)
+				}
+			}()
+			ctx.Next()
+		})
+	}
+}
+
+// WithHttpRequestID 通过为请求注入请求 ID 的方式创建 HTTP 服务器
+//   - 请求 ID 优先从请求头 header 中获取，默认为 HttpRequestIDHeader，不存在时将随机生成
+//   - 请求 ID 将被写入同名的响应头中，并可通过 ctx.GetString(HttpRequestIDKey) 获取
+func WithHttpRequestID(header ...string) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkHttp {
+			return
+		}
+		var h = HttpRequestIDHeader
+		if len(header) > 0 && len(header[0]) > 0 {
+			h = header[0]
+		}
+		srv.ginServer.Use(func(ctx *gin.Context) {
+			id := ctx.GetHeader(h)
+			if len(id) == 0 {
+				var b [16]byte
+				_, _ = rand.Read(b[:])
+				id = hex.EncodeToString(b[:])
+			}
+			ctx.Set(HttpRequestIDKey, id)
+			ctx.Header(h, id)
+			ctx.Next()
+		})
+	}
+}
+
+// WithHttpCORS 通过处理跨域请求的方式创建 HTTP 服务器，config 为空时将允许所有来源的跨域请求
+//   - 预检请求将直接返回 http.StatusNoContent
+func WithHttpCORS(config ...HttpCORSConfig) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkHttp {
+			return
+		}
+		var c HttpCORSConfig
+		if len(config) > 0 {
+			c = config[0]
+		}
+		var allowAll = len(c.AllowOrigins) == 0
+		var origins = make(map[string]bool, len(c.AllowOrigins))
+		for _, origin := range c.AllowOrigins {
+			allowAll = allowAll || origin == "*"
+			origins[origin] = true
+		}
+		var methods = "GET,POST,PUT,PATCH,DELETE,HEAD,OPTIONS"
+		if len(c.AllowMethods) > 0 {
+			methods = strings.Join(c.AllowMethods, ",")
+		}
+		var headers, exposes = strings.Join(c.AllowHeaders, ","), strings.Join(c.ExposeHeaders, ",")
+		srv.ginServer.Use(func(ctx *gin.Context) {
+			origin := ctx.GetHeader("Origin")
+			if len(origin) == 0 {
+				ctx.Next()
+				return
+			}
+			if !allowAll && !origins[origin] {
+				ctx.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			if allowAll && !c.AllowCredentials {
+				ctx.Header("Access-Control-Allow-Origin", "*")
+			} else {
+				ctx.Header("Access-Control-Allow-Origin", origin)
+				ctx.Writer.Header().Add("Vary", "Origin")
+			}
+			if c.AllowCredentials {
+				ctx.Header("Access-Control-Allow-Credentials", "true")
+			}
+			if len(exposes) > 0 {
+				ctx.Header("Access-Control-Expose-Headers", exposes)
+			}
+			if ctx.Request.Method != http.MethodOptions || len(ctx.GetHeader("Access-Control-Request-Method")) == 0 {
+				ctx.Next()
+				return
+			}
+			ctx.Header("Access-Control-Allow-Methods", methods)
+			if len(headers) > 0 {
+				ctx.Header("Access-Control-Allow-Headers", headers)
+			} else if requested := ctx.GetHeader("Access-Control-Request-Headers"); len(requested) > 0 {
+				ctx.Header("Access-Control-Allow-Headers", requested)
+			}
+			if c.MaxAge > 0 {
+				ctx.Header("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge/time.Second)))
+			}
+			ctx.AbortWithStatus(http.StatusNoContent)
+		})
+	}
+}
+
+// WithHttpGzip 通过 gzip 压缩响应的方式创建 HTTP 服务器，仅对声明了 Accept-Encoding: gzip 的请求生效
+//   - level 为压缩级别，默认为 gzip.DefaultCompression
+//   - 已经设置了 Content-Encoding 的响应及 Websocket 升级请求将不会被压缩
+func WithHttpGzip(level ...int) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkHttp {
+			return
+		}
+		var l = gzip.DefaultCompression
+		if len(level) > 0 {
+			l = level[0]
+		}
+		if _, err := gzip.NewWriterLevel(io.Discard, l); err != nil {
+			panic(err)
+		}
+		var pool = sync.Pool{New: func() any {
+			w, _ := gzip.NewWriterLevel(io.Discard, l)
+			return w
+		}}
+		srv.ginServer.Use(func(ctx *gin.Context) {
+			if !strings.Contains(ctx.GetHeader("Accept-Encoding"), "gzip") || strings.EqualFold(ctx.GetHeader("Upgrade"), "websocket") {
+				ctx.Next()
+				return
+			}
+			gz := pool.Get().(*gzip.Writer)
+			writer := &httpGzipWriter{ResponseWriter: ctx.Writer, gz: gz}
+			gz.Reset(ctx.Writer)
+			ctx.Writer = writer
+			defer func() {
+				if writer.compress {
+					_ = gz.Close()
+				}
+				gz.Reset(io.Discard)
+				pool.Put(gz)
+			}()
+			ctx.Writer.Header().Add("Vary", "Accept-Encoding")
+			ctx.Next()
+		})
+	}
+}
+
+// httpGzipWriter 以 gzip 压缩写入响应体的 gin.ResponseWriter，是否压缩将在首次写入时决定
+type httpGzipWriter struct {
+	gin.ResponseWriter
+	gz       *gzip.Writer
+	decided  bool
+	compress bool
+}
+
+func (slf *httpGzipWriter) decide() {
+	if slf.decided {
+		return
+	}
+	slf.decided = true
+	header := slf.ResponseWriter.Header()
+	if len(header.Get("Content-Encoding")) > 0 || slf.ResponseWriter.Status() == http.StatusNoContent {
+		return
+	}
+	slf.compress = true
+	header.Set("Content-Encoding", "gzip")
+	header.Del("Content-Length")
+}
+
+func (slf *httpGzipWriter) Write(data []byte) (int, error) {
+	slf.decide()
+	if !slf.compress {
+		return slf.ResponseWriter.Write(data)
+	}
+	return slf.gz.Write(data)
+}
+
+func (slf *httpGzipWriter) WriteString(s string) (int, error) {
+	return slf.Write([]byte(s))
+}
+
+func (slf *httpGzipWriter) Flush() {
+	if slf.compress {
+		_ = slf.gz.Flush()
+	}
+	slf.ResponseWriter.Flush()
+}
+
+func httpSkipPaths(paths []string) map[string]bool {
+	var skips = make(map[string]bool, len(paths))
+	for _, path := range paths {
+		skips[path] = true
+	}
+	return skips
+}
+
+// parseJWT 解析并校验 HMAC 签名的 JWT，返回其声明
+func parseJWT(token string, secret []byte) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrJWTMalformed
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err = json.Unmarshal(headerBytes, &header); err != nil {
+		return nil, ErrJWTMalformed
+	}
+	var h func() hash.Hash
+	switch header.Alg {
+	case "HS256":
+		h = sha256.New
+	case "HS384":
+		h = sha512.New384
+	case "HS512":
+		h = sha512.New
+	default:
+		return nil, ErrJWTUnsupportedAlg
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	mac := hmac.New(h, secret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrJWTInvalidSignature
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	var claims map[string]any
+	if err = json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrJWTMalformed
+	}
+	now := float64(time.Now().Unix())
+	if exp, ok := claims["exp"].(float64); ok && now >= exp {
+		return nil, ErrJWTExpired
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now < nbf {
+		return nil, ErrJWTNotValidYet
+	}
+	return claims, nil
+}
+
+func newHttpLimiterStore(limit rate.Limit, burst int) *httpLimiterStore {
+	return &httpLimiterStore{
+		limit:    limit,
+		burst:    burst,
+		limiters: make(map[string]*httpLimiter),
+	}
+}
+
+// httpLimiterStore 按键存储的令牌桶集合，长时间未使用的令牌桶将被清理
+type httpLimiterStore struct {
+	limit     rate.Limit
+	burst     int
+	limiters  map[string]*httpLimiter
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+type httpLimiter struct {
+	*rate.Limiter
+	lastSeen time.Time
+}
+
+func (slf *httpLimiterStore) allow(key string) bool {
+	slf.mu.Lock()
+	now := time.Now()
+	if now.Sub(slf.lastSweep) > time.Minute {
+		for k, l := range slf.limiters {
+			if now.Sub(l.lastSeen) > time.Minute {
+				delete(slf.limiters, k)
+			}
+		}
+		slf.lastSweep = now
+	}
+	limiter, exist := slf.limiters[key]
+	if !exist {
+		limiter = &httpLimiter{Limiter: rate.NewLimiter(slf.limit, slf.burst)}
+		slf.limiters[key] = limiter
+	}
+	limiter.lastSeen = now
+	slf.mu.Unlock()
+	return limiter.AllowN(now, 1)
+}
@@ -0,0 +1,254 @@
+package server_test
+
+import (
+	"compress/gzip"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/server"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithHttpRateLimit(t *testing.T) {
+	srv := server.New(server.NetworkHttp, server.WithHttpRateLimit(1, 2))
+	srv.HttpServer().GET("/", func(ctx *server.HttpContext) {
+		ctx.Gin().Status(http.StatusOK)
+	})
+	engine := srv.HttpServer().Gin()
+
+	var codes []int
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		codes = append(codes, w.Code)
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Fatalf("unexpected status codes: %v", codes)
+	}
+}
+
+func TestWithHttpRateLimit_ForwardedFor(t *testing.T) {
+	var request = func(engine http.Handler, forwardedFor string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Forwarded-For", forwardedFor)
+		engine.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// 未设置可信代理时，伪造的 X-Forwarded-For 请求头应共享同一个令牌桶
+	srv := server.New(server.NetworkHttp, server.WithHttpRateLimit(1, 2))
+	srv.HttpServer().GET("/", func(ctx *server.HttpContext) {
+		ctx.Gin().Status(http.StatusOK)
+	})
+	var codes []int
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		codes = append(codes, request(srv.HttpServer().Gin(), ip))
+	}
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK || codes[2] != http.StatusTooManyRequests {
+		t.Fatalf("spoofed X-Forwarded-For should share one bucket, got %v", codes)
+	}
+
+	// 请求来自可信代理时，将以请求头中的客户端 IP 作为限流键
+	srv = server.New(server.NetworkHttp, server.WithTrustedProxies("192.0.2.1"), server.WithHttpRateLimit(1, 2))
+	srv.HttpServer().GET("/", func(ctx *server.HttpContext) {
+		ctx.Gin().Status(http.StatusOK)
+	})
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+		if code := request(srv.HttpServer().Gin(), ip); code != http.StatusOK {
+			t.Fatalf("expected a bucket per forwarded client behind a trusted proxy, got %d for %s", code, ip)
+		}
+	}
+}
+
+// signJWT 使用 HS256 对声明进行签名，生成用于测试的令牌
+func signJWT(secret []byte, claims string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	unsigned := encode([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + encode([]byte(claims))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + encode(mac.Sum(nil))
+}
+
+func TestWithHttpJWTAuth(t *testing.T) {
+	secret := []byte("secret")
+	srv := server.New(server.NetworkHttp, server.WithHttpJWTAuth(secret, "/login"))
+	srv.HttpServer().GET("/me", func(ctx *server.HttpContext) {
+		claims, _ := ctx.Gin().Get(server.HttpJWTClaimsKey)
+		ctx.Gin().String(http.StatusOK, "%v", claims.(map[string]any)["uid"])
+	})
+	srv.HttpServer().GET("/login", func(ctx *server.HttpContext) {
+		ctx.Gin().Status(http.StatusOK)
+	})
+	engine := srv.HttpServer().Gin()
+
+	var cases = []struct {
+		name          string
+		path          string
+		authorization string
+		code          int
+		body          string
+	}{
+		{"valid", "/me", "Bearer " + signJWT(secret, `{"uid":"10001","exp":`+strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)+`}`), http.StatusOK, "10001"},
+		{"expired", "/me", "Bearer " + signJWT(secret, `{"uid":"10001","exp":`+strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)+`}`), http.StatusUnauthorized, ""},
+		{"missing", "/me", "", http.StatusUnauthorized, ""},
+		{"invalid signature", "/me", "Bearer " + signJWT([]byte("other"), `{"uid":"10001"}`), http.StatusUnauthorized, ""},
+		{"not bearer", "/me", "Basic " + signJWT(secret, `{"uid":"10001"}`), http.StatusUnauthorized, ""},
+		{"skip path", "/login", "", http.StatusOK, ""},
+	}
+	for _, c := range cases {
+		request := httptest.NewRequest(http.MethodGet, c.path, nil)
+		if c.authorization != "" {
+			request.Header.Set("Authorization", c.authorization)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, request)
+		if w.Code != c.code || w.Body.String() != c.body {
+			t.Fatalf("%s: expected %d %q, got %d %q", c.name, c.code, c.body, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestWithHttpSignature(t *testing.T) {
+	var secret = []byte("secret")
+	srv := server.New(server.NetworkHttp, server.WithHttpSignature(secret, time.Minute), server.WithHttpSignatureBodyLimit(64))
+	srv.HttpServer().POST("/pay", func(ctx *server.HttpContext) {
+		ctx.Gin().Status(http.StatusOK)
+	})
+	engine := srv.HttpServer().Gin()
+
+	var cases = []struct {
+		name string
+		body []byte
+		sign func(ts string, body []byte) string
+		code int
+	}{
+		{"valid", []byte(`{"amount":1}`), func(ts string, body []byte) string {
+			return server.HttpSign(secret, http.MethodPost, "/pay", "id=1", ts, body)
+		}, http.StatusOK},
+		{"invalid", []byte(`{"amount":1}`), func(ts string, body []byte) string {
+			return server.HttpSign([]byte("other"), http.MethodPost, "/pay", "id=1", ts, body)
+		}, http.StatusUnauthorized},
+		{"too large", []byte(strings.Repeat("a", 65)), func(ts string, body []byte) string {
+			return server.HttpSign(secret, http.MethodPost, "/pay", "id=1", ts, body)
+		}, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, c := range cases {
+		body := c.body
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req := httptest.NewRequest(http.MethodPost, "/pay?id=1", strings.NewReader(string(body)))
+		req.Header.Set(server.HttpTimestampHeader, ts)
+		req.Header.Set(server.HttpSignatureHeader, c.sign(ts, body))
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		if w.Code != c.code {
+			t.Fatalf("%s: expected status %d, got %d", c.name, c.code, w.Code)
+		}
+	}
+}
+
+func TestWithWebsocketJWTAuth(t *testing.T) {
+	secret := []byte("secret")
+	encode := base64.RawURLEncoding.EncodeToString
+	unsigned := encode([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + encode([]byte(`{"uid":"10001"}`))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(unsigned))
+	token := unsigned + "." + encode(mac.Sum(nil))
+
+	srv := server.New(server.NetworkWebsocket, server.WithWebsocketJWTAuth(secret))
+	var uid = make(chan any, 1)
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		uid <- conn.GetData(server.WebsocketJWTClaimsKey).(map[string]any)["uid"]
+	})
+	var addr = freeAddr(t, "tcp")
+	runServer(t, srv, addr+"/jwt")
+	defer srv.Shutdown()
+
+	_, resp, err := websocket.DefaultDialer.Dial("ws://"+addr+"/jwt?token=invalid", nil)
+	if err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected upgrade to be rejected with %d, got %v", http.StatusUnauthorized, err)
+	}
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+addr+"/jwt?token="+token, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	select {
+	case v := <-uid:
+		if v != "10001" {
+			t.Fatalf("expected uid 10001, got %v", v)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection opened event was not triggered")
+	}
+}
+
+func TestWithHttpPresets(t *testing.T) {
+	var recovered error
+	srv := server.New(server.NetworkHttp,
+		server.WithHttpRequestID(),
+		server.WithHttpRecovery(),
+		server.WithHttpCORS(server.HttpCORSConfig{AllowOrigins: []string{"https://a.com"}, MaxAge: time.Minute}),
+		server.WithHttpGzip(),
+	)
+	srv.RegMessageErrorEvent(func(srv *server.Server, message *server.Message, err error) {
+		recovered = err
+	})
+	srv.HttpServer().GET("/panic", func(ctx *server.HttpContext) {
+		panic(errors.New("boom"))
+	})
+	srv.HttpServer().GET("/hello", func(ctx *server.HttpContext) {
+		ctx.Gin().String(http.StatusOK, strings.Repeat("hello", 100))
+	})
+	engine := srv.HttpServer().Gin()
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusInternalServerError || recovered == nil || recovered.Error() != "boom" {
+		t.Fatalf("expected the panic to be recovered, got %d, %v", w.Code, recovered)
+	}
+	if len(w.Header().Get(server.HttpRequestIDHeader)) != 32 {
+		t.Fatalf("expected a generated request id, got %q", w.Header().Get(server.HttpRequestIDHeader))
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/hello", nil)
+	request.Header.Set("Accept-Encoding", "gzip")
+	request.Header.Set("Origin", "https://a.com")
+	request.Header.Set(server.HttpRequestIDHeader, "id")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, request)
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://a.com" || w.Header().Get(server.HttpRequestIDHeader) != "id" {
+		t.Fatalf("unexpected headers: %v", w.Header())
+	}
+	reader, err := gzip.NewReader(w.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body, _ := io.ReadAll(reader); string(body) != strings.Repeat("hello", 100) {
+		t.Fatalf("unexpected body: %s", body)
+	}
+
+	request = httptest.NewRequest(http.MethodOptions, "/hello", nil)
+	request.Header.Set("Origin", "https://b.com")
+	request.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, request)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected the origin to be rejected, got %d", w.Code)
+	}
+	request.Header.Set("Origin", "https://a.com")
+	w = httptest.NewRecorder()
+	engine.ServeHTTP(w, request)
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Max-Age") != "60" {
+		t.Fatalf("unexpected preflight response: %d, %v", w.Code, w.Header())
+	}
+}
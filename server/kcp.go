@@ -0,0 +1,72 @@
+package server
+
+import (
+	"github.com/xtaci/kcp-go/v5"
+)
+
+// KcpConfig KCP 调优配置，通过 WithKcpConfig 进行设置
+//   - 各项参数的含义可参考 https://github.com/skywind3000/kcp/wiki
+//   - 值为零值的参数将采用 kcp-go 的默认值
+type KcpConfig struct {
+	NoDelay      bool           // 是否启用 nodelay 模式
+	Interval     int            // 内部刷新间隔（毫秒），为 0 时采用默认值 40ms
+	Resend       int            // 快速重传触发次数，为 0 时表示关闭快速重传
+	NoCongestion bool           // 是否关闭拥塞控制
+	SndWnd       int            // 发送窗口大小
+	RcvWnd       int            // 接收窗口大小
+	MTU          int            // 最大传输单元
+	DataShards   int            // FEC 数据分片数量，为 0 时表示不启用 FEC
+	ParityShards int            // FEC 校验分片数量
+	BlockCrypt   kcp.BlockCrypt // 数据包加密方式，为 nil 时表示不加密，例如 kcp.NewAESBlockCrypt(key)
+	StreamMode   bool           // 是否启用流模式，启用后将合并数据包以提高吞吐量
+	WriteDelay   bool           // 是否延迟到下一次刷新时再发送数据
+	AckNoDelay   bool           // 是否立即发送 ACK
+	DSCP         int            // 差分服务代码点，将作用于监听器
+}
+
+// NewKcpFastConfig 创建一个适用于对延迟敏感的游戏的 KCP 配置
+//   - 等同于 nodelay(1, 10, 2, 1)，发送及接收窗口为 1024
+func NewKcpFastConfig() *KcpConfig {
+	return &KcpConfig{
+		NoDelay:      true,
+		Interval:     10,
+		Resend:       2,
+		NoCongestion: true,
+		SndWnd:       1024,
+		RcvWnd:       1024,
+		AckNoDelay:   true,
+	}
+}
+
+// Apply 将配置应用到会话，可用于服务器或客户端的 kcp.UDPSession
+func (slf *KcpConfig) Apply(session *kcp.UDPSession) {
+	if slf.NoDelay || slf.Interval > 0 || slf.Resend > 0 || slf.NoCongestion {
+		var nodelay, nc, interval = 0, 0, slf.Interval
+		if slf.NoDelay {
+			nodelay = 1
+		}
+		if slf.NoCongestion {
+			nc = 1
+		}
+		if interval <= 0 {
+			interval = 40
+		}
+		session.SetNoDelay(nodelay, interval, slf.Resend, nc)
+	}
+	if slf.SndWnd > 0 || slf.RcvWnd > 0 {
+		var snd, rcv = slf.SndWnd, slf.RcvWnd
+		if snd <= 0 {
+			snd = 32
+		}
+		if rcv <= 0 {
+			rcv = 32
+		}
+		session.SetWindowSize(snd, rcv)
+	}
+	if slf.MTU > 0 {
+		session.SetMtu(slf.MTU)
+	}
+	session.SetStreamMode(slf.StreamMode)
+	session.SetWriteDelay(slf.WriteDelay)
+	session.SetACKNoDelay(slf.AckNoDelay)
+}
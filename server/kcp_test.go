@@ -0,0 +1,66 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/xtaci/kcp-go/v5"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestWithKcpConfig(t *testing.T) {
+	config := server.NewKcpFastConfig()
+	config.DataShards, config.ParityShards = 10, 3
+	config.BlockCrypt, _ = kcp.NewAESBlockCrypt([]byte("0123456789abcdef"))
+	srv := server.New(server.NetworkKcp, server.WithKcpConfig(config))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		conn.Write(packet)
+	})
+	var addr = freeAddr(t, "udp")
+	runServer(t, srv, addr)
+	defer srv.Shutdown()
+
+	echo := func(block kcp.BlockCrypt) (string, error) {
+		session, err := kcp.DialWithOptions(addr, block, config.DataShards, config.ParityShards)
+		if err != nil {
+			return "", err
+		}
+		defer session.Close()
+		config.Apply(session)
+		if _, err = session.Write([]byte("hello")); err != nil {
+			return "", err
+		}
+		_ = session.SetReadDeadline(time.Now().Add(time.Second))
+		var buf = make([]byte, 16)
+		n, err := session.Read(buf)
+		return string(buf[:n]), err
+	}
+
+	if reply, err := echo(config.BlockCrypt); err != nil || reply != "hello" {
+		t.Fatalf("expected echo with the same config, got %q %v", reply, err)
+	}
+	mismatch, _ := kcp.NewAESBlockCrypt([]byte("fedcba9876543210"))
+	if reply, err := echo(mismatch); err == nil {
+		t.Fatalf("expected no echo with a different block crypt, got %q", reply)
+	}
+}
+
+func TestServer_ShutdownReleasesKcpPort(t *testing.T) {
+	srv := server.New(server.NetworkKcp)
+	var addr = freeAddr(t, "udp")
+	runServer(t, srv, addr)
+	srv.Shutdown()
+
+	var deadline = time.Now().Add(3 * time.Second)
+	for {
+		conn, err := net.ListenPacket("udp", addr)
+		if err == nil {
+			_ = conn.Close()
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("kcp port was not released after shutdown: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
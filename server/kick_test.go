@@ -0,0 +1,68 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestServer_Kick(t *testing.T) {
+	srv := server.New(server.NetworkTcp)
+	var kicked = make(chan int, 1)
+	var closed = make(chan server.CloseReason, 1)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		if srv.Kick("not-exist", 0, nil) {
+			t.Error("expected kicking an unknown connection to return false")
+		}
+		// 踢出前写入的数据包应在最后一个数据包之前送达
+		conn.Write([]byte("last-"))
+		if !srv.Kick(conn.GetID(), 7, []byte("bye")) {
+			t.Error("expected kicking an online connection to return true")
+		}
+	})
+	srv.RegConnectionKickedEvent(func(srv *server.Server, conn *server.Conn, code int, payload []byte) {
+		kicked <- code
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
+		closed <- reason
+	})
+	var addr = freeAddr(t, "tcp")
+	runServer(t, srv, addr)
+	defer srv.Shutdown()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err = conn.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	data, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "last-bye" {
+		t.Fatalf("expected the kick payload to be flushed before closing, got %q", data)
+	}
+
+	select {
+	case code := <-kicked:
+		if code != 7 {
+			t.Fatalf("expected kick code 7, got %d", code)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ConnectionKickedEvent was not triggered")
+	}
+	select {
+	case reason := <-closed:
+		if reason != server.CloseReasonKicked {
+			t.Fatalf("expected %s, got %s", server.CloseReasonKicked, reason)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("connection was not closed")
+	}
+}
@@ -0,0 +1,621 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/server/internal/logger"
+	"github.com/panjf2000/gnet"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/quic-go/webtransport-go"
+	"github.com/xtaci/kcp-go/v5"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// listener 服务器的附加侦听器
+type listener struct {
+	network Network
+	addr    string
+}
+
+// String 返回侦听器的字符串表示，例如 "tcp://:9000"
+func (slf listener) String() string {
+	return fmt.Sprintf("%s://%s", slf.network, slf.addr)
+}
+
+// AddListener 为服务器添加一个附加侦听器，使服务器能够同时在多个网络及地址上接收连接，例如同时为网页客户端提供 Websocket 及为原生客户端提供 TCP 服务
+//   - 所有侦听器接收的连接共享同一套事件及消息处理流程，可通过 Conn.GetNetwork 区分连接所属的网络类型
+//   - 仅支持 TCP、UDP、Unix、KCP、Websocket 及 WebTransport 网络类型，且服务器自身也需要是这些网络类型之一，否则将引发 panic
+//   - 地址格式与 Run 相同，需要在 Run 之前调用，附加侦听器将与服务器一同启动及关闭
+//   - 附加的 Websocket 侦听器使用独立的路由，不会与服务器自身的 Websocket 侦听器冲突
+//
+// 例如：server.New(server.NetworkWebsocket).AddListener(server.NetworkTcp, ":9000").Run(":8888/ws")
+func (slf *Server) AddListener(network Network, addr string) *Server {
+	if !isListenerNetwork(slf.network) || !isListenerNetwork(network) {
+		panic(fmt.Errorf("%w: %s -> %s", ErrListenerUnsupportedNetwork, slf.network, network))
+	}
+	slf.listeners = append(slf.listeners, listener{network: network, addr: addr})
+	return slf
+}
+
+// isListenerNetwork 检查网络类型是否为基于连接的网络类型
+func isListenerNetwork(network Network) bool {
+	switch network {
+	case NetworkTcp, NetworkTcp4, NetworkTcp6, NetworkUdp, NetworkUdp4, NetworkUdp6, NetworkUnix, NetworkKcp, NetworkWebsocket, NetworkWebTransport:
+		return true
+	default:
+		return false
+	}
+}
+
+// splitListenerAddr 将 ":8888/ws" 形式的地址拆分为侦听地址及路由路径，当不包含路由路径时路由路径为 "/"
+func splitListenerAddr(addr string) (host, pattern string) {
+	if index := strings.Index(addr, "/"); index != -1 {
+		return addr[:index], addr[index:]
+	}
+	return addr, "/"
+}
+
+// listenFailed 在侦听器运行失败时触发 ListenErrorEvent 并由于该错误关闭服务器
+//   - 热重启期间旧进程的侦听器将被主动关闭，此时产生的错误将被忽略
+func (slf *Server) listenFailed(network Network, addr string, err error) {
+	if slf.IsRestarting() {
+		return
+	}
+	slf.OnListenErrorEvent(network, addr, err)
+	slf.PushErrorMessage(err, MessageErrorActionShutdown)
+}
+
+// listenHandshake 在独立的协程中运行 serve，并阻塞至侦听器就绪或启动失败
+//   - serve 应当在完成端口绑定后调用 ready，在此之前返回的错误将作为启动失败的错误返回
+//   - 侦听器就绪后发生的错误将通过 listenFailed 关闭服务器
+func (slf *Server) listenHandshake(network Network, addr string, serve func(ready func()) error) error {
+	var result = make(chan error, 1)
+	var once sync.Once
+	go func() {
+		err := serve(func() {
+			once.Do(func() { result <- nil })
+		})
+		var startup bool
+		once.Do(func() {
+			startup = true
+			result <- err
+		})
+		if !startup && err != nil {
+			slf.listenFailed(network, addr, err)
+		}
+	}()
+	return <-result
+}
+
+// listen 在特定地址上创建侦听器，返回的 serve 函数将开始接收连接并阻塞至侦听结束
+//   - serve 将在完成端口绑定后调用 ready，用于 listenHandshake 判断侦听器是否启动成功
+func (slf *Server) listen(network Network, addr string) (serve func(ready func()) error, err error) {
+	switch network {
+	case NetworkTcp, NetworkTcp4, NetworkTcp6, NetworkUdp, NetworkUdp4, NetworkUdp6, NetworkUnix:
+		if slf.hotRestart != nil && !isUDPNetwork(network) {
+			return slf.listenInheritable(network, addr)
+		}
+		var protoAddr = fmt.Sprintf("%s://%s", network, addr)
+		var handler = &gNet{Server: slf, network: network}
+		if isUDPNetwork(network) {
+			handler.udp = newUDPSessions()
+		}
+		if network == NetworkUnix {
+			// gnet 会将地址转换为小写，socket 文件实际创建于小写路径
+			addr := strings.ToLower(addr)
+			if err = removeStaleUnixSocket(addr); err != nil {
+				return nil, err
+			}
+			var bound atomic.Bool
+			handler.init = func() error {
+				bound.Store(true)
+				return slf.setupUnixSocket(addr)
+			}
+			slf.listenerClosers = append(slf.listenerClosers, func() error {
+				err := gnet.Stop(context.Background(), protoAddr)
+				if bound.Load() {
+					if removeErr := os.Remove(addr); removeErr != nil && !errors.Is(removeErr, fs.ErrNotExist) {
+						return removeErr
+					}
+				}
+				return err
+			})
+		} else {
+			slf.listenerClosers = append(slf.listenerClosers, func() error {
+				return gnet.Stop(context.Background(), protoAddr)
+			})
+		}
+		return func(ready func()) error {
+			handler.ready = ready
+			if err := gnet.Serve(handler, protoAddr,
+				gnet.WithLogger(new(logger.GNet)),
+				gnet.WithTicker(true),
+				gnet.WithMulticore(true),
+				gnet.WithReusePort(slf.hotRestart != nil),
+			); err != nil {
+				return err
+			}
+			return handler.initErr
+		}, nil
+	case NetworkKcp:
+		return slf.listenKcp(addr)
+	case NetworkWebsocket:
+		return slf.listenWebsocket(addr), nil
+	case NetworkWebTransport:
+		return slf.listenWebTransport(addr)
+	default:
+		return nil, ErrCanNotSupportNetwork
+	}
+}
+
+// listenInheritable 创建可被热重启继承的 TCP 或 Unix 侦听器，启用 WithHotRestart 时将代替 gnet 接收连接
+//   - gnet 无法单独关闭侦听器，热重启排空时需要在不影响已建立连接的情况下停止接收新连接
+//   - Unix 侦听器的 socket 文件将在服务器关闭时删除，热重启时将保留给新进程使用
+func (slf *Server) listenInheritable(network Network, addr string) (serve func(ready func()) error, err error) {
+	var listener net.Listener
+	if network == NetworkUnix {
+		// 与 gnet 驱动的 Unix 侦听器保持一致，socket 文件创建于小写路径
+		addr = strings.ToLower(addr)
+		listener, err = slf.listenUnix(addr)
+	} else {
+		listener, err = slf.listenTCP(string(network), addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var closed atomic.Bool
+	slf.listenerClosers = append(slf.listenerClosers, func() error {
+		closed.Store(true)
+		// 热重启排空时侦听器已被提前关闭
+		if err := listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			return err
+		}
+		if network == NetworkUnix && !slf.IsRestarting() {
+			if err := os.Remove(addr); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+		return nil
+	})
+	return func(ready func()) error {
+		slf.hotRestart.accepting.Add(1)
+		defer slf.hotRestart.accepting.Done()
+		ready()
+		for {
+			c, err := listener.Accept()
+			if err != nil {
+				if closed.Load() || errors.Is(err, net.ErrClosed) {
+					return nil
+				}
+				return err
+			}
+			ip := c.RemoteAddr().String()
+			if index := strings.LastIndex(ip, ":"); index != -1 {
+				ip = ip[0:index]
+			}
+			if !slf.filterConnection(ip) {
+				_ = c.Close()
+				continue
+			}
+
+			conn := newNetConn(slf, network, c)
+			conn.filtered = slf.connFilter != nil
+			if !slf.protectConn(conn, func() { slf.OnConnectionOpenedEvent(conn) }) {
+				continue
+			}
+
+			go func(conn *Conn) {
+				buf := make([]byte, 4096)
+				for !conn.IsClosed() {
+					n, err := conn.nc.Read(buf)
+					if err != nil {
+						if !conn.IsClosed() {
+							conn.CloseWithReason(readCloseReason(err), err)
+						}
+						break
+					}
+					if !slf.protectConn(conn, func() { slf.receivePacket(conn, 0, buf[:n]) }) {
+						break
+					}
+				}
+			}(conn)
+		}
+	}, nil
+}
+
+// listenKcp 创建 KCP 侦听器
+func (slf *Server) listenKcp(addr string) (serve func(ready func()) error, err error) {
+	var config = slf.kcpConfig
+	if config == nil {
+		config = new(KcpConfig)
+	}
+	listener, err := kcp.ListenWithOptions(addr, config.BlockCrypt, config.DataShards, config.ParityShards)
+	if err != nil {
+		return nil, err
+	}
+	if config.DSCP > 0 {
+		_ = listener.SetDSCP(config.DSCP)
+	}
+	var closed atomic.Bool
+	slf.listenerClosers = append(slf.listenerClosers, func() error {
+		closed.Store(true)
+		return listener.Close()
+	})
+	return func(ready func()) error {
+		ready()
+		for {
+			session, err := listener.AcceptKCP()
+			if err != nil {
+				// 侦听器关闭或底层连接失效后 AcceptKCP 将持续返回错误，此时应结束侦听而非空转
+				if closed.Load() || errors.Is(err, io.ErrClosedPipe) {
+					return nil
+				}
+				return err
+			}
+			config.Apply(session)
+			ip := session.RemoteAddr().String()
+			if index := strings.LastIndex(ip, ":"); index != -1 {
+				ip = ip[0:index]
+			}
+			if !slf.filterConnection(ip) {
+				_ = session.Close()
+				continue
+			}
+
+			conn := newKcpConn(slf, session)
+			conn.filtered = slf.connFilter != nil
+			if !slf.protectConn(conn, func() { slf.OnConnectionOpenedEvent(conn) }) {
+				continue
+			}
+
+			go func(conn *Conn) {
+				buf := make([]byte, 4096)
+				for !conn.IsClosed() {
+					n, err := conn.kcp.Read(buf)
+					if err != nil {
+						if !conn.IsClosed() {
+							conn.CloseWithReason(readCloseReason(err), err)
+						}
+						break
+					}
+					if !slf.protectConn(conn, func() { slf.receivePacket(conn, 0, buf[:n]) }) {
+						break
+					}
+				}
+			}(conn)
+		}
+	}, nil
+}
+
+// listenWebsocket 创建 Websocket 侦听器，除 addr 中的路由外，还将注册通过 Server.WebsocketRoute 声明的路由
+//   - 每个侦听器均使用独立的 http.Server，服务器关闭时将优雅关闭
+//   - 非 Websocket 升级请求将交由 Server.HttpRouter 的路由器处理
+func (slf *Server) listenWebsocket(addr string) (serve func(ready func()) error) {
+	host, pattern := splitListenerAddr(addr)
+	var upgrade = newDefaultWebsocketUpgrader()
+	if slf.websocketUpgrader != nil {
+		*upgrade = *slf.websocketUpgrader
+	}
+	if slf.websocketCheckOrigin != nil {
+		upgrade.CheckOrigin = slf.websocketCheckOrigin
+	}
+	var mux = http.NewServeMux()
+	var patterns = map[string]bool{pattern: true}
+	mux.HandleFunc(pattern, slf.websocketHandler(pattern, upgrade))
+	for _, p := range slf.websocketPatterns {
+		if !patterns[p] {
+			patterns[p] = true
+			mux.HandleFunc(p, slf.websocketHandler(p, upgrade))
+		}
+	}
+
+	var handler http.Handler = mux
+	if slf.ginServer != nil {
+		handler = http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if websocket.IsWebSocketUpgrade(request) {
+				mux.ServeHTTP(writer, request)
+				return
+			}
+			slf.ginServer.ServeHTTP(writer, request)
+		})
+	}
+	var server = &http.Server{Addr: host, Handler: handler}
+	slf.listenerClosers = append(slf.listenerClosers, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		return server.Shutdown(ctx)
+	})
+	return func(ready func()) error {
+		config, err := slf.loadTLSConfig()
+		if err != nil {
+			return err
+		}
+		var bind = host
+		if len(bind) == 0 {
+			if bind = ":http"; config != nil {
+				bind = ":https"
+			}
+		}
+		listener, err := slf.listenTCP(string(NetworkWebsocket), bind)
+		if err != nil {
+			return err
+		}
+		ready()
+		if config != nil {
+			server.TLSConfig = config
+			err = server.ServeTLS(listener, "", "")
+		} else {
+			err = server.Serve(listener)
+		}
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
+
+// requestIP 获取 HTTP 请求的客户端 IP
+//   - 默认采用请求的远程地址，仅当远程地址属于 WithTrustedProxies 指定的可信代理时才会信任 X-Forwarded-For 及 X-Real-IP 请求头
+func (slf *Server) requestIP(request *http.Request) string {
+	ip, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		ip = request.RemoteAddr
+	}
+	if !slf.isTrustedProxy(ip) {
+		return ip
+	}
+	// X-Forwarded-For 中由右至左第一个不属于可信代理的地址即为客户端地址
+	if forwarded := request.Header.Get("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			if ip = hop; !slf.isTrustedProxy(hop) {
+				return ip
+			}
+		}
+		return ip
+	}
+	if realIP := strings.TrimSpace(request.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return ip
+}
+
+// isTrustedProxy 检查 ip 是否属于可信代理
+func (slf *Server) isTrustedProxy(ip string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, proxy := range slf.trustedProxies {
+		if proxy.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// websocketHandler 创建处理特定路由的 Websocket 连接的处理函数
+func (slf *Server) websocketHandler(pattern string, upgrade *websocket.Upgrader) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ip := slf.requestIP(request)
+		var claims map[string]any
+		if slf.websocketJWT != nil {
+			var err error
+			if claims, err = slf.websocketJWT(request); err != nil {
+				writer.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		if !slf.filterConnection(ip) {
+			writer.WriteHeader(http.StatusForbidden)
+			return
+		}
+		ws, err := upgrade.Upgrade(writer, request, nil)
+		if err != nil {
+			if slf.connFilter != nil {
+				slf.connFilter.release(ip)
+			}
+			return
+		}
+		if slf.websocketCompression > 0 {
+			_ = ws.SetCompressionLevel(slf.websocketCompression)
+		}
+		ws.EnableWriteCompression(slf.websocketWriteCompression)
+		if slf.maxPacketSize > 0 {
+			ws.SetReadLimit(int64(slf.maxPacketSize))
+		}
+		conn := newWebsocketConn(slf, ws, ip)
+		conn.filtered = slf.connFilter != nil
+		conn.wsPattern = pattern
+		ws.SetPongHandler(func(string) error {
+			conn.refreshActive()
+			if slf.websocketReadDeadline > 0 {
+				return ws.SetReadDeadline(time.Now().Add(slf.websocketReadDeadline))
+			}
+			return nil
+		})
+		conn.SetData(wsRequestKey, request)
+		for k, v := range request.URL.Query() {
+			if len(v) == 1 {
+				conn.SetData(k, v[0])
+			} else {
+				conn.SetData(k, v)
+			}
+		}
+		if claims != nil {
+			conn.SetData(WebsocketJWTClaimsKey, claims)
+		}
+		if !slf.protectConn(conn, func() { slf.OnConnectionOpenedEvent(conn) }) {
+			return
+		}
+
+		for !conn.IsClosed() {
+			if slf.websocketReadDeadline > 0 {
+				if err := ws.SetReadDeadline(time.Now().Add(slf.websocketReadDeadline)); err != nil {
+					conn.CloseWithReason(readCloseReason(err), err)
+					break
+				}
+			}
+			messageType, packet, buffer, readErr := slf.readWebsocketMessage(ws)
+			if readErr != nil {
+				if errors.Is(readErr, websocket.ErrReadLimit) {
+					slf.oversizePacket(conn, -1)
+				} else if !conn.IsClosed() {
+					conn.CloseWithReason(readCloseReason(readErr), readErr)
+				}
+				break
+			}
+			if len(slf.supportMessageTypes) > 0 && !slf.supportMessageTypes[messageType] {
+				buffer.release()
+				conn.CloseWithReason(readCloseReason(ErrWebsocketIllegalMessageType), ErrWebsocketIllegalMessageType)
+				break
+			}
+			conn.refreshActive()
+			if !slf.protectConn(conn, func() { slf.pushPacketMessage(conn, messageType, packet, buffer) }) {
+				break
+			}
+		}
+		// 关闭握手完成或读取失败后关闭底层连接
+		_ = ws.Close()
+	}
+}
+
+// readWebsocketMessage 读取一条 Websocket 消息，当启用数据包缓冲池时将读取到缓冲池的缓冲区中
+func (slf *Server) readWebsocketMessage(ws *websocket.Conn) (messageType int, packet []byte, buffer *packetBuffer, err error) {
+	if slf.packetBuffers == nil {
+		messageType, packet, err = ws.ReadMessage()
+		return
+	}
+	var reader io.Reader
+	if messageType, reader, err = ws.NextReader(); err != nil {
+		return
+	}
+	if buffer, err = slf.packetBuffers.readFrom(reader); err != nil {
+		return
+	}
+	return messageType, buffer.data, buffer, nil
+}
+
+// listenWebTransport 创建 WebTransport 侦听器
+func (slf *Server) listenWebTransport(addr string) (serve func(ready func()) error, err error) {
+	if slf.tlsConfig == nil && (len(slf.certFile) == 0 || len(slf.keyFile) == 0) {
+		return nil, ErrWebTransportRequireTLS
+	}
+	host, pattern := splitListenerAddr(addr)
+	var mux = http.NewServeMux()
+	var server = &webtransport.Server{
+		H3: http3.Server{Addr: host, Handler: mux},
+	}
+	mux.HandleFunc(pattern, func(writer http.ResponseWriter, request *http.Request) {
+		ip := slf.requestIP(request)
+		if !slf.filterConnection(ip) {
+			writer.WriteHeader(http.StatusForbidden)
+			return
+		}
+		session, err := server.Upgrade(writer, request)
+		if err != nil {
+			if slf.connFilter != nil {
+				slf.connFilter.release(ip)
+			}
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		conn := newWebTransportConn(slf, session, ip)
+		conn.filtered = slf.connFilter != nil
+		conn.SetData(wsRequestKey, request)
+		for k, v := range request.URL.Query() {
+			if len(v) == 1 {
+				conn.SetData(k, v[0])
+			} else {
+				conn.SetData(k, v)
+			}
+		}
+		if !slf.protectConn(conn, func() { slf.OnConnectionOpenedEvent(conn) }) {
+			return
+		}
+
+		for !conn.IsClosed() {
+			stream, err := session.AcceptStream(session.Context())
+			if err != nil {
+				conn.CloseWithReason(readCloseReason(err), err)
+				break
+			}
+			conn.wtStream.CompareAndSwap(nil, &stream)
+			// 每个流持有独立的解码缓冲区，并发的流之间不会交错数据
+			//   - 当前使用的 webtransport-go 版本未开放会话级别的数据报接口，因此仅支持基于流的收发
+			go func(stream webtransport.Stream) {
+				var codecBuffer []byte
+				buf := make([]byte, 4096)
+				for !conn.IsClosed() {
+					n, err := stream.Read(buf)
+					if n > 0 && !slf.protectConn(conn, func() { slf.receivePacketWithBuffer(conn, 0, &codecBuffer, buf[:n]) }) {
+						break
+					}
+					if err != nil {
+						if !conn.IsClosed() && !errors.Is(err, io.EOF) {
+							conn.CloseWithReason(readCloseReason(err), err)
+						}
+						break
+					}
+				}
+			}(stream)
+		}
+	})
+	slf.listenerClosers = append(slf.listenerClosers, server.Close)
+	return func(ready func()) error {
+		config, err := slf.loadTLSConfig()
+		if err != nil {
+			return err
+		}
+		server.H3.TLSConfig = config
+		var bind = host
+		if len(bind) == 0 {
+			bind = ":https"
+		}
+		packetConn, err := net.ListenPacket(string(NetworkUdp), bind)
+		if err != nil {
+			return err
+		}
+		defer packetConn.Close()
+		ready()
+		if err = server.Serve(packetConn); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}, nil
+}
+
+// loadTLSConfig 根据 WithTLS 及 WithTLSConfig 的配置生成 tls.Config，当未配置 TLS 时返回 nil
+//   - 每次调用均会返回新的 tls.Config，避免多个侦听器之间相互影响
+func (slf *Server) loadTLSConfig() (*tls.Config, error) {
+	if slf.tlsConfig == nil && len(slf.certFile)+len(slf.keyFile) == 0 {
+		return nil, nil
+	}
+	var config = new(tls.Config)
+	if slf.tlsConfig != nil {
+		config = slf.tlsConfig.Clone()
+	}
+	if len(slf.certFile)+len(slf.keyFile) > 0 {
+		cert, err := tls.LoadX509KeyPair(slf.certFile, slf.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+	return config, nil
+}
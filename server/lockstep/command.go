@@ -0,0 +1,66 @@
+package lockstep
+
+import "errors"
+
+var (
+	// ErrClientNotJoined 客户端不在广播队列中
+	ErrClientNotJoined = errors.New("lockstep: client not joined")
+	// ErrCommandRateLimited 客户端在当前帧添加的指令数量超出 WithCommandRateLimit 设定的上限
+	ErrCommandRateLimited = errors.New("lockstep: command rate limited")
+)
+
+// AddClientCommand 添加由特定客户端发起的指令到当前帧
+//   - 客户端需要在广播队列中，否则将返回 ErrClientNotJoined
+//   - 指令将依次经过 WithCommandRateLimit 设定的频率限制及 WithCommandValidator 设定的校验，未通过时将返回错误并触发 CommandViolationEvent
+//   - 被拒绝的指令不会被添加到当前帧，也不会占用频率限制的次数
+func (slf *Lockstep[ClientID, Command]) AddClientCommand(clientId ClientID, command Command) error {
+	slf.clientLock.RLock()
+	_, exist := slf.clients[clientId]
+	slf.clientLock.RUnlock()
+	if !exist {
+		return ErrClientNotJoined
+	}
+
+	if slf.commandRateLimited(clientId) {
+		slf.OnCommandViolationEvent(clientId, command, ErrCommandRateLimited)
+		return ErrCommandRateLimited
+	}
+
+	if slf.commandValidator != nil {
+		if err := slf.commandValidator(clientId, command); err != nil {
+			slf.OnCommandViolationEvent(clientId, command, err)
+			return err
+		}
+	}
+
+	slf.currentFrameLock.Lock()
+	if slf.commandRateLimit > 0 {
+		// 校验期间其他指令可能已占用剩余次数，需要再次检查
+		if slf.commandCounter[clientId] >= slf.commandRateLimit {
+			slf.currentFrameLock.Unlock()
+			slf.OnCommandViolationEvent(clientId, command, ErrCommandRateLimited)
+			return ErrCommandRateLimited
+		}
+		slf.commandCounter[clientId]++
+	}
+	slf.currentCommands = append(slf.currentCommands, command)
+	slf.currentFrameLock.Unlock()
+	return nil
+}
+
+// commandRateLimited 检查客户端在当前帧添加的指令数量是否已达到 WithCommandRateLimit 设定的上限
+func (slf *Lockstep[ClientID, Command]) commandRateLimited(clientId ClientID) bool {
+	if slf.commandRateLimit <= 0 {
+		return false
+	}
+	slf.currentFrameLock.RLock()
+	defer slf.currentFrameLock.RUnlock()
+	return slf.commandCounter[clientId] >= slf.commandRateLimit
+}
+
+// GetClientCommandCount 获取客户端在当前帧已添加的指令数量，仅在设置了 WithCommandRateLimit 时有效
+func (slf *Lockstep[ClientID, Command]) GetClientCommandCount(clientId ClientID) int {
+	slf.currentFrameLock.RLock()
+	defer slf.currentFrameLock.RUnlock()
+	return slf.commandCounter[clientId]
+}
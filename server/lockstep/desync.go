@@ -0,0 +1,113 @@
+package lockstep
+
+import "github.com/kercylan98/minotaur/utils/hash"
+
+// DefaultStateHashWindow 未通过 WithDesyncDetection 设置等待帧数时，状态哈希的最大等待帧数
+//   - 落后当前帧超过该帧数的帧仍未被所有客户端上报时，其状态哈希将被丢弃而不进行比较
+const DefaultStateHashWindow int64 = 600
+
+// ReportStateHash 由客户端上报特定帧的状态哈希，当所有客户端均已上报或超出 WithDesyncDetection 设定的等待帧数时将对该帧的状态哈希进行比较
+//   - 状态哈希与多数客户端不一致的客户端将被视为不同步，并触发 DesyncDetectedEvent
+//   - 当无法确定多数时（例如两个客户端的状态哈希不一致），所有客户端都将被视为不同步，但不会被移出广播队列
+//   - 不在广播队列中的客户端上报的状态哈希将被忽略，已经比较过的帧上报的状态哈希也将被忽略
+//   - 尚未广播的帧及落后当前帧超过等待帧数的帧上报的状态哈希将被忽略，等待帧数为 WithDesyncDetection 设置的 timeout，未设置时为 DefaultStateHashWindow
+func (slf *Lockstep[ClientID, Command]) ReportStateHash(clientId ClientID, frame int64, stateHash uint64) {
+	currentFrame := slf.GetCurrentFrame()
+	if frame >= currentFrame || currentFrame-frame > slf.stateHashWindow() {
+		return
+	}
+
+	slf.clientLock.RLock()
+	_, exist := slf.clients[clientId]
+	clientCount := len(slf.clients)
+	slf.clientLock.RUnlock()
+	if !exist {
+		return
+	}
+
+	slf.stateHashLock.Lock()
+	if frame <= slf.stateHashChecked {
+		slf.stateHashLock.Unlock()
+		return
+	}
+	hashes, exist := slf.stateHashes[frame]
+	if !exist {
+		hashes = make(map[ClientID]uint64)
+		slf.stateHashes[frame] = hashes
+	}
+	hashes[clientId] = stateHash
+	if len(hashes) < clientCount {
+		slf.stateHashLock.Unlock()
+		return
+	}
+	delete(slf.stateHashes, frame)
+	slf.stateHashLock.Unlock()
+
+	slf.compareStateHashes(frame, hashes)
+}
+
+// stateHashWindow 获取状态哈希的最大等待帧数
+func (slf *Lockstep[ClientID, Command]) stateHashWindow() int64 {
+	if slf.desyncTimeout > 0 {
+		return slf.desyncTimeout
+	}
+	return DefaultStateHashWindow
+}
+
+// checkStateHashTimeout 移除超出等待帧数的状态哈希，设置了 WithDesyncDetection 的 timeout 时将使用已上报的状态哈希进行比较
+func (slf *Lockstep[ClientID, Command]) checkStateHashTimeout(currentFrame int64) {
+	var window = slf.stateHashWindow()
+	var expired = make(map[int64]map[ClientID]uint64)
+	slf.stateHashLock.Lock()
+	for frame, hashes := range slf.stateHashes {
+		if currentFrame-frame > window {
+			if slf.desyncTimeout > 0 {
+				expired[frame] = hashes
+			}
+			delete(slf.stateHashes, frame)
+		}
+	}
+	slf.stateHashLock.Unlock()
+	for frame, hashes := range expired {
+		slf.compareStateHashes(frame, hashes)
+	}
+}
+
+// compareStateHashes 比较特定帧的状态哈希，并对不同步的客户端触发事件
+func (slf *Lockstep[ClientID, Command]) compareStateHashes(frame int64, hashes map[ClientID]uint64) {
+	slf.stateHashLock.Lock()
+	if frame > slf.stateHashChecked {
+		slf.stateHashChecked = frame
+	}
+	for f := range slf.stateHashes {
+		if f <= slf.stateHashChecked {
+			delete(slf.stateHashes, f)
+		}
+	}
+	slf.stateHashLock.Unlock()
+
+	var counter = make(map[uint64]int)
+	for _, h := range hashes {
+		counter[h]++
+	}
+	if len(counter) <= 1 {
+		return
+	}
+	var expected uint64
+	var majority bool
+	for h, count := range counter {
+		if count*2 > len(hashes) {
+			expected, majority = h, true
+			break
+		}
+	}
+	for _, clientId := range hash.KeyToSlice(hashes) {
+		if majority && hashes[clientId] == expected {
+			continue
+		}
+		slf.OnDesyncDetectedEvent(clientId, frame, hashes[clientId], expected)
+		if majority && slf.desyncKick {
+			slf.LeaveClient(clientId)
+		}
+	}
+}
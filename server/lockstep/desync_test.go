@@ -0,0 +1,44 @@
+package lockstep
+
+import "testing"
+
+type desyncCli struct {
+	id string
+}
+
+func (slf *desyncCli) GetID() string {
+	return slf.id
+}
+
+func (slf *desyncCli) Write(packet []byte, callback ...func(err error)) {}
+
+func TestLockstep_stateHashWindow(t *testing.T) {
+	for _, timeout := range []int64{0, 20} {
+		ls := NewLockstep[string, int](WithDesyncDetection[string, int](timeout, false))
+		ls.JoinClient(&desyncCli{id: "player_1"})
+		ls.JoinClient(&desyncCli{id: "player_2"})
+		window := ls.stateHashWindow()
+		ls.currentFrame = 10
+
+		// 尚未广播的帧上报的状态哈希将被忽略
+		ls.ReportStateHash("player_1", 10, 1)
+		ls.ReportStateHash("player_1", 1<<40, 1)
+		if count := len(ls.stateHashes); count != 0 {
+			t.Fatalf("timeout %d: expected future frames to be rejected, got %d frames", timeout, count)
+		}
+
+		// 个别客户端未上报的帧在超出等待帧数后将被移除
+		for frame := int64(0); frame < 10; frame++ {
+			ls.ReportStateHash("player_1", frame, 1)
+		}
+		ls.currentFrame = window + 5
+		ls.checkStateHashTimeout(ls.currentFrame)
+		if count := len(ls.stateHashes); count != 5 {
+			t.Fatalf("timeout %d: expected 5 frames within the window, got %d", timeout, count)
+		}
+		ls.ReportStateHash("player_1", 0, 1)
+		if _, exist := ls.stateHashes[0]; exist {
+			t.Fatalf("timeout %d: expected frames behind the window to be rejected", timeout)
+		}
+	}
+}
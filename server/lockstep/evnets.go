@@ -1,3 +1,13 @@
 package lockstep
 
 type StoppedEventHandle[ClientID comparable, Command any] func(lockstep *Lockstep[ClientID, Command])
+
+type ClientSendFailedEventHandle[ClientID comparable, Command any] func(lockstep *Lockstep[ClientID, Command], clientId ClientID)
+
+type FrameBroadcastEventHandle[ClientID comparable, Command any] func(lockstep *Lockstep[ClientID, Command], frame int64, commands []Command)
+
+type ClientCaughtUpEventHandle[ClientID comparable, Command any] func(lockstep *Lockstep[ClientID, Command], clientId ClientID)
+
+type DesyncDetectedEventHandle[ClientID comparable, Command any] func(lockstep *Lockstep[ClientID, Command], clientId ClientID, frame int64, stateHash, expected uint64)
+
+type CommandViolationEventHandle[ClientID comparable, Command any] func(lockstep *Lockstep[ClientID, Command], clientId ClientID, command Command, err error)
@@ -0,0 +1,211 @@
+package lockstep
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"github.com/klauspost/compress/zstd"
+	"sync/atomic"
+)
+
+const (
+	frameFlagCompressed byte = 1 << iota // 帧数据包标记：帧数据经过 zstd 压缩
+)
+
+const (
+	frameKindFull  byte = iota // 完整帧
+	frameKindDelta             // 基于上一帧的差量帧
+)
+
+// maxDecodedFramePacketSize 解压后帧数据包的最大长度，用于防止解压炸弹
+const maxDecodedFramePacketSize = 64 << 20
+
+var (
+	// ErrInvalidFramePacket 无效的帧数据包
+	ErrInvalidFramePacket = errors.New("lockstep: invalid frame packet")
+	// ErrFrameBaseMismatch 差量帧所依赖的帧与解码器最后解码的帧不一致，通常是由于丢失了数据包
+	ErrFrameBaseMismatch = errors.New("lockstep: frame base mismatch")
+)
+
+// frameEncoding 帧编码配置
+//   - 启用差量帧或批量压缩后，发送给客户端的数据包将被编码为帧数据包，需要通过 FrameDecoder 进行解码
+//   - 帧数据包格式为 | flags(1) | body |，当 flags 包含 frameFlagCompressed 时 body 经过 zstd 压缩
+//   - body 由若干帧组成，每一帧的格式为 | varint(frame) | kind(1) | data |
+//   - 完整帧的 data 为 | uvarint(len) | payload |，差量帧的 data 为 | varint(base) | uvarint(prefix) | uvarint(suffix) | uvarint(len) | middle |
+//   - 差量帧中的 base 为其所依赖的帧，客户端最后解码的帧与之不一致时将无法解码
+type frameEncoding struct {
+	elision   bool // 是否省略空帧
+	keepAlive int  // 连续省略空帧时每隔多少帧发送一次空帧
+
+	delta bool // 是否启用差量帧
+
+	batch       bool          // 是否将单次广播的多个帧合并为一个数据包
+	threshold   int           // 合并后的数据包超过该长度时进行压缩
+	zstdEncoder *zstd.Encoder // zstd 压缩器
+}
+
+// encoded 检查是否需要将帧编码为帧数据包
+func (slf *frameEncoding) encoded() bool {
+	return slf.delta || slf.batch
+}
+
+// frameEncodingState 客户端的帧编码状态
+type frameEncodingState struct {
+	base      []byte      // 最后一次发送给客户端的帧的序列化数据
+	baseFrame int64       // 最后一次发送给客户端的帧
+	empties   int         // 连续省略的空帧数量
+	resync    atomic.Bool // 是否发生了发送失败，此时客户端可能缺失差量帧所依赖的帧，下一帧需要作为完整帧发送
+}
+
+// encodeFrame 将帧编码后追加到 buf 中，并更新客户端的帧编码状态
+func (slf *frameEncoding) encodeFrame(buf []byte, state *frameEncodingState, frame int64, data []byte) []byte {
+	buf = binary.AppendVarint(buf, frame)
+	if state.resync.Swap(false) {
+		state.base = nil
+	}
+	var prefix, suffix int
+	if slf.delta && state.base != nil {
+		prefix, suffix = commonAffix(state.base, data)
+	}
+	if prefix+suffix > 3 {
+		middle := data[prefix : len(data)-suffix]
+		buf = append(buf, frameKindDelta)
+		buf = binary.AppendVarint(buf, state.baseFrame)
+		buf = binary.AppendUvarint(buf, uint64(prefix))
+		buf = binary.AppendUvarint(buf, uint64(suffix))
+		buf = binary.AppendUvarint(buf, uint64(len(middle)))
+		buf = append(buf, middle...)
+	} else {
+		buf = append(buf, frameKindFull)
+		buf = binary.AppendUvarint(buf, uint64(len(data)))
+		buf = append(buf, data...)
+	}
+	state.base, state.baseFrame = data, frame
+	return buf
+}
+
+// packet 将编码后的帧封装为帧数据包
+func (slf *frameEncoding) packet(body []byte) []byte {
+	if slf.zstdEncoder != nil && len(body) > slf.threshold {
+		compressed := slf.zstdEncoder.EncodeAll(body, []byte{frameFlagCompressed})
+		if len(compressed) < len(body)+1 {
+			return compressed
+		}
+	}
+	return append([]byte{0}, body...)
+}
+
+// commonAffix 获取两个字节切片的公共前缀及公共后缀的长度，前缀与后缀不会重叠
+func commonAffix(a, b []byte) (prefix, suffix int) {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for prefix < n && a[prefix] == b[prefix] {
+		prefix++
+	}
+	for suffix < n-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	return
+}
+
+// Frame 经过 FrameDecoder 解码的帧
+type Frame struct {
+	Index int64  // 帧索引
+	Data  []byte // 通过 WithSerialization 序列化后的帧数据
+}
+
+// NewFrameDecoder 创建帧数据包解码器，用于解码启用了 WithDeltaFrames 或 WithFrameBatchCompression 后发送给客户端的帧数据包
+//   - 差量帧依赖于上一帧的数据，因此每个客户端需要使用独立的解码器，并按照接收顺序进行解码
+//   - 当丢失了数据包时，后续的差量帧将返回 ErrFrameBaseMismatch，此时应当丢弃该数据包，服务端在发送失败后将发送完整帧，解码将从完整帧开始恢复
+//   - 适用于 Go 编写的客户端、机器人及测试，其他语言的客户端可参考帧数据包格式自行实现
+func NewFrameDecoder() *FrameDecoder {
+	return new(FrameDecoder)
+}
+
+// FrameDecoder 帧数据包解码器
+type FrameDecoder struct {
+	base        []byte
+	baseFrame   int64
+	zstdDecoder *zstd.Decoder
+}
+
+// Decode 解码帧数据包，返回数据包中包含的所有帧
+func (slf *FrameDecoder) Decode(packet []byte) ([]Frame, error) {
+	if len(packet) == 0 {
+		return nil, ErrInvalidFramePacket
+	}
+	flags, body := packet[0], packet[1:]
+	if flags&frameFlagCompressed != 0 {
+		if slf.zstdDecoder == nil {
+			decoder, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecodedFramePacketSize))
+			if err != nil {
+				return nil, err
+			}
+			slf.zstdDecoder = decoder
+		}
+		decoded, err := slf.zstdDecoder.DecodeAll(body, nil)
+		if err != nil {
+			return nil, err
+		}
+		body = decoded
+	}
+
+	var frames []Frame
+	var reader = bytes.NewReader(body)
+	for reader.Len() > 0 {
+		index, err := binary.ReadVarint(reader)
+		if err != nil {
+			return nil, ErrInvalidFramePacket
+		}
+		kind, err := reader.ReadByte()
+		if err != nil {
+			return nil, ErrInvalidFramePacket
+		}
+		var data []byte
+		switch kind {
+		case frameKindFull:
+			if data, err = readFrameBytes(reader); err != nil {
+				return nil, err
+			}
+		case frameKindDelta:
+			base, err := binary.ReadVarint(reader)
+			if err != nil {
+				return nil, ErrInvalidFramePacket
+			}
+			if slf.base == nil || base != slf.baseFrame {
+				return nil, ErrFrameBaseMismatch
+			}
+			prefix, err1 := binary.ReadUvarint(reader)
+			suffix, err2 := binary.ReadUvarint(reader)
+			if err1 != nil || err2 != nil || prefix+suffix > uint64(len(slf.base)) {
+				return nil, ErrInvalidFramePacket
+			}
+			middle, err := readFrameBytes(reader)
+			if err != nil {
+				return nil, err
+			}
+			data = make([]byte, 0, int(prefix)+len(middle)+int(suffix))
+			data = append(data, slf.base[:prefix]...)
+			data = append(data, middle...)
+			data = append(data, slf.base[uint64(len(slf.base))-suffix:]...)
+		default:
+			return nil, ErrInvalidFramePacket
+		}
+		slf.base, slf.baseFrame = data, index
+		frames = append(frames, Frame{Index: index, Data: data})
+	}
+	return frames, nil
+}
+
+// readFrameBytes 读取以 uvarint 作为长度前缀的字节切片
+func readFrameBytes(reader *bytes.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(reader)
+	if err != nil || length > uint64(reader.Len()) {
+		return nil, ErrInvalidFramePacket
+	}
+	data := make([]byte, length)
+	_, _ = reader.Read(data)
+	return data, nil
+}
@@ -3,6 +3,7 @@ package lockstep
 import (
 	"encoding/json"
 	"github.com/kercylan98/minotaur/utils/timer"
+	"sort"
 	"sync"
 	"time"
 )
@@ -21,9 +22,16 @@ func NewLockstep[ClientID comparable, Command any](options ...Option[ClientID, C
 			data, _ := json.Marshal(frameStruct)
 			return data
 		},
-		clients:     make(map[ClientID]Client[ClientID]),
-		clientFrame: make(map[ClientID]int64),
-		frameCache:  make(map[int64][]byte),
+		clients:        make(map[ClientID]Client[ClientID]),
+		clientFrame:    make(map[ClientID]int64),
+		catchingUp:     make(map[ClientID]struct{}),
+		frameCache:     make(map[int64][]byte),
+		emptyFrames:    make(map[int64]struct{}),
+		encodings:      make(map[ClientID]*frameEncodingState),
+		stateHashes:    make(map[int64]map[ClientID]uint64),
+		commandCounter: make(map[ClientID]int),
+		transport:      new(clientTransport[ClientID]),
+		sendFailures:   make(map[ClientID]int),
 	}
 	for _, option := range options {
 		option(lockstep)
@@ -35,8 +43,14 @@ func NewLockstep[ClientID comparable, Command any](options ...Option[ClientID, C
 //   - 支持最大帧上限 WithFrameLimit
 //   - 自定逻辑帧频率，默认为每秒15帧(帧/66ms) WithFrameRate
 //   - 自定帧序列化方式 WithSerialization
-//   - 从特定帧开始追帧
+//   - 每一帧广播后的回调 RegFrameBroadcastEvent，可用于记录回放或进行服务端状态计算
+//   - 从特定帧开始追帧，支持追帧速率 WithCatchUpRate 及追帧完成事件 RegClientCaughtUpEvent
 //   - 兼容各种基于TCP/UDP/Unix的网络类型，可通过客户端实现其他网络类型同步
+//   - 帧编码，支持空帧省略 WithEmptyFrameElision、差量帧 WithDeltaFrames 及批量压缩 WithFrameBatchCompression
+//   - 通过客户端上报的状态哈希检测不同步 ReportStateHash，支持自动移除不同步的客户端 WithDesyncDetection
+//   - 录制回放 ExportReplay，并可通过 NewReplayPlayer 重新广播或进行无头验证
+//   - 客户端指令校验 WithCommandValidator 及频率限制 WithCommandRateLimit，需通过 AddClientCommand 添加指令
+//   - 自定帧广播传输层 WithTransport，支持发送节流 WithSendPacing 及发送失败处理 WithMaxSendFailures，传输层实现 GroupTransport 时相同的帧将批量广播
 type Lockstep[ClientID comparable, Command any] struct {
 	running       bool                                         // 运行状态
 	runningLock   sync.RWMutex                                 // 运行状态锁
@@ -47,17 +61,52 @@ type Lockstep[ClientID comparable, Command any] struct {
 
 	clients     map[ClientID]Client[ClientID] // 接受广播的客户端
 	clientFrame map[ClientID]int64            // 客户端当前帧
+	catchingUp  map[ClientID]struct{}         // 正在追帧的客户端
+	catchUpRate int                           // 追帧时每次广播向单个客户端发送的最大帧数
 	clientLock  sync.RWMutex                  // 客户端锁
 
 	currentFrame     int64        // 当前主要帧
 	currentCommands  []Command    // 当前帧指令
 	currentFrameLock sync.RWMutex // 当前主要帧锁
 
-	frameCache     map[int64][]byte // 帧序列化缓存
-	frameCacheLock sync.RWMutex     // 帧序列化缓存锁
-	ticker         *timer.Ticker    // 定时器
-
-	lockstepStoppedEventHandles []StoppedEventHandle[ClientID, Command]
+	commandValidator func(clientId ClientID, command Command) error // 指令校验函数
+	commandRateLimit int                                            // 单个客户端每帧允许添加的最大指令数量
+	commandCounter   map[ClientID]int                               // 客户端在当前帧已添加的指令数量
+
+	frameCache     map[int64][]byte   // 帧序列化缓存
+	emptyFrames    map[int64]struct{} // 没有任何指令的帧
+	frameCacheLock sync.RWMutex       // 帧序列化缓存锁
+	ticker         *timer.Ticker      // 定时器
+
+	transport       Transport[ClientID] // 帧广播传输层
+	sendPacing      int                 // 每次广播向单个客户端发送的最大帧数
+	maxSendFailures int                 // 连续发送失败上限
+	sendFailures    map[ClientID]int    // 客户端连续发送失败次数
+	sendFailed      []ClientID          // 等待移除的发送失败客户端
+	sendFailureLock sync.Mutex          // 发送失败锁
+
+	encoding  frameEncoding                    // 帧编码配置
+	encodings map[ClientID]*frameEncodingState // 客户端的帧编码状态
+
+	stateHashes      map[int64]map[ClientID]uint64 // 客户端上报的状态哈希 [frame][clientId]
+	stateHashChecked int64                         // 最后一次比较状态哈希的帧
+	stateHashLock    sync.Mutex                    // 状态哈希锁
+	desyncTimeout    int64                         // 等待客户端上报状态哈希的帧数
+	desyncKick       bool                          // 是否将不同步的客户端移出广播队列
+
+	seed          int64                       // 随机种子
+	replayFrames  []ReplayFrame[Command]      // 回放记录中包含指令的帧
+	replayEnd     int64                       // 回放记录的结束帧（不包含）
+	replayMeta    map[string]string           // 回放记录的元数据
+	replayLock    sync.RWMutex                // 回放记录锁
+	commandSource func(frame int64) []Command // 帧指令来源，用于回放
+
+	lockstepStoppedEventHandles  []StoppedEventHandle[ClientID, Command]
+	clientSendFailedEventHandles []ClientSendFailedEventHandle[ClientID, Command]
+	frameBroadcastEventHandles   []FrameBroadcastEventHandle[ClientID, Command]
+	clientCaughtUpEventHandles   []ClientCaughtUpEventHandle[ClientID, Command]
+	desyncDetectedEventHandles   []DesyncDetectedEventHandle[ClientID, Command]
+	commandViolationEventHandles []CommandViolationEventHandle[ClientID, Command]
 }
 
 // JoinClient 将客户端加入到广播队列中，通常在开始广播前使用
@@ -66,25 +115,41 @@ func (slf *Lockstep[ClientID, Command]) JoinClient(client Client[ClientID]) {
 	slf.clientLock.Lock()
 	defer slf.clientLock.Unlock()
 	slf.clients[client.GetID()] = client
+	slf.encodings[client.GetID()] = new(frameEncodingState)
 }
 
 // JoinClientWithFrame 加入客户端到广播队列中，并从特定帧开始追帧
 //   - 可用于重连及状态同步、帧同步混用的情况
 //   - 混用：服务端记录指令时同时做一次状态计算，新客户端加入时直接同步当前状态，之后从特定帧开始广播
+//   - 当 frameIndex 落后于当前帧时，客户端将进入追帧状态，按照 WithCatchUpRate 设定的速率补发历史帧，追上当前帧后切换为实时广播并触发 ClientCaughtUpEvent
 func (slf *Lockstep[ClientID, Command]) JoinClientWithFrame(client Client[ClientID], frameIndex int64) {
 	slf.currentFrameLock.RLock()
-	if frameIndex > slf.currentFrame {
-		frameIndex = slf.currentFrame
-	}
+	currentFrame := slf.currentFrame
 	slf.currentFrameLock.RUnlock()
+	if frameIndex > currentFrame {
+		frameIndex = currentFrame
+	}
 	if frameIndex < slf.initFrame {
 		frameIndex = slf.initFrame
 	}
 	slf.clientLock.Lock()
 	slf.clients[client.GetID()] = client
 	slf.clientFrame[client.GetID()] = frameIndex
+	slf.encodings[client.GetID()] = new(frameEncodingState)
+	if frameIndex < currentFrame {
+		slf.catchingUp[client.GetID()] = struct{}{}
+	} else {
+		delete(slf.catchingUp, client.GetID())
+	}
 	slf.clientLock.Unlock()
+}
 
+// IsCatchingUp 检查客户端是否正在追帧
+func (slf *Lockstep[ClientID, Command]) IsCatchingUp(clientId ClientID) bool {
+	slf.clientLock.RLock()
+	defer slf.clientLock.RUnlock()
+	_, exist := slf.catchingUp[clientId]
+	return exist
 }
 
 // GetClientCount 获取客户端数量
@@ -101,6 +166,7 @@ func (slf *Lockstep[ClientID, Command]) DropCache(handler func(frame int64) bool
 	for frame, _ := range slf.frameCache {
 		if handler(frame) {
 			delete(slf.frameCache, frame)
+			delete(slf.emptyFrames, frame)
 		}
 	}
 }
@@ -111,6 +177,11 @@ func (slf *Lockstep[ClientID, Command]) LeaveClient(clientId ClientID) {
 	defer slf.clientLock.Unlock()
 	delete(slf.clients, clientId)
 	delete(slf.clientFrame, clientId)
+	delete(slf.catchingUp, clientId)
+	delete(slf.encodings, clientId)
+	slf.sendFailureLock.Lock()
+	delete(slf.sendFailures, clientId)
+	slf.sendFailureLock.Unlock()
 }
 
 // StartBroadcast 开始广播
@@ -125,46 +196,193 @@ func (slf *Lockstep[ClientID, Command]) StartBroadcast() {
 	slf.running = true
 	slf.runningLock.Unlock()
 	slf.currentFrame = slf.initFrame
+	slf.resetReplay()
+	slf.stateHashLock.Lock()
+	slf.stateHashChecked = slf.initFrame - 1
+	slf.stateHashLock.Unlock()
 
-	slf.ticker.Loop("lockstep", timer.Instantly, time.Second/time.Duration(slf.frameRate), timer.Forever, func() {
+	slf.ticker.Loop("lockstep", timer.Instantly, time.Second/time.Duration(slf.frameRate), timer.Forever, slf.tick)
+}
 
-		slf.currentFrameLock.RLock()
-		if slf.frameLimit > 0 && slf.currentFrame >= slf.frameLimit {
-			slf.currentFrameLock.RUnlock()
-			slf.StopBroadcast()
-			return
-		}
-		slf.currentFrameLock.RUnlock()
-		slf.currentFrameLock.Lock()
-		slf.currentFrame++
-		currentFrame := slf.currentFrame
-		currentCommands := slf.currentCommands
-		slf.currentCommands = make([]Command, 0, len(currentCommands))
+// tick 推进一帧并向客户端进行同步
+func (slf *Lockstep[ClientID, Command]) tick() {
+	slf.releaseSendFailedClients()
+
+	slf.currentFrameLock.Lock()
+	if slf.frameLimit > 0 && slf.currentFrame >= slf.frameLimit {
 		slf.currentFrameLock.Unlock()
+		slf.StopBroadcast()
+		return
+	}
+	slf.currentFrame++
+	currentFrame := slf.currentFrame
+	currentCommands := slf.currentCommands
+	slf.currentCommands = make([]Command, 0, len(currentCommands))
+	if len(slf.commandCounter) > 0 {
+		slf.commandCounter = make(map[ClientID]int)
+	}
+	slf.currentFrameLock.Unlock()
+	if slf.commandSource != nil {
+		currentCommands = append(append([]Command(nil), slf.commandSource(currentFrame-1)...), currentCommands...)
+	}
+	slf.record(currentFrame-1, currentCommands)
+
+	slf.checkStateHashTimeout(currentFrame)
 
-		slf.clientLock.RLock()
-		defer slf.clientLock.RUnlock()
-		slf.frameCacheLock.Lock()
-		defer slf.frameCacheLock.Unlock()
+	slf.clientLock.Lock()
+	slf.frameCacheLock.Lock()
+
+	// 当前帧的缓存始终需要生成，避免受发送节流影响的客户端在后续追帧时丢失指令
+	slf.frameCache[currentFrame-1] = slf.serialization(currentFrame-1, currentCommands)
+	if len(currentCommands) == 0 {
+		slf.emptyFrames[currentFrame-1] = struct{}{}
+	}
+
+	// 传输层支持批量广播且帧不需要按客户端编码时，相同的帧将合并为一次广播
+	var shared map[int64][]Client[ClientID]
+	group, isGroup := slf.transport.(GroupTransport[ClientID])
+	if isGroup && !slf.encoding.encoded() {
+		shared = make(map[int64][]Client[ClientID])
+	}
 
-		for clientId, client := range slf.clients {
-			var i = slf.clientFrame[clientId]
-			if i < slf.initFrame {
-				i = slf.initFrame
+	var caughtUp []ClientID
+	for clientId, client := range slf.clients {
+		var i = slf.clientFrame[clientId]
+		if i < slf.initFrame {
+			i = slf.initFrame
+		}
+		var end = currentFrame
+		var pacing = slf.sendPacing
+		_, catchingUp := slf.catchingUp[clientId]
+		if catchingUp && slf.catchUpRate > 0 {
+			pacing = slf.catchUpRate
+		}
+		if pacing > 0 && end-i > int64(pacing) {
+			end = i + int64(pacing)
+		}
+		slf.sendFrames(client, i, end, shared)
+		slf.clientFrame[clientId] = end
+		if catchingUp && end == currentFrame {
+			delete(slf.catchingUp, clientId)
+			caughtUp = append(caughtUp, clientId)
+		}
+	}
+	if len(shared) > 0 {
+		slf.broadcastFrames(group, shared)
+	}
+
+	slf.frameCacheLock.Unlock()
+	slf.clientLock.Unlock()
+
+	for _, clientId := range caughtUp {
+		slf.OnClientCaughtUpEvent(clientId)
+	}
+	slf.OnFrameBroadcastEvent(currentFrame-1, currentCommands)
+}
+
+// sendFrames 向客户端发送 [start, end) 范围内的帧，并根据帧编码配置进行空帧省略、差量编码及批量压缩（无锁）
+//   - shared 不为 nil 时，未经编码的帧将记录在 shared 中，由 broadcastFrames 统一进行广播
+func (slf *Lockstep[ClientID, Command]) sendFrames(client Client[ClientID], start, end int64, shared map[int64][]Client[ClientID]) {
+	state := slf.encodings[client.GetID()]
+	if state == nil {
+		state = new(frameEncodingState)
+		slf.encodings[client.GetID()] = state
+	}
+	var body []byte
+	for i := start; i < end; i++ {
+		cache, exist := slf.frameCache[i]
+		if !exist {
+			cache = slf.serialization(i, nil)
+			slf.frameCache[i] = cache
+			slf.emptyFrames[i] = struct{}{}
+		}
+		if _, empty := slf.emptyFrames[i]; empty && slf.encoding.elision {
+			state.empties++
+			if slf.encoding.keepAlive <= 0 || state.empties < slf.encoding.keepAlive {
+				continue
 			}
-			for ; i < currentFrame; i++ {
-				cache, exist := slf.frameCache[i]
-				if !exist {
-					cache = slf.serialization(i, currentCommands)
-					slf.frameCache[i] = cache
-				}
-				client.Write(cache)
+		}
+		state.empties = 0
+		if !slf.encoding.encoded() {
+			if shared != nil {
+				shared[i] = append(shared[i], client)
+			} else {
+				slf.send(client, cache)
 			}
-			slf.clientFrame[clientId] = currentFrame
+			continue
+		}
+		body = slf.encoding.encodeFrame(body, state, i, cache)
+		if !slf.encoding.batch {
+			slf.sendEncoded(client, state, slf.encoding.packet(body))
+			body = nil
 		}
+	}
+	if len(body) > 0 {
+		slf.sendEncoded(client, state, slf.encoding.packet(body))
+	}
+}
+
+// broadcastFrames 按照帧的顺序通过批量广播传输层发送 shared 中记录的帧（无锁）
+func (slf *Lockstep[ClientID, Command]) broadcastFrames(group GroupTransport[ClientID], shared map[int64][]Client[ClientID]) {
+	frames := make([]int64, 0, len(shared))
+	for frame := range shared {
+		frames = append(frames, frame)
+	}
+	sort.Slice(frames, func(i, j int) bool {
+		return frames[i] < frames[j]
+	})
+	for _, frame := range frames {
+		group.Broadcast(shared[frame], slf.frameCache[frame], func(client Client[ClientID], err error) {
+			slf.sendResult(client.GetID(), err)
+		})
+	}
+}
+
+// send 通过传输层向客户端发送数据包，并记录发送结果
+func (slf *Lockstep[ClientID, Command]) send(client Client[ClientID], packet []byte) {
+	clientId := client.GetID()
+	slf.transport.Send(client, packet, func(err error) {
+		slf.sendResult(clientId, err)
 	})
 }
 
+// sendEncoded 通过传输层向客户端发送帧数据包，发送失败时客户端的下一帧将作为完整帧发送
+func (slf *Lockstep[ClientID, Command]) sendEncoded(client Client[ClientID], state *frameEncodingState, packet []byte) {
+	clientId := client.GetID()
+	slf.transport.Send(client, packet, func(err error) {
+		if err != nil {
+			state.resync.Store(true)
+		}
+		slf.sendResult(clientId, err)
+	})
+}
+
+// sendResult 记录客户端的发送结果
+func (slf *Lockstep[ClientID, Command]) sendResult(clientId ClientID, err error) {
+	slf.sendFailureLock.Lock()
+	defer slf.sendFailureLock.Unlock()
+	if err == nil {
+		delete(slf.sendFailures, clientId)
+		return
+	}
+	slf.sendFailures[clientId]++
+	if slf.maxSendFailures > 0 && slf.sendFailures[clientId] == slf.maxSendFailures {
+		slf.sendFailed = append(slf.sendFailed, clientId)
+	}
+}
+
+// releaseSendFailedClients 移除连续发送失败次数达到上限的客户端
+func (slf *Lockstep[ClientID, Command]) releaseSendFailedClients() {
+	slf.sendFailureLock.Lock()
+	failed := slf.sendFailed
+	slf.sendFailed = nil
+	slf.sendFailureLock.Unlock()
+	for _, clientId := range failed {
+		slf.LeaveClient(clientId)
+		slf.OnClientSendFailedEvent(clientId)
+	}
+}
+
 // StopBroadcast 停止广播
 func (slf *Lockstep[ClientID, Command]) StopBroadcast() {
 	slf.runningLock.Lock()
@@ -187,9 +405,22 @@ func (slf *Lockstep[ClientID, Command]) StopBroadcast() {
 	defer slf.clientLock.Unlock()
 
 	slf.frameCache = make(map[int64][]byte)
+	slf.emptyFrames = make(map[int64]struct{})
 	slf.currentCommands = make([]Command, 0)
+	slf.commandCounter = make(map[ClientID]int)
 	slf.currentFrame = -1
 	slf.clientFrame = make(map[ClientID]int64)
+	slf.catchingUp = make(map[ClientID]struct{})
+	for clientId := range slf.encodings {
+		slf.encodings[clientId] = new(frameEncodingState)
+	}
+	slf.sendFailureLock.Lock()
+	slf.sendFailures = make(map[ClientID]int)
+	slf.sendFailed = nil
+	slf.sendFailureLock.Unlock()
+	slf.stateHashLock.Lock()
+	slf.stateHashes = make(map[int64]map[ClientID]uint64)
+	slf.stateHashLock.Unlock()
 }
 
 // IsRunning 是否正在广播
@@ -250,3 +481,61 @@ func (slf *Lockstep[ClientID, Command]) OnLockstepStoppedEvent() {
 		handle(slf)
 	}
 }
+
+// RegClientSendFailedEvent 当客户端连续发送失败次数达到 WithMaxSendFailures 设定的上限并被移出广播队列时将触发被注册的事件处理函数
+func (slf *Lockstep[ClientID, Command]) RegClientSendFailedEvent(handle ClientSendFailedEventHandle[ClientID, Command]) {
+	slf.clientSendFailedEventHandles = append(slf.clientSendFailedEventHandles, handle)
+}
+
+func (slf *Lockstep[ClientID, Command]) OnClientSendFailedEvent(clientId ClientID) {
+	for _, handle := range slf.clientSendFailedEventHandles {
+		handle(slf, clientId)
+	}
+}
+
+// RegFrameBroadcastEvent 当每一帧推进并向客户端广播后将触发被注册的事件处理函数
+//   - frame 为已结束的帧，commands 为该帧的所有指令
+func (slf *Lockstep[ClientID, Command]) RegFrameBroadcastEvent(handle FrameBroadcastEventHandle[ClientID, Command]) {
+	slf.frameBroadcastEventHandles = append(slf.frameBroadcastEventHandles, handle)
+}
+
+func (slf *Lockstep[ClientID, Command]) OnFrameBroadcastEvent(frame int64, commands []Command) {
+	for _, handle := range slf.frameBroadcastEventHandles {
+		handle(slf, frame, commands)
+	}
+}
+
+// RegClientCaughtUpEvent 当通过 JoinClientWithFrame 加入的客户端完成追帧并切换为实时广播时将触发被注册的事件处理函数
+func (slf *Lockstep[ClientID, Command]) RegClientCaughtUpEvent(handle ClientCaughtUpEventHandle[ClientID, Command]) {
+	slf.clientCaughtUpEventHandles = append(slf.clientCaughtUpEventHandles, handle)
+}
+
+func (slf *Lockstep[ClientID, Command]) OnClientCaughtUpEvent(clientId ClientID) {
+	for _, handle := range slf.clientCaughtUpEventHandles {
+		handle(slf, clientId)
+	}
+}
+
+// RegDesyncDetectedEvent 当客户端上报的状态哈希与其他客户端不一致时将触发被注册的事件处理函数
+//   - stateHash 为客户端上报的状态哈希，expected 为多数客户端的状态哈希，当无法确定多数时 expected 为 0
+func (slf *Lockstep[ClientID, Command]) RegDesyncDetectedEvent(handle DesyncDetectedEventHandle[ClientID, Command]) {
+	slf.desyncDetectedEventHandles = append(slf.desyncDetectedEventHandles, handle)
+}
+
+func (slf *Lockstep[ClientID, Command]) OnDesyncDetectedEvent(clientId ClientID, frame int64, stateHash, expected uint64) {
+	for _, handle := range slf.desyncDetectedEventHandles {
+		handle(slf, clientId, frame, stateHash, expected)
+	}
+}
+
+// RegCommandViolationEvent 当通过 AddClientCommand 添加的指令未通过频率限制或校验时将触发被注册的事件处理函数
+//   - err 为 ErrCommandRateLimited 或 WithCommandValidator 设定的校验函数返回的错误
+func (slf *Lockstep[ClientID, Command]) RegCommandViolationEvent(handle CommandViolationEventHandle[ClientID, Command]) {
+	slf.commandViolationEventHandles = append(slf.commandViolationEventHandles, handle)
+}
+
+func (slf *Lockstep[ClientID, Command]) OnCommandViolationEvent(clientId ClientID, command Command, err error) {
+	for _, handle := range slf.commandViolationEventHandles {
+		handle(slf, clientId, command, err)
+	}
+}
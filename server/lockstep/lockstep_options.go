@@ -1,12 +1,14 @@
 package lockstep
 
+import "github.com/klauspost/compress/zstd"
+
 type Option[ClientID comparable, Command any] func(lockstep *Lockstep[ClientID, Command])
 
 // WithFrameLimit 通过特定逻辑帧上限创建锁步（帧）同步组件
 //   - 当达到上限时将停止广播
 func WithFrameLimit[ClientID comparable, Command any](frameLimit int64) Option[ClientID, Command] {
 	return func(lockstep *Lockstep[ClientID, Command]) {
-		if frameLimit > 0 {
+		if frameLimit < 0 {
 			frameLimit = 0
 		}
 		lockstep.frameLimit = frameLimit
@@ -14,10 +16,12 @@ func WithFrameLimit[ClientID comparable, Command any](frameLimit int64) Option[C
 }
 
 // WithFrameRate 通过特定逻辑帧率创建锁步（帧）同步组件
-//   - 默认情况下为 15/s
+//   - 默认情况下为 15/s，小于等于 0 时将被忽略
 func WithFrameRate[ClientID comparable, Command any](frameRate int64) Option[ClientID, Command] {
 	return func(lockstep *Lockstep[ClientID, Command]) {
-		lockstep.frameRate = frameRate
+		if frameRate > 0 {
+			lockstep.frameRate = frameRate
+		}
 	}
 }
 
@@ -42,3 +46,112 @@ func WithInitFrame[ClientID comparable, Command any](initFrame int64) Option[Cli
 		lockstep.initFrame = initFrame
 	}
 }
+
+// WithTransport 通过特定的帧广播传输层创建锁步（帧）同步组件
+//   - 默认情况下将直接调用 Client.Write 进行发送
+//   - 可通过 NewConnTransport 使用基于 server.Conn 的传输层
+func WithTransport[ClientID comparable, Command any](transport Transport[ClientID]) Option[ClientID, Command] {
+	return func(lockstep *Lockstep[ClientID, Command]) {
+		if transport != nil {
+			lockstep.transport = transport
+		}
+	}
+}
+
+// WithSendPacing 通过限制每次广播向单个客户端发送的最大帧数创建锁步（帧）同步组件
+//   - 适用于追帧时避免一次性向客户端写入大量历史帧造成拥塞，超出部分将在后续广播中继续发送
+//   - 默认情况下为 0，即不限制
+func WithSendPacing[ClientID comparable, Command any](maxFramesPerTick int) Option[ClientID, Command] {
+	return func(lockstep *Lockstep[ClientID, Command]) {
+		lockstep.sendPacing = maxFramesPerTick
+	}
+}
+
+// WithMaxSendFailures 通过限制客户端连续发送失败次数创建锁步（帧）同步组件
+//   - 当客户端连续发送失败次数达到上限时，将在下一次广播前被移出广播队列，并触发 ClientSendFailedEvent
+//   - 默认情况下为 0，即不处理发送失败
+func WithMaxSendFailures[ClientID comparable, Command any](n int) Option[ClientID, Command] {
+	return func(lockstep *Lockstep[ClientID, Command]) {
+		lockstep.maxSendFailures = n
+	}
+}
+
+// WithCatchUpRate 通过限制追帧时每次广播向单个客户端发送的最大帧数创建锁步（帧）同步组件
+//   - 仅对通过 JoinClientWithFrame 加入且落后于当前帧的客户端生效，例如设置为 10 时客户端将以 10 倍速追帧，追上当前帧后切换为实时广播
+//   - 由于每次广播时当前帧也将推进一帧，framesPerTick 需要大于 1 客户端才能够追上当前帧
+//   - 追帧期间将替代 WithSendPacing 的限制
+//   - 默认情况下为 0，即一次性补发所有历史帧（受 WithSendPacing 限制）
+func WithCatchUpRate[ClientID comparable, Command any](framesPerTick int) Option[ClientID, Command] {
+	return func(lockstep *Lockstep[ClientID, Command]) {
+		lockstep.catchUpRate = framesPerTick
+	}
+}
+
+// WithEmptyFrameElision 通过省略空帧的方式创建锁步（帧）同步组件，没有任何指令的帧将不会发送给客户端，客户端可根据下一次收到的帧索引推进逻辑帧
+//   - keepAlive 大于 0 时，连续省略 keepAlive 个空帧后将发送一次空帧，用于使客户端能够在长时间没有指令时推进逻辑帧
+func WithEmptyFrameElision[ClientID comparable, Command any](keepAlive int) Option[ClientID, Command] {
+	return func(lockstep *Lockstep[ClientID, Command]) {
+		lockstep.encoding.elision = true
+		lockstep.encoding.keepAlive = keepAlive
+	}
+}
+
+// WithDeltaFrames 通过差量帧的方式创建锁步（帧）同步组件，每一帧将基于发送给该客户端的上一帧的序列化数据进行差量编码
+//   - 启用后发送给客户端的数据将被编码为帧数据包，客户端需要通过 FrameDecoder 或相同的格式进行解码
+//   - 向客户端发送失败后，该客户端的下一帧将作为完整帧发送
+func WithDeltaFrames[ClientID comparable, Command any]() Option[ClientID, Command] {
+	return func(lockstep *Lockstep[ClientID, Command]) {
+		lockstep.encoding.delta = true
+	}
+}
+
+// WithFrameBatchCompression 通过批量压缩的方式创建锁步（帧）同步组件，单次广播中发送给同一客户端的多个帧将被合并为一个帧数据包，长度超过 threshold 时将通过 zstd 进行压缩
+//   - 适用于追帧或长时间对局等需要发送大量帧的场景
+//   - 启用后发送给客户端的数据将被编码为帧数据包，客户端需要通过 FrameDecoder 或相同的格式进行解码
+func WithFrameBatchCompression[ClientID comparable, Command any](threshold int) Option[ClientID, Command] {
+	return func(lockstep *Lockstep[ClientID, Command]) {
+		encoder, err := zstd.NewWriter(nil)
+		if err != nil {
+			panic(err)
+		}
+		lockstep.encoding.batch = true
+		lockstep.encoding.threshold = threshold
+		lockstep.encoding.zstdEncoder = encoder
+	}
+}
+
+// WithDesyncDetection 通过特定的不同步检测策略创建锁步（帧）同步组件
+//   - timeout 大于 0 时，上报状态哈希的帧落后当前帧超过 timeout 帧后，将使用已上报的状态哈希进行比较，避免个别客户端不上报导致无法检测
+//   - kick 为 true 时，不同步的客户端将在触发 DesyncDetectedEvent 后被移出广播队列
+//   - 默认情况下仅在所有客户端均已上报时进行比较，且不会移除不同步的客户端，落后当前帧超过 DefaultStateHashWindow 帧仍未上报完整的状态哈希将被丢弃
+func WithDesyncDetection[ClientID comparable, Command any](timeout int64, kick bool) Option[ClientID, Command] {
+	return func(lockstep *Lockstep[ClientID, Command]) {
+		lockstep.desyncTimeout = timeout
+		lockstep.desyncKick = kick
+	}
+}
+
+// WithSeed 通过特定的随机种子创建锁步（帧）同步组件
+//   - 随机种子将被记录在回放记录中，通常应当同时下发给客户端，以便客户端及回放能够获得一致的随机结果
+func WithSeed[ClientID comparable, Command any](seed int64) Option[ClientID, Command] {
+	return func(lockstep *Lockstep[ClientID, Command]) {
+		lockstep.seed = seed
+	}
+}
+
+// WithCommandValidator 通过特定的指令校验函数创建锁步（帧）同步组件，用于拒绝格式错误或不可能出现的指令
+//   - 仅对通过 AddClientCommand 添加的指令生效，校验函数返回错误时指令将被拒绝
+func WithCommandValidator[ClientID comparable, Command any](validator func(clientId ClientID, command Command) error) Option[ClientID, Command] {
+	return func(lockstep *Lockstep[ClientID, Command]) {
+		lockstep.commandValidator = validator
+	}
+}
+
+// WithCommandRateLimit 通过限制单个客户端每帧允许添加的最大指令数量创建锁步（帧）同步组件，用于防止客户端通过大量指令进行攻击
+//   - 仅对通过 AddClientCommand 添加的指令生效，超出上限的指令将被拒绝
+//   - 默认情况下为 0，即不限制
+func WithCommandRateLimit[ClientID comparable, Command any](maxCommandsPerFrame int) Option[ClientID, Command] {
+	return func(lockstep *Lockstep[ClientID, Command]) {
+		lockstep.commandRateLimit = maxCommandsPerFrame
+	}
+}
@@ -1,10 +1,15 @@
 package lockstep_test
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/kercylan98/minotaur/server/lockstep"
 	"github.com/kercylan98/minotaur/utils/log"
 	"github.com/kercylan98/minotaur/utils/random"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -48,3 +53,569 @@ func TestNewLockstep(t *testing.T) {
 	time.Sleep(time.Second)
 	fmt.Println("end")
 }
+
+type failCli struct {
+	id string
+}
+
+func (slf *failCli) GetID() string {
+	return slf.id
+}
+
+func (slf *failCli) Write(packet []byte, callback ...func(err error)) {
+	if len(callback) > 0 {
+		callback[0](lockstep.ErrClientClosed)
+	}
+}
+
+func TestLockstep_MaxSendFailures(t *testing.T) {
+	ls := lockstep.NewLockstep[string, int](
+		lockstep.WithFrameRate[string, int](100),
+		lockstep.WithMaxSendFailures[string, int](3),
+	)
+	var failed = make(chan string, 1)
+	ls.RegClientSendFailedEvent(func(lockstep *lockstep.Lockstep[string, int], clientId string) {
+		failed <- clientId
+	})
+	ls.JoinClient(&Cli{id: "player_1"})
+	ls.JoinClient(&failCli{id: "player_2"})
+	ls.StartBroadcast()
+	defer ls.StopBroadcast()
+
+	select {
+	case id := <-failed:
+		if id != "player_2" {
+			t.Fatalf("unexpected failed client: %s", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("send failed event not fired")
+	}
+	if count := ls.GetClientCount(); count != 1 {
+		t.Fatalf("expected 1 client, got %d", count)
+	}
+}
+
+func TestLockstep_FrameLimit(t *testing.T) {
+	ls := lockstep.NewLockstep[string, int](
+		lockstep.WithFrameRate[string, int](100),
+		lockstep.WithFrameLimit[string, int](5),
+	)
+	var stopped = make(chan struct{}, 1)
+	ls.RegLockstepStoppedEvent(func(lockstep *lockstep.Lockstep[string, int]) {
+		stopped <- struct{}{}
+	})
+	ls.StartBroadcast()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("lockstep not stopped after reaching frame limit")
+	}
+	if ls.IsRunning() {
+		t.Fatal("lockstep is still running")
+	}
+}
+
+type recordCli struct {
+	id      string
+	packets chan string
+}
+
+func (slf *recordCli) GetID() string {
+	return slf.id
+}
+
+func (slf *recordCli) Write(packet []byte, callback ...func(err error)) {
+	slf.packets <- string(packet)
+	if len(callback) > 0 {
+		callback[0](nil)
+	}
+}
+
+func TestLockstep_SerializationAndFrameBroadcastEvent(t *testing.T) {
+	ls := lockstep.NewLockstep[string, int](
+		lockstep.WithFrameRate[string, int](100),
+		lockstep.WithSerialization[string, int](func(frame int64, commands []int) []byte {
+			return []byte(fmt.Sprintf("%d:%v", frame, commands))
+		}),
+	)
+	var frames = make(chan int64, 100)
+	ls.RegFrameBroadcastEvent(func(lockstep *lockstep.Lockstep[string, int], frame int64, commands []int) {
+		frames <- frame
+	})
+	cli := &recordCli{id: "player_1", packets: make(chan string, 100)}
+	ls.JoinClient(cli)
+	ls.AddCommands([]int{1, 2})
+	ls.StartBroadcast()
+	defer ls.StopBroadcast()
+
+	for i := int64(0); i < 3; i++ {
+		select {
+		case frame := <-frames:
+			if frame != i {
+				t.Fatalf("expected frame %d, got %d", i, frame)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("frame broadcast event not fired")
+		}
+	}
+	if packet := <-cli.packets; packet != "0:[1 2]" {
+		t.Fatalf("unexpected packet: %s", packet)
+	}
+	if packet := <-cli.packets; packet != "1:[]" {
+		t.Fatalf("unexpected packet: %s", packet)
+	}
+}
+
+func TestLockstep_CatchUp(t *testing.T) {
+	ls := lockstep.NewLockstep[string, int](
+		lockstep.WithFrameRate[string, int](100),
+		lockstep.WithCatchUpRate[string, int](5),
+	)
+	var caughtUp = make(chan string, 1)
+	ls.RegClientCaughtUpEvent(func(lockstep *lockstep.Lockstep[string, int], clientId string) {
+		caughtUp <- clientId
+	})
+	ls.StartBroadcast()
+	defer ls.StopBroadcast()
+	for ls.GetCurrentFrame() < 20 {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	cli := &recordCli{id: "player_1", packets: make(chan string, 1024)}
+	ls.JoinClientWithFrame(cli, 0)
+	if !ls.IsCatchingUp(cli.GetID()) {
+		t.Fatal("client should be catching up")
+	}
+
+	select {
+	case id := <-caughtUp:
+		if id != cli.GetID() {
+			t.Fatalf("unexpected caught up client: %s", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("client caught up event not fired")
+	}
+	if ls.IsCatchingUp(cli.GetID()) {
+		t.Fatal("client should not be catching up")
+	}
+	if frame, current := ls.GetClientCurrentFrame(cli.GetID()), ls.GetCurrentFrame(); current-frame > 1 {
+		t.Fatalf("client frame %d is behind current frame %d", frame, current)
+	}
+}
+
+type packetCli struct {
+	id      string
+	packets chan []byte
+}
+
+func (slf *packetCli) GetID() string {
+	return slf.id
+}
+
+func (slf *packetCli) Write(packet []byte, callback ...func(err error)) {
+	slf.packets <- packet
+	if len(callback) > 0 {
+		callback[0](nil)
+	}
+}
+
+func TestLockstep_FrameEncoding(t *testing.T) {
+	ls := lockstep.NewLockstep[string, int](
+		lockstep.WithFrameRate[string, int](100),
+		lockstep.WithEmptyFrameElision[string, int](0),
+		lockstep.WithDeltaFrames[string, int](),
+		lockstep.WithFrameBatchCompression[string, int](64),
+	)
+	ls.StartBroadcast()
+	for i := 0; i < 30; i++ {
+		ls.AddCommand(i)
+		time.Sleep(time.Millisecond * 5)
+	}
+	for ls.GetCurrentFrame() < 40 {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	cli := &packetCli{id: "player_1", packets: make(chan []byte, 1024)}
+	ls.JoinClientWithFrame(cli, 0)
+	time.Sleep(time.Millisecond * 50)
+	ls.StopBroadcast()
+
+	decoder := lockstep.NewFrameDecoder()
+	var commands []int
+	var last int64 = -1
+	for len(cli.packets) > 0 {
+		frames, err := decoder.Decode(<-cli.packets)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, frame := range frames {
+			if frame.Index <= last {
+				t.Fatalf("frame %d received after frame %d", frame.Index, last)
+			}
+			last = frame.Index
+			var f struct {
+				Frame    int64 `json:"frame"`
+				Commands []int `json:"commands"`
+			}
+			if err = json.Unmarshal(frame.Data, &f); err != nil {
+				t.Fatal(err)
+			}
+			if f.Frame != frame.Index || len(f.Commands) == 0 {
+				t.Fatalf("unexpected frame %d: %s", frame.Index, frame.Data)
+			}
+			commands = append(commands, f.Commands...)
+		}
+	}
+	if len(commands) != 30 {
+		t.Fatalf("expected 30 commands, got %d", len(commands))
+	}
+	for i, command := range commands {
+		if command != i {
+			t.Fatalf("expected command %d, got %d", i, command)
+		}
+	}
+}
+
+// dropTransport 丢弃特定次数的发送并以错误作为发送结果的传输层
+type dropTransport struct {
+	lock  sync.Mutex
+	sends int
+	drop  int
+}
+
+func (slf *dropTransport) Send(client lockstep.Client[string], packet []byte, callback func(err error)) {
+	slf.lock.Lock()
+	slf.sends++
+	drop := slf.sends == slf.drop
+	slf.lock.Unlock()
+	if drop {
+		callback(errors.New("drop"))
+		return
+	}
+	client.Write(packet, callback)
+}
+
+func TestLockstep_FrameEncodingSendFailure(t *testing.T) {
+	var frameData = func(frame int64) string {
+		return fmt.Sprintf("frame-%s-end", strings.Repeat("#", int(frame)))
+	}
+	ls := lockstep.NewLockstep[string, int](
+		lockstep.WithFrameRate[string, int](100),
+		lockstep.WithDeltaFrames[string, int](),
+		lockstep.WithTransport[string, int](&dropTransport{drop: 3}),
+		lockstep.WithSerialization[string, int](func(frame int64, commands []int) []byte {
+			return []byte(frameData(frame))
+		}),
+	)
+	cli := &packetCli{id: "player_1", packets: make(chan []byte, 1024)}
+	ls.JoinClient(cli)
+	ls.StartBroadcast()
+	for ls.GetCurrentFrame() < 10 {
+		time.Sleep(time.Millisecond * 5)
+	}
+	ls.StopBroadcast()
+
+	// 发送失败的帧将丢失，但之后的帧将作为完整帧发送，客户端能够继续正确解码
+	decoder := lockstep.NewFrameDecoder()
+	var packets [][]byte
+	var indexes []int64
+	for len(cli.packets) > 0 {
+		packet := <-cli.packets
+		packets = append(packets, packet)
+		frames, err := decoder.Decode(packet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, frame := range frames {
+			if string(frame.Data) != frameData(frame.Index) {
+				t.Fatalf("frame %d decoded to %s", frame.Index, frame.Data)
+			}
+			indexes = append(indexes, frame.Index)
+		}
+	}
+	if len(indexes) < 5 || indexes[0] != 0 || indexes[1] != 1 || indexes[2] != 3 {
+		t.Fatalf("expected frame 2 to be dropped, got %v", indexes)
+	}
+
+	// 丢失数据包而未能得知发送失败时，差量帧将无法通过解码
+	decoder = lockstep.NewFrameDecoder()
+	if _, err := decoder.Decode(packets[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decoder.Decode(packets[len(packets)-1]); !errors.Is(err, lockstep.ErrFrameBaseMismatch) {
+		t.Fatalf("expected %v, got %v", lockstep.ErrFrameBaseMismatch, err)
+	}
+}
+
+func TestLockstep_ReportStateHash(t *testing.T) {
+	ls := lockstep.NewLockstep[string, int](
+		lockstep.WithFrameRate[string, int](100),
+		lockstep.WithDesyncDetection[string, int](0, true),
+	)
+	var desync []string
+	ls.RegDesyncDetectedEvent(func(lockstep *lockstep.Lockstep[string, int], clientId string, frame int64, stateHash, expected uint64) {
+		if frame != 5 || stateHash != 2 || expected != 1 {
+			t.Fatalf("unexpected desync: %s %d %d %d", clientId, frame, stateHash, expected)
+		}
+		desync = append(desync, clientId)
+	})
+	for _, id := range []string{"player_1", "player_2", "player_3"} {
+		ls.JoinClient(&recordCli{id: id, packets: make(chan string, 1024)})
+	}
+	ls.StartBroadcast()
+	defer ls.StopBroadcast()
+	for ls.GetCurrentFrame() <= 5 {
+		time.Sleep(time.Millisecond * 5)
+	}
+
+	ls.ReportStateHash("player_1", 5, 1)
+	ls.ReportStateHash("player_2", 5, 2)
+	if len(desync) != 0 {
+		t.Fatal("desync detected before all clients reported")
+	}
+	ls.ReportStateHash("player_3", 5, 1)
+	if len(desync) != 1 || desync[0] != "player_2" {
+		t.Fatalf("unexpected desync clients: %v", desync)
+	}
+	if count := ls.GetClientCount(); count != 2 {
+		t.Fatalf("expected 2 clients, got %d", count)
+	}
+
+	ls.ReportStateHash("player_1", 4, 3)
+	ls.ReportStateHash("player_3", 4, 4)
+	if len(desync) != 1 {
+		t.Fatal("state hash of checked frame should be ignored")
+	}
+}
+
+func TestLockstep_Replay(t *testing.T) {
+	ls := lockstep.NewLockstep[string, int](
+		lockstep.WithFrameRate[string, int](100),
+		lockstep.WithSeed[string, int](42),
+	)
+	ls.SetReplayMeta("match", "1")
+	ls.StartBroadcast()
+	for i := 0; i < 10; i++ {
+		ls.AddCommand(i)
+		time.Sleep(time.Millisecond * 15)
+	}
+	time.Sleep(time.Millisecond * 30)
+	ls.StopBroadcast()
+
+	data, err := ls.ExportReplay()
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay, err := lockstep.ImportReplay[int](data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replay.Seed != 42 || replay.Meta["match"] != "1" || replay.EndFrame <= replay.InitFrame {
+		t.Fatalf("unexpected replay: %+v", replay)
+	}
+
+	player := lockstep.NewReplayPlayer[string, int](replay)
+	var commands []int
+	var frames int64
+	player.Run(func(frame int64, c []int) {
+		frames++
+		commands = append(commands, c...)
+	})
+	if frames != replay.EndFrame-replay.InitFrame || len(commands) != 10 {
+		t.Fatalf("unexpected headless run: %d frames, %v", frames, commands)
+	}
+
+	var stopped = make(chan struct{}, 1)
+	player.RegLockstepStoppedEvent(func(lockstep *lockstep.Lockstep[string, int]) {
+		stopped <- struct{}{}
+	})
+	cli := &recordCli{id: "spectator", packets: make(chan string, 1024)}
+	player.JoinClient(cli)
+	player.StartBroadcast()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second * 3):
+		t.Fatal("replay player not stopped")
+	}
+	if count := len(cli.packets); int64(count) != frames {
+		t.Fatalf("expected %d frames, got %d", frames, count)
+	}
+}
+
+func TestLockstep_AddClientCommand(t *testing.T) {
+	var errIllegal = errors.New("illegal command")
+	ls := lockstep.NewLockstep[string, int](
+		lockstep.WithCommandRateLimit[string, int](2),
+		lockstep.WithCommandValidator[string, int](func(clientId string, command int) error {
+			if command < 0 {
+				return errIllegal
+			}
+			return nil
+		}),
+	)
+	var violations []error
+	ls.RegCommandViolationEvent(func(lockstep *lockstep.Lockstep[string, int], clientId string, command int, err error) {
+		violations = append(violations, err)
+	})
+	ls.JoinClient(&Cli{id: "player_1"})
+
+	if err := ls.AddClientCommand("player_2", 1); err != lockstep.ErrClientNotJoined {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ls.AddClientCommand("player_1", -1); err != errIllegal {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := ls.AddClientCommand("player_1", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ls.AddClientCommand("player_1", 3); err != lockstep.ErrCommandRateLimited {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(violations) != 2 || len(ls.GetCurrentCommands()) != 2 {
+		t.Fatalf("unexpected violations %v or commands %v", violations, ls.GetCurrentCommands())
+	}
+}
+
+func TestLockstep_AddClientCommandRateLimitBeforeValidator(t *testing.T) {
+	var validated int
+	ls := lockstep.NewLockstep[string, int](
+		lockstep.WithCommandRateLimit[string, int](1),
+		lockstep.WithCommandValidator[string, int](func(clientId string, command int) error {
+			validated++
+			return nil
+		}),
+	)
+	ls.JoinClient(&Cli{id: "player_1"})
+
+	if err := ls.AddClientCommand("player_1", 1); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := ls.AddClientCommand("player_1", 2); err != lockstep.ErrCommandRateLimited {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if validated != 1 {
+		t.Fatalf("expected validator to be called once, got %d", validated)
+	}
+}
+
+type timedCli struct {
+	id     string
+	lock   sync.Mutex
+	writes []time.Time
+}
+
+func (slf *timedCli) GetID() string {
+	return slf.id
+}
+
+func (slf *timedCli) Write(packet []byte, callback ...func(err error)) {
+	slf.lock.Lock()
+	slf.writes = append(slf.writes, time.Now())
+	slf.lock.Unlock()
+	if len(callback) > 0 {
+		callback[0](nil)
+	}
+}
+
+func TestLockstep_SendPacing(t *testing.T) {
+	const frameRate, pacing = 20, 2
+	interval := time.Second / frameRate
+	ls := lockstep.NewLockstep[string, int](
+		lockstep.WithFrameRate[string, int](frameRate),
+		lockstep.WithSendPacing[string, int](pacing),
+	)
+	ls.StartBroadcast()
+	for ls.GetCurrentFrame() < 10 {
+		time.Sleep(time.Millisecond * 5)
+	}
+	cli := &timedCli{id: "player_1"}
+	ls.JoinClientWithFrame(cli, 0)
+	time.Sleep(interval * 5)
+	ls.StopBroadcast()
+
+	cli.lock.Lock()
+	defer cli.lock.Unlock()
+	if len(cli.writes) < pacing*3 {
+		t.Fatalf("expected at least %d writes, got %d", pacing*3, len(cli.writes))
+	}
+	// 每次广播至多写入 pacing 帧，同一次广播内的写入是连续的，不同广播之间的写入间隔接近于帧间隔
+	var burst = 1
+	for i := 1; i < len(cli.writes); i++ {
+		if gap := cli.writes[i].Sub(cli.writes[i-1]); gap < interval/2 {
+			burst++
+			if burst > pacing {
+				t.Fatalf("more than %d writes within one tick: %v", pacing, cli.writes)
+			}
+		} else {
+			burst = 1
+		}
+	}
+}
+
+type groupTransport struct {
+	lock       sync.Mutex
+	broadcasts map[string][]string // packet -> clients
+	sends      int
+}
+
+func (slf *groupTransport) Send(client lockstep.Client[string], packet []byte, callback func(err error)) {
+	slf.lock.Lock()
+	slf.sends++
+	slf.lock.Unlock()
+	client.Write(packet, callback)
+}
+
+func (slf *groupTransport) Broadcast(clients []lockstep.Client[string], packet []byte, callback func(client lockstep.Client[string], err error)) {
+	slf.lock.Lock()
+	for _, client := range clients {
+		slf.broadcasts[string(packet)] = append(slf.broadcasts[string(packet)], client.GetID())
+	}
+	slf.lock.Unlock()
+	for _, client := range clients {
+		client := client
+		client.Write(packet, func(err error) {
+			callback(client, err)
+		})
+	}
+}
+
+func TestLockstep_GroupTransport(t *testing.T) {
+	transport := &groupTransport{broadcasts: make(map[string][]string)}
+	ls := lockstep.NewLockstep[string, int](
+		lockstep.WithFrameRate[string, int](100),
+		lockstep.WithTransport[string, int](transport),
+		lockstep.WithSerialization[string, int](func(frame int64, commands []int) []byte {
+			return []byte(strconv.FormatInt(frame, 10))
+		}),
+	)
+	cli1 := &recordCli{id: "player_1", packets: make(chan string, 1024)}
+	cli2 := &recordCli{id: "player_2", packets: make(chan string, 1024)}
+	ls.JoinClient(cli1)
+	ls.JoinClient(cli2)
+	ls.StartBroadcast()
+	for ls.GetCurrentFrame() < 5 {
+		time.Sleep(time.Millisecond * 5)
+	}
+	ls.StopBroadcast()
+
+	transport.lock.Lock()
+	defer transport.lock.Unlock()
+	if transport.sends != 0 {
+		t.Fatalf("expected frames to be broadcast, got %d single sends", transport.sends)
+	}
+	if clients := transport.broadcasts["0"]; len(clients) != 2 {
+		t.Fatalf("expected frame 0 to be broadcast to 2 clients, got %v", clients)
+	}
+	for _, cli := range []*recordCli{cli1, cli2} {
+		for i := 0; i < 5; i++ {
+			if packet := <-cli.packets; packet != strconv.Itoa(i) {
+				t.Fatalf("%s: expected frame %d, got %s", cli.id, i, packet)
+			}
+		}
+	}
+}
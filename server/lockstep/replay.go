@@ -0,0 +1,149 @@
+package lockstep
+
+import (
+	"encoding/json"
+	"github.com/kercylan98/minotaur/utils/hash"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Replay 锁步（帧）同步的回放记录，可通过 Lockstep.ExportReplay 导出，通过 ImportReplay 导入
+//   - 仅记录包含指令的帧，没有指令的帧在回放时将被视为空帧
+type Replay[Command any] struct {
+	Seed      int64                  `json:"seed"`           // 通过 WithSeed 设置的随机种子
+	FrameRate int64                  `json:"frameRate"`      // 录制时的帧率
+	InitFrame int64                  `json:"initFrame"`      // 初始帧
+	EndFrame  int64                  `json:"endFrame"`       // 结束帧（不包含）
+	Meta      map[string]string      `json:"meta,omitempty"` // 通过 Lockstep.SetReplayMeta 设置的元数据
+	Frames    []ReplayFrame[Command] `json:"frames"`         // 包含指令的帧
+}
+
+// ReplayFrame 回放记录中的帧
+type ReplayFrame[Command any] struct {
+	Frame    int64     `json:"f"` // 帧索引
+	Commands []Command `json:"c"` // 帧指令
+}
+
+// ImportReplay 导入通过 Lockstep.ExportReplay 导出的回放记录
+func ImportReplay[Command any](data []byte) (*Replay[Command], error) {
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecodedFramePacketSize))
+	if err != nil {
+		return nil, err
+	}
+	defer decoder.Close()
+	if data, err = decoder.DecodeAll(data, nil); err != nil {
+		return nil, err
+	}
+	var replay = new(Replay[Command])
+	if err = json.Unmarshal(data, replay); err != nil {
+		return nil, err
+	}
+	return replay, nil
+}
+
+// ExportReplay 导出当前的回放记录，回放记录将被序列化为 JSON 并通过 zstd 进行压缩
+//   - 回放记录从最近一次 StartBroadcast 开始录制，停止广播后仍然可以导出，直到下一次开始广播
+//   - 帧指令需要能够被 JSON 序列化
+func (slf *Lockstep[ClientID, Command]) ExportReplay() ([]byte, error) {
+	data, err := json.Marshal(slf.GetReplay())
+	if err != nil {
+		return nil, err
+	}
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer encoder.Close()
+	return encoder.EncodeAll(data, nil), nil
+}
+
+// GetReplay 获取当前的回放记录
+func (slf *Lockstep[ClientID, Command]) GetReplay() *Replay[Command] {
+	slf.replayLock.RLock()
+	defer slf.replayLock.RUnlock()
+	return &Replay[Command]{
+		Seed:      slf.seed,
+		FrameRate: slf.frameRate,
+		InitFrame: slf.initFrame,
+		EndFrame:  slf.replayEnd,
+		Meta:      hash.Copy(slf.replayMeta),
+		Frames:    append([]ReplayFrame[Command](nil), slf.replayFrames...),
+	}
+}
+
+// SetReplayMeta 设置回放记录的元数据，例如对局 ID、版本号、参与的玩家等
+func (slf *Lockstep[ClientID, Command]) SetReplayMeta(key, value string) {
+	slf.replayLock.Lock()
+	defer slf.replayLock.Unlock()
+	if slf.replayMeta == nil {
+		slf.replayMeta = make(map[string]string)
+	}
+	slf.replayMeta[key] = value
+}
+
+// GetSeed 获取通过 WithSeed 设置的随机种子
+func (slf *Lockstep[ClientID, Command]) GetSeed() int64 {
+	return slf.seed
+}
+
+// record 记录帧指令
+func (slf *Lockstep[ClientID, Command]) record(frame int64, commands []Command) {
+	slf.replayLock.Lock()
+	defer slf.replayLock.Unlock()
+	if len(commands) > 0 {
+		slf.replayFrames = append(slf.replayFrames, ReplayFrame[Command]{Frame: frame, Commands: commands})
+	}
+	slf.replayEnd = frame + 1
+}
+
+// resetReplay 清空回放记录
+func (slf *Lockstep[ClientID, Command]) resetReplay() {
+	slf.replayLock.Lock()
+	defer slf.replayLock.Unlock()
+	slf.replayFrames = nil
+	slf.replayEnd = slf.initFrame
+}
+
+// NewReplayPlayer 创建回放播放器，回放播放器基于 Lockstep 按照回放记录中的帧指令重新进行广播，可用于观战或回放
+//   - 帧率、初始帧、随机种子及帧上限将使用回放记录中的值，可通过 options 覆盖帧率以实现倍速播放
+//   - 可通过 JoinClient 加入观战者，通过 JoinClientWithFrame 从特定帧开始观看，通过 StartBroadcast 开始播放
+//   - 播放过程中通过 AddCommand 添加的指令将被追加到回放记录中的帧指令之后
+func NewReplayPlayer[ClientID comparable, Command any](replay *Replay[Command], options ...Option[ClientID, Command]) *ReplayPlayer[ClientID, Command] {
+	player := &ReplayPlayer[ClientID, Command]{
+		replay: replay,
+		frames: make(map[int64][]Command, len(replay.Frames)),
+	}
+	for _, frame := range replay.Frames {
+		player.frames[frame.Frame] = frame.Commands
+	}
+	player.Lockstep = NewLockstep[ClientID, Command](append([]Option[ClientID, Command]{
+		WithFrameRate[ClientID, Command](replay.FrameRate),
+		WithInitFrame[ClientID, Command](replay.InitFrame),
+		WithSeed[ClientID, Command](replay.Seed),
+	}, options...)...)
+	player.Lockstep.frameLimit = replay.EndFrame
+	player.Lockstep.commandSource = player.GetCommands
+	for key, value := range replay.Meta {
+		player.Lockstep.SetReplayMeta(key, value)
+	}
+	return player
+}
+
+// ReplayPlayer 回放播放器
+type ReplayPlayer[ClientID comparable, Command any] struct {
+	*Lockstep[ClientID, Command]
+	replay *Replay[Command]
+	frames map[int64][]Command
+}
+
+// GetCommands 获取回放记录中特定帧的指令
+func (slf *ReplayPlayer[ClientID, Command]) GetCommands(frame int64) []Command {
+	return slf.frames[frame]
+}
+
+// Run 以无头模式立即按顺序执行回放记录中的所有帧，不会进行广播，可用于在服务端对回放进行验证
+//   - handler 将按照帧顺序被调用，包括没有指令的空帧
+func (slf *ReplayPlayer[ClientID, Command]) Run(handler func(frame int64, commands []Command)) {
+	for frame := slf.replay.InitFrame; frame < slf.replay.EndFrame; frame++ {
+		handler(frame, slf.frames[frame])
+	}
+}
@@ -0,0 +1,65 @@
+package lockstep
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/server"
+)
+
+// ErrClientClosed 客户端连接已关闭
+var ErrClientClosed = errors.New("lockstep: client connection closed")
+
+// Transport 帧广播传输层，负责将序列化后的帧数据发送至客户端
+//   - 发送结果需通过 callback 进行回调，callback 允许异步调用
+type Transport[ClientID comparable] interface {
+	// Send 向客户端发送数据包
+	Send(client Client[ClientID], packet []byte, callback func(err error))
+}
+
+// GroupTransport 支持批量广播的帧广播传输层
+//   - 当未启用差量帧及批量压缩时，单次广播中需要向多个客户端发送的相同帧将通过 Broadcast 一次性发送，而不是逐个调用 Send
+//   - 对于同一客户端，Broadcast 的调用顺序与帧的顺序一致
+type GroupTransport[ClientID comparable] interface {
+	Transport[ClientID]
+	// Broadcast 向多个客户端发送相同的数据包，每个客户端的发送结果需通过 callback 进行回调
+	Broadcast(clients []Client[ClientID], packet []byte, callback func(client Client[ClientID], err error))
+}
+
+// clientTransport 默认传输层，直接调用 Client.Write 进行发送
+type clientTransport[ClientID comparable] struct{}
+
+func (slf *clientTransport[ClientID]) Send(client Client[ClientID], packet []byte, callback func(err error)) {
+	client.Write(packet, callback)
+}
+
+// NewConnTransport 创建一个基于 server.Conn 的帧广播传输层
+//   - *server.Conn 本身即实现了 Client[string]，可直接通过 JoinClient 加入广播队列
+//   - 当连接已关闭时将不会进行写入，而是以 ErrClientClosed 作为发送结果，配合 WithMaxSendFailures 可自动移除已断开的客户端
+//   - 对于 Websocket 连接，需要在加入广播队列前通过 Conn.SetWST 设置消息类型
+//   - 该传输层实现了 GroupTransport，相同的帧将作为同一个数据包广播给一组连接，不会为每个连接单独序列化
+func NewConnTransport() GroupTransport[string] {
+	return new(connTransport)
+}
+
+type connTransport struct{}
+
+func (slf *connTransport) Send(client Client[string], packet []byte, callback func(err error)) {
+	conn, ok := client.(*server.Conn)
+	if !ok {
+		client.Write(packet, callback)
+		return
+	}
+	if conn.IsClosed() {
+		callback(ErrClientClosed)
+		return
+	}
+	conn.Write(packet, callback)
+}
+
+func (slf *connTransport) Broadcast(clients []Client[string], packet []byte, callback func(client Client[string], err error)) {
+	for _, client := range clients {
+		client := client
+		slf.Send(client, packet, func(err error) {
+			callback(client, err)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/utils/log"
+)
+
+// WithLogger 通过特定的日志记录器创建服务器，服务器内部的日志都将通过该记录器输出
+//   - 默认使用 log 包的全局日志记录器，可通过 log.SetLogger 进行设置
+//   - *zap.Logger 可直接作为日志记录器使用，slog 可通过 log.NewSlog 进行适配，其他日志库可通过实现 log.Logger 接口进行适配
+//   - 与连接及消息相关的日志将携带 ConnID 及 MessageType 字段
+func WithLogger(logger log.Logger) Option {
+	return func(srv *Server) {
+		srv.logger = logger
+	}
+}
+
+// Logger 获取服务器使用的日志记录器，未通过 WithLogger 设置时将返回 log 包的全局日志记录器
+func (slf *Server) Logger() log.Logger {
+	if slf.logger != nil {
+		return slf.logger
+	}
+	return log.GetLogger()
+}
@@ -0,0 +1,42 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"github.com/kercylan98/minotaur/utils/log"
+	"sync"
+	"testing"
+)
+
+type recordLogger struct {
+	lock     sync.Mutex
+	messages []string
+}
+
+func (slf *recordLogger) record(msg string) {
+	slf.lock.Lock()
+	slf.messages = append(slf.messages, msg)
+	slf.lock.Unlock()
+}
+
+func (slf *recordLogger) Debug(msg string, fields ...log.Field)  { slf.record(msg) }
+func (slf *recordLogger) Info(msg string, fields ...log.Field)   { slf.record(msg) }
+func (slf *recordLogger) Warn(msg string, fields ...log.Field)   { slf.record(msg) }
+func (slf *recordLogger) Error(msg string, fields ...log.Field)  { slf.record(msg) }
+func (slf *recordLogger) DPanic(msg string, fields ...log.Field) { slf.record(msg) }
+func (slf *recordLogger) Panic(msg string, fields ...log.Field)  { slf.record(msg) }
+func (slf *recordLogger) Fatal(msg string, fields ...log.Field)  { slf.record(msg) }
+
+func TestWithLogger(t *testing.T) {
+	logger := new(recordLogger)
+	srv := server.New(server.NetworkNone, server.WithLogger(logger))
+	if srv.Logger() != logger {
+		t.Fatal("expected the logger set by WithLogger")
+	}
+	srv.RegStopEvent(func(srv *server.Server) {})
+	if len(logger.messages) != 1 {
+		t.Fatalf("expected the registration to be logged by the custom logger, got %v", logger.messages)
+	}
+	if server.New(server.NetworkNone).Logger() != log.GetLogger() {
+		t.Fatal("expected the global logger by default")
+	}
+}
@@ -0,0 +1,78 @@
+package server_test
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithConnMailbox(t *testing.T) {
+	const packets = 100
+	srv := server.New(server.NetworkTcp, server.WithCodec(server.NewLineCodec(0)), server.WithConnMailbox())
+	var release = make(chan struct{})
+	var pinged = make(chan struct{})
+	var disorder atomic.Bool
+	var done = make(chan struct{})
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		switch string(packet) {
+		case "block":
+			select {
+			case <-release:
+			case <-time.After(5 * time.Second):
+			}
+			return
+		case "ping":
+			close(pinged)
+			return
+		}
+		seq, _ := strconv.Atoi(string(packet))
+		last, _ := conn.GetData("seq").(int)
+		if seq != last+1 {
+			disorder.Store(true)
+		}
+		conn.SetData("seq", seq)
+		if seq == packets {
+			close(done)
+		}
+	})
+	var addr = freeAddr(t, "tcp")
+	runServer(t, srv, addr)
+	defer srv.Shutdown()
+
+	dial := func() net.Conn {
+		conn, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conn
+	}
+	blocked, other := dial(), dial()
+	defer blocked.Close()
+	defer other.Close()
+
+	// 阻塞其中一个连接的邮箱，另一个连接的消息仍应被处理
+	_, _ = blocked.Write([]byte("block\n"))
+	for i := 1; i <= packets; i++ {
+		_, _ = fmt.Fprintf(blocked, "%d\n", i)
+	}
+	_, _ = other.Write([]byte("ping\n"))
+	select {
+	case <-pinged:
+	case <-time.After(3 * time.Second):
+		t.Fatal("packet of another connection was blocked by a busy mailbox")
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued packets were not handled after the mailbox was released")
+	}
+	if disorder.Load() {
+		t.Fatal("packets of the same connection were handled out of order")
+	}
+}
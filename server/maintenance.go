@@ -0,0 +1,52 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/utils/log"
+)
+
+// maintenance 维护模式
+type maintenance struct {
+	whitelist func(conn *Conn) bool
+}
+
+// EnterMaintenance 使服务器进入维护模式，所有不在白名单中的连接将被断开
+//   - whitelist 返回 true 的连接将被保留，例如仅允许 GM 账号连接，为空时将使用 WithMaintenance 设置的默认白名单，均为空时将断开所有连接
+//   - 已建立的连接将立即进行检查，新建立的连接将在 ConnectionOpenedEvent 执行完毕后进行检查，因此可在其中完成鉴权后再由白名单进行判断
+//   - 连接断开前将发送 WithMaintenance 设置的数据包，随后以 CloseReasonMaintenance 关闭连接
+//   - 重复调用将替换当前的白名单并重新检查所有连接
+func (slf *Server) EnterMaintenance(whitelist func(conn *Conn) bool) {
+	if whitelist == nil {
+		whitelist = slf.maintenanceWhitelist
+	}
+	slf.maintenance.Store(&maintenance{whitelist: whitelist})
+	slf.Logger().Info("Server", log.String("Maintenance", "enter"))
+	slf.PushSystemMessage(func() {
+		slf.RangeConn(func(conn *Conn) bool {
+			slf.checkMaintenance(conn)
+			return true
+		})
+	}, log.String("Maintenance", "enter"))
+}
+
+// ExitMaintenance 使服务器退出维护模式
+func (slf *Server) ExitMaintenance() {
+	if slf.maintenance.Swap(nil) != nil {
+		slf.Logger().Info("Server", log.String("Maintenance", "exit"))
+	}
+}
+
+// IsMaintenance 服务器是否处于维护模式
+func (slf *Server) IsMaintenance() bool {
+	return slf.maintenance.Load() != nil
+}
+
+// checkMaintenance 检查连接是否允许在维护模式下保持连接，不允许时将发送维护数据包并断开连接
+//   - 返回 false 时表示连接已被断开
+func (slf *Server) checkMaintenance(conn *Conn) bool {
+	m := slf.maintenance.Load()
+	if m == nil || (m.whitelist != nil && m.whitelist(conn)) {
+		return true
+	}
+	conn.flushAndClose(slf.maintenancePacket, CloseReasonMaintenance, ErrServerMaintenance)
+	return false
+}
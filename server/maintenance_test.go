@@ -0,0 +1,58 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+func TestServer_EnterMaintenance(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket, server.WithMaintenance([]byte("maintenance"), nil))
+	var closed = make(chan server.CloseReason, 2)
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		if srv.GetOnlineCount() == 1 {
+			conn.SetData("gm", true)
+		}
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
+		closed <- reason
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		server.NewBot(srv).JoinServer()
+		server.NewBot(srv).JoinServer()
+	})
+	runServer(t, srv, freeAddr(t, "tcp")+"/maintenance")
+	time.Sleep(100 * time.Millisecond)
+
+	srv.EnterMaintenance(func(conn *server.Conn) bool {
+		return conn.GetData("gm") == true
+	})
+	select {
+	case reason := <-closed:
+		if reason != server.CloseReasonMaintenance {
+			t.Fatalf("expected %s, got %s", server.CloseReasonMaintenance, reason)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection not in whitelist was not closed")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if count := srv.GetOnlineCount(); count != 1 {
+		t.Fatalf("expected 1 connection online, got %d", count)
+	}
+
+	server.NewBot(srv).JoinServer()
+	select {
+	case reason := <-closed:
+		if reason != server.CloseReasonMaintenance {
+			t.Fatalf("expected %s, got %s", server.CloseReasonMaintenance, reason)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("new connection was not closed during maintenance")
+	}
+
+	srv.ExitMaintenance()
+	if srv.IsMaintenance() {
+		t.Fatal("expected server to exit maintenance")
+	}
+	srv.Shutdown()
+}
@@ -1,8 +1,11 @@
 package server
 
 import (
+	"context"
 	"github.com/kercylan98/minotaur/utils/hash"
 	"github.com/kercylan98/minotaur/utils/log"
+	"reflect"
+	goruntime "runtime"
 )
 
 const (
@@ -47,6 +50,15 @@ const (
 
 	// MessageTypeShunt 普通分流消息类型
 	MessageTypeShunt
+
+	// MessageTypeCross 跨服消息类型：该类型的数据将被发送到 ReceiveCrossPacketEvent 进行处理
+	MessageTypeCross
+
+	// MessageTypeDelay 延迟消息类型：将在延迟时间到达后于系统分发器中执行
+	MessageTypeDelay
+
+	// MessageTypeShuntDelay 分流延迟消息类型：将在延迟时间到达后于连接所在的分发器中执行
+	MessageTypeShuntDelay
 )
 
 var messageNames = map[MessageType]string{
@@ -64,6 +76,9 @@ var messageNames = map[MessageType]string{
 	MessageTypeUniqueShuntAsyncCallback: "MessageTypeUniqueShuntAsyncCallback",
 	MessageTypeSystem:                   "MessageTypeSystem",
 	MessageTypeShunt:                    "MessageTypeShunt",
+	MessageTypeCross:                    "MessageTypeCross",
+	MessageTypeDelay:                    "MessageTypeDelay",
+	MessageTypeShuntDelay:               "MessageTypeShuntDelay",
 }
 
 const (
@@ -99,26 +114,40 @@ type Message struct {
 	ordinaryHandler  func()
 	exceptionHandler func() error
 	errHandler       func(err error)
-	packet           []byte
 	err              error
 	name             string
 	t                MessageType
-	errAction        MessageErrorAction
 	marks            []log.Field
+	packetMessage    PacketMessage // MessageTypePacket 消息的内容
+	errorMessage     ErrorMessage  // MessageTypeError 消息的内容
+	tickerMessage    TickerMessage // MessageTypeTicker 及 MessageTypeShuntTicker 消息的内容
+	crossMessage     CrossMessage  // MessageTypeCross 消息的内容
+	ctx              context.Context
+	failed           bool       // 执行过程中是否发生 panic 或返回错误
+	delay            *delayTask // 延迟消息的执行状态
 }
 
 // reset 重置消息结构体
 func (slf *Message) reset() {
+	if slf.conn != nil && slf.conn.buffer != nil {
+		slf.conn.buffer.release()
+		slf.conn.buffer = nil
+	}
 	slf.conn = nil
 	slf.ordinaryHandler = nil
 	slf.exceptionHandler = nil
 	slf.errHandler = nil
-	slf.packet = nil
 	slf.err = nil
 	slf.name = ""
 	slf.t = 0
-	slf.errAction = 0
+	slf.packetMessage = PacketMessage{}
+	slf.errorMessage = ErrorMessage{}
+	slf.tickerMessage = TickerMessage{}
+	slf.crossMessage = CrossMessage{}
+	slf.failed = false
 	slf.marks = nil
+	slf.ctx = nil
+	slf.delay = nil
 }
 
 // MessageType 返回消息类型
@@ -126,6 +155,112 @@ func (slf *Message) MessageType() MessageType {
 	return slf.t
 }
 
+// Typed 返回类型化的消息，可断言为 *PacketMessage、*ErrorMessage、*TickerMessage 或 *CrossMessage
+//   - 其他类型的消息将返回 nil
+func (slf *Message) Typed() TypedMessage {
+	switch slf.t {
+	case MessageTypePacket:
+		return &slf.packetMessage
+	case MessageTypeError:
+		return &slf.errorMessage
+	case MessageTypeTicker, MessageTypeShuntTicker:
+		return &slf.tickerMessage
+	case MessageTypeCross:
+		return &slf.crossMessage
+	}
+	return nil
+}
+
+// Context 返回消息执行期间的上下文，当通过 WithMessageExecTimeout 设置执行超时时，上下文将在超时后被取消
+//   - 耗时较长的处理函数可通过检查 Context().Done() 主动中断执行
+//   - 未设置执行超时时将返回 context.Background()
+func (slf *Message) Context() context.Context {
+	if slf.ctx == nil {
+		return context.Background()
+	}
+	return slf.ctx
+}
+
+// GetRoute 返回 MessageTypePacket 消息的路由名称，通过 Router 分发的数据包将为消息 ID，其他消息将返回空字符串
+//   - 自行分发数据包时可通过 Conn.SetMessageRoute 设置
+func (slf *Message) GetRoute() string {
+	if slf.t != MessageTypePacket || slf.conn == nil {
+		return ""
+	}
+	return slf.conn.route
+}
+
+// GetHandlerName 返回消息处理函数的名称，不存在处理函数的消息将返回空字符串
+func (slf *Message) GetHandlerName() string {
+	var handler any
+	switch {
+	case slf.tickerMessage.caller != nil:
+		handler = slf.tickerMessage.caller
+	case slf.ordinaryHandler != nil:
+		handler = slf.ordinaryHandler
+	case slf.exceptionHandler != nil:
+		handler = slf.exceptionHandler
+	case slf.errHandler != nil:
+		handler = slf.errHandler
+	default:
+		return ""
+	}
+	if f := goruntime.FuncForPC(reflect.ValueOf(handler).Pointer()); f != nil {
+		return f.Name()
+	}
+	return ""
+}
+
+// logFields 返回携带消息类型及连接 ID 的日志字段
+func (slf *Message) logFields(fields ...log.Field) []log.Field {
+	var result = make([]log.Field, 0, len(fields)+2)
+	result = append(result, log.String("MessageType", messageNames[slf.t]))
+	if slf.conn != nil {
+		result = append(result, log.String("ConnID", slf.conn.GetID()))
+	}
+	return append(result, fields...)
+}
+
+// GetConn 返回消息所属的连接，仅 MessageTypePacket 及分流类消息存在连接，其他消息将返回 nil
+func (slf *Message) GetConn() *Conn {
+	return slf.conn
+}
+
+// GetPacket 返回 MessageTypePacket 及 MessageTypeCross 消息的数据包，其他消息将返回 nil
+func (slf *Message) GetPacket() []byte {
+	switch slf.t {
+	case MessageTypePacket:
+		return slf.packetMessage.packet
+	case MessageTypeCross:
+		return slf.crossMessage.packet
+	}
+	return nil
+}
+
+// GetError 返回 MessageTypeError 及异步回调类消息所携带的错误
+func (slf *Message) GetError() error {
+	if slf.t == MessageTypeError {
+		return slf.errorMessage.err
+	}
+	return slf.err
+}
+
+// GetName 返回定时器消息的名称、唯一异步类消息的唯一标识或跨服消息的发送方服务器 ID
+func (slf *Message) GetName() string {
+	switch slf.t {
+	case MessageTypeTicker, MessageTypeShuntTicker:
+		return slf.tickerMessage.name
+	case MessageTypeCross:
+		return slf.crossMessage.serverId
+	}
+	return slf.name
+}
+
+// GetCrossName 返回跨服消息所属的跨服名称，其他消息将返回空字符串
+func (slf *Message) GetCrossName() string {
+	return slf.crossMessage.crossName
+}
+
 // String 返回消息的字符串表示
 func (slf *Message) String() string {
 	return slf.t.String()
@@ -138,19 +273,22 @@ func (slf MessageType) String() string {
 
 // castToPacketMessage 将消息转换为数据包消息
 func (slf *Message) castToPacketMessage(conn *Conn, packet []byte, mark ...log.Field) *Message {
-	slf.t, slf.conn, slf.packet, slf.marks = MessageTypePacket, conn, packet, mark
+	slf.t, slf.conn, slf.marks = MessageTypePacket, conn, mark
+	slf.packetMessage = PacketMessage{conn: conn, packet: packet}
 	return slf
 }
 
 // castToTickerMessage 将消息转换为定时器消息
 func (slf *Message) castToTickerMessage(name string, caller func(), mark ...log.Field) *Message {
-	slf.t, slf.name, slf.ordinaryHandler, slf.marks = MessageTypeTicker, name, caller, mark
+	slf.t, slf.marks = MessageTypeTicker, mark
+	slf.tickerMessage = TickerMessage{name: name, caller: caller}
 	return slf
 }
 
 // castToShuntTickerMessage 将消息转换为分发器定时器消息
 func (slf *Message) castToShuntTickerMessage(conn *Conn, name string, caller func(), mark ...log.Field) *Message {
-	slf.t, slf.conn, slf.name, slf.ordinaryHandler, slf.marks = MessageTypeShuntTicker, slf.conn, name, caller, mark
+	slf.t, slf.conn, slf.marks = MessageTypeShuntTicker, conn, mark
+	slf.tickerMessage = TickerMessage{conn: conn, name: name, caller: caller}
 	return slf
 }
 
@@ -210,7 +348,8 @@ func (slf *Message) castToSystemMessage(caller func(), mark ...log.Field) *Messa
 
 // castToErrorMessage 将消息转换为错误消息
 func (slf *Message) castToErrorMessage(err error, action MessageErrorAction, mark ...log.Field) *Message {
-	slf.t, slf.err, slf.errAction, slf.marks = MessageTypeError, err, action, mark
+	slf.t, slf.marks = MessageTypeError, mark
+	slf.errorMessage = ErrorMessage{err: err, action: action}
 	return slf
 }
 
@@ -219,3 +358,22 @@ func (slf *Message) castToShuntMessage(conn *Conn, caller func(), mark ...log.Fi
 	slf.t, slf.conn, slf.ordinaryHandler, slf.marks = MessageTypeShunt, conn, caller, mark
 	return slf
 }
+
+// castToDelayMessage 将消息转换为延迟消息
+func (slf *Message) castToDelayMessage(task *delayTask, caller func(), mark ...log.Field) *Message {
+	slf.t, slf.delay, slf.ordinaryHandler, slf.marks = MessageTypeDelay, task, caller, mark
+	return slf
+}
+
+// castToShuntDelayMessage 将消息转换为分流延迟消息
+func (slf *Message) castToShuntDelayMessage(conn *Conn, task *delayTask, caller func(), mark ...log.Field) *Message {
+	slf.t, slf.conn, slf.delay, slf.ordinaryHandler, slf.marks = MessageTypeShuntDelay, conn, task, caller, mark
+	return slf
+}
+
+// castToCrossMessage 将消息转换为跨服消息
+func (slf *Message) castToCrossMessage(crossName, serverId string, packet []byte, mark ...log.Field) *Message {
+	slf.t, slf.marks = MessageTypeCross, mark
+	slf.crossMessage = CrossMessage{crossName: crossName, serverId: serverId, packet: packet}
+	return slf
+}
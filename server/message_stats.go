@@ -0,0 +1,160 @@
+package server
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMessageExecStatsSampleSize = 1024 // 默认每种消息保留的执行耗时样本数量
+)
+
+// MessageExecStats 特定消息的执行耗时统计，通过 Server.GetMessageExecStats 获取
+//   - Count、Errors、Low、Avg 及 Max 为启用统计或上次重置以来的累计数据，P50、P90 及 P99 基于最近的执行耗时样本计算
+type MessageExecStats struct {
+	Type   MessageType   `json:"type"`   // 消息类型
+	Name   string        `json:"name"`   // MessageTypePacket 消息为路由名称（通过 Router 分发时为消息 ID），其他消息为处理函数名称，可参考 Message.GetRoute 及 Message.GetHandlerName
+	Count  int64         `json:"count"`  // 执行次数
+	Errors int64         `json:"errors"` // 执行过程中发生 panic 或异步消息返回错误的次数
+	Low    int64         `json:"low"`    // 慢消息次数
+	Avg    time.Duration `json:"avg"`    // 平均耗时
+	Max    time.Duration `json:"max"`    // 最大耗时
+	P50    time.Duration `json:"p50"`    // 50 分位耗时
+	P90    time.Duration `json:"p90"`    // 90 分位耗时
+	P99    time.Duration `json:"p99"`    // 99 分位耗时
+}
+
+// newMessageExecStats 创建消息执行耗时统计
+func newMessageExecStats(sampleSize int) *messageExecStats {
+	return &messageExecStats{
+		sampleSize: sampleSize,
+		entries:    map[messageExecStatsKey]*messageExecStatsEntry{},
+	}
+}
+
+type messageExecStatsKey struct {
+	t    MessageType
+	name string
+}
+
+type messageExecStatsEntry struct {
+	count   int64
+	errors  int64
+	low     int64
+	total   time.Duration
+	max     time.Duration
+	samples []time.Duration
+	cursor  int
+}
+
+// messageExecStats 消息执行耗时统计
+type messageExecStats struct {
+	sampleSize int
+	entries    map[messageExecStatsKey]*messageExecStatsEntry
+	rw         sync.Mutex
+}
+
+// record 记录消息的执行耗时
+func (slf *messageExecStats) record(t MessageType, name string, cost time.Duration, low, failed bool) {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	var key = messageExecStatsKey{t: t, name: name}
+	entry, exist := slf.entries[key]
+	if !exist {
+		entry = &messageExecStatsEntry{samples: make([]time.Duration, 0, slf.sampleSize)}
+		slf.entries[key] = entry
+	}
+	entry.count++
+	entry.total += cost
+	if cost > entry.max {
+		entry.max = cost
+	}
+	if low {
+		entry.low++
+	}
+	if failed {
+		entry.errors++
+	}
+	if len(entry.samples) < slf.sampleSize {
+		entry.samples = append(entry.samples, cost)
+	} else {
+		entry.samples[entry.cursor] = cost
+		entry.cursor = (entry.cursor + 1) % slf.sampleSize
+	}
+}
+
+// snapshot 获取所有消息的执行耗时统计，结果将按照 P99 降序排列
+//   - reset 为 true 时将在获取后清空统计数据
+func (slf *messageExecStats) snapshot(reset bool) []MessageExecStats {
+	slf.rw.Lock()
+	var result = make([]MessageExecStats, 0, len(slf.entries))
+	var samples []time.Duration
+	for key, entry := range slf.entries {
+		samples = append(samples[:0], entry.samples...)
+		sort.Slice(samples, func(i, j int) bool {
+			return samples[i] < samples[j]
+		})
+		result = append(result, MessageExecStats{
+			Type:   key.t,
+			Name:   key.name,
+			Count:  entry.count,
+			Errors: entry.errors,
+			Low:    entry.low,
+			Avg:    entry.total / time.Duration(entry.count),
+			Max:    entry.max,
+			P50:    percentile(samples, 0.5),
+			P90:    percentile(samples, 0.9),
+			P99:    percentile(samples, 0.99),
+		})
+	}
+	if reset {
+		slf.entries = map[messageExecStatsKey]*messageExecStatsEntry{}
+	}
+	slf.rw.Unlock()
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].P99 == result[j].P99 {
+			return result[i].Max > result[j].Max
+		}
+		return result[i].P99 > result[j].P99
+	})
+	return result
+}
+
+// percentile 获取已排序样本中特定分位的值
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(float64(len(sorted))*p+0.5) - 1
+	if index < 0 {
+		index = 0
+	} else if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index]
+}
+
+// GetMessageExecStats 获取通过 WithMessageExecStats 启用的消息执行耗时统计，结果将按照 P99 降序排列，当未启用时将返回 nil
+func (slf *Server) GetMessageExecStats() []MessageExecStats {
+	return slf.Stats()
+}
+
+// Stats 获取通过 WithMessageExecStats 启用的按消息类型及路由统计的执行次数、错误次数及耗时摘要，结果将按照 P99 降序排列，当未启用时将返回 nil
+//   - reset 为 true 时将在获取后重置统计数据，适用于按固定周期上报的监控面板
+func (slf *Server) Stats(reset ...bool) []MessageExecStats {
+	if slf.messageExecStats == nil {
+		return nil
+	}
+	return slf.messageExecStats.snapshot(len(reset) > 0 && reset[0])
+}
+
+// ResetStats 重置通过 WithMessageExecStats 启用的消息执行耗时统计
+func (slf *Server) ResetStats() {
+	if slf.messageExecStats == nil {
+		return
+	}
+	slf.messageExecStats.rw.Lock()
+	slf.messageExecStats.entries = map[messageExecStatsKey]*messageExecStatsEntry{}
+	slf.messageExecStats.rw.Unlock()
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMessageExecStats(t *testing.T) {
+	stats := newMessageExecStats(100)
+	for i := 1; i <= 200; i++ {
+		stats.record(MessageTypePacket, "1001", time.Duration(i)*time.Millisecond, i > 190, i%50 == 0)
+	}
+	stats.record(MessageTypeSystem, "handler", time.Second, true, false)
+
+	result := stats.snapshot(false)
+	if len(result) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(result))
+	}
+	if result[0].Name != "handler" {
+		t.Fatalf("expected the slowest message first, got %s", result[0].Name)
+	}
+	packet := result[1]
+	if packet.Count != 200 || packet.Errors != 4 || packet.Low != 10 || packet.Max != 200*time.Millisecond {
+		t.Fatalf("unexpected stats: %+v", packet)
+	}
+	// 仅保留最近的 100 个样本，即 101ms ~ 200ms
+	if packet.P50 != 150*time.Millisecond || packet.P99 != 199*time.Millisecond {
+		t.Fatalf("unexpected percentiles: %+v", packet)
+	}
+
+	if result = stats.snapshot(true); len(result) != 2 {
+		t.Fatalf("expected 2 entries before reset, got %d", len(result))
+	}
+	if result = stats.snapshot(false); len(result) != 0 {
+		t.Fatalf("expected no entries after reset, got %d", len(result))
+	}
+}
+
+func TestMessage_GetHandlerName(t *testing.T) {
+	msg := new(Message).castToSystemMessage(testMessageHandler)
+	if name := msg.GetHandlerName(); name != "github.com/kercylan98/minotaur/server.testMessageHandler" {
+		t.Fatalf("unexpected handler name: %s", name)
+	}
+}
+
+func testMessageHandler() {}
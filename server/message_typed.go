@@ -0,0 +1,158 @@
+package server
+
+import "github.com/kercylan98/minotaur/utils/log"
+
+// TypedMessage 类型化的服务器消息，可通过 Message.Typed 获取后断言为具体的消息结构
+//   - 目前包括 *PacketMessage、*ErrorMessage、*TickerMessage 及 *CrossMessage
+//   - 类型化消息与所属的 Message 共享生命周期，消息执行完毕后将被回收复用，不应在事件外持有
+type TypedMessage interface {
+	// MessageType 返回消息类型
+	MessageType() MessageType
+
+	// dispatcher 返回消息应当写入的分发器
+	dispatcher(srv *Server) *dispatcher
+
+	// handle 在分发器中处理消息
+	handle(srv *Server)
+}
+
+// PacketMessage 数据包消息，将被发送到 ConnectionReceivePacketEvent 进行处理
+type PacketMessage struct {
+	conn   *Conn
+	packet []byte
+}
+
+// MessageType 返回消息类型
+func (slf *PacketMessage) MessageType() MessageType {
+	return MessageTypePacket
+}
+
+// GetConn 返回发送数据包的连接
+func (slf *PacketMessage) GetConn() *Conn {
+	return slf.conn
+}
+
+// GetPacket 返回数据包，经过 ConnectionPacketPreprocessEvent 替换后将返回替换后的数据包
+func (slf *PacketMessage) GetPacket() []byte {
+	return slf.packet
+}
+
+func (slf *PacketMessage) dispatcher(srv *Server) *dispatcher {
+	return srv.getConnDispatcher(slf.conn)
+}
+
+func (slf *PacketMessage) handle(srv *Server) {
+	if !srv.authenticate(slf.conn, slf.packet) {
+		return
+	}
+	if !srv.OnConnectionPacketPreprocessEvent(slf.conn, slf.packet, func(newPacket []byte) { slf.packet = newPacket }) {
+		srv.OnConnectionReceivePacketEvent(slf.conn, slf.packet)
+	}
+}
+
+// ErrorMessage 错误消息，将根据 MessageErrorAction 交由 Server 进行统一处理
+type ErrorMessage struct {
+	err    error
+	action MessageErrorAction
+}
+
+// MessageType 返回消息类型
+func (slf *ErrorMessage) MessageType() MessageType {
+	return MessageTypeError
+}
+
+// GetError 返回消息携带的错误
+func (slf *ErrorMessage) GetError() error {
+	return slf.err
+}
+
+// GetAction 返回错误的处理方式
+func (slf *ErrorMessage) GetAction() MessageErrorAction {
+	return slf.action
+}
+
+func (slf *ErrorMessage) dispatcher(srv *Server) *dispatcher {
+	return srv.systemDispatcher
+}
+
+func (slf *ErrorMessage) handle(srv *Server) {
+	switch slf.action {
+	case MessageErrorActionNone:
+		srv.Logger().Panic("Server", log.Err(slf.err))
+	case MessageErrorActionShutdown:
+		go srv.shutdown(slf.err)
+	default:
+		srv.Logger().Warn("Server", log.String("not support message error action", slf.action.String()))
+	}
+}
+
+// TickerMessage 定时器消息，存在连接时为分流定时器消息，将在连接所在的分发器中执行
+type TickerMessage struct {
+	conn   *Conn
+	name   string
+	caller func()
+}
+
+// MessageType 返回消息类型，分流定时器消息将返回 MessageTypeShuntTicker
+func (slf *TickerMessage) MessageType() MessageType {
+	if slf.conn != nil {
+		return MessageTypeShuntTicker
+	}
+	return MessageTypeTicker
+}
+
+// GetConn 返回分流定时器消息所属的连接，非分流定时器消息将返回 nil
+func (slf *TickerMessage) GetConn() *Conn {
+	return slf.conn
+}
+
+// GetName 返回定时器名称
+func (slf *TickerMessage) GetName() string {
+	return slf.name
+}
+
+func (slf *TickerMessage) dispatcher(srv *Server) *dispatcher {
+	if slf.conn != nil {
+		return srv.getConnDispatcher(slf.conn)
+	}
+	return srv.systemDispatcher
+}
+
+func (slf *TickerMessage) handle(srv *Server) {
+	slf.caller()
+}
+
+// CrossMessage 跨服消息，将被发送到 ReceiveCrossPacketEvent 进行处理
+type CrossMessage struct {
+	crossName string
+	serverId  string
+	packet    []byte
+}
+
+// MessageType 返回消息类型
+func (slf *CrossMessage) MessageType() MessageType {
+	return MessageTypeCross
+}
+
+// GetCrossName 返回消息所属的跨服名称
+func (slf *CrossMessage) GetCrossName() string {
+	return slf.crossName
+}
+
+// GetServerId 返回发送方服务器 ID
+func (slf *CrossMessage) GetServerId() string {
+	return slf.serverId
+}
+
+// GetPacket 返回跨服数据包
+func (slf *CrossMessage) GetPacket() []byte {
+	return slf.packet
+}
+
+func (slf *CrossMessage) dispatcher(srv *Server) *dispatcher {
+	return srv.systemDispatcher
+}
+
+func (slf *CrossMessage) handle(srv *Server) {
+	srv.OnReceiveCrossPacketEvent(slf.crossName, slf.serverId, slf.packet)
+}
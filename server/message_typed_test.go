@@ -0,0 +1,50 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+func TestMessage_Typed(t *testing.T) {
+	srv := server.New(server.NetworkNone, server.WithCross("loopback", "server-1", new(loopbackCross)))
+	var received = make(chan string, 2)
+	srv.RegMessageExecBeforeEvent(func(srv *server.Server, message *server.Message) bool {
+		switch typed := message.Typed().(type) {
+		case *server.TickerMessage:
+			if typed.MessageType() != server.MessageTypeTicker || typed.GetConn() != nil {
+				t.Errorf("unexpected ticker message %s", typed.MessageType())
+			}
+			received <- "ticker:" + typed.GetName()
+		case *server.CrossMessage:
+			received <- "cross:" + typed.GetCrossName() + ":" + typed.GetServerId() + ":" + string(typed.GetPacket())
+		case nil:
+			if message.MessageType() == server.MessageTypeTicker || message.MessageType() == server.MessageTypeCross {
+				t.Errorf("%s should be typed", message.MessageType())
+			}
+		}
+		return true
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		srv.PushTickerMessage("tick", func() {})
+		if err := srv.PushCrossMessage("loopback", "server-1", []byte("hello")); err != nil {
+			t.Error(err)
+		}
+	})
+	go func() {
+		_ = srv.RunNone()
+	}()
+	defer srv.Shutdown()
+
+	var expected = map[string]bool{"ticker:tick": true, "cross:loopback:server-1:hello": true}
+	for i := 0; i < len(expected); i++ {
+		select {
+		case message := <-received:
+			if !expected[message] {
+				t.Fatalf("unexpected typed message %s", message)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("typed message was not received")
+		}
+	}
+}
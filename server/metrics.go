@@ -0,0 +1,144 @@
+package server
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultMetricsNamespace = "minotaur" // 默认的指标命名空间
+	DefaultMetricsPattern   = "/metrics" // 默认的指标路由
+)
+
+// newMetrics 创建服务器的 Prometheus 指标，每个服务器拥有独立的注册表
+func newMetrics(srv *Server, namespace string) *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		packetsReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "packets_received_total",
+			Help: "Total number of packets received from connections.",
+		}),
+		packetsSent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "packets_sent_total",
+			Help: "Total number of packets written to connections.",
+		}),
+		bytesReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "bytes_received_total",
+			Help: "Total number of packet bytes received from connections.",
+		}),
+		bytesSent: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "bytes_sent_total",
+			Help: "Total number of packet bytes written to connections.",
+		}),
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "messages_total",
+			Help: "Total number of messages pushed into the server by message type.",
+		}, []string{"type"}),
+		messageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "message_duration_seconds",
+			Help:    "Time spent executing messages by message type.",
+			Buckets: []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1, 5},
+		}, []string{"type"}),
+		messageLowExec: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "message_low_exec_total",
+			Help: "Total number of messages whose execution exceeded the expected duration by message type.",
+		}, []string{"type"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "connections",
+			Help: "Number of online connections.",
+		}, func() float64 {
+			return float64(srv.GetOnlineCount())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "message_queue_depth",
+			Help: "Number of messages waiting to be or being executed.",
+		}, func() float64 {
+			return float64(srv.GetMessageCount())
+		}),
+		m.packetsReceived, m.packetsSent, m.bytesReceived, m.bytesSent,
+		m.messages, m.messageDuration, m.messageLowExec,
+	)
+	return m
+}
+
+// metrics 服务器的 Prometheus 指标
+type metrics struct {
+	registry        *prometheus.Registry
+	packetsReceived prometheus.Counter
+	packetsSent     prometheus.Counter
+	bytesReceived   prometheus.Counter
+	bytesSent       prometheus.Counter
+	messages        *prometheus.CounterVec
+	messageDuration *prometheus.HistogramVec
+	messageLowExec  *prometheus.CounterVec
+}
+
+// receive 记录接收到的数据包
+func (slf *metrics) receive(packet []byte) {
+	if slf == nil {
+		return
+	}
+	slf.packetsReceived.Inc()
+	slf.bytesReceived.Add(float64(len(packet)))
+}
+
+// send 记录写入的数据包
+func (slf *metrics) send(packet []byte) {
+	if slf == nil {
+		return
+	}
+	slf.packetsSent.Inc()
+	slf.bytesSent.Add(float64(len(packet)))
+}
+
+// push 记录推送的消息
+func (slf *metrics) push(t MessageType) {
+	if slf == nil {
+		return
+	}
+	slf.messages.WithLabelValues(t.String()).Inc()
+}
+
+// exec 记录消息的执行耗时
+func (slf *metrics) exec(t MessageType, cost time.Duration, low bool) {
+	if slf == nil {
+		return
+	}
+	slf.messageDuration.WithLabelValues(t.String()).Observe(cost.Seconds())
+	if low {
+		slf.messageLowExec.WithLabelValues(t.String()).Inc()
+	}
+}
+
+// MetricsHandler 获取通过 WithMetrics 启用的 Prometheus 指标处理器，可挂载到任意 HTTP 服务中，例如 gin.WrapH(srv.MetricsHandler())
+//   - 当未通过 WithMetrics 启用指标时，将会发生 panic，在库代码中应使用不会发生 panic 的 GetMetricsHandler
+func (slf *Server) MetricsHandler() http.Handler {
+	handler, err := slf.GetMetricsHandler()
+	if err != nil {
+		panic(err)
+	}
+	return handler
+}
+
+// GetMetricsHandler 与 MetricsHandler 一致，不同的是当未通过 WithMetrics 启用指标时将返回 ErrNoSupportMetrics
+func (slf *Server) GetMetricsHandler() (http.Handler, error) {
+	if slf.metrics == nil {
+		return nil, ErrNoSupportMetrics
+	}
+	return promhttp.HandlerFor(slf.metrics.registry, promhttp.HandlerOpts{}), nil
+}
+
+// MetricsRegistry 获取通过 WithMetrics 启用的 Prometheus 注册表，可用于注册自定义指标，当未启用时将返回 nil
+func (slf *Server) MetricsRegistry() *prometheus.Registry {
+	if slf.metrics == nil {
+		return nil
+	}
+	return slf.metrics.registry
+}
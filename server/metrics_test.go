@@ -0,0 +1,29 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServer_MetricsHandler(t *testing.T) {
+	srv := server.New(server.NetworkNone, server.WithMetrics())
+
+	recorder := httptest.NewRecorder()
+	srv.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Body)
+	for _, name := range []string{"minotaur_connections", "minotaur_message_queue_depth", "minotaur_packets_received_total", "go_goroutines"} {
+		if !strings.Contains(string(body), name) {
+			t.Fatalf("metric %s not found", name)
+		}
+	}
+
+	defer func() {
+		if err := recover(); err != server.ErrNoSupportMetrics {
+			t.Fatalf("expected ErrNoSupportMetrics, got %v", err)
+		}
+	}()
+	server.New(server.NetworkNone).MetricsHandler()
+}
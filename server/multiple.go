@@ -3,17 +3,19 @@ package server
 import (
 	"github.com/kercylan98/minotaur/utils/log"
 	"github.com/kercylan98/minotaur/utils/network"
-	"math"
 	"os"
 	"os/signal"
 	"sync"
 	"syscall"
 )
 
+// NewMultipleServer 创建一个多服务器管理器，用于在同一进程中运行多个服务器，例如网关、HTTP 及战斗服务器
+//   - serverHandle 用于返回服务器的侦听地址及服务器实例
 func NewMultipleServer(serverHandle ...func() (addr string, srv *Server)) *MultipleServer {
 	ms := &MultipleServer{
 		servers:   make([]*Server, len(serverHandle), len(serverHandle)),
 		addresses: make([]string, len(serverHandle), len(serverHandle)),
+		shutdown:  make(chan struct{}, 1),
 	}
 	for i := 0; i < len(serverHandle); i++ {
 		ms.addresses[i], ms.servers[i] = serverHandle[i]()
@@ -21,47 +23,45 @@ func NewMultipleServer(serverHandle ...func() (addr string, srv *Server)) *Multi
 	return ms
 }
 
+// MultipleServer 多服务器管理器，统一管理多个服务器的生命周期
+//   - 所有服务器共享同一个系统信号处理，接收到系统信号时将关闭所有服务器
+//   - 任一服务器关闭时（例如发生错误、调用 Server.Shutdown 或到达 WithLimitLife 生命周期），其他服务器也将随之关闭
 type MultipleServer struct {
 	servers          []*Server
 	addresses        []string
 	exitEventHandles []func()
+	shutdown         chan struct{}
 }
 
-func (slf *MultipleServer) Run() {
-	var exceptionChannel = make(chan error, 1)
-	var runtimeExceptionChannel = make(chan error, 1)
-	defer func() {
-		close(exceptionChannel)
-		close(runtimeExceptionChannel)
-	}()
+// Run 运行所有服务器，并阻塞至所有服务器关闭
+//   - 任一服务器启动失败时，将关闭其他已启动的服务器并返回该错误
+//   - 由于服务器运行时错误导致的关闭，将返回该错误
+func (slf *MultipleServer) Run() error {
+	var stopped = make(chan error, len(slf.servers))
+	var startErr error
+	var startErrLock sync.Mutex
 	var wait sync.WaitGroup
 	for i := 0; i < len(slf.servers); i++ {
 		wait.Add(1)
 		go func(address string, server *Server) {
-			var lock sync.Mutex
-			var startFinish bool
-			server.startFinishEventHandlers.Append(func(srv *Server) {
-				lock.Lock()
-				defer lock.Unlock()
-				if !startFinish {
-					startFinish = true
-					wait.Done()
-				}
-			}, math.MaxInt)
+			defer wait.Done()
 			server.multiple = slf
-			server.multipleRuntimeErrorChan = runtimeExceptionChannel
+			server.multipleRuntimeErrorChan = stopped
 			if err := server.Run(address); err != nil {
-				exceptionChannel <- err
-			}
-			lock.Lock()
-			defer lock.Unlock()
-			if !startFinish {
-				startFinish = true
-				wait.Done()
+				startErrLock.Lock()
+				if startErr == nil {
+					startErr = err
+				}
+				startErrLock.Unlock()
 			}
 		}(slf.addresses[i], slf.servers[i])
 	}
 	wait.Wait()
+	if startErr != nil {
+		slf.shutdownAll()
+		slf.OnExitEvent()
+		return startErr
+	}
 
 	log.Info("Server", log.String(serverMultipleMark, "===================================================================="))
 	ip, _ := network.IP()
@@ -76,45 +76,42 @@ func (slf *MultipleServer) Run() {
 
 	systemSignal := make(chan os.Signal, 1)
 	signal.Notify(systemSignal, syscall.SIGHUP, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(systemSignal)
+	var err error
 	select {
-	case err := <-exceptionChannel:
-		for _, server := range slf.servers {
-			server.OnStopEvent()
-		}
-		for len(slf.servers) > 0 {
-			server := slf.servers[0]
-			server.shutdown(err)
-			slf.servers = slf.servers[1:]
-		}
-		break
-	case <-runtimeExceptionChannel:
-		for _, server := range slf.servers {
-			server.OnStopEvent()
-		}
-		for len(slf.servers) > 0 {
-			server := slf.servers[0]
-			server.multipleRuntimeErrorChan = nil
-			server.shutdown(nil)
-			slf.servers = slf.servers[1:]
-		}
-		break
 	case <-systemSignal:
-		for _, server := range slf.servers {
-			server.OnStopEvent()
-		}
-		for len(slf.servers) > 0 {
-			server := slf.servers[0]
-			server.multipleRuntimeErrorChan = nil
-			server.shutdown(nil)
-			slf.servers = slf.servers[1:]
-		}
-		break
+	case <-slf.shutdown:
+	case err = <-stopped:
 	}
-
+	slf.shutdownAll()
 	slf.OnExitEvent()
+	return err
+}
+
+// Shutdown 关闭所有服务器，Run 将在所有服务器关闭后返回
+func (slf *MultipleServer) Shutdown() {
+	select {
+	case slf.shutdown <- struct{}{}:
+	default:
+	}
+}
+
+// GetServers 获取所有服务器
+func (slf *MultipleServer) GetServers() []*Server {
+	return slf.servers
+}
+
+// shutdownAll 依次关闭所有服务器，并等待所有服务器关闭完成
+func (slf *MultipleServer) shutdownAll() {
+	for _, server := range slf.servers {
+		server.shutdown(nil)
+	}
+	for _, server := range slf.servers {
+		<-server.closeChannel
+	}
 }
 
-// RegExitEvent 注册退出事件
+// RegExitEvent 注册退出事件，将在所有服务器关闭后执行
 func (slf *MultipleServer) RegExitEvent(handle func()) {
 	slf.exitEventHandles = append(slf.exitEventHandles, handle)
 }
@@ -0,0 +1,53 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+func TestMultipleServer_Run(t *testing.T) {
+	var stopped = make(chan string, 2)
+	var first, second *server.Server
+	ms := server.NewMultipleServer(
+		func() (addr string, srv *server.Server) {
+			first = server.New(server.NetworkNone)
+			first.RegStopEvent(func(srv *server.Server) {
+				stopped <- "first"
+			})
+			first.RegStartFinishEvent(func(srv *server.Server) {
+				go func() {
+					time.Sleep(100 * time.Millisecond)
+					srv.Shutdown()
+				}()
+			})
+			return "", first
+		},
+		func() (addr string, srv *server.Server) {
+			second = server.New(server.NetworkNone)
+			second.RegStopEvent(func(srv *server.Server) {
+				stopped <- "second"
+			})
+			return "", second
+		},
+	)
+
+	var result = make(chan error, 1)
+	go func() {
+		result <- ms.Run()
+	}()
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("multiple server did not stop after one of its servers was shutdown")
+	}
+	if len(stopped) != 2 {
+		t.Fatalf("expected 2 servers stopped, got %d", len(stopped))
+	}
+	if len(ms.GetServers()) != 2 {
+		t.Fatalf("expected 2 servers, got %d", len(ms.GetServers()))
+	}
+}
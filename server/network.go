@@ -10,6 +10,7 @@ const (
 	NetworkTcp  Network = "tcp"
 	NetworkTcp4 Network = "tcp4"
 	NetworkTcp6 Network = "tcp6"
+	// NetworkUdp 该模式下同一远程地址的数据包将被视为同一个虚拟连接，空闲超时可通过 WithUDPSessionTimeout 设置
 	NetworkUdp  Network = "udp"
 	NetworkUdp4 Network = "udp4"
 	NetworkUdp6 Network = "udp6"
@@ -20,11 +21,16 @@ const (
 	NetworkWebsocket Network = "websocket"
 	NetworkKcp       Network = "kcp"
 	NetworkGRPC      Network = "grpc"
+	// NetworkWebTransport 基于 HTTP/3 的 WebTransport 模式，该模式下必须通过 WithTLS 指定证书
+	//  - 客户端需要在会话建立后打开双向流进行通讯，服务器将通过客户端打开的第一个双向流写入数据
+	//  - 流是基于字节的，通常需要配合 WithCodec 处理分包与粘包问题
+	//  - 与 NetworkWebsocket 相同，可以通过连接的 GetData 函数获取 url 参数值
+	NetworkWebTransport Network = "webtransport"
 )
 
 var (
 	networks = []Network{
-		NetworkNone, NetworkTcp, NetworkTcp4, NetworkTcp6, NetworkUdp, NetworkUdp4, NetworkUdp6, NetworkUnix, NetworkHttp, NetworkWebsocket, NetworkKcp, NetworkGRPC,
+		NetworkNone, NetworkTcp, NetworkTcp4, NetworkTcp6, NetworkUdp, NetworkUdp4, NetworkUdp6, NetworkUnix, NetworkHttp, NetworkWebsocket, NetworkKcp, NetworkGRPC, NetworkWebTransport,
 	}
 )
 
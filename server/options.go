@@ -1,10 +1,21 @@
 package server
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
 	"github.com/gin-contrib/pprof"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/kercylan98/minotaur/utils/log"
 	"github.com/kercylan98/minotaur/utils/timer"
+	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
 	"time"
 )
 
@@ -28,18 +39,72 @@ type option struct {
 }
 
 type runtime struct {
-	deadlockDetect            time.Duration // 是否开启死锁检测
-	supportMessageTypes       map[int]bool  // websocket模式下支持的消息类型
-	certFile, keyFile         string        // TLS文件
-	messagePoolSize           int           // 消息池大小
-	ticker                    *timer.Ticker // 定时器
-	tickerAutonomy            bool          // 定时器是否独立运行
-	connTickerSize            int           // 连接定时器大小
-	websocketReadDeadline     time.Duration // websocket连接超时时间
-	websocketCompression      int           // websocket压缩等级
-	websocketWriteCompression bool          // websocket写入压缩
-	limitLife                 time.Duration // 限制最大生命周期
-	packetWarnSize            int           // 数据包大小警告
+	deadlockDetect            time.Duration         // 是否开启死锁检测
+	supportMessageTypes       map[int]bool          // websocket模式下支持的消息类型
+	websocketWriteMessageType int                   // websocket模式下默认写入的消息类型
+	certFile, keyFile         string                // TLS文件
+	tlsConfig                 *tls.Config           // TLS配置
+	messagePoolSize           int                   // 消息池大小
+	ticker                    *timer.Ticker         // 定时器
+	cronLocation              *time.Location        // Cron 调度默认使用的时区
+	tickerAutonomy            bool                  // 定时器是否独立运行
+	connTickerSize            int                   // 连接定时器大小
+	websocketReadDeadline     time.Duration         // websocket连接超时时间
+	websocketPingInterval     time.Duration         // websocket模式下发送 Ping 的间隔
+	websocketUpgrader         *websocket.Upgrader   // websocket升级器
+	websocketCheckOrigin      websocketOriginCheck  // websocket来源检查函数
+	trustedProxies            []*net.IPNet          // 可信代理
+	websocketCompression      int                   // websocket压缩等级
+	websocketWriteCompression bool                  // websocket写入压缩
+	limitLife                 time.Duration         // 限制最大生命周期
+	packetWarnSize            int                   // 数据包大小警告
+	codec                     Codec                 // 数据包编解码器
+	heartbeatInterval         time.Duration         // 心跳间隔
+	heartbeatTimeout          time.Duration         // 心跳超时时间
+	heartbeatPacket           []byte                // 心跳数据包
+	kcpConfig                 *KcpConfig            // KCP调优配置
+	shardCount                int                   // 分片分发器数量
+	shardStrategy             ShardStrategy         // 消息分片策略
+	connMailbox               bool                  // 是否为每个连接分配独立的邮箱
+	workerPoolMin             int                   // 工作池最小工作协程数量
+	workerPoolMax             int                   // 工作池最大工作协程数量
+	workerPoolLatency         time.Duration         // 工作池扩容的排队时长阈值
+	connFilter                *connectionFilter     // 连接过滤器
+	packetRateLimit           *packetRateLimit      // 连接数据包数量速率限制
+	packetByteRateLimit       *packetRateLimit      // 连接数据包字节速率限制
+	compression               *packetCompression    // 数据包压缩器
+	packetCrypto              CryptoAlgorithm       // 数据包加密算法
+	id                        string                // 服务器 ID
+	cross                     map[string]Cross      // 跨服
+	crossDiscovery            CrossDiscovery        // 跨服服务发现
+	metrics                   *metrics              // Prometheus 指标
+	adminAddr                 string                // 管理服务侦听地址
+	adminToken                string                // 管理服务鉴权令牌
+	messageExecTimeout        time.Duration         // 消息执行超时时间
+	lowMessageDuration        time.Duration         // 慢消息阈值
+	asyncLowMessageDuration   time.Duration         // 异步慢消息阈值
+	messageExecStats          *messageExecStats     // 消息执行耗时统计
+	panicPolicy               PanicPolicy           // panic 处理策略
+	hotRestart                *hotRestart           // 热重启
+	maintenancePacket         []byte                // 维护模式下断开连接前发送的数据包
+	maintenanceWhitelist      func(conn *Conn) bool // 维护模式的默认白名单
+	websocketPatterns         []string              // 额外的 Websocket 路由
+	unixSocket                *unixSocket           // Unix socket 文件配置
+	udpSessionTimeout         time.Duration         // UDP 虚拟会话空闲超时时间
+	authHandler               AuthHandler           // 连接鉴权函数
+	authTimeout               time.Duration         // 连接鉴权超时时间
+	websocketJWT              websocketJWTAuth      // Websocket 升级前的 JWT 认证
+	capture                   *packetCapture        // 数据包捕获器
+	maxPacketSize             int                   // 接收数据包的最大长度
+	packetBuffers             *packetBufferPool     // 数据包缓冲池
+	writeCoalesce             *writeCoalesce        // 出站数据包合并写入
+	logger                    log.Logger            // 日志记录器
+	packetLogging             *packetLogging        // 数据包日志
+	grpcHealthEnabled         bool                  // 是否注册 grpc 健康检查服务
+	grpcReflection            bool                  // 是否注册 grpc 反射服务
+	grpcGateway               *grpcGateway          // grpc-gateway 处理器
+	packetSequenceWindow      int                   // 入站数据包序列号窗口大小
+	httpSignatureBodyLimit    int64                 // WithHttpSignature 允许的请求体最大长度
 }
 
 // WithPacketWarnSize 通过数据包大小警告的方式创建服务器，当数据包大小超过指定大小时，将会输出 WARN 类型的日志
@@ -49,7 +114,7 @@ func WithPacketWarnSize(size int) Option {
 	return func(srv *Server) {
 		if size <= 0 {
 			srv.packetWarnSize = 0
-			log.Info("WithPacketWarnSize", log.String("State", "Ignore"), log.String("Reason", "size <= 0"))
+			srv.Logger().Info("WithPacketWarnSize", log.String("State", "Ignore"), log.String("Reason", "size <= 0"))
 			return
 		}
 		srv.packetWarnSize = size
@@ -96,11 +161,100 @@ func WithDeadlockDetect(t time.Duration) Option {
 	return func(srv *Server) {
 		if t > 0 {
 			srv.deadlockDetect = t
-			log.Info("DeadlockDetect", log.String("Time", t.String()))
+			srv.Logger().Info("DeadlockDetect", log.String("Time", t.String()))
 		}
 	}
 }
 
+// WithMessageExecTimeout 通过消息执行超时的方式创建服务器，消息执行超过 timeout 时将取消消息的上下文并触发 MessageTimeoutEvent
+//   - 处理函数可通过 Message.Context 或 Conn.Context 获取上下文，并通过检查 Done() 主动中断执行，服务器不会强制中断处理函数
+//   - 与 WithDeadlockDetect 仅输出日志不同，该选项可用于对卡住的处理函数进行协作式取消及上报
+//   - 默认不开启
+func WithMessageExecTimeout(timeout time.Duration) Option {
+	return func(srv *Server) {
+		if timeout > 0 {
+			srv.messageExecTimeout = timeout
+		}
+	}
+}
+
+// WithContext 通过特定的父级上下文创建服务器，当 ctx 被取消时服务器将自动关闭
+//   - 服务器上下文 Server.Context 将派生自 ctx
+//   - 由上下文取消导致的关闭无法通过 ShutdownBeforeEvent 取消
+//   - 当仅需要在运行时指定上下文时，可使用 Server.RunContext
+func WithContext(ctx context.Context) Option {
+	return func(srv *Server) {
+		if ctx != nil {
+			srv.ctx = ctx
+		}
+	}
+}
+
+// WithHotRestart 通过支持热重启的方式创建服务器，启用后可通过 Server.Restart 在不断开侦听的情况下升级进程
+//   - drainTimeout 为旧进程等待连接全部下线的超时时间，超时后旧进程将直接关闭，<= 0 时将使用 DefaultHotRestartDrainTimeout
+//   - 启用后 gnet 驱动的侦听器将开启 SO_REUSEPORT
+//   - 启用 WithAdmin 及 WithAdminToken 时，还可通过 POST /admin/restart 触发热重启
+func WithHotRestart(drainTimeout time.Duration) Option {
+	return func(srv *Server) {
+		if drainTimeout <= 0 {
+			drainTimeout = DefaultHotRestartDrainTimeout
+		}
+		srv.hotRestart = &hotRestart{drainTimeout: drainTimeout}
+	}
+}
+
+// WithMaintenance 通过特定的维护模式配置创建服务器
+//   - packet 为维护模式下断开不在白名单中的连接前发送的数据包，为空时将直接断开连接
+//   - whitelist 为 Server.EnterMaintenance 未指定白名单及通过管理服务进入维护模式时使用的默认白名单
+func WithMaintenance(packet []byte, whitelist func(conn *Conn) bool) Option {
+	return func(srv *Server) {
+		srv.maintenancePacket = packet
+		srv.maintenanceWhitelist = whitelist
+	}
+}
+
+// WithPanicPolicy 通过特定的 panic 处理策略创建服务器，决定在处理连接数据包或连接相关消息的过程中发生 panic 时服务器的行为
+//   - PanicPolicyRecover：恢复并继续处理后续数据包及消息
+//   - PanicPolicyCloseConnection：以 CloseReasonPanic 关闭发生 panic 的连接
+//   - PanicPolicyShutdown：关闭服务器
+//   - 默认情况下消息分发器中的 panic 将被恢复，而连接读取协程中的 panic 将关闭连接
+//   - 无论何种策略，发生 panic 时都将输出 ERROR 类型的日志并触发 ConnectionPanicEvent
+func WithPanicPolicy(policy PanicPolicy) Option {
+	return func(srv *Server) {
+		srv.panicPolicy = policy
+	}
+}
+
+// WithLowMessageDuration 通过特定的慢消息阈值创建服务器，消息执行耗时超过该值时将输出 WARN 类型的日志并触发 MessageLowExecEvent
+//   - 默认值为 DefaultLowMessageDuration
+//   - 当 duration <= 0 时，表示不检查慢消息
+func WithLowMessageDuration(duration time.Duration) Option {
+	return func(srv *Server) {
+		srv.lowMessageDuration = duration
+	}
+}
+
+// WithAsyncLowMessageDuration 通过特定的异步慢消息阈值创建服务器，作用于异步消息及 HTTP 请求
+//   - 默认值为 DefaultAsyncLowMessageDuration
+//   - 当 duration <= 0 时，表示不检查慢消息
+func WithAsyncLowMessageDuration(duration time.Duration) Option {
+	return func(srv *Server) {
+		srv.asyncLowMessageDuration = duration
+	}
+}
+
+// WithMessageExecStats 通过消息执行耗时统计的方式创建服务器，将按照消息类型及路由或处理函数名称统计执行次数、慢消息次数及耗时分位数
+//   - sampleSize 为每种消息保留的最近执行耗时样本数量，用于计算分位数，<= 0 时将使用 DefaultMessageExecStatsSampleSize
+//   - 统计结果可通过 Server.Stats 获取，启用 WithAdmin 时还可通过 /debug/messages 获取，通过 DELETE 请求 /debug/messages 将在获取后重置统计
+func WithMessageExecStats(sampleSize int) Option {
+	return func(srv *Server) {
+		if sampleSize <= 0 {
+			sampleSize = DefaultMessageExecStatsSampleSize
+		}
+		srv.messageExecStats = newMessageExecStats(sampleSize)
+	}
+}
+
 // WithDisableAsyncMessage 通过禁用异步消息的方式创建服务器
 func WithDisableAsyncMessage() Option {
 	return func(srv *Server) {
@@ -120,6 +274,7 @@ func WithAsyncPoolSize(size int) Option {
 // WithWebsocketReadDeadline 设置 Websocket 读取超时时间
 //   - 默认： DefaultWebsocketReadDeadline
 //   - 当 t <= 0 时，表示不设置超时时间
+//   - 每次读取到消息或接收到 Pong 控制帧时，超时时间将被重新计算，可配合 WithHeartbeat 实现保活
 func WithWebsocketReadDeadline(t time.Duration) Option {
 	return func(srv *Server) {
 		if srv.network != NetworkWebsocket {
@@ -129,6 +284,111 @@ func WithWebsocketReadDeadline(t time.Duration) Option {
 	}
 }
 
+// WithWebsocketPing 通过定时发送 Ping 控制帧的方式创建 Websocket 服务器，以保持连接活跃并检测失效的连接
+//   - 服务器将每隔 interval 向所有 Websocket 连接发送 Ping，接收到 Pong 时将刷新连接的活跃时间及读取超时时间
+//   - 与 WithHeartbeat 不同的是，Ping 将无视连接是否活跃而定时发送，适用于需要穿过会断开空闲连接的代理或负载均衡的场景
+//   - interval 应小于 WithWebsocketReadDeadline 设置的读取超时时间，否则连接将在接收到 Pong 前超时
+func WithWebsocketPing(interval time.Duration) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkWebsocket {
+			return
+		}
+		srv.websocketPingInterval = interval
+	}
+}
+
+// WithWebsocketUpgrader 通过自定义的 websocket.Upgrader 创建 Websocket 服务器
+//   - 可用于控制跨域检查、读写缓冲区大小、握手超时时间、子协议等
+//   - 默认的 Upgrader 读写缓冲区大小均为 4096，且允许所有来源的请求
+//   - 服务器将持有 upgrader 的副本，之后对 upgrader 的修改不会生效，服务器也不会修改 upgrader
+func WithWebsocketUpgrader(upgrader *websocket.Upgrader) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkWebsocket || upgrader == nil {
+			return
+		}
+		clone := *upgrader
+		srv.websocketUpgrader = &clone
+	}
+}
+
+// WithWebsocketCheckOrigin 设置 Websocket 升级时的来源检查函数，当返回 false 时将拒绝升级
+//   - 默认允许所有来源的请求
+//   - 当同时使用 WithWebsocketUpgrader 时，无论选项的顺序如何，均将覆盖其 CheckOrigin 函数
+func WithWebsocketCheckOrigin(checkOrigin func(r *http.Request) bool) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkWebsocket {
+			return
+		}
+		srv.websocketCheckOrigin = checkOrigin
+	}
+}
+
+// WithTrustedProxies 设置可信代理的 IP 或 CIDR，适用于 HTTP、Websocket 及 WebTransport 服务器部署在反向代理之后的情况
+//   - 默认情况下将采用请求的远程地址作为客户端 IP，不信任任何请求头，避免客户端通过伪造请求头绕过连接过滤
+//   - 仅当请求的远程地址属于可信代理时，才会通过 X-Forwarded-For 或 X-Real-IP 请求头获取客户端 IP
+//   - 存在 HTTP 路由器时将同步设置其可信代理，使 gin.Context.ClientIP 的行为保持一致
+//   - 当 proxies 中存在无法解析的地址时将会发生 panic
+func WithTrustedProxies(proxies ...string) Option {
+	return func(srv *Server) {
+		srv.trustedProxies = srv.trustedProxies[:0]
+		for _, proxy := range proxies {
+			if !strings.Contains(proxy, "/") {
+				if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+					proxy += "/32"
+				} else {
+					proxy += "/128"
+				}
+			}
+			_, network, err := net.ParseCIDR(proxy)
+			if err != nil {
+				panic(err)
+			}
+			srv.trustedProxies = append(srv.trustedProxies, network)
+		}
+		if srv.ginServer != nil {
+			if err := srv.ginServer.SetTrustedProxies(proxies); err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+// websocketOriginCheck Websocket 升级时的来源检查函数
+type websocketOriginCheck func(r *http.Request) bool
+
+// newDefaultWebsocketUpgrader 创建默认的 websocket.Upgrader
+func newDefaultWebsocketUpgrader() *websocket.Upgrader {
+	return &websocket.Upgrader{
+		ReadBufferSize:  4096,
+		WriteBufferSize: 4096,
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+}
+
+// WithCross 通过跨服的方式创建服务器
+//   - 推送跨服消息时，将推送到对应 crossName 的跨服中间件中，crossName 可以满足不同功能采用不同的跨服/消息中间件
+//   - 通常情况下 crossName 仅需一个即可
+//   - serverId 为本服 ID，多次使用该选项时将以最后一次的 serverId 为准
+func WithCross(crossName, serverId string, cross Cross) Option {
+	return func(srv *Server) {
+		srv.id = serverId
+		if srv.cross == nil {
+			srv.cross = map[string]Cross{}
+		}
+		srv.cross[crossName] = cross
+	}
+}
+
+// WithCrossDiscovery 通过跨服服务发现创建服务器，用于在 Server.BroadcastCross 及 Server.PushCrossGroupMessage 时解析目标服务器
+//   - 官方实现可参考 server/cluster 包中的 Cluster
+func WithCrossDiscovery(discovery CrossDiscovery) Option {
+	return func(srv *Server) {
+		srv.crossDiscovery = discovery
+	}
+}
+
 // WithTicker 通过定时器创建服务器，为服务器添加定时器功能
 //   - size：服务器定时器时间轮大小
 //   - connSize：服务器连接定时器时间轮大小
@@ -147,18 +407,134 @@ func WithTicker(size, connSize int, autonomy bool) Option {
 	}
 }
 
+// WithCronLocation 设置通过 Server.Cron 设置的调度默认使用的时区，默认为 time.Local
+//   - 适用于服务器所在时区与游戏运营时区不一致的场景，例如每日重置
+func WithCronLocation(loc *time.Location) Option {
+	return func(srv *Server) {
+		srv.cronLocation = loc
+	}
+}
+
 // WithTLS 通过安全传输层协议TLS创建服务器
-//   - 支持：Http、Websocket
+//   - 支持：Http、Websocket、WebTransport、GRPC
+//   - 可与 WithTLSConfig 同时使用，此时证书文件将被追加到 tls.Config 的证书列表中
 func WithTLS(certFile, keyFile string) Option {
 	return func(srv *Server) {
 		switch srv.network {
-		case NetworkHttp, NetworkWebsocket:
+		case NetworkHttp, NetworkWebsocket, NetworkWebTransport, NetworkGRPC:
 			srv.certFile = certFile
 			srv.keyFile = keyFile
 		}
 	}
 }
 
+// WithTLSConfig 通过完整的 tls.Config 创建服务器
+//   - 支持：Http、Websocket、WebTransport、GRPC
+//   - 可通过 ClientCAs 及 ClientAuth 开启客户端证书校验（mTLS），适用于服务器间的双向认证
+//   - 传入的 tls.Config 将被复制后使用，后续对其修改不会生效
+func WithTLSConfig(config *tls.Config) Option {
+	return func(srv *Server) {
+		if config == nil {
+			return
+		}
+		switch srv.network {
+		case NetworkHttp, NetworkWebsocket, NetworkWebTransport, NetworkGRPC:
+			srv.tlsConfig = config.Clone()
+		}
+	}
+}
+
+// WithUnixSocketMode 设置 NetworkUnix 侦听器创建的 socket 文件权限，例如 0660
+//   - 对服务器自身及通过 AddListener 添加的 Unix 侦听器均有效
+//   - 默认情况下 socket 文件的权限由进程的 umask 决定
+func WithUnixSocketMode(mode os.FileMode) Option {
+	return func(srv *Server) {
+		srv.getUnixSocket().mode = mode
+	}
+}
+
+// WithUnixSocketOwner 设置 NetworkUnix 侦听器创建的 socket 文件所有者
+//   - 对服务器自身及通过 AddListener 添加的 Unix 侦听器均有效
+//   - 当 uid 或 gid 为 -1 时表示不修改对应的值
+func WithUnixSocketOwner(uid, gid int) Option {
+	return func(srv *Server) {
+		var us = srv.getUnixSocket()
+		us.uid, us.gid = uid, gid
+	}
+}
+
+// WithUDPSessionTimeout 设置 UDP 虚拟会话的空闲超时时间，默认为 DefaultUDPSessionTimeout
+//   - 支持：Udp、Udp4、Udp6
+//   - UDP 模式下同一远程地址的数据包将被视为同一个连接，当超过 timeout 未接收到该地址的数据包时，连接将以 CloseReasonReadTimeout 关闭
+//   - 当 timeout <= 0 时将使用默认值
+func WithUDPSessionTimeout(timeout time.Duration) Option {
+	return func(srv *Server) {
+		if timeout <= 0 {
+			timeout = DefaultUDPSessionTimeout
+		}
+		srv.udpSessionTimeout = timeout
+	}
+}
+
+// WithAuthHandler 通过连接鉴权的方式创建服务器，连接建立后需要在 timeout 内通过首个数据包完成鉴权
+//   - 连接接收到的首个数据包将交由 handler 进行鉴权，且不会进入 ConnectionReceivePacketEvent
+//   - handler 返回 nil 时表示鉴权通过，此后才会触发 ConnectionOpenedEvent，并且连接才会被计入在线连接
+//   - handler 返回错误或超过 timeout 仍未鉴权时，连接将以 CloseReasonUnauthorized 关闭，此时依旧会触发 ConnectionClosedEvent
+//   - 当 timeout <= 0 时将使用 DefaultAuthTimeout
+func WithAuthHandler(handler AuthHandler, timeout time.Duration) Option {
+	return func(srv *Server) {
+		if timeout <= 0 {
+			timeout = DefaultAuthTimeout
+		}
+		srv.authHandler = handler
+		srv.authTimeout = timeout
+	}
+}
+
+// WithPacketCapture 通过捕获数据包的方式创建服务器，所有连接的入站及出站数据包将被记录到 w 中
+//   - 捕获文件的格式可参考 CaptureMagic，可通过 NewCaptureReader 读取或通过 Replay 回放到服务器中
+//   - 数据将在服务器关闭时写入完毕，当 w 实现了 io.Closer 时将一并关闭
+//   - 捕获将带来额外的 IO 开销，建议仅在测试或问题排查时开启
+func WithPacketCapture(w io.Writer) Option {
+	return func(srv *Server) {
+		if w == nil {
+			return
+		}
+		srv.capture = newPacketCapture(w)
+	}
+}
+
+// WithPacketLogging 通过记录数据包日志的方式创建服务器，将以 INFO 级别记录被采样连接的入站及出站数据包
+//   - sampleRate 为连接的采样率，取值范围为 0 ~ 1，连接是否被采样将在连接建立时决定，也可以通过 Conn.SetPacketLogging 对特定连接进行设置
+//   - maxBytes 为单个数据包最多记录的字节数，超出的部分将被截断，当 maxBytes <= 0 时将记录完整的数据包
+//   - encoding 为数据包内容的编码方式，默认为 PacketLogEncodingHex
+//   - 数据包日志默认开启，可通过 Server.SetPacketLogging 或管理接口 /admin/log/packet 在运行时开启或关闭
+//   - 记录的数据包为解密、解压及解码后的逻辑数据包，与 WithPacketCapture 一致
+func WithPacketLogging(sampleRate float64, maxBytes int, encoding ...PacketLogEncoding) Option {
+	return func(srv *Server) {
+		srv.packetLogging = &packetLogging{
+			sampleRate: sampleRate,
+			maxBytes:   maxBytes,
+			encoding:   PacketLogEncodingHex,
+		}
+		if len(encoding) > 0 && encoding[0] == PacketLogEncodingBase64 {
+			srv.packetLogging.encoding = PacketLogEncodingBase64
+		}
+		srv.packetLogging.enabled.Store(true)
+	}
+}
+
+// WithPacketSequence 通过检查入站数据包序列号的方式创建服务器，用于在可重连的传输中实现至多一次的语义
+//   - 客户端需要在每个数据包头部附加 8 字节大端序的递增序列号，可参考 AppendPacketSequence，序列号将在 ConnectionReceivePacketEvent 前被去除
+//   - 在最近 window 个序列号内重复的数据包及早于该范围的数据包将被视为重放，将触发 ConnectionPacketReplayEvent 并被丢弃
+//   - 序列号的检查在解密、解压及解码之后进行，长度不足以包含序列号的数据包将导致连接以 CloseReasonProtocolError 关闭
+//   - 客户端重连后可通过 Conn.InheritPacketSequence 继承旧连接的序列号窗口
+func WithPacketSequence(window int) Option {
+	return func(srv *Server) {
+		srv.packetSequenceWindow = window
+	}
+}
+
 // WithGRPCServerOptions 通过GRPC的可选项创建GRPC服务器
 func WithGRPCServerOptions(options ...grpc.ServerOption) Option {
 	return func(srv *Server) {
@@ -169,6 +545,56 @@ func WithGRPCServerOptions(options ...grpc.ServerOption) Option {
 	}
 }
 
+// WithGRPCHealth 通过注册 grpc.health.v1 健康检查服务的方式创建GRPC服务器
+//   - 健康检查状态与服务器生命周期绑定，仅在 StartFinishEvent 之后为 SERVING，处于排空状态或开始关闭后为 NOT_SERVING
+//   - 整体状态（空服务名）及所有已注册服务的状态将被同时设置，服务需要在服务器运行前通过 Server.GRPCServer 注册
+func WithGRPCHealth() Option {
+	return func(srv *Server) {
+		if srv.network != NetworkGRPC {
+			return
+		}
+		srv.grpcHealthEnabled = true
+	}
+}
+
+// WithGRPCReflection 通过注册 grpc 反射服务的方式创建GRPC服务器，以便 grpcurl 等工具在不依赖 proto 文件的情况下调用服务
+func WithGRPCReflection() Option {
+	return func(srv *Server) {
+		if srv.network != NetworkGRPC {
+			return
+		}
+		srv.grpcReflection = true
+	}
+}
+
+// WithGRPCGateway 通过挂载 grpc-gateway 生成的处理器的方式创建 HTTP 服务器，使同一服务定义同时提供 gRPC 及 REST 两种访问方式
+//   - gateway 通常为 grpc-gateway 的 runtime.ServeMux，可通过 RegisterXxxHandlerServer 直接调用进程内的服务实现，也可以通过 RegisterXxxHandlerFromEndpoint 转发至 NetworkGRPC 服务器
+//   - 当 prefix 为空时，所有未匹配 gin 路由的请求将交由 gateway 处理；否则仅 prefix 下的请求将交由 gateway 处理，请求路径不会被裁剪
+//   - 请求将经过通过 Server.HttpRouter 注册的中间件，例如 WithHttpRateLimit、WithHttpJWTAuth 等，gateway 将在 Server.Run 时挂载，因此不受中间件的注册顺序影响
+func WithGRPCGateway(gateway http.Handler, prefix ...string) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkHttp || gateway == nil {
+			return
+		}
+		srv.grpcGateway = &grpcGateway{handler: gateway}
+		if len(prefix) > 0 {
+			srv.grpcGateway.prefix = strings.TrimSuffix(prefix[0], "/")
+		}
+	}
+}
+
+// WithWebsocketWriteMessageType 设置向 Websocket 连接写入数据时默认使用的消息类型，仅支持 WebsocketMessageTypeText 及 WebsocketMessageTypeBinary
+//   - 默认为 WebsocketMessageTypeBinary
+//   - 在处理数据包的过程中写入时将沿用接收到的消息类型，可通过 Conn.WriteWithType 指定特定的消息类型
+func WithWebsocketWriteMessageType(messageType int) Option {
+	return func(srv *Server) {
+		switch messageType {
+		case WebsocketMessageTypeText, WebsocketMessageTypeBinary:
+			srv.websocketWriteMessageType = messageType
+		}
+	}
+}
+
 // WithWebsocketMessageType 设置仅支持特定类型的Websocket消息
 func WithWebsocketMessageType(messageTypes ...int) Option {
 	return func(srv *Server) {
@@ -199,6 +625,7 @@ func WithMessageBufferSize(size int) Option {
 }
 
 // WithPProf 通过性能分析工具PProf创建服务器
+//   - 仅支持 NetworkHttp，其他网络类型可通过 WithAdmin 在独立的端口上使用 PProf
 func WithPProf(pattern ...string) Option {
 	return func(srv *Server) {
 		if srv.network != NetworkHttp {
@@ -207,3 +634,237 @@ func WithPProf(pattern ...string) Option {
 		pprof.Register(srv.ginServer, pattern...)
 	}
 }
+
+// WithMetrics 通过 Prometheus 指标创建服务器，将统计连接数量、数据包收发数量及字节数、消息队列深度、消息执行耗时等指标
+//   - namespace：指标命名空间，默认为 DefaultMetricsNamespace
+//   - 当网络类型为 NetworkHttp 时，将自动挂载到 DefaultMetricsPattern 路由，其他网络类型可通过 Server.MetricsHandler 自行挂载
+func WithMetrics(namespace ...string) Option {
+	return func(srv *Server) {
+		var ns = DefaultMetricsNamespace
+		if len(namespace) > 0 {
+			ns = namespace[0]
+		}
+		srv.metrics = newMetrics(srv, ns)
+		if srv.network == NetworkHttp {
+			srv.ginServer.GET(DefaultMetricsPattern, gin.WrapH(srv.MetricsHandler()))
+		}
+	}
+}
+
+// WithAdmin 通过独立端口的管理服务创建服务器，无论服务器的网络类型如何，均会在 addr 上启动 HTTP 服务
+//   - /debug/pprof/：net/http/pprof 性能分析
+//   - /debug/stats：JSON 格式的运行时状态，可参考 RuntimeStats
+//   - /metrics：当通过 WithMetrics 启用指标时可用
+//   - /debug/messages：当通过 WithMessageExecStats 启用消息执行耗时统计时可用，DELETE 请求将在获取后重置统计
+//   - 管理服务不应暴露在公网中
+func WithAdmin(addr string) Option {
+	return func(srv *Server) {
+		srv.adminAddr = addr
+	}
+}
+
+// WithAdminToken 设置管理服务的鉴权令牌，需配合 WithAdmin 使用，设置后管理服务的所有接口均需要通过 "Authorization: Bearer {token}" 请求头或 token 查询参数鉴权
+//   - 仅在设置令牌后才会开放以下管理接口：
+//   - GET /admin/online：在线连接数量
+//   - GET /admin/conns[?id=]：所有在线连接或特定连接的信息
+//   - POST /admin/kick?id=&code=：踢出特定连接，请求体将作为最后一个数据包发送
+//   - POST /admin/broadcast：将请求体作为数据包广播给所有在线连接
+//   - GET|POST /admin/log/level[?level=]：获取或调整日志级别
+//   - POST /admin/drain[?enable=]：设置服务器是否处于排空状态，可参考 Server.SetDraining
+//   - POST /admin/shutdown：关闭服务器
+func WithAdminToken(token string) Option {
+	return func(srv *Server) {
+		srv.adminToken = token
+	}
+}
+
+// WithCodec 通过特定的数据包编解码器创建服务器，用于处理基于流的网络类型中数据包的分包与粘包问题
+//   - 支持：Tcp、Tcp4、Tcp6、Unix、Kcp、WebTransport
+//   - 接收到的数据将在解码为完整的数据包后再进入 OnConnectionReceivePacketEvent，通过 Conn.Write 写入的数据包将在编码后发送
+//   - 内置的编解码器可参考 NewLengthFieldCodec、NewDelimiterCodec、NewLineCodec
+func WithCodec(codec Codec) Option {
+	return func(srv *Server) {
+		switch srv.network {
+		case NetworkTcp, NetworkTcp4, NetworkTcp6, NetworkUnix, NetworkKcp, NetworkWebTransport:
+			srv.codec = codec
+		}
+	}
+}
+
+// WithShard 通过分片分发器的方式创建服务器，使连接消息能够在多个分发器中并行处理
+//   - count：分片分发器数量，通常可设置为 CPU 核心数，当 count <= 1 时将不启用分片
+//   - strategy：消息分片策略，相同分片键的消息将通过一致性哈希始终落在同一个分片中按顺序处理，默认为 ShardByConnID
+//
+// 未通过 UseShunt 指定分流渠道的连接消息将根据分片策略进行分发，系统消息仍将在系统分发器中处理
+//   - 需要注意的是，不同分片中的消息是并行处理的，跨分片访问共享数据时需要自行保证线程安全
+func WithShard(count int, strategy ...ShardStrategy) Option {
+	return func(srv *Server) {
+		if count <= 1 {
+			return
+		}
+		srv.shardCount = count
+		if len(strategy) > 0 && strategy[0] != nil {
+			srv.shardStrategy = strategy[0]
+		} else {
+			srv.shardStrategy = ShardByConnID()
+		}
+	}
+}
+
+// WithConnMailbox 通过连接邮箱的方式创建服务器，每个连接都将拥有独立的消息分发器（邮箱）
+//   - 同一连接的消息将在其邮箱中按顺序处理，不同连接的消息将完全并行处理，类似于 Actor 模型
+//   - 邮箱将在连接收到第一条消息时创建，并在连接关闭时释放
+//   - 通过 UseShunt 指定分流渠道的连接将使用指定的分流渠道，同时设置 WithShard 时将优先使用邮箱
+//   - 需要注意的是，处理不同连接的消息时访问共享数据需要自行保证线程安全
+func WithConnMailbox() Option {
+	return func(srv *Server) {
+		srv.connMailbox = true
+	}
+}
+
+// WithWorkerPool 通过动态工作池的方式创建服务器，连接消息将由数量在 min 到 max 之间动态伸缩的工作协程并行处理
+//   - 相同分片键的消息始终按顺序串行处理，分片键由 WithShard 指定的分片策略决定，默认为 ShardByConnID
+//   - 当消息的平均排队时长超过 latency 时将增加工作协程，当工作协程持续空闲时将逐步减少至 min
+//   - 设置后将替代 WithShard 的固定分片分发器，通过 UseShunt 指定分流渠道或设置 WithConnMailbox 的连接不受影响
+//   - 可通过 Server.GetWorkerPoolStats 获取工作池运行状态
+func WithWorkerPool(min, max int, latency time.Duration) Option {
+	return func(srv *Server) {
+		if max <= 0 {
+			return
+		}
+		if min < 0 {
+			min = 0
+		}
+		if min > max {
+			min = max
+		}
+		if latency <= 0 {
+			latency = time.Millisecond * 100
+		}
+		srv.workerPoolMin, srv.workerPoolMax, srv.workerPoolLatency = min, max, latency
+	}
+}
+
+// WithConnectionFilter 通过连接过滤器的方式创建服务器，对新建立的连接进行 IP 黑白名单及连接数量的限制
+//   - 被拒绝的连接将不会触发 ConnectionOpenedEvent 及 ConnectionClosedEvent，而是触发 ConnectionRejectedEvent
+//   - Websocket 及 WebTransport 将在升级前进行检查，被拒绝时将响应 403 状态码
+//   - 当 Allow 或 Deny 中存在不合法的 IP 或 CIDR 时将引发 panic
+func WithConnectionFilter(filter ConnectionFilter) Option {
+	return func(srv *Server) {
+		srv.connFilter = newConnectionFilter(filter)
+	}
+}
+
+// WithMaxPacketSize 通过限制接收数据包最大长度的方式创建服务器，用于防止恶意的超大数据包耗尽内存
+//   - Websocket 将通过 SetReadLimit 在读取时进行限制，其他网络类型将在接收到数据时进行检查
+//   - 当存在编解码器时，限制作用于解码后的数据包，同时缓冲区中未完整的数据包超出限制时也将被视为超出
+//   - 超出限制的连接将以 CloseReasonProtocolError 被关闭并触发 ConnectionPacketOversizeEvent
+//   - 当 size <= 0 时表示不限制
+func WithMaxPacketSize(size int) Option {
+	return func(srv *Server) {
+		srv.maxPacketSize = size
+	}
+}
+
+// WithPacketBufferPool 通过数据包缓冲池的方式创建服务器，接收的数据包将使用缓冲池中的缓冲区，以降低高频收包时的 GC 压力
+//   - 启用后数据包仅在处理该数据包的事件中有效，事件处理完成后缓冲区将被复用
+//   - 需要在异步任务中使用数据包时，应通过 Conn.RetainPacket 延长数据包的生命周期，或通过 bytes.Clone 复制数据包
+//   - 通过 PushPacketMessage 推送的数据包不受影响
+func WithPacketBufferPool() Option {
+	return func(srv *Server) {
+		srv.packetBuffers = new(packetBufferPool)
+	}
+}
+
+// WithWriteCoalescing 通过合并出站数据包的方式创建服务器，每个连接的多个小数据包将被合并后通过一次写入发送，以减少系统调用次数
+//   - 合并后的数据达到 size 字节或距离首个未发送的数据包超过 interval 时将进行写入，默认值为 DefaultWriteCoalesceSize 及 DefaultWriteCoalesceInterval
+//   - 适用于 TCP、Unix、KCP 及 WebTransport 等基于流的网络类型，Websocket 及 UDP 的数据包具有边界，不会进行合并
+//   - 数据包的写入回调将在实际写入后被调用，连接关闭时尚未写入的数据将被丢弃，Conn.Kick 将在合并的数据写入后再关闭连接
+//   - 由于数据包将被合并，客户端应通过 WithCodec 等方式处理粘包问题
+func WithWriteCoalescing(interval time.Duration, size int) Option {
+	return func(srv *Server) {
+		if interval <= 0 {
+			interval = DefaultWriteCoalesceInterval
+		}
+		if size <= 0 {
+			size = DefaultWriteCoalesceSize
+		}
+		srv.writeCoalesce = &writeCoalesce{interval: interval, size: size}
+	}
+}
+
+// WithPacketRateLimit 通过令牌桶的方式限制每个连接每秒接收的数据包数量
+//   - limit 为每秒允许接收的数据包数量，burst 为允许突发的数据包数量
+//   - action 为超出限制时的处理方式，超出限制时将触发 ConnectionRateLimitedEvent
+//   - 当存在编解码器时，限制作用于解码后的数据包
+func WithPacketRateLimit(limit rate.Limit, burst int, action PacketRateLimitAction) Option {
+	return func(srv *Server) {
+		srv.packetRateLimit = &packetRateLimit{limit: limit, burst: burst, action: action}
+	}
+}
+
+// WithPacketByteRateLimit 通过令牌桶的方式限制每个连接每秒接收的数据包字节数
+//   - limit 为每秒允许接收的字节数，burst 为允许突发的字节数，大于 burst 的数据包将始终超出限制
+//   - action 为超出限制时的处理方式，超出限制时将触发 ConnectionRateLimitedEvent
+//   - 可与 WithPacketRateLimit 同时使用
+func WithPacketByteRateLimit(limit rate.Limit, burst int, action PacketRateLimitAction) Option {
+	return func(srv *Server) {
+		srv.packetByteRateLimit = &packetRateLimit{limit: limit, burst: burst, action: action}
+	}
+}
+
+// WithPacketCompression 通过数据包压缩的方式创建服务器，长度超过 threshold 的出站数据包将使用 algorithm 进行压缩
+//   - 启用后所有数据包都将携带 1 字节的数据包头：| header(1) | packet |，header 为 0 时表示未压缩，否则为压缩所使用的 CompressionAlgorithm
+//   - 入站数据包将根据数据包头进行解压，客户端可自行选择是否压缩及所使用的算法，OnConnectionReceivePacketEvent 接收到的始终为解压后的数据包
+//   - 当存在编解码器时，压缩将在编码前进行，解压将在解码后进行
+//   - 入站数据包的数据包头不合法或解压失败时，连接将以 CloseReasonProtocolError 被关闭
+func WithPacketCompression(threshold int, algorithm CompressionAlgorithm) Option {
+	return func(srv *Server) {
+		srv.compression = newPacketCompression(threshold, algorithm)
+	}
+}
+
+// WithPacketCrypto 通过数据包加密的方式创建服务器，连接建立后将通过 X25519 密钥交换协商密钥，并使用 algorithm 对数据包进行加密
+//   - 客户端在连接建立后需要首先发送 32 字节的 X25519 公钥，服务器将回复 32 字节的服务器公钥，该握手数据包不会被加密，也不会触发 ConnectionReceivePacketEvent
+//   - 双方通过 DerivePacketCryptoKeys 从共享密钥中派生两个方向各自的加密密钥，此后所有数据包的格式为 | nonce | ciphertext |
+//   - nonce 通过 PacketCryptoNonce 生成，每个方向的序号均从 1 开始逐个递增，序号未严格递增的入站数据包将被视为重放而被拒绝
+//   - 握手完成前写入的数据包将被暂存，并在握手完成后按顺序加密发送
+//   - 当存在编解码器时，加密将在编码前进行，解密将在解码后进行；当存在压缩时，将先压缩后加密
+//   - 握手失败或数据包解密失败时，连接将以 CloseReasonProtocolError 被关闭
+func WithPacketCrypto(algorithm CryptoAlgorithm) Option {
+	return func(srv *Server) {
+		if _, exist := cryptoAlgorithmNames[algorithm]; !exist {
+			panic(fmt.Errorf("crypto: unsupported algorithm %d", algorithm))
+		}
+		srv.packetCrypto = algorithm
+	}
+}
+
+// WithKcpConfig 通过特定的 KCP 调优配置创建 KCP 服务器
+//   - 默认情况下 KCP 采用普通模式且不启用 FEC 及加密，对于延迟敏感的游戏可参考 NewKcpFastConfig
+//   - 客户端需要采用相同的 FEC 及加密配置，否则将无法正常通讯
+func WithKcpConfig(config *KcpConfig) Option {
+	return func(srv *Server) {
+		if srv.network != NetworkKcp {
+			return
+		}
+		srv.kcpConfig = config
+	}
+}
+
+// WithHeartbeat 通过心跳检测的方式创建服务器，用于检测并清理空闲或已失效的连接
+//   - interval：连接空闲超过该时长时将向其发送心跳，同时作为检测的周期
+//   - timeout：连接空闲超过该时长时将触发 ConnectionIdleEvent，随后连接将以 ErrConnectionHeartbeatTimeout 被关闭
+//   - packet：非 Websocket 连接发送的心跳数据包，为空时将不发送心跳，仅进行空闲检测；Websocket 连接将发送 Ping 控制帧
+//
+// 连接建立、接收到数据包以及 Websocket 接收到 Pong 时均视为连接活跃
+func WithHeartbeat(interval, timeout time.Duration, packet []byte) Option {
+	return func(srv *Server) {
+		if interval <= 0 || timeout <= 0 {
+			return
+		}
+		srv.heartbeatInterval = interval
+		srv.heartbeatTimeout = timeout
+		srv.heartbeatPacket = packet
+	}
+}
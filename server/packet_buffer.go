@@ -0,0 +1,119 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/bits"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	packetBufferMinShift = 6  // 缓冲池中最小的缓冲区容量为 64B
+	packetBufferClasses  = 11 // 缓冲池中最大的缓冲区容量为 64KB，更大的数据包将不经过缓冲池
+)
+
+// packetBufferPool 按容量分级的数据包缓冲池
+type packetBufferPool struct {
+	pools [packetBufferClasses]sync.Pool
+}
+
+// get 获取一个长度为 size 的缓冲区，缓冲区的引用计数为 1
+func (slf *packetBufferPool) get(size int) *packetBuffer {
+	class := -1
+	if size <= 1<<packetBufferMinShift {
+		class = 0
+	} else if c := bits.Len(uint(size-1)) - packetBufferMinShift; c < packetBufferClasses {
+		class = c
+	}
+	var buffer *packetBuffer
+	if class < 0 {
+		buffer = &packetBuffer{data: make([]byte, size), class: class}
+	} else if v := slf.pools[class].Get(); v != nil {
+		buffer = v.(*packetBuffer)
+		buffer.data = buffer.data[:size]
+	} else {
+		buffer = &packetBuffer{data: make([]byte, size, 1<<(class+packetBufferMinShift)), class: class, pool: slf}
+	}
+	buffer.refs.Store(1)
+	return buffer
+}
+
+// clone 将数据复制到缓冲池中的缓冲区，当未启用缓冲池时将直接复制数据
+func (slf *packetBufferPool) clone(data []byte) ([]byte, *packetBuffer) {
+	if slf == nil {
+		return bytes.Clone(data), nil
+	}
+	buffer := slf.get(len(data))
+	copy(buffer.data, data)
+	return buffer.data, buffer
+}
+
+// readFrom 将 r 中的全部数据读取到缓冲池中的缓冲区
+func (slf *packetBufferPool) readFrom(r io.Reader) (*packetBuffer, error) {
+	buffer := slf.get(1 << packetBufferMinShift)
+	var n int
+	for {
+		if n == cap(buffer.data) {
+			grown := slf.get(2 * n)
+			copy(grown.data, buffer.data[:n])
+			buffer.release()
+			buffer = grown
+		}
+		m, err := r.Read(buffer.data[n:cap(buffer.data)])
+		n += m
+		if err != nil {
+			buffer.data = buffer.data[:n]
+			if errors.Is(err, io.EOF) {
+				return buffer, nil
+			}
+			buffer.release()
+			return nil, err
+		}
+	}
+}
+
+// packetBuffer 来自缓冲池的数据包缓冲区，引用计数归零后将被归还缓冲池
+type packetBuffer struct {
+	data  []byte
+	refs  atomic.Int32
+	class int
+	pool  *packetBufferPool
+}
+
+// retain 增加缓冲区的引用计数
+func (slf *packetBuffer) retain() {
+	if slf.refs.Add(1) <= 1 {
+		panic(errors.New("server: packet buffer has already been released"))
+	}
+}
+
+// release 减少缓冲区的引用计数，归零后将缓冲区归还缓冲池
+func (slf *packetBuffer) release() {
+	if slf == nil {
+		return
+	}
+	switch refs := slf.refs.Add(-1); {
+	case refs < 0:
+		panic(errors.New("server: packet buffer released too many times"))
+	case refs == 0 && slf.pool != nil:
+		slf.pool.pools[slf.class].Put(slf)
+	}
+}
+
+// RetainPacket 延长当前数据包的生命周期，返回的 release 函数被调用前数据包所在的缓冲区不会被复用
+//   - 仅在通过 WithPacketBufferPool 启用数据包缓冲池时生效，否则将返回一个空函数
+//   - 需要在 ConnectionReceivePacketEvent 等处理数据包的事件中同步调用，release 必须且只能被调用一次
+//   - 适用于需要在异步任务中使用数据包的场景，也可以通过 bytes.Clone 复制数据包以达到相同的目的
+func (slf *Conn) RetainPacket() (release func()) {
+	buffer := slf.buffer
+	if buffer == nil {
+		return func() {}
+	}
+	buffer.retain()
+	var once sync.Once
+	return func() {
+		once.Do(buffer.release)
+	}
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPacketBufferPool_Get(t *testing.T) {
+	var pool packetBufferPool
+	for _, c := range []struct {
+		size, cap int
+	}{{0, 64}, {1, 64}, {64, 64}, {65, 128}, {1000, 1024}, {65536, 65536}, {65537, 65537}} {
+		buffer := pool.get(c.size)
+		if len(buffer.data) != c.size || cap(buffer.data) != c.cap {
+			t.Fatalf("size %d: expected len %d cap %d, got len %d cap %d", c.size, c.size, c.cap, len(buffer.data), cap(buffer.data))
+		}
+		buffer.release()
+	}
+}
+
+func TestPacketBuffer_Retain(t *testing.T) {
+	var pool packetBufferPool
+	buffer := pool.get(10)
+	buffer.retain()
+	buffer.release()
+	if buffer.refs.Load() != 1 {
+		t.Fatalf("expected 1 reference, got %d", buffer.refs.Load())
+	}
+	buffer.release()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic when retaining a released buffer")
+		}
+	}()
+	buffer.retain()
+}
+
+func TestPacketBufferPool_ReadFrom(t *testing.T) {
+	var pool packetBufferPool
+	data := []byte(strings.Repeat("minotaur", 100))
+	buffer, err := pool.readFrom(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buffer.release()
+	if !bytes.Equal(buffer.data, data) {
+		t.Fatal("read data mismatch")
+	}
+}
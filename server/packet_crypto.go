@@ -0,0 +1,187 @@
+package server
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+	"io"
+	"sync"
+)
+
+const (
+	CryptoAES256GCM        CryptoAlgorithm = iota + 1 // AES-256-GCM 加密算法，在支持 AES 指令集的平台上性能最佳
+	CryptoChaCha20Poly1305                            // ChaCha20-Poly1305 加密算法，适用于不支持 AES 指令集的平台
+)
+
+const (
+	packetCryptoClientKeyInfo = "minotaur packet crypto client" // 派生客户端至服务器方向密钥时所使用的上下文信息
+	packetCryptoServerKeyInfo = "minotaur packet crypto server" // 派生服务器至客户端方向密钥时所使用的上下文信息
+	packetCryptoNonceSize     = 12                              // AES-256-GCM 及 ChaCha20-Poly1305 的 nonce 长度
+)
+
+var cryptoAlgorithmNames = map[CryptoAlgorithm]string{
+	CryptoAES256GCM:        "AES256GCM",
+	CryptoChaCha20Poly1305: "ChaCha20Poly1305",
+}
+
+// CryptoAlgorithm 数据包加密算法
+type CryptoAlgorithm byte
+
+// String 返回加密算法的字符串表示
+func (slf CryptoAlgorithm) String() string {
+	return cryptoAlgorithmNames[slf]
+}
+
+// newAEAD 根据加密算法及密钥创建 AEAD 加密器
+func (slf CryptoAlgorithm) newAEAD(key []byte) (cipher.AEAD, error) {
+	switch slf {
+	case CryptoAES256GCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case CryptoChaCha20Poly1305:
+		return chacha20poly1305.New(key)
+	default:
+		return nil, fmt.Errorf("crypto: unsupported algorithm %d", slf)
+	}
+}
+
+// DerivePacketCryptoKeys 通过 ECDH 协商出的共享密钥派生数据包加密密钥，客户端需要采用相同的方式派生密钥
+//   - clientKey 用于加密客户端发往服务器的数据包，serverKey 用于加密服务器发往客户端的数据包，避免数据包被原样反射回发送方
+//   - 派生方式为 HKDF-SHA256，salt 为空，长度为 32 字节，info 分别为 "minotaur packet crypto client" 及 "minotaur packet crypto server"
+func DerivePacketCryptoKeys(secret []byte) (clientKey, serverKey []byte, err error) {
+	if clientKey, err = derivePacketCryptoKey(secret, packetCryptoClientKeyInfo); err != nil {
+		return nil, nil, err
+	}
+	if serverKey, err = derivePacketCryptoKey(secret, packetCryptoServerKeyInfo); err != nil {
+		return nil, nil, err
+	}
+	return clientKey, serverKey, nil
+}
+
+// derivePacketCryptoKey 通过 HKDF-SHA256 从共享密钥中派生特定用途的 32 字节密钥
+func derivePacketCryptoKey(secret []byte, info string) ([]byte, error) {
+	var key = make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, []byte(info)), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// PacketCryptoNonce 生成序号为 seq 的数据包所使用的 nonce，格式为 4 字节的 0 及 8 字节大端序的序号
+//   - 每个方向的序号均从 1 开始逐个递增，客户端需要采用相同的方式生成 nonce
+func PacketCryptoNonce(seq uint64) []byte {
+	var nonce = make([]byte, packetCryptoNonceSize)
+	binary.BigEndian.PutUint64(nonce[packetCryptoNonceSize-8:], seq)
+	return nonce
+}
+
+// newPacketCryptoSession 创建连接的数据包加密会话，将生成用于密钥交换的 X25519 密钥对
+func newPacketCryptoSession(algorithm CryptoAlgorithm) (*packetCryptoSession, error) {
+	privateKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &packetCryptoSession{algorithm: algorithm, privateKey: privateKey}, nil
+}
+
+// packetCryptoSession 连接的数据包加密会话
+//   - 握手完成前写入的数据包将被暂存，并在握手完成后按顺序加密写入
+//   - 加密后的数据包格式为 | nonce | ciphertext |，nonce 中包含每个方向独立递增的序号
+type packetCryptoSession struct {
+	algorithm  CryptoAlgorithm
+	privateKey *ecdh.PrivateKey
+	opener     cipher.AEAD // 解密客户端数据包
+	sealer     cipher.AEAD // 加密服务器数据包
+	sendSeq    uint64      // 最后一个发送的数据包序号
+	recvSeq    uint64      // 最后一个接收的数据包序号
+	pending    []*connPacket
+	mu         sync.Mutex
+}
+
+// handshake 根据客户端的公钥完成密钥交换，并将服务器公钥及握手期间暂存的数据包写入连接
+func (slf *packetCryptoSession) handshake(conn *Conn, peer []byte) error {
+	publicKey, err := ecdh.X25519().NewPublicKey(peer)
+	if err != nil {
+		return ErrCryptoHandshakeFailed
+	}
+	secret, err := slf.privateKey.ECDH(publicKey)
+	if err != nil {
+		return ErrCryptoHandshakeFailed
+	}
+	clientKey, serverKey, err := DerivePacketCryptoKeys(secret)
+	if err != nil {
+		return err
+	}
+	opener, err := slf.algorithm.newAEAD(clientKey)
+	if err != nil {
+		return err
+	}
+	sealer, err := slf.algorithm.newAEAD(serverKey)
+	if err != nil {
+		return err
+	}
+
+	slf.mu.Lock()
+	defer slf.mu.Unlock()
+	slf.opener, slf.sealer = opener, sealer
+	conn.write(slf.privateKey.PublicKey().Bytes(), nil)
+	for _, cp := range slf.pending {
+		(&Conn{wst: cp.wst, connection: conn.connection}).write(slf.seal(cp.packet), cp.callback)
+	}
+	slf.pending = nil
+	return nil
+}
+
+// write 加密数据包并写入连接，握手完成前将暂存数据包
+func (slf *packetCryptoSession) write(conn *Conn, packet []byte, callback func(err error)) {
+	slf.mu.Lock()
+	defer slf.mu.Unlock()
+	if slf.sealer == nil {
+		slf.pending = append(slf.pending, &connPacket{wst: conn.GetWST(), packet: packet, callback: callback})
+		return
+	}
+	conn.write(slf.seal(packet), callback)
+}
+
+// receive 处理来自客户端的数据包，当数据包为握手数据包时 handshake 将返回 true
+//   - 序号未严格递增的数据包将被视为重放的数据包而被拒绝
+func (slf *packetCryptoSession) receive(conn *Conn, packet []byte) (result []byte, handshake bool, err error) {
+	slf.mu.Lock()
+	opener := slf.opener
+	slf.mu.Unlock()
+	if opener == nil {
+		return nil, true, slf.handshake(conn, packet)
+	}
+	if len(packet) < packetCryptoNonceSize+opener.Overhead() {
+		return nil, false, ErrCryptoInvalidPacket
+	}
+	nonce := packet[:packetCryptoNonceSize]
+	if result, err = opener.Open(nil, nonce, packet[packetCryptoNonceSize:], nil); err != nil {
+		return nil, false, ErrCryptoInvalidPacket
+	}
+	slf.mu.Lock()
+	defer slf.mu.Unlock()
+	seq := binary.BigEndian.Uint64(nonce[packetCryptoNonceSize-8:])
+	if seq <= slf.recvSeq {
+		return nil, false, ErrCryptoInvalidPacket
+	}
+	slf.recvSeq = seq
+	return result, false, nil
+}
+
+// seal 使用下一个序号作为 nonce 加密数据包，调用方需要持有锁
+func (slf *packetCryptoSession) seal(packet []byte) []byte {
+	slf.sendSeq++
+	var nonce = PacketCryptoNonce(slf.sendSeq)
+	var result = make([]byte, 0, len(nonce)+len(packet)+slf.sealer.Overhead())
+	return slf.sealer.Seal(append(result, nonce...), nonce, packet, nil)
+}
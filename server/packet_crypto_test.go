@@ -0,0 +1,92 @@
+package server
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/rand"
+	"io"
+	"testing"
+	"time"
+)
+
+type packetCryptoTestWriter chan []byte
+
+func (slf packetCryptoTestWriter) Write(p []byte) (int, error) {
+	slf <- bytes.Clone(p)
+	return len(p), nil
+}
+
+func TestPacketCryptoSession(t *testing.T) {
+	for _, algorithm := range []CryptoAlgorithm{CryptoAES256GCM, CryptoChaCha20Poly1305} {
+		var writer = make(packetCryptoTestWriter, 8)
+		conn := newBotConn(New(NetworkWebsocket))
+		var w io.Writer = writer
+		conn.delay = time.Nanosecond
+		conn.botWriter.Store(&w)
+		session, err := newPacketCryptoSession(algorithm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.crypto = session
+
+		conn.Write([]byte("before handshake"))
+		client, _ := ecdh.X25519().GenerateKey(rand.Reader)
+		if _, handshake, err := session.receive(conn, client.PublicKey().Bytes()); err != nil || !handshake {
+			t.Fatalf("%s: handshake failed: %v", algorithm, err)
+		}
+		serverPublicKey, _ := ecdh.X25519().NewPublicKey(<-writer)
+		secret, _ := client.ECDH(serverPublicKey)
+		clientKey, serverKey, _ := DerivePacketCryptoKeys(secret)
+		sealer, _ := algorithm.newAEAD(clientKey)
+		opener, _ := algorithm.newAEAD(serverKey)
+
+		sealed := <-writer
+		if !bytes.Equal(sealed[:packetCryptoNonceSize], PacketCryptoNonce(1)) {
+			t.Fatalf("%s: expected the first server packet to use sequence 1", algorithm)
+		}
+		packet, err := opener.Open(nil, sealed[:packetCryptoNonceSize], sealed[packetCryptoNonceSize:], nil)
+		if err != nil || string(packet) != "before handshake" {
+			t.Fatalf("%s: unexpected pending packet %q: %v", algorithm, packet, err)
+		}
+
+		// 服务器发出的数据包被原样反射回服务器时，由于两个方向的密钥不同而无法解密
+		if _, _, err := session.receive(conn, sealed); err != ErrCryptoInvalidPacket {
+			t.Fatalf("%s: expected reflected packet to be rejected, got %v", algorithm, err)
+		}
+
+		var seal = func(seq uint64, packet string) []byte {
+			nonce := PacketCryptoNonce(seq)
+			return sealer.Seal(nonce, nonce, []byte(packet), nil)
+		}
+		sealed = seal(1, "hello")
+		if packet, handshake, err := session.receive(conn, sealed); err != nil || handshake || string(packet) != "hello" {
+			t.Fatalf("%s: unexpected packet %q: %v", algorithm, packet, err)
+		}
+		if _, _, err := session.receive(conn, sealed); err != ErrCryptoInvalidPacket {
+			t.Fatalf("%s: expected replayed packet to be rejected, got %v", algorithm, err)
+		}
+		if packet, _, err := session.receive(conn, seal(3, "skip")); err != nil || string(packet) != "skip" {
+			t.Fatalf("%s: unexpected packet %q: %v", algorithm, packet, err)
+		}
+		if _, _, err := session.receive(conn, seal(2, "late")); err != ErrCryptoInvalidPacket {
+			t.Fatalf("%s: expected out of order packet to be rejected, got %v", algorithm, err)
+		}
+		sealed = seal(4, "tampered")
+		sealed[len(sealed)-1] ^= 0xFF
+		if _, _, err := session.receive(conn, sealed); err != ErrCryptoInvalidPacket {
+			t.Fatalf("%s: expected ErrCryptoInvalidPacket, got %v", algorithm, err)
+		}
+		if packet, _, err := session.receive(conn, seal(4, "after tampered")); err != nil || string(packet) != "after tampered" {
+			t.Fatalf("%s: tampered packet should not advance the sequence, got %q: %v", algorithm, packet, err)
+		}
+
+		conn.Write([]byte("after handshake"))
+		sealed = <-writer
+		if !bytes.Equal(sealed[:packetCryptoNonceSize], PacketCryptoNonce(2)) {
+			t.Fatalf("%s: expected the second server packet to use sequence 2", algorithm)
+		}
+		if packet, err = opener.Open(nil, sealed[:packetCryptoNonceSize], sealed[packetCryptoNonceSize:], nil); err != nil || string(packet) != "after handshake" {
+			t.Fatalf("%s: unexpected packet %q: %v", algorithm, packet, err)
+		}
+	}
+}
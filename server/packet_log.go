@@ -0,0 +1,96 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/kercylan98/minotaur/utils/log"
+	"github.com/kercylan98/minotaur/utils/random"
+	"sync/atomic"
+)
+
+const (
+	PacketLogEncodingHex    PacketLogEncoding = iota + 1 // 十六进制
+	PacketLogEncodingBase64                              // Base64
+)
+
+var packetLogEncodingNames = map[PacketLogEncoding]string{
+	PacketLogEncodingHex:    "Hex",
+	PacketLogEncodingBase64: "Base64",
+}
+
+// PacketLogEncoding 数据包日志中数据包内容的编码方式
+type PacketLogEncoding byte
+
+// String 返回编码方式的字符串表示
+func (slf PacketLogEncoding) String() string {
+	return packetLogEncodingNames[slf]
+}
+
+// packetLogging 数据包日志配置
+type packetLogging struct {
+	sampleRate float64           // 连接的采样率
+	maxBytes   int               // 单个数据包最多记录的字节数
+	encoding   PacketLogEncoding // 数据包内容的编码方式
+	enabled    atomic.Bool       // 是否开启
+}
+
+// sample 判断新的连接是否被采样
+func (slf *packetLogging) sample() bool {
+	if slf == nil || slf.sampleRate <= 0 {
+		return false
+	}
+	return slf.sampleRate >= 1 || random.Float64() < slf.sampleRate
+}
+
+// format 编码数据包内容，超出 maxBytes 的部分将被截断
+func (slf *packetLogging) format(packet []byte) (content string, truncated bool) {
+	if slf.maxBytes > 0 && len(packet) > slf.maxBytes {
+		packet, truncated = packet[:slf.maxBytes], true
+	}
+	switch slf.encoding {
+	case PacketLogEncodingBase64:
+		content = base64.StdEncoding.EncodeToString(packet)
+	default:
+		content = hex.EncodeToString(packet)
+	}
+	return
+}
+
+// record 当连接被采样时记录数据包日志
+func (slf *packetLogging) record(direction CaptureDirection, conn *Conn, packet []byte) {
+	if slf == nil || !slf.enabled.Load() || !conn.packetLogged.Load() {
+		return
+	}
+	content, truncated := slf.format(packet)
+	conn.server.Logger().Info("PacketLog",
+		log.String("Direction", direction.String()),
+		log.String("ConnID", conn.GetID()),
+		log.String("IP", conn.GetIP()),
+		log.Int("Size", len(packet)),
+		log.String(slf.encoding.String(), content),
+		log.Bool("Truncated", truncated),
+	)
+}
+
+// SetPacketLogging 在运行时开启或关闭数据包日志，仅在通过 WithPacketLogging 启用数据包日志时生效
+func (slf *Server) SetPacketLogging(enable bool) {
+	if slf.packetLogging != nil {
+		slf.packetLogging.enabled.Store(enable)
+	}
+}
+
+// IsPacketLogging 检查数据包日志是否处于开启状态
+func (slf *Server) IsPacketLogging() bool {
+	return slf.packetLogging != nil && slf.packetLogging.enabled.Load()
+}
+
+// SetPacketLogging 设置是否记录该连接的数据包日志，可用于对未被采样的特定连接进行问题排查
+//   - 仅在通过 WithPacketLogging 启用数据包日志且数据包日志处于开启状态时生效
+func (slf *Conn) SetPacketLogging(enable bool) {
+	slf.packetLogged.Store(enable)
+}
+
+// IsPacketLogging 检查该连接是否记录数据包日志
+func (slf *Conn) IsPacketLogging() bool {
+	return slf.packetLogged.Load()
+}
@@ -0,0 +1,31 @@
+package server
+
+import "testing"
+
+func TestPacketLogging_Format(t *testing.T) {
+	logging := &packetLogging{maxBytes: 2, encoding: PacketLogEncodingHex}
+	if content, truncated := logging.format([]byte{0x01, 0xab, 0xff}); content != "01ab" || !truncated {
+		t.Fatalf("unexpected hex content: %s, truncated: %v", content, truncated)
+	}
+	logging.maxBytes, logging.encoding = 0, PacketLogEncodingBase64
+	if content, truncated := logging.format([]byte("hello")); content != "aGVsbG8=" || truncated {
+		t.Fatalf("unexpected base64 content: %s, truncated: %v", content, truncated)
+	}
+}
+
+func TestWithPacketLogging(t *testing.T) {
+	if New(NetworkNone, WithPacketLogging(0, 0)).packetLogging.sample() {
+		t.Fatal("expected no connection to be sampled when the sample rate is 0")
+	}
+	srv := New(NetworkNone, WithPacketLogging(1, 0))
+	if !srv.packetLogging.sample() || !srv.IsPacketLogging() {
+		t.Fatal("expected every connection to be sampled and packet logging to be enabled")
+	}
+	srv.SetPacketLogging(false)
+	if srv.IsPacketLogging() {
+		t.Fatal("expected packet logging to be disabled at runtime")
+	}
+	if New(NetworkNone).IsPacketLogging() {
+		t.Fatal("expected packet logging to be disabled by default")
+	}
+}
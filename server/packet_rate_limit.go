@@ -0,0 +1,64 @@
+package server
+
+import (
+	"golang.org/x/time/rate"
+	"time"
+)
+
+const (
+	PacketRateLimitActionDrop       PacketRateLimitAction = iota + 1 // 丢弃超出限制的数据包
+	PacketRateLimitActionDisconnect                                  // 断开超出限制的连接
+)
+
+var packetRateLimitActionNames = map[PacketRateLimitAction]string{
+	PacketRateLimitActionDrop:       "Drop",
+	PacketRateLimitActionDisconnect: "Disconnect",
+}
+
+// PacketRateLimitAction 连接接收数据包超出速率限制时的处理方式
+type PacketRateLimitAction byte
+
+// String 返回处理方式的字符串表示
+func (slf PacketRateLimitAction) String() string {
+	return packetRateLimitActionNames[slf]
+}
+
+// packetRateLimit 数据包速率限制配置
+type packetRateLimit struct {
+	limit  rate.Limit
+	burst  int
+	action PacketRateLimitAction
+}
+
+// newLimiter 创建一个令牌桶限流器，当未进行配置时返回 nil
+func (slf *packetRateLimit) newLimiter() *rate.Limiter {
+	if slf == nil {
+		return nil
+	}
+	return rate.NewLimiter(slf.limit, slf.burst)
+}
+
+// allowPacket 检查连接接收的数据包是否符合 WithPacketRateLimit 及 WithPacketByteRateLimit 的速率限制
+//   - 超出限制时将触发 ConnectionRateLimitedEvent，同一连接每秒最多触发一次
+func (slf *Server) allowPacket(conn *Conn, packet []byte) bool {
+	if conn.IsBot() || (conn.packetLimiter == nil && conn.byteLimiter == nil) {
+		return true
+	}
+	var action PacketRateLimitAction
+	now := time.Now()
+	if conn.packetLimiter != nil && !conn.packetLimiter.AllowN(now, 1) {
+		action = slf.packetRateLimit.action
+	} else if conn.byteLimiter != nil && !conn.byteLimiter.AllowN(now, len(packet)) {
+		action = slf.packetByteRateLimit.action
+	} else {
+		return true
+	}
+
+	if last := conn.rateLimitedAt.Load(); now.Unix() > last && conn.rateLimitedAt.CompareAndSwap(last, now.Unix()) {
+		slf.OnConnectionRateLimitedEvent(conn, action)
+	}
+	if action == PacketRateLimitActionDisconnect {
+		conn.CloseWithReason(CloseReasonRateLimited, ErrConnectionRateLimited)
+	}
+	return false
+}
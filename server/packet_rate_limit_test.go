@@ -0,0 +1,27 @@
+package server
+
+import (
+	"github.com/kercylan98/minotaur/utils/concurrent"
+	"testing"
+)
+
+func TestServer_AllowPacket(t *testing.T) {
+	srv := New(NetworkWebsocket,
+		WithPacketRateLimit(1, 2, PacketRateLimitActionDrop),
+		WithPacketByteRateLimit(1, 8, PacketRateLimitActionDrop),
+	)
+	srv.messagePool = concurrent.NewPool[*Message](0, func() *Message { return new(Message) }, func(data *Message) { data.reset() })
+	conn := &Conn{connection: &connection{server: srv, gw: func(packet []byte) {}}}
+	conn.packetLimiter = srv.packetRateLimit.newLimiter()
+	conn.byteLimiter = srv.packetByteRateLimit.newLimiter()
+
+	if !srv.allowPacket(conn, []byte("1234")) {
+		t.Fatal("expected the first packet to be allowed")
+	}
+	if srv.allowPacket(conn, []byte("123456789")) {
+		t.Fatal("expected a packet larger than the byte burst to be dropped")
+	}
+	if srv.allowPacket(conn, []byte("1")) {
+		t.Fatal("expected the third packet to exceed the packet burst")
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/binary"
+	"sync"
+)
+
+// PacketSequenceSize 启用 WithPacketSequence 后，入站数据包头部的序列号长度
+const PacketSequenceSize = 8
+
+// AppendPacketSequence 在数据包头部添加序列号，适用于启用 WithPacketSequence 时的客户端或测试
+//   - 序列号以 8 字节大端序编码
+func AppendPacketSequence(seq uint64, packet []byte) []byte {
+	result := make([]byte, PacketSequenceSize, PacketSequenceSize+len(packet))
+	binary.BigEndian.PutUint64(result, seq)
+	return append(result, packet...)
+}
+
+// newPacketSequence 创建大小为 size 的序列号滑动窗口，size 将被向上取整为 64 的倍数
+func newPacketSequence(size int) *packetSequence {
+	words := (size + 63) / 64
+	return &packetSequence{size: uint64(words * 64), bits: make([]uint64, words)}
+}
+
+// packetSequence 连接入站数据包的序列号滑动窗口，用于拒绝重复或重放的数据包
+//   - 窗口内已接收的序列号及早于窗口的序列号均将被拒绝
+type packetSequence struct {
+	lock sync.Mutex
+	size uint64
+	max  uint64
+	seen bool
+	bits []uint64
+}
+
+// accept 检查序列号是否可以被接收，可以接收时将记录该序列号
+func (slf *packetSequence) accept(seq uint64) bool {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	switch {
+	case !slf.seen:
+		slf.seen, slf.max = true, seq
+	case seq > slf.max:
+		if seq-slf.max >= slf.size {
+			clear(slf.bits)
+		} else {
+			for s := slf.max + 1; s < seq; s++ {
+				slf.unset(s)
+			}
+		}
+		slf.max = seq
+	case slf.max-seq >= slf.size || slf.test(seq):
+		return false
+	}
+	slf.bits[(seq%slf.size)/64] |= 1 << (seq % 64)
+	return true
+}
+
+func (slf *packetSequence) test(seq uint64) bool {
+	return slf.bits[(seq%slf.size)/64]&(1<<(seq%64)) != 0
+}
+
+func (slf *packetSequence) unset(seq uint64) {
+	slf.bits[(seq%slf.size)/64] &^= 1 << (seq % 64)
+}
+
+// last 获取已接收的最大序列号
+func (slf *packetSequence) last() (seq uint64, ok bool) {
+	slf.lock.Lock()
+	defer slf.lock.Unlock()
+	return slf.max, slf.seen
+}
+
+// checkPacketSequence 检查并去除数据包头部的序列号，重复或重放的数据包将触发 ConnectionPacketReplayEvent 并被丢弃
+//   - 数据包长度不足以包含序列号时将以 CloseReasonProtocolError 关闭连接
+func (slf *Server) checkPacketSequence(conn *Conn, packet []byte) ([]byte, bool) {
+	sequence := conn.sequence.Load()
+	if sequence == nil {
+		return packet, true
+	}
+	if len(packet) < PacketSequenceSize {
+		conn.CloseWithReason(CloseReasonProtocolError, ErrPacketSequenceMissing)
+		return nil, false
+	}
+	seq := binary.BigEndian.Uint64(packet)
+	if !sequence.accept(seq) {
+		slf.OnConnectionPacketReplayEvent(conn, seq)
+		return nil, false
+	}
+	return packet[PacketSequenceSize:], true
+}
+
+// GetPacketSequence 获取连接已接收的最大序列号，当未启用 WithPacketSequence 或尚未接收到数据包时 ok 为 false
+func (slf *Conn) GetPacketSequence() (seq uint64, ok bool) {
+	if sequence := slf.sequence.Load(); sequence != nil {
+		return sequence.last()
+	}
+	return 0, false
+}
+
+// InheritPacketSequence 继承另一个连接的序列号窗口，适用于客户端断线重连后延续序列号的场景
+//   - 通常在鉴权通过并找到同一玩家的旧连接后调用，此后在旧连接中已接收的序列号将同样被新连接拒绝
+//   - 当未启用 WithPacketSequence 时将不会产生任何效果
+func (slf *Conn) InheritPacketSequence(conn *Conn) {
+	if slf.sequence.Load() == nil {
+		return
+	}
+	if sequence := conn.sequence.Load(); sequence != nil {
+		slf.sequence.Store(sequence)
+	}
+}
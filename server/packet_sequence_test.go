@@ -0,0 +1,23 @@
+package server
+
+import "testing"
+
+func TestPacketSequence_Accept(t *testing.T) {
+	sequence := newPacketSequence(64)
+	var cases = []struct {
+		seq    uint64
+		accept bool
+	}{
+		{1, true}, {3, true}, {2, true}, {2, false}, {3, false},
+		{100, true}, {37, true}, {36, false}, {99, true}, {99, false},
+		{1000, true}, {100, false}, {937, true}, {999, true},
+	}
+	for i, c := range cases {
+		if accept := sequence.accept(c.seq); accept != c.accept {
+			t.Fatalf("case %d: seq %d expected %v, got %v", i, c.seq, c.accept, accept)
+		}
+	}
+	if seq, ok := sequence.last(); !ok || seq != 1000 {
+		t.Fatalf("expected the last sequence to be 1000, got %d", seq)
+	}
+}
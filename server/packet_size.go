@@ -0,0 +1,14 @@
+package server
+
+// maxPacketFrameOverhead 编解码器中单个数据包的帧头部或分隔符所允许的额外长度
+//   - 缓冲区中未完整的数据包长度超出 maxPacketSize 与该值之和时将被视为超出限制
+const maxPacketFrameOverhead = 64
+
+// oversizePacket 关闭接收的数据包超出 WithMaxPacketSize 限制的连接并触发 ConnectionPacketOversizeEvent
+func (slf *Server) oversizePacket(conn *Conn, size int) {
+	if conn.IsClosed() {
+		return
+	}
+	conn.CloseWithReason(CloseReasonProtocolError, ErrPacketTooLarge)
+	slf.OnConnectionPacketOversizeEvent(conn, size)
+}
@@ -0,0 +1,69 @@
+package server_test
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/server"
+	"testing"
+	"time"
+)
+
+func TestWithMaxPacketSize(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket, server.WithMaxPacketSize(8))
+	var received = make(chan []byte, 1)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		received <- packet
+	})
+	var oversize = make(chan int, 1)
+	srv.RegConnectionPacketOversizeEvent(func(srv *server.Server, conn *server.Conn, size int) {
+		oversize <- size
+	})
+	var closed = make(chan server.CloseReason, 1)
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
+		closed <- reason
+	})
+	var addr = freeAddr(t, "tcp")
+	runServer(t, srv, addr)
+	defer srv.Shutdown()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws://"+addr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+	if err = ws.WriteMessage(websocket.BinaryMessage, []byte("12345678")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case packet := <-received:
+		if string(packet) != "12345678" {
+			t.Fatalf("unexpected packet %q", packet)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("packet within the limit was not received")
+	}
+
+	if err = ws.WriteMessage(websocket.BinaryMessage, []byte("123456789")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case reason := <-closed:
+		if reason != server.CloseReasonProtocolError {
+			t.Fatalf("expected %s, got %s", server.CloseReasonProtocolError, reason)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("oversize connection was not closed")
+	}
+	select {
+	case size := <-oversize:
+		if size != -1 {
+			t.Fatalf("expected unknown size -1, got %d", size)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ConnectionPacketOversizeEvent was not fired")
+	}
+	select {
+	case packet := <-received:
+		t.Fatalf("oversize packet %q should not be received", packet)
+	default:
+	}
+}
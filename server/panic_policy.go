@@ -0,0 +1,73 @@
+package server
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/log"
+	"runtime/debug"
+)
+
+const (
+	PanicPolicyRecover         PanicPolicy = iota + 1 // 恢复并继续处理
+	PanicPolicyCloseConnection                        // 关闭发生 panic 的连接
+	PanicPolicyShutdown                               // 关闭服务器
+)
+
+var panicPolicyNames = map[PanicPolicy]string{
+	PanicPolicyRecover:         "Recover",
+	PanicPolicyCloseConnection: "CloseConnection",
+	PanicPolicyShutdown:        "Shutdown",
+}
+
+// PanicPolicy panic 处理策略
+type PanicPolicy byte
+
+// String 返回 panic 处理策略的字符串表示
+func (slf PanicPolicy) String() string {
+	return panicPolicyNames[slf]
+}
+
+// protectConn 在连接读取协程中执行 handler，并在发生 panic 时根据 PanicPolicy 进行处理
+//   - 未设置策略时默认关闭连接
+//   - 返回 false 时表示连接已不可继续处理，读取协程应当退出
+func (slf *Server) protectConn(conn *Conn, handler func()) (ok bool) {
+	defer func() {
+		if err := recover(); err != nil {
+			stack := debug.Stack()
+			slf.Logger().Error("Server", log.String("conn", conn.GetID()), log.Any("error", err), log.String("stack", string(stack)))
+			ok = slf.applyPanicPolicy(conn, err, stack, PanicPolicyCloseConnection)
+		}
+	}()
+	handler()
+	return true
+}
+
+// applyPanicPolicy 在发生 panic 后触发 ConnectionPanicEvent 并根据 PanicPolicy 进行处理
+//   - def 为未设置策略时的默认策略
+//   - conn 为空时将不会触发 ConnectionPanicEvent，PanicPolicyCloseConnection 也将不会产生任何效果
+//   - 返回值表示连接是否可以继续处理
+func (slf *Server) applyPanicPolicy(conn *Conn, err any, stack []byte, def PanicPolicy) bool {
+	var policy = slf.panicPolicy
+	if policy == 0 {
+		policy = def
+	}
+	if conn != nil {
+		slf.OnConnectionPanicEvent(conn, err, stack)
+	}
+
+	switch policy {
+	case PanicPolicyCloseConnection, PanicPolicyShutdown:
+		e, ok := err.(error)
+		if !ok {
+			e = fmt.Errorf("%v", err)
+		}
+		if conn != nil {
+			conn.CloseWithReason(CloseReasonPanic, e)
+		}
+		if policy == PanicPolicyShutdown {
+			slf.PushErrorMessage(e, MessageErrorActionShutdown)
+		}
+		return false
+	default:
+		return true
+	}
+}
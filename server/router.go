@@ -0,0 +1,184 @@
+package server
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/log"
+	"sync"
+)
+
+type (
+	// RouterDecoder 路由器数据包解码器，用于从数据包中解析出消息 ID 及消息体
+	RouterDecoder[ID comparable] func(packet []byte) (id ID, payload []byte, err error)
+	// RouterHandler 路由处理函数
+	RouterHandler func(conn *Conn, payload []byte)
+	// RouterMiddleware 路由中间件，可通过包装 next 实现鉴权、日志、限流等功能，不调用 next 即可中断处理
+	RouterMiddleware func(next RouterHandler) RouterHandler
+	// RouterEncoder 路由器数据包编码器，用于将消息 ID 及消息体编码为数据包
+	RouterEncoder[ID comparable] func(id ID, payload []byte) []byte
+	// RouterErrorHandler 路由错误处理函数，当数据包解码失败、消息体反序列化失败或路由不存在时将被调用
+	RouterErrorHandler[ID comparable] func(conn *Conn, id ID, err error)
+)
+
+// RouterDecoderUint16 以数据包前 2 个字节（大端序）作为消息 ID 的路由器解码器
+func RouterDecoderUint16(packet []byte) (id uint16, payload []byte, err error) {
+	if len(packet) < 2 {
+		return 0, nil, ErrRouterPacketTooShort
+	}
+	return binary.BigEndian.Uint16(packet), packet[2:], nil
+}
+
+// RouterDecoderUint32 以数据包前 4 个字节（大端序）作为消息 ID 的路由器解码器
+func RouterDecoderUint32(packet []byte) (id uint32, payload []byte, err error) {
+	if len(packet) < 4 {
+		return 0, nil, ErrRouterPacketTooShort
+	}
+	return binary.BigEndian.Uint32(packet), packet[4:], nil
+}
+
+// RouterEncoderUint16 以数据包前 2 个字节（大端序）作为消息 ID 的路由器编码器，与 RouterDecoderUint16 对应
+func RouterEncoderUint16(id uint16, payload []byte) []byte {
+	return append(binary.BigEndian.AppendUint16(make([]byte, 0, 2+len(payload)), id), payload...)
+}
+
+// RouterEncoderUint32 以数据包前 4 个字节（大端序）作为消息 ID 的路由器编码器，与 RouterDecoderUint32 对应
+func RouterEncoderUint32(id uint32, payload []byte) []byte {
+	return append(binary.BigEndian.AppendUint32(make([]byte, 0, 4+len(payload)), id), payload...)
+}
+
+// NewRouter 创建一个基于消息 ID 进行分发的路由器
+//   - decoder 用于从数据包中解析出消息 ID 及消息体，内置的解码器可参考 RouterDecoderUint16、RouterDecoderUint32
+//   - 通过 Bind 将路由器绑定到服务器后，将会通过 ConnectionReceivePacketEvent 对数据包进行分发
+func NewRouter[ID comparable](decoder RouterDecoder[ID]) *Router[ID] {
+	return &Router[ID]{
+		decoder: decoder,
+		routes:  make(map[ID]RouterHandler),
+		errorHandler: func(conn *Conn, id ID, err error) {
+			log.Error("Router", log.String("ConnID", conn.GetID()), log.Any("MessageID", id), log.Err(err))
+		},
+	}
+}
+
+// Router 基于消息 ID 进行分发的路由器
+//   - 支持全局中间件 Use 及路由级中间件
+//   - 支持通过 RouteJSON、RouteTyped 注册具有类型化消息体的处理函数
+type Router[ID comparable] struct {
+	decoder      RouterDecoder[ID]
+	encoder      RouterEncoder[ID]
+	routes       map[ID]RouterHandler
+	middlewares  []RouterMiddleware
+	errorHandler RouterErrorHandler[ID]
+	rw           sync.RWMutex
+}
+
+// Use 添加全局中间件，全局中间件将作用于调用 Use 之后注册的所有路由
+func (slf *Router[ID]) Use(middlewares ...RouterMiddleware) *Router[ID] {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	slf.middlewares = append(slf.middlewares, middlewares...)
+	return slf
+}
+
+// SetErrorHandler 设置错误处理函数，默认将输出 ERROR 类型的日志
+func (slf *Router[ID]) SetErrorHandler(handler RouterErrorHandler[ID]) *Router[ID] {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	slf.errorHandler = handler
+	return slf
+}
+
+// SetEncoder 设置数据包编码器，设置后可通过 Write 向连接写入携带消息 ID 的数据包
+func (slf *Router[ID]) SetEncoder(encoder RouterEncoder[ID]) *Router[ID] {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	slf.encoder = encoder
+	return slf
+}
+
+// Write 将消息 ID 及消息体编码后写入连接
+//   - 当未通过 SetEncoder 设置编码器时将会引发 panic
+func (slf *Router[ID]) Write(conn *Conn, id ID, payload []byte, callback ...func(err error)) {
+	slf.rw.RLock()
+	encoder := slf.encoder
+	slf.rw.RUnlock()
+	if encoder == nil {
+		panic(errors.New("router: encoder is not set, use SetEncoder to set it"))
+	}
+	conn.Write(encoder(id, payload), callback...)
+}
+
+// Route 为特定消息 ID 注册处理函数，middlewares 为仅作用于该路由的中间件
+//   - 中间件的执行顺序为全局中间件、路由中间件，按照注册顺序执行
+//   - 重复注册相同的消息 ID 将会引发 panic
+func (slf *Router[ID]) Route(id ID, handler RouterHandler, middlewares ...RouterMiddleware) *Router[ID] {
+	slf.rw.Lock()
+	defer slf.rw.Unlock()
+	if _, exist := slf.routes[id]; exist {
+		panic(fmt.Errorf("the route[%v] has already been registered, duplicate registration is not allowed", id))
+	}
+	var chain = make([]RouterMiddleware, 0, len(slf.middlewares)+len(middlewares))
+	chain = append(chain, slf.middlewares...)
+	chain = append(chain, middlewares...)
+	for i := len(chain) - 1; i >= 0; i-- {
+		handler = chain[i](handler)
+	}
+	slf.routes[id] = handler
+	return slf
+}
+
+// Dispatch 对数据包进行解码并分发到对应的处理函数
+func (slf *Router[ID]) Dispatch(conn *Conn, packet []byte) {
+	id, payload, err := slf.decoder(packet)
+	slf.rw.RLock()
+	handler, exist := slf.routes[id]
+	errorHandler := slf.errorHandler
+	slf.rw.RUnlock()
+	if err != nil {
+		errorHandler(conn, id, err)
+		return
+	}
+	if !exist {
+		errorHandler(conn, id, ErrRouterNotFound)
+		return
+	}
+	if conn != nil {
+		conn.SetMessageRoute(fmt.Sprint(id))
+	}
+	handler(conn, payload)
+}
+
+// handleError 调用错误处理函数
+func (slf *Router[ID]) handleError(conn *Conn, id ID, err error) {
+	slf.rw.RLock()
+	errorHandler := slf.errorHandler
+	slf.rw.RUnlock()
+	errorHandler(conn, id, err)
+}
+
+// Bind 将路由器绑定到服务器，通过 ConnectionReceivePacketEvent 对数据包进行分发
+func (slf *Router[ID]) Bind(srv *Server, priority ...int) *Router[ID] {
+	srv.RegConnectionReceivePacketEvent(func(srv *Server, conn *Conn, packet []byte) {
+		slf.Dispatch(conn, packet)
+	}, priority...)
+	return slf
+}
+
+// RouteTyped 为特定消息 ID 注册具有类型化消息体的处理函数，消息体将通过 unmarshal 进行反序列化
+//   - 当反序列化失败时将调用路由器的错误处理函数
+func RouteTyped[ID comparable, T any](router *Router[ID], id ID, unmarshal func(data []byte, v any) error, handler func(conn *Conn, message *T), middlewares ...RouterMiddleware) {
+	router.Route(id, func(conn *Conn, payload []byte) {
+		var message = new(T)
+		if err := unmarshal(payload, message); err != nil {
+			router.handleError(conn, id, err)
+			return
+		}
+		handler(conn, message)
+	}, middlewares...)
+}
+
+// RouteJSON 为特定消息 ID 注册以 JSON 格式反序列化消息体的处理函数
+func RouteJSON[ID comparable, T any](router *Router[ID], id ID, handler func(conn *Conn, message *T), middlewares ...RouterMiddleware) {
+	RouteTyped[ID, T](router, id, json.Unmarshal, handler, middlewares...)
+}
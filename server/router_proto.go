@@ -0,0 +1,37 @@
+package server
+
+import (
+	"google.golang.org/protobuf/proto"
+)
+
+// RegisterProtoHandler 为特定消息 ID 注册以 protobuf 格式反序列化消息体的处理函数
+//   - 当处理函数返回的响应不为 nil 时，将以相同的消息 ID 序列化后写入连接，此时需要通过 Router.SetEncoder 设置编码器
+//   - 反序列化或序列化失败时将调用路由器的错误处理函数
+func RegisterProtoHandler[ID comparable, T proto.Message](router *Router[ID], id ID, handler func(conn *Conn, message T) proto.Message, middlewares ...RouterMiddleware) {
+	var zero T
+	var messageType = zero.ProtoReflect().Type()
+	router.Route(id, func(conn *Conn, payload []byte) {
+		message := messageType.New().Interface().(T)
+		if err := proto.Unmarshal(payload, message); err != nil {
+			router.handleError(conn, id, err)
+			return
+		}
+		response := handler(conn, message)
+		if response == nil {
+			return
+		}
+		if err := WriteProto(router, conn, id, response); err != nil {
+			router.handleError(conn, id, err)
+		}
+	}, middlewares...)
+}
+
+// WriteProto 将 protobuf 消息序列化后以特定消息 ID 写入连接，适用于服务器主动推送的场景
+func WriteProto[ID comparable](router *Router[ID], conn *Conn, id ID, message proto.Message, callback ...func(err error)) error {
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return err
+	}
+	router.Write(conn, id, data, callback...)
+	return nil
+}
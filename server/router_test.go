@@ -0,0 +1,65 @@
+package server_test
+
+import (
+	"encoding/binary"
+	"github.com/kercylan98/minotaur/server"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"testing"
+)
+
+func TestRouter_Dispatch(t *testing.T) {
+	type Login struct {
+		Account string `json:"account"`
+	}
+
+	var trace []string
+	var errs []error
+	r := server.NewRouter[uint16](server.RouterDecoderUint16).SetErrorHandler(func(conn *server.Conn, id uint16, err error) {
+		errs = append(errs, err)
+	})
+	r.Use(func(next server.RouterHandler) server.RouterHandler {
+		return func(conn *server.Conn, payload []byte) {
+			trace = append(trace, "global")
+			next(conn, payload)
+		}
+	})
+	server.RouteJSON[uint16, Login](r, 1, func(conn *server.Conn, message *Login) {
+		trace = append(trace, "login:"+message.Account)
+	}, func(next server.RouterHandler) server.RouterHandler {
+		return func(conn *server.Conn, payload []byte) {
+			trace = append(trace, "route")
+			next(conn, payload)
+		}
+	})
+
+	packet := binary.BigEndian.AppendUint16(nil, 1)
+	r.Dispatch(nil, append(packet, `{"account":"minotaur"}`...))
+	r.Dispatch(nil, binary.BigEndian.AppendUint16(nil, 2))
+	r.Dispatch(nil, []byte{1})
+
+	if len(trace) != 3 || trace[0] != "global" || trace[1] != "route" || trace[2] != "login:minotaur" {
+		t.Fatalf("unexpected trace: %v", trace)
+	}
+	if len(errs) != 2 || errs[0] != server.ErrRouterNotFound || errs[1] != server.ErrRouterPacketTooShort {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+}
+
+func TestRegisterProtoHandler(t *testing.T) {
+	var received string
+	r := server.NewRouter[uint16](server.RouterDecoderUint16)
+	server.RegisterProtoHandler[uint16, *wrapperspb.StringValue](r, 1, func(conn *server.Conn, message *wrapperspb.StringValue) proto.Message {
+		received = message.GetValue()
+		return nil
+	})
+
+	data, err := proto.Marshal(wrapperspb.String("minotaur"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Dispatch(nil, server.RouterEncoderUint16(1, data))
+	if received != "minotaur" {
+		t.Fatalf("unexpected message: %s", received)
+	}
+}
@@ -1,11 +1,12 @@
 package server
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/gorilla/websocket"
 	"github.com/kercylan98/minotaur/server/internal/logger"
 	"github.com/kercylan98/minotaur/utils/concurrent"
 	"github.com/kercylan98/minotaur/utils/log"
@@ -14,27 +15,33 @@ import (
 	"github.com/kercylan98/minotaur/utils/super"
 	"github.com/kercylan98/minotaur/utils/timer"
 	"github.com/panjf2000/ants/v2"
-	"github.com/panjf2000/gnet"
-	"github.com/xtaci/kcp-go/v5"
 	"google.golang.org/grpc"
-	"net"
+	"google.golang.org/grpc/health"
 	"net/http"
 	"os"
 	"os/signal"
 	"runtime/debug"
-	"strings"
 	"sync"
 	"sync/atomic"
 	"syscall"
 	"time"
 )
 
+const (
+	serverStateRunning  int32 = iota // 运行中，正常接收消息
+	serverStateDraining              // 关闭中，等待已接收的消息处理完成
+	serverStateStopped               // 已停止，不再接收任何消息
+)
+
 // New 根据特定网络类型创建一个服务器
 func New(network Network, options ...Option) *Server {
 	server := &Server{
 		runtime: &runtime{
-			messagePoolSize: DefaultMessageBufferSize,
-			packetWarnSize:  DefaultPacketWarnSize,
+			messagePoolSize:         DefaultMessageBufferSize,
+			packetWarnSize:          DefaultPacketWarnSize,
+			lowMessageDuration:      DefaultLowMessageDuration,
+			asyncLowMessageDuration: DefaultAsyncLowMessageDuration,
+			udpSessionTimeout:       DefaultUDPSessionTimeout,
 		},
 		option:           &option{},
 		network:          network,
@@ -47,10 +54,11 @@ func New(network Network, options ...Option) *Server {
 		currDispatcher:   map[string]*dispatcher{},
 	}
 	server.event = newEvent(server)
+	server.eventBus = newEventBus(server)
 
 	switch network {
 	case NetworkHttp:
-		server.ginServer = gin.New()
+		server.ginServer = newGinServer()
 		server.httpServer = &http.Server{
 			Handler: server.ginServer,
 		}
@@ -58,11 +66,13 @@ func New(network Network, options ...Option) *Server {
 		server.grpcServer = grpc.NewServer()
 	case NetworkWebsocket:
 		server.websocketReadDeadline = DefaultWebsocketReadDeadline
+		server.ginServer = newGinServer()
 	}
 
 	for _, option := range options {
 		option(server)
 	}
+	server.ctx, server.cancel = context.WithCancel(server.ctx)
 
 	if !server.disableAnts {
 		if server.antsPoolSize <= 0 {
@@ -84,14 +94,14 @@ type Server struct {
 	*event                                                         // 事件
 	*runtime                                                       // 运行时
 	*option                                                        // 可选项
-	ginServer                *gin.Engine                           // HTTP模式下的路由器
+	ginServer                *gin.Engine                           // HTTP及Websocket模式下的路由器
 	httpServer               *http.Server                          // HTTP模式下的服务器
 	grpcServer               *grpc.Server                          // GRPC模式下的服务器
-	gServer                  *gNet                                 // TCP或UDP模式下的服务器
 	multiple                 *MultipleServer                       // 多服务器模式下的服务器
 	ants                     *ants.Pool                            // 协程池
 	messagePool              *concurrent.Pool[*Message]            // 消息池
 	ctx                      context.Context                       // 上下文
+	cancel                   context.CancelFunc                    // 取消上下文
 	online                   *concurrent.BalanceMap[string, *Conn] // 在线连接
 	systemDispatcher         *dispatcher                           // 系统消息分发器
 	network                  Network                               // 网络类型
@@ -101,12 +111,35 @@ type Server struct {
 	multipleRuntimeErrorChan chan error                            // 多服务器模式下的运行时错误
 	messageLock              sync.RWMutex                          // 消息锁
 	dispatcherLock           sync.RWMutex                          // 消息分发器锁
-	isShutdown               atomic.Bool                           // 是否已关闭
+	state                    atomic.Int32                          // 服务器状态
+	shutdownPending          atomic.Bool                           // 是否正在执行关闭前事件
+	dispatcherWait           sync.WaitGroup                        // 消息分发器退出等待
 	messageCounter           atomic.Int64                          // 消息计数器
+	connIdGenerator          atomic.Int64                          // 连接 ID 生成器
 	isRunning                bool                                  // 是否正在运行
 	dispatchers              map[string]*dispatcher                // 消息分发器集合
 	dispatcherMember         map[string]map[string]*Conn           // 消息分发器包含的连接
 	currDispatcher           map[string]*dispatcher                // 当前连接所处消息分发器
+	shardDispatchers         []*dispatcher                         // 分片消息分发器
+	workerDispatcher         *dispatcher                           // 工作池消息分发器
+	listeners                []listener                            // 附加侦听器
+	listenerClosers          []func() error                        // 侦听器关闭函数
+	crossCallId              atomic.Uint64                         // 跨服请求 ID 生成器
+	crossCalls               map[uint64]chan []byte                // 等待响应的跨服请求
+	crossCallLock            sync.Mutex                            // 跨服请求锁
+	draining                 atomic.Bool                           // 是否处于排空状态
+	maintenance              atomic.Pointer[maintenance]           // 维护模式
+	startFinished            atomic.Bool                           // 是否已启动完成
+	grpcHealth               *health.Server                        // GRPC模式下的健康检查服务
+	eventBus                 *EventBus                             // 事件总线
+}
+
+// newGinServer 创建不信任任何代理的 gin 引擎，仅在通过 WithTrustedProxies 设置可信代理后才会从请求头中获取客户端 IP
+//   - gin 默认信任所有代理，此时客户端可通过伪造 X-Forwarded-For 请求头绕过基于 IP 的限流及过滤
+func newGinServer() *gin.Engine {
+	engine := gin.New()
+	_ = engine.SetTrustedProxies(nil)
+	return engine
 }
 
 // Run 使用特定地址运行服务器
@@ -120,6 +153,7 @@ type Server struct {
 //   - server.NetworkHttp (addr:":8888")
 //   - server.NetworkWebsocket (addr:":8888/ws")
 //   - server.NetworkKcp (addr:":8888")
+//   - server.NetworkWebTransport (addr:":8888/wt")
 //   - server.NetworkNone (addr:"")
 func (slf *Server) Run(addr string) error {
 	if slf.network == NetworkNone {
@@ -131,7 +165,38 @@ func (slf *Server) Run(addr string) error {
 	slf.event.check()
 	slf.addr = addr
 	slf.systemDispatcher = generateDispatcher(serverSystemDispatcher, slf.dispatchMessage)
-	var protoAddr = fmt.Sprintf("%s://%s", slf.network, slf.addr)
+	if slf.workerPoolMax > 0 {
+		var strategy = slf.shardStrategy
+		if strategy == nil {
+			strategy = ShardByConnID()
+		}
+		d := generateDispatcher(serverWorkerDispatcher, slf.dispatchMessage)
+		d.pool = newWorkerPool(slf.workerPoolMin, slf.workerPoolMax, slf.workerPoolLatency, func(message *Message) string {
+			return strategy(message.conn)
+		}, func(message *Message) {
+			slf.dispatchMessage(d, message)
+		})
+		slf.workerDispatcher = d
+		slf.dispatcherWait.Add(1)
+		d.pool.start(&slf.dispatcherWait)
+	}
+	for i := 0; i < slf.shardCount && slf.workerDispatcher == nil; i++ {
+		d := generateDispatcher(shardDispatcherName(i), slf.dispatchMessage)
+		slf.shardDispatchers = append(slf.shardDispatchers, d)
+		slf.dispatcherWait.Add(1)
+		go d.start(&slf.dispatcherWait)
+	}
+	var listenerServes = make([]func() error, 0, len(slf.listeners))
+	for _, l := range slf.listeners {
+		serve, err := slf.listen(l.network, l.addr)
+		if err != nil {
+			return slf.abort(err)
+		}
+		var l = l
+		listenerServes = append(listenerServes, func() error {
+			return slf.listenHandshake(l.network, l.addr, serve)
+		})
+	}
 	var messageInitFinish = make(chan struct{}, 1)
 	var connectionInitHandle = func(callback func()) {
 		slf.messageLock.Lock()
@@ -144,15 +209,13 @@ func (slf *Server) Run(addr string) error {
 			},
 		)
 		slf.messageLock.Unlock()
-		if slf.network != NetworkHttp && slf.network != NetworkWebsocket && slf.network != NetworkGRPC {
-			slf.gServer = &gNet{Server: slf}
-		}
 		if callback != nil {
 			go callback()
 		}
+		slf.dispatcherWait.Add(1)
 		go func() {
 			messageInitFinish <- struct{}{}
-			slf.systemDispatcher.start()
+			slf.systemDispatcher.start(&slf.dispatcherWait)
 		}()
 	}
 
@@ -163,76 +226,34 @@ func (slf *Server) Run(addr string) error {
 			slf.OnStartBeforeEvent()
 		})
 	case NetworkGRPC:
-		listener, err := net.Listen(string(NetworkTcp), slf.addr)
+		listener, err := slf.listenTCP(string(NetworkGRPC), slf.addr)
 		if err != nil {
-			return err
+			return slf.abort(err)
+		}
+		config, err := slf.loadTLSConfig()
+		if err != nil {
+			_ = listener.Close()
+			return slf.abort(err)
+		}
+		if config != nil {
+			if len(config.NextProtos) == 0 {
+				config.NextProtos = []string{"h2"}
+			}
+			listener = tls.NewListener(listener, config)
 		}
+		slf.registerGRPCServices()
 		go connectionInitHandle(nil)
 		go func() {
 			slf.isRunning = true
 			slf.OnStartBeforeEvent()
 			if err := slf.grpcServer.Serve(listener); err != nil {
 				slf.isRunning = false
-				slf.PushErrorMessage(err, MessageErrorActionShutdown)
+				slf.listenFailed(slf.network, slf.addr, err)
 			}
 		}()
-	case NetworkTcp, NetworkTcp4, NetworkTcp6, NetworkUdp, NetworkUdp4, NetworkUdp6, NetworkUnix:
-		go connectionInitHandle(func() {
-			slf.isRunning = true
-			slf.OnStartBeforeEvent()
-			if err := gnet.Serve(slf.gServer, protoAddr,
-				gnet.WithLogger(new(logger.GNet)),
-				gnet.WithTicker(true),
-				gnet.WithMulticore(true),
-			); err != nil {
-				slf.isRunning = false
-				slf.PushErrorMessage(err, MessageErrorActionShutdown)
-			}
-		})
-	case NetworkKcp:
-		listener, err := kcp.ListenWithOptions(slf.addr, nil, 0, 0)
-		if err != nil {
-			return err
-		}
-		go connectionInitHandle(func() {
-			slf.isRunning = true
-			slf.OnStartBeforeEvent()
-			for {
-				session, err := listener.AcceptKCP()
-				if err != nil {
-					continue
-				}
-
-				conn := newKcpConn(slf, session)
-				slf.OnConnectionOpenedEvent(conn)
-
-				go func(conn *Conn) {
-					defer func() {
-						if err := recover(); err != nil {
-							e, ok := err.(error)
-							if !ok {
-								e = fmt.Errorf("%v", err)
-							}
-							conn.Close(e)
-						}
-					}()
-
-					buf := make([]byte, 4096)
-					for !conn.IsClosed() {
-						n, err := conn.kcp.Read(buf)
-						if err != nil {
-							if conn.IsClosed() {
-								break
-							}
-							panic(err)
-						}
-						slf.PushPacketMessage(conn, 0, buf[:n])
-					}
-				}(conn)
-			}
-		})
 	case NetworkHttp:
-		go func() {
+		connectionInitHandle(nil)
+		if err := slf.listenHandshake(slf.network, slf.addr, func(ready func()) error {
 			slf.isRunning = true
 			slf.OnStartBeforeEvent()
 			slf.httpServer.Addr = slf.addr
@@ -240,140 +261,115 @@ func (slf *Server) Run(addr string) error {
 			slf.ginServer.Use(func(c *gin.Context) {
 				t := time.Now()
 				c.Next()
-				log.Info("Server", log.String("type", "http"),
+				slf.Logger().Info("Server", log.String("type", "http"),
 					log.String("method", c.Request.Method), log.Int("status", c.Writer.Status()),
 					log.String("ip", c.ClientIP()), log.String("path", c.Request.URL.Path),
 					log.Duration("cost", time.Since(t)))
 			})
-			go connectionInitHandle(nil)
-			if len(slf.certFile)+len(slf.keyFile) > 0 {
-				if err := slf.httpServer.ListenAndServeTLS(slf.certFile, slf.keyFile); err != nil {
-					slf.isRunning = false
-					slf.PushErrorMessage(err, MessageErrorActionShutdown)
-				}
-			} else {
-				if err := slf.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-					slf.isRunning = false
-					slf.PushErrorMessage(err, MessageErrorActionShutdown)
-				}
+			slf.registerGRPCGateway()
+			config, err := slf.loadTLSConfig()
+			if err != nil {
+				slf.isRunning = false
+				return err
 			}
-
-		}()
-	case NetworkWebsocket:
-		go connectionInitHandle(func() {
-			var pattern string
-			var index = strings.Index(addr, "/")
-			if index == -1 {
-				pattern = "/"
+			listener, err := slf.listenTCP(string(NetworkHttp), slf.addr)
+			if err != nil {
+				slf.isRunning = false
+				return err
+			}
+			ready()
+			if config != nil {
+				slf.httpServer.TLSConfig = config
+				err = slf.httpServer.ServeTLS(listener, "", "")
 			} else {
-				pattern = addr[index:]
-				slf.addr = slf.addr[:index]
+				err = slf.httpServer.Serve(listener)
 			}
-			var upgrade = websocket.Upgrader{
-				ReadBufferSize:  4096,
-				WriteBufferSize: 4096,
-				CheckOrigin: func(r *http.Request) bool {
-					return true
-				},
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slf.isRunning = false
+				return err
 			}
-			http.HandleFunc(pattern, func(writer http.ResponseWriter, request *http.Request) {
-				ip := request.Header.Get("X-Real-IP")
-				ws, err := upgrade.Upgrade(writer, request, nil)
-				if err != nil {
-					return
-				}
-				if len(ip) == 0 {
-					addr := ws.RemoteAddr().String()
-					if index := strings.LastIndex(addr, ":"); index != -1 {
-						ip = addr[0:index]
-					}
-				}
-				if slf.websocketCompression > 0 {
-					_ = ws.SetCompressionLevel(slf.websocketCompression)
-				}
-				ws.EnableWriteCompression(slf.websocketWriteCompression)
-				conn := newWebsocketConn(slf, ws, ip)
-				conn.SetData(wsRequestKey, request)
-				for k, v := range request.URL.Query() {
-					if len(v) == 1 {
-						conn.SetData(k, v[0])
-					} else {
-						conn.SetData(k, v)
-					}
-				}
-				slf.OnConnectionOpenedEvent(conn)
-
-				defer func() {
-					if err := recover(); err != nil {
-						e, ok := err.(error)
-						if !ok {
-							e = fmt.Errorf("%v", err)
-						}
-						conn.Close(e)
-					}
-				}()
-				for !conn.IsClosed() {
-					if slf.websocketReadDeadline > 0 {
-						if err := ws.SetReadDeadline(time.Now().Add(slf.websocketReadDeadline)); err != nil {
-							panic(err)
-						}
-					}
-					messageType, packet, readErr := ws.ReadMessage()
-					if readErr != nil {
-						if conn.IsClosed() {
-							break
-						}
-						panic(readErr)
-					}
-					if len(slf.supportMessageTypes) > 0 && !slf.supportMessageTypes[messageType] {
-						panic(ErrWebsocketIllegalMessageType)
-					}
-					slf.PushPacketMessage(conn, messageType, packet)
-				}
-			})
-			go func() {
-				slf.isRunning = true
-				slf.OnStartBeforeEvent()
-				if len(slf.certFile)+len(slf.keyFile) > 0 {
-					if err := http.ListenAndServeTLS(slf.addr, slf.certFile, slf.keyFile, nil); err != nil {
-						slf.isRunning = false
-						slf.PushErrorMessage(err, MessageErrorActionShutdown)
-					}
-				} else {
-					if err := http.ListenAndServe(slf.addr, nil); err != nil {
-						slf.isRunning = false
-						slf.PushErrorMessage(err, MessageErrorActionShutdown)
-					}
-				}
-
-			}()
-		})
+			return nil
+		}); err != nil {
+			return slf.abort(err)
+		}
+	case NetworkTcp, NetworkTcp4, NetworkTcp6, NetworkUdp, NetworkUdp4, NetworkUdp6, NetworkUnix, NetworkKcp, NetworkWebsocket, NetworkWebTransport:
+		serve, err := slf.listen(slf.network, slf.addr)
+		if err != nil {
+			return slf.abort(err)
+		}
+		var addr = slf.addr
+		if slf.network == NetworkWebsocket || slf.network == NetworkWebTransport {
+			slf.addr, _ = splitListenerAddr(slf.addr)
+		}
+		connectionInitHandle(nil)
+		if err = slf.listenHandshake(slf.network, addr, func(ready func()) error {
+			slf.isRunning = true
+			slf.OnStartBeforeEvent()
+			if err := serve(ready); err != nil {
+				slf.isRunning = false
+				return err
+			}
+			return nil
+		}); err != nil {
+			return slf.abort(err)
+		}
 	default:
-		return ErrCanNotSupportNetwork
+		return slf.abort(ErrCanNotSupportNetwork)
 	}
 
 	<-messageInitFinish
 	close(messageInitFinish)
 	messageInitFinish = nil
+	for crossName, cross := range slf.cross {
+		var name = crossName
+		if err := cross.Init(slf, func(serverId string, packet []byte) {
+			slf.handleCrossPacket(name, serverId, packet)
+		}); err != nil {
+			return slf.abort(err)
+		}
+		slf.Logger().Info("Server", log.String("Cross", crossName), log.String("ServerID", slf.id))
+	}
+	if err := slf.startAdmin(); err != nil {
+		return slf.abort(err)
+	}
+	for _, serve := range listenerServes {
+		if err := serve(); err != nil {
+			return slf.abort(err)
+		}
+	}
+	slf.startHeartbeat()
+	slf.startWebsocketPing()
 	if slf.multiple == nil {
 		ip, _ := network.IP()
-		log.Info("Server", log.String(serverMark, "===================================================================="))
-		log.Info("Server", log.String(serverMark, "RunningInfo"),
+		var listeners = make([]string, 0, len(slf.listeners))
+		for _, l := range slf.listeners {
+			listeners = append(listeners, l.String())
+		}
+		slf.Logger().Info("Server", log.String(serverMark, "===================================================================="))
+		slf.Logger().Info("Server", log.String(serverMark, "RunningInfo"),
 			log.Any("network", slf.network),
 			log.String("ip", ip.String()),
 			log.String("listen", slf.addr),
+			log.Any("listeners", listeners),
 		)
-		log.Info("Server", log.String(serverMark, "===================================================================="))
+		slf.Logger().Info("Server", log.String(serverMark, "===================================================================="))
 		slf.OnStartFinishEvent()
+		slf.startFinished.Store(true)
+		slf.refreshGRPCHealth()
 		time.Sleep(time.Second)
-		if !slf.isShutdown.Load() {
+		if slf.state.Load() == serverStateRunning {
 			slf.OnMessageReadyEvent()
 		}
 
 		signal.Notify(slf.systemSignal, syscall.SIGHUP, syscall.SIGQUIT, syscall.SIGTERM, syscall.SIGINT)
-		select {
-		case <-slf.systemSignal:
-			slf.shutdown(nil)
+		for {
+			select {
+			case <-slf.systemSignal:
+			case <-slf.ctx.Done():
+			}
+			if slf.shutdown(nil) {
+				break
+			}
 		}
 
 		select {
@@ -382,8 +378,10 @@ func (slf *Server) Run(addr string) error {
 		}
 	} else {
 		slf.OnStartFinishEvent()
+		slf.startFinished.Store(true)
+		slf.refreshGRPCHealth()
 		time.Sleep(time.Second)
-		if !slf.isShutdown.Load() {
+		if slf.state.Load() == serverStateRunning {
 			slf.OnMessageReadyEvent()
 		}
 	}
@@ -391,6 +389,39 @@ func (slf *Server) Run(addr string) error {
 	return nil
 }
 
+// GetID 获取服务器 ID，通过 WithCross 设置
+func (slf *Server) GetID() string {
+	return slf.id
+}
+
+// SetDraining 设置服务器是否处于排空状态，处于排空状态时将拒绝新的连接，已建立的连接不受影响
+//   - 被拒绝的连接将以 ConnectionRejectReasonDraining 触发 ConnectionRejectedEvent
+//   - 通常用于停服或迁移前，等待在线连接自然下线后再通过 Shutdown 关闭服务器
+func (slf *Server) SetDraining(draining bool) {
+	slf.draining.Store(draining)
+	slf.refreshGRPCHealth()
+}
+
+// IsDraining 服务器是否处于排空状态
+func (slf *Server) IsDraining() bool {
+	return slf.draining.Load()
+}
+
+// GetNetwork 获取服务器的网络类型
+func (slf *Server) GetNetwork() Network {
+	return slf.network
+}
+
+// PushCrossMessage 推送跨服消息到特定跨服的特定服务器中
+//   - 当服务器未通过 WithCross 设置 crossName 对应的跨服时，将返回 ErrNoSupportCross
+func (slf *Server) PushCrossMessage(crossName string, serverId string, packet []byte) error {
+	cross, exist := slf.cross[crossName]
+	if !exist {
+		return ErrNoSupportCross
+	}
+	return cross.PushMessage(serverId, marshalCrossPacket(crossPacketMessage, 0, packet))
+}
+
 // IsSocket 是否是 Socket 模式
 func (slf *Server) IsSocket() bool {
 	return slf.network == NetworkTcp || slf.network == NetworkTcp4 || slf.network == NetworkTcp6 ||
@@ -398,12 +429,21 @@ func (slf *Server) IsSocket() bool {
 		slf.network == NetworkUnix || slf.network == NetworkKcp || slf.network == NetworkWebsocket
 }
 
+// RunContext 与 Run 相同，区别在于当 ctx 被取消时服务器将自动关闭，适用于嵌入到更大的应用程序或测试中
+//   - 由上下文取消导致的关闭无法通过 ShutdownBeforeEvent 取消
+//   - 如需在创建服务器时指定上下文，可使用 WithContext
+func (slf *Server) RunContext(ctx context.Context, addr string) error {
+	defer context.AfterFunc(ctx, slf.cancel)()
+	return slf.Run(addr)
+}
+
 // RunNone 是 Run("") 的简写，仅适用于运行 NetworkNone 服务器
 func (slf *Server) RunNone() error {
 	return slf.Run(str.None)
 }
 
-// Context 获取服务器上下文
+// Context 获取服务器上下文，该上下文将在服务器关闭完成时被取消
+//   - 当通过 WithContext 设置父级上下文时，该上下文派生自父级上下文
 func (slf *Server) Context() context.Context {
 	return slf.ctx
 }
@@ -421,7 +461,7 @@ func (slf *Server) GetOnlineCount() int {
 // GetOnlineBotCount 获取在线机器人数量
 func (slf *Server) GetOnlineBotCount() int {
 	var count int
-	slf.online.Range(func(id string, conn *Conn) bool {
+	slf.RangeConn(func(conn *Conn) bool {
 		if conn.IsBot() {
 			count++
 		}
@@ -440,6 +480,55 @@ func (slf *Server) GetOnlineAll() map[string]*Conn {
 	return slf.online.Map()
 }
 
+// GetConn 获取在线连接，当连接不存在时 exist 将返回 false
+func (slf *Server) GetConn(id string) (conn *Conn, exist bool) {
+	return slf.online.GetExist(id)
+}
+
+// RangeConn 遍历所有在线连接，当 handle 返回 false 时将停止遍历
+//   - 遍历基于在线连接的快照进行，允许在 handle 中关闭连接或进行广播
+func (slf *Server) RangeConn(handle func(conn *Conn) bool) {
+	for _, conn := range slf.online.Slice() {
+		if !handle(conn) {
+			break
+		}
+	}
+}
+
+// Broadcast 向所有在线连接广播数据包
+func (slf *Server) Broadcast(packet []byte) {
+	slf.RangeConn(func(conn *Conn) bool {
+		conn.Write(packet)
+		return true
+	})
+}
+
+// BroadcastFilter 向所有满足 filter 的在线连接广播数据包
+func (slf *Server) BroadcastFilter(packet []byte, filter func(conn *Conn) bool) {
+	slf.RangeConn(func(conn *Conn) bool {
+		if filter(conn) {
+			conn.Write(packet)
+		}
+		return true
+	})
+}
+
+// BroadcastExcept 向除 except 以外的所有在线连接广播数据包
+func (slf *Server) BroadcastExcept(packet []byte, except ...string) {
+	if len(except) == 0 {
+		slf.Broadcast(packet)
+		return
+	}
+	var excepts = make(map[string]struct{}, len(except))
+	for _, id := range except {
+		excepts[id] = struct{}{}
+	}
+	slf.BroadcastFilter(packet, func(conn *Conn) bool {
+		_, exist := excepts[conn.GetID()]
+		return !exist
+	})
+}
+
 // IsOnline 是否在线
 func (slf *Server) IsOnline(id string) bool {
 	return slf.online.Exist(id)
@@ -452,56 +541,209 @@ func (slf *Server) CloseConn(id string) {
 	}
 }
 
-// Ticker 获取服务器定时器
+// Kick 将特定 ID 的连接踢出服务器，当连接不存在时将返回 false
+//   - 该函数是 Conn.Kick 的快捷方式
+func (slf *Server) Kick(id string, code int, payload []byte) bool {
+	conn, exist := slf.online.GetExist(id)
+	if !exist {
+		return false
+	}
+	conn.Kick(code, payload)
+	return true
+}
+
+// Ticker 获取服务器定时器，当未通过 WithTicker 启用定时器时将会发生 panic
+//   - 在库代码中应使用不会发生 panic 的 GetTicker 或 TryTicker
 func (slf *Server) Ticker() *timer.Ticker {
+	ticker, err := slf.GetTicker()
+	if err != nil {
+		panic(err)
+	}
+	return ticker
+}
+
+// GetTicker 获取服务器定时器，当未通过 WithTicker 启用定时器时将返回 ErrNoSupportTicker
+func (slf *Server) GetTicker() (*timer.Ticker, error) {
 	if slf.ticker == nil {
-		panic(ErrNoSupportTicker)
+		return nil, ErrNoSupportTicker
 	}
-	return slf.ticker
+	return slf.ticker, nil
+}
+
+// TryTicker 获取服务器定时器，当未通过 WithTicker 启用定时器时 ok 将返回 false
+func (slf *Server) TryTicker() (ticker *timer.Ticker, ok bool) {
+	return slf.ticker, slf.ticker != nil
 }
 
 // Shutdown 主动停止运行服务器
+//   - 在 MultipleServer 中运行时，其他服务器也将随之关闭
 func (slf *Server) Shutdown() {
+	if slf.multiple != nil {
+		go slf.shutdown(nil)
+		return
+	}
 	slf.systemSignal <- syscall.SIGQUIT
 }
 
+// ShutdownAfter 在 d 后关闭服务器，期间将定期向所有连接广播倒计时数据包，适用于计划内的停服维护
+//   - notifier 用于根据剩余时间生成倒计时数据包，返回空数据包时将不进行广播，当 notifier 为空时仅延迟关闭
+//   - 剩余时间大于 1 分钟时每分钟广播一次，大于 10 秒时每 10 秒广播一次，其余情况每秒广播一次，广播时间将对齐到整数间隔
+//   - 可通过返回的 cancel 函数取消本次关闭，服务器关闭后倒计时将自动停止
+func (slf *Server) ShutdownAfter(d time.Duration, notifier func(remaining time.Duration) []byte) (cancel func()) {
+	var deadline = time.Now().Add(d)
+	ctx, cancel := context.WithCancel(slf.ctx)
+	go func() {
+		for {
+			remaining := time.Until(deadline).Round(time.Second)
+			if remaining <= 0 {
+				break
+			}
+			if notifier != nil {
+				if packet := notifier(remaining); len(packet) > 0 {
+					slf.Broadcast(packet)
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(shutdownCountdownInterval(time.Until(deadline))):
+			}
+		}
+		if ctx.Err() == nil {
+			slf.Logger().Info("Server", log.String("ShutdownAfter", d.String()), log.String("state", "shutdown"))
+			slf.Shutdown()
+		}
+	}()
+	return cancel
+}
+
+// shutdownCountdownInterval 获取距离下一次倒计时广播的间隔，将对齐到整数间隔，例如剩余 5m30s 时将在 30s 后广播剩余 5m
+func shutdownCountdownInterval(remaining time.Duration) time.Duration {
+	var interval time.Duration
+	switch {
+	case remaining > time.Minute:
+		interval = time.Minute
+	case remaining > 10*time.Second:
+		interval = 10 * time.Second
+	default:
+		interval = time.Second
+	}
+	if r := remaining % interval; r > 0 {
+		return r
+	}
+	return interval
+}
+
 // shutdown 停止运行服务器
-func (slf *Server) shutdown(err error) {
+//   - 服务器将首先进入关闭中状态，此时仅接收异步回调消息，以便正在处理的异步消息能够完成
+//   - 当所有消息处理完成后，服务器将进入已停止状态，此后推送的消息将被直接丢弃，随后关闭并等待所有消息分发器退出
+//   - 不允许在消息分发器中同步调用该函数，否则将因等待自身处理完成而阻塞
+//   - 开始关闭前将触发 ShutdownBeforeEvent，当关闭被取消时将返回 false
+func (slf *Server) shutdown(err error) bool {
+	if slf.state.Load() != serverStateRunning || !slf.shutdownPending.CompareAndSwap(false, true) {
+		return true
+	}
+	if !slf.OnShutdownBeforeEvent(err) && err == nil && slf.ctx.Err() == nil && slf.multiple == nil {
+		slf.shutdownPending.Store(false)
+		slf.Logger().Info("Server", log.Any("network", slf.network), log.String("listen", slf.addr),
+			log.String("action", "shutdown"), log.String("state", "cancelled"))
+		return false
+	}
+	if !slf.state.CompareAndSwap(serverStateRunning, serverStateDraining) {
+		return true
+	}
+	slf.refreshGRPCHealth()
 	if err != nil {
-		log.Error("Server", log.String("state", "shutdown"), log.Err(err))
+		slf.Logger().Error("Server", log.String("state", "shutdown"), log.Err(err))
 	}
-	slf.isShutdown.Store(true)
+	var waitLog = time.Now()
 	for slf.messageCounter.Load() > 0 {
-		log.Info("Server", log.Any("network", slf.network), log.String("listen", slf.addr),
-			log.String("action", "shutdown"), log.String("state", "waiting"), log.Int64("message", slf.messageCounter.Load()))
-		time.Sleep(time.Second)
-	}
-	if slf.multiple == nil {
-		slf.OnStopEvent()
+		if time.Since(waitLog) >= time.Second {
+			waitLog = time.Now()
+			slf.Logger().Info("Server", log.Any("network", slf.network), log.String("listen", slf.addr),
+				log.String("action", "shutdown"), log.String("state", "waiting"), log.Int64("message", slf.messageCounter.Load()))
+		}
+		time.Sleep(time.Millisecond * 10)
 	}
+	slf.stopDispatchers()
+	slf.OnStopEvent()
 	defer func() {
 		if slf.multipleRuntimeErrorChan != nil {
 			slf.multipleRuntimeErrorChan <- err
 		}
 	}()
-	if slf.gServer != nil && slf.isRunning {
-		if shutdownErr := gnet.Stop(context.Background(), fmt.Sprintf("%s://%s", slf.network, slf.addr)); err != nil {
-			log.Error("Server", log.Err(shutdownErr))
+	slf.release()
+
+	if err != nil {
+		if slf.multiple != nil {
+			slf.Logger().Error("Server", log.Any("network", slf.network), log.String("listen", slf.addr),
+				log.String("action", "shutdown"), log.String("state", "exception"), log.Err(err))
+		} else {
+			slf.Logger().Panic("Server", log.Any("network", slf.network), log.String("listen", slf.addr),
+				log.String("action", "shutdown"), log.String("state", "exception"), log.Err(err))
 		}
+	} else {
+		slf.Logger().Info("Server", log.Any("network", slf.network), log.String("listen", slf.addr),
+			log.String("action", "shutdown"), log.String("state", "normal"))
+	}
+	slf.closeChannel <- struct{}{}
+	return true
+}
+
+// abort 在 Run 启动失败时停止服务器，释放已创建的消息分发器及已绑定的侦听器等资源，并返回 err
+//   - 与 shutdown 不同的是，不会触发 ShutdownBeforeEvent 及 StopEvent，因为服务器尚未启动完成
+func (slf *Server) abort(err error) error {
+	slf.stopDispatchers()
+	slf.release()
+	slf.closeChannel <- struct{}{}
+	return err
+}
+
+// stopDispatchers 将服务器标记为已停止，随后关闭并等待所有消息分发器退出
+func (slf *Server) stopDispatchers() {
+	slf.messageLock.Lock()
+	slf.state.Store(serverStateStopped)
+	slf.messageLock.Unlock()
+	slf.dispatcherLock.Lock()
+	for s, d := range slf.dispatchers {
+		d.close()
+		delete(slf.dispatchers, s)
+	}
+	slf.dispatcherLock.Unlock()
+	for _, d := range slf.shardDispatchers {
+		d.close()
+	}
+	if slf.workerDispatcher != nil {
+		slf.workerDispatcher.close()
+	}
+	if slf.systemDispatcher != nil {
+		slf.systemDispatcher.close()
+	}
+	slf.dispatcherWait.Wait()
+}
+
+// release 关闭所有侦听器并释放跨服、定时器等服务器持有的资源
+func (slf *Server) release() {
+	for _, closer := range slf.listenerClosers {
+		if shutdownErr := closer(); shutdownErr != nil {
+			slf.Logger().Error("Server", log.Err(shutdownErr))
+		}
+	}
+	for _, cross := range slf.cross {
+		cross.Release()
 	}
 	if slf.ticker != nil {
 		slf.ticker.Release()
 	}
 	if slf.ants != nil {
 		slf.ants.Release()
-		slf.ants = nil
 	}
-	slf.dispatcherLock.Lock()
-	for s, d := range slf.dispatchers {
-		d.close()
-		delete(slf.dispatchers, s)
+	if slf.capture != nil {
+		slf.capture.close(slf.Logger())
+	}
+	if slf.grpcHealth != nil {
+		slf.grpcHealth.Shutdown()
 	}
-	slf.dispatcherLock.Unlock()
 	if slf.grpcServer != nil && slf.isRunning {
 		slf.grpcServer.GracefulStop()
 	}
@@ -509,62 +751,91 @@ func (slf *Server) shutdown(err error) {
 		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 		defer cancel()
 		if shutdownErr := slf.httpServer.Shutdown(ctx); shutdownErr != nil {
-			log.Error("Server", log.Err(shutdownErr))
+			slf.Logger().Error("Server", log.Err(shutdownErr))
 		}
 	}
+	slf.cancel()
+}
 
+// GRPCServer 当网络类型为 NetworkGRPC 时将被允许获取 grpc 服务器，否则将会发生 panic
+//   - 在库代码中应使用不会发生 panic 的 GetGRPCServer 或 TryGRPCServer
+func (slf *Server) GRPCServer() *grpc.Server {
+	server, err := slf.GetGRPCServer()
 	if err != nil {
-		if slf.multiple != nil {
-			slf.multiple.RegExitEvent(func() {
-				log.Panic("Server", log.Any("network", slf.network), log.String("listen", slf.addr),
-					log.String("action", "shutdown"), log.String("state", "exception"), log.Err(err))
-			})
-			for i, server := range slf.multiple.servers {
-				if server.addr == slf.addr {
-					slf.multiple.servers = append(slf.multiple.servers[:i], slf.multiple.servers[i+1:]...)
-					break
-				}
-			}
-		} else {
-			log.Panic("Server", log.Any("network", slf.network), log.String("listen", slf.addr),
-				log.String("action", "shutdown"), log.String("state", "exception"), log.Err(err))
-		}
-	} else {
-		log.Info("Server", log.Any("network", slf.network), log.String("listen", slf.addr),
-			log.String("action", "shutdown"), log.String("state", "normal"))
+		panic(err)
 	}
-	slf.closeChannel <- struct{}{}
+	return server
 }
 
-// GRPCServer 当网络类型为 NetworkGRPC 时将被允许获取 grpc 服务器，否则将会发生 panic
-func (slf *Server) GRPCServer() *grpc.Server {
+// GetGRPCServer 当网络类型为 NetworkGRPC 时将被允许获取 grpc 服务器，否则将返回 ErrNetworkOnlySupportGRPC
+func (slf *Server) GetGRPCServer() (*grpc.Server, error) {
 	if slf.grpcServer == nil {
-		panic(ErrNetworkOnlySupportGRPC)
+		return nil, ErrNetworkOnlySupportGRPC
 	}
-	return slf.grpcServer
+	return slf.grpcServer, nil
 }
 
-// HttpRouter 当网络类型为 NetworkHttp 时将被允许获取路由器进行路由注册，否则将会发生 panic
+// TryGRPCServer 当网络类型为 NetworkGRPC 时将被允许获取 grpc 服务器，否则 ok 将返回 false
+func (slf *Server) TryGRPCServer() (server *grpc.Server, ok bool) {
+	return slf.grpcServer, slf.grpcServer != nil
+}
+
+// HttpRouter 当网络类型为 NetworkHttp 或 NetworkWebsocket 时将被允许获取路由器进行路由注册，否则将会发生 panic
 //   - 通过该函数注册的路由将无法在服务器关闭时正常等待请求结束
+//   - 当网络类型为 NetworkWebsocket 时，非 Websocket 升级请求将交由该路由器处理，使 HTTP 接口与 Websocket 共享端口
 //
 // Deprecated: 从 Minotaur 0.0.29 开始，由于设计原因已弃用，该函数将直接返回 *gin.Server 对象，导致无法正常的对请求结束时进行处理
 func (slf *Server) HttpRouter() gin.IRouter {
+	router, err := slf.GetHttpRouter()
+	if err != nil {
+		panic(err)
+	}
+	return router
+}
+
+// GetHttpRouter 与 HttpRouter 一致，不同的是当网络类型不为 NetworkHttp 或 NetworkWebsocket 时将返回 ErrNetworkOnlySupportHttp
+func (slf *Server) GetHttpRouter() (gin.IRouter, error) {
+	if slf.ginServer == nil {
+		return nil, ErrNetworkOnlySupportHttp
+	}
+	return slf.ginServer, nil
+}
+
+// TryHttpRouter 与 HttpRouter 一致，不同的是当网络类型不为 NetworkHttp 或 NetworkWebsocket 时 ok 将返回 false
+func (slf *Server) TryHttpRouter() (router gin.IRouter, ok bool) {
 	if slf.ginServer == nil {
-		panic(ErrNetworkOnlySupportHttp)
+		return nil, false
 	}
-	return slf.ginServer
+	return slf.ginServer, true
 }
 
 // HttpServer 替代 HttpRouter 的函数，返回一个 *Http[*HttpContext] 对象
 //   - 通过该函数注册的路由将在服务器关闭时正常等待请求结束
+//   - 当网络类型为 NetworkWebsocket 时，非 Websocket 升级请求将交由该路由器处理，使 HTTP 接口与 Websocket 共享端口
 //   - 如果需要自行包装 Context 对象，可以使用 NewHttpHandleWrapper 方法
+//   - 当网络类型不为 NetworkHttp 或 NetworkWebsocket 时将会发生 panic，在库代码中应使用不会发生 panic 的 GetHttpServer 或 TryHttpServer
 func (slf *Server) HttpServer() *Http[*HttpContext] {
+	server, err := slf.GetHttpServer()
+	if err != nil {
+		panic(err)
+	}
+	return server
+}
+
+// GetHttpServer 与 HttpServer 一致，不同的是当网络类型不为 NetworkHttp 或 NetworkWebsocket 时将返回 ErrNetworkOnlySupportHttp
+func (slf *Server) GetHttpServer() (*Http[*HttpContext], error) {
 	if slf.ginServer == nil {
-		panic(ErrNetworkOnlySupportHttp)
+		return nil, ErrNetworkOnlySupportHttp
 	}
 	return NewHttpHandleWrapper(slf, func(ctx *gin.Context) *HttpContext {
 		return NewHttpContext(ctx)
-	})
+	}), nil
+}
+
+// TryHttpServer 与 HttpServer 一致，不同的是当网络类型不为 NetworkHttp 或 NetworkWebsocket 时 ok 将返回 false
+func (slf *Server) TryHttpServer() (server *Http[*HttpContext], ok bool) {
+	server, err := slf.GetHttpServer()
+	return server, err == nil
 }
 
 // GetMessageCount 获取当前服务器中消息的数量
@@ -572,15 +843,26 @@ func (slf *Server) GetMessageCount() int64 {
 	return slf.messageCounter.Load()
 }
 
+// GetWorkerPoolStats 获取通过 WithWorkerPool 启用的动态工作池的运行状态，当未启用或服务器未运行时 ok 将返回 false
+func (slf *Server) GetWorkerPoolStats() (stats WorkerPoolStats, ok bool) {
+	if slf.workerDispatcher == nil {
+		return stats, false
+	}
+	return slf.workerDispatcher.pool.stats(), true
+}
+
 // UseShunt 切换连接所使用的消息分流渠道，当分流渠道 name 不存在时将会创建一个新的分流渠道，否则将会加入已存在的分流渠道
 //   - 默认情况下，所有连接都使用系统通道进行消息分发，当指定消息分流渠道时，将会使用指定的消息分流渠道进行消息分发
+//   - 切换后该连接的新消息将立即由新的分流渠道处理，已在旧分流渠道中排队的消息仍会在旧渠道中处理完毕，两者之间不保证先后顺序
+//   - 旧分流渠道不再包含任何连接时，将在排队的消息处理完毕后被释放
 func (slf *Server) UseShunt(conn *Conn, name string) {
 	slf.dispatcherLock.Lock()
 	defer slf.dispatcherLock.Unlock()
 	d, exist := slf.dispatchers[name]
 	if !exist {
 		d = generateDispatcher(name, slf.dispatchMessage)
-		go d.start()
+		slf.dispatcherWait.Add(1)
+		go d.start(&slf.dispatcherWait)
 		slf.dispatchers[name] = d
 	}
 
@@ -592,7 +874,7 @@ func (slf *Server) UseShunt(conn *Conn, name string) {
 
 		delete(slf.dispatcherMember[curr.name], conn.GetID())
 		if len(slf.dispatcherMember[curr.name]) == 0 {
-			curr.close()
+			curr.closeWhenDrained()
 			delete(slf.dispatchers, curr.name)
 		}
 	}
@@ -604,6 +886,7 @@ func (slf *Server) UseShunt(conn *Conn, name string) {
 	}
 
 	member[conn.GetID()] = conn
+	slf.currDispatcher[conn.GetID()] = d
 }
 
 // getConnDispatcher 获取连接所使用的消息分发器
@@ -612,11 +895,38 @@ func (slf *Server) getConnDispatcher(conn *Conn) *dispatcher {
 		return slf.systemDispatcher
 	}
 	slf.dispatcherLock.RLock()
-	defer slf.dispatcherLock.RUnlock()
 	d, exist := slf.currDispatcher[conn.GetID()]
+	slf.dispatcherLock.RUnlock()
 	if exist {
 		return d
 	}
+	if slf.connMailbox {
+		return slf.useMailbox(conn)
+	}
+	if slf.workerDispatcher != nil {
+		return slf.workerDispatcher
+	}
+	if len(slf.shardDispatchers) > 0 {
+		return slf.shardDispatchers[shardIndex(slf.shardStrategy(conn), len(slf.shardDispatchers))]
+	}
+	return slf.systemDispatcher
+}
+
+// useMailbox 为连接分配独立的邮箱分发器，当连接已关闭时将返回系统分发器
+func (slf *Server) useMailbox(conn *Conn) *dispatcher {
+	if conn.IsClosed() {
+		return slf.systemDispatcher
+	}
+	slf.UseShunt(conn, fmt.Sprintf("%s:%s", serverMailboxDispatcher, conn.GetID()))
+	if conn.IsClosed() {
+		slf.releaseDispatcher(conn)
+		return slf.systemDispatcher
+	}
+	slf.dispatcherLock.RLock()
+	defer slf.dispatcherLock.RUnlock()
+	if d, exist := slf.currDispatcher[conn.GetID()]; exist {
+		return d
+	}
 	return slf.systemDispatcher
 }
 
@@ -639,20 +949,39 @@ func (slf *Server) releaseDispatcher(conn *Conn) {
 }
 
 // pushMessage 向服务器中写入特定类型的消息，需严格遵守消息属性要求
+//   - 当服务器处于关闭中状态时，仅接收正在处理的异步消息产生的回调消息，其他消息将被直接丢弃
+//   - 当服务器已停止时，消息将被直接丢弃
 func (slf *Server) pushMessage(message *Message) {
-	if slf.messagePool.IsClose() || !slf.OnMessageExecBeforeEvent(message) {
+	if !slf.OnMessageExecBeforeEvent(message) {
+		slf.messagePool.Release(message)
+		return
+	}
+	slf.messageLock.RLock()
+	defer slf.messageLock.RUnlock()
+	switch slf.state.Load() {
+	case serverStateDraining:
+		switch message.t {
+		case MessageTypeAsyncCallback, MessageTypeShuntAsyncCallback, MessageTypeUniqueAsyncCallback, MessageTypeUniqueShuntAsyncCallback:
+		default:
+			slf.messagePool.Release(message)
+			return
+		}
+	case serverStateStopped:
 		slf.messagePool.Release(message)
 		return
 	}
 	var dispatcher *dispatcher
-	switch message.t {
-	case MessageTypePacket,
-		MessageTypeShuntTicker, MessageTypeShuntAsync, MessageTypeShuntAsyncCallback,
-		MessageTypeUniqueShuntAsync, MessageTypeUniqueShuntAsyncCallback,
-		MessageTypeShunt:
-		dispatcher = slf.getConnDispatcher(message.conn)
-	case MessageTypeSystem, MessageTypeAsync, MessageTypeUniqueAsync, MessageTypeAsyncCallback, MessageTypeUniqueAsyncCallback, MessageTypeError, MessageTypeTicker:
-		dispatcher = slf.systemDispatcher
+	if typed := message.Typed(); typed != nil {
+		dispatcher = typed.dispatcher(slf)
+	} else {
+		switch message.t {
+		case MessageTypeShuntAsync, MessageTypeShuntAsyncCallback,
+			MessageTypeUniqueShuntAsync, MessageTypeUniqueShuntAsyncCallback,
+			MessageTypeShunt, MessageTypeShuntDelay:
+			dispatcher = slf.getConnDispatcher(message.conn)
+		case MessageTypeSystem, MessageTypeAsync, MessageTypeUniqueAsync, MessageTypeAsyncCallback, MessageTypeUniqueAsyncCallback, MessageTypeDelay:
+			dispatcher = slf.systemDispatcher
+		}
 	}
 	if dispatcher == nil {
 		return
@@ -662,22 +991,57 @@ func (slf *Server) pushMessage(message *Message) {
 		return
 	}
 	slf.messageCounter.Add(1)
-	dispatcher.put(message)
+	slf.metrics.push(message.t)
+	if !dispatcher.put(message) {
+		slf.messageCounter.Add(-1)
+		slf.messagePool.Release(message)
+	}
 }
 
+// low 记录消息的执行耗时，当耗时超过 expect 时将输出 WARN 类型的日志并触发 MessageLowExecEvent
+//   - expect <= 0 时表示不检查慢消息
+//   - message 为 nil 时表示 HTTP 请求，此时仅输出日志
 func (slf *Server) low(message *Message, present time.Time, expect time.Duration, messageReplace ...string) {
 	cost := time.Since(present)
-	if cost > expect {
+	var isLow = expect > 0 && cost > expect
+	if message == nil {
+		if isLow {
+			var fields = make([]log.Field, 0, len(messageReplace)+2)
+			fields = append(fields, log.String("type", "HTTP"), log.String("cost", cost.String()))
+			for i, s := range messageReplace {
+				fields = append(fields, log.String(fmt.Sprintf("Other-%d", i+1), s))
+			}
+			slf.Logger().Warn("Server", fields...)
+		}
+		return
+	}
+	slf.metrics.exec(message.t, cost, isLow)
+	if slf.messageExecStats != nil {
+		var name = message.GetRoute()
+		if len(name) == 0 {
+			name = message.GetHandlerName()
+		}
+		slf.messageExecStats.record(message.t, name, cost, isLow, message.failed)
+	}
+	if isLow {
 		if len(messageReplace) > 0 {
 			for i, s := range messageReplace {
 				message.marks = append(message.marks, log.String(fmt.Sprintf("Other-%d", i+1), s))
 			}
 		}
-		var fields = make([]log.Field, 0, len(message.marks)+4)
+		var fields = make([]log.Field, 0, len(message.marks)+6)
 		fields = append(fields, log.String("type", messageNames[message.t]), log.String("cost", cost.String()), log.String("message", message.String()))
+		if route := message.GetRoute(); len(route) > 0 {
+			fields = append(fields, log.String("route", route))
+		} else if handler := message.GetHandlerName(); len(handler) > 0 {
+			fields = append(fields, log.String("handler", handler))
+		}
+		if message.conn != nil {
+			fields = append(fields, log.String("ConnID", message.conn.GetID()))
+		}
 		fields = append(fields, message.marks...)
 		fields = append(fields, log.Stack("stack"))
-		log.Warn("Server", fields...)
+		slf.Logger().Warn("Server", fields...)
 		slf.OnMessageLowExecEvent(message, cost)
 	}
 }
@@ -694,79 +1058,74 @@ func (slf *Server) dispatchMessage(dispatcher *dispatcher, msg *Message) {
 			select {
 			case <-ctx.Done():
 				if err := ctx.Err(); err == context.DeadlineExceeded {
-					log.Warn("Server", log.String("MessageType", messageNames[msg.t]), log.String("Info", msg.String()), log.Any("SuspectedDeadlock", msg))
+					slf.Logger().Warn("Server", msg.logFields(log.String("Info", msg.String()), log.Any("SuspectedDeadlock", msg))...)
 				}
 			}
 		}(ctx, msg)
 	}
 
+	var execCancel context.CancelFunc
+	if slf.messageExecTimeout > 0 {
+		execCancel = slf.withMessageExecTimeout(msg)
+	}
+
 	present := time.Now()
 	if msg.t != MessageTypeAsync && msg.t != MessageTypeUniqueAsync && msg.t != MessageTypeShuntAsync && msg.t != MessageTypeUniqueShuntAsync {
 		defer func(msg *Message) {
 			if err := recover(); err != nil {
+				msg.failed = true
 				stack := string(debug.Stack())
-				log.Error("Server", log.String("MessageType", messageNames[msg.t]), log.String("Info", msg.String()), log.Any("error", err), log.String("stack", stack))
+				slf.Logger().Error("Server", msg.logFields(log.String("Info", msg.String()), log.Any("error", err), log.String("stack", stack))...)
 				fmt.Println(stack)
 				if e, ok := err.(error); ok {
 					slf.OnMessageErrorEvent(msg, e)
 				}
+				slf.applyPanicPolicy(msg.conn, err, []byte(stack), PanicPolicyRecover)
 			}
 			if msg.t == MessageTypeUniqueAsyncCallback || msg.t == MessageTypeUniqueShuntAsyncCallback {
 				dispatcher.antiUnique(msg.name)
 			}
 
 			super.Handle(cancel)
-			slf.low(msg, present, time.Millisecond*100)
+			super.Handle(execCancel)
+			slf.low(msg, present, slf.lowMessageDuration)
 			slf.messageCounter.Add(-1)
-
-			if !slf.isShutdown.Load() {
-				slf.messagePool.Release(msg)
-			}
-
+			slf.messagePool.Release(msg)
 		}(msg)
 	}
 
+	if typed := msg.Typed(); typed != nil {
+		typed.handle(slf)
+		return
+	}
+
 	switch msg.t {
-	case MessageTypePacket:
-		if !slf.OnConnectionPacketPreprocessEvent(msg.conn, msg.packet, func(newPacket []byte) { msg.packet = newPacket }) {
-			slf.OnConnectionReceivePacketEvent(msg.conn, msg.packet)
-		}
-	case MessageTypeError:
-		switch msg.errAction {
-		case MessageErrorActionNone:
-			log.Panic("Server", log.Err(msg.err))
-		case MessageErrorActionShutdown:
-			slf.shutdown(msg.err)
-		default:
-			log.Warn("Server", log.String("not support message error action", msg.errAction.String()))
-		}
-	case MessageTypeTicker, MessageTypeShuntTicker:
-		msg.ordinaryHandler()
 	case MessageTypeAsync, MessageTypeShuntAsync, MessageTypeUniqueAsync, MessageTypeUniqueShuntAsync:
 		if err := slf.ants.Submit(func() {
 			defer func() {
 				if err := recover(); err != nil {
+					msg.failed = true
 					if msg.t == MessageTypeUniqueAsync || msg.t == MessageTypeUniqueShuntAsync {
 						dispatcher.antiUnique(msg.name)
 					}
 					stack := string(debug.Stack())
-					log.Error("Server", log.String("MessageType", messageNames[msg.t]), log.Any("error", err), log.String("stack", stack))
+					slf.Logger().Error("Server", msg.logFields(log.Any("error", err), log.String("stack", stack))...)
 					fmt.Println(stack)
 					if e, ok := err.(error); ok {
 						slf.OnMessageErrorEvent(msg, e)
 					}
+					slf.applyPanicPolicy(msg.conn, err, []byte(stack), PanicPolicyRecover)
 				}
 				super.Handle(cancel)
-				slf.low(msg, present, time.Second)
+				super.Handle(execCancel)
+				slf.low(msg, present, slf.asyncLowMessageDuration)
 				slf.messageCounter.Add(-1)
-
-				if !slf.isShutdown.Load() {
-					slf.messagePool.Release(msg)
-				}
+				slf.messagePool.Release(msg)
 			}()
 			var err error
 			if msg.exceptionHandler != nil {
 				err = msg.exceptionHandler()
+				msg.failed = err != nil
 			}
 			if msg.errHandler != nil {
 				if msg.conn == nil {
@@ -786,7 +1145,7 @@ func (slf *Server) dispatchMessage(dispatcher *dispatcher, msg *Message) {
 			}
 			dispatcher.antiUnique(msg.name)
 			if err != nil {
-				log.Error("Server", log.String("MessageType", messageNames[msg.t]), log.Any("error", err), log.String("stack", string(debug.Stack())))
+				slf.Logger().Error("Server", msg.logFields(log.Any("error", err), log.String("stack", string(debug.Stack())))...)
 			}
 		}); err != nil {
 			panic(err)
@@ -795,9 +1154,37 @@ func (slf *Server) dispatchMessage(dispatcher *dispatcher, msg *Message) {
 		msg.errHandler(msg.err)
 	case MessageTypeSystem, MessageTypeShunt:
 		msg.ordinaryHandler()
+	case MessageTypeDelay, MessageTypeShuntDelay:
+		if msg.delay.execute() {
+			msg.ordinaryHandler()
+		}
 	default:
-		log.Warn("Server", log.String("not support message type", msg.t.String()))
+		slf.Logger().Warn("Server", log.String("not support message type", msg.t.String()))
+	}
+}
+
+// withMessageExecTimeout 为消息设置执行超时的上下文，当消息执行超时时将在独立的协程中触发 MessageTimeoutEvent
+//   - MessageTypePacket 消息的上下文同时可通过 Conn.Context 获取
+//   - 返回的函数需要在消息执行完成后调用
+func (slf *Server) withMessageExecTimeout(msg *Message) context.CancelFunc {
+	var parent = slf.ctx
+	if msg.t == MessageTypePacket && msg.conn.ctx != nil {
+		parent = msg.conn.ctx
+	}
+	ctx, cancel := context.WithTimeout(parent, slf.messageExecTimeout)
+	msg.ctx = ctx
+	if msg.t == MessageTypePacket {
+		msg.conn.ctx = ctx
 	}
+	// 消息执行完成后将被回收复用，因此事件中传递的是执行前的快照
+	var snapshot = *msg
+	context.AfterFunc(ctx, func() {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			slf.Logger().Warn("Server", snapshot.logFields(log.String("Info", snapshot.String()), log.String("MessageExecTimeout", slf.messageExecTimeout.String()))...)
+			slf.OnMessageTimeoutEvent(&snapshot, slf.messageExecTimeout)
+		}
+	})
+	return cancel
 }
 
 // PushSystemMessage 向服务器中推送 MessageTypeSystem 消息
@@ -839,12 +1226,101 @@ func (slf *Server) PushShuntAsyncCallbackMessage(conn *Conn, err error, callback
 // PushPacketMessage 向服务器中推送 MessageTypePacket 消息
 //   - 当存在 WithShunt 的选项时，将会根据选项中的 shuntMatcher 进行分发，否则将在系统分发器中处理消息
 func (slf *Server) PushPacketMessage(conn *Conn, wst int, packet []byte, mark ...log.Field) {
+	slf.pushPacketMessage(conn, wst, packet, nil, mark...)
+}
+
+// pushPacketMessage 向服务器中推送 MessageTypePacket 消息，buffer 为数据包所在的缓冲区，将在消息处理完成后归还缓冲池
+func (slf *Server) pushPacketMessage(conn *Conn, wst int, packet []byte, buffer *packetBuffer, mark ...log.Field) {
+	slf.metrics.receive(packet)
+	if conn.crypto != nil {
+		var handshake bool
+		var err error
+		if packet, handshake, err = conn.crypto.receive(conn, packet); err != nil {
+			buffer.release()
+			conn.CloseWithReason(CloseReasonProtocolError, err)
+			return
+		} else if handshake {
+			buffer.release()
+			return
+		}
+	}
+	if slf.compression != nil && !conn.IsBot() && conn.gw == nil {
+		var err error
+		if packet, err = slf.compression.decompress(packet); err != nil {
+			buffer.release()
+			conn.CloseWithReason(CloseReasonProtocolError, err)
+			return
+		}
+	}
+	if !slf.allowPacket(conn, packet) {
+		buffer.release()
+		return
+	}
+	var ok bool
+	if packet, ok = slf.checkPacketSequence(conn, packet); !ok {
+		buffer.release()
+		return
+	}
+	slf.capture.record(CaptureDirectionInbound, conn, packet)
+	slf.packetLogging.record(CaptureDirectionInbound, conn, packet)
 	slf.pushMessage(slf.messagePool.Get().castToPacketMessage(
-		&Conn{wst: wst, connection: conn.connection},
+		&Conn{wst: wst, connection: conn.connection, buffer: buffer},
 		packet,
 	))
 }
 
+// receivePacket 接收来自连接的原始数据，当存在编解码器时将解码为完整的数据包后再推送 MessageTypePacket 消息
+//   - data 在函数返回后允许被复用
+func (slf *Server) receivePacket(conn *Conn, wst int, data []byte) {
+	slf.receivePacketWithBuffer(conn, wst, &conn.codecBuffer, data)
+}
+
+// receivePacketWithBuffer 与 receivePacket 相同，但使用 codecBuffer 作为解码缓冲区
+//   - 同一连接存在多个并发读取来源（例如 WebTransport 的多个流）时，每个来源应持有独立的缓冲区，避免数据交错
+func (slf *Server) receivePacketWithBuffer(conn *Conn, wst int, codecBuffer *[]byte, data []byte) {
+	conn.refreshActive()
+	if slf.codec == nil {
+		if slf.maxPacketSize > 0 && len(data) > slf.maxPacketSize {
+			slf.oversizePacket(conn, len(data))
+			return
+		}
+		packet, buffer := slf.packetBuffers.clone(data)
+		slf.pushPacketMessage(conn, wst, packet, buffer)
+		return
+	}
+	*codecBuffer = append(*codecBuffer, data...)
+	for len(*codecBuffer) > 0 {
+		packet, n, err := slf.codec.Decode(*codecBuffer)
+		if err != nil {
+			*codecBuffer = nil
+			conn.CloseWithReason(CloseReasonProtocolError, err)
+			return
+		}
+		if n <= 0 {
+			if slf.maxPacketSize > 0 && len(*codecBuffer) > slf.maxPacketSize+maxPacketFrameOverhead {
+				size := len(*codecBuffer)
+				*codecBuffer = nil
+				slf.oversizePacket(conn, size)
+				return
+			}
+			break
+		}
+		if slf.maxPacketSize > 0 && len(packet) > slf.maxPacketSize {
+			*codecBuffer = nil
+			slf.oversizePacket(conn, len(packet))
+			return
+		}
+		cloned, buffer := slf.packetBuffers.clone(packet)
+		slf.pushPacketMessage(conn, wst, cloned, buffer)
+		*codecBuffer = (*codecBuffer)[n:]
+	}
+	if len(*codecBuffer) == 0 {
+		*codecBuffer = nil
+	} else if cap(*codecBuffer) > 2*len(*codecBuffer) {
+		*codecBuffer = bytes.Clone(*codecBuffer)
+	}
+}
+
 // PushTickerMessage 向服务器中推送 MessageTypeTicker 消息
 //   - 通过该函数推送定时消息，当消息触发时将在系统分发器中处理消息
 //   - 可通过 timer.Ticker 或第三方定时器将执行函数(caller)推送到该消息中进行处理，可有效的避免线程安全问题
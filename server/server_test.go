@@ -1,15 +1,23 @@
 package server_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/kercylan98/minotaur/server"
 	"github.com/kercylan98/minotaur/server/client"
 	"github.com/kercylan98/minotaur/utils/times"
+	"net"
+	"os"
+	"sync"
 	"testing"
 	"time"
 )
 
 func TestNew(t *testing.T) {
+	if len(os.Getenv("MINOTAUR_TEST_MANUAL")) == 0 {
+		t.Skip("manual test, set MINOTAUR_TEST_MANUAL to run it")
+	}
 	//limiter := rate.NewLimiter(rate.Every(time.Second), 100)
 	srv := server.New(server.NetworkWebsocket, server.WithMessageBufferSize(1024*1024), server.WithPProf())
 	//srv.RegMessageExecBeforeEvent(func(srv *server.Server, message *server.Message) bool {
@@ -20,7 +28,7 @@ func TestNew(t *testing.T) {
 	//	}
 	//	return true
 	//})
-	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, err any) {
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
 		fmt.Println("关闭", conn.GetID(), err, "Count", srv.GetOnlineCount())
 	})
 	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
@@ -38,6 +46,9 @@ func TestNew(t *testing.T) {
 }
 
 func TestNewClient(t *testing.T) {
+	if len(os.Getenv("MINOTAUR_TEST_MANUAL")) == 0 {
+		t.Skip("manual test, set MINOTAUR_TEST_MANUAL to run it")
+	}
 	count := 500
 	for i := 0; i < count; i++ {
 		id := i
@@ -66,3 +77,514 @@ func TestNewClient(t *testing.T) {
 
 	time.Sleep(times.Week)
 }
+
+func TestServer_ShutdownRace(t *testing.T) {
+	srv := server.New(server.NetworkNone)
+	var stop = make(chan struct{})
+	var wait sync.WaitGroup
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		for i := 0; i < 8; i++ {
+			wait.Add(1)
+			go func() {
+				defer wait.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						srv.PushSystemMessage(func() {})
+						srv.PushAsyncMessage(func() error { return nil }, func(err error) {})
+						time.Sleep(time.Microsecond * 100)
+					}
+				}
+			}()
+		}
+		go func() {
+			time.Sleep(time.Millisecond * 100)
+			srv.Shutdown()
+		}()
+	})
+	if err := srv.RunNone(); err != nil {
+		t.Fatal(err)
+	}
+	close(stop)
+	wait.Wait()
+	if count := srv.GetMessageCount(); count != 0 {
+		t.Fatalf("expected no pending messages after shutdown, got %d", count)
+	}
+}
+
+func TestServer_AddListener(t *testing.T) {
+	var tcpAddr = freeAddr(t, "tcp")
+	srv := server.New(server.NetworkWebsocket).AddListener(server.NetworkTcp, tcpAddr)
+	var received = make(chan server.Network, 1)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		received <- conn.GetNetwork()
+	})
+	runServer(t, srv, freeAddr(t, "tcp")+"/ws")
+	defer srv.Shutdown()
+
+	conn, err := net.Dial("tcp", tcpAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err = conn.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case network := <-received:
+		if network != server.NetworkTcp {
+			t.Fatalf("expected %s, got %s", server.NetworkTcp, network)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("packet from the additional listener was not received")
+	}
+}
+
+func TestWithMessageExecTimeout(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket, server.WithMessageExecTimeout(50*time.Millisecond))
+	var cancelled = make(chan struct{})
+	var timeout = make(chan server.MessageType, 1)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		select {
+		case <-conn.Context().Done():
+			close(cancelled)
+		case <-time.After(time.Second):
+		}
+	})
+	srv.RegMessageTimeoutEvent(func(srv *server.Server, message *server.Message, d time.Duration) {
+		if message.MessageType() == server.MessageTypePacket {
+			timeout <- message.MessageType()
+		}
+	})
+	var bot *server.Bot
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		bot = server.NewBot(srv)
+		bot.JoinServer()
+	})
+	runServer(t, srv, freeAddr(t, "tcp")+"/timeout")
+	defer srv.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+	bot.SendPacket([]byte("hello"))
+	select {
+	case mt := <-timeout:
+		if mt != server.MessageTypePacket {
+			t.Fatalf("expected %s, got %s", server.MessageTypePacket, mt)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("message timeout event was not triggered")
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("message context was not cancelled")
+	}
+}
+
+func TestWithPanicPolicy(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket, server.WithPanicPolicy(server.PanicPolicyCloseConnection))
+	var panicked = make(chan any, 1)
+	var closed = make(chan server.CloseReason, 1)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		panic("boom")
+	})
+	srv.RegConnectionPanicEvent(func(srv *server.Server, conn *server.Conn, err any, stack []byte) {
+		if len(stack) == 0 {
+			t.Error("expected stack")
+		}
+		panicked <- err
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
+		closed <- reason
+	})
+	var bot *server.Bot
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		bot = server.NewBot(srv)
+		bot.JoinServer()
+	})
+	runServer(t, srv, freeAddr(t, "tcp")+"/panic")
+	defer srv.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+	bot.SendPacket([]byte("hello"))
+	select {
+	case err := <-panicked:
+		if err != "boom" {
+			t.Fatalf("expected boom, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection panic event was not triggered")
+	}
+	select {
+	case reason := <-closed:
+		if reason != server.CloseReasonPanic {
+			t.Fatalf("expected %s, got %s", server.CloseReasonPanic, reason)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("connection was not closed")
+	}
+}
+
+func TestServer_RegShutdownBeforeEvent(t *testing.T) {
+	srv := server.New(server.NetworkNone)
+	var count int
+	srv.RegShutdownBeforeEvent(func(srv *server.Server, err error) bool {
+		count++
+		return count > 1
+	})
+	var stopped bool
+	srv.RegStopEvent(func(srv *server.Server) {
+		stopped = true
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go func() {
+			srv.Shutdown()
+			time.Sleep(100 * time.Millisecond)
+			srv.Shutdown()
+		}()
+	})
+	if err := srv.RunNone(); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("expected shutdown before event to be triggered 2 times, got %d", count)
+	}
+	if !stopped {
+		t.Fatal("stop event was not triggered")
+	}
+}
+
+func TestServer_RunBindError(t *testing.T) {
+	for network, path := range map[server.Network]string{
+		server.NetworkTcp:       "",
+		server.NetworkWebsocket: "/bind",
+	} {
+		occupied, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var addr = occupied.Addr().String() + path
+		var result = make(chan error, 1)
+		go func(network server.Network, addr string) {
+			result <- server.New(network).Run(addr)
+		}(network, addr)
+		select {
+		case err = <-result:
+			if err == nil {
+				t.Fatalf("%s: expected bind error, got nil", network)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: run did not return bind error", network)
+		}
+		_ = occupied.Close()
+	}
+}
+
+func TestServer_RunContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	srv := server.New(server.NetworkNone)
+	srv.RegShutdownBeforeEvent(func(srv *server.Server, err error) bool {
+		return false
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		go cancel()
+	})
+	var result = make(chan error, 1)
+	go func() {
+		result <- srv.RunContext(ctx, "")
+	}()
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server was not shutdown after the context was cancelled")
+	}
+	if srv.Context().Err() == nil {
+		t.Fatal("server context was not cancelled after shutdown")
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	srv := server.New(server.NetworkNone, server.WithContext(ctx))
+	if err := srv.RunNone(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+type countdownWriter struct {
+	packets chan string
+}
+
+func (slf *countdownWriter) Write(p []byte) (int, error) {
+	slf.packets <- string(p)
+	return len(p), nil
+}
+
+func TestServer_ShutdownAfter(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket)
+	var writer = &countdownWriter{packets: make(chan string, 10)}
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		srv.ShutdownAfter(2*time.Second, func(remaining time.Duration) []byte {
+			return []byte(remaining.String())
+		})
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		bot := server.NewBot(srv)
+		bot.SetWriter(writer)
+		bot.JoinServer()
+	})
+	var result = make(chan error, 1)
+	go func() {
+		result <- srv.Run(freeAddr(t, "tcp") + "/countdown")
+	}()
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("server was not shutdown after the countdown")
+	}
+	// 机器人的写入循环可能仍在运行，因此不关闭通道，仅在超时时间内读取倒计时
+	var packets []string
+	for len(packets) < 2 {
+		select {
+		case packet := <-writer.packets:
+			packets = append(packets, packet)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected countdown [2s 1s], got %v", packets)
+		}
+	}
+	select {
+	case packet := <-writer.packets:
+		t.Fatalf("unexpected countdown packet %s after %v", packet, packets)
+	default:
+	}
+	if packets[0] != "2s" || packets[1] != "1s" {
+		t.Fatalf("expected countdown [2s 1s], got %v", packets)
+	}
+}
+
+func TestServer_RegConnectionWritePacketBeforeEvent(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket)
+	srv.RegConnectionWritePacketBeforeEvent(func(srv *server.Server, conn *server.Conn, packet []byte) []byte {
+		return append([]byte("v2:"), packet...)
+	})
+	srv.RegConnectionWritePacketBeforeEvent(func(srv *server.Server, conn *server.Conn, packet []byte) []byte {
+		if string(packet) == "v2:secret" {
+			return nil
+		}
+		return packet
+	})
+	var writer = &countdownWriter{packets: make(chan string, 10)}
+	var dropped = make(chan error, 1)
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		conn.Write([]byte("secret"), func(err error) {
+			dropped <- err
+		})
+		conn.Write([]byte("hello"))
+	})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		bot := server.NewBot(srv)
+		bot.SetWriter(writer)
+		bot.JoinServer()
+	})
+	runServer(t, srv, freeAddr(t, "tcp")+"/write")
+	defer srv.Shutdown()
+	select {
+	case err := <-dropped:
+		if !errors.Is(err, server.ErrPacketDropped) {
+			t.Fatalf("expected %v, got %v", server.ErrPacketDropped, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("packet was not dropped")
+	}
+	select {
+	case packet := <-writer.packets:
+		if packet != "v2:hello" {
+			t.Fatalf("expected v2:hello, got %s", packet)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("packet was not written")
+	}
+}
+
+func TestServer_GetCapability(t *testing.T) {
+	srv := server.New(server.NetworkNone)
+	if _, err := srv.GetTicker(); !errors.Is(err, server.ErrNoSupportTicker) {
+		t.Fatalf("expected ErrNoSupportTicker, got %v", err)
+	}
+	if _, ok := srv.TryTicker(); ok {
+		t.Fatal("expected no ticker")
+	}
+	if _, err := srv.GetGRPCServer(); !errors.Is(err, server.ErrNetworkOnlySupportGRPC) {
+		t.Fatalf("expected ErrNetworkOnlySupportGRPC, got %v", err)
+	}
+	if _, err := srv.GetHttpServer(); !errors.Is(err, server.ErrNetworkOnlySupportHttp) {
+		t.Fatalf("expected ErrNetworkOnlySupportHttp, got %v", err)
+	}
+	if _, ok := srv.TryHttpRouter(); ok {
+		t.Fatal("expected no http router")
+	}
+	if _, err := srv.GetMetricsHandler(); !errors.Is(err, server.ErrNoSupportMetrics) {
+		t.Fatalf("expected ErrNoSupportMetrics, got %v", err)
+	}
+	if _, err := srv.GetWebsocketRoute("/chat"); !errors.Is(err, server.ErrNetworkOnlySupportWebsocket) {
+		t.Fatalf("expected ErrNetworkOnlySupportWebsocket, got %v", err)
+	}
+	if _, ok := server.New(server.NetworkHttp).TryHttpServer(); !ok {
+		t.Fatal("expected the http server to be available")
+	}
+}
+
+func TestServer_RunReleasesOnStartupFailure(t *testing.T) {
+	occupied, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer occupied.Close()
+
+	// 管理服务端口被占用时，已绑定的主侦听器及额外侦听器均应被释放
+	var mainAddr, extraAddr = freeAddr(t, "tcp"), freeAddr(t, "tcp")
+	srv := server.New(server.NetworkTcp, server.WithAdmin(occupied.Addr().String()), server.WithShard(2)).
+		AddListener(server.NetworkTcp, extraAddr)
+	var result = make(chan error, 1)
+	go func() {
+		result <- srv.Run(mainAddr)
+	}()
+	select {
+	case err = <-result:
+		if err == nil {
+			t.Fatal("expected Run to fail when the admin address is in use")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after a startup failure")
+	}
+	for _, addr := range []string{mainAddr, extraAddr} {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			t.Fatalf("expected %s to be released after a startup failure: %v", addr, err)
+		}
+		_ = listener.Close()
+	}
+
+	retry := server.New(server.NetworkTcp)
+	runServer(t, retry, mainAddr)
+	retry.Shutdown()
+}
+
+func TestServer_Broadcast(t *testing.T) {
+	srv := server.New(server.NetworkNone)
+	runServer(t, srv, "")
+	defer srv.Shutdown()
+
+	var lock sync.Mutex
+	var received = map[string][]string{}
+	var conns []*server.Conn
+	for i := 0; i < 4; i++ {
+		var conn *server.Conn
+		conn = server.NewGatewayConn(srv, "127.0.0.1", func(packet []byte) {
+			lock.Lock()
+			received[conn.GetID()] = append(received[conn.GetID()], string(packet))
+			lock.Unlock()
+		})
+		conns = append(conns, conn)
+		srv.OnConnectionOpenedEvent(conn)
+	}
+	for deadline := time.Now().Add(time.Second); srv.GetOnlineCount() < len(conns); {
+		if time.Now().After(deadline) {
+			t.Fatal("expected all connections to be online")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if conn, exist := srv.GetConn(conns[0].GetID()); !exist || conn != conns[0] {
+		t.Fatal("expected GetConn to find the connection")
+	}
+	if _, exist := srv.GetConn("unknown"); exist {
+		t.Fatal("expected GetConn to miss an unknown connection")
+	}
+	var ranged int
+	srv.RangeConn(func(conn *server.Conn) bool {
+		ranged++
+		return ranged < 2
+	})
+	if ranged != 2 {
+		t.Fatalf("expected RangeConn to stop after 2 connections, got %d", ranged)
+	}
+
+	srv.Broadcast([]byte("all"))
+	srv.BroadcastFilter([]byte("filter"), func(conn *server.Conn) bool {
+		return conn == conns[1] || conn == conns[2]
+	})
+	srv.BroadcastExcept([]byte("except"), conns[0].GetID(), conns[3].GetID())
+
+	var expected = [][]string{
+		{"all"},
+		{"all", "filter", "except"},
+		{"all", "filter", "except"},
+		{"all"},
+	}
+	for deadline := time.Now().Add(time.Second); ; {
+		lock.Lock()
+		var matched = true
+		for i, conn := range conns {
+			if fmt.Sprint(received[conn.GetID()]) != fmt.Sprint(expected[i]) {
+				matched = false
+			}
+		}
+		snapshot := fmt.Sprint(received)
+		lock.Unlock()
+		if matched {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("unexpected broadcast result: %s", snapshot)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// freeAddr 获取本地空闲端口的地址，network 为 "udp" 时将获取 UDP 端口，否则获取 TCP 端口
+func freeAddr(t *testing.T, network string) string {
+	t.Helper()
+	if network == "udp" {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		return conn.LocalAddr().String()
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+// runServer 在新的协程中运行服务器并等待启动完成，运行失败或启动超时时将终止测试
+//   - 需要在注册其他事件后调用，返回时 StartFinishEvent 已经执行完毕
+func runServer(t *testing.T, srv *server.Server, addr string) {
+	t.Helper()
+	var ready = make(chan struct{})
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		close(ready)
+	})
+	var result = make(chan error, 1)
+	go func() {
+		result <- srv.Run(addr)
+	}()
+	select {
+	case <-ready:
+	case err := <-result:
+		t.Fatalf("server run failed: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server was not started")
+	}
+}
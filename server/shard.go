@@ -0,0 +1,49 @@
+package server
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// ShardStrategy 消息分片策略，返回连接所属实体的分片键，相同分片键的消息将始终由同一个分片分发器按顺序处理
+//   - 例如返回房间 ID 即可使同一房间内所有连接的消息在同一个分片中串行处理
+//   - 当分片键发生变化时，新旧分片中的消息不保证先后顺序
+type ShardStrategy func(conn *Conn) string
+
+// ShardByConnID 以连接 ID 作为分片键的分片策略
+func ShardByConnID() ShardStrategy {
+	return func(conn *Conn) string {
+		return conn.GetID()
+	}
+}
+
+// ShardByData 以连接中特定 key 的数据作为分片键的分片策略，适用于通过 Conn.SetData 记录房间 ID 等情况
+//   - 当连接中不存在该数据时，将以连接 ID 作为分片键
+func ShardByData(key any) ShardStrategy {
+	return func(conn *Conn) string {
+		if value := conn.GetData(key); value != nil {
+			return fmt.Sprint(value)
+		}
+		return conn.GetID()
+	}
+}
+
+// shardDispatcherName 获取分片分发器名称
+func shardDispatcherName(index int) string {
+	return fmt.Sprintf("%s-%d", serverShardDispatcher, index)
+}
+
+// shardIndex 通过一致性哈希（Jump Consistent Hash）计算分片键所属的分片
+//   - 当分片数量发生变化时，仅有最少量的分片键会被重新分配
+func shardIndex(key string, shards int) int {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(key))
+	var k = hash.Sum64()
+	var b, j int64 = -1, 0
+	for j < int64(shards) {
+		b = j
+		k = k*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((k>>33)+1)))
+	}
+	return int(b)
+}
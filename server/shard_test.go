@@ -0,0 +1,38 @@
+package server
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestShardIndex(t *testing.T) {
+	const shards = 8
+	var counter = make([]int, shards)
+	for i := 0; i < 10000; i++ {
+		key := strconv.Itoa(i)
+		index := shardIndex(key, shards)
+		if index < 0 || index >= shards {
+			t.Fatalf("shard index out of range: %d", index)
+		}
+		if index != shardIndex(key, shards) {
+			t.Fatalf("shard index of %s is not stable", key)
+		}
+		counter[index]++
+	}
+	for i, count := range counter {
+		if count == 0 {
+			t.Fatalf("shard %d received no keys", i)
+		}
+	}
+
+	var moved int
+	for i := 0; i < 10000; i++ {
+		key := strconv.Itoa(i)
+		if shardIndex(key, shards) != shardIndex(key, shards+1) {
+			moved++
+		}
+	}
+	if moved > 10000/(shards+1)*2 {
+		t.Fatalf("too many keys moved after adding a shard: %d", moved)
+	}
+}
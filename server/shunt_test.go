@@ -0,0 +1,124 @@
+package server_test
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestServer_UseShuntWithInFlightMessages(t *testing.T) {
+	const packets = 200
+	srv := server.New(server.NetworkTcp, server.WithCodec(server.NewLineCodec(0)))
+	var received atomic.Int32
+	var done = make(chan struct{})
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		if string(packet) == "start" {
+			srv.UseShunt(conn, "a")
+			conn.Write([]byte("ok\n"))
+			return
+		}
+		// 处理缓慢，使切换时旧分流渠道中仍有排队的消息
+		time.Sleep(time.Millisecond)
+		if seq, _ := strconv.Atoi(string(packet)); seq == 10 {
+			srv.UseShunt(conn, "b")
+		}
+		if received.Add(1) == packets {
+			close(done)
+		}
+	})
+	var addr = freeAddr(t, "tcp")
+	runServer(t, srv, addr)
+	defer srv.Shutdown()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err = conn.Write([]byte("start\n")); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err = conn.Read(make([]byte, 3)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= packets; i++ {
+		if _, err = fmt.Fprintf(conn, "%d\n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected %d packets to be handled after switching shunt, got %d", packets, received.Load())
+	}
+}
+
+func TestServer_PushShuntTickerMessage(t *testing.T) {
+	srv := server.New(server.NetworkTcp, server.WithCodec(server.NewLineCodec(0)))
+	var serverConn atomic.Pointer[server.Conn]
+	var blocking, release = make(chan struct{}), make(chan struct{})
+	var lock sync.Mutex
+	var order []string
+	record := func(name string) {
+		lock.Lock()
+		defer lock.Unlock()
+		order = append(order, name)
+	}
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		switch string(packet) {
+		case "join":
+			serverConn.Store(conn)
+			srv.UseShunt(conn, "room")
+			conn.Write([]byte("ok\n"))
+		case "block":
+			close(blocking)
+			select {
+			case <-release:
+			case <-time.After(5 * time.Second):
+			}
+			record("packet")
+		}
+	})
+	var addr = freeAddr(t, "tcp")
+	runServer(t, srv, addr)
+	defer srv.Shutdown()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, _ = conn.Write([]byte("join\n"))
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err = conn.Read(make([]byte, 3)); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = conn.Write([]byte("block\n"))
+	<-blocking
+
+	// 分流定时器应在连接所处的分流渠道中执行，因此需要等待阻塞中的数据包处理完毕
+	var ticked = make(chan struct{})
+	srv.PushShuntTickerMessage(serverConn.Load(), "tick", func() {
+		record("tick")
+		close(ticked)
+	})
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	select {
+	case <-ticked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shunt ticker message was not handled")
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if len(order) != 2 || order[0] != "packet" || order[1] != "tick" {
+		t.Fatalf("expected shunt ticker to run after the blocked packet on the same shunt, got %v", order)
+	}
+}
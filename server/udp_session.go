@@ -0,0 +1,87 @@
+package server
+
+import (
+	"github.com/panjf2000/gnet"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// udpSessions UDP 侦听器的虚拟会话表，以远程地址区分不同的连接
+type udpSessions struct {
+	sessions map[string]*Conn
+	lock     sync.Mutex
+}
+
+// newUDPSessions 创建 UDP 虚拟会话表
+func newUDPSessions() *udpSessions {
+	return &udpSessions{sessions: map[string]*Conn{}}
+}
+
+// isUDPNetwork 检查网络类型是否为 UDP
+func isUDPNetwork(network Network) bool {
+	switch network {
+	case NetworkUdp, NetworkUdp4, NetworkUdp6:
+		return true
+	default:
+		return false
+	}
+}
+
+// session 获取数据包所属的虚拟会话，当会话不存在或已关闭时将创建新的会话并触发 ConnectionOpenedEvent
+//   - 当远程地址被连接过滤器拒绝时将返回 nil，数据包将被丢弃
+func (slf *gNet) session(c gnet.Conn) *Conn {
+	key := c.RemoteAddr().String()
+	slf.udp.lock.Lock()
+	conn, exist := slf.udp.sessions[key]
+	if exist && !conn.IsClosed() {
+		slf.udp.lock.Unlock()
+		return conn
+	}
+	ip := key
+	if index := strings.LastIndex(ip, ":"); index != -1 {
+		ip = ip[0:index]
+	}
+	if !slf.filterConnection(ip) {
+		delete(slf.udp.sessions, key)
+		slf.udp.lock.Unlock()
+		return nil
+	}
+	conn = newUDPConn(slf.Server, slf.network, c)
+	conn.filtered = slf.connFilter != nil
+	slf.udp.sessions[key] = conn
+	slf.udp.lock.Unlock()
+	slf.protectConn(conn, func() { slf.OnConnectionOpenedEvent(conn) })
+	return conn
+}
+
+// expire 关闭空闲超时的虚拟会话，并清理已关闭的会话
+func (slf *gNet) expire() {
+	var now = time.Now()
+	var expired []*Conn
+	slf.udp.lock.Lock()
+	for key, conn := range slf.udp.sessions {
+		switch {
+		case conn.IsClosed():
+			delete(slf.udp.sessions, key)
+		case now.Sub(conn.GetLastActiveTime()) >= slf.udpSessionTimeout:
+			delete(slf.udp.sessions, key)
+			expired = append(expired, conn)
+		}
+	}
+	slf.udp.lock.Unlock()
+	for _, conn := range expired {
+		conn.CloseWithReason(CloseReasonReadTimeout, ErrUDPSessionTimeout)
+	}
+}
+
+// newUDPConn 创建一个 UDP 虚拟会话连接
+//   - gnet 在数据包处理完成后会回收远程地址，因此需要复制一份远程地址
+func newUDPConn(server *Server, network Network, conn gnet.Conn) *Conn {
+	c := newGNetConn(server, network, conn)
+	if addr, ok := conn.RemoteAddr().(*net.UDPAddr); ok {
+		c.remoteAddr = &net.UDPAddr{IP: append(net.IP(nil), addr.IP...), Port: addr.Port, Zone: addr.Zone}
+	}
+	return c
+}
@@ -0,0 +1,61 @@
+package server_test
+
+import (
+	"github.com/kercylan98/minotaur/server"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithUDPSessionTimeout(t *testing.T) {
+	srv := server.New(server.NetworkUdp, server.WithUDPSessionTimeout(300*time.Millisecond))
+	var opened atomic.Int32
+	var closed = make(chan server.CloseReason, 1)
+	srv.RegConnectionOpenedEvent(func(srv *server.Server, conn *server.Conn) {
+		opened.Add(1)
+		conn.SetData("count", 0)
+	})
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		count := conn.GetData("count").(int) + 1
+		conn.SetData("count", count)
+		conn.Write([]byte{byte(count)})
+	})
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
+		closed <- reason
+	})
+	var addr = freeAddr(t, "udp")
+	runServer(t, srv, addr)
+	defer srv.Shutdown()
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var buf = make([]byte, 16)
+	for i := 1; i <= 2; i++ {
+		if _, err = conn.Write([]byte("hello")); err != nil {
+			t.Fatal(err)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != 1 || int(buf[0]) != i {
+			t.Fatalf("expected session packet count %d, got %v", i, buf[:n])
+		}
+	}
+	if count := opened.Load(); count != 1 {
+		t.Fatalf("expected 1 opened session, got %d", count)
+	}
+	select {
+	case reason := <-closed:
+		if reason != server.CloseReasonReadTimeout {
+			t.Fatalf("expected %s, got %s", server.CloseReasonReadTimeout, reason)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("udp session was not expired")
+	}
+}
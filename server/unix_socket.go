@@ -0,0 +1,64 @@
+package server
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+	"os"
+	"time"
+)
+
+// unixSocket NetworkUnix 侦听器的 socket 文件配置
+type unixSocket struct {
+	mode     os.FileMode // socket 文件权限，为 0 时不修改
+	uid, gid int         // socket 文件所有者，为 -1 时不修改
+}
+
+// getUnixSocket 获取 Unix socket 文件配置，不存在时将创建默认配置
+func (slf *Server) getUnixSocket() *unixSocket {
+	if slf.unixSocket == nil {
+		slf.unixSocket = &unixSocket{uid: -1, gid: -1}
+	}
+	return slf.unixSocket
+}
+
+// removeStaleUnixSocket 移除上次运行遗留的 socket 文件
+//   - 当路径不是 socket 文件或 socket 文件仍在被其他进程侦听时将返回错误，避免误删
+func removeStaleUnixSocket(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return ErrUnixSocketNotSocket
+	}
+	if conn, err := net.DialTimeout(string(NetworkUnix), path, time.Second); err == nil {
+		_ = conn.Close()
+		return ErrUnixSocketInUse
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// setupUnixSocket 在 socket 文件创建后根据配置设置其权限及所有者
+func (slf *Server) setupUnixSocket(path string) error {
+	if slf.unixSocket == nil {
+		return nil
+	}
+	if slf.unixSocket.mode != 0 {
+		if err := os.Chmod(path, slf.unixSocket.mode); err != nil {
+			return err
+		}
+	}
+	if slf.unixSocket.uid != -1 || slf.unixSocket.gid != -1 {
+		if err := os.Chown(path, slf.unixSocket.uid, slf.unixSocket.gid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
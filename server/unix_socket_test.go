@@ -0,0 +1,81 @@
+package server_test
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/server"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWithUnixSocketMode(t *testing.T) {
+	dir, err := os.MkdirTemp("", "minotaur")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "minotaur.sock")
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	srv := server.New(server.NetworkUnix, server.WithUnixSocketMode(0600))
+	var mode = make(chan os.FileMode, 1)
+	srv.RegStartFinishEvent(func(srv *server.Server) {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Error(err)
+		} else {
+			mode <- info.Mode().Perm()
+		}
+		srv.Shutdown()
+	})
+	var result = make(chan error, 1)
+	go func() {
+		result <- srv.Run(path)
+	}()
+	select {
+	case err = <-result:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server was not started with a stale socket file")
+	}
+	if perm := <-mode; perm != 0600 {
+		t.Fatalf("expected socket mode 0600, got %o", perm)
+	}
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected socket file to be removed after shutdown, got %v", err)
+	}
+}
+
+func TestServer_RunUnixSocketInUse(t *testing.T) {
+	dir, err := os.MkdirTemp("", "minotaur")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "minotaur.sock")
+	occupied, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer occupied.Close()
+	if err = server.New(server.NetworkUnix).Run(path); !errors.Is(err, server.ErrUnixSocketInUse) {
+		t.Fatalf("expected %v, got %v", server.ErrUnixSocketInUse, err)
+	}
+
+	file := filepath.Join(dir, "minotaur.txt")
+	if err = os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = server.New(server.NetworkUnix).Run(file); !errors.Is(err, server.ErrUnixSocketNotSocket) {
+		t.Fatalf("expected %v, got %v", server.ErrUnixSocketNotSocket, err)
+	}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"errors"
+	"github.com/gorilla/websocket"
+	"time"
+)
+
+// websocketCloseCode 根据连接关闭原因获取 Websocket 关闭帧的状态码，返回 -1 表示不发送关闭帧
+//   - 客户端主动关闭时，gorilla/websocket 已经在读取到关闭帧时进行了回复
+//   - 读写错误时底层连接通常已不可用，将直接关闭
+func websocketCloseCode(reason CloseReason, err error, running bool) int {
+	switch reason {
+	case CloseReasonClientClose, CloseReasonReadError, CloseReasonWriteError:
+		return -1
+	case CloseReasonProtocolError:
+		switch {
+		case errors.Is(err, ErrPacketTooLarge), errors.Is(err, ErrCodecPacketTooLarge), errors.Is(err, ErrCompressionPacketTooLarge), errors.Is(err, websocket.ErrReadLimit):
+			return websocket.CloseMessageTooBig
+		case errors.Is(err, ErrWebsocketIllegalMessageType):
+			return websocket.CloseUnsupportedData
+		default:
+			return websocket.CloseProtocolError
+		}
+	case CloseReasonKicked, CloseReasonRateLimited, CloseReasonMaintenance, CloseReasonUnauthorized:
+		return websocket.ClosePolicyViolation
+	case CloseReasonPanic:
+		return websocket.CloseInternalServerErr
+	case CloseReasonReadTimeout, CloseReasonHeartbeatTimeout:
+		return websocket.CloseGoingAway
+	default:
+		if !running {
+			return websocket.CloseGoingAway
+		}
+		return websocket.CloseNormalClosure
+	}
+}
+
+// closeWebsocket 以关闭握手的方式关闭 Websocket 连接
+//   - 发送与关闭原因对应的关闭帧后，将等待客户端回复关闭帧，超过 DefaultWebsocketCloseTimeout 后将直接关闭底层连接
+func (slf *Conn) closeWebsocket(reason CloseReason, err error) {
+	code := websocketCloseCode(reason, err, slf.server.state.Load() == serverStateRunning && !slf.server.shutdownPending.Load())
+	if code < 0 {
+		_ = slf.ws.Close()
+		return
+	}
+	var text = reason.String()
+	if err != nil {
+		text = err.Error()
+	}
+	if len(text) > 123 {
+		// 控制帧的负载最长为 125 字节，其中 2 字节为状态码
+		text = text[:123]
+	}
+	if e := slf.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(DefaultWebsocketCloseTimeout)); e != nil {
+		_ = slf.ws.Close()
+		return
+	}
+	ws := slf.ws
+	time.AfterFunc(DefaultWebsocketCloseTimeout, func() {
+		_ = ws.Close()
+	})
+}
+
+// startWebsocketPing 开始按照 WithWebsocketPing 设置的间隔向所有 Websocket 连接发送 Ping 控制帧，将在服务器关闭后停止
+func (slf *Server) startWebsocketPing() {
+	if slf.websocketPingInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(slf.websocketPingInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if slf.state.Load() != serverStateRunning {
+				return
+			}
+			slf.RangeConn(func(conn *Conn) bool {
+				if conn.ws != nil && !conn.IsClosed() {
+					_ = conn.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(slf.websocketPingInterval))
+				}
+				return true
+			})
+		}
+	}()
+}
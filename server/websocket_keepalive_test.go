@@ -0,0 +1,47 @@
+package server_test
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/server"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWithWebsocketPing(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket, server.WithWebsocketPing(50*time.Millisecond))
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		conn.Close()
+	})
+	var closed = make(chan server.CloseReason, 1)
+	srv.RegConnectionClosedEvent(func(srv *server.Server, conn *server.Conn, reason server.CloseReason, err any) {
+		closed <- reason
+	})
+	var addr = freeAddr(t, "tcp")
+	runServer(t, srv, addr)
+	defer srv.Shutdown()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws://"+addr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ws.Close() }()
+	var pings atomic.Int32
+	ws.SetPingHandler(func(data string) error {
+		if pings.Add(1) == 3 {
+			return ws.WriteMessage(websocket.BinaryMessage, []byte("close"))
+		}
+		return ws.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	_ = ws.SetReadDeadline(time.Now().Add(3 * time.Second))
+	_, _, err = ws.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Fatalf("expected a normal close frame, got %v", err)
+	}
+	if pings.Load() < 3 {
+		t.Fatalf("expected at least 3 pings, got %d", pings.Load())
+	}
+	if reason := <-closed; reason != server.CloseReasonServerClose {
+		t.Fatalf("expected ServerClose, got %s", reason)
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+// WebsocketRoute 在同一 Websocket 侦听器上的路由，通过 Server.WebsocketRoute 创建
+//   - 通过 WebsocketRoute 注册的事件处理函数仅对该路由的连接生效
+type WebsocketRoute struct {
+	srv     *Server
+	pattern string
+}
+
+// WebsocketRoute 在 Websocket 侦听器上声明额外的路由，例如 "/chat"，并返回用于注册该路由事件的 WebsocketRoute
+//   - 需要在服务器运行前声明，将同时作用于主侦听器及通过 AddListener 添加的 Websocket 侦听器
+//   - 当网络类型不为 NetworkWebsocket 且不存在 Websocket 附加侦听器时将发生 panic，在库代码中应使用不会发生 panic 的 GetWebsocketRoute
+//   - 连接所属的路由可通过 Conn.GetWebsocketPattern 获取
+func (slf *Server) WebsocketRoute(pattern string) *WebsocketRoute {
+	route, err := slf.GetWebsocketRoute(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return route
+}
+
+// GetWebsocketRoute 与 WebsocketRoute 一致，不同的是当网络类型不为 NetworkWebsocket 且不存在 Websocket 附加侦听器时将返回 ErrNetworkOnlySupportWebsocket
+func (slf *Server) GetWebsocketRoute(pattern string) (*WebsocketRoute, error) {
+	var support = slf.network == NetworkWebsocket
+	for _, l := range slf.listeners {
+		support = support || l.network == NetworkWebsocket
+	}
+	if !support {
+		return nil, ErrNetworkOnlySupportWebsocket
+	}
+	var exist bool
+	for _, p := range slf.websocketPatterns {
+		if exist = p == pattern; exist {
+			break
+		}
+	}
+	if !exist {
+		slf.websocketPatterns = append(slf.websocketPatterns, pattern)
+	}
+	return &WebsocketRoute{srv: slf, pattern: pattern}, nil
+}
+
+// GetPattern 获取路由
+func (slf *WebsocketRoute) GetPattern() string {
+	return slf.pattern
+}
+
+// RegConnectionOpenedEvent 在该路由的连接打开后将立刻执行被注册的事件处理函数
+func (slf *WebsocketRoute) RegConnectionOpenedEvent(handler ConnectionOpenedEventHandler, priority ...int) {
+	slf.srv.RegConnectionOpenedEvent(func(srv *Server, conn *Conn) {
+		if conn.wsPattern == slf.pattern {
+			handler(srv, conn)
+		}
+	}, priority...)
+}
+
+// RegConnectionClosedEvent 在该路由的连接关闭后将立刻执行被注册的事件处理函数
+func (slf *WebsocketRoute) RegConnectionClosedEvent(handler ConnectionClosedEventHandler, priority ...int) {
+	slf.srv.RegConnectionClosedEvent(func(srv *Server, conn *Conn, reason CloseReason, err any) {
+		if conn.wsPattern == slf.pattern {
+			handler(srv, conn, reason, err)
+		}
+	}, priority...)
+}
+
+// RegConnectionReceivePacketEvent 在接收到该路由的连接的数据包时将立刻执行被注册的事件处理函数
+func (slf *WebsocketRoute) RegConnectionReceivePacketEvent(handler ConnectionReceivePacketEventHandler, priority ...int) {
+	slf.srv.RegConnectionReceivePacketEvent(func(srv *Server, conn *Conn, packet []byte) {
+		if conn.wsPattern == slf.pattern {
+			handler(srv, conn, packet)
+		}
+	}, priority...)
+}
@@ -0,0 +1,93 @@
+package server_test
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/server"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestServer_WebsocketRoute(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket)
+	var ws, chat = make(chan string, 1), make(chan string, 1)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		if conn.GetWebsocketPattern() == "/ws" {
+			ws <- string(packet)
+		}
+	})
+	srv.WebsocketRoute("/chat").RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		chat <- string(packet)
+	})
+	var addr = freeAddr(t, "tcp")
+	runServer(t, srv, addr+"/ws")
+	defer srv.Shutdown()
+
+	var dial = func(path string) *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial("ws://"+addr+path, nil)
+		if err != nil {
+			t.Fatalf("dial %s failed: %v", path, err)
+		}
+		return conn
+	}
+	for path, expected := range map[string]chan string{"/ws": ws, "/chat": chat} {
+		conn := dial(path)
+		if err := conn.WriteMessage(websocket.BinaryMessage, []byte(path)); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case packet := <-expected:
+			if packet != path {
+				t.Fatalf("expected %s, got %s", path, packet)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("packet from %s was not received by its route", path)
+		}
+		_ = conn.Close()
+	}
+	if len(ws)+len(chat) != 0 {
+		t.Fatal("packet was received by another route")
+	}
+}
+
+func TestServer_WebsocketWithHttpRouter(t *testing.T) {
+	srv := server.New(server.NetworkWebsocket)
+	var received = make(chan string, 1)
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		received <- string(packet)
+	})
+	srv.HttpServer().GET("/ping", func(ctx *server.HttpContext) {
+		ctx.Gin().String(http.StatusOK, "pong")
+	})
+	var addr = freeAddr(t, "tcp")
+	runServer(t, srv, addr)
+	defer srv.Shutdown()
+
+	response, err := http.Get("http://" + addr + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(response.Body)
+	_ = response.Body.Close()
+	if string(body) != "pong" {
+		t.Fatalf("expected pong, got %s", body)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+addr+"/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err = conn.WriteMessage(websocket.BinaryMessage, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case packet := <-received:
+		if packet != "hello" {
+			t.Fatalf("expected hello, got %s", packet)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("websocket packet was not received")
+	}
+}
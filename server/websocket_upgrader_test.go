@@ -0,0 +1,51 @@
+package server_test
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/kercylan98/minotaur/server"
+	"net/http"
+	"testing"
+)
+
+func TestWithWebsocketCheckOrigin(t *testing.T) {
+	upgrader := &websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 1024}
+	srv := server.New(server.NetworkWebsocket,
+		server.WithWebsocketCheckOrigin(func(r *http.Request) bool {
+			return r.Header.Get("Origin") == "https://a.com"
+		}),
+		server.WithWebsocketUpgrader(upgrader),
+	)
+	var addr = freeAddr(t, "tcp")
+	runServer(t, srv, addr)
+	defer srv.Shutdown()
+
+	if upgrader.CheckOrigin != nil {
+		t.Fatal("the caller's upgrader should not be modified")
+	}
+
+	var cases = []struct {
+		origin  string
+		allowed bool
+	}{
+		{"https://a.com", true},
+		{"https://b.com", false},
+	}
+	for _, c := range cases {
+		header := http.Header{"Origin": []string{c.origin}}
+		conn, resp, err := websocket.DefaultDialer.Dial("ws://"+addr, header)
+		if c.allowed {
+			if err != nil {
+				t.Fatalf("origin %s: expected upgrade to succeed, got %v", c.origin, err)
+			}
+			_ = conn.Close()
+			continue
+		}
+		if err == nil {
+			_ = conn.Close()
+			t.Fatalf("origin %s: expected upgrade to be rejected", c.origin)
+		}
+		if resp == nil || resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("origin %s: expected status %d, got %v", c.origin, http.StatusForbidden, resp)
+		}
+	}
+}
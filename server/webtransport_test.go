@@ -0,0 +1,93 @@
+package server_test
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"github.com/kercylan98/minotaur/server"
+	"github.com/quic-go/quic-go/http3"
+	"github.com/quic-go/webtransport-go"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebTransport_ConcurrentStreams(t *testing.T) {
+	_, ca, caKey := newTestCertificate(t, 1, nil, nil, 0)
+	serverCert, _, _ := newTestCertificate(t, 2, ca, caKey, x509.ExtKeyUsageServerAuth)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	const streams, packets = 2, 50
+	codec := server.NewLengthFieldCodec(2, 0)
+	srv := server.New(server.NetworkWebTransport,
+		server.WithTLSConfig(&tls.Config{Certificates: []tls.Certificate{serverCert}}),
+		server.WithCodec(codec),
+	)
+	var lock sync.Mutex
+	var received = make(map[string]int)
+	var done = make(chan struct{})
+	srv.RegConnectionReceivePacketEvent(func(srv *server.Server, conn *server.Conn, packet []byte) {
+		lock.Lock()
+		defer lock.Unlock()
+		received[string(packet)]++
+		if len(received) == streams*packets {
+			close(done)
+		}
+	})
+	var addr = freeAddr(t, "udp")
+	runServer(t, srv, addr+"/wt")
+	defer srv.Shutdown()
+
+	dialer := &webtransport.Dialer{RoundTripper: &http3.RoundTripper{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	defer dialer.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, session, err := dialer.Dial(ctx, "https://"+addr+"/wt", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.CloseWithError(0, "")
+
+	var wait sync.WaitGroup
+	for i := 0; i < streams; i++ {
+		stream, err := session.OpenStreamSync(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wait.Add(1)
+		go func(id int, stream webtransport.Stream) {
+			defer wait.Done()
+			for p := 0; p < packets; p++ {
+				encoded, _ := codec.Encode([]byte(fmt.Sprintf("stream-%d-packet-%d", id, p)))
+				// 将帧拆分为两次写入，使两个流的半帧在服务器端交错到达
+				for _, part := range [][]byte{encoded[:len(encoded)/2], encoded[len(encoded)/2:]} {
+					if _, err := stream.Write(part); err != nil {
+						t.Error(err)
+						return
+					}
+					time.Sleep(time.Millisecond)
+				}
+			}
+		}(i, stream)
+	}
+	wait.Wait()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		lock.Lock()
+		defer lock.Unlock()
+		t.Fatalf("expected %d packets, got %d", streams*packets, len(received))
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	for i := 0; i < streams; i++ {
+		for p := 0; p < packets; p++ {
+			if count := received[fmt.Sprintf("stream-%d-packet-%d", i, p)]; count != 1 {
+				t.Fatalf("stream %d packet %d received %d times", i, p, count)
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// WorkerPoolStats 消息分发工作池的运行状态
+type WorkerPoolStats struct {
+	Workers     int           // 当前工作协程数量
+	IdleWorkers int           // 当前空闲的工作协程数量
+	MinWorkers  int           // 最小工作协程数量
+	MaxWorkers  int           // 最大工作协程数量
+	Queued      int           // 等待处理的消息数量
+	Keys        int           // 存在待处理消息的分片键数量
+	Latency     time.Duration // 最近一个检测周期内消息的平均排队时长
+}
+
+// newWorkerPool 创建一个按分片键保证顺序的动态工作池
+//   - 相同分片键的消息将按顺序串行处理，不同分片键的消息将由多个工作协程并行处理
+//   - 当消息排队时长超过 latency 时将增加工作协程，直到 max；当工作协程持续空闲时将减少，直到 min
+func newWorkerPool(min, max int, latency time.Duration, key func(message *Message) string, handler func(message *Message)) *workerPool {
+	pool := &workerPool{
+		min:     min,
+		max:     max,
+		latency: latency,
+		key:     key,
+		handler: handler,
+		queues:  map[string]*workerQueue{},
+	}
+	pool.cond = sync.NewCond(&pool.mu)
+	return pool
+}
+
+type workerTask struct {
+	message *Message
+	enqueue time.Time
+}
+
+type workerQueue struct {
+	tasks     []workerTask
+	running   bool
+	scheduled bool
+}
+
+type workerPool struct {
+	min, max     int
+	latency      time.Duration
+	key          func(message *Message) string
+	handler      func(message *Message)
+	mu           sync.Mutex
+	cond         *sync.Cond
+	queues       map[string]*workerQueue
+	ready        []string
+	queued       int
+	workers      int
+	idle         int
+	shrink       int
+	closed       bool
+	wait         sync.WaitGroup
+	latencySum   time.Duration
+	latencyCount int
+	lastLatency  time.Duration
+}
+
+// start 启动工作池，wait 将在工作池关闭且所有工作协程退出后被标记为完成
+func (slf *workerPool) start(wait *sync.WaitGroup) {
+	slf.mu.Lock()
+	for slf.workers < slf.min {
+		slf.spawn()
+	}
+	slf.mu.Unlock()
+	go func() {
+		defer wait.Done()
+		ticker := time.NewTicker(slf.latency)
+		defer ticker.Stop()
+		for range ticker.C {
+			if !slf.scale() {
+				break
+			}
+		}
+		slf.wait.Wait()
+	}()
+}
+
+// scale 根据最近一个周期的排队时长调整工作协程数量，当工作池已关闭时返回 false
+func (slf *workerPool) scale() bool {
+	slf.mu.Lock()
+	defer slf.mu.Unlock()
+	if slf.closed {
+		return false
+	}
+	slf.lastLatency = 0
+	if slf.latencyCount > 0 {
+		slf.lastLatency = slf.latencySum / time.Duration(slf.latencyCount)
+	}
+	slf.latencySum, slf.latencyCount = 0, 0
+	var oldest time.Duration
+	if len(slf.ready) > 0 {
+		oldest = time.Since(slf.queues[slf.ready[0]].tasks[0].enqueue)
+	}
+	switch {
+	case (slf.lastLatency > slf.latency || oldest > slf.latency) && slf.workers < slf.max:
+		slf.spawn()
+	case slf.queued == 0 && slf.idle > 0 && slf.workers-slf.shrink > slf.min:
+		slf.shrink++
+		slf.cond.Signal()
+	}
+	return true
+}
+
+// spawn 创建一个工作协程，调用方需持有锁
+func (slf *workerPool) spawn() {
+	slf.workers++
+	slf.wait.Add(1)
+	go slf.work()
+}
+
+func (slf *workerPool) work() {
+	defer slf.wait.Done()
+	slf.mu.Lock()
+	for {
+		for len(slf.ready) == 0 && !slf.closed && slf.shrink == 0 {
+			slf.idle++
+			slf.cond.Wait()
+			slf.idle--
+		}
+		if len(slf.ready) == 0 {
+			if slf.shrink > 0 {
+				slf.shrink--
+			}
+			slf.workers--
+			slf.mu.Unlock()
+			return
+		}
+		key := slf.ready[0]
+		slf.ready = slf.ready[1:]
+		queue := slf.queues[key]
+		task := queue.tasks[0]
+		queue.tasks = queue.tasks[1:]
+		queue.scheduled, queue.running = false, true
+		slf.queued--
+		slf.latencySum += time.Since(task.enqueue)
+		slf.latencyCount++
+		slf.mu.Unlock()
+
+		slf.handler(task.message)
+
+		slf.mu.Lock()
+		queue.running = false
+		if len(queue.tasks) > 0 {
+			queue.scheduled = true
+			slf.ready = append(slf.ready, key)
+		} else {
+			delete(slf.queues, key)
+		}
+	}
+}
+
+// put 向工作池中写入消息，当工作池已关闭时返回 false
+func (slf *workerPool) put(message *Message) bool {
+	key := slf.key(message)
+	slf.mu.Lock()
+	defer slf.mu.Unlock()
+	if slf.closed {
+		return false
+	}
+	queue, exist := slf.queues[key]
+	if !exist {
+		queue = &workerQueue{}
+		slf.queues[key] = queue
+	}
+	queue.tasks = append(queue.tasks, workerTask{message: message, enqueue: time.Now()})
+	slf.queued++
+	if !queue.running && !queue.scheduled {
+		queue.scheduled = true
+		slf.ready = append(slf.ready, key)
+		if slf.idle > 0 {
+			slf.cond.Signal()
+		} else if slf.workers < slf.max && slf.workers-slf.shrink <= 0 {
+			slf.spawn()
+		}
+	}
+	return true
+}
+
+// close 关闭工作池，已写入的消息仍将被处理
+func (slf *workerPool) close() {
+	slf.mu.Lock()
+	defer slf.mu.Unlock()
+	slf.closed = true
+	slf.cond.Broadcast()
+}
+
+// stats 获取工作池运行状态
+func (slf *workerPool) stats() WorkerPoolStats {
+	slf.mu.Lock()
+	defer slf.mu.Unlock()
+	return WorkerPoolStats{
+		Workers:     slf.workers,
+		IdleWorkers: slf.idle,
+		MinWorkers:  slf.min,
+		MaxWorkers:  slf.max,
+		Queued:      slf.queued,
+		Keys:        len(slf.queues),
+		Latency:     slf.lastLatency,
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool(t *testing.T) {
+	const keys, perKey = 16, 50
+	var (
+		messageKeys = map[*Message]string{}
+		messageSeq  = map[*Message]int{}
+		result      = map[string][]int{}
+		mu          sync.Mutex
+		done        sync.WaitGroup
+		exit        sync.WaitGroup
+	)
+	pool := newWorkerPool(1, 8, time.Millisecond, func(message *Message) string {
+		return messageKeys[message]
+	}, func(message *Message) {
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		key := messageKeys[message]
+		result[key] = append(result[key], messageSeq[message])
+		mu.Unlock()
+		done.Done()
+	})
+	exit.Add(1)
+	pool.start(&exit)
+
+	var messages []*Message
+	for i := 0; i < perKey; i++ {
+		for k := 0; k < keys; k++ {
+			message := new(Message)
+			messageKeys[message] = strconv.Itoa(k)
+			messageSeq[message] = i
+			messages = append(messages, message)
+		}
+	}
+	done.Add(len(messages))
+	for _, message := range messages {
+		pool.put(message)
+	}
+	done.Wait()
+
+	if stats := pool.stats(); stats.Workers <= 1 {
+		t.Fatalf("expected the pool to scale up, got %d workers", stats.Workers)
+	}
+	for key, seq := range result {
+		for i, v := range seq {
+			if i != v {
+				t.Fatalf("messages of key %s are out of order: %v", key, seq)
+			}
+		}
+	}
+
+	pool.close()
+	exit.Wait()
+	if stats := pool.stats(); stats.Workers != 0 || stats.Queued != 0 {
+		t.Fatalf("unexpected stats after close: %+v", stats)
+	}
+}
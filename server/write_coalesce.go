@@ -0,0 +1,113 @@
+package server
+
+import (
+	"sync"
+	"time"
+)
+
+// writeCoalesce 出站数据包合并写入配置
+type writeCoalesce struct {
+	interval time.Duration // 合并的最长等待时间
+	size     int           // 合并的字节数阈值
+}
+
+// newWriteCoalescer 创建出站数据包合并写入器，write 为实际写入连接的函数，onError 为定时写入失败时的处理函数
+func newWriteCoalescer(config *writeCoalesce, write func(packet []byte) error, onError func(err error)) *writeCoalescer {
+	return &writeCoalescer{
+		writeCoalesce: config,
+		write:         write,
+		onError:       onError,
+	}
+}
+
+// writeCoalescer 出站数据包合并写入器，将多个小数据包合并后通过一次写入发送
+type writeCoalescer struct {
+	*writeCoalesce
+	write     func(packet []byte) error
+	onError   func(err error)
+	lock      sync.Mutex
+	buf       []byte
+	callbacks []func(err error)
+	timer     *time.Timer
+	closed    bool
+}
+
+// add 将数据包放入合并缓冲区，当缓冲区达到字节数阈值时将立即写入，否则将在等待时间到达后写入
+//   - 数据包的回调函数将在实际写入后、释放锁之后被调用，因此允许在回调函数中继续写入数据包
+//   - 合并写入器关闭后放入的数据包将被丢弃，其回调函数将收到 ErrConnectionClosed
+func (slf *writeCoalescer) add(packet []byte, callback func(err error)) error {
+	slf.lock.Lock()
+	if slf.closed {
+		slf.lock.Unlock()
+		if callback != nil {
+			callback(ErrConnectionClosed)
+		}
+		return nil
+	}
+	if slf.buf == nil {
+		slf.buf = make([]byte, 0, slf.size)
+	}
+	slf.buf = append(slf.buf, packet...)
+	if callback != nil {
+		slf.callbacks = append(slf.callbacks, callback)
+	}
+	if len(slf.buf) >= slf.size {
+		callbacks, err := slf.flushWithoutLock()
+		slf.lock.Unlock()
+		notifyCoalesced(callbacks, err)
+		return err
+	}
+	if slf.timer == nil {
+		slf.timer = time.AfterFunc(slf.interval, func() {
+			if err := slf.flush(); err != nil {
+				go slf.onError(err)
+			}
+		})
+	}
+	slf.lock.Unlock()
+	return nil
+}
+
+// flush 立即写入合并缓冲区中的数据
+func (slf *writeCoalescer) flush() error {
+	slf.lock.Lock()
+	callbacks, err := slf.flushWithoutLock()
+	slf.lock.Unlock()
+	notifyCoalesced(callbacks, err)
+	return err
+}
+
+// flushWithoutLock 写入合并缓冲区中的数据，返回需要在释放锁后调用的回调函数（无锁）
+//   - 写入仍在锁内进行，以保证合并后的数据包按放入顺序写入
+func (slf *writeCoalescer) flushWithoutLock() ([]func(err error), error) {
+	if slf.timer != nil {
+		slf.timer.Stop()
+		slf.timer = nil
+	}
+	if slf.closed || len(slf.buf) == 0 {
+		return nil, nil
+	}
+	// 底层连接可能异步持有写入的数据，因此每次写入后都使用新的缓冲区
+	packet, callbacks := slf.buf, slf.callbacks
+	slf.buf, slf.callbacks = nil, nil
+	return callbacks, slf.write(packet)
+}
+
+// close 写入合并缓冲区中尚未写入的数据后关闭合并写入器，应当在关闭底层连接前调用
+//   - 返回的 notify 函数用于调用已写入数据包的回调函数，调用方应当在释放自身持有的锁后调用
+func (slf *writeCoalescer) close() (notify func()) {
+	slf.lock.Lock()
+	callbacks, err := slf.flushWithoutLock()
+	slf.closed = true
+	slf.lock.Unlock()
+	return func() {
+		notifyCoalesced(callbacks, err)
+	}
+}
+
+// notifyCoalesced 以写入结果调用合并写入的数据包的回调函数
+func notifyCoalesced(callbacks []func(err error), err error) {
+	for _, callback := range callbacks {
+		callback(err)
+	}
+}
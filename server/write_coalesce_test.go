@@ -0,0 +1,110 @@
+package server
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWriteCoalescer(t *testing.T) {
+	var lock sync.Mutex
+	var writes [][]byte
+	var written = make(chan struct{}, 8)
+	coalescer := newWriteCoalescer(&writeCoalesce{interval: 20 * time.Millisecond, size: 8}, func(packet []byte) error {
+		lock.Lock()
+		writes = append(writes, packet)
+		lock.Unlock()
+		written <- struct{}{}
+		return nil
+	}, func(err error) {
+		t.Error(err)
+	})
+
+	var callbacks atomic.Int32
+	for _, packet := range []string{"ab", "cd"} {
+		if err := coalescer.add([]byte(packet), func(err error) { callbacks.Add(1) }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		t.Fatal("coalesced packets were not written after the interval")
+	}
+	if err := coalescer.add([]byte("12345678"), nil); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-written:
+	default:
+		t.Fatal("expected an immediate write when the size threshold is reached")
+	}
+	lock.Lock()
+	if len(writes) != 2 || string(writes[0]) != "abcd" || string(writes[1]) != "12345678" {
+		t.Fatalf("unexpected writes %q", writes)
+	}
+	lock.Unlock()
+	// 回调函数在写入完成并释放锁后才会被调用，定时写入的回调函数需要等待
+	for deadline := time.Now().Add(time.Second); callbacks.Load() != 2 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if count := callbacks.Load(); count != 2 {
+		t.Fatalf("expected 2 callbacks, got %d", count)
+	}
+
+	// 关闭时尚未写入的数据应被写入，随后放入的数据包将被丢弃
+	var closedErr = make(chan error, 2)
+	_ = coalescer.add([]byte("ef"), func(err error) { closedErr <- err })
+	coalescer.close()()
+	select {
+	case <-written:
+	default:
+		t.Fatal("pending data should be flushed on close")
+	}
+	_ = coalescer.add([]byte("gh"), func(err error) { closedErr <- err })
+	if err := <-closedErr; err != nil {
+		t.Fatalf("expected the flushed packet callback to succeed, got %v", err)
+	}
+	if err := <-closedErr; err != ErrConnectionClosed {
+		t.Fatalf("expected %v, got %v", ErrConnectionClosed, err)
+	}
+	select {
+	case <-written:
+		t.Fatal("packets added after close should be discarded")
+	case <-time.After(50 * time.Millisecond):
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if len(writes) != 3 || string(writes[2]) != "ef" {
+		t.Fatalf("unexpected writes %q", writes)
+	}
+}
+
+func TestWriteCoalescer_CallbackWrite(t *testing.T) {
+	var written = make(chan string, 8)
+	var coalescer *writeCoalescer
+	coalescer = newWriteCoalescer(&writeCoalesce{interval: 10 * time.Millisecond, size: 4}, func(packet []byte) error {
+		written <- string(packet)
+		return nil
+	}, func(err error) {
+		t.Error(err)
+	})
+
+	// 回调函数中继续写入数据包时不应死锁，无论是达到字节数阈值时的写入还是定时写入
+	_ = coalescer.add([]byte("abcd"), func(err error) {
+		_ = coalescer.add([]byte("ef"), func(err error) {
+			_ = coalescer.add([]byte("gh"), nil)
+		})
+	})
+	for _, expected := range []string{"abcd", "ef", "gh"} {
+		select {
+		case packet := <-written:
+			if packet != expected {
+				t.Fatalf("expected %q, got %q", expected, packet)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("packet %q was not written, the callback may be deadlocked", expected)
+		}
+	}
+}
@@ -80,6 +80,13 @@ func (slf *Unbounded[V]) Close() {
 	close(slf.c)
 }
 
+// Len 获取缓冲区中尚未被读取的数据数量
+func (slf *Unbounded[V]) Len() int {
+	slf.mu.Lock()
+	defer slf.mu.Unlock()
+	return len(slf.c) + len(slf.backlog)
+}
+
 // IsClosed 是否已关闭
 func (slf *Unbounded[V]) IsClosed() bool {
 	slf.mu.Lock()
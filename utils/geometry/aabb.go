@@ -0,0 +1,55 @@
+package geometry
+
+import "github.com/kercylan98/minotaur/utils/generic"
+
+// NewAABB 创建一个轴对齐包围盒，x、y 为包围盒左上角（最小值）的坐标
+func NewAABB[V generic.SignedNumber](x, y, width, height V) AABB[V] {
+	return AABB[V]{X: x, Y: y, Width: width, Height: height}
+}
+
+// AABB 轴对齐包围盒，常用于碰撞检测的粗略阶段及空间索引
+type AABB[V generic.SignedNumber] struct {
+	X, Y          V // 左上角（最小值）的坐标
+	Width, Height V // 宽高
+}
+
+// MaxX 获取包围盒在 x 轴上的最大值
+func (slf AABB[V]) MaxX() V {
+	return slf.X + slf.Width
+}
+
+// MaxY 获取包围盒在 y 轴上的最大值
+func (slf AABB[V]) MaxY() V {
+	return slf.Y + slf.Height
+}
+
+// Intersects 检查是否与另一个包围盒相交，边缘相接也将视为相交
+func (slf AABB[V]) Intersects(other AABB[V]) bool {
+	return slf.X <= other.MaxX() && other.X <= slf.MaxX() && slf.Y <= other.MaxY() && other.Y <= slf.MaxY()
+}
+
+// Contains 检查是否完全包含另一个包围盒
+func (slf AABB[V]) Contains(other AABB[V]) bool {
+	return other.X >= slf.X && other.Y >= slf.Y && other.MaxX() <= slf.MaxX() && other.MaxY() <= slf.MaxY()
+}
+
+// ContainsPoint 检查是否包含特定的点，边缘上的点也将视为包含
+func (slf AABB[V]) ContainsPoint(x, y V) bool {
+	return x >= slf.X && x <= slf.MaxX() && y >= slf.Y && y <= slf.MaxY()
+}
+
+// IntersectsCircle 检查是否与以 x、y 为圆心，radius 为半径的圆相交
+func (slf AABB[V]) IntersectsCircle(x, y, radius V) bool {
+	nx, ny := x, y
+	if nx < slf.X {
+		nx = slf.X
+	} else if nx > slf.MaxX() {
+		nx = slf.MaxX()
+	}
+	if ny < slf.Y {
+		ny = slf.Y
+	} else if ny > slf.MaxY() {
+		ny = slf.MaxY()
+	}
+	return CalcDistanceSquared(x, y, nx, ny) <= radius*radius
+}
@@ -3,5 +3,7 @@
 //   - 图形表示：astar 包支持使用图形或网络来表示路径搜索的环境。您可以定义节点和边，以构建图形，并在其中执行路径搜索。
 //   - A* 算法：该包提供了 A* 算法的实现，用于在图形中找到最短路径。A* 算法使用启发式函数来评估节点的优先级，并选择最有希望的节点进行扩展，以达到最短路径的目标。
 //   - 自定义启发式函数：您可以根据具体问题定义自己的启发式函数，以指导 A* 算法的搜索过程。启发式函数用于估计从当前节点到目标节点的代价，以帮助算法选择最佳路径。
+//   - 网格寻路：Grid 提供了二维网格上的 A* 寻路，支持四方向及八方向移动、斜向穿角规则、格子移动代价、可替换的启发函数以及路径平滑。
+//   - 跳点搜索及流场：Grid 支持使用跳点搜索加速寻路，亦可通过 NewFlowField 为目的地生成流场，供大量单位共享使用。
 //   - 可定制性：astar 包提供了一些可定制的选项，以满足不同场景下的需求。您可以设置节点的代价、边的权重等参数，以调整算法的行为。
 package astar
@@ -0,0 +1,111 @@
+package astar
+
+import (
+	"container/heap"
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"math"
+)
+
+// NewFlowField 基于网格生成以 targets 为目的地的流场，当存在多个目的地时，每个格子将指向代价最小的目的地
+//   - 流场记录了每个格子到达目的地的最小代价及下一步应移动到的格子，适用于大量单位前往相同目的地的场景，所有单位共享同一个流场而无需各自进行寻路
+//   - 流场生成时将遵循网格的斜向移动规则及移动代价，网格的可通行性发生变化后需要重新生成流场
+func NewFlowField(grid *Grid, targets ...geometry.Point[int]) *FlowField {
+	field := &FlowField{
+		grid:  grid,
+		costs: make([]float64, grid.width*grid.height),
+		next:  make([]int, grid.width*grid.height),
+	}
+	for i := range field.costs {
+		field.costs[i] = math.Inf(1)
+		field.next[i] = -1
+	}
+
+	open := &h[int, float64]{}
+	for _, target := range targets {
+		if !grid.IsWalkable(target.GetXY()) {
+			continue
+		}
+		index := field.index(target.GetXY())
+		field.costs[index] = 0
+		heap.Push(open, &hm[int, float64]{v: index, p: 0})
+	}
+
+	for open.Len() > 0 {
+		item := heap.Pop(open).(*hm[int, float64])
+		current, cost := item.v, -item.p
+		if cost > field.costs[current] {
+			continue
+		}
+		cx, cy := current%grid.width, current/grid.width
+		grid.rangeNeighbours(cx, cy, func(x, y int, diagonal bool) {
+			index := field.index(x, y)
+			if c := cost + grid.stepCost(cx, cy, diagonal); c < field.costs[index] {
+				field.costs[index] = c
+				field.next[index] = current
+				heap.Push(open, &hm[int, float64]{v: index, p: -c})
+			}
+		})
+	}
+	return field
+}
+
+// FlowField 基于网格生成的流场
+type FlowField struct {
+	grid  *Grid
+	costs []float64 // 每个格子到达目的地的最小代价
+	next  []int     // 每个格子下一步应移动到的格子索引
+}
+
+// Cost 获取从格子到达目的地的最小代价，当格子无法到达目的地时返回 false
+func (slf *FlowField) Cost(point geometry.Point[int]) (float64, bool) {
+	x, y := point.GetXY()
+	if !slf.inBounds(x, y) {
+		return 0, false
+	}
+	cost := slf.costs[slf.index(x, y)]
+	return cost, !math.IsInf(cost, 1)
+}
+
+// Next 获取从格子出发下一步应移动到的格子，当格子为目的地或无法到达目的地时返回 false
+func (slf *FlowField) Next(point geometry.Point[int]) (geometry.Point[int], bool) {
+	x, y := point.GetXY()
+	if !slf.inBounds(x, y) {
+		return geometry.Point[int]{}, false
+	}
+	next := slf.next[slf.index(x, y)]
+	if next < 0 {
+		return geometry.Point[int]{}, false
+	}
+	return geometry.NewPoint(next%slf.grid.width, next/slf.grid.width), true
+}
+
+// Direction 获取从格子出发下一步应移动的方向，当格子为目的地或无法到达目的地时返回 0, 0
+func (slf *FlowField) Direction(point geometry.Point[int]) (dx, dy int) {
+	next, ok := slf.Next(point)
+	if !ok {
+		return 0, 0
+	}
+	return next.GetX() - point.GetX(), next.GetY() - point.GetY()
+}
+
+// Path 获取从格子出发沿流场到达目的地的路径，返回的路径包含起点和目的地，当无法到达目的地时返回 nil
+func (slf *FlowField) Path(start geometry.Point[int]) []geometry.Point[int] {
+	if _, ok := slf.Cost(start); !ok {
+		return nil
+	}
+	result := []geometry.Point[int]{start}
+	for current, ok := slf.Next(start); ok; current, ok = slf.Next(current) {
+		result = append(result, current)
+	}
+	return result
+}
+
+// inBounds 检查格子是否在网格内
+func (slf *FlowField) inBounds(x, y int) bool {
+	return x >= 0 && y >= 0 && x < slf.grid.width && y < slf.grid.height
+}
+
+// index 获取格子在流场中的索引
+func (slf *FlowField) index(x, y int) int {
+	return y*slf.grid.width + x
+}
@@ -0,0 +1,60 @@
+package astar_test
+
+import (
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"github.com/kercylan98/minotaur/utils/geometry/astar"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestNewFlowField(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	grid := newRandomGrid(r, 40, 40, 0.25, astar.WithDiagonal(astar.DiagonalOnlyWhenNoObstacles))
+	target := geometry.NewPoint(39, 39)
+	field := astar.NewFlowField(grid, target)
+
+	for y := 0; y < 40; y++ {
+		for x := 0; x < 40; x++ {
+			start := geometry.NewPoint(x, y)
+			expected := grid.Find(start, target)
+			cost, ok := field.Cost(start)
+			if ok != (expected != nil) {
+				t.Fatalf("%v: reachability mismatch", start)
+			}
+			if !ok {
+				continue
+			}
+			path := field.Path(start)
+			if path[len(path)-1] != target {
+				t.Fatalf("%v: path does not reach target %v", start, path)
+			}
+			if e, a := calcPathCost(t, grid, expected), calcPathCost(t, grid, path); math.Abs(e-a) > 1e-9 || math.Abs(e-cost) > 1e-9 {
+				t.Fatalf("%v: cost mismatch, find %f, field path %f, field cost %f", start, e, a, cost)
+			}
+		}
+	}
+
+	if dx, dy := field.Direction(target); dx != 0 || dy != 0 {
+		t.Fatalf("target should have no direction")
+	}
+}
+
+func TestNewFlowField_MultipleTargets(t *testing.T) {
+	grid := astar.NewGrid(10, 1, func(x, y int) bool { return true })
+	field := astar.NewFlowField(grid, geometry.NewPoint(0, 0), geometry.NewPoint(9, 0))
+	if dx, _ := field.Direction(geometry.NewPoint(2, 0)); dx != -1 {
+		t.Fatalf("unexpected direction %d", dx)
+	}
+	if dx, _ := field.Direction(geometry.NewPoint(7, 0)); dx != 1 {
+		t.Fatalf("unexpected direction %d", dx)
+	}
+}
+
+func BenchmarkNewFlowField(b *testing.B) {
+	grid := newRandomGrid(rand.New(rand.NewSource(1)), 200, 200, 0.1, astar.WithDiagonal(astar.DiagonalOnlyWhenNoObstacles))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		astar.NewFlowField(grid, geometry.NewPoint(199, 199))
+	}
+}
@@ -0,0 +1,225 @@
+package astar
+
+import (
+	"container/heap"
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"math"
+)
+
+// NewGrid 创建一个宽为 width、高为 height 的网格，walkable 用于判断格子是否可通行
+//   - 网格实现了 Graph 接口，亦可配合 Find 函数使用
+func NewGrid(width, height int, walkable func(x, y int) bool, options ...GridOption) *Grid {
+	grid := &Grid{
+		width:    width,
+		height:   height,
+		walkable: walkable,
+	}
+	for _, option := range options {
+		option(grid)
+	}
+	if grid.heuristic == nil {
+		if grid.diagonal == DiagonalNever {
+			grid.heuristic = HeuristicManhattan
+		} else {
+			grid.heuristic = HeuristicOctile
+		}
+	}
+	return grid
+}
+
+// NewGridWithFloorPlan 基于平面图创建网格，平面图中的空格将被视为可通行的格子
+func NewGridWithFloorPlan(floorPlan geometry.FloorPlan, options ...GridOption) *Grid {
+	var width int
+	for _, row := range floorPlan {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	return NewGrid(width, len(floorPlan), func(x, y int) bool {
+		return x < len(floorPlan[y]) && floorPlan[y][x] == ' '
+	}, options...)
+}
+
+// Grid 适用于 A* 算法的二维网格
+type Grid struct {
+	width, height int
+	walkable      func(x, y int) bool
+	diagonal      DiagonalMovement
+	cost          func(x, y int) float64
+	heuristic     Heuristic
+}
+
+// IsWalkable 检查格子是否在网格内且可通行
+func (slf *Grid) IsWalkable(x, y int) bool {
+	return x >= 0 && y >= 0 && x < slf.width && y < slf.height && slf.walkable(x, y)
+}
+
+// Neighbours 返回与给定格子相邻且可到达的格子列表
+func (slf *Grid) Neighbours(node geometry.Point[int]) []geometry.Point[int] {
+	var neighbours []geometry.Point[int]
+	slf.rangeNeighbours(node.GetX(), node.GetY(), func(x, y int, diagonal bool) {
+		neighbours = append(neighbours, geometry.NewPoint(x, y))
+	})
+	return neighbours
+}
+
+// Find 查找从 start 到 end 的代价最小的路径，返回的路径包含起点和终点，当无法到达时返回 nil
+func (slf *Grid) Find(start, end geometry.Point[int]) []geometry.Point[int] {
+	if !slf.IsWalkable(start.GetXY()) || !slf.IsWalkable(end.GetXY()) {
+		return nil
+	}
+
+	type record struct {
+		parent geometry.Point[int]
+		g      float64
+		closed bool
+	}
+
+	records := map[geometry.Point[int]]*record{start: {parent: start}}
+	open := &h[geometry.Point[int], float64]{}
+	heap.Push(open, &hm[geometry.Point[int], float64]{v: start, p: -slf.estimate(start, end)})
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(*hm[geometry.Point[int], float64]).v
+		r := records[current]
+		if r.closed {
+			continue
+		}
+		if current == end {
+			var result []geometry.Point[int]
+			for node := end; ; node = records[node].parent {
+				result = append(result, node)
+				if node == start {
+					break
+				}
+			}
+			for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+				result[i], result[j] = result[j], result[i]
+			}
+			return result
+		}
+		r.closed = true
+
+		slf.rangeNeighbours(current.GetX(), current.GetY(), func(x, y int, diagonal bool) {
+			g := r.g + slf.stepCost(x, y, diagonal)
+			next := geometry.NewPoint(x, y)
+			if nr, exist := records[next]; exist && (nr.closed || g >= nr.g) {
+				return
+			}
+			records[next] = &record{parent: current, g: g}
+			heap.Push(open, &hm[geometry.Point[int], float64]{v: next, p: -(g + slf.estimate(next, end))})
+		})
+	}
+	return nil
+}
+
+// Smooth 对路径进行平滑处理，移除路径中能够被直线跨越的中间节点，返回的路径仅包含拐点
+//   - 直线经过的所有格子均需可通行，当直线恰好穿过格子的拐角时，将按照网格的斜向移动规则判断是否允许通过，仅允许四方向移动的网格将按照 DiagonalOnlyWhenNoObstacles 进行判断
+//   - 平滑处理仅考虑可通行性，不考虑格子的移动代价
+func (slf *Grid) Smooth(path []geometry.Point[int]) []geometry.Point[int] {
+	if len(path) <= 2 {
+		return path
+	}
+	result := []geometry.Point[int]{path[0]}
+	anchor := 0
+	for anchor < len(path)-1 {
+		next := anchor + 1
+		for i := len(path) - 1; i > next; i-- {
+			if slf.lineWalkable(path[anchor], path[i]) {
+				next = i
+				break
+			}
+		}
+		result = append(result, path[next])
+		anchor = next
+	}
+	return result
+}
+
+// estimate 估算从 node 到 end 的剩余代价
+func (slf *Grid) estimate(node, end geometry.Point[int]) float64 {
+	return slf.heuristic(abs(node.GetX()-end.GetX()), abs(node.GetY()-end.GetY()))
+}
+
+// stepCost 计算移动到 x、y 所在格子的代价
+func (slf *Grid) stepCost(x, y int, diagonal bool) float64 {
+	cost := 1.0
+	if slf.cost != nil {
+		cost = slf.cost(x, y)
+	}
+	if diagonal {
+		cost *= math.Sqrt2
+	}
+	return cost
+}
+
+// rangeNeighbours 遍历 x、y 所在格子可到达的相邻格子
+func (slf *Grid) rangeNeighbours(x, y int, handle func(x, y int, diagonal bool)) {
+	for _, direction := range [][2]int{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+		if nx, ny := x+direction[0], y+direction[1]; slf.IsWalkable(nx, ny) {
+			handle(nx, ny, false)
+		}
+	}
+	if slf.diagonal == DiagonalNever {
+		return
+	}
+	for _, direction := range [][2]int{{-1, -1}, {1, -1}, {-1, 1}, {1, 1}} {
+		if nx, ny := x+direction[0], y+direction[1]; slf.IsWalkable(nx, ny) && slf.diagonalAllowed(x, y, direction[0], direction[1]) {
+			handle(nx, ny, true)
+		}
+	}
+}
+
+// diagonalAllowed 检查是否允许从 x、y 所在格子向 dx、dy 方向斜向移动，该函数不检查目标格子是否可通行
+func (slf *Grid) diagonalAllowed(x, y, dx, dy int) bool {
+	return slf.cornerPassable(slf.diagonal, x, y, dx, dy)
+}
+
+// cornerPassable 检查在 diagonal 规则下是否允许从 x、y 所在格子穿过拐角到达 dx、dy 方向的格子
+func (slf *Grid) cornerPassable(diagonal DiagonalMovement, x, y, dx, dy int) bool {
+	horizontal, vertical := slf.IsWalkable(x+dx, y), slf.IsWalkable(x, y+dy)
+	switch diagonal {
+	case DiagonalAlways:
+		return true
+	case DiagonalIfAtMostOneObstacle:
+		return horizontal || vertical
+	case DiagonalOnlyWhenNoObstacles:
+		return horizontal && vertical
+	default:
+		return false
+	}
+}
+
+// lineWalkable 检查从 a 到 b 的直线经过的所有格子是否均可通行
+func (slf *Grid) lineWalkable(a, b geometry.Point[int]) bool {
+	x, y := a.GetXY()
+	dx, dy := b.GetX()-x, b.GetY()-y
+	nx, ny, sx, sy := dx, dy, 1, 1
+	diagonal := slf.diagonal
+	if diagonal == DiagonalNever {
+		diagonal = DiagonalOnlyWhenNoObstacles
+	}
+	if nx < 0 {
+		nx, sx = -nx, -1
+	}
+	if ny < 0 {
+		ny, sy = -ny, -1
+	}
+	for ix, iy := 0, 0; ix < nx || iy < ny; {
+		switch decision := (1+2*ix)*ny - (1+2*iy)*nx; {
+		case decision == 0:
+			if !slf.cornerPassable(diagonal, x, y, sx, sy) {
+				return false
+			}
+			x, y, ix, iy = x+sx, y+sy, ix+1, iy+1
+		case decision < 0:
+			x, ix = x+sx, ix+1
+		default:
+			y, iy = y+sy, iy+1
+		}
+		if !slf.IsWalkable(x, y) {
+			return false
+		}
+	}
+	return true
+}
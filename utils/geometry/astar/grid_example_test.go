@@ -0,0 +1,37 @@
+package astar_test
+
+import (
+	"fmt"
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"github.com/kercylan98/minotaur/utils/geometry/astar"
+)
+
+func ExampleGrid_Find() {
+	floorPlan := geometry.FloorPlan{
+		"===========",
+		"X XX  X   X",
+		"X  X   XX X",
+		"X XX      X",
+		"X     XXX X",
+		"X XX  X   X",
+		"X XX  X   X",
+		"===========",
+	}
+	grid := astar.NewGridWithFloorPlan(floorPlan, astar.WithDiagonal(astar.DiagonalOnlyWhenNoObstacles))
+
+	for _, point := range grid.Find(geometry.NewPoint(1, 1), geometry.NewPoint(8, 6)) {
+		floorPlan.Put(point, '.')
+	}
+
+	fmt.Println(floorPlan)
+
+	// Output:
+	// ===========
+	// X.XX  X   X
+	// X. X   XX X
+	// X.XX .....X
+	// X.... XXX.X
+	// X XX  X  .X
+	// X XX  X . X
+	// ===========
+}
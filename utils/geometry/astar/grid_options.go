@@ -0,0 +1,37 @@
+package astar
+
+// GridOption 网格选项
+type GridOption func(grid *Grid)
+
+// DiagonalMovement 网格中的斜向移动规则
+type DiagonalMovement uint8
+
+const (
+	DiagonalNever               DiagonalMovement = iota // 仅允许上下左右四个方向移动
+	DiagonalAlways                                      // 允许斜向移动，即便斜向两侧的格子均为障碍
+	DiagonalIfAtMostOneObstacle                         // 斜向两侧的格子至多存在一个障碍时允许斜向移动
+	DiagonalOnlyWhenNoObstacles                         // 斜向两侧的格子均不存在障碍时才允许斜向移动，即不允许穿过障碍的拐角
+)
+
+// WithDiagonal 设置网格中的斜向移动规则，默认为 DiagonalNever
+func WithDiagonal(diagonal DiagonalMovement) GridOption {
+	return func(grid *Grid) {
+		grid.diagonal = diagonal
+	}
+}
+
+// WithCost 设置进入格子的移动代价，默认情况下进入任意格子的代价均为 1
+//   - 斜向移动的代价为进入目标格子代价的 √2 倍
+//   - 代价应不小于 1，否则启发函数可能高估剩余代价，导致搜索结果并非最短路径
+func WithCost(cost func(x, y int) float64) GridOption {
+	return func(grid *Grid) {
+		grid.cost = cost
+	}
+}
+
+// WithHeuristic 设置网格启发函数，默认情况下在仅允许四方向移动时使用 HeuristicManhattan，否则使用 HeuristicOctile
+func WithHeuristic(heuristic Heuristic) GridOption {
+	return func(grid *Grid) {
+		grid.heuristic = heuristic
+	}
+}
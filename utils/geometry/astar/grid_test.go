@@ -0,0 +1,72 @@
+package astar_test
+
+import (
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"github.com/kercylan98/minotaur/utils/geometry/astar"
+	"testing"
+)
+
+func TestGrid_FindDiagonal(t *testing.T) {
+	floorPlan := geometry.FloorPlan{
+		"  ",
+		"X ",
+	}
+	start, end := geometry.NewPoint(0, 0), geometry.NewPoint(1, 1)
+
+	var cases = []struct {
+		diagonal astar.DiagonalMovement
+		length   int
+	}{
+		{astar.DiagonalNever, 3},
+		{astar.DiagonalAlways, 2},
+		{astar.DiagonalIfAtMostOneObstacle, 2},
+		{astar.DiagonalOnlyWhenNoObstacles, 3},
+	}
+	for _, c := range cases {
+		path := astar.NewGridWithFloorPlan(floorPlan, astar.WithDiagonal(c.diagonal)).Find(start, end)
+		if len(path) != c.length {
+			t.Fatalf("diagonal %d: got path %v, want length %d", c.diagonal, path, c.length)
+		}
+	}
+}
+
+func TestGrid_FindCost(t *testing.T) {
+	grid := astar.NewGrid(3, 3, func(x, y int) bool { return true }, astar.WithCost(func(x, y int) float64 {
+		if x == 1 && y == 1 {
+			return 10
+		}
+		return 1
+	}))
+	for _, point := range grid.Find(geometry.NewPoint(0, 1), geometry.NewPoint(2, 1)) {
+		if point == geometry.NewPoint(1, 1) {
+			t.Fatalf("path should avoid the expensive cell")
+		}
+	}
+}
+
+func TestGrid_FindUnreachable(t *testing.T) {
+	grid := astar.NewGridWithFloorPlan(geometry.FloorPlan{
+		" X ",
+		" X ",
+	}, astar.WithDiagonal(astar.DiagonalAlways))
+	if path := grid.Find(geometry.NewPoint(0, 0), geometry.NewPoint(2, 1)); path != nil {
+		t.Fatalf("unexpected path %v", path)
+	}
+}
+
+func TestGrid_Smooth(t *testing.T) {
+	grid := astar.NewGridWithFloorPlan(geometry.FloorPlan{
+		"      ",
+		"      ",
+		"    X ",
+	})
+	path := grid.Smooth(grid.Find(geometry.NewPoint(0, 0), geometry.NewPoint(5, 2)))
+	if len(path) != 3 || path[0] != geometry.NewPoint(0, 0) || path[2] != geometry.NewPoint(5, 2) {
+		t.Fatalf("unexpected smoothed path %v", path)
+	}
+
+	path = grid.Smooth(grid.Find(geometry.NewPoint(0, 0), geometry.NewPoint(5, 1)))
+	if len(path) != 2 {
+		t.Fatalf("unexpected smoothed path %v", path)
+	}
+}
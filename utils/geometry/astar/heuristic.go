@@ -0,0 +1,32 @@
+package astar
+
+import "math"
+
+// Heuristic 网格启发函数，dx、dy 为当前格子与目标格子在 x、y 轴上的距离的绝对值
+type Heuristic func(dx, dy int) float64
+
+// HeuristicManhattan 曼哈顿距离，适用于仅允许上下左右四个方向移动的网格
+func HeuristicManhattan(dx, dy int) float64 {
+	return float64(dx + dy)
+}
+
+// HeuristicEuclidean 欧几里得距离，适用于允许任意方向移动的场景
+func HeuristicEuclidean(dx, dy int) float64 {
+	return math.Sqrt(float64(dx*dx + dy*dy))
+}
+
+// HeuristicOctile 八方向距离，适用于允许斜向移动且斜向移动代价为 √2 的网格
+func HeuristicOctile(dx, dy int) float64 {
+	if dx < dy {
+		dx, dy = dy, dx
+	}
+	return float64(dx-dy) + math.Sqrt2*float64(dy)
+}
+
+// HeuristicChebyshev 切比雪夫距离，适用于允许斜向移动且斜向移动代价与直线移动代价相同的网格
+func HeuristicChebyshev(dx, dy int) float64 {
+	if dx < dy {
+		return float64(dy)
+	}
+	return float64(dx)
+}
@@ -0,0 +1,247 @@
+package astar
+
+import (
+	"container/heap"
+	"github.com/kercylan98/minotaur/utils/geometry"
+)
+
+// FindJPS 使用跳点搜索（Jump Point Search）算法查找从 start 到 end 的最短路径，返回的路径包含起点和终点，当无法到达时返回 nil
+//   - 跳点搜索通过跳过对称路径大幅减少需要扩展的节点数量，在开阔的网格中通常远快于 Find
+//   - 跳点搜索要求所有格子的移动代价一致，因此将忽略 WithCost 设置的代价
+//   - 仅支持 DiagonalNever 及 DiagonalOnlyWhenNoObstacles 规则，其他规则下将使用 Find 进行查找
+func (slf *Grid) FindJPS(start, end geometry.Point[int]) []geometry.Point[int] {
+	if slf.diagonal != DiagonalNever && slf.diagonal != DiagonalOnlyWhenNoObstacles {
+		return slf.Find(start, end)
+	}
+	if !slf.IsWalkable(start.GetXY()) || !slf.IsWalkable(end.GetXY()) {
+		return nil
+	}
+
+	type record struct {
+		parent geometry.Point[int]
+		g      float64
+		closed bool
+	}
+
+	records := map[geometry.Point[int]]*record{start: {parent: start}}
+	open := &h[geometry.Point[int], float64]{}
+	heap.Push(open, &hm[geometry.Point[int], float64]{v: start, p: -slf.estimate(start, end)})
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(*hm[geometry.Point[int], float64]).v
+		r := records[current]
+		if r.closed {
+			continue
+		}
+		if current == end {
+			var jumpPoints []geometry.Point[int]
+			for node := end; ; node = records[node].parent {
+				jumpPoints = append(jumpPoints, node)
+				if node == start {
+					break
+				}
+			}
+			return expandJumpPoints(jumpPoints)
+		}
+		r.closed = true
+
+		for _, neighbour := range slf.jpsNeighbours(current, r.parent, current == start) {
+			jumpPoint, ok := slf.jump(neighbour.GetX(), neighbour.GetY(), current.GetX(), current.GetY(), end)
+			if !ok {
+				continue
+			}
+			g := r.g + slf.jumpDistance(current, jumpPoint)
+			if jr, exist := records[jumpPoint]; exist && (jr.closed || g >= jr.g) {
+				continue
+			}
+			records[jumpPoint] = &record{parent: current, g: g}
+			heap.Push(open, &hm[geometry.Point[int], float64]{v: jumpPoint, p: -(g + slf.estimate(jumpPoint, end))})
+		}
+	}
+	return nil
+}
+
+// jpsNeighbours 获取跳点搜索中经过剪枝后需要探索的相邻格子
+func (slf *Grid) jpsNeighbours(node, parent geometry.Point[int], isStart bool) []geometry.Point[int] {
+	if isStart {
+		return slf.Neighbours(node)
+	}
+
+	var neighbours []geometry.Point[int]
+	var push = func(x, y int) {
+		neighbours = append(neighbours, geometry.NewPoint(x, y))
+	}
+	x, y := node.GetXY()
+	dx, dy := sign(x-parent.GetX()), sign(y-parent.GetY())
+
+	if slf.diagonal == DiagonalNever {
+		if dx != 0 {
+			if slf.IsWalkable(x, y-1) {
+				push(x, y-1)
+			}
+			if slf.IsWalkable(x, y+1) {
+				push(x, y+1)
+			}
+			if slf.IsWalkable(x+dx, y) {
+				push(x+dx, y)
+			}
+		} else {
+			if slf.IsWalkable(x-1, y) {
+				push(x-1, y)
+			}
+			if slf.IsWalkable(x+1, y) {
+				push(x+1, y)
+			}
+			if slf.IsWalkable(x, y+dy) {
+				push(x, y+dy)
+			}
+		}
+		return neighbours
+	}
+
+	switch {
+	case dx != 0 && dy != 0:
+		vertical, horizontal := slf.IsWalkable(x, y+dy), slf.IsWalkable(x+dx, y)
+		if vertical {
+			push(x, y+dy)
+		}
+		if horizontal {
+			push(x+dx, y)
+		}
+		if vertical && horizontal && slf.IsWalkable(x+dx, y+dy) {
+			push(x+dx, y+dy)
+		}
+	case dx != 0:
+		next, top, bottom := slf.IsWalkable(x+dx, y), slf.IsWalkable(x, y-1), slf.IsWalkable(x, y+1)
+		if next {
+			push(x+dx, y)
+			if top && slf.IsWalkable(x+dx, y-1) {
+				push(x+dx, y-1)
+			}
+			if bottom && slf.IsWalkable(x+dx, y+1) {
+				push(x+dx, y+1)
+			}
+		}
+		if top {
+			push(x, y-1)
+		}
+		if bottom {
+			push(x, y+1)
+		}
+	default:
+		next, left, right := slf.IsWalkable(x, y+dy), slf.IsWalkable(x-1, y), slf.IsWalkable(x+1, y)
+		if next {
+			push(x, y+dy)
+			if left && slf.IsWalkable(x-1, y+dy) {
+				push(x-1, y+dy)
+			}
+			if right && slf.IsWalkable(x+1, y+dy) {
+				push(x+1, y+dy)
+			}
+		}
+		if left {
+			push(x-1, y)
+		}
+		if right {
+			push(x+1, y)
+		}
+	}
+	return neighbours
+}
+
+// jump 从 px、py 向 x、y 方向跳跃，返回找到的跳点
+func (slf *Grid) jump(x, y, px, py int, end geometry.Point[int]) (geometry.Point[int], bool) {
+	dx, dy := x-px, y-py
+	for {
+		if !slf.IsWalkable(x, y) {
+			return geometry.Point[int]{}, false
+		}
+		if x == end.GetX() && y == end.GetY() {
+			return end, true
+		}
+
+		if slf.diagonal == DiagonalNever {
+			if dx != 0 {
+				if (slf.IsWalkable(x, y-1) && !slf.IsWalkable(x-dx, y-1)) || (slf.IsWalkable(x, y+1) && !slf.IsWalkable(x-dx, y+1)) {
+					return geometry.NewPoint(x, y), true
+				}
+			} else {
+				if (slf.IsWalkable(x-1, y) && !slf.IsWalkable(x-1, y-dy)) || (slf.IsWalkable(x+1, y) && !slf.IsWalkable(x+1, y-dy)) {
+					return geometry.NewPoint(x, y), true
+				}
+				if _, ok := slf.jump(x+1, y, x, y, end); ok {
+					return geometry.NewPoint(x, y), true
+				}
+				if _, ok := slf.jump(x-1, y, x, y, end); ok {
+					return geometry.NewPoint(x, y), true
+				}
+			}
+			x, y = x+dx, y+dy
+			continue
+		}
+
+		switch {
+		case dx != 0 && dy != 0:
+			if _, ok := slf.jump(x+dx, y, x, y, end); ok {
+				return geometry.NewPoint(x, y), true
+			}
+			if _, ok := slf.jump(x, y+dy, x, y, end); ok {
+				return geometry.NewPoint(x, y), true
+			}
+			if !slf.IsWalkable(x+dx, y) || !slf.IsWalkable(x, y+dy) {
+				return geometry.Point[int]{}, false
+			}
+		case dx != 0:
+			if (slf.IsWalkable(x, y-1) && !slf.IsWalkable(x-dx, y-1)) || (slf.IsWalkable(x, y+1) && !slf.IsWalkable(x-dx, y+1)) {
+				return geometry.NewPoint(x, y), true
+			}
+		default:
+			if (slf.IsWalkable(x-1, y) && !slf.IsWalkable(x-1, y-dy)) || (slf.IsWalkable(x+1, y) && !slf.IsWalkable(x+1, y-dy)) {
+				return geometry.NewPoint(x, y), true
+			}
+		}
+		x, y = x+dx, y+dy
+	}
+}
+
+// jumpDistance 计算两个跳点之间的移动代价
+func (slf *Grid) jumpDistance(a, b geometry.Point[int]) float64 {
+	dx, dy := abs(a.GetX()-b.GetX()), abs(a.GetY()-b.GetY())
+	if slf.diagonal == DiagonalNever {
+		return HeuristicManhattan(dx, dy)
+	}
+	return HeuristicOctile(dx, dy)
+}
+
+// expandJumpPoints 将逆序的跳点序列展开为连续的格子路径
+func expandJumpPoints(jumpPoints []geometry.Point[int]) []geometry.Point[int] {
+	result := []geometry.Point[int]{jumpPoints[len(jumpPoints)-1]}
+	for i := len(jumpPoints) - 1; i > 0; i-- {
+		x, y := jumpPoints[i].GetXY()
+		tx, ty := jumpPoints[i-1].GetXY()
+		dx, dy := sign(tx-x), sign(ty-y)
+		for x != tx || y != ty {
+			x, y = x+dx, y+dy
+			result = append(result, geometry.NewPoint(x, y))
+		}
+	}
+	return result
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,75 @@
+package astar_test
+
+import (
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"github.com/kercylan98/minotaur/utils/geometry/astar"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func newRandomGrid(r *rand.Rand, width, height int, density float64, options ...astar.GridOption) *astar.Grid {
+	blocked := make([]bool, width*height)
+	for i := range blocked {
+		blocked[i] = r.Float64() < density
+	}
+	blocked[0], blocked[len(blocked)-1] = false, false
+	return astar.NewGrid(width, height, func(x, y int) bool {
+		return !blocked[y*width+x]
+	}, options...)
+}
+
+func calcPathCost(t *testing.T, grid *astar.Grid, path []geometry.Point[int]) float64 {
+	var cost float64
+	for i := 1; i < len(path); i++ {
+		dx, dy := path[i].GetX()-path[i-1].GetX(), path[i].GetY()-path[i-1].GetY()
+		if !grid.IsWalkable(path[i].GetXY()) || dx*dx+dy*dy > 2 || dx*dx+dy*dy == 0 {
+			t.Fatalf("invalid step %v -> %v", path[i-1], path[i])
+		}
+		if dx != 0 && dy != 0 {
+			cost += math.Sqrt2
+		} else {
+			cost++
+		}
+	}
+	return cost
+}
+
+func TestGrid_FindJPS(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, diagonal := range []astar.DiagonalMovement{astar.DiagonalNever, astar.DiagonalOnlyWhenNoObstacles, astar.DiagonalAlways} {
+		for i := 0; i < 50; i++ {
+			grid := newRandomGrid(r, 30, 30, 0.3, astar.WithDiagonal(diagonal))
+			start, end := geometry.NewPoint(0, 0), geometry.NewPoint(29, 29)
+			expected, actual := grid.Find(start, end), grid.FindJPS(start, end)
+			if (expected == nil) != (actual == nil) {
+				t.Fatalf("diagonal %d: reachability mismatch, find %v, jps %v", diagonal, expected, actual)
+			}
+			if expected == nil {
+				continue
+			}
+			if actual[0] != start || actual[len(actual)-1] != end {
+				t.Fatalf("diagonal %d: unexpected endpoints %v", diagonal, actual)
+			}
+			if e, a := calcPathCost(t, grid, expected), calcPathCost(t, grid, actual); math.Abs(e-a) > 1e-9 {
+				t.Fatalf("diagonal %d: cost mismatch, find %f, jps %f", diagonal, e, a)
+			}
+		}
+	}
+}
+
+func BenchmarkGrid_Find(b *testing.B) {
+	grid := newRandomGrid(rand.New(rand.NewSource(1)), 200, 200, 0.1, astar.WithDiagonal(astar.DiagonalOnlyWhenNoObstacles))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		grid.Find(geometry.NewPoint(0, 0), geometry.NewPoint(199, 199))
+	}
+}
+
+func BenchmarkGrid_FindJPS(b *testing.B) {
+	grid := newRandomGrid(rand.New(rand.NewSource(1)), 200, 200, 0.1, astar.WithDiagonal(astar.DiagonalOnlyWhenNoObstacles))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		grid.FindJPS(geometry.NewPoint(0, 0), geometry.NewPoint(199, 199))
+	}
+}
@@ -3,5 +3,6 @@
 //   - 几何形状："geometry"包支持处理各种几何形状，如点、线、多边形和圆等。您可以使用这些形状来表示和操作实际世界中的几何对象。
 //   - 几何计算：该包提供了一系列函数，用于执行常见的几何计算，如计算两点之间的距离、计算线段的长度、计算多边形的面积等。这些函数旨在提供高效和准确的计算结果。
 //   - 坐标转换："geometry"包还提供了一些函数，用于在不同坐标系之间进行转换。您可以将点从笛卡尔坐标系转换为极坐标系，或者从二维坐标系转换为三维坐标系等。
+//   - 视线与视野：该包提供了 Bresenham 直线、超覆盖直线、阴影投射视野以及 IsVisible 等网格视线计算函数，可用于实现战争迷雾等功能。
 //   - 简化接口：该包的设计目标之一是提供简化的接口，使几何计算变得更加直观和易于使用。您可以轻松地创建和操作几何对象，而无需处理繁琐的底层细节。
 package geometry
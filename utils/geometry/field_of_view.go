@@ -0,0 +1,86 @@
+package geometry
+
+// fieldOfViewOctants 将八个象限的坐标变换至第一象限的乘数
+var fieldOfViewOctants = [8][4]int{
+	{1, 0, 0, 1},
+	{0, 1, 1, 0},
+	{0, -1, 1, 0},
+	{-1, 0, 0, 1},
+	{-1, 0, 0, -1},
+	{0, -1, -1, 0},
+	{0, 1, -1, 0},
+	{1, 0, 0, -1},
+}
+
+// CalcFieldOfView 通过递归阴影投射（Recursive Shadowcasting）算法计算在网格中从 origin 出发，在 radius 半径内可以看到的所有格子
+//   - 结果包含 origin 本身，阻挡视线的格子在可见时同样会被包含在结果中，例如能够看到的墙
+//   - 当网格外的格子按照约定视为阻挡视线时，结果中可能会包含紧邻网格边界的网格外格子，需要时请自行过滤
+//   - radius 小于 0 时将不会返回任何格子
+func CalcFieldOfView(grid SightGrid, origin Point[int], radius int) []Point[int] {
+	var result []Point[int]
+	RangeFieldOfView(grid, origin, radius, func(point Point[int]) {
+		result = append(result, point)
+	})
+	return result
+}
+
+// RangeFieldOfView 遍历在网格中从 origin 出发，在 radius 半径内可以看到的所有格子，每个格子仅会被遍历一次
+//   - 规则可参考 CalcFieldOfView
+func RangeFieldOfView(grid SightGrid, origin Point[int], radius int, handle func(point Point[int])) {
+	if radius < 0 {
+		return
+	}
+	visited := map[Point[int]]struct{}{origin: {}}
+	handle(origin)
+	lit := func(point Point[int]) {
+		if _, exist := visited[point]; !exist {
+			visited[point] = struct{}{}
+			handle(point)
+		}
+	}
+	for _, octant := range fieldOfViewOctants {
+		castLight(grid, origin, radius, 1, 1, 0, octant, lit)
+	}
+}
+
+// castLight 在单个象限中从第 row 行开始，投射斜率位于 start 与 end 之间的光线
+func castLight(grid SightGrid, origin Point[int], radius, row int, start, end float64, octant [4]int, lit func(point Point[int])) {
+	if start < end {
+		return
+	}
+	ox, oy := origin.GetXY()
+	xx, xy, yx, yy := octant[0], octant[1], octant[2], octant[3]
+	var newStart float64
+	for j := row; j <= radius; j++ {
+		blocked := false
+		for dx, dy := -j, -j; dx <= 0; dx++ {
+			x, y := ox+dx*xx+dy*xy, oy+dx*yx+dy*yy
+			leftSlope, rightSlope := (float64(dx)-0.5)/(float64(dy)+0.5), (float64(dx)+0.5)/(float64(dy)-0.5)
+			if start < rightSlope {
+				continue
+			} else if end > leftSlope {
+				break
+			}
+
+			if dx*dx+dy*dy <= radius*radius {
+				lit(NewPoint(x, y))
+			}
+			opaque := grid.BlocksSight(x, y)
+			if blocked {
+				if opaque {
+					newStart = rightSlope
+					continue
+				}
+				blocked = false
+				start = newStart
+			} else if opaque && j < radius {
+				blocked = true
+				castLight(grid, origin, radius, j+1, start, leftSlope, octant, lit)
+				newStart = rightSlope
+			}
+		}
+		if blocked {
+			break
+		}
+	}
+}
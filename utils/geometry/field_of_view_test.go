@@ -0,0 +1,55 @@
+package geometry_test
+
+import (
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"testing"
+)
+
+func TestCalcFieldOfView(t *testing.T) {
+	floorPlan := geometry.FloorPlan{
+		"XXXXXXXXX",
+		"X       X",
+		"X       X",
+		"X   X   X",
+		"X       X",
+		"XXXXXXXXX",
+	}
+	visible := make(map[geometry.Point[int]]bool)
+	for _, point := range geometry.CalcFieldOfView(floorPlan, geometry.NewPoint(2, 3), 10) {
+		if visible[point] {
+			t.Fatalf("%v returned twice", point)
+		}
+		visible[point] = true
+	}
+
+	for _, point := range []geometry.Point[int]{{2, 3}, {1, 1}, {7, 1}, {4, 3}, {7, 4}, {0, 3}} {
+		if !visible[point] {
+			t.Fatalf("%v should be visible", point)
+		}
+	}
+	for _, point := range []geometry.Point[int]{{5, 3}, {6, 3}, {7, 3}} {
+		if visible[point] {
+			t.Fatalf("%v should be hidden behind the wall", point)
+		}
+	}
+	for point := range visible {
+		if point.GetX() < 0 || point.GetY() < 0 || point.GetX() >= 9 || point.GetY() >= 6 {
+			t.Fatalf("%v is outside of the enclosed room", point)
+		}
+	}
+}
+
+func TestCalcFieldOfView_Radius(t *testing.T) {
+	open := geometry.SightGridFunc(func(x, y int) bool { return false })
+	if result := geometry.CalcFieldOfView(open, geometry.NewPoint(0, 0), 0); len(result) != 1 {
+		t.Fatalf("unexpected result %v", result)
+	}
+	for _, point := range geometry.CalcFieldOfView(open, geometry.NewPoint(0, 0), 5) {
+		if x, y := point.GetXY(); x*x+y*y > 25 {
+			t.Fatalf("%v is out of radius", point)
+		}
+	}
+	if result := geometry.CalcFieldOfView(open, geometry.NewPoint(0, 0), 1); len(result) != 5 {
+		t.Fatalf("unexpected result %v", result)
+	}
+}
@@ -17,6 +17,11 @@ func (slf FloorPlan) IsInBounds(point Point[int]) bool {
 	return (0 <= point.GetX() && point.GetX() < len(slf[point.GetY()])) && (0 <= point.GetY() && point.GetY() < len(slf))
 }
 
+// BlocksSight 检查位置是否阻挡视线，非空格及边界外的位置均视为阻挡视线，用于实现 SightGrid 接口
+func (slf FloorPlan) BlocksSight(x, y int) bool {
+	return y < 0 || y >= len(slf) || x < 0 || x >= len(slf[y]) || slf[y][x] != ' '
+}
+
 // Put 设置平面图特定位置的字符
 func (slf FloorPlan) Put(point Point[int], c rune) {
 	slf[point.GetY()] = slf[point.GetY()][:point.GetX()] + string(c) + slf[point.GetY()][point.GetX()+1:]
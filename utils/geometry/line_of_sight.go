@@ -0,0 +1,112 @@
+package geometry
+
+// SightGrid 用于计算视线及视野的网格
+type SightGrid interface {
+	// BlocksSight 检查格子是否阻挡视线，网格外的格子应视为阻挡视线
+	BlocksSight(x, y int) bool
+}
+
+// SightGridFunc 以函数形式实现的 SightGrid
+type SightGridFunc func(x, y int) bool
+
+// BlocksSight 检查格子是否阻挡视线
+func (slf SightGridFunc) BlocksSight(x, y int) bool {
+	return slf(x, y)
+}
+
+// CalcBresenhamLine 通过 Bresenham 算法计算从 from 到 to 的直线所经过的格子，结果包含起点和终点
+//   - 每一步仅会经过一个格子，斜向的直线将以斜向步进的方式穿过格子拐角
+func CalcBresenhamLine(from, to Point[int]) []Point[int] {
+	var result []Point[int]
+	RangeBresenhamLine(from, to, func(point Point[int]) bool {
+		result = append(result, point)
+		return true
+	})
+	return result
+}
+
+// RangeBresenhamLine 通过 Bresenham 算法按顺序遍历从 from 到 to 的直线所经过的格子，当 handle 返回 false 时将停止遍历
+func RangeBresenhamLine(from, to Point[int], handle func(point Point[int]) bool) {
+	x, y := from.GetXY()
+	tx, ty := to.GetXY()
+	dx, sx := lineStep(tx - x)
+	dy, sy := lineStep(ty - y)
+	dy = -dy
+	err := dx + dy
+	for {
+		if !handle(NewPoint(x, y)) || (x == tx && y == ty) {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y += sy
+		}
+	}
+}
+
+// CalcSupercoverLine 计算从 from 到 to 的直线所接触到的所有格子，结果包含起点和终点
+//   - 与 Bresenham 算法不同，当直线恰好穿过格子拐角时，拐角两侧的格子均会被包含在结果中
+func CalcSupercoverLine(from, to Point[int]) []Point[int] {
+	var result []Point[int]
+	RangeSupercoverLine(from, to, func(point Point[int]) bool {
+		result = append(result, point)
+		return true
+	})
+	return result
+}
+
+// RangeSupercoverLine 按顺序遍历从 from 到 to 的直线所接触到的所有格子，当 handle 返回 false 时将停止遍历
+//   - 当直线恰好穿过格子拐角时，将先后遍历拐角两侧的格子，再遍历对角的格子
+func RangeSupercoverLine(from, to Point[int], handle func(point Point[int]) bool) {
+	x, y := from.GetXY()
+	nx, sx := lineStep(to.GetX() - x)
+	ny, sy := lineStep(to.GetY() - y)
+	if !handle(NewPoint(x, y)) {
+		return
+	}
+	for ix, iy := 0, 0; ix < nx || iy < ny; {
+		switch decision := (1+2*ix)*ny - (1+2*iy)*nx; {
+		case decision == 0:
+			if !handle(NewPoint(x+sx, y)) || !handle(NewPoint(x, y+sy)) {
+				return
+			}
+			x, y, ix, iy = x+sx, y+sy, ix+1, iy+1
+		case decision < 0:
+			x, ix = x+sx, ix+1
+		default:
+			y, iy = y+sy, iy+1
+		}
+		if !handle(NewPoint(x, y)) {
+			return
+		}
+	}
+}
+
+// IsVisible 检查在网格中从 from 是否能够看到 to
+//   - 视线经过的格子由 RangeSupercoverLine 计算，起点和终点本身是否阻挡视线不会影响结果，例如能够看到一面墙
+//   - 当视线恰好穿过格子拐角时，拐角两侧的格子任意一个阻挡视线都将视为不可见，以避免透过斜向相邻的两面墙之间的缝隙看到对面
+//   - 该函数的结果是对称的，即 IsVisible(grid, a, b) 与 IsVisible(grid, b, a) 的结果始终相同
+func IsVisible(grid SightGrid, from, to Point[int]) bool {
+	visible := true
+	RangeSupercoverLine(from, to, func(point Point[int]) bool {
+		if point == from || point == to {
+			return true
+		}
+		visible = !grid.BlocksSight(point.GetXY())
+		return visible
+	})
+	return visible
+}
+
+// lineStep 获取直线在单个轴上的距离及步进方向
+func lineStep(delta int) (distance, step int) {
+	if delta < 0 {
+		return -delta, -1
+	}
+	return delta, 1
+}
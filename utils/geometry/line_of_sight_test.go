@@ -0,0 +1,69 @@
+package geometry_test
+
+import (
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"math/rand"
+	"testing"
+)
+
+func TestCalcBresenhamLine(t *testing.T) {
+	line := geometry.CalcBresenhamLine(geometry.NewPoint(0, 0), geometry.NewPoint(4, 2))
+	expected := []geometry.Point[int]{{0, 0}, {1, 1}, {2, 1}, {3, 2}, {4, 2}}
+	if len(line) != len(expected) {
+		t.Fatalf("got %v, want %v", line, expected)
+	}
+	for i := range expected {
+		if line[i] != expected[i] {
+			t.Fatalf("got %v, want %v", line, expected)
+		}
+	}
+
+	if line = geometry.CalcBresenhamLine(geometry.NewPoint(3, 3), geometry.NewPoint(0, 0)); len(line) != 4 || line[3] != geometry.NewPoint(0, 0) {
+		t.Fatalf("unexpected line %v", line)
+	}
+}
+
+func TestCalcSupercoverLine(t *testing.T) {
+	line := geometry.CalcSupercoverLine(geometry.NewPoint(0, 0), geometry.NewPoint(2, 2))
+	expected := []geometry.Point[int]{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {2, 1}, {1, 2}, {2, 2}}
+	if len(line) != len(expected) {
+		t.Fatalf("got %v, want %v", line, expected)
+	}
+	for i := range expected {
+		if line[i] != expected[i] {
+			t.Fatalf("got %v, want %v", line, expected)
+		}
+	}
+}
+
+func TestIsVisible(t *testing.T) {
+	floorPlan := geometry.FloorPlan{
+		"     ",
+		"  X  ",
+		" X   ",
+		"     ",
+	}
+	if geometry.IsVisible(floorPlan, geometry.NewPoint(0, 1), geometry.NewPoint(4, 1)) {
+		t.Fatalf("wall should block sight")
+	}
+	if !geometry.IsVisible(floorPlan, geometry.NewPoint(0, 0), geometry.NewPoint(4, 0)) {
+		t.Fatalf("sight should be clear")
+	}
+	if !geometry.IsVisible(floorPlan, geometry.NewPoint(0, 0), geometry.NewPoint(2, 1)) {
+		t.Fatalf("wall itself should be visible")
+	}
+	if geometry.IsVisible(floorPlan, geometry.NewPoint(1, 1), geometry.NewPoint(2, 2)) {
+		t.Fatalf("diagonal gap between walls should block sight")
+	}
+
+	r := rand.New(rand.NewSource(1))
+	grid := geometry.SightGridFunc(func(x, y int) bool {
+		return (x*7+y*13)%5 == 0
+	})
+	for i := 0; i < 1000; i++ {
+		a, b := geometry.NewPoint(r.Intn(20), r.Intn(20)), geometry.NewPoint(r.Intn(20), r.Intn(20))
+		if geometry.IsVisible(grid, a, b) != geometry.IsVisible(grid, b, a) {
+			t.Fatalf("visibility between %v and %v is not symmetric", a, b)
+		}
+	}
+}
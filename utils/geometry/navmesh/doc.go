@@ -2,4 +2,6 @@
 // 主要特性：
 //   - 导航网格表示：navmesh 包支持使用导航网格来表示虚拟环境中的可行走区域和障碍物。您可以定义多边形区域和连接关系，以构建导航网格，并在其中执行路径规划和导航。
 //   - 导航算法：采用了 A* 算法作为导航算法，用于在导航网格中找到最短路径或最优路径。这些算法使用启发式函数和代价评估来指导路径搜索，并提供高效的路径规划能力。
+//   - 网格加载：支持通过形状列表、三角形列表、多边形顶点索引列表以及 Recast 生成的多边形网格创建导航网格。
+//   - 空间查询：支持定位点所在的形状，以及检测两点之间的直线是否全程位于导航网格内。
 package navmesh
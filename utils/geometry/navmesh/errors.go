@@ -0,0 +1,12 @@
+package navmesh
+
+import "errors"
+
+var (
+	// ErrInvalidTriangles 三角形索引数量不是 3 的倍数
+	ErrInvalidTriangles = errors.New("the number of triangle indices must be a multiple of 3")
+	// ErrInvalidPolygon 多边形的顶点数量少于 3 个
+	ErrInvalidPolygon = errors.New("polygon must have at least 3 vertices")
+	// ErrVertexIndexOutOfRange 顶点索引超出顶点列表的范围
+	ErrVertexIndexOutOfRange = errors.New("vertex index out of range")
+)
@@ -0,0 +1,65 @@
+package navmesh
+
+import (
+	"github.com/kercylan98/minotaur/utils/generic"
+	"github.com/kercylan98/minotaur/utils/geometry"
+)
+
+// RecastNullIndex Recast 多边形网格中用于填充多边形顶点索引的空索引
+const RecastNullIndex = 0xffff
+
+// NewNavMeshWithTriangles 通过三角形列表创建导航网格
+//   - vertices 为顶点列表，indices 中每 3 个索引表示一个三角形
+//   - 相邻三角形需要共享相同的边才能被连接
+func NewNavMeshWithTriangles[V generic.SignedNumber](vertices []geometry.Point[V], indices []int, meshShrinkAmount V) (*NavMesh[V], error) {
+	if len(indices)%3 != 0 {
+		return nil, ErrInvalidTriangles
+	}
+	polygons := make([][]int, 0, len(indices)/3)
+	for i := 0; i < len(indices); i += 3 {
+		polygons = append(polygons, indices[i:i+3])
+	}
+	return NewNavMeshWithPolygons(vertices, polygons, meshShrinkAmount)
+}
+
+// NewNavMeshWithPolygons 通过多边形列表创建导航网格
+//   - vertices 为顶点列表，polygons 中的每个元素为组成一个多边形的顶点索引，多边形需为凸多边形
+//   - 相邻多边形需要共享相同的边才能被连接
+func NewNavMeshWithPolygons[V generic.SignedNumber](vertices []geometry.Point[V], polygons [][]int, meshShrinkAmount V) (*NavMesh[V], error) {
+	shapes := make([]geometry.Shape[V], 0, len(polygons))
+	for _, polygon := range polygons {
+		if len(polygon) < 3 {
+			return nil, ErrInvalidPolygon
+		}
+		points := make([]geometry.Point[V], len(polygon))
+		for i, index := range polygon {
+			if index < 0 || index >= len(vertices) {
+				return nil, ErrVertexIndexOutOfRange
+			}
+			points[i] = vertices[index]
+		}
+		shapes = append(shapes, geometry.NewShape(points...))
+	}
+	return NewNavMesh(shapes, meshShrinkAmount), nil
+}
+
+// NewNavMeshWithRecast 通过 Recast 生成的多边形网格（rcPolyMesh）创建导航网格
+//   - vertices 为三维顶点列表，将投影到 XZ 平面上，Y 轴高度信息将被忽略
+//   - polygons 中的每个元素为组成一个多边形的顶点索引，其中的 RecastNullIndex 将被视为填充并忽略
+//   - 适用于由三维场景烘焙得到的、在平面上不存在重叠的可行走区域
+func NewNavMeshWithRecast[V generic.SignedNumber](vertices [][3]V, polygons [][]int, meshShrinkAmount V) (*NavMesh[V], error) {
+	projected := make([]geometry.Point[V], len(vertices))
+	for i, vertex := range vertices {
+		projected[i] = geometry.NewPoint(vertex[0], vertex[2])
+	}
+	trimmed := make([][]int, len(polygons))
+	for i, polygon := range polygons {
+		for _, index := range polygon {
+			if index == RecastNullIndex {
+				break
+			}
+			trimmed[i] = append(trimmed[i], index)
+		}
+	}
+	return NewNavMeshWithPolygons(projected, trimmed, meshShrinkAmount)
+}
@@ -33,12 +33,14 @@ func NewNavMesh[V generic.SignedNumber](shapes []geometry.Shape[V], meshShrinkAm
 		nm.meshShapes[i] = newShape(i, shape)
 	}
 	nm.generateLink()
+	nm.generateIndex()
 	return nm
 }
 
 type NavMesh[V generic.SignedNumber] struct {
 	meshShapes       []*shape[V]
 	meshShrinkAmount V
+	index            *geometry.QuadTree[V, *shape[V]]
 }
 
 // Neighbours 实现 astar.Graph 的接口，用于向 A* 算法提供相邻图形
@@ -90,6 +92,26 @@ func (slf *NavMesh[V]) Find(point geometry.Point[V], maxDistance V) (distance V,
 	return minDistance, pointOnClosest, closest.Shape
 }
 
+// Locate 查找包含给定点的形状，点位于形状的边上时亦视为包含
+//   - 当点位于多个形状的公共边上时，将返回其中任意一个形状
+func (slf *NavMesh[V]) Locate(point geometry.Point[V]) (geometry.Shape[V], bool) {
+	if meshShape := slf.locate(point); meshShape != nil {
+		return meshShape.Shape, true
+	}
+	return nil, false
+}
+
+// locate 查找包含给定点的形状
+func (slf *NavMesh[V]) locate(point geometry.Point[V]) *shape[V] {
+	x, y := point.GetXY()
+	for _, meshShape := range slf.index.QueryRect(geometry.NewAABB(x, y, 0, 0)) {
+		if meshShape.Contains(point) || geometry.IsPointOnEdge(meshShape.edges, point) {
+			return meshShape
+		}
+	}
+	return nil
+}
+
 // FindPath 函数用于在 NavMesh 中查找从起点到终点的路径，并返回路径上的点序列。
 //
 // 参数：
@@ -269,3 +291,21 @@ func (slf *NavMesh[V]) generateLink() {
 		}
 	}
 }
+
+// generateIndex 生成用于定位形状的空间索引
+func (slf *NavMesh[V]) generateIndex() {
+	var bounds geometry.AABB[V]
+	for i, meshShape := range slf.meshShapes {
+		if i == 0 {
+			bounds = meshShape.bounds
+			continue
+		}
+		minX, minY := min(bounds.X, meshShape.bounds.X), min(bounds.Y, meshShape.bounds.Y)
+		maxX, maxY := max(bounds.MaxX(), meshShape.bounds.MaxX()), max(bounds.MaxY(), meshShape.bounds.MaxY())
+		bounds = geometry.NewAABB(minX, minY, maxX-minX, maxY-minY)
+	}
+	slf.index = geometry.NewQuadTree[V, *shape[V]](bounds, 0, 0)
+	for _, meshShape := range slf.meshShapes {
+		slf.index.Insert(meshShape, meshShape.bounds)
+	}
+}
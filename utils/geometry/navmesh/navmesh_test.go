@@ -0,0 +1,100 @@
+package navmesh_test
+
+import (
+	"errors"
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"github.com/kercylan98/minotaur/utils/geometry/navmesh"
+	"testing"
+)
+
+// newLShapedNavMesh 创建一个由三角形组成的 L 形导航网格
+//
+//	(0,0)----(10,0)----(20,0)
+//	  |         |         |
+//	(0,10)---(10,10)---(20,10)
+//	            |         |
+//	         (10,20)---(20,20)
+func newLShapedNavMesh(t *testing.T) *navmesh.NavMesh[float64] {
+	vertices := []geometry.Point[float64]{
+		geometry.NewPoint[float64](0, 0),
+		geometry.NewPoint[float64](10, 0),
+		geometry.NewPoint[float64](10, 10),
+		geometry.NewPoint[float64](0, 10),
+		geometry.NewPoint[float64](20, 0),
+		geometry.NewPoint[float64](20, 10),
+		geometry.NewPoint[float64](20, 20),
+		geometry.NewPoint[float64](10, 20),
+	}
+	nm, err := navmesh.NewNavMeshWithTriangles(vertices, []int{
+		0, 1, 2,
+		0, 2, 3,
+		1, 4, 5,
+		1, 5, 2,
+		2, 5, 6,
+		2, 6, 7,
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return nm
+}
+
+func TestNewNavMeshWithTriangles(t *testing.T) {
+	vertices := []geometry.Point[float64]{geometry.NewPoint[float64](0, 0), geometry.NewPoint[float64](1, 0), geometry.NewPoint[float64](1, 1)}
+	if _, err := navmesh.NewNavMeshWithTriangles(vertices, []int{0, 1}, 0); !errors.Is(err, navmesh.ErrInvalidTriangles) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if _, err := navmesh.NewNavMeshWithTriangles(vertices, []int{0, 1, 3}, 0); !errors.Is(err, navmesh.ErrVertexIndexOutOfRange) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestNewNavMeshWithRecast(t *testing.T) {
+	nm, err := navmesh.NewNavMeshWithRecast([][3]float64{
+		{0, 5, 0}, {10, 5, 0}, {10, 5, 10}, {0, 5, 10}, {20, 5, 0}, {20, 5, 10},
+	}, [][]int{
+		{0, 1, 2, 3, navmesh.RecastNullIndex, navmesh.RecastNullIndex},
+		{1, 4, 5, 2, navmesh.RecastNullIndex, navmesh.RecastNullIndex},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := nm.Locate(geometry.NewPoint[float64](15, 5)); !ok {
+		t.Fatalf("point should be located")
+	}
+	if path := nm.FindPath(geometry.NewPoint[float64](1, 1), geometry.NewPoint[float64](19, 9)); len(path) != 2 {
+		t.Fatalf("unexpected path %v", path)
+	}
+}
+
+func TestNavMesh_Locate(t *testing.T) {
+	nm := newLShapedNavMesh(t)
+	if _, ok := nm.Locate(geometry.NewPoint[float64](15, 15)); !ok {
+		t.Fatalf("point should be located")
+	}
+	if _, ok := nm.Locate(geometry.NewPoint[float64](5, 15)); ok {
+		t.Fatalf("point should not be located")
+	}
+}
+
+func TestNavMesh_FindPath(t *testing.T) {
+	nm := newLShapedNavMesh(t)
+	path := nm.FindPath(geometry.NewPoint[float64](1, 5), geometry.NewPoint[float64](15, 19))
+	if len(path) != 3 || !path[1].Equal(geometry.NewPoint[float64](10, 10)) {
+		t.Fatalf("unexpected path %v", path)
+	}
+}
+
+func TestNavMesh_Raycast(t *testing.T) {
+	nm := newLShapedNavMesh(t)
+
+	if hit, clear := nm.Raycast(geometry.NewPoint[float64](8, 2), geometry.NewPoint[float64](16, 18)); !clear || !hit.Equal(geometry.NewPoint[float64](16, 18)) {
+		t.Fatalf("unexpected hit %v %v", hit, clear)
+	}
+	if hit, clear := nm.Raycast(geometry.NewPoint[float64](5, 5), geometry.NewPoint[float64](5, 15)); clear || !hit.Equal(geometry.NewPoint[float64](5, 10)) {
+		t.Fatalf("unexpected hit %v %v", hit, clear)
+	}
+	if hit, clear := nm.Raycast(geometry.NewPoint[float64](-5, 5), geometry.NewPoint[float64](5, 5)); clear || !hit.Equal(geometry.NewPoint[float64](-5, 5)) {
+		t.Fatalf("unexpected hit %v %v", hit, clear)
+	}
+}
@@ -0,0 +1,80 @@
+package navmesh
+
+import (
+	"github.com/kercylan98/minotaur/utils/generic"
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"github.com/kercylan98/minotaur/utils/maths"
+	"math"
+)
+
+// Raycast 在导航网格上检测从 start 到 end 的直线是否畅通无阻
+//   - 当直线全程位于导航网格内时，返回 end 和 true
+//   - 当直线在途中离开导航网格时，返回离开导航网格的位置和 false
+//   - 当 start 不在导航网格内时，返回 start 和 false
+//   - 射线检测依赖于形状为凸多边形，当存在凹多边形时结果可能不准确
+func (slf *NavMesh[V]) Raycast(start, end geometry.Point[V]) (hit geometry.Point[V], clear bool) {
+	current := slf.locate(start)
+	if current == nil {
+		return start, false
+	}
+
+	sx, sy := float64(start.GetX()), float64(start.GetY())
+	dx, dy := float64(end.GetX())-sx, float64(end.GetY())-sy
+	visited := make(map[*shape[V]]bool)
+	for {
+		if current.Contains(end) || geometry.IsPointOnEdge(current.edges, end) {
+			return end, true
+		}
+		visited[current] = true
+
+		exit := -1.0
+		for _, edge := range current.edges {
+			if t, ok := calcRayEdgeIntersection(sx, sy, dx, dy, edge); ok && t > exit {
+				exit = t
+			}
+		}
+		if exit < 0 {
+			return start, false
+		}
+
+		px, py := sx+dx*exit, sy+dy*exit
+		var next *shape[V]
+		for i, portal := range current.portals {
+			if !visited[current.links[i]] && isPointOnSegment(px, py, portal) {
+				next = current.links[i]
+				break
+			}
+		}
+		if next == nil {
+			return geometry.NewPoint(V(px), V(py)), false
+		}
+		current = next
+	}
+}
+
+// calcRayEdgeIntersection 计算从 sx、sy 出发，方向为 dx、dy 的射线与 edge 的交点在射线上的参数 t
+func calcRayEdgeIntersection[V generic.SignedNumber](sx, sy, dx, dy float64, edge geometry.LineSegment[V]) (t float64, ok bool) {
+	ax, ay := float64(edge.GetStart().GetX()), float64(edge.GetStart().GetY())
+	ex, ey := float64(edge.GetEnd().GetX())-ax, float64(edge.GetEnd().GetY())-ay
+	denominator := dx*ey - dy*ex
+	if math.Abs(denominator) < maths.GetDefaultTolerance() {
+		return 0, false
+	}
+	t = ((ax-sx)*ey - (ay-sy)*ex) / denominator
+	u := ((ax-sx)*dy - (ay-sy)*dx) / denominator
+	tolerance := maths.GetDefaultTolerance()
+	return t, u >= -tolerance && u <= 1+tolerance
+}
+
+// isPointOnSegment 检查 x、y 是否位于 segment 上
+func isPointOnSegment[V generic.SignedNumber](x, y float64, segment geometry.LineSegment[V]) bool {
+	ax, ay := float64(segment.GetStart().GetX()), float64(segment.GetStart().GetY())
+	bx, by := float64(segment.GetEnd().GetX()), float64(segment.GetEnd().GetY())
+	ex, ey := bx-ax, by-ay
+	length := ex*ex + ey*ey
+	if length == 0 {
+		return math.Hypot(x-ax, y-ay) <= maths.GetDefaultTolerance()
+	}
+	t := math.Max(0, math.Min(1, ((x-ax)*ex+(y-ay)*ey)/length))
+	return math.Hypot(x-(ax+ex*t), y-(ay+ey*t)) <= maths.GetDefaultTolerance()
+}
@@ -12,9 +12,24 @@ func newShape[V generic.SignedNumber](id int, s geometry.Shape[V]) *shape[V] {
 		centroid:       geometry.CalcRectangleCentroid(s),
 		boundingRadius: geometry.CalcBoundingRadius(s),
 		edges:          s.Edges(),
+		bounds:         calcShapeBounds(s),
 	}
 }
 
+// calcShapeBounds 计算形状的轴对齐包围盒
+func calcShapeBounds[V generic.SignedNumber](s geometry.Shape[V]) geometry.AABB[V] {
+	var minX, minY, maxX, maxY V
+	for i, point := range s.Points() {
+		x, y := point.GetXY()
+		if i == 0 {
+			minX, minY, maxX, maxY = x, y, x, y
+			continue
+		}
+		minX, minY, maxX, maxY = min(minX, x), min(minY, y), max(maxX, x), max(maxY, y)
+	}
+	return geometry.NewAABB(minX, minY, maxX-minX, maxY-minY)
+}
+
 type shape[V generic.SignedNumber] struct {
 	id int
 	geometry.Shape[V]
@@ -23,6 +38,7 @@ type shape[V generic.SignedNumber] struct {
 	boundingRadius V
 	centroid       geometry.Point[V]
 	edges          []geometry.LineSegment[V]
+	bounds         geometry.AABB[V]
 
 	weight V
 	x, y   V
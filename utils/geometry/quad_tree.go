@@ -0,0 +1,207 @@
+package geometry
+
+import "github.com/kercylan98/minotaur/utils/generic"
+
+// NewQuadTree 创建一个覆盖 bounds 范围的四叉树
+//   - capacity 为节点在分裂前能够容纳的对象数量，小于等于 0 时默认为 8
+//   - maxDepth 为四叉树的最大深度，小于等于 0 时默认为 8
+//   - 超出 bounds 范围的对象仍然可以插入，但将被存储在根节点中，数量较多时将降低查询效率
+func NewQuadTree[V generic.SignedNumber, T comparable](bounds AABB[V], capacity, maxDepth int) *QuadTree[V, T] {
+	if capacity <= 0 {
+		capacity = 8
+	}
+	if maxDepth <= 0 {
+		maxDepth = 8
+	}
+	return &QuadTree[V, T]{
+		root:     &quadTreeNode[V, T]{bounds: bounds},
+		items:    make(map[T]quadTreeItem[V, T]),
+		capacity: capacity,
+		maxDepth: maxDepth,
+	}
+}
+
+// QuadTree 四叉树，用于对具有包围盒的对象进行空间索引，支持插入、移除、更新及矩形、圆形范围查询
+//   - 无法完全放入任一子节点的对象将被存储在父节点中
+//   - 非并发安全
+type QuadTree[V generic.SignedNumber, T comparable] struct {
+	root     *quadTreeNode[V, T]
+	items    map[T]quadTreeItem[V, T]
+	capacity int
+	maxDepth int
+}
+
+// quadTreeItem 四叉树中的对象
+type quadTreeItem[V generic.SignedNumber, T comparable] struct {
+	item   T
+	bounds AABB[V]
+	node   *quadTreeNode[V, T]
+}
+
+// quadTreeNode 四叉树节点
+type quadTreeNode[V generic.SignedNumber, T comparable] struct {
+	bounds   AABB[V]
+	depth    int
+	parent   *quadTreeNode[V, T]
+	children *[4]*quadTreeNode[V, T]
+	items    []T
+}
+
+// Insert 插入对象，当对象已存在时将更新对象的包围盒
+func (slf *QuadTree[V, T]) Insert(item T, bounds AABB[V]) {
+	if _, exist := slf.items[item]; exist {
+		slf.Update(item, bounds)
+		return
+	}
+	slf.insert(slf.root, item, bounds)
+}
+
+// Remove 移除对象，返回对象是否存在
+func (slf *QuadTree[V, T]) Remove(item T) bool {
+	info, exist := slf.items[item]
+	if !exist {
+		return false
+	}
+	delete(slf.items, item)
+	info.node.remove(item)
+	return true
+}
+
+// Update 更新对象的包围盒，当对象不存在时将插入对象
+//   - 当新的包围盒仍然完全位于对象所在节点且无法放入子节点时，将不会移动对象
+func (slf *QuadTree[V, T]) Update(item T, bounds AABB[V]) {
+	info, exist := slf.items[item]
+	if !exist {
+		slf.insert(slf.root, item, bounds)
+		return
+	}
+	node := info.node
+	if (node == slf.root || node.bounds.Contains(bounds)) && node.childFor(bounds) == nil {
+		info.bounds = bounds
+		slf.items[item] = info
+		return
+	}
+	node.remove(item)
+	for node.parent != nil && !node.bounds.Contains(bounds) {
+		node = node.parent
+	}
+	slf.insert(node, item, bounds)
+}
+
+// Get 获取对象的包围盒
+func (slf *QuadTree[V, T]) Get(item T) (bounds AABB[V], exist bool) {
+	info, exist := slf.items[item]
+	return info.bounds, exist
+}
+
+// Len 获取对象数量
+func (slf *QuadTree[V, T]) Len() int {
+	return len(slf.items)
+}
+
+// Clear 清空四叉树
+func (slf *QuadTree[V, T]) Clear() {
+	slf.root = &quadTreeNode[V, T]{bounds: slf.root.bounds}
+	slf.items = make(map[T]quadTreeItem[V, T])
+}
+
+// QueryRect 获取包围盒与 bounds 相交的所有对象
+func (slf *QuadTree[V, T]) QueryRect(bounds AABB[V]) []T {
+	var result []T
+	slf.query(slf.root, bounds.Intersects, &result)
+	return result
+}
+
+// QueryCircle 获取包围盒与以 x、y 为圆心，radius 为半径的圆相交的所有对象
+func (slf *QuadTree[V, T]) QueryCircle(x, y, radius V) []T {
+	var result []T
+	slf.query(slf.root, func(bounds AABB[V]) bool {
+		return bounds.IntersectsCircle(x, y, radius)
+	}, &result)
+	return result
+}
+
+// query 递归查询与 hit 相交的对象
+func (slf *QuadTree[V, T]) query(node *quadTreeNode[V, T], hit func(bounds AABB[V]) bool, result *[]T) {
+	for _, item := range node.items {
+		if hit(slf.items[item].bounds) {
+			*result = append(*result, item)
+		}
+	}
+	if node.children == nil {
+		return
+	}
+	for _, child := range node.children {
+		if hit(child.bounds) {
+			slf.query(child, hit, result)
+		}
+	}
+}
+
+// insert 从特定节点开始插入对象
+func (slf *QuadTree[V, T]) insert(node *quadTreeNode[V, T], item T, bounds AABB[V]) {
+	for {
+		if node.children == nil && len(node.items) >= slf.capacity && node.depth < slf.maxDepth {
+			slf.split(node)
+		}
+		child := node.childFor(bounds)
+		if child == nil {
+			break
+		}
+		node = child
+	}
+	node.items = append(node.items, item)
+	slf.items[item] = quadTreeItem[V, T]{item: item, bounds: bounds, node: node}
+}
+
+// split 分裂节点，并将能够放入子节点的对象移动到子节点中
+func (slf *QuadTree[V, T]) split(node *quadTreeNode[V, T]) {
+	b := node.bounds
+	hw, hh := b.Width/2, b.Height/2
+	if hw <= 0 || hh <= 0 {
+		return
+	}
+	node.children = &[4]*quadTreeNode[V, T]{
+		{bounds: NewAABB(b.X, b.Y, hw, hh), depth: node.depth + 1, parent: node},
+		{bounds: NewAABB(b.X+hw, b.Y, b.Width-hw, hh), depth: node.depth + 1, parent: node},
+		{bounds: NewAABB(b.X, b.Y+hh, hw, b.Height-hh), depth: node.depth + 1, parent: node},
+		{bounds: NewAABB(b.X+hw, b.Y+hh, b.Width-hw, b.Height-hh), depth: node.depth + 1, parent: node},
+	}
+	items := node.items
+	node.items = nil
+	for _, item := range items {
+		info := slf.items[item]
+		if child := node.childFor(info.bounds); child != nil {
+			child.items = append(child.items, item)
+			info.node = child
+			slf.items[item] = info
+		} else {
+			node.items = append(node.items, item)
+		}
+	}
+}
+
+// childFor 获取能够完全容纳 bounds 的子节点，不存在时返回 nil
+func (slf *quadTreeNode[V, T]) childFor(bounds AABB[V]) *quadTreeNode[V, T] {
+	if slf.children == nil {
+		return nil
+	}
+	for _, child := range slf.children {
+		if child.bounds.Contains(bounds) {
+			return child
+		}
+	}
+	return nil
+}
+
+// remove 从节点中移除对象
+func (slf *quadTreeNode[V, T]) remove(item T) {
+	for i, t := range slf.items {
+		if t == item {
+			last := len(slf.items) - 1
+			slf.items[i] = slf.items[last]
+			slf.items = slf.items[:last]
+			return
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package geometry_test
+
+import (
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func bruteForceQueryRect(items map[int]geometry.AABB[float64], bounds geometry.AABB[float64]) []int {
+	var result []int
+	for item, b := range items {
+		if b.Intersects(bounds) {
+			result = append(result, item)
+		}
+	}
+	sort.Ints(result)
+	return result
+}
+
+func sortedEqual(a, b []int) bool {
+	sort.Ints(a)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestQuadTree_QueryRect(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tree := geometry.NewQuadTree[float64, int](geometry.NewAABB[float64](0, 0, 1000, 1000), 4, 6)
+	items := make(map[int]geometry.AABB[float64])
+	for i := 0; i < 500; i++ {
+		bounds := geometry.NewAABB(r.Float64()*990, r.Float64()*990, r.Float64()*10, r.Float64()*10)
+		items[i] = bounds
+		tree.Insert(i, bounds)
+	}
+	for i := 0; i < 200; i++ {
+		bounds := geometry.NewAABB(r.Float64()*990, r.Float64()*990, r.Float64()*10, r.Float64()*10)
+		items[i] = bounds
+		tree.Update(i, bounds)
+	}
+	for i := 400; i < 500; i++ {
+		delete(items, i)
+		if !tree.Remove(i) {
+			t.Fatalf("remove %d failed", i)
+		}
+	}
+	if tree.Len() != len(items) {
+		t.Fatalf("len %d, want %d", tree.Len(), len(items))
+	}
+	for i := 0; i < 100; i++ {
+		query := geometry.NewAABB(r.Float64()*900, r.Float64()*900, r.Float64()*100, r.Float64()*100)
+		if got, want := tree.QueryRect(query), bruteForceQueryRect(items, query); !sortedEqual(got, want) {
+			t.Fatalf("query %v got %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestQuadTree_QueryCircle(t *testing.T) {
+	tree := geometry.NewQuadTree[float64, string](geometry.NewAABB[float64](0, 0, 100, 100), 0, 0)
+	tree.Insert("a", geometry.NewAABB[float64](10, 10, 2, 2))
+	tree.Insert("b", geometry.NewAABB[float64](50, 50, 2, 2))
+	tree.Insert("c", geometry.NewAABB[float64](14, 10, 2, 2))
+
+	result := tree.QueryCircle(10, 10, 3)
+	if len(result) != 1 || result[0] != "a" {
+		t.Fatalf("unexpected result %v", result)
+	}
+	if result = tree.QueryCircle(13, 11, 2); len(result) != 2 {
+		t.Fatalf("unexpected result %v", result)
+	}
+}
+
+func BenchmarkQuadTree_QueryRect(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	tree := geometry.NewQuadTree[float64, int](geometry.NewAABB[float64](0, 0, 10000, 10000), 8, 8)
+	for i := 0; i < 10000; i++ {
+		tree.Insert(i, geometry.NewAABB(r.Float64()*9990, r.Float64()*9990, 10, 10))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.QueryRect(geometry.NewAABB(r.Float64()*9900, r.Float64()*9900, 100, 100))
+	}
+}
+
+func BenchmarkQuadTree_Update(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	tree := geometry.NewQuadTree[float64, int](geometry.NewAABB[float64](0, 0, 10000, 10000), 8, 8)
+	for i := 0; i < 10000; i++ {
+		tree.Insert(i, geometry.NewAABB(r.Float64()*9990, r.Float64()*9990, 10, 10))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tree.Update(i%10000, geometry.NewAABB(r.Float64()*9990, r.Float64()*9990, 10, 10))
+	}
+}
@@ -0,0 +1,178 @@
+package geometry
+
+import (
+	"github.com/kercylan98/minotaur/utils/generic"
+	"math"
+)
+
+// NewSpatialHash 创建一个以 cellSize 为格子边长的空间哈希网格，cellSize 小于等于 0 时将默认为 1
+//   - cellSize 通常设置为对象包围盒尺寸的 1~2 倍，过小将导致对象跨越大量格子，过大将导致每个格子中的对象过多
+func NewSpatialHash[V generic.SignedNumber, T comparable](cellSize V) *SpatialHash[V, T] {
+	if cellSize <= 0 {
+		cellSize = 1
+	}
+	return &SpatialHash[V, T]{
+		cellSize: cellSize,
+		cells:    make(map[[2]int64][]T),
+		items:    make(map[T]AABB[V]),
+	}
+}
+
+// SpatialHash 空间哈希网格，将对象按照包围盒覆盖的格子进行索引，适用于对象尺寸相近且分布较为均匀的碰撞检测粗略阶段
+//   - 与 QuadTree 相比无需预先指定范围，插入、移除及更新的开销更稳定
+//   - 非并发安全
+type SpatialHash[V generic.SignedNumber, T comparable] struct {
+	cellSize V
+	cells    map[[2]int64][]T
+	items    map[T]AABB[V]
+}
+
+// Insert 插入对象，当对象已存在时将更新对象的包围盒
+func (slf *SpatialHash[V, T]) Insert(item T, bounds AABB[V]) {
+	if old, exist := slf.items[item]; exist {
+		slf.rangeCells(old, func(cell [2]int64) {
+			slf.removeFromCell(cell, item)
+		})
+	}
+	slf.items[item] = bounds
+	slf.rangeCells(bounds, func(cell [2]int64) {
+		slf.cells[cell] = append(slf.cells[cell], item)
+	})
+}
+
+// Update 更新对象的包围盒，当对象不存在时将插入对象
+//   - 当对象覆盖的格子未发生变化时，仅会更新对象的包围盒
+func (slf *SpatialHash[V, T]) Update(item T, bounds AABB[V]) {
+	old, exist := slf.items[item]
+	if exist {
+		ominX, ominY, omaxX, omaxY := slf.cellRange(old)
+		nminX, nminY, nmaxX, nmaxY := slf.cellRange(bounds)
+		if ominX == nminX && ominY == nminY && omaxX == nmaxX && omaxY == nmaxY {
+			slf.items[item] = bounds
+			return
+		}
+	}
+	slf.Insert(item, bounds)
+}
+
+// Remove 移除对象，返回对象是否存在
+func (slf *SpatialHash[V, T]) Remove(item T) bool {
+	bounds, exist := slf.items[item]
+	if !exist {
+		return false
+	}
+	delete(slf.items, item)
+	slf.rangeCells(bounds, func(cell [2]int64) {
+		slf.removeFromCell(cell, item)
+	})
+	return true
+}
+
+// Get 获取对象的包围盒
+func (slf *SpatialHash[V, T]) Get(item T) (bounds AABB[V], exist bool) {
+	bounds, exist = slf.items[item]
+	return
+}
+
+// Len 获取对象数量
+func (slf *SpatialHash[V, T]) Len() int {
+	return len(slf.items)
+}
+
+// Clear 清空空间哈希网格
+func (slf *SpatialHash[V, T]) Clear() {
+	slf.cells = make(map[[2]int64][]T)
+	slf.items = make(map[T]AABB[V])
+}
+
+// QueryRect 获取包围盒与 bounds 相交的所有对象
+func (slf *SpatialHash[V, T]) QueryRect(bounds AABB[V]) []T {
+	return slf.query(bounds, bounds.Intersects)
+}
+
+// QueryCircle 获取包围盒与以 x、y 为圆心，radius 为半径的圆相交的所有对象
+func (slf *SpatialHash[V, T]) QueryCircle(x, y, radius V) []T {
+	return slf.query(NewAABB(x-radius, y-radius, radius*2, radius*2), func(bounds AABB[V]) bool {
+		return bounds.IntersectsCircle(x, y, radius)
+	})
+}
+
+// RangePairs 遍历所有包围盒相交的对象对，每一对对象仅会被遍历一次，当 handle 返回 false 时将停止遍历
+//   - 适用于碰撞检测的粗略阶段，得到的对象对可进一步进行精确的碰撞检测
+func (slf *SpatialHash[V, T]) RangePairs(handle func(a, b T) bool) {
+	var visited = make(map[[2]T]struct{})
+	for _, items := range slf.cells {
+		for i := 0; i < len(items); i++ {
+			for j := i + 1; j < len(items); j++ {
+				a, b := items[i], items[j]
+				if _, exist := visited[[2]T{a, b}]; exist {
+					continue
+				}
+				if !slf.items[a].Intersects(slf.items[b]) {
+					continue
+				}
+				visited[[2]T{a, b}] = struct{}{}
+				visited[[2]T{b, a}] = struct{}{}
+				if !handle(a, b) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// query 获取覆盖范围内与 hit 相交的所有对象
+func (slf *SpatialHash[V, T]) query(area AABB[V], hit func(bounds AABB[V]) bool) []T {
+	var result []T
+	var visited = make(map[T]struct{})
+	slf.rangeCells(area, func(cell [2]int64) {
+		for _, item := range slf.cells[cell] {
+			if _, exist := visited[item]; exist {
+				continue
+			}
+			visited[item] = struct{}{}
+			if hit(slf.items[item]) {
+				result = append(result, item)
+			}
+		}
+	})
+	return result
+}
+
+// rangeCells 遍历包围盒覆盖的所有格子
+func (slf *SpatialHash[V, T]) rangeCells(bounds AABB[V], handle func(cell [2]int64)) {
+	minX, minY, maxX, maxY := slf.cellRange(bounds)
+	for x := minX; x <= maxX; x++ {
+		for y := minY; y <= maxY; y++ {
+			handle([2]int64{x, y})
+		}
+	}
+}
+
+// cellRange 获取包围盒覆盖的格子范围
+func (slf *SpatialHash[V, T]) cellRange(bounds AABB[V]) (minX, minY, maxX, maxY int64) {
+	size := float64(slf.cellSize)
+	minX = int64(math.Floor(float64(bounds.X) / size))
+	minY = int64(math.Floor(float64(bounds.Y) / size))
+	maxX = int64(math.Floor(float64(bounds.MaxX()) / size))
+	maxY = int64(math.Floor(float64(bounds.MaxY()) / size))
+	return
+}
+
+// removeFromCell 将对象从格子中移除
+func (slf *SpatialHash[V, T]) removeFromCell(cell [2]int64, item T) {
+	items := slf.cells[cell]
+	for i, t := range items {
+		if t == item {
+			last := len(items) - 1
+			items[i] = items[last]
+			items = items[:last]
+			break
+		}
+	}
+	if len(items) == 0 {
+		delete(slf.cells, cell)
+	} else {
+		slf.cells[cell] = items
+	}
+}
@@ -0,0 +1,114 @@
+package geometry_test
+
+import (
+	"github.com/kercylan98/minotaur/utils/geometry"
+	"math/rand"
+	"testing"
+)
+
+func TestSpatialHash_QueryRect(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	hash := geometry.NewSpatialHash[float64, int](16)
+	items := make(map[int]geometry.AABB[float64])
+	for i := 0; i < 500; i++ {
+		bounds := geometry.NewAABB(r.Float64()*1000-500, r.Float64()*1000-500, r.Float64()*20, r.Float64()*20)
+		items[i] = bounds
+		hash.Insert(i, bounds)
+	}
+	for i := 0; i < 200; i++ {
+		bounds := geometry.NewAABB(r.Float64()*1000-500, r.Float64()*1000-500, r.Float64()*20, r.Float64()*20)
+		items[i] = bounds
+		hash.Update(i, bounds)
+	}
+	for i := 400; i < 500; i++ {
+		delete(items, i)
+		if !hash.Remove(i) {
+			t.Fatalf("remove %d failed", i)
+		}
+	}
+	if hash.Len() != len(items) {
+		t.Fatalf("len %d, want %d", hash.Len(), len(items))
+	}
+	for i := 0; i < 100; i++ {
+		query := geometry.NewAABB(r.Float64()*1000-500, r.Float64()*1000-500, r.Float64()*100, r.Float64()*100)
+		if got, want := hash.QueryRect(query), bruteForceQueryRect(items, query); !sortedEqual(got, want) {
+			t.Fatalf("query %v got %v, want %v", query, got, want)
+		}
+	}
+}
+
+func TestSpatialHash_RangePairs(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	hash := geometry.NewSpatialHash[float64, int](8)
+	items := make(map[int]geometry.AABB[float64])
+	for i := 0; i < 200; i++ {
+		bounds := geometry.NewAABB(r.Float64()*200, r.Float64()*200, r.Float64()*12, r.Float64()*12)
+		items[i] = bounds
+		hash.Insert(i, bounds)
+	}
+
+	var want = make(map[[2]int]struct{})
+	for a := 0; a < 200; a++ {
+		for b := a + 1; b < 200; b++ {
+			if items[a].Intersects(items[b]) {
+				want[[2]int{a, b}] = struct{}{}
+			}
+		}
+	}
+	var got = make(map[[2]int]struct{})
+	hash.RangePairs(func(a, b int) bool {
+		if a > b {
+			a, b = b, a
+		}
+		if _, exist := got[[2]int{a, b}]; exist {
+			t.Fatalf("pair %d-%d visited twice", a, b)
+		}
+		got[[2]int{a, b}] = struct{}{}
+		return true
+	})
+	if len(got) != len(want) {
+		t.Fatalf("got %d pairs, want %d", len(got), len(want))
+	}
+	for pair := range want {
+		if _, exist := got[pair]; !exist {
+			t.Fatalf("missing pair %v", pair)
+		}
+	}
+}
+
+func TestSpatialHash_QueryCircle(t *testing.T) {
+	hash := geometry.NewSpatialHash[int, string](4)
+	hash.Insert("a", geometry.NewAABB(-10, -10, 2, 2))
+	hash.Insert("b", geometry.NewAABB(20, 20, 2, 2))
+
+	if result := hash.QueryCircle(-9, -9, 1); len(result) != 1 || result[0] != "a" {
+		t.Fatalf("unexpected result %v", result)
+	}
+	if result := hash.QueryCircle(0, 0, 5); len(result) != 0 {
+		t.Fatalf("unexpected result %v", result)
+	}
+}
+
+func BenchmarkSpatialHash_QueryRect(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	hash := geometry.NewSpatialHash[float64, int](32)
+	for i := 0; i < 10000; i++ {
+		hash.Insert(i, geometry.NewAABB(r.Float64()*9990, r.Float64()*9990, 10, 10))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hash.QueryRect(geometry.NewAABB(r.Float64()*9900, r.Float64()*9900, 100, 100))
+	}
+}
+
+func BenchmarkSpatialHash_Update(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	hash := geometry.NewSpatialHash[float64, int](32)
+	for i := 0; i < 10000; i++ {
+		hash.Insert(i, geometry.NewAABB(r.Float64()*9990, r.Float64()*9990, 10, 10))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		hash.Update(i%10000, geometry.NewAABB(r.Float64()*9990, r.Float64()*9990, 10, 10))
+	}
+}
@@ -35,5 +35,6 @@ func (slf *Encoder) Build(options ...LoggerOption) *Minotaur {
 	return &Minotaur{
 		Logger:  l,
 		Sugared: l.Sugar(),
+		level:   slf.conf.Level,
 	}
 }
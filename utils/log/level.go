@@ -18,3 +18,8 @@ const (
 	// FatalLevel 记录一条消息，然后调用 os.Exit(1)
 	FatalLevel Level = zapcore.FatalLevel
 )
+
+// ParseLevel 将 "debug"、"info"、"warn"、"error"、"dpanic"、"panic"、"fatal" 等文本解析为日志级别
+func ParseLevel(text string) (Level, error) {
+	return zapcore.ParseLevel(text)
+}
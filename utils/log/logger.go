@@ -15,6 +15,28 @@ func SetLogger(l Logger) {
 	logger = l
 }
 
+// GetLogger 获取当前的日志记录器
+func GetLogger() Logger {
+	return logger
+}
+
+// SetLevel 在运行时调整日志级别，仅当日志记录器为 *Minotaur 时有效，否则将返回 false
+func SetLevel(level Level) bool {
+	if m, ok := logger.(*Minotaur); ok && m != nil {
+		m.SetLevel(level)
+		return true
+	}
+	return false
+}
+
+// GetLevel 获取当前的日志级别，仅当日志记录器为 *Minotaur 时有效，否则 ok 将返回 false
+func GetLevel() (level Level, ok bool) {
+	if m, ok := logger.(*Minotaur); ok && m != nil {
+		return m.Level(), true
+	}
+	return level, false
+}
+
 // Logger 适用于 Minotaur 的日志接口
 type Logger interface {
 	// Debug 在 DebugLevel 记录一条消息。该消息包括在日志站点传递的任何字段以及记录器上累积的任何字段
@@ -5,4 +5,15 @@ import "go.uber.org/zap"
 type Minotaur struct {
 	*zap.Logger
 	Sugared *zap.SugaredLogger
+	level   zap.AtomicLevel
+}
+
+// SetLevel 在运行时调整日志级别，不会影响通过 Encoder.Split 及 Encoder.AddCore 添加的输出
+func (slf *Minotaur) SetLevel(level Level) {
+	slf.level.SetLevel(level)
+}
+
+// Level 获取当前的日志级别
+func (slf *Minotaur) Level() Level {
+	return slf.level.Level()
 }
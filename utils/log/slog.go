@@ -0,0 +1,70 @@
+package log
+
+import (
+	"context"
+	"go.uber.org/zap/zapcore"
+	"log/slog"
+	"os"
+	"sort"
+)
+
+// NewSlog 创建一个将日志输出到 slog.Logger 的日志记录器，可通过 SetLogger 或 server.WithLogger 使用
+//   - DPanic 将以 ERROR 级别输出，Panic 及 Fatal 在以 ERROR 级别输出后将分别引发 panic 及调用 os.Exit(1)
+//   - 字段将按照键名排序后转换为 slog.Attr
+func NewSlog(logger *slog.Logger) Logger {
+	return &slogLogger{logger: logger}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (slf *slogLogger) log(level slog.Level, msg string, fields []Field) {
+	if !slf.logger.Enabled(context.Background(), level) {
+		return
+	}
+	enc := zapcore.NewMapObjectEncoder()
+	for _, field := range fields {
+		field.AddTo(enc)
+	}
+	keys := make([]string, 0, len(enc.Fields))
+	for key := range enc.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	attrs := make([]slog.Attr, 0, len(keys))
+	for _, key := range keys {
+		attrs = append(attrs, slog.Any(key, enc.Fields[key]))
+	}
+	slf.logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+func (slf *slogLogger) Debug(msg string, fields ...Field) {
+	slf.log(slog.LevelDebug, msg, fields)
+}
+
+func (slf *slogLogger) Info(msg string, fields ...Field) {
+	slf.log(slog.LevelInfo, msg, fields)
+}
+
+func (slf *slogLogger) Warn(msg string, fields ...Field) {
+	slf.log(slog.LevelWarn, msg, fields)
+}
+
+func (slf *slogLogger) Error(msg string, fields ...Field) {
+	slf.log(slog.LevelError, msg, fields)
+}
+
+func (slf *slogLogger) DPanic(msg string, fields ...Field) {
+	slf.log(slog.LevelError, msg, fields)
+}
+
+func (slf *slogLogger) Panic(msg string, fields ...Field) {
+	slf.log(slog.LevelError, msg, fields)
+	panic(msg)
+}
+
+func (slf *slogLogger) Fatal(msg string, fields ...Field) {
+	slf.log(slog.LevelError, msg, fields)
+	os.Exit(1)
+}